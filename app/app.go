@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/gosnmp/gosnmp"
 	rds "github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog/log"
 	"github.com/s4lfanet/go-api-c320/config"
@@ -12,10 +14,14 @@ import (
 	"github.com/s4lfanet/go-api-c320/internal/repository"
 	"github.com/s4lfanet/go-api-c320/internal/usecase"
 	"github.com/s4lfanet/go-api-c320/pkg/graceful"
+	"github.com/s4lfanet/go-api-c320/pkg/jwt"
 	"github.com/s4lfanet/go-api-c320/pkg/redis"
 	"github.com/s4lfanet/go-api-c320/pkg/snmp"
 )
 
+// firmwareDetectTimeout bounds the firmware detection of an OLT at startup, including the CLI fallback
+const firmwareDetectTimeout = 30 * time.Second
+
 // App represents the main application structure that holds the HTTP router
 // and manages the application lifecycle, including dependency initialization
 // and server startup.
@@ -67,76 +73,165 @@ func (a *App) Start(ctx context.Context) error { // Method to start the applicat
 		}
 	}(redisClient) // Pass redisClient to the deferred function
 
-	// Initialize SNMP connection
-	snmpConn, err := snmp.SetupSnmpConnection(cfg) // Setup SNMP connection using configuration
-	if err != nil {                                // Check if setup failed
-		log.Error().Err(err).Msg("Failed to setup SNMP connection") // Log the error
-		return fmt.Errorf("SNMP setup failed: %w", err)
+	// Load the OLT registry (OLT_REGISTRY_FILE, or a single OLT from the legacy environment variables)
+	devices, err := config.LoadOLTRegistry(cfg)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load OLT registry")
+		return err
 	}
 
-	// Check SNMP connection
-	/*
-		if SNMP Connection with wrong credentials in SNMP v3, return error is nil
-		if SNMP Connection with the wrong Port in SNMP v2 v2c, return error is nil
-		if SNMP Connection with wrong community v2 v2c, return error is nil
-
-		Connect creates and opens a socket. Because UDP is a connectionless protocol,
-		you won't know if the remote host is responding until you send packets.
-		Neither will you know if the host is regularly disappearing and reappearing.
-	*/
+	// Initialize per-OLT connections (SNMP, Redis namespace, Telnet session manager)
+	registry := repository.NewOLTRegistry()
+	defer func() { // Close all SNMP and Telnet connections after application shutdown
+		if err := registry.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close OLT connections")
+		}
+	}()
 
-	if snmpConn.Connect() != nil { // Attempt to connect to SNMP agent (UDP socket creation)
-		log.Error().Err(err).Msg("Failed to connect to SNMP server") // Log connection failure
-	} else { // If connection setup (socket creation) succeeded
-		log.Info().Msg("SNMP server successfully connected") // Log success message
+	systems := make(map[string]*usecase.SystemUsecase, len(devices))
+	for _, device := range devices {
+		conn, system, err := connectOLT(ctx, device, cfg, redisClient)
+		if err != nil {
+			return err
+		}
+		if err := registry.Register(conn); err != nil {
+			return err
+		}
+		systems[device.ID] = system
 	}
 
-	// Close SNMP connection after application shutdown
-	defer func() { // Defer closure of SNMP connection
-		if err := snmpConn.Conn.Close(); err != nil { // Close the SNMP connection and check for error
-			log.Error().Err(err).Msg("Failed to close SNMP connection") // Log the error
+	// Every OLT-changing request is recorded in the hash-chained audit log (AUDIT_LOG_FILE), keyed with AUDIT_HMAC_KEY
+	auditKey, err := cfg.Audit.HMACKeyBytes()
+	if err != nil {
+		log.Error().Err(err).Msg("Invalid audit log configuration")
+		return err
+	}
+	if auditKey == nil {
+		log.Warn().Msg("AUDIT_HMAC_KEY is not set: whoever can write the audit log can rewrite it with a valid hash chain")
+	}
+	if cfg.Audit.MaxSizeMB < 0 {
+		err := config.ErrInvalidConfig("AUDIT_LOG_MAX_SIZE_MB must not be negative")
+		log.Error().Err(err).Msg("Invalid audit log configuration")
+		return err
+	}
+	auditStore, err := repository.NewFileAuditStore(cfg.Audit.LogFile, int64(cfg.Audit.MaxSizeMB)<<20)
+	if err != nil {
+		log.Error().Err(err).Str("file", cfg.Audit.LogFile).Msg("Failed to open audit log")
+		return err
+	}
+	defer func() {
+		if err := auditStore.Close(); err != nil {
+			log.Error().Err(err).Msg("Failed to close audit log")
 		}
 	}()
+	auditUsecase := usecase.NewAuditUsecase(auditStore, auditKey)
 
-	// Initialize repository
-	snmpRepo := repository.NewPonRepository(snmpConn.Target, snmpConn.Community, snmpConn.Port) // Create a new PON repository with SNMP details
-	redisRepo := repository.NewOnuRedisRepo(redisClient)                                        // Create new ONU Redis repository
-	onuRepo := repository.NewOnuRepository(snmpConn, cfg)                                       // Create new ONU repository for monitoring
+	// API keys are stored hashed in Redis and shared by all OLTs
+	apiKeyUsecase := usecase.NewAPIKeyUsecase(cfg.Auth, repository.NewRedisAPIKeyStore(redisClient))
 
-	// Initialize Telnet session manager
-	telnetCfg := config.LoadTelnetConfig()                                // Load telnet configuration
-	telnetSessionManager := repository.GetGlobalSessionManager(telnetCfg) // Get global telnet session manager
+	// Requests, and the jobs they submit, are authenticated unless AUTH_ENABLED=false
+	var authenticator usecase.Authenticator
+	if cfg.Auth.Enabled {
+		// Bearer tokens of the OIDC provider are accepted besides API keys if AUTH_OIDC_JWKS is set
+		var tokenUsecase usecase.TokenUsecase
+		if cfg.Auth.OIDC.Enabled() {
+			keys := jwt.NewJWKS(cfg.Auth.OIDC.JWKS)
+			if err := keys.Load(ctx); err != nil {
+				log.Error().Err(err).Msg("Failed to load the OIDC key set")
+				return err
+			}
+			tokenUsecase, err = usecase.NewTokenUsecase(cfg.Auth.OIDC, keys)
+			if err != nil {
+				log.Error().Err(err).Msg("Invalid OIDC configuration")
+				return err
+			}
+			log.Info().Str("issuer", cfg.Auth.OIDC.Issuer).Str("jwks", cfg.Auth.OIDC.JWKS).Msg("OIDC bearer tokens enabled")
+		}
 
-	// Initialize usecase
-	onuUsecase := usecase.NewOnuUsecase(snmpRepo, redisRepo, cfg)                                   // Create new ONU usecase with repositories and config
-	ponUsecase := usecase.NewPonUsecase(snmpRepo, redisRepo, cfg)                                   // Create new PON usecase with repositories and config
-	profileUsecase := usecase.NewProfileUsecase(snmpRepo, redisRepo, cfg)                           // Create new Profile usecase with repositories and config
-	cardUsecase := usecase.NewCardUsecase(snmpRepo, redisRepo, cfg)                                 // Create new Card usecase with repositories and config
-	provisionUsecase := usecase.NewProvisionUsecase(telnetSessionManager, cfg)                      // Create new Provision usecase with telnet manager
-	vlanUsecase := usecase.NewVLANUsecase(telnetSessionManager, cfg)                                // Create new VLAN usecase with telnet manager
-	trafficUsecase := usecase.NewTrafficUsecase(telnetSessionManager, cfg)                          // Create new Traffic usecase with telnet manager
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(telnetSessionManager, cfg)                    // Create new ONU Management usecase with telnet manager
-	batchUsecase := usecase.NewBatchOperationsUsecase(telnetSessionManager, onuMgmtUsecase, cfg)    // Create new Batch Operations usecase
-	monitoringUsecase := usecase.NewMonitoringUsecase(snmpConn, cfg, onuRepo, telnetSessionManager) // Create new Monitoring usecase with SNMP + Telnet (Phase 7.2)
+		authenticator = usecase.NewAuthenticator(apiKeyUsecase, tokenUsecase)
+		if cfg.Auth.AdminKey == "" {
+			log.Warn().Msg("AUTH_ADMIN_KEY is not set: API keys can only be issued with an existing admin:keys key")
+		}
+	} else {
+		log.Warn().Msg("Authentication is disabled (AUTH_ENABLED=false): anyone who can reach the API can change the OLTs")
+	}
 
-	// Initialize handler
-	onuHandler := handler.NewOnuHandler(onuUsecase)                      // Create new ONU handler with usecase
-	ponHandler := handler.NewPonHandler(ponUsecase)                      // Create new PON handler with usecase
-	profileHandler := handler.NewProfileHandler(profileUsecase)          // Create new Profile handler with usecase
-	cardHandler := handler.NewCardHandler(cardUsecase)                   // Create new Card handler with usecase
-	provisionHandler := handler.NewProvisionHandler(provisionUsecase)    // Create new Provision handler with usecase
-	vlanHandler := handler.NewVLANHandler(vlanUsecase)                   // Create new VLAN handler with usecase
-	trafficHandler := handler.NewTrafficHandler(trafficUsecase)          // Create new Traffic handler with usecase
-	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)    // Create new ONU Management handler with usecase
-	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)      // Create new Batch Operations handler with usecase
-	monitoringHandler := handler.NewMonitoringHandler(monitoringUsecase) // Create new Monitoring handler (Phase 7.1)
-
-	// Initialize Config Backup handler (Phase 6.2)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(cfg, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase) // Create config backup usecase
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)                                                // Create new Config Backup handler
+	// Batch operations, OLT backups, restores and PON monitoring run as jobs kept in Redis (JOB_RETENTION)
+	jobUsecase := usecase.NewJobUsecase(repository.NewRedisJobStore(redisClient, cfg.Jobs.Retention), auditUsecase, authenticator, cfg.Jobs.Retention)
+
+	// Live monitoring stream, fed by the state collector of each OLT (every STREAM_POLL_INTERVAL while clients are subscribed)
+	streamUsecase := usecase.NewMonitoringStreamUsecase()
+
+	// Background workers (backup schedulers, job workers) stop when Start returns
+	workerCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+
+	// Initialize handlers for every OLT
+	olts := make(map[string]*routeHandlers, len(devices))
+	for _, conn := range registry.List() {
+		olts[conn.Device.ID] = newRouteHandlers(workerCtx, conn, systems[conn.Device.ID], jobUsecase, streamUsecase)
+	}
+
+	// Continue the jobs that were unfinished at the last shutdown, then run new ones
+	go jobUsecase.Run(workerCtx)
+
+	// Open streams would keep the server from shutting down; they are closed when the shutdown starts
+	streamCtx, stopStreams := context.WithCancel(workerCtx)
+	defer stopStreams()
+	go streamUsecase.Run(streamCtx)
+
+	// Receive SNMP traps and informs of all OLTs (TRAP_LISTEN_ADDR); received events are kept in memory and streamed
+	trapSources := make([]usecase.TrapSource, 0, len(devices))
+	for _, conn := range registry.List() {
+		trapSources = append(trapSources, usecase.TrapSource{
+			OLTID:  conn.Device.ID,
+			Hosts:  []string{conn.Device.Snmp.Host, conn.Device.Telnet.Host},
+			Config: conn.Config,
+		})
+	}
+	eventUsecase := usecase.NewEventUsecase(cfg, repository.NewMemoryEventStore(cfg.Trap.EventBuffer), trapSources, streamUsecase)
+	if cfg.Trap.ListenAddr != "" {
+		engineID, err := cfg.Trap.EngineIDBytes()
+		if err != nil {
+			log.Error().Err(err).Msg("Invalid SNMP trap configuration")
+			return err
+		}
+		// SNMPv3 notifications are accepted with the credentials of the OLTs that use SNMPv3
+		security := snmp.TrapSecurity{EngineID: engineID}
+		for _, conn := range registry.List() {
+			if conn.Config.SnmpCfg.IsV3() {
+				security.Users = append(security.Users, conn.Config.SnmpCfg)
+			}
+		}
+		switch {
+		case cfg.Trap.Community == "" && len(security.Users) == 0:
+			err := config.ErrInvalidConfig("TRAP_COMMUNITY is required to receive traps unless the OLTs use SNMPv3")
+			log.Error().Err(err).Msg("Invalid SNMP trap configuration")
+			return err
+		case cfg.Trap.Community == "":
+			log.Warn().Msg("TRAP_COMMUNITY is not set: only SNMPv3 traps are received, v1/v2c traps are dropped")
+		}
+		if err := snmp.StartTrapListener(workerCtx, cfg.Trap.ListenAddr, security, eventUsecase.HandleTrap); err != nil {
+			log.Error().Err(err).Msg("Failed to start SNMP trap listener")
+			return err
+		}
+	}
+
+	// Initialize handlers that are not bound to a single OLT
+	global := &globalHandlers{
+		olt:      handler.NewOLTHandler(usecase.NewOLTUsecase(registry)), // Create new OLT registry handler
+		events:   handler.NewEventHandler(eventUsecase),                  // Create new event handler
+		apiKeys:  handler.NewAPIKeyHandler(apiKeyUsecase),                // Create new API key handler
+		audit:    handler.NewAuditHandler(auditUsecase),                  // Create new audit log handler
+		auditLog: auditUsecase,
+		jobs:     handler.NewJobHandler(jobUsecase), // Create new job handler
+	}
+	if authenticator != nil {
+		global.auth = authenticator
+	}
 
 	// Initialize router
-	a.router = loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, monitoringHandler) // Load all routes and middleware, assigning to app router
+	a.router = loadRoutes(olts[registry.DefaultID()], olts, global) // Load all routes and middleware, assigning to app router
 
 	// Start server
 	addr := "8081"          // Define the server address/port
@@ -144,6 +239,7 @@ func (a *App) Start(ctx context.Context) error { // Method to start the applicat
 		Addr:    ":" + addr, // Set the address
 		Handler: a.router,   // Set the handler (router)
 	}
+	server.RegisterOnShutdown(stopStreams)
 
 	// Start server at given address
 	log.Info().Msgf("Application started at %s", addr) // Log startup message with address
@@ -151,3 +247,167 @@ func (a *App) Start(ctx context.Context) error { // Method to start the applicat
 	// Graceful shutdown
 	return graceful.Shutdown(ctx, server) // Start a server with graceful shutdown handling
 }
+
+// connectOLT sets up the SNMP connection, repositories and Telnet session manager of a single OLT.
+// The firmware of the OLT is detected before its OID mappings are generated.
+func connectOLT(ctx context.Context, device config.OLTDeviceConfig, baseCfg *config.Config, redisClient *rds.Client) (*repository.OLTConnection, *usecase.SystemUsecase, error) {
+	snmpCfg := device.Snmp.SnmpConfig()
+	snmpCfg.StartupProbe = baseCfg.SnmpCfg.StartupProbe
+
+	// Replay serves the recorded exchanges of a cassette instead of connecting to the OLT
+	var cassette *repository.Cassette
+	var err error
+	if baseCfg.Cassette.Mode == config.CassetteReplay {
+		cassette, err = repository.LoadCassette(baseCfg.Cassette.Path(device.ID))
+		if err != nil {
+			log.Error().Err(err).Str("olt_id", device.ID).Msg("Failed to load cassette")
+			return nil, nil, fmt.Errorf("cassette replay failed for OLT %s: %w", device.ID, err)
+		}
+		log.Warn().Str("olt_id", device.ID).Int("interactions", cassette.Len()).Msg("Replaying OLT exchanges from cassette")
+	}
+
+	// Initialize SNMP connection
+	var snmpConn *gosnmp.GoSNMP
+	snmpRepo := repository.NewSnmpRepository(snmpCfg) // Create a new SNMP repository with the OLT credentials (v2c or v3)
+	if cassette == nil {
+		snmpConn, err = snmp.ConnectSnmp(snmpCfg) // Setup SNMP connection using the device credentials
+		if err != nil {                           // Check if setup failed
+			log.Error().Err(err).Str("olt_id", device.ID).Msg("Failed to setup SNMP connection") // Log the error
+			return nil, nil, fmt.Errorf("SNMP setup failed for OLT %s: %w", device.ID, err)
+		}
+
+		// Check SNMP connection: Connect only opens a UDP socket, so wrong hosts, ports and
+		// credentials only surface once a request is sent
+		if snmpCfg.StartupProbe {
+			if err := snmp.Probe(snmpConn); err != nil {
+				_ = snmpConn.Conn.Close()
+				log.Error().Err(err).Str("olt_id", device.ID).Msg("SNMP startup probe failed")
+				return nil, nil, fmt.Errorf("SNMP startup probe failed for OLT %s: %w", device.ID, err)
+			}
+		}
+
+		log.Info().Str("olt_id", device.ID).Msg("SNMP server successfully connected") // Log success message
+	} else {
+		snmpRepo = repository.NewReplaySnmpRepository(cassette)
+	}
+
+	// Initialize Telnet session manager
+	telnetCfg := device.TelnetConfig()
+	var telnetSessionManager *repository.TelnetSessionManager
+	var cassetteFile string
+	switch {
+	case cassette != nil:
+		telnetSessionManager = repository.NewReplayTelnetSessionManager(telnetCfg, cassette)
+	case baseCfg.Cassette.Mode == config.CassetteRecord:
+		// Record every SNMP and CLI exchange; the cassette is written when the OLT connection is closed
+		cassette = repository.NewCassette(repository.CassetteSecrets(snmpCfg, telnetCfg)...)
+		cassetteFile = baseCfg.Cassette.Path(device.ID)
+		snmpRepo = repository.NewRecordingSnmpRepository(snmpRepo, cassette)
+		telnetSessionManager = repository.NewRecordingTelnetSessionManager(telnetCfg, cassette)
+		log.Warn().Str("olt_id", device.ID).Str("cassette", cassetteFile).Msg("Recording OLT exchanges")
+	default:
+		telnetSessionManager = repository.NewTelnetSessionManager(telnetCfg) // Dedicated session manager per OLT
+	}
+
+	// Detect the firmware (sysDescr, or "show version") and generate the OID mappings of its profile
+	detectCtx, cancel := context.WithTimeout(ctx, firmwareDetectTimeout)
+	systemUsecase := usecase.NewSystemUsecase(device.ID, device.Firmware, snmpRepo, telnetSessionManager)
+	device.Firmware = systemUsecase.Firmware(detectCtx)
+	cancel()
+
+	cfg, err := device.DeviceConfig(baseCfg)
+	if err != nil {
+		log.Error().Err(err).Str("olt_id", device.ID).Msg("Failed to build OLT config")
+		return nil, nil, err
+	}
+
+	// Keep cache keys of additional OLTs apart; the default OLT keeps the legacy key layout
+	redisRepo := repository.NewOnuRedisRepo(redisClient)
+	if device.ID != config.DefaultOLTID {
+		redisRepo = repository.NewOnuRedisRepoWithPrefix(redisClient, "olt:"+device.ID+":")
+	}
+
+	// Initialize backup storage
+	backupStore, err := repository.NewBackupStore(cfg)
+	if err != nil {
+		log.Error().Err(err).Str("olt_id", device.ID).Msg("Failed to setup backup storage")
+		return nil, nil, fmt.Errorf("backup storage setup failed for OLT %s: %w", device.ID, err)
+	}
+
+	conn := &repository.OLTConnection{
+		Device:               device,
+		Config:               cfg,
+		TelnetConfig:         telnetCfg,
+		SnmpConn:             snmpConn,
+		SnmpRepo:             snmpRepo,
+		RedisRepo:            redisRepo,
+		OnuRepo:              repository.NewOnuRepository(snmpRepo, cfg), // Create new ONU repository for monitoring
+		TelnetSessionManager: telnetSessionManager,
+		BackupStore:          backupStore,
+		Cassette:             cassette,
+		CassetteFile:         cassetteFile,
+	}
+
+	// Regenerate the OID mappings in place when a later detection finds another firmware
+	systemUsecase.OnFirmwareChange(func(firmware config.FirmwareVersion) error {
+		next := device
+		next.Firmware = firmware
+		nextCfg, err := next.DeviceConfig(baseCfg)
+		if err != nil {
+			return err
+		}
+		cfg.SwitchOIDs(nextCfg)
+		return nil
+	})
+	return conn, systemUsecase, nil
+}
+
+// newRouteHandlers wires the usecases and handlers of a single OLT, registers the OLT's jobs and monitoring stream
+// and starts its background workers
+func newRouteHandlers(ctx context.Context, conn *repository.OLTConnection, systemUsecase *usecase.SystemUsecase, jobUsecase usecase.JobUsecase, streamUsecase usecase.MonitoringStreamUsecase) *routeHandlers {
+	cfg := conn.Config
+	snmpRepo := conn.SnmpRepo
+	redisRepo := conn.RedisRepo
+	telnetSessionManager := conn.TelnetSessionManager
+
+	// Initialize usecase
+	onuUsecase := usecase.NewOnuUsecase(snmpRepo, redisRepo, cfg)                                                                  // Create new ONU usecase with repositories and config
+	ponUsecase := usecase.NewPonUsecase(snmpRepo, redisRepo, cfg)                                                                  // Create new PON usecase with repositories and config
+	profileUsecase := usecase.NewProfileUsecase(snmpRepo, redisRepo, cfg)                                                          // Create new Profile usecase with repositories and config
+	cardUsecase := usecase.NewCardUsecase(snmpRepo, redisRepo, cfg)                                                                // Create new Card usecase with repositories and config
+	configBackupUsecase := usecase.NewConfigBackupUsecase(cfg, conn.BackupStore, snmpRepo, telnetSessionManager)                   // Create config backup usecase (Phase 6.2), also takes pre-change snapshots
+	provisionUsecase := usecase.NewProvisionUsecase(telnetSessionManager, cfg, configBackupUsecase)                                // Create new Provision usecase with telnet manager
+	vlanUsecase := usecase.NewVLANUsecase(telnetSessionManager, cfg, configBackupUsecase)                                          // Create new VLAN usecase with telnet manager
+	trafficUsecase := usecase.NewTrafficUsecase(telnetSessionManager, cfg, configBackupUsecase)                                    // Create new Traffic usecase with telnet manager
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(telnetSessionManager, cfg, configBackupUsecase)                              // Create new ONU Management usecase with telnet manager
+	monitoringUsecase := usecase.NewMonitoringUsecase(snmpRepo, cfg, conn.OnuRepo, telnetSessionManager)                           // Create new Monitoring usecase with SNMP + Telnet (Phase 7.2)
+	jobs := jobUsecase.RegisterOLT(conn.Device.ID, usecase.NewJobExecutor(onuMgmtUsecase, configBackupUsecase, monitoringUsecase)) // Run the OLT's jobs with its usecases
+	batchUsecase := usecase.NewBatchOperationsUsecase(jobs)                                                                        // Create new Batch Operations usecase
+	backupSchedulerUsecase := usecase.NewBackupSchedulerUsecase(cfg, configBackupUsecase)                                          // Create backup scheduler
+	streamUsecase.RegisterOLT(conn.Device.ID, cfg)                                                                                 // Accept subscriptions on /monitoring/stream
+	metricsCollector := usecase.NewOLTMetricsCollector(conn.Device.ID, cfg, monitoringUsecase, cardUsecase, streamUsecase)         // Collect ONU, PON and card state for /metrics and the stream
+
+	// Start scheduled backups, the state collection and the firmware check after reconnects
+	go backupSchedulerUsecase.Run(ctx)
+	go metricsCollector.Run(ctx)
+	go systemUsecase.Run(ctx)
+
+	// Initialize handler
+	return &routeHandlers{
+		oltID:        conn.Device.ID,                                                    // Recorded in the audit log
+		onu:          handler.NewOnuHandler(onuUsecase),                                 // Create new ONU handler with usecase
+		pon:          handler.NewPonHandler(ponUsecase),                                 // Create new PON handler with usecase
+		profile:      handler.NewProfileHandler(profileUsecase),                         // Create new Profile handler with usecase
+		card:         handler.NewCardHandler(cardUsecase),                               // Create new Card handler with usecase
+		provision:    handler.NewProvisionHandler(provisionUsecase),                     // Create new Provision handler with usecase
+		vlan:         handler.NewVLANHandler(vlanUsecase),                               // Create new VLAN handler with usecase
+		traffic:      handler.NewTrafficHandler(trafficUsecase),                         // Create new Traffic handler with usecase
+		onuMgmt:      handler.NewONUManagementHandler(onuMgmtUsecase),                   // Create new ONU Management handler with usecase
+		batch:        handler.NewBatchOperationsHandler(batchUsecase),                   // Create new Batch Operations handler with usecase
+		configBackup: handler.NewConfigBackupHandler(configBackupUsecase, jobs),         // Create new Config Backup handler
+		schedule:     handler.NewBackupScheduleHandler(backupSchedulerUsecase),          // Create new Backup Schedule handler
+		monitoring:   handler.NewMonitoringHandler(monitoringUsecase, jobs),             // Create new Monitoring handler (Phase 7.1)
+		stream:       handler.NewMonitoringStreamHandler(conn.Device.ID, streamUsecase), // Create new live monitoring stream handler
+		system:       handler.NewSystemHandler(systemUsecase),                           // Create new System info handler
+	}
+}
@@ -3,16 +3,119 @@ package app
 import (
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
 	"github.com/s4lfanet/go-api-c320/internal/handler"
 	"github.com/s4lfanet/go-api-c320/internal/middleware"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+	"github.com/s4lfanet/go-api-c320/pkg/metrics"
 )
 
-func loadRoutes(onuHandler *handler.OnuHandler, ponHandler *handler.PonHandler, profileHandler *handler.ProfileHandler, cardHandler *handler.CardHandler, provisionHandler *handler.ProvisionHandler, vlanHandler handler.VLANHandlerInterface, trafficHandler handler.TrafficHandlerInterface, onuMgmtHandler handler.ONUManagementHandlerInterface, batchHandler handler.BatchOperationsHandlerInterface, configBackupHandler *handler.ConfigBackupHandler, monitoringHandler *handler.MonitoringHandler) http.Handler { // Function to configure and return the HTTP router
+// routeHandlers groups the handlers that are served for a single OLT.
+// The default OLT is served under /api/v1, every registered OLT under /api/v1/olts/{olt_id}.
+type routeHandlers struct {
+	oltID        string // OLT the handlers operate on, recorded in the audit log
+	onu          *handler.OnuHandler
+	pon          *handler.PonHandler
+	profile      *handler.ProfileHandler
+	card         *handler.CardHandler
+	provision    *handler.ProvisionHandler
+	vlan         handler.VLANHandlerInterface
+	traffic      handler.TrafficHandlerInterface
+	onuMgmt      handler.ONUManagementHandlerInterface
+	batch        handler.BatchOperationsHandlerInterface
+	configBackup *handler.ConfigBackupHandler
+	schedule     *handler.BackupScheduleHandler
+	monitoring   *handler.MonitoringHandler
+	stream       *handler.MonitoringStreamHandler
+	system       *handler.SystemHandler
+}
+
+// globalHandlers groups the handlers that are not bound to a single OLT
+type globalHandlers struct {
+	olt      *handler.OLTHandler
+	events   *handler.EventHandler
+	apiKeys  *handler.APIKeyHandler
+	audit    *handler.AuditHandler
+	jobs     *handler.JobHandler
+	auth     middleware.Authenticator // nil disables authentication (AUTH_ENABLED=false)
+	auditLog middleware.AuditRecorder // Records the OLT-changing requests; nil only in tests
+}
+
+// routeScopes maps route patterns of /api/v1, and /metrics of the root router, to the scope required for
+// reading (GET) and for changes. The first matching prefix applies, other routes need read:onu or write:onu.
+// /olts/{olt_id}/* is authorized by the router of the addressed OLT.
+var routeScopes = []struct {
+	prefix string
+	read   string
+	write  string
+}{
+	{"/olts/{olt_id}/*", "", ""},
+	{"/auth/", model.ScopeAdminKeys, model.ScopeAdminKeys},
+	{"/audit", model.ScopeReadAudit, model.ScopeReadAudit},
+	{"/jobs", "", ""}, // Each job needs the scope of its type, checked by the job usecase
+	{"/config/", model.ScopeAdminConfig, model.ScopeAdminConfig},
+	{"/batch/", model.ScopeBatch, model.ScopeBatch},
+	{"/vlan/", model.ScopeReadONU, model.ScopeWriteVLAN},
+	{"/traffic/", model.ScopeReadONU, model.ScopeWriteTraffic},
+	{"/monitoring/", model.ScopeReadONU, model.ScopeReadONU}, // Monitoring jobs only read the OLT
+	{"/metrics", model.ScopeReadMetrics, model.ScopeReadMetrics},
+}
+
+// routeScope returns the scope required for a route of /api/v1
+func routeScope(method, pattern string) string {
+	read := method == http.MethodGet || method == http.MethodHead
+	for _, rs := range routeScopes {
+		if !strings.HasPrefix(pattern, rs.prefix) {
+			continue
+		}
+		if read {
+			return rs.read
+		}
+		return rs.write
+	}
+
+	if read {
+		return model.ScopeReadONU
+	}
+	return model.ScopeWriteONU
+}
+
+// auditOperations names the OLT-changing routes of /api/v1 by method and pattern; requests to these
+// routes are recorded in the audit log. Backups, schedules and cache deletion do not change the OLT.
+// Batch operations and restores run as jobs, which record their runs themselves.
+var auditOperations = map[string]string{
+	"POST /onu/register":                                  "onu.register",
+	"DELETE /onu/{pon}/{onu_id}":                          "onu.delete",
+	"POST /vlan/onu":                                      "vlan.configure",
+	"PUT /vlan/onu":                                       "vlan.modify",
+	"DELETE /vlan/onu/{pon}/{onu_id}":                     "vlan.delete",
+	"POST /traffic/dba-profile":                           "dba_profile.create",
+	"PUT /traffic/dba-profile":                            "dba_profile.modify",
+	"DELETE /traffic/dba-profile/{name}":                  "dba_profile.delete",
+	"POST /traffic/tcont":                                 "tcont.configure",
+	"DELETE /traffic/tcont/{pon}/{onu_id}/{tcont_id}":     "tcont.delete",
+	"POST /traffic/gemport":                               "gemport.configure",
+	"DELETE /traffic/gemport/{pon}/{onu_id}/{gemport_id}": "gemport.delete",
+	"POST /onu-management/reboot":                         "onu.reboot",
+	"POST /onu-management/block":                          "onu.block",
+	"POST /onu-management/unblock":                        "onu.unblock",
+	"PUT /onu-management/description":                     "onu.update_description",
+	"DELETE /onu-management/{pon}/{onu_id}":               "onu.delete",
+}
+
+// auditOperation returns the audited operation of a route of /api/v1, or "" for routes that do not change the OLT
+func auditOperation(method, pattern string) string {
+	return auditOperations[method+" "+pattern]
+}
+
+func loadRoutes(defaultOLT *routeHandlers, olts map[string]*routeHandlers, global *globalHandlers) http.Handler { // Function to configure and return the HTTP router
 
 	// Initialize logger
 	l := log.Output(zerolog.ConsoleWriter{ // Create a new logger with console writer output
@@ -26,13 +129,14 @@ func loadRoutes(onuHandler *handler.OnuHandler, ponHandler *handler.PonHandler,
 	router.Use(middleware.RequestID) // Add unique request ID to each request
 
 	// Security middleware
-	router.Use(middleware.SecurityHeaders)                  // Apply security headers middleware
-	router.Use(middleware.RequestTimeout(90 * time.Second)) // Set request timeout to the 90s (allows cold cache SNMP queries up to the 60s without a timeout)
-	router.Use(middleware.RateLimiter(100, 200))            // Apply rate limiting: 100 requests per second, burst up to 200
-	router.Use(middleware.MaxBodySize(1 << 20))             // Limit request body size to 1MB (1 << 20 bytes)
+	router.Use(middleware.SecurityHeaders)                                      // Apply security headers middleware
+	router.Use(middleware.RequestTimeout(90*time.Second, "/monitoring/stream")) // Set request timeout to the 90s (allows cold cache SNMP queries up to the 60s without a timeout); streams stay open
+	router.Use(middleware.RateLimiter(100, 200))                                // Apply rate limiting: 100 requests per second, burst up to 200
+	router.Use(middleware.MaxBodySize(1 << 20))                                 // Limit request body size to 1MB (1 << 20 bytes)
 
 	// Middleware for logging requests
 	router.Use(middleware.Logger(l)) // Apply logging middleware using the initialized logger
+	router.Use(middleware.Metrics)   // Record request latency by route for /metrics
 
 	// Middleware for CORS (now configurable via environment variables)
 	router.Use(middleware.CorsMiddleware()) // Apply Cross-Origin Resource Sharing (CORS) middleware
@@ -40,26 +144,104 @@ func loadRoutes(onuHandler *handler.OnuHandler, ponHandler *handler.PonHandler,
 	// Define a simple root endpoint
 	router.Get("/", rootHandler) // Register the GET handler for the root path "/"
 
+	// Prometheus metrics of the OLTs and of the API itself; they describe every ONU, so scrapes need read:metrics
+	router.Group(func(r chi.Router) {
+		if global != nil && global.auth != nil {
+			r.Use(middleware.Authenticate(global.auth))
+		}
+		r.Use(middleware.Authorize(router, routeScope))
+		r.Method(http.MethodGet, "/metrics", metrics.Default.Handler())
+	})
+
 	// Create a group for /api/v1/
 	apiV1Group := chi.NewRouter() // Create a new router instance for API version 1 group
 
+	// API key authentication; every route requires the scope of routeScopes and restricted keys their boards/PONs
+	if global != nil && global.auth != nil {
+		apiV1Group.Use(middleware.Authenticate(global.auth))
+	}
+	apiV1Group.Use(middleware.Authorize(apiV1Group, routeScope))
+
+	// Audit log of the requests that change the default OLT; the other OLTs are audited by oltDispatcher
+	var auditLog middleware.AuditRecorder
+	if global != nil && global.auditLog != nil {
+		auditLog = global.auditLog
+		apiV1Group.Use(middleware.Audit(apiV1Group, auditLog, auditOperation, defaultOLT.oltID))
+	}
+
+	// Routes of the default OLT are served directly under /api/v1 (backwards compatible)
+	registerOLTRoutes(apiV1Group, defaultOLT)
+
+	// Define routes for /api/v1/olts (multi-OLT registry)
+	apiV1Group.Route("/olts", func(r chi.Router) {
+		if global != nil && global.olt != nil {
+			r.Get("/", global.olt.ListOLTs) // GET list all registered OLTs
+		}
+		r.Route("/{olt_id}", func(r chi.Router) {
+			if global != nil && global.olt != nil {
+				r.Get("/", global.olt.GetOLT) // GET OLT details
+			}
+			r.Mount("/", oltDispatcher(olts, auditLog)) // All per-OLT routes, e.g. /olts/{olt_id}/board/1/pon/1
+		})
+	})
+
+	// Define routes for /api/v1/events (SNMP traps and informs of all OLTs)
+	if global != nil && global.events != nil {
+		apiV1Group.Get("/events", global.events.ListEvents) // GET list received events
+	}
+
+	// Define routes for /api/v1/audit (audit log of OLT changes)
+	if global != nil && global.audit != nil {
+		apiV1Group.Get("/audit", global.audit.ListEntries)      // GET list audit log entries
+		apiV1Group.Get("/audit/verify", global.audit.VerifyLog) // GET verify the hash chain of the audit log
+	}
+
+	// Define routes for /api/v1/jobs (background jobs of all OLTs)
+	if global != nil && global.jobs != nil {
+		apiV1Group.Route("/jobs", func(r chi.Router) {
+			r.Get("/", global.jobs.ListJobs)                 // GET list jobs
+			r.Get("/{jobId}", global.jobs.GetJob)            // GET job with per-target progress
+			r.Post("/{jobId}/cancel", global.jobs.CancelJob) // POST cancel job
+			r.Post("/{jobId}/resume", global.jobs.ResumeJob) // POST resume failed or canceled job
+		})
+	}
+
+	// Define routes for /api/v1/auth (API key administration)
+	if global != nil && global.apiKeys != nil {
+		apiV1Group.Route("/auth/keys", func(r chi.Router) {
+			r.Get("/", global.apiKeys.ListKeys)                 // GET list API keys
+			r.Post("/", global.apiKeys.IssueKey)                // POST issue API key
+			r.Get("/{keyId}", global.apiKeys.GetKey)            // GET API key
+			r.Delete("/{keyId}", global.apiKeys.RevokeKey)      // DELETE revoke API key
+			r.Post("/{keyId}/rotate", global.apiKeys.RotateKey) // POST rotate API key
+		})
+	}
+
+	// Mount /api/v1/ to root router
+	router.Mount("/api/v1", apiV1Group) // Mount the API v1 group to the main router under /api/v1 prefix
+
+	return router // Return the configured router
+}
+
+// registerOLTRoutes registers all routes that operate on a single OLT
+func registerOLTRoutes(apiV1Group chi.Router, h *routeHandlers) {
 	// Define routes for /api/v1/
 	apiV1Group.Route("/board", func(r chi.Router) { // Create a route group starting with "/board"
 		r.Route("/{board_id}/pon/{pon_id}", func(r chi.Router) { // Nested route group with board_id and pon_id parameters
 			// Apply validation middleware for board_id and pon_id
 			r.Use(middleware.ValidateBoardPonParams) // specific middleware to validate these parameters
 
-			r.Get("/", onuHandler.GetByBoardIDAndPonID)             // GET /board/{board_id}/pon/{pon_id}/ - Fetch ONUs by board and PON
-			r.Delete("/", onuHandler.DeleteCache)                   // DELETE /board/{board_id}/pon/{pon_id}/ - Delete cache for board/pon
-			r.Get("/info", ponHandler.GetPonPortInfo)               // GET .../info - Fetch PON port information
-			r.Get("/onu_id/empty", onuHandler.GetEmptyOnuID)        // GET .../onu_id/empty - Fetch empty ONU IDs
-			r.Get("/onu_id_sn", onuHandler.GetOnuIDAndSerialNumber) // GET .../onu_id_sn - Fetch ONU IDs and serial numbers
-			r.Get("/onu_id/update", onuHandler.UpdateEmptyOnuID)    // GET .../onu_id/update - Update empty ONU IDs (Note: GET used for update seems unusual but following existing code)
+			r.Get("/", h.onu.GetByBoardIDAndPonID)             // GET /board/{board_id}/pon/{pon_id}/ - Fetch ONUs by board and PON
+			r.Delete("/", h.onu.DeleteCache)                   // DELETE /board/{board_id}/pon/{pon_id}/ - Delete cache for board/pon
+			r.Get("/info", h.pon.GetPonPortInfo)               // GET .../info - Fetch PON port information
+			r.Get("/onu_id/empty", h.onu.GetEmptyOnuID)        // GET .../onu_id/empty - Fetch empty ONU IDs
+			r.Get("/onu_id_sn", h.onu.GetOnuIDAndSerialNumber) // GET .../onu_id_sn - Fetch ONU IDs and serial numbers
+			r.Get("/onu_id/update", h.onu.UpdateEmptyOnuID)    // GET .../onu_id/update - Update empty ONU IDs (Note: GET used for update seems unusual but following existing code)
 
 			// Routes with onu_id parameter
 			r.Route("/onu/{onu_id}", func(r chi.Router) { // Nested route group for specific ONU ID
-				r.Use(middleware.ValidateOnuIDParam)             // Validate onu_id parameter
-				r.Get("/", onuHandler.GetByBoardIDPonIDAndOnuID) // GET .../onu/{onu_id} - Fetch specific ONU details
+				r.Use(middleware.ValidateOnuIDParam)        // Validate onu_id parameter
+				r.Get("/", h.onu.GetByBoardIDPonIDAndOnuID) // GET .../onu/{onu_id} - Fetch specific ONU details
 			})
 		})
 	})
@@ -67,112 +249,156 @@ func loadRoutes(onuHandler *handler.OnuHandler, ponHandler *handler.PonHandler,
 	// Define routes for /api/v1/paginate
 	apiV1Group.Route("/paginate", func(r chi.Router) { // Create a route group for pagination
 		r.Route("/board/{board_id}/pon/{pon_id}", func(r chi.Router) { // Nested route group with board and PON IDs
-			r.Use(middleware.ValidateBoardPonParams)                // Apply parameter validation
-			r.Get("/", onuHandler.GetByBoardIDAndPonIDWithPaginate) // GET .../ - Fetch paginated ONU list
+			r.Use(middleware.ValidateBoardPonParams)           // Apply parameter validation
+			r.Get("/", h.onu.GetByBoardIDAndPonIDWithPaginate) // GET .../ - Fetch paginated ONU list
 		})
 	})
 
 	// Define routes for /api/v1/profiles
 	apiV1Group.Route("/profiles", func(r chi.Router) { // Create a route group for profiles
 		r.Route("/traffic", func(r chi.Router) { // Nested route group for traffic profiles
-			r.Get("/", profileHandler.GetAllTrafficProfiles)         // GET /profiles/traffic - Fetch all traffic profiles
-			r.Get("/{profile_id}", profileHandler.GetTrafficProfile) // GET /profiles/traffic/{profile_id} - Fetch specific traffic profile
+			r.Get("/", h.profile.GetAllTrafficProfiles)         // GET /profiles/traffic - Fetch all traffic profiles
+			r.Get("/{profile_id}", h.profile.GetTrafficProfile) // GET /profiles/traffic/{profile_id} - Fetch specific traffic profile
 		})
 		r.Route("/vlan", func(r chi.Router) { // Nested route group for VLAN profiles
-			r.Get("/", profileHandler.GetAllVlanProfiles) // GET /profiles/vlan - Fetch all VLAN profiles
+			r.Get("/", h.profile.GetAllVlanProfiles) // GET /profiles/vlan - Fetch all VLAN profiles
 		})
 	})
 
 	// Define routes for /api/v1/system
 	apiV1Group.Route("/system", func(r chi.Router) { // Create a route group for system information
+		r.Get("/info", h.system.GetSystemInfo) // GET /system/info - Fetch OLT model, software version and OID profile
 		r.Route("/cards", func(r chi.Router) { // Nested route group for card/slot info
-			r.Get("/", cardHandler.GetAllCards)                  // GET /system/cards - Fetch all cards
-			r.Get("/{rack}/{shelf}/{slot}", cardHandler.GetCard) // GET /system/cards/{rack}/{shelf}/{slot} - Fetch specific card
+			r.Get("/", h.card.GetAllCards)                  // GET /system/cards - Fetch all cards
+			r.Get("/{rack}/{shelf}/{slot}", h.card.GetCard) // GET /system/cards/{rack}/{shelf}/{slot} - Fetch specific card
 		})
 	})
 
 	// Define routes for /api/v1/onu (provisioning)
 	apiV1Group.Route("/onu", func(r chi.Router) {
-		r.Get("/unconfigured", provisionHandler.GetUnconfiguredONUs)            // GET all unconfigured ONUs
-		r.Get("/unconfigured/{pon}", provisionHandler.GetUnconfiguredONUsByPON) // GET unconfigured ONUs by PON port
-		r.Post("/register", provisionHandler.RegisterONU)                       // POST register new ONU
-		r.Delete("/{pon}/{onu_id}", provisionHandler.DeleteONU)                 // DELETE ONU
+		r.Get("/unconfigured", h.provision.GetUnconfiguredONUs)            // GET all unconfigured ONUs
+		r.Get("/unconfigured/{pon}", h.provision.GetUnconfiguredONUsByPON) // GET unconfigured ONUs by PON port
+		r.Post("/register", h.provision.RegisterONU)                       // POST register new ONU
+		r.Delete("/{pon}/{onu_id}", h.provision.DeleteONU)                 // DELETE ONU
 	})
 
 	// Define routes for /api/v1/vlan (VLAN management)
 	apiV1Group.Route("/vlan", func(r chi.Router) {
-		r.Get("/onu/{pon}/{onu_id}", vlanHandler.GetONUVLAN)    // GET ONU VLAN configuration
-		r.Get("/service-ports", vlanHandler.GetAllServicePorts) // GET all service-port configurations
-		r.Post("/onu", vlanHandler.ConfigureVLAN)               // POST configure ONU VLAN
-		r.Put("/onu", vlanHandler.ModifyVLAN)                   // PUT modify ONU VLAN
-		r.Delete("/onu/{pon}/{onu_id}", vlanHandler.DeleteVLAN) // DELETE ONU VLAN
+		r.Get("/onu/{pon}/{onu_id}", h.vlan.GetONUVLAN)    // GET ONU VLAN configuration
+		r.Get("/service-ports", h.vlan.GetAllServicePorts) // GET all service-port configurations
+		r.Post("/onu", h.vlan.ConfigureVLAN)               // POST configure ONU VLAN
+		r.Put("/onu", h.vlan.ModifyVLAN)                   // PUT modify ONU VLAN
+		r.Delete("/onu/{pon}/{onu_id}", h.vlan.DeleteVLAN) // DELETE ONU VLAN
 	})
 
 	// Define routes for /api/v1/traffic (Traffic profile management)
 	apiV1Group.Route("/traffic", func(r chi.Router) {
 		// DBA Profile routes
-		r.Get("/dba-profiles", trafficHandler.GetAllDBAProfiles)         // GET all DBA profiles
-		r.Get("/dba-profile/{name}", trafficHandler.GetDBAProfile)       // GET specific DBA profile
-		r.Post("/dba-profile", trafficHandler.CreateDBAProfile)          // POST create DBA profile
-		r.Put("/dba-profile", trafficHandler.ModifyDBAProfile)           // PUT modify DBA profile
-		r.Delete("/dba-profile/{name}", trafficHandler.DeleteDBAProfile) // DELETE DBA profile
+		r.Get("/dba-profiles", h.traffic.GetAllDBAProfiles)         // GET all DBA profiles
+		r.Get("/dba-profile/{name}", h.traffic.GetDBAProfile)       // GET specific DBA profile
+		r.Post("/dba-profile", h.traffic.CreateDBAProfile)          // POST create DBA profile
+		r.Put("/dba-profile", h.traffic.ModifyDBAProfile)           // PUT modify DBA profile
+		r.Delete("/dba-profile/{name}", h.traffic.DeleteDBAProfile) // DELETE DBA profile
 
 		// TCONT routes
-		r.Get("/tcont/{pon}/{onu_id}/{tcont_id}", trafficHandler.GetONUTCONT)    // GET T-CONT configuration
-		r.Post("/tcont", trafficHandler.ConfigureTCONT)                          // POST configure T-CONT
-		r.Delete("/tcont/{pon}/{onu_id}/{tcont_id}", trafficHandler.DeleteTCONT) // DELETE T-CONT
+		r.Get("/tcont/{pon}/{onu_id}/{tcont_id}", h.traffic.GetONUTCONT)    // GET T-CONT configuration
+		r.Post("/tcont", h.traffic.ConfigureTCONT)                          // POST configure T-CONT
+		r.Delete("/tcont/{pon}/{onu_id}/{tcont_id}", h.traffic.DeleteTCONT) // DELETE T-CONT
 
 		// GEMPort routes
-		r.Post("/gemport", trafficHandler.ConfigureGEMPort)                            // POST configure GEM port
-		r.Delete("/gemport/{pon}/{onu_id}/{gemport_id}", trafficHandler.DeleteGEMPort) // DELETE GEM port
+		r.Post("/gemport", h.traffic.ConfigureGEMPort)                            // POST configure GEM port
+		r.Delete("/gemport/{pon}/{onu_id}/{gemport_id}", h.traffic.DeleteGEMPort) // DELETE GEM port
 	})
 
 	// Define routes for /api/v1/onu-management (ONU lifecycle management)
 	apiV1Group.Route("/onu-management", func(r chi.Router) {
-		r.Post("/reboot", onuMgmtHandler.RebootONU)             // POST reboot ONU
-		r.Post("/block", onuMgmtHandler.BlockONU)               // POST block (disable) ONU
-		r.Post("/unblock", onuMgmtHandler.UnblockONU)           // POST unblock (enable) ONU
-		r.Put("/description", onuMgmtHandler.UpdateDescription) // PUT update ONU description
-		r.Delete("/{pon}/{onu_id}", onuMgmtHandler.DeleteONU)   // DELETE ONU configuration
+		r.Post("/reboot", h.onuMgmt.RebootONU)             // POST reboot ONU
+		r.Post("/block", h.onuMgmt.BlockONU)               // POST block (disable) ONU
+		r.Post("/unblock", h.onuMgmt.UnblockONU)           // POST unblock (enable) ONU
+		r.Put("/description", h.onuMgmt.UpdateDescription) // PUT update ONU description
+		r.Delete("/{pon}/{onu_id}", h.onuMgmt.DeleteONU)   // DELETE ONU configuration
 	})
 
 	// Define routes for /api/v1/batch (Batch operations - Phase 6.1)
 	apiV1Group.Route("/batch", func(r chi.Router) {
-		r.Post("/reboot", batchHandler.BatchRebootONUs)              // POST batch reboot ONUs
-		r.Post("/block", batchHandler.BatchBlockONUs)                // POST batch block ONUs
-		r.Post("/unblock", batchHandler.BatchUnblockONUs)            // POST batch unblock ONUs
-		r.Post("/delete", batchHandler.BatchDeleteONUs)              // POST batch delete ONUs
-		r.Put("/descriptions", batchHandler.BatchUpdateDescriptions) // PUT batch update descriptions
+		r.Post("/reboot", h.batch.BatchRebootONUs)              // POST batch reboot ONUs
+		r.Post("/block", h.batch.BatchBlockONUs)                // POST batch block ONUs
+		r.Post("/unblock", h.batch.BatchUnblockONUs)            // POST batch unblock ONUs
+		r.Post("/delete", h.batch.BatchDeleteONUs)              // POST batch delete ONUs
+		r.Put("/descriptions", h.batch.BatchUpdateDescriptions) // PUT batch update descriptions
 	})
 
 	// Define routes for /api/v1/config (Configuration backup/restore - Phase 6.2)
 	apiV1Group.Route("/config", func(r chi.Router) {
 		// Backup operations
-		r.Post("/backup/onu/{pon}/{onuId}", configBackupHandler.BackupONU) // POST backup single ONU
-		r.Post("/backup/olt", configBackupHandler.BackupOLT)               // POST backup entire OLT
-		r.Post("/backup/import", configBackupHandler.ImportBackup)         // POST import backup from file
+		r.Post("/backup/onu/{pon}/{onuId}", h.configBackup.BackupONU) // POST backup single ONU
+		r.Post("/backup/olt", h.configBackup.BackupOLT)               // POST backup entire OLT
+		r.Post("/backup/import", h.configBackup.ImportBackup)         // POST import backup from file
 
 		// Backup management
-		r.Get("/backups", configBackupHandler.ListBackups)                   // GET list all backups
-		r.Get("/backup/{backupId}", configBackupHandler.GetBackup)           // GET specific backup
-		r.Delete("/backup/{backupId}", configBackupHandler.DeleteBackup)     // DELETE backup
-		r.Get("/backup/{backupId}/export", configBackupHandler.ExportBackup) // GET export backup as file
+		r.Get("/backups", h.configBackup.ListBackups)                   // GET list all backups
+		r.Get("/backups/diff", h.configBackup.DiffBackups)              // GET diff between two backups
+		r.Get("/backup/{backupId}", h.configBackup.GetBackup)           // GET specific backup
+		r.Delete("/backup/{backupId}", h.configBackup.DeleteBackup)     // DELETE backup
+		r.Get("/backup/{backupId}/export", h.configBackup.ExportBackup) // GET export backup as file
+		r.Get("/backup/{backupId}/drift", h.configBackup.DetectDrift)   // GET drift between backup and live OLT
 
 		// Restore operations
-		r.Post("/restore/{backupId}", configBackupHandler.RestoreFromBackup) // POST restore from backup
+		r.Post("/restore/{backupId}", h.configBackup.RestoreFromBackup) // POST restore from backup
+
+		// Running configuration
+		r.Get("/running", h.configBackup.GetRunningConfig) // GET parsed (or raw) running-config
+
+		// Scheduled backups
+		r.Get("/schedules", h.schedule.ListSchedules)                  // GET list backup schedules
+		r.Post("/schedules", h.schedule.CreateSchedule)                // POST create backup schedule
+		r.Get("/schedules/{scheduleId}", h.schedule.GetSchedule)       // GET backup schedule
+		r.Put("/schedules/{scheduleId}", h.schedule.UpdateSchedule)    // PUT update schedule or retention
+		r.Delete("/schedules/{scheduleId}", h.schedule.DeleteSchedule) // DELETE backup schedule
+		r.Post("/schedules/{scheduleId}/run", h.schedule.RunSchedule)  // POST run schedule now
 	})
 
 	// Define routes for /api/v1/monitoring (Phase 7.1)
 	apiV1Group.Route("/monitoring", func(r chi.Router) {
-		r.Get("/onu/{pon}/{onuId}", monitoringHandler.GetONUMonitoring) // GET real-time ONU monitoring
-		r.Get("/pon/{pon}", monitoringHandler.GetPONMonitoring)         // GET PON monitoring with all ONUs
-		r.Get("/olt", monitoringHandler.GetOLTMonitoring)               // GET OLT summary
+		r.Get("/onu/{pon}/{onuId}", h.monitoring.GetONUMonitoring) // GET real-time ONU monitoring on board 1
+		r.Get("/pon/{pon}", h.monitoring.GetPONMonitoring)         // GET PON monitoring with all ONUs on board 1
+		r.Get("/olt", h.monitoring.GetOLTMonitoring)               // GET OLT summary with per-board counts
+		r.Get("/stream", h.stream.StreamMonitoring)                // GET live updates as Server-Sent Events
+		r.Route("/board/{board_id}/pon/{pon_id}", func(r chi.Router) {
+			r.Use(middleware.ValidateBoardPonParams)
+			r.Get("/", h.monitoring.GetBoardPONMonitoring)       // GET PON monitoring with all ONUs on any board
+			r.Post("/jobs", h.monitoring.SubmitPONMonitoringJob) // POST read PON monitoring in a background job
+			r.Route("/onu/{onu_id}", func(r chi.Router) {
+				r.Use(middleware.ValidateOnuIDParam)
+				r.Get("/", h.monitoring.GetBoardONUMonitoring) // GET real-time ONU monitoring on any board
+			})
+		})
 	})
+}
 
-	// Mount /api/v1/ to root router
-	router.Mount("/api/v1", apiV1Group) // Mount the API v1 group to the main router under /api/v1 prefix
+// oltDispatcher routes /api/v1/olts/{olt_id}/... to the router of the addressed OLT.
+// Changes are recorded in auditLog unless it is nil.
+func oltDispatcher(olts map[string]*routeHandlers, auditLog middleware.AuditRecorder) http.Handler {
+	routers := make(map[string]http.Handler, len(olts))
+	for id, h := range olts {
+		r := chi.NewRouter()
+		r.Use(middleware.Authorize(r, routeScope))
+		if auditLog != nil {
+			r.Use(middleware.Audit(r, auditLog, auditOperation, id))
+		}
+		registerOLTRoutes(r, h)
+		routers[id] = r
+	}
 
-	return router // Return the configured router
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		oltID := chi.URLParam(r, "olt_id")
+		router, ok := routers[oltID]
+		if !ok {
+			utils.HandleError(w, apperrors.NewNotFoundError("OLT", oltID))
+			return
+		}
+		router.ServeHTTP(w, r)
+	})
 }
 
 // rootHandler is a simple handler for a root endpoint
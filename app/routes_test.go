@@ -1,13 +1,21 @@
 package app
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/go-chi/chi/v5"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
 	"github.com/s4lfanet/go-api-c320/internal/handler"
 	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
 	"github.com/s4lfanet/go-api-c320/internal/usecase"
 )
 
@@ -23,6 +31,9 @@ type mockProfileUsecase struct{}
 // mockCardUsecase for testing routes
 type mockCardUsecase struct{}
 
+// mockSystemUsecase for testing routes
+type mockSystemUsecase struct{}
+
 func (m *mockOnuUsecase) GetByBoardIDAndPonID(ctx context.Context, boardID, ponID int) ([]model.ONUInfoPerBoard, error) {
 	return nil, nil
 }
@@ -78,6 +89,11 @@ func (m *mockCardUsecase) GetCard(ctx context.Context, rack, shelf, slot int) (*
 	return &model.CardInfo{}, nil
 }
 
+// Mock methods for SystemUsecase
+func (m *mockSystemUsecase) GetSystemInfo(ctx context.Context) (*model.SystemInfo, error) {
+	return &model.SystemInfo{}, nil
+}
+
 func TestRootHandler(t *testing.T) {
 	req := httptest.NewRequest("GET", "/", nil)
 	rr := httptest.NewRecorder()
@@ -104,20 +120,31 @@ func TestLoadRoutes(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	if router == nil {
 		t.Error("Expected non-nil router")
@@ -134,19 +161,30 @@ func TestLoadRoutes_RootEndpoint(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	rr := httptest.NewRecorder()
@@ -168,19 +206,30 @@ func TestLoadRoutes_MiddlewareApplied(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	req := httptest.NewRequest("GET", "/", nil)
 	rr := httptest.NewRecorder()
@@ -209,19 +258,30 @@ func TestLoadRoutes_CORSHeaders(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	req := httptest.NewRequest("OPTIONS", "/", nil)
 	req.Header.Set("Origin", "http://localhost:3000")
@@ -245,20 +305,31 @@ func TestLoadRoutes_APIv1ProfileRoutes(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	tests := []struct {
 		name   string
@@ -293,20 +364,32 @@ func TestLoadRoutes_APIv1SystemRoutes(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+		system:       handler.NewSystemHandler(&mockSystemUsecase{}),
+	}, nil, nil)
 
 	tests := []struct {
 		name   string
@@ -315,6 +398,7 @@ func TestLoadRoutes_APIv1SystemRoutes(t *testing.T) {
 	}{
 		{"GET all cards", "/api/v1/system/cards/", http.StatusOK},
 		{"GET specific card", "/api/v1/system/cards/1/1/1", http.StatusOK},
+		{"GET system info", "/api/v1/system/info", http.StatusOK},
 	}
 
 	for _, tt := range tests {
@@ -340,19 +424,30 @@ func TestLoadRoutes_APIv1RouteNotFound(t *testing.T) {
 	ponHandler := handler.NewPonHandler(ponUsecase)
 	profileHandler := handler.NewProfileHandler(profileUsecase)
 	cardHandler := handler.NewCardHandler(cardUsecase)
-	provisionUsecase := usecase.NewProvisionUsecase(nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
 	provisionHandler := handler.NewProvisionHandler(provisionUsecase)
-	vlanUsecase := usecase.NewVLANUsecase(nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
 	vlanHandler := handler.NewVLANHandler(vlanUsecase)
-	trafficUsecase := usecase.NewTrafficUsecase(nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
 	var trafficHandler handler.TrafficHandlerInterface = handler.NewTrafficHandler(trafficUsecase)
-	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil)
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
 	onuMgmtHandler := handler.NewONUManagementHandler(onuMgmtUsecase)
-	batchUsecase := usecase.NewBatchOperationsUsecase(nil, onuMgmtUsecase, nil)
+	batchUsecase := usecase.NewBatchOperationsUsecase(nil)
 	batchHandler := handler.NewBatchOperationsHandler(batchUsecase)
-	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, onuMgmtUsecase, vlanUsecase, trafficUsecase, provisionUsecase)
-	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase)
-	router := loadRoutes(onuHandler, ponHandler, profileHandler, cardHandler, provisionHandler, vlanHandler, trafficHandler, onuMgmtHandler, batchHandler, configBackupHandler, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+	configBackupHandler := handler.NewConfigBackupHandler(configBackupUsecase, nil)
+	router := loadRoutes(&routeHandlers{
+		onu:          onuHandler,
+		pon:          ponHandler,
+		profile:      profileHandler,
+		card:         cardHandler,
+		provision:    provisionHandler,
+		vlan:         vlanHandler,
+		traffic:      trafficHandler,
+		onuMgmt:      onuMgmtHandler,
+		batch:        batchHandler,
+		configBackup: configBackupHandler,
+	}, nil, nil)
 
 	req := httptest.NewRequest("GET", "/api/v1/nonexistent", nil)
 	rr := httptest.NewRecorder()
@@ -363,3 +458,366 @@ func TestLoadRoutes_APIv1RouteNotFound(t *testing.T) {
 		t.Errorf("Expected status %d for non-existent route, got %d", http.StatusNotFound, rr.Code)
 	}
 }
+
+func TestLoadRoutes_PerOLTRoutes(t *testing.T) {
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
+	vlanUsecase := usecase.NewVLANUsecase(nil, nil, nil)
+	trafficUsecase := usecase.NewTrafficUsecase(nil, nil, nil)
+	provisionUsecase := usecase.NewProvisionUsecase(nil, nil, nil)
+	configBackupUsecase := usecase.NewConfigBackupUsecase(nil, nil, nil, nil)
+
+	h := &routeHandlers{
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(provisionUsecase),
+		vlan:         handler.NewVLANHandler(vlanUsecase),
+		traffic:      handler.NewTrafficHandler(trafficUsecase),
+		onuMgmt:      handler.NewONUManagementHandler(onuMgmtUsecase),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(configBackupUsecase, nil),
+	}
+
+	router := loadRoutes(h, map[string]*routeHandlers{"default": h, "core-1": h}, nil)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{"default OLT legacy path", "/api/v1/profiles/traffic/", http.StatusOK},
+		{"default OLT by id", "/api/v1/olts/default/profiles/traffic/", http.StatusOK},
+		{"second OLT", "/api/v1/olts/core-1/system/cards/", http.StatusOK},
+		{"unknown OLT", "/api/v1/olts/unknown/profiles/traffic/", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, rr.Code)
+			}
+		})
+	}
+}
+
+func TestLoadRoutes_MetricsEndpoint(t *testing.T) {
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
+	h := &routeHandlers{
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(usecase.NewProvisionUsecase(nil, nil, nil)),
+		vlan:         handler.NewVLANHandler(usecase.NewVLANUsecase(nil, nil, nil)),
+		traffic:      handler.NewTrafficHandler(usecase.NewTrafficUsecase(nil, nil, nil)),
+		onuMgmt:      handler.NewONUManagementHandler(onuMgmtUsecase),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(usecase.NewConfigBackupUsecase(nil, nil, nil, nil), nil),
+	}
+	router := loadRoutes(h, map[string]*routeHandlers{"default": h}, nil)
+
+	// Per-OLT requests are recorded by their route pattern, not by the requested IDs
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/v1/olts/default/system/cards/", nil))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest("GET", "/metrics", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("Expected status OK for /metrics, got %d", rr.Code)
+	}
+	body := rr.Body.String()
+	if !strings.Contains(body, `route="/api/v1/olts/{olt_id}/system/cards"`) {
+		t.Errorf("request latency of the per-OLT route not exported:\n%s", body)
+	}
+}
+
+func TestLoadRoutes_MonitoringStream(t *testing.T) {
+	cfg := &config.Config{BoardPonMap: map[config.BoardPonKey]*config.BoardPonConfig{{BoardID: 1, PonID: 1}: {}}}
+	stream := usecase.NewMonitoringStreamUsecase() // No collector: only traps are streamed
+	stream.RegisterOLT("core-1", cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go stream.Run(ctx)
+
+	h := &routeHandlers{
+		oltID:        "core-1",
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(usecase.NewProvisionUsecase(nil, nil, nil)),
+		vlan:         handler.NewVLANHandler(usecase.NewVLANUsecase(nil, nil, nil)),
+		traffic:      handler.NewTrafficHandler(usecase.NewTrafficUsecase(nil, nil, nil)),
+		onuMgmt:      handler.NewONUManagementHandler(usecase.NewONUManagementUsecase(nil, nil, nil)),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(usecase.NewConfigBackupUsecase(nil, nil, nil, nil), nil),
+		stream:       handler.NewMonitoringStreamHandler("core-1", stream),
+	}
+	server := httptest.NewServer(loadRoutes(h, map[string]*routeHandlers{"core-1": h}, nil))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/monitoring/stream?pon=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("stream with pon but no board = %d, want 400", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/v1/olts/core-1/monitoring/stream?board=1&pon=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("stream = %d %s, want 200 text/event-stream", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// Subscribed once the headers are sent; the trap is pushed as event and as ONU status
+	stream.PublishEvent(model.OLTEvent{OLTID: "core-1", Type: model.EventOnuOffline, Board: 1, PON: 1, ONUID: 5, Status: "LOS"})
+	scanner := bufio.NewScanner(resp.Body)
+	var events []string
+	for len(events) < 2 && scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+		if data, ok := strings.CutPrefix(scanner.Text(), "data: "); ok && len(events) == 2 {
+			var update model.MonitoringUpdate
+			if err := json.Unmarshal([]byte(data), &update); err != nil || update.ONUID != 5 || update.Status == nil || update.Status.Online {
+				t.Errorf("onu_status data = %s (%v)", data, err)
+			}
+		}
+	}
+	if strings.Join(events, ",") != "event,onu_status" {
+		t.Errorf("stream events = %v, want event,onu_status", events)
+	}
+}
+
+// staticAuthenticator accepts the keys of a map
+type staticAuthenticator map[string]*model.Principal
+
+func (a staticAuthenticator) Authenticate(_ context.Context, key string) (*model.Principal, error) {
+	if principal, ok := a[key]; ok {
+		return principal, nil
+	}
+	return nil, apperrors.NewUnauthorizedError("invalid API key")
+}
+
+func TestRouteScope(t *testing.T) {
+	tests := []struct {
+		method  string
+		pattern string
+		want    string
+	}{
+		{"GET", "/board/{board_id}/pon/{pon_id}/", model.ScopeReadONU},
+		{"DELETE", "/board/{board_id}/pon/{pon_id}/", model.ScopeWriteONU},
+		{"POST", "/onu/register", model.ScopeWriteONU},
+		{"POST", "/onu-management/reboot", model.ScopeWriteONU},
+		{"GET", "/vlan/service-ports", model.ScopeReadONU},
+		{"POST", "/vlan/onu", model.ScopeWriteVLAN},
+		{"DELETE", "/traffic/dba-profile/{name}", model.ScopeWriteTraffic},
+		{"POST", "/batch/delete", model.ScopeBatch},
+		{"GET", "/config/backups", model.ScopeAdminConfig},
+		{"POST", "/config/restore/{backupId}", model.ScopeAdminConfig},
+		{"POST", "/auth/keys/", model.ScopeAdminKeys},
+		{"GET", "/audit/verify", model.ScopeReadAudit},
+		{"POST", "/jobs/{jobId}/cancel", ""},
+		{"POST", "/monitoring/board/{board_id}/pon/{pon_id}/jobs", model.ScopeReadONU},
+		{"GET", "/monitoring/stream", model.ScopeReadONU},
+		{"GET", "/events", model.ScopeReadONU},
+		{"POST", "/olts/{olt_id}/*", ""},
+		{"GET", "/metrics", model.ScopeReadMetrics},
+	}
+
+	for _, tt := range tests {
+		if got := routeScope(tt.method, tt.pattern); got != tt.want {
+			t.Errorf("routeScope(%s %s) = %q, want %q", tt.method, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestLoadRoutes_Authentication(t *testing.T) {
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
+	h := &routeHandlers{
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(usecase.NewProvisionUsecase(nil, nil, nil)),
+		vlan:         handler.NewVLANHandler(usecase.NewVLANUsecase(nil, nil, nil)),
+		traffic:      handler.NewTrafficHandler(usecase.NewTrafficUsecase(nil, nil, nil)),
+		onuMgmt:      handler.NewONUManagementHandler(onuMgmtUsecase),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(usecase.NewConfigBackupUsecase(nil, nil, nil, nil), nil),
+	}
+	router := loadRoutes(h, map[string]*routeHandlers{"default": h, "core-1": h}, &globalHandlers{
+		auth: staticAuthenticator{
+			"reader":     {ID: "reader", Scopes: []string{model.ScopeReadONU}},
+			"pon-1-2":    {ID: "pon-1-2", Scopes: []string{model.ScopeReadONU}, PONs: []model.BoardPON{{Board: 1, PON: 2}}},
+			"prometheus": {ID: "prometheus", Scopes: []string{model.ScopeReadMetrics}},
+			"restricted-prometheus": {ID: "restricted-prometheus", Scopes: []string{model.ScopeReadMetrics},
+				Boards: []int{1}},
+			"technician": {ID: "2b7c", Name: "bob", Method: model.AuthMethodOIDC, Roles: []string{model.RoleTechnician},
+				Scopes: model.RoleScopes[model.RoleTechnician]},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		key    string
+		status int
+	}{
+		{"root is open", "GET", "/", "", http.StatusOK},
+		{"metrics without key", "GET", "/metrics", "", http.StatusUnauthorized},
+		{"metrics without scope", "GET", "/metrics", "reader", http.StatusForbidden},
+		{"metrics", "GET", "/metrics", "prometheus", http.StatusOK},
+		{"restricted metrics", "GET", "/metrics", "restricted-prometheus", http.StatusForbidden},
+		{"no key", "GET", "/api/v1/profiles/traffic/", "", http.StatusUnauthorized},
+		{"invalid key", "GET", "/api/v1/profiles/traffic/", "guess", http.StatusUnauthorized},
+		{"read", "GET", "/api/v1/profiles/traffic/", "reader", http.StatusOK},
+		{"read on second OLT", "GET", "/api/v1/olts/core-1/system/cards/", "reader", http.StatusOK},
+		{"batch without scope", "POST", "/api/v1/batch/delete", "reader", http.StatusForbidden},
+		{"batch without scope on second OLT", "POST", "/api/v1/olts/core-1/batch/delete", "reader", http.StatusForbidden},
+		{"restore without scope", "POST", "/api/v1/config/restore/backup-1", "reader", http.StatusForbidden},
+		{"restricted own PON", "GET", "/api/v1/board/1/pon/2/info", "pon-1-2", http.StatusOK},
+		{"restricted own PON on second OLT", "GET", "/api/v1/olts/core-1/board/1/pon/2/info", "pon-1-2", http.StatusOK},
+		{"restricted other PON", "GET", "/api/v1/olts/core-1/board/2/pon/2/info", "pon-1-2", http.StatusForbidden},
+		{"restricted OLT-wide route", "GET", "/api/v1/system/cards/", "pon-1-2", http.StatusForbidden},
+		{"technician role reads", "GET", "/api/v1/olts/core-1/system/cards/", "technician", http.StatusOK},
+		{"technician role cannot restore", "POST", "/api/v1/config/restore/backup-1", "technician", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.status {
+				t.Errorf("Expected status %d, got %d: %s", tt.status, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuditOperations_AreRoutes(t *testing.T) {
+	routes := chi.NewRouter()
+	registerOLTRoutes(routes, &routeHandlers{
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(usecase.NewProvisionUsecase(nil, nil, nil)),
+		vlan:         handler.NewVLANHandler(usecase.NewVLANUsecase(nil, nil, nil)),
+		traffic:      handler.NewTrafficHandler(usecase.NewTrafficUsecase(nil, nil, nil)),
+		onuMgmt:      handler.NewONUManagementHandler(usecase.NewONUManagementUsecase(nil, nil, nil)),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(usecase.NewConfigBackupUsecase(nil, nil, nil, nil), nil),
+	})
+
+	registered := make(map[string]bool)
+	_ = chi.Walk(routes, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		registered[method+" "+route] = true
+		return nil
+	})
+	for route := range auditOperations {
+		if !registered[route] {
+			t.Errorf("audited route %s is not registered", route)
+		}
+	}
+	// Other writes only touch the API's own cache, backups and schedules, or submit jobs, which record their runs
+	for route := range registered {
+		method, pattern, _ := strings.Cut(route, " ")
+		if method != http.MethodGet && auditOperations[route] == "" &&
+			!strings.HasPrefix(pattern, "/config/") && !strings.HasPrefix(pattern, "/board/") &&
+			!strings.HasPrefix(pattern, "/batch/") && !strings.HasPrefix(pattern, "/monitoring/") {
+			t.Errorf("route %s changes the OLT but is not audited", route)
+		}
+	}
+}
+
+func TestLoadRoutes_AuditLog(t *testing.T) {
+	store, err := repository.NewFileAuditStore(filepath.Join(t.TempDir(), "audit.log"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	auditUsecase := usecase.NewAuditUsecase(store, nil)
+
+	onuMgmtUsecase := usecase.NewONUManagementUsecase(nil, nil, nil)
+	h := &routeHandlers{
+		onu:          handler.NewOnuHandler(&mockOnuUsecase{}),
+		pon:          handler.NewPonHandler(&mockPonUsecase{}),
+		profile:      handler.NewProfileHandler(&mockProfileUsecase{}),
+		card:         handler.NewCardHandler(&mockCardUsecase{}),
+		provision:    handler.NewProvisionHandler(usecase.NewProvisionUsecase(nil, nil, nil)),
+		vlan:         handler.NewVLANHandler(usecase.NewVLANUsecase(nil, nil, nil)),
+		traffic:      handler.NewTrafficHandler(usecase.NewTrafficUsecase(nil, nil, nil)),
+		onuMgmt:      handler.NewONUManagementHandler(onuMgmtUsecase),
+		batch:        handler.NewBatchOperationsHandler(usecase.NewBatchOperationsUsecase(nil)),
+		configBackup: handler.NewConfigBackupHandler(usecase.NewConfigBackupUsecase(nil, nil, nil, nil), nil),
+	}
+	defaultOLT, secondOLT := *h, *h
+	defaultOLT.oltID, secondOLT.oltID = "default", "core-1"
+	router := loadRoutes(&defaultOLT, map[string]*routeHandlers{"default": &defaultOLT, "core-1": &secondOLT}, &globalHandlers{
+		audit:    handler.NewAuditHandler(auditUsecase),
+		auditLog: auditUsecase,
+		auth: staticAuthenticator{
+			"technician": {ID: "2b7c", Name: "bob", Method: model.AuthMethodOIDC, Scopes: model.RoleScopes[model.RoleTechnician]},
+			"auditor":    {ID: "auditor", Name: "regulator", Scopes: []string{model.ScopeReadAudit}},
+		},
+	})
+
+	serve := func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Reads are not audited; changes to either OLT are, also when they fail
+	serve("GET", "/api/v1/olts/core-1/system/cards/", "technician", "")
+	serve("POST", "/api/v1/olts/core-1/onu-management/block", "technician", `{"pon_port": "1/1/3", "onu_id": 7}`)
+	serve("POST", "/api/v1/onu-management/reboot", "technician", `{"pon_port": "1/2/1"`)
+
+	if rr := serve("GET", "/api/v1/audit", "technician", ""); rr.Code != http.StatusForbidden {
+		t.Errorf("GET /audit without read:audit = %d, want 403", rr.Code)
+	}
+	rr := serve("GET", "/api/v1/audit?user=bob", "auditor", "")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET /audit = %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp struct {
+		Data []model.AuditEntry `json:"data"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("GET /audit returned %d entries, want 2: %s", len(resp.Data), rr.Body.String())
+	}
+	reboot, block := resp.Data[0], resp.Data[1]
+	if block.OLTID != "core-1" || block.Operation != "onu.block" || block.Targets[0] != (model.AuditTarget{Board: 1, PON: 3, ONUID: 7}) {
+		t.Errorf("block entry = %+v", block)
+	}
+	if reboot.OLTID != "default" || reboot.Operation != "onu.reboot" || reboot.Result != model.AuditResultFailure || reboot.StatusCode != http.StatusBadRequest {
+		t.Errorf("reboot entry = %+v", reboot)
+	}
+
+	rr = serve("GET", "/api/v1/audit/verify", "auditor", "")
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"valid":true`) {
+		t.Errorf("GET /audit/verify = %d: %s", rr.Code, rr.Body.String())
+	}
+}
@@ -0,0 +1,48 @@
+// Command clisim serves a simulated ZTE C320 command line over Telnet, so the API can be run
+// against it without an OLT (TELNET_HOST=127.0.0.1, TELNET_PORT=2323).
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func main() {
+	addr := flag.String("listen", "127.0.0.1:2323", "Telnet listen address")
+	username := flag.String("username", "admin", "Login username")
+	password := flag.String("password", "admin", "Login password")
+	enablePassword := flag.String("enable-password", "", "Password asked by \"enable\" (none if empty)")
+	maxSessions := flag.Int("max-sessions", 4, "Concurrent sessions (VTY lines)")
+	version := flag.String("version", "V2.1.0", "Software version reported by \"show version\"")
+	empty := flag.Bool("empty", false, "Start without the demo ONUs and DBA profiles")
+	flag.Parse()
+
+	state := simulator.NewDemoState()
+	if *empty {
+		state = simulator.NewState()
+	}
+
+	server := simulator.NewCLIServer(simulator.CLIConfig{
+		Username:       *username,
+		Password:       *password,
+		EnablePassword: *enablePassword,
+		MaxSessions:    *maxSessions,
+		Version:        *version,
+	}, state)
+	if err := server.Listen(*addr); err != nil {
+		log.Fatal().Err(err).Str("address", *addr).Msg("Failed to start CLI simulator")
+	}
+	log.Info().Str("address", server.Addr().String()).Msg("C320 CLI simulator listening")
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Info().Int("commands", len(server.Commands())).Msg("CLI simulator is stopping")
+	_ = server.Close()
+}
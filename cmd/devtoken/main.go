@@ -0,0 +1,118 @@
+// Command devtoken issues OIDC-style bearer tokens from a local key set, so token authentication can be
+// tried without an identity provider. It writes the key set to -jwks for AUTH_OIDC_JWKS and prints a token.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/pkg/jwt"
+)
+
+// keyID is the "kid" of the local key
+const keyID = "devtoken"
+
+func main() {
+	keyPath := flag.String("key", "devtoken-key.pem", "EC private key (P-256), generated if missing")
+	jwksPath := flag.String("jwks", "devtoken-jwks.json", "Key set written for AUTH_OIDC_JWKS")
+	issuer := flag.String("issuer", "http://localhost/devtoken", "Token issuer (AUTH_OIDC_ISSUER)")
+	audience := flag.String("audience", "go-api-c320", "Token audience (AUTH_OIDC_AUDIENCE)")
+	user := flag.String("user", "dev", "User name (preferred_username claim)")
+	roles := flag.String("roles", "admin", "Comma-separated roles or groups")
+	rolesClaim := flag.String("roles-claim", "roles", "Claim holding the roles (AUTH_OIDC_ROLES_CLAIM)")
+	ttl := flag.Duration("ttl", time.Hour, "Token lifetime")
+	flag.Parse()
+
+	key, err := loadOrCreateKey(*keyPath)
+	if err != nil {
+		log.Fatal().Err(err).Str("key", *keyPath).Msg("Failed to load signing key")
+	}
+	if err := writeKeySet(*jwksPath, &key.PublicKey); err != nil {
+		log.Fatal().Err(err).Str("jwks", *jwksPath).Msg("Failed to write key set")
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{
+		"iss":                *issuer,
+		"aud":                *audience,
+		"sub":                *user,
+		"preferred_username": *user,
+		"iat":                now.Unix(),
+		"exp":                now.Add(*ttl).Unix(),
+	}
+	setClaim(claims, *rolesClaim, strings.Split(*roles, ","))
+
+	token, err := jwt.Sign("ES256", keyID, key, claims)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to sign token")
+	}
+	fmt.Println(token)
+}
+
+// loadOrCreateKey reads a PEM encoded EC key, or generates one and saves it
+func loadOrCreateKey(path string) (*ecdsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return key, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// writeKeySet writes the JSON Web Key Set of the public key
+func writeKeySet(path string, public *ecdsa.PublicKey) error {
+	b64 := base64.RawURLEncoding.EncodeToString
+	x, y := make([]byte, 32), make([]byte, 32)
+	public.X.FillBytes(x)
+	public.Y.FillBytes(y)
+
+	data, err := json.MarshalIndent(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "EC", "kid": keyID, "use": "sig", "alg": "ES256", "crv": "P-256", "x": b64(x), "y": b64(y),
+	}}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// setClaim sets a claim, creating the objects of a dotted name such as "realm_access.roles"
+func setClaim(claims map[string]interface{}, name string, value interface{}) {
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		nested, ok := claims[part].(map[string]interface{})
+		if !ok {
+			nested = make(map[string]interface{})
+			claims[part] = nested
+		}
+		claims = nested
+	}
+	claims[parts[len(parts)-1]] = value
+}
@@ -0,0 +1,83 @@
+// Command snmpsim serves a simulated ZTE C320 SNMP agent from snmpwalk record files, so the API can be run
+// against it without an OLT (SNMP_HOST=127.0.0.1, SNMP_PORT=1161). Values can be changed periodically
+// with -mutate, e.g. -mutate "30s inc .1.3.6.1.4.1.3902.1012.3.31.4.1 1500" to increase traffic counters.
+package main
+
+import (
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+// mutations collects repeated -mutate flags
+type mutations []string
+
+func (m *mutations) String() string { return strings.Join(*m, "; ") }
+
+func (m *mutations) Set(value string) error {
+	*m = append(*m, value)
+	return nil
+}
+
+func main() {
+	addr := flag.String("listen", "127.0.0.1:1161", "UDP listen address")
+	community := flag.String("community", "public", "SNMP v2c community")
+	firmware := flag.String("firmware", string(config.FirmwareV22), "Built-in fixture: v2.1 or v2.2")
+	recordFiles := flag.String("records", "", "Comma-separated snmpwalk -On files served instead of the fixture")
+	user := flag.String("v3-user", "", "SNMPv3 user (SNMPv3 disabled if empty)")
+	authProtocol := flag.String("auth-protocol", "", "SNMPv3 authentication protocol: MD5, SHA or SHA256")
+	authPassphrase := flag.String("auth-passphrase", "", "SNMPv3 authentication passphrase")
+	privProtocol := flag.String("priv-protocol", "", "SNMPv3 privacy protocol: DES or AES")
+	privPassphrase := flag.String("priv-passphrase", "", "SNMPv3 privacy passphrase")
+	var specs mutations
+	flag.Var(&specs, "mutate", "Periodic change \"<interval> inc|set|cycle <oid> <arguments>\" (repeatable)")
+	flag.Parse()
+
+	var records *simulator.Records
+	var err error
+	if *recordFiles != "" {
+		records, err = simulator.LoadRecords(strings.Split(*recordFiles, ",")...)
+	} else {
+		records, err = simulator.Fixture(config.FirmwareVersion(*firmware))
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load SNMP records")
+	}
+
+	agent := simulator.NewSNMPAgent(simulator.SNMPConfig{
+		Community:      *community,
+		User:           *user,
+		AuthProtocol:   *authProtocol,
+		AuthPassphrase: *authPassphrase,
+		PrivProtocol:   *privProtocol,
+		PrivPassphrase: *privPassphrase,
+	}, records)
+	for _, spec := range specs {
+		interval, mutation, err := simulator.ParseMutation(spec)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid mutation")
+		}
+		agent.Every(interval, mutation)
+	}
+	if err := agent.Listen(*addr); err != nil {
+		log.Fatal().Err(err).Str("address", *addr).Msg("Failed to start SNMP simulator")
+	}
+	log.Info().
+		Str("address", agent.Addr().String()).
+		Int("records", records.Len()).
+		Int("mutations", len(specs)).
+		Msg("C320 SNMP simulator listening")
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+
+	log.Info().Int("requests", agent.Requests()).Msg("SNMP simulator is stopping")
+	_ = agent.Close()
+}
@@ -1,9 +1,16 @@
 package config
 
 import (
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Config represents the main application configuration structure
@@ -13,15 +20,114 @@ type Config struct { // Define the main configuration struct named Config
 	SnmpCfg     SnmpConfig                      // Field to hold SNMP configuration settings
 	RedisCfg    RedisConfig                     // Field to hold Redis configuration settings
 	OltCfg      OltConfig                       // Field to hold OLT configuration settings
+	BackupStore BackupStoreConfig               // Where and how configuration backups are stored
+	Trap        TrapConfig                      // SNMP trap and inform receiver
+	Metrics     MetricsConfig                   // Prometheus metrics on /metrics
+	Cassette    CassetteConfig                  // Recording and replay of OLT exchanges
+	Auth        AuthConfig                      // API key and OIDC bearer token authentication
+	Audit       AuditConfig                     // Audit log of the changes made to the OLTs
+	Jobs        JobsConfig                      // Background jobs (batch operations, OLT backups, restores)
+	Stream      StreamConfig                    // Live monitoring stream (/monitoring/stream)
+	Firmware    FirmwareVersion                 // Firmware profile the OID mappings are generated for
+	OIDProfile  *OIDProfile                     `mapstructure:"-"` // OID profile of that firmware, including environment overrides
 	BoardPonMap map[BoardPonKey]*BoardPonConfig `mapstructure:"-"` // Dynamic map to store configurations for each Board and PON, ignored during direct un-marshaling
+
+	// oidMu guards the OID mappings (Firmware, OIDProfile, BoardPonMap and the OIDs of OltCfg), which
+	// SwitchOIDs replaces when the OLT gets another firmware. While the config is in use they are read
+	// with BaseOID, GetBoardPonConfig, BoardPonKeys and FirmwareProfile. nil for configs built as literals.
+	oidMu *sync.RWMutex
 }
 
 // SnmpConfig contains configuration parameters for SNMP connection
-// including target IP address, port, and community string.
+// including target IP address, port, and either a v2c community string or SNMPv3 (USM) credentials.
 type SnmpConfig struct { // Define the SnmpConfig struct for SNMP settings
 	IP        string `mapstructure:"ip"`        // IP address of the SNMP device, mapped from the "ip" configuration key
 	Port      uint16 `mapstructure:"port"`      // Port number for the SNMP connection, mapped from the "port" configuration key
 	Community string `mapstructure:"community"` // SNMP community string (password), mapped from the "community" configuration key
+
+	// SNMPv3 settings; the security level follows from the protocols set (authPriv, authNoPriv or noAuthNoPriv)
+	Version        string `mapstructure:"version"`         // "2c" (default) or "3"
+	User           string `mapstructure:"user"`            // SNMPv3 security name
+	AuthProtocol   string `mapstructure:"auth_protocol"`   // "MD5", "SHA" or "SHA256"; empty disables authentication
+	AuthPassphrase string `mapstructure:"auth_passphrase"` // Authentication passphrase (at least 8 characters)
+	PrivProtocol   string `mapstructure:"priv_protocol"`   // "DES" or "AES"; empty disables privacy (encryption)
+	PrivPassphrase string `mapstructure:"priv_passphrase"` // Privacy passphrase (at least 8 characters)
+
+	StartupProbe bool `mapstructure:"startup_probe"` // Query the agent at startup and fail on bad credentials
+}
+
+// SNMP versions accepted in SnmpConfig.Version
+const (
+	SnmpVersion2c = "2c"
+	SnmpVersion3  = "3"
+)
+
+// IsV3 reports whether the connection uses SNMPv3 instead of a v2c community
+func (c SnmpConfig) IsV3() bool {
+	return c.Version == SnmpVersion3 || c.Version == "v3"
+}
+
+// SecurityLevel returns the SNMPv3 security level: "authPriv", "authNoPriv" or "noAuthNoPriv"
+func (c SnmpConfig) SecurityLevel() string {
+	switch {
+	case c.PrivProtocol != "":
+		return "authPriv"
+	case c.AuthProtocol != "":
+		return "authNoPriv"
+	default:
+		return "noAuthNoPriv"
+	}
+}
+
+// Validate checks the version and credentials of the SNMP settings.
+// The error messages name the offending setting without the configuration prefix.
+func (c SnmpConfig) Validate() error {
+	switch c.Version {
+	case "", SnmpVersion2c, "v2c":
+		if c.Community == "" {
+			return fmt.Errorf("community is required")
+		}
+		return nil
+	case SnmpVersion3, "v3":
+	default:
+		return fmt.Errorf("version must be %q or %q, got %q", SnmpVersion2c, SnmpVersion3, c.Version)
+	}
+
+	if c.User == "" {
+		return fmt.Errorf("user is required for SNMPv3")
+	}
+
+	switch NormalizeSnmpProtocol(c.AuthProtocol) {
+	case "":
+		if c.PrivProtocol != "" {
+			return fmt.Errorf("priv_protocol requires an auth_protocol")
+		}
+		return nil
+	case "MD5", "SHA", "SHA256":
+	default:
+		return fmt.Errorf("auth_protocol must be MD5, SHA or SHA256, got %q", c.AuthProtocol)
+	}
+	if len(c.AuthPassphrase) < 8 {
+		return fmt.Errorf("auth_passphrase must be at least 8 characters")
+	}
+
+	switch NormalizeSnmpProtocol(c.PrivProtocol) {
+	case "":
+		return nil
+	case "DES", "AES":
+	default:
+		return fmt.Errorf("priv_protocol must be DES or AES, got %q", c.PrivProtocol)
+	}
+	if len(c.PrivPassphrase) < 8 {
+		return fmt.Errorf("priv_passphrase must be at least 8 characters")
+	}
+
+	return nil
+}
+
+// NormalizeSnmpProtocol canonicalizes an SNMPv3 protocol name ("sha-256" -> "SHA256")
+func NormalizeSnmpProtocol(protocol string) string {
+	return strings.ToUpper(strings.ReplaceAll(protocol, "-", ""))
 }
 
 // RedisConfig contains configuration parameters for Redis connection,
@@ -48,6 +154,198 @@ type OltConfig struct { // Define the OltConfig struct for OLT settings
 	BackupDir       string `mapstructure:"backup_dir"`  // Directory for configuration backups
 }
 
+// BackupStoreConfig selects the storage backend of configuration backups.
+// Backups are always gzip-compressed; they are AES-256-GCM encrypted when an encryption key is set.
+type BackupStoreConfig struct {
+	Type          string // "local" (files in OltCfg.BackupDir) or "s3" (S3-compatible object store)
+	EncryptionKey string // AES-256 key, 64 hex characters or base64 of 32 bytes; empty disables encryption
+	S3Endpoint    string // Object store URL, e.g., "https://s3.amazonaws.com" or "http://minio:9000"
+	S3Region      string // Signing region
+	S3Bucket      string // Bucket name
+	S3Prefix      string // Key prefix ("folder") inside the bucket
+	S3AccessKey   string // Access key ID
+	S3SecretKey   string // Secret access key
+}
+
+// TrapConfig configures the receiver of SNMP traps and informs sent by the OLTs
+type TrapConfig struct {
+	ListenAddr  string // UDP address to receive traps on, e.g. ":162"; empty disables the receiver
+	Community   string // Required v1/v2c community; empty drops v1/v2c notifications
+	EngineID    string // SNMPv3 engine ID of the receiver in hex, which the OLTs send informs to; empty generates one at start
+	EventBuffer int    // Number of events kept for GET /api/v1/events
+}
+
+// MetricsConfig configures the OLT-side metrics exported on /metrics
+type MetricsConfig struct {
+	CollectInterval time.Duration // How often ONU, PON and card state is collected from each OLT; 0 disables the collection
+}
+
+// AuditConfig configures the audit log of OLT-changing operations
+type AuditConfig struct {
+	LogFile   string // Append-only JSON Lines file of the hash-chained audit entries
+	MaxSizeMB int    // Size in MiB at which LogFile is rotated to LogFile.N; 0 never rotates it
+	HMACKey   string // Key of the HMAC-SHA256 chaining the entries, at least 32 bytes as hex or base64; empty chains them with plain SHA-256
+}
+
+// JobsConfig configures the background jobs kept in Redis
+type JobsConfig struct {
+	Retention time.Duration // How long finished jobs are kept; unfinished jobs are kept until they finish
+}
+
+// StreamConfig configures the live monitoring stream
+type StreamConfig struct {
+	PollInterval time.Duration // Pause between the state collections of an OLT while clients are subscribed; 0 leaves the stream to METRICS_COLLECT_INTERVAL and traps
+}
+
+// Cassette modes
+const (
+	CassetteRecord = "record" // Capture every SNMP and CLI exchange with the OLTs into cassette files
+	CassetteReplay = "replay" // Serve the exchanges of cassette files instead of connecting to the OLTs
+)
+
+// CassetteConfig configures recording and replay of OLT exchanges for reproducible bug reports
+type CassetteConfig struct {
+	Mode string // "record", "replay" or empty (disabled)
+	Dir  string // Directory of the cassette files, one <olt-id>.json per OLT
+}
+
+// Path returns the cassette file of an OLT
+func (c CassetteConfig) Path(oltID string) string {
+	return filepath.Join(c.Dir, oltID+".json")
+}
+
+// Validate checks the cassette mode and directory
+func (c CassetteConfig) Validate() error {
+	switch c.Mode {
+	case "":
+		return nil
+	case CassetteRecord, CassetteReplay:
+	default:
+		return fmt.Errorf("CASSETTE_MODE must be %q or %q, got %q", CassetteRecord, CassetteReplay, c.Mode)
+	}
+	if c.Dir == "" {
+		return fmt.Errorf("CASSETTE_DIR is required for CASSETTE_MODE=%s", c.Mode)
+	}
+	return nil
+}
+
+// MinAdminKeyLength is the minimum length of the bootstrap admin key
+const MinAdminKeyLength = 32
+
+// AuthConfig configures the authentication of /api/v1
+type AuthConfig struct {
+	Enabled  bool       // Require an API key or bearer token on /api/v1; disable only behind an authenticating proxy
+	AdminKey string     // Bootstrap key with all scopes, used to issue the first API keys; empty disables it
+	OIDC     OIDCConfig // Bearer tokens (JWT) of an OpenID Connect provider
+}
+
+// Validate checks the length of the bootstrap admin key and the OIDC settings
+func (c AuthConfig) Validate() error {
+	if c.AdminKey != "" && len(c.AdminKey) < MinAdminKeyLength {
+		return fmt.Errorf("AUTH_ADMIN_KEY must be at least %d characters", MinAdminKeyLength)
+	}
+	return c.OIDC.Validate()
+}
+
+// OIDCConfig configures the validation of JWT bearer tokens issued by an OpenID Connect provider
+// and the mapping of their claims to roles (viewer, technician, admin)
+type OIDCConfig struct {
+	JWKS        string        // File path or http(s) URL of the provider's JSON Web Key Set; empty disables OIDC
+	Issuer      string        // Required "iss" claim
+	Audience    string        // Required entry of the "aud" claim, i.e. the client ID of the API
+	UserClaim   string        // Claim naming the user in backups and logs, e.g. "preferred_username"
+	RolesClaim  string        // Claim with the user's roles or groups; dotted for nested claims, e.g. "realm_access.roles"
+	RoleMapping string        // Claim values mapped to roles, e.g. "noc-viewers=viewer,noc-admins=admin"; empty uses the role names
+	Leeway      time.Duration // Allowed clock skew for "exp" and "nbf"
+}
+
+// Enabled reports whether bearer tokens are accepted
+func (c OIDCConfig) Enabled() bool {
+	return c.JWKS != ""
+}
+
+// RoleMap parses RoleMapping into claim value => role. It returns nil if no mapping is configured.
+func (c OIDCConfig) RoleMap() (map[string]string, error) {
+	if strings.TrimSpace(c.RoleMapping) == "" {
+		return nil, nil
+	}
+
+	roles := make(map[string]string)
+	for _, entry := range strings.Split(c.RoleMapping, ",") {
+		value, role, ok := strings.Cut(entry, "=")
+		value, role = strings.TrimSpace(value), strings.TrimSpace(role)
+		if !ok || value == "" || role == "" {
+			return nil, fmt.Errorf("AUTH_OIDC_ROLE_MAPPING entries must be <claim value>=<role>, got %q", entry)
+		}
+		roles[value] = role
+	}
+	return roles, nil
+}
+
+// Validate checks that the issuer and audience are set when tokens are accepted
+func (c OIDCConfig) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.Issuer == "" {
+		return fmt.Errorf("AUTH_OIDC_ISSUER is required with AUTH_OIDC_JWKS")
+	}
+	if c.Audience == "" {
+		return fmt.Errorf("AUTH_OIDC_AUDIENCE is required with AUTH_OIDC_JWKS")
+	}
+	if c.Leeway < 0 {
+		return fmt.Errorf("AUTH_OIDC_LEEWAY must not be negative")
+	}
+	_, err := c.RoleMap()
+	return err
+}
+
+// EncryptionKeyBytes decodes the configured encryption key. It returns nil if encryption is disabled.
+func (c BackupStoreConfig) EncryptionKeyBytes() ([]byte, error) {
+	if c.EncryptionKey == "" {
+		return nil, nil
+	}
+
+	if key, err := hex.DecodeString(c.EncryptionKey); err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(c.EncryptionKey); err == nil && len(key) == 32 {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("BACKUP_ENCRYPTION_KEY must be 32 bytes, encoded as 64 hex characters or base64")
+}
+
+// HMACKeyBytes decodes the configured audit key. It returns nil if none is configured.
+func (c AuditConfig) HMACKeyBytes() ([]byte, error) {
+	if c.HMACKey == "" {
+		return nil, nil
+	}
+
+	if key, err := hex.DecodeString(c.HMACKey); err == nil && len(key) >= 32 {
+		return key, nil
+	}
+	if key, err := base64.StdEncoding.DecodeString(c.HMACKey); err == nil && len(key) >= 32 {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("AUDIT_HMAC_KEY must be at least 32 bytes, encoded as hex characters or base64")
+}
+
+// EngineIDBytes decodes the configured engine ID. It returns nil if none is configured.
+func (c TrapConfig) EngineIDBytes() ([]byte, error) {
+	if c.EngineID == "" {
+		return nil, nil
+	}
+
+	// RFC 3411 limits engine IDs to 5-32 octets
+	engineID, err := hex.DecodeString(strings.TrimPrefix(c.EngineID, "0x"))
+	if err != nil || len(engineID) < 5 || len(engineID) > 32 {
+		return nil, fmt.Errorf("TRAP_ENGINE_ID must be 5 to 32 bytes encoded as hex characters")
+	}
+	return engineID, nil
+}
+
 // BoardPonKey represents the unique key for board/pon lookup
 type BoardPonKey struct { // Define the BoardPonKey struct to use as a map key
 	BoardID int // Integer identifier for the Board
@@ -69,6 +367,11 @@ type BoardPonConfig struct { // Define the BoardPonConfig struct for specific Bo
 	OnuLastOfflineOID         string `mapstructure:"onu_last_offline_time"`     // OID for the last offline time, mapped from "onu_last_offline_time"
 	OnuLastOfflineReasonOID   string `mapstructure:"onu_last_offline_reason"`   // OID for the last offline reason, mapped from "onu_last_offline_reason"
 	OnuGponOpticalDistanceOID string `mapstructure:"onu_gpon_optical_distance"` // OID for the GPON optical distance, mapped from "onu_gpon_optical_distance"
+	OnuFirmwareOID            string `mapstructure:"onu_firmware"`              // OID for the ONU firmware version; empty if the firmware has no such column
+	OnuRxPacketsOID           string `mapstructure:"onu_rx_packets"`            // OID for the ONU RX packet counter; empty if not available
+	OnuRxBytesOID             string `mapstructure:"onu_rx_bytes"`              // OID for the ONU RX byte counter; empty if not available
+	PonRxPacketsOID           string `mapstructure:"pon_rx_packets"`            // OID for the PON RX packet counter (row {pon_index}.1); empty if not available
+	PonRxBytesOID             string `mapstructure:"pon_rx_bytes"`              // OID for the PON RX byte counter (row {pon_index}.1); empty if not available
 }
 
 //==============================================================================
@@ -105,6 +408,16 @@ func getEnvAsUint16(key string, defaultValue uint16) uint16 {
 	return defaultValue
 }
 
+// getEnvAsDuration retrieves an environment variable as duration (e.g. "5m") or returns a default value
+func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
 // LoadConfig loads configuration from environment variables
 // All sensitive data (SNMP, Redis, Server) MUST come from environment variables
 // Board/PON OID mappings are generated dynamically using mathematical formulas (no config file needed)
@@ -120,6 +433,15 @@ func LoadConfig() (*Config, error) {
 		IP:        getEnv("SNMP_HOST", ""),
 		Port:      getEnvAsUint16("SNMP_PORT", 161),
 		Community: getEnv("SNMP_COMMUNITY", ""),
+
+		Version:        getEnv("SNMP_VERSION", SnmpVersion2c),
+		User:           getEnv("SNMP_V3_USER", ""),
+		AuthProtocol:   getEnv("SNMP_V3_AUTH_PROTOCOL", ""),
+		AuthPassphrase: getEnv("SNMP_V3_AUTH_PASSPHRASE", ""),
+		PrivProtocol:   getEnv("SNMP_V3_PRIV_PROTOCOL", ""),
+		PrivPassphrase: getEnv("SNMP_V3_PRIV_PASSPHRASE", ""),
+
+		StartupProbe: getEnv("SNMP_STARTUP_PROBE", "true") != "false",
 	}
 
 	// Redis Configuration from environment (REQUIRED for production)
@@ -144,16 +466,89 @@ func LoadConfig() (*Config, error) {
 		BackupDir:       getEnv("BACKUP_DIR", "/var/lib/go-snmp-olt/backups"),
 	}
 
+	// Backup storage backend
+	cfg.BackupStore = BackupStoreConfig{
+		Type:          getEnv("BACKUP_STORE", "local"),
+		EncryptionKey: getEnv("BACKUP_ENCRYPTION_KEY", ""),
+		S3Endpoint:    getEnv("BACKUP_S3_ENDPOINT", ""),
+		S3Region:      getEnv("BACKUP_S3_REGION", "us-east-1"),
+		S3Bucket:      getEnv("BACKUP_S3_BUCKET", ""),
+		S3Prefix:      getEnv("BACKUP_S3_PREFIX", ""),
+		S3AccessKey:   getEnv("BACKUP_S3_ACCESS_KEY", ""),
+		S3SecretKey:   getEnv("BACKUP_S3_SECRET_KEY", ""),
+	}
+
+	// SNMP trap receiver
+	cfg.Trap = TrapConfig{
+		ListenAddr:  getEnv("TRAP_LISTEN_ADDR", ""),
+		Community:   getEnv("TRAP_COMMUNITY", ""),
+		EngineID:    getEnv("TRAP_ENGINE_ID", ""),
+		EventBuffer: getEnvAsInt("TRAP_EVENT_BUFFER", 10000),
+	}
+
+	// OLT metrics collection (walks every ONU, so the interval should be minutes, not seconds)
+	cfg.Metrics = MetricsConfig{
+		CollectInterval: getEnvAsDuration("METRICS_COLLECT_INTERVAL", 5*time.Minute),
+	}
+
+	// Recording and replay of OLT exchanges (off unless CASSETTE_MODE is set)
+	cfg.Cassette = CassetteConfig{
+		Mode: getEnv("CASSETTE_MODE", ""),
+		Dir:  getEnv("CASSETTE_DIR", ""),
+	}
+
+	// API authentication (on unless AUTH_ENABLED=false); OIDC bearer tokens if AUTH_OIDC_JWKS is set
+	cfg.Auth = AuthConfig{
+		Enabled:  getEnv("AUTH_ENABLED", "true") != "false",
+		AdminKey: getEnv("AUTH_ADMIN_KEY", ""),
+		OIDC: OIDCConfig{
+			JWKS:        getEnv("AUTH_OIDC_JWKS", ""),
+			Issuer:      getEnv("AUTH_OIDC_ISSUER", ""),
+			Audience:    getEnv("AUTH_OIDC_AUDIENCE", ""),
+			UserClaim:   getEnv("AUTH_OIDC_USER_CLAIM", "preferred_username"),
+			RolesClaim:  getEnv("AUTH_OIDC_ROLES_CLAIM", "roles"),
+			RoleMapping: getEnv("AUTH_OIDC_ROLE_MAPPING", ""),
+			Leeway:      getEnvAsDuration("AUTH_OIDC_LEEWAY", time.Minute),
+		},
+	}
+
+	// Audit log of every OLT-changing request (always on)
+	cfg.Audit = AuditConfig{
+		LogFile:   getEnv("AUDIT_LOG_FILE", "/var/lib/go-snmp-olt/audit.log"),
+		MaxSizeMB: getEnvAsInt("AUDIT_LOG_MAX_SIZE_MB", 100),
+		HMACKey:   getEnv("AUDIT_HMAC_KEY", ""),
+	}
+
+	// Background jobs, shared by all OLTs
+	cfg.Jobs = JobsConfig{
+		Retention: getEnvAsDuration("JOB_RETENTION", 7*24*time.Hour),
+	}
+
+	// Live monitoring stream, fed by one collection per OLT for all subscribed clients
+	cfg.Stream = StreamConfig{
+		PollInterval: getEnvAsDuration("STREAM_POLL_INTERVAL", 30*time.Second),
+	}
+
 	// ===================================================================
 	// Generate Board/PON OID mappings DYNAMICALLY (no config file needed)
 	// ===================================================================
 
+	// An unsupported firmware version would otherwise fall back to detection unnoticed
+	if firmware := os.Getenv("ZTE_FIRMWARE_VERSION"); firmware != "" {
+		if _, ok := ParseFirmwareVersion(firmware); !ok {
+			return nil, ErrInvalidConfig(fmt.Sprintf("unsupported ZTE_FIRMWARE_VERSION %q (v2.1, v2.2 or auto)", firmware))
+		}
+	}
+
 	// Generate all 32 Board-PON configurations using mathematical formulas
 	boardPonMap, err := InitializeBoardPonMap()
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize Board/PON OID mappings: %w", err)
 	}
 	cfg.BoardPonMap = boardPonMap
+	cfg.Firmware = GetCurrentFirmwareVersion()
+	cfg.OIDProfile = activeOIDProfile()
+	cfg.oidMu = new(sync.RWMutex)
 
 	// Validate config on startup (fail fast)
 	if err := cfg.ValidateConfig(); err != nil {
@@ -165,6 +560,7 @@ func LoadConfig() (*Config, error) {
 
 // GetBoardPonConfig retrieves configuration for a specific board and PON
 func (c *Config) GetBoardPonConfig(boardID, ponID int) (*BoardPonConfig, error) { // Define method GetBoardPonConfig on Config struct; takes boardID and ponID
+	defer c.readOIDs()()
 	key := BoardPonKey{BoardID: boardID, PonID: ponID} // Create a BoardPonKey using the provided boardID and ponID
 	cfg, ok := c.BoardPonMap[key]                      // Attempt to retrieve the configuration from the map
 	if !ok {                                           // Check if the retrieval was successful (ok is false if key not found)
@@ -173,6 +569,58 @@ func (c *Config) GetBoardPonConfig(boardID, ponID int) (*BoardPonConfig, error)
 	return cfg, nil // Return the found configuration and nil error
 }
 
+// BaseOID returns the base OID the ONU and PON tables of the OLT are read under
+func (c *Config) BaseOID() string {
+	defer c.readOIDs()()
+	return c.OltCfg.BaseOID1
+}
+
+// BoardPonKeys returns the boards and PONs with OID mappings, sorted by board and PON
+func (c *Config) BoardPonKeys() []BoardPonKey {
+	defer c.readOIDs()()
+	keys := make([]BoardPonKey, 0, len(c.BoardPonMap))
+	for key := range c.BoardPonMap {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].BoardID != keys[j].BoardID {
+			return keys[i].BoardID < keys[j].BoardID
+		}
+		return keys[i].PonID < keys[j].PonID
+	})
+	return keys
+}
+
+// FirmwareProfile returns the firmware the OID mappings are generated for and its OID profile
+func (c *Config) FirmwareProfile() (FirmwareVersion, *OIDProfile) {
+	defer c.readOIDs()()
+	return c.Firmware, c.OIDProfile
+}
+
+// SwitchOIDs replaces the OID mappings with those of next, generated for the new firmware of the OLT.
+// Reads through the accessors see either the old or the new mappings.
+func (c *Config) SwitchOIDs(next *Config) {
+	if c.oidMu != nil {
+		c.oidMu.Lock()
+		defer c.oidMu.Unlock()
+	}
+	c.Firmware = next.Firmware
+	c.OIDProfile = next.OIDProfile
+	c.BoardPonMap = next.BoardPonMap
+	c.OltCfg.BaseOID1 = next.OltCfg.BaseOID1
+	c.OltCfg.OnuIDNameAllPon = next.OltCfg.OnuIDNameAllPon
+	c.OltCfg.OnuTypeAllPon = next.OltCfg.OnuTypeAllPon
+}
+
+// readOIDs locks the OID mappings for reading and returns the unlock function
+func (c *Config) readOIDs() func() {
+	if c.oidMu == nil {
+		return func() {}
+	}
+	c.oidMu.RLock()
+	return c.oidMu.RUnlock
+}
+
 // ValidateConfig validates that all required board/pon configurations are present
 func (c *Config) ValidateConfig() error { // Define method ValidateConfig on Config struct; returns an error
 	// Validate that all 32 board/pon combinations exist
@@ -184,5 +632,8 @@ func (c *Config) ValidateConfig() error { // Define method ValidateConfig on Con
 			}
 		}
 	}
-	return nil // Return nil if all validations pass
+	if err := c.Cassette.Validate(); err != nil { // Reject unknown cassette modes
+		return err
+	}
+	return c.Auth.Validate() // Reject weak bootstrap keys and incomplete OIDC settings; return nil if all validations pass
 }
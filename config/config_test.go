@@ -2,7 +2,9 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetEnv(t *testing.T) {
@@ -293,6 +295,44 @@ func TestLoadConfig_DefaultValues(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_InvalidFirmwareVersion(t *testing.T) {
+	t.Setenv("ZTE_FIRMWARE_VERSION", "v3.0")
+
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "ZTE_FIRMWARE_VERSION") {
+		t.Errorf("LoadConfig() error = %v, want unsupported ZTE_FIRMWARE_VERSION", err)
+	}
+}
+
+func TestConfig_SwitchOIDs(t *testing.T) {
+	device := OLTDeviceConfig{ID: "olt-2", Firmware: FirmwareV21}
+	cfg, err := device.DeviceConfig(&Config{})
+	if err != nil {
+		t.Fatalf("DeviceConfig() error = %v", err)
+	}
+
+	device.Firmware = FirmwareV22
+	next, err := device.DeviceConfig(&Config{})
+	if err != nil {
+		t.Fatalf("DeviceConfig() error = %v", err)
+	}
+	cfg.SwitchOIDs(next)
+
+	firmware, profile := cfg.FirmwareProfile()
+	if firmware != FirmwareV22 || profile.Name != OIDProfiles[FirmwareV22].Name {
+		t.Errorf("FirmwareProfile() = %s, %s, want the v2.2 profile", firmware, profile.Name)
+	}
+	if cfg.BaseOID() != OIDProfiles[FirmwareV22].BaseOID {
+		t.Errorf("BaseOID() = %s, want %s", cfg.BaseOID(), OIDProfiles[FirmwareV22].BaseOID)
+	}
+	board, err := cfg.GetBoardPonConfig(1, 1)
+	if err != nil || board != next.BoardPonMap[BoardPonKey{BoardID: 1, PonID: 1}] {
+		t.Errorf("GetBoardPonConfig(1, 1) = %+v, %v, want the v2.2 mapping", board, err)
+	}
+	if keys := cfg.BoardPonKeys(); len(keys) != 32 || keys[0] != (BoardPonKey{BoardID: 1, PonID: 1}) {
+		t.Errorf("BoardPonKeys() = %v, want 32 keys starting at board 1 PON 1", keys)
+	}
+}
+
 func TestGetBoardPonConfig(t *testing.T) {
 	cfg := &Config{
 		BoardPonMap: make(map[BoardPonKey]*BoardPonConfig),
@@ -455,3 +495,219 @@ func TestConfig_StructFields(t *testing.T) {
 		t.Error("BoardPonMap should not be nil")
 	}
 }
+
+func TestBackupStoreConfig_EncryptionKeyBytes(t *testing.T) {
+	hexKey := "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+	base64Key := "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8="
+
+	tests := []struct {
+		name    string
+		key     string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "Disabled", key: "", wantLen: 0},
+		{name: "Hex", key: hexKey, wantLen: 32},
+		{name: "Base64", key: base64Key, wantLen: 32},
+		{name: "Too short", key: "0001020304", wantErr: true},
+		{name: "Passphrase", key: "my-secret-passphrase", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := BackupStoreConfig{EncryptionKey: tt.key}.EncryptionKeyBytes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EncryptionKeyBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(key) != tt.wantLen {
+				t.Errorf("key length = %d, want %d", len(key), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestAuditConfig_HMACKeyBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantLen int
+		wantErr bool
+	}{
+		{name: "Unkeyed", key: "", wantLen: 0},
+		{name: "Hex", key: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", wantLen: 32},
+		{name: "Base64", key: "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8gISIjJCUmJygpKissLS4vMDEyMzQ1Njc4OTo7PD0+Pw==", wantLen: 64},
+		{name: "Too short", key: "000102030405060708090a0b0c0d0e0f", wantErr: true},
+		{name: "Passphrase", key: "my-secret-passphrase", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := AuditConfig{HMACKey: tt.key}.HMACKeyBytes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HMACKeyBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(key) != tt.wantLen {
+				t.Errorf("key length = %d, want %d", len(key), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestTrapConfig_EngineIDBytes(t *testing.T) {
+	tests := []struct {
+		name     string
+		engineID string
+		wantLen  int
+		wantErr  bool
+	}{
+		{name: "Generated", engineID: "", wantLen: 0},
+		{name: "Hex", engineID: "80001f8804747261707321", wantLen: 11},
+		{name: "0x prefix", engineID: "0x80001f8804", wantLen: 5},
+		{name: "Too short", engineID: "80001f88", wantErr: true},
+		{name: "Text", engineID: "go-api-c320", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engineID, err := TrapConfig{EngineID: tt.engineID}.EngineIDBytes()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EngineIDBytes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(engineID) != tt.wantLen {
+				t.Errorf("engine ID length = %d, want %d", len(engineID), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestSnmpConfig_Validate(t *testing.T) {
+	v3 := SnmpConfig{Version: "3", User: "nms", AuthProtocol: "SHA256", AuthPassphrase: "auth-secret", PrivProtocol: "AES", PrivPassphrase: "priv-secret"}
+
+	tests := []struct {
+		name    string
+		modify  func(c *SnmpConfig)
+		level   string
+		wantErr string
+	}{
+		{name: "authPriv", level: "authPriv"},
+		{name: "authNoPriv", modify: func(c *SnmpConfig) { c.PrivProtocol, c.PrivPassphrase = "", "" }, level: "authNoPriv"},
+		{name: "noAuthNoPriv", modify: func(c *SnmpConfig) { *c = SnmpConfig{Version: "3", User: "nms"} }, level: "noAuthNoPriv"},
+		{name: "lower case protocols", modify: func(c *SnmpConfig) { c.AuthProtocol, c.PrivProtocol = "md5", "des" }, level: "authPriv"},
+		{name: "v2c", modify: func(c *SnmpConfig) { *c = SnmpConfig{Community: "public"} }, level: "noAuthNoPriv"},
+		{name: "v2c without community", modify: func(c *SnmpConfig) { *c = SnmpConfig{Version: "2c"} }, wantErr: "community is required"},
+		{name: "unknown version", modify: func(c *SnmpConfig) { c.Version = "1" }, wantErr: "version must be"},
+		{name: "missing user", modify: func(c *SnmpConfig) { c.User = "" }, wantErr: "user is required"},
+		{name: "unknown auth protocol", modify: func(c *SnmpConfig) { c.AuthProtocol = "SHA512" }, wantErr: "auth_protocol must be MD5, SHA or SHA256"},
+		{name: "unknown priv protocol", modify: func(c *SnmpConfig) { c.PrivProtocol = "3DES" }, wantErr: "priv_protocol must be DES or AES"},
+		{name: "priv without auth", modify: func(c *SnmpConfig) { c.AuthProtocol = "" }, wantErr: "priv_protocol requires an auth_protocol"},
+		{name: "short priv passphrase", modify: func(c *SnmpConfig) { c.PrivPassphrase = "1234567" }, wantErr: "priv_passphrase must be at least 8 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := v3
+			if tt.modify != nil {
+				tt.modify(&cfg)
+			}
+
+			err := cfg.Validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if cfg.IsV3() && cfg.SecurityLevel() != tt.level {
+				t.Errorf("SecurityLevel() = %q, want %q", cfg.SecurityLevel(), tt.level)
+			}
+		})
+	}
+}
+
+func TestCassetteConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     CassetteConfig
+		wantErr string
+	}{
+		{name: "disabled", cfg: CassetteConfig{}},
+		{name: "record", cfg: CassetteConfig{Mode: CassetteRecord, Dir: "/tmp/cassettes"}},
+		{name: "replay", cfg: CassetteConfig{Mode: CassetteReplay, Dir: "/tmp/cassettes"}},
+		{name: "unknown mode", cfg: CassetteConfig{Mode: "rewind", Dir: "/tmp/cassettes"}, wantErr: "CASSETTE_MODE must be"},
+		{name: "missing directory", cfg: CassetteConfig{Mode: CassetteRecord}, wantErr: "CASSETTE_DIR is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+
+	if got := (CassetteConfig{Dir: "/tmp/cassettes"}).Path("olt-2"); got != "/tmp/cassettes/olt-2.json" {
+		t.Errorf("Path() = %q", got)
+	}
+}
+
+func TestAuthConfig_Validate(t *testing.T) {
+	if err := (AuthConfig{Enabled: true}).Validate(); err != nil {
+		t.Errorf("Validate() without admin key error = %v", err)
+	}
+	if err := (AuthConfig{Enabled: true, AdminKey: strings.Repeat("k", MinAdminKeyLength)}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := (AuthConfig{Enabled: true, AdminKey: "short"}).Validate(); err == nil || !strings.Contains(err.Error(), "AUTH_ADMIN_KEY") {
+		t.Errorf("Validate() short admin key error = %v, want AUTH_ADMIN_KEY error", err)
+	}
+}
+
+func TestOIDCConfig_Validate(t *testing.T) {
+	valid := OIDCConfig{JWKS: "/etc/go-api-c320/jwks.json", Issuer: "https://sso.example.net/realms/noc", Audience: "go-api-c320"}
+
+	tests := []struct {
+		name    string
+		modify  func(c *OIDCConfig)
+		wantErr string
+	}{
+		{name: "valid", modify: func(c *OIDCConfig) {}},
+		{name: "disabled", modify: func(c *OIDCConfig) { *c = OIDCConfig{Issuer: "ignored"} }},
+		{name: "role mapping", modify: func(c *OIDCConfig) { c.RoleMapping = "noc-viewers=viewer, noc-admins = admin" }},
+		{name: "no issuer", modify: func(c *OIDCConfig) { c.Issuer = "" }, wantErr: "AUTH_OIDC_ISSUER"},
+		{name: "no audience", modify: func(c *OIDCConfig) { c.Audience = "" }, wantErr: "AUTH_OIDC_AUDIENCE"},
+		{name: "negative leeway", modify: func(c *OIDCConfig) { c.Leeway = -time.Second }, wantErr: "AUTH_OIDC_LEEWAY"},
+		{name: "bad role mapping", modify: func(c *OIDCConfig) { c.RoleMapping = "noc-admins" }, wantErr: "AUTH_OIDC_ROLE_MAPPING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := AuthConfig{Enabled: true, OIDC: c}.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	roles, err := OIDCConfig{RoleMapping: "noc-viewers=viewer, noc-admins = admin"}.RoleMap()
+	if err != nil || len(roles) != 2 || roles["noc-admins"] != "admin" {
+		t.Errorf("RoleMap() = %v, %v", roles, err)
+	}
+}
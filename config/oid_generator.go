@@ -3,7 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 )
 
 // FirmwareVersion represents the ZTE C320 firmware version
@@ -12,6 +14,9 @@ type FirmwareVersion string
 const (
 	FirmwareV21 FirmwareVersion = "v2.1" // Firmware V2.1.0
 	FirmwareV22 FirmwareVersion = "v2.2" // Firmware V2.2.x and newer
+
+	// FirmwareAuto selects the profile from the software version reported by the OLT
+	FirmwareAuto FirmwareVersion = "auto"
 )
 
 // OIDProfile contains all OID configurations for a specific firmware version
@@ -36,6 +41,28 @@ type OIDProfile struct {
 	Board2OnuTypeBase            int
 	OnuIDIncrement               int
 	OnuTypeIncrement             int
+
+	// Monitoring columns, indexed like the ONU ID tables ({pon_index}.{onu_id}); the PON
+	// counters are rows {pon_index}.1. Empty where the firmware has no such column.
+	OnuFirmwarePrefix  string // ONU software version
+	OnuRxPacketsPrefix string // Packets received from the ONU
+	OnuRxBytesPrefix   string // Bytes received from the ONU
+	PonRxPacketsPrefix string // Packets received on the PON port
+	PonRxBytesPrefix   string // Bytes received on the PON port
+	OnuStatusOnline    int    // Value of the OnuStatusIDPrefix column for an online ONU
+
+	// Notification OIDs (value of snmpTrapOID.0) decoded by the trap receiver.
+	// ZTE does not publish the notification MIB of the C320, so they are empty in the
+	// firmware profiles and set per OLT (traps of the registry file, TRAP_*_OID for the default OLT).
+	// ONU and PON varbinds are indexed like the ONU tables ({pon_index}.{onu_id}),
+	// card varbinds like the card table ({rack}.{shelf}.{slot}).
+	TrapOnuStatusChangeOID string // ONU state change, the new state is carried in the OnuStatusIDPrefix column
+	TrapOnuDyingGaspOID    string // ONU power failure
+	TrapOnuLOSOID          string // ONU loss of signal
+	TrapPonPortDownOID     string // PON port link down
+	TrapPonPortUpOID       string // PON port link up
+	TrapCardOfflineOID     string // Card removed or offline
+	TrapCardOnlineOID      string // Card inserted or online
 }
 
 // OID Profiles for different firmware versions
@@ -71,6 +98,13 @@ var OIDProfiles = map[FirmwareVersion]*OIDProfile{
 		Board2OnuTypeBase: 268509184, // Same as OnuID for V2.1
 		OnuIDIncrement:    256,       // V2.1 increments by 256 per PON
 		OnuTypeIncrement:  256,       // Same increment
+		// Monitoring columns of the ONU table and the ONU/PON statistics tables
+		OnuFirmwarePrefix:  ".3.13.3.1.11", // ONU Firmware Version (STRING)
+		OnuRxPacketsPrefix: ".3.31.4.1.3",  // ONU RX packets (Counter64)
+		OnuRxBytesPrefix:   ".3.31.4.1.6",  // ONU RX bytes (Counter64)
+		PonRxPacketsPrefix: ".3.31.5.1.3",  // PON RX packets (Counter64)
+		PonRxBytesPrefix:   ".3.31.5.1.6",  // PON RX bytes (Counter64)
+		OnuStatusOnline:    1,              // 1=online, 2=offline
 	},
 	FirmwareV22: {
 		Name:    "ZTE C320 V2.2+",
@@ -94,23 +128,70 @@ var OIDProfiles = map[FirmwareVersion]*OIDProfile{
 		Board2OnuTypeBase:            268566528,
 		OnuIDIncrement:               1,
 		OnuTypeIncrement:             256,
+		OnuStatusOnline:              4, // 4=online, 7=offline, 2=LOS; no firmware or traffic counter columns
 	},
 }
 
-// GetCurrentFirmwareVersion returns the firmware version from environment variable
-// Default is V2.1 if not specified
+// GetCurrentFirmwareVersion returns the firmware version the process-wide OID variables are built for.
+// It is the version pinned with ZTE_FIRMWARE_VERSION, or V2.1 if the firmware is detected (auto) or not set.
 func GetCurrentFirmwareVersion() FirmwareVersion {
-	version := os.Getenv("ZTE_FIRMWARE_VERSION")
-	switch version {
-	case "v2.2", "V2.2", "2.2":
-		return FirmwareV22
-	case "v2.1", "V2.1", "2.1", "":
-		return FirmwareV21
+	if version := ConfiguredFirmwareVersion(); version != FirmwareAuto {
+		return version
+	}
+	return FirmwareV21
+}
+
+// ConfiguredFirmwareVersion returns the firmware version pinned with ZTE_FIRMWARE_VERSION.
+// FirmwareAuto is returned if the variable is unset or "auto"; LoadConfig rejects unsupported versions.
+func ConfiguredFirmwareVersion() FirmwareVersion {
+	version, ok := ParseFirmwareVersion(os.Getenv("ZTE_FIRMWARE_VERSION"))
+	if !ok {
+		return FirmwareAuto
+	}
+	return version
+}
+
+// ParseFirmwareVersion normalizes a configured firmware version ("v2.2", "V2.2", "2.2", "auto").
+// An empty string selects FirmwareAuto.
+func ParseFirmwareVersion(value string) (FirmwareVersion, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "v2.1", "2.1":
+		return FirmwareV21, true
+	case "v2.2", "2.2":
+		return FirmwareV22, true
+	case "", "auto":
+		return FirmwareAuto, true
 	default:
-		return FirmwareV21
+		return "", false
 	}
 }
 
+// FirmwareForSoftwareVersion selects the OID profile for a software version reported by the OLT,
+// e.g. "V2.1.0" or "V2.2.1P2". known is false for builds no profile was verified against:
+// newer V2.x builds are read with the V2.2 profile, anything else with the V2.1 profile.
+func FirmwareForSoftwareVersion(software string) (version FirmwareVersion, known bool) {
+	m := softwareVersionRegex.FindStringSubmatch(software)
+	if m == nil {
+		return FirmwareV21, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+
+	switch {
+	case major == 2 && minor == 1:
+		return FirmwareV21, true
+	case major == 2 && minor == 2:
+		return FirmwareV22, true
+	case major == 2 && minor > 2:
+		return FirmwareV22, false
+	default:
+		return FirmwareV21, false
+	}
+}
+
+// softwareVersionRegex matches the major and minor number of a ZTE software version (V2.1.0, v2.2.1P2)
+var softwareVersionRegex = regexp.MustCompile(`(?i)^V(\d+)\.(\d+)`)
+
 // GetOIDProfile returns the OID profile for the current firmware version
 func GetOIDProfile() *OIDProfile {
 	return OIDProfiles[GetCurrentFirmwareVersion()]
@@ -211,7 +292,17 @@ func reinitializeOIDVariables() {
 //	Board 1, PON 2: 285278466, 268501504 (+1, +256)
 //	Board 2, PON 1: 285278721, 268566784
 func GenerateBoardPonOID(boardID, ponID int) (*BoardPonConfig, error) {
+	return GenerateBoardPonOIDForProfile(activeOIDProfile(), boardID, ponID)
+}
+
+// GenerateBoardPonOIDForProfile generates OID configurations for a Board-PON combination
+// using an explicit firmware profile instead of the process-wide OID variables.
+// This is used when several OLTs with different firmware are managed by one instance.
+func GenerateBoardPonOIDForProfile(profile *OIDProfile, boardID, ponID int) (*BoardPonConfig, error) {
 	// Validate inputs
+	if profile == nil {
+		return nil, fmt.Errorf("OID profile is required")
+	}
 	if boardID < 1 || boardID > 2 {
 		return nil, fmt.Errorf("invalid boardID: %d (must be 1 or 2)", boardID)
 	}
@@ -219,48 +310,128 @@ func GenerateBoardPonOID(boardID, ponID int) (*BoardPonConfig, error) {
 		return nil, fmt.Errorf("invalid ponID: %d (must be 1-16)", ponID)
 	}
 
-	// Determine base values based on board
-	var baseOnuID, baseOnuType int
+	// Calculate suffixes using formula
+	onuIDSuffix, onuTypeSuffix, ok := profile.PonIndex(boardID, ponID)
+	if !ok {
+		return nil, fmt.Errorf("unsupported boardID: %d", boardID)
+	}
+
+	// Generate full OIDs by concatenating prefix + suffix
+	return &BoardPonConfig{
+		OnuIDNameOID:              fmt.Sprintf("%s.%d", profile.OnuIDNamePrefix, onuIDSuffix),
+		OnuTypeOID:                fmt.Sprintf("%s.%d", profile.OnuTypePrefix, onuTypeSuffix),
+		OnuSerialNumberOID:        fmt.Sprintf("%s.%d", profile.OnuSerialNumberPrefix, onuIDSuffix),
+		OnuRxPowerOID:             fmt.Sprintf("%s.%d", profile.OnuRxPowerPrefix, onuIDSuffix),
+		OnuTxPowerOID:             fmt.Sprintf("%s.%d", profile.OnuTxPowerPrefix, onuTypeSuffix),
+		OnuStatusOID:              fmt.Sprintf("%s.%d", profile.OnuStatusIDPrefix, onuIDSuffix),
+		OnuIPAddressOID:           fmt.Sprintf("%s.%d", profile.OnuIPAddressPrefix, onuTypeSuffix),
+		OnuDescriptionOID:         fmt.Sprintf("%s.%d", profile.OnuDescriptionPrefix, onuIDSuffix),
+		OnuLastOnlineOID:          fmt.Sprintf("%s.%d", profile.OnuLastOnlineTimePrefix, onuIDSuffix),
+		OnuLastOfflineOID:         fmt.Sprintf("%s.%d", profile.OnuLastOfflineTimePrefix, onuIDSuffix),
+		OnuLastOfflineReasonOID:   fmt.Sprintf("%s.%d", profile.OnuLastOfflineReasonPrefix, onuIDSuffix),
+		OnuGponOpticalDistanceOID: fmt.Sprintf("%s.%d", profile.OnuGponOpticalDistancePrefix, onuIDSuffix),
+		OnuFirmwareOID:            optionalOID(profile.OnuFirmwarePrefix, onuIDSuffix),
+		OnuRxPacketsOID:           optionalOID(profile.OnuRxPacketsPrefix, onuIDSuffix),
+		OnuRxBytesOID:             optionalOID(profile.OnuRxBytesPrefix, onuIDSuffix),
+		PonRxPacketsOID:           optionalOID(profile.PonRxPacketsPrefix, onuIDSuffix),
+		PonRxBytesOID:             optionalOID(profile.PonRxBytesPrefix, onuIDSuffix),
+	}, nil
+}
+
+// optionalOID appends the suffix to a prefix the firmware may not have; an empty prefix stays empty
+func optionalOID(prefix string, suffix int) string {
+	if prefix == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.%d", prefix, suffix)
+}
+
+// PonIndex returns the PON index of a board/PON in the ONU ID tables (Board{N}OnuIDBase)
+// and in the ONU type / PON port tables (Board{N}OnuTypeBase). It is the inverse of PonFromIndex.
+func (p *OIDProfile) PonIndex(boardID, ponID int) (onuIDIndex, onuTypeIndex int, ok bool) {
 	switch boardID {
 	case 1:
-		baseOnuID = Board1OnuIDBase
-		baseOnuType = Board1OnuTypeBase
+		return p.Board1OnuIDBase + ponID*p.OnuIDIncrement, p.Board1OnuTypeBase + ponID*p.OnuTypeIncrement, true
 	case 2:
-		baseOnuID = Board2OnuIDBase
-		baseOnuType = Board2OnuTypeBase
+		return p.Board2OnuIDBase + ponID*p.OnuIDIncrement, p.Board2OnuTypeBase + ponID*p.OnuTypeIncrement, true
 	default:
-		return nil, fmt.Errorf("unsupported boardID: %d", boardID)
+		return 0, 0, false
 	}
+}
 
-	// Calculate suffixes using formula
-	onuIDSuffix := baseOnuID + (ponID * OnuIDIncrement)
-	onuTypeSuffix := baseOnuType + (ponID * OnuTypeIncrement)
+// activeOIDProfile returns the profile of the process-wide firmware with the OID environment overrides
+func activeOIDProfile() *OIDProfile {
+	return withOIDOverrides(GetOIDProfile())
+}
 
-	// Generate full OIDs by concatenating prefix + suffix
-	return &BoardPonConfig{
-		OnuIDNameOID:              fmt.Sprintf("%s.%d", OnuIDNamePrefix, onuIDSuffix),
-		OnuTypeOID:                fmt.Sprintf("%s.%d", OnuTypePrefix, onuTypeSuffix),
-		OnuSerialNumberOID:        fmt.Sprintf("%s.%d", OnuSerialNumberPrefix, onuIDSuffix),
-		OnuRxPowerOID:             fmt.Sprintf("%s.%d", OnuRxPowerPrefix, onuIDSuffix),
-		OnuTxPowerOID:             fmt.Sprintf("%s.%d", OnuTxPowerPrefix, onuTypeSuffix),
-		OnuStatusOID:              fmt.Sprintf("%s.%d", OnuStatusIDPrefix, onuIDSuffix),
-		OnuIPAddressOID:           fmt.Sprintf("%s.%d", OnuIPAddressPrefix, onuTypeSuffix),
-		OnuDescriptionOID:         fmt.Sprintf("%s.%d", OnuDescriptionPrefix, onuIDSuffix),
-		OnuLastOnlineOID:          fmt.Sprintf("%s.%d", OnuLastOnlineTimePrefix, onuIDSuffix),
-		OnuLastOfflineOID:         fmt.Sprintf("%s.%d", OnuLastOfflineTimePrefix, onuIDSuffix),
-		OnuLastOfflineReasonOID:   fmt.Sprintf("%s.%d", OnuLastOfflineReasonPrefix, onuIDSuffix),
-		OnuGponOpticalDistanceOID: fmt.Sprintf("%s.%d", OnuGponOpticalDistancePrefix, onuIDSuffix),
-	}, nil
+// withOIDOverrides applies the OID environment variables (OLT_BASE_OID, ONU_*_PREFIX, BOARD*_BASE
+// and *_INCREMENT) to a firmware profile
+func withOIDOverrides(profile *OIDProfile) *OIDProfile {
+	overridden := *profile
+	overridden.BaseOID = getOIDEnv("OLT_BASE_OID", profile.BaseOID)
+	overridden.OnuIDNamePrefix = getOIDEnv("ONU_ID_NAME_PREFIX", profile.OnuIDNamePrefix)
+	overridden.OnuTypePrefix = getOIDEnv("ONU_TYPE_PREFIX", profile.OnuTypePrefix)
+	overridden.OnuSerialNumberPrefix = getOIDEnv("ONU_SERIAL_NUMBER_PREFIX", profile.OnuSerialNumberPrefix)
+	overridden.OnuRxPowerPrefix = getOIDEnv("ONU_RX_POWER_PREFIX", profile.OnuRxPowerPrefix)
+	overridden.OnuTxPowerPrefix = getOIDEnv("ONU_TX_POWER_PREFIX", profile.OnuTxPowerPrefix)
+	overridden.OnuStatusIDPrefix = getOIDEnv("ONU_STATUS_ID_PREFIX", profile.OnuStatusIDPrefix)
+	overridden.OnuIPAddressPrefix = getOIDEnv("ONU_IP_ADDRESS_PREFIX", profile.OnuIPAddressPrefix)
+	overridden.OnuDescriptionPrefix = getOIDEnv("ONU_DESCRIPTION_PREFIX", profile.OnuDescriptionPrefix)
+	overridden.OnuLastOnlineTimePrefix = getOIDEnv("ONU_LAST_ONLINE_PREFIX", profile.OnuLastOnlineTimePrefix)
+	overridden.OnuLastOfflineTimePrefix = getOIDEnv("ONU_LAST_OFFLINE_PREFIX", profile.OnuLastOfflineTimePrefix)
+	overridden.OnuLastOfflineReasonPrefix = getOIDEnv("ONU_LAST_OFFLINE_REASON_PREFIX", profile.OnuLastOfflineReasonPrefix)
+	overridden.OnuGponOpticalDistancePrefix = getOIDEnv("ONU_GPON_OPTICAL_DISTANCE_PREFIX", profile.OnuGponOpticalDistancePrefix)
+	overridden.Board1OnuIDBase = getOIDEnvAsInt("BOARD1_ONU_ID_BASE", profile.Board1OnuIDBase)
+	overridden.Board1OnuTypeBase = getOIDEnvAsInt("BOARD1_ONU_TYPE_BASE", profile.Board1OnuTypeBase)
+	overridden.Board2OnuIDBase = getOIDEnvAsInt("BOARD2_ONU_ID_BASE", profile.Board2OnuIDBase)
+	overridden.Board2OnuTypeBase = getOIDEnvAsInt("BOARD2_ONU_TYPE_BASE", profile.Board2OnuTypeBase)
+	overridden.OnuIDIncrement = getOIDEnvAsInt("ONU_ID_INCREMENT", profile.OnuIDIncrement)
+	overridden.OnuTypeIncrement = getOIDEnvAsInt("ONU_TYPE_INCREMENT", profile.OnuTypeIncrement)
+	return &overridden
+}
+
+// PonFromIndex decodes the board and PON of a PON index as used in ONU table OIDs.
+// Both the ONU ID index (Board{N}OnuIDBase) and the ONU type / PON port index
+// (Board{N}OnuTypeBase) are accepted, as notifications use either depending on the table.
+func (p *OIDProfile) PonFromIndex(index int) (boardID, ponID int, ok bool) {
+	bases := []struct {
+		board, base, increment int
+	}{
+		{1, p.Board1OnuIDBase, p.OnuIDIncrement},
+		{2, p.Board2OnuIDBase, p.OnuIDIncrement},
+		{1, p.Board1OnuTypeBase, p.OnuTypeIncrement},
+		{2, p.Board2OnuTypeBase, p.OnuTypeIncrement},
+	}
+
+	for _, b := range bases {
+		if b.increment <= 0 {
+			continue
+		}
+		offset := index - b.base
+		if offset <= 0 || offset%b.increment != 0 {
+			continue
+		}
+		if pon := offset / b.increment; pon >= 1 && pon <= 16 {
+			return b.board, pon, true
+		}
+	}
+
+	return 0, 0, false
 }
 
 // InitializeBoardPonMap generates all 32 Board-PON configurations dynamically.
 // This replaces the need for a 20KB config file with 384 lines of OID mappings.
 func InitializeBoardPonMap() (map[BoardPonKey]*BoardPonConfig, error) {
+	return InitializeBoardPonMapForProfile(activeOIDProfile())
+}
+
+// InitializeBoardPonMapForProfile generates all 32 Board-PON configurations for a specific firmware profile.
+func InitializeBoardPonMapForProfile(profile *OIDProfile) (map[BoardPonKey]*BoardPonConfig, error) {
 	boardPonMap := make(map[BoardPonKey]*BoardPonConfig, 32) // Pre-allocate for 32 entries (2 boards * 16 PONs)
 
 	for boardID := 1; boardID <= 2; boardID++ {
 		for ponID := 1; ponID <= 16; ponID++ {
-			cfg, err := GenerateBoardPonOID(boardID, ponID)
+			cfg, err := GenerateBoardPonOIDForProfile(profile, boardID, ponID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to generate OID for Board%dPon%d: %w", boardID, ponID, err)
 			}
@@ -236,3 +236,127 @@ func BenchmarkInitializeBoardPonMap(b *testing.B) {
 		_, _ = InitializeBoardPonMap()
 	}
 }
+
+// TestPonFromIndex verifies that PON indexes of both firmware versions are decoded to board and PON
+func TestPonFromIndex(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   FirmwareVersion
+		index     int
+		wantBoard int
+		wantPon   int
+		wantOK    bool
+	}{
+		{"V2.1 board 1 PON 1", FirmwareV21, 268501248, 1, 1, true},
+		{"V2.1 board 2 PON 16", FirmwareV21, 268509184 + 16*256, 2, 16, true},
+		{"V2.2 ONU ID index board 1 PON 3", FirmwareV22, 285278467, 1, 3, true},
+		{"V2.2 ONU ID index board 2 PON 1", FirmwareV22, 285278721, 2, 1, true},
+		{"V2.2 ONU type index board 1 PON 2", FirmwareV22, 268501504, 1, 2, true},
+		{"Base itself", FirmwareV22, 285278464, 0, 0, false},
+		{"Unrelated index", FirmwareV21, 12, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board, pon, ok := GetOIDProfileForVersion(tt.version).PonFromIndex(tt.index)
+			if board != tt.wantBoard || pon != tt.wantPon || ok != tt.wantOK {
+				t.Errorf("PonFromIndex(%d) = (%d, %d, %v), want (%d, %d, %v)",
+					tt.index, board, pon, ok, tt.wantBoard, tt.wantPon, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestPonIndex verifies that PonIndex is the inverse of PonFromIndex on both boards
+func TestPonIndex(t *testing.T) {
+	for _, version := range []FirmwareVersion{FirmwareV21, FirmwareV22} {
+		profile := GetOIDProfileForVersion(version)
+		for boardID := 1; boardID <= 2; boardID++ {
+			for ponID := 1; ponID <= 16; ponID++ {
+				onuIDIndex, onuTypeIndex, ok := profile.PonIndex(boardID, ponID)
+				if !ok {
+					t.Fatalf("%s PonIndex(%d, %d) not ok", version, boardID, ponID)
+				}
+				for _, index := range []int{onuIDIndex, onuTypeIndex} {
+					if board, pon, _ := profile.PonFromIndex(index); board != boardID || pon != ponID {
+						t.Errorf("%s PonFromIndex(%d) = (%d, %d), want (%d, %d)", version, index, board, pon, boardID, ponID)
+					}
+				}
+			}
+		}
+	}
+
+	if _, _, ok := GetOIDProfileForVersion(FirmwareV21).PonIndex(3, 1); ok {
+		t.Error("PonIndex(3, 1) ok, want unsupported board")
+	}
+}
+
+// TestGenerateBoardPonOIDForProfile_Monitoring verifies that monitoring columns the firmware lacks stay empty
+func TestGenerateBoardPonOIDForProfile_Monitoring(t *testing.T) {
+	v21, err := GenerateBoardPonOIDForProfile(GetOIDProfileForVersion(FirmwareV21), 2, 1)
+	if err != nil {
+		t.Fatalf("GenerateBoardPonOIDForProfile(v2.1) error = %v", err)
+	}
+	if v21.OnuFirmwareOID != ".3.13.3.1.11.268509440" || v21.PonRxBytesOID != ".3.31.5.1.6.268509440" {
+		t.Errorf("v2.1 board 2 PON 1 = firmware %q, PON RX bytes %q", v21.OnuFirmwareOID, v21.PonRxBytesOID)
+	}
+
+	v22, err := GenerateBoardPonOIDForProfile(GetOIDProfileForVersion(FirmwareV22), 2, 1)
+	if err != nil {
+		t.Fatalf("GenerateBoardPonOIDForProfile(v2.2) error = %v", err)
+	}
+	if v22.OnuFirmwareOID != "" || v22.OnuRxPacketsOID != "" || v22.OnuRxBytesOID != "" || v22.PonRxPacketsOID != "" || v22.PonRxBytesOID != "" {
+		t.Errorf("v2.2 monitoring OIDs = %+v, want empty", v22)
+	}
+}
+
+func TestFirmwareForSoftwareVersion(t *testing.T) {
+	tests := []struct {
+		software  string
+		want      FirmwareVersion
+		wantKnown bool
+	}{
+		{"V2.1.0", FirmwareV21, true},
+		{"V2.1.2P1", FirmwareV21, true},
+		{"V2.2.0", FirmwareV22, true},
+		{"v2.2.1P2", FirmwareV22, true},
+		{"V2.3.0", FirmwareV22, false},
+		{"V2.0.1", FirmwareV21, false},
+		{"V1.2.5", FirmwareV21, false},
+		{"unknown", FirmwareV21, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.software, func(t *testing.T) {
+			got, known := FirmwareForSoftwareVersion(tt.software)
+			if got != tt.want || known != tt.wantKnown {
+				t.Errorf("FirmwareForSoftwareVersion(%q) = %s, %v, want %s, %v", tt.software, got, known, tt.want, tt.wantKnown)
+			}
+		})
+	}
+}
+
+func TestConfiguredFirmwareVersion(t *testing.T) {
+	tests := []struct {
+		env  string
+		want FirmwareVersion
+	}{
+		{"", FirmwareAuto},
+		{"auto", FirmwareAuto},
+		{"V2.2", FirmwareV22},
+		{"2.1", FirmwareV21},
+		{"v9", FirmwareAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.env, func(t *testing.T) {
+			t.Setenv("ZTE_FIRMWARE_VERSION", tt.env)
+			if got := ConfiguredFirmwareVersion(); got != tt.want {
+				t.Errorf("ConfiguredFirmwareVersion() = %s, want %s", got, tt.want)
+			}
+			if got := GetCurrentFirmwareVersion(); tt.want == FirmwareAuto && got != FirmwareV21 {
+				t.Errorf("GetCurrentFirmwareVersion() = %s, want v2.1 when detecting", got)
+			}
+		})
+	}
+}
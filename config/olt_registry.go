@@ -0,0 +1,360 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// DefaultOLTID is the identifier of the OLT built from the legacy single-device
+// environment variables (SNMP_HOST, TELNET_HOST, ...).
+const DefaultOLTID = "default"
+
+// oltIDPattern restricts OLT identifiers to URL-safe characters
+var oltIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// OLTTelnetConfig contains per-device CLI credentials, used over Telnet or SSH.
+// Empty fields fall back to the global TELNET_*, CLI_TRANSPORT and SSH_* environment variables.
+type OLTTelnetConfig struct {
+	Transport      string   `json:"transport,omitempty"`     // "telnet" or "ssh"
+	Host           string   `json:"host"`                    // CLI host (defaults to the SNMP host)
+	Port           int      `json:"port"`                    // CLI port (defaults to TELNET_PORT or SSH_PORT of the transport)
+	Username       string   `json:"username"`                // CLI username
+	Password       string   `json:"password"`                // CLI password
+	EnablePassword string   `json:"enable_password"`         // Enable mode password
+	SSHKeyFile     string   `json:"ssh_key_file,omitempty"`  // Private key for SSH public key authentication
+	SSHHostKeys    []string `json:"ssh_host_keys,omitempty"` // Pinned SHA256 fingerprints of the SSH host key
+
+	// SSHLegacyAlgorithms additionally offers SHA-1 key exchanges, CBC ciphers and HMAC-SHA1 for
+	// firmware whose SSH server supports nothing newer. Never inherited from other OLTs.
+	SSHLegacyAlgorithms bool `json:"ssh_legacy_algorithms,omitempty"`
+}
+
+// OLTSnmpConfig contains per-device SNMP credentials: a v2c community or, with version "3", SNMPv3 (USM) credentials
+type OLTSnmpConfig struct {
+	Host      string `json:"host"`      // SNMP agent address
+	Port      uint16 `json:"port"`      // SNMP port (defaults to 161)
+	Community string `json:"community"` // SNMP community string (v2c)
+
+	Version        string `json:"version,omitempty"`         // "2c" (default) or "3"
+	User           string `json:"user,omitempty"`            // SNMPv3 security name
+	AuthProtocol   string `json:"auth_protocol,omitempty"`   // "MD5", "SHA" or "SHA256"
+	AuthPassphrase string `json:"auth_passphrase,omitempty"` // Authentication passphrase
+	PrivProtocol   string `json:"priv_protocol,omitempty"`   // "DES" or "AES"
+	PrivPassphrase string `json:"priv_passphrase,omitempty"` // Privacy passphrase
+}
+
+// SnmpConfig converts the device credentials to the connection settings of the SNMP clients
+func (s OLTSnmpConfig) SnmpConfig() SnmpConfig {
+	return SnmpConfig{
+		IP:             s.Host,
+		Port:           s.Port,
+		Community:      s.Community,
+		Version:        s.Version,
+		User:           s.User,
+		AuthProtocol:   s.AuthProtocol,
+		AuthPassphrase: s.AuthPassphrase,
+		PrivProtocol:   s.PrivProtocol,
+		PrivPassphrase: s.PrivPassphrase,
+	}
+}
+
+// OLTTrapConfig contains the notification OIDs (snmpTrapOID.0) of a device, taken from the MIB of
+// its firmware or from the trap_oid of received unknown events. Notifications without a configured
+// OID are stored as unknown events.
+type OLTTrapConfig struct {
+	OnuStatusChange string `json:"onu_status_change,omitempty"` // ONU state change
+	OnuDyingGasp    string `json:"onu_dying_gasp,omitempty"`    // ONU power failure
+	OnuLOS          string `json:"onu_los,omitempty"`           // ONU loss of signal
+	PonPortDown     string `json:"pon_port_down,omitempty"`     // PON port link down
+	PonPortUp       string `json:"pon_port_up,omitempty"`       // PON port link up
+	CardOffline     string `json:"card_offline,omitempty"`      // Card removed or offline
+	CardOnline      string `json:"card_online,omitempty"`       // Card inserted or online
+}
+
+// oids lists the configured OIDs by JSON name, for validation
+func (t OLTTrapConfig) oids() map[string]string {
+	return map[string]string{
+		"onu_status_change": t.OnuStatusChange,
+		"onu_dying_gasp":    t.OnuDyingGasp,
+		"onu_los":           t.OnuLOS,
+		"pon_port_down":     t.PonPortDown,
+		"pon_port_up":       t.PonPortUp,
+		"card_offline":      t.CardOffline,
+		"card_online":       t.CardOnline,
+	}
+}
+
+// trapOIDPattern accepts numeric OIDs with or without the leading dot
+var trapOIDPattern = regexp.MustCompile(`^\.?[0-9]+(\.[0-9]+)+$`)
+
+// OLTDeviceConfig describes a single OLT managed by this instance
+type OLTDeviceConfig struct {
+	ID       string          `json:"id"`              // Unique identifier used in /api/v1/olts/{olt_id}
+	Name     string          `json:"name"`            // Human-readable name
+	Firmware FirmwareVersion `json:"firmware"`        // Firmware profile (v2.1, v2.2 or auto to detect it)
+	Snmp     OLTSnmpConfig   `json:"snmp"`            // SNMP credentials
+	Telnet   OLTTelnetConfig `json:"telnet"`          // Telnet credentials
+	Traps    OLTTrapConfig   `json:"traps,omitempty"` // Notification OIDs decoded by the trap receiver
+}
+
+// OLTRegistryConfig is the on-disk format of the OLT registry file
+type OLTRegistryConfig struct {
+	OLTs []OLTDeviceConfig `json:"olts"` // Registered OLT devices
+}
+
+// LoadOLTRegistry loads the OLT device list.
+// If OLT_REGISTRY_FILE is set, devices are read from that JSON file; otherwise a single
+// "default" device is built from the legacy SNMP_* / TELNET_* environment variables.
+func LoadOLTRegistry(base *Config) ([]OLTDeviceConfig, error) {
+	path := getEnv("OLT_REGISTRY_FILE", "")
+	if path == "" {
+		device := defaultOLTDevice(base)
+		if err := device.validateTraps(); err != nil {
+			return nil, err
+		}
+		if err := device.validateCLI(); err != nil {
+			return nil, err
+		}
+		return []OLTDeviceConfig{device}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OLT registry file %s: %w", path, err)
+	}
+
+	return ParseOLTRegistry(data)
+}
+
+// ParseOLTRegistry parses and validates an OLT registry document
+func ParseOLTRegistry(data []byte) ([]OLTDeviceConfig, error) {
+	var registry OLTRegistryConfig
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse OLT registry: %w", err)
+	}
+
+	if len(registry.OLTs) == 0 {
+		return nil, ErrInvalidConfig("OLT registry must contain at least one OLT")
+	}
+
+	seen := make(map[string]bool, len(registry.OLTs))
+	for i := range registry.OLTs {
+		device := &registry.OLTs[i]
+		if err := device.normalize(); err != nil {
+			return nil, err
+		}
+		if seen[device.ID] {
+			return nil, ErrInvalidConfig(fmt.Sprintf("duplicate OLT id %q", device.ID))
+		}
+		seen[device.ID] = true
+	}
+
+	return registry.OLTs, nil
+}
+
+// normalize validates a device entry and fills in defaults
+func (d *OLTDeviceConfig) normalize() error {
+	if !oltIDPattern.MatchString(d.ID) {
+		return ErrInvalidConfig(fmt.Sprintf("invalid OLT id %q (allowed: letters, digits, '-' and '_')", d.ID))
+	}
+	if d.Snmp.Host == "" {
+		return ErrInvalidConfig(fmt.Sprintf("OLT %s: snmp host is required", d.ID))
+	}
+	if err := d.Snmp.SnmpConfig().Validate(); err != nil {
+		return ErrInvalidConfig(fmt.Sprintf("OLT %s: snmp %v", d.ID, err))
+	}
+	if d.Snmp.Port == 0 {
+		d.Snmp.Port = 161
+	}
+	if d.Name == "" {
+		d.Name = d.ID
+	}
+
+	firmware, ok := ParseFirmwareVersion(string(d.Firmware))
+	switch {
+	case !ok:
+		return ErrInvalidConfig(fmt.Sprintf("OLT %s: unsupported firmware %q", d.ID, d.Firmware))
+	case d.Firmware == "":
+		d.Firmware = ConfiguredFirmwareVersion()
+	default:
+		d.Firmware = firmware
+	}
+
+	switch d.Telnet.Transport {
+	case "", TransportTelnet, TransportSSH:
+	default:
+		return ErrInvalidConfig(fmt.Sprintf("OLT %s: unsupported CLI transport %q (telnet or ssh)", d.ID, d.Telnet.Transport))
+	}
+
+	if err := d.validateTraps(); err != nil {
+		return err
+	}
+
+	if d.Telnet.Host == "" {
+		d.Telnet.Host = d.Snmp.Host
+	}
+	return d.validateCLI()
+}
+
+// validateTraps rejects notification OIDs given as MIB names or with typos, which would never match
+func (d *OLTDeviceConfig) validateTraps() error {
+	for name, oid := range d.Traps.oids() {
+		if oid != "" && !trapOIDPattern.MatchString(oid) {
+			return ErrInvalidConfig(fmt.Sprintf("OLT %s: traps.%s %q is not a numeric OID", d.ID, name, oid))
+		}
+	}
+	return nil
+}
+
+// validateCLI rejects SSH without a pinned host key at startup instead of at the first connection
+func (d *OLTDeviceConfig) validateCLI() error {
+	cli := d.TelnetConfig()
+	if cli.Transport == TransportSSH && len(cli.SSHHostKeys) == 0 {
+		return ErrInvalidConfig(fmt.Sprintf("OLT %s: ssh transport requires a pinned host key (ssh_host_keys or SSH_HOST_KEY_FINGERPRINTS)", d.ID))
+	}
+	return nil
+}
+
+// defaultOLTDevice builds the single-device entry from the legacy environment variables
+func defaultOLTDevice(base *Config) OLTDeviceConfig {
+	telnetCfg := LoadTelnetConfig()
+	return OLTDeviceConfig{
+		ID:       DefaultOLTID,
+		Name:     getEnv("OLT_NAME", DefaultOLTID),
+		Firmware: ConfiguredFirmwareVersion(),
+		Snmp: OLTSnmpConfig{
+			Host:           base.SnmpCfg.IP,
+			Port:           base.SnmpCfg.Port,
+			Community:      base.SnmpCfg.Community,
+			Version:        base.SnmpCfg.Version,
+			User:           base.SnmpCfg.User,
+			AuthProtocol:   base.SnmpCfg.AuthProtocol,
+			AuthPassphrase: base.SnmpCfg.AuthPassphrase,
+			PrivProtocol:   base.SnmpCfg.PrivProtocol,
+			PrivPassphrase: base.SnmpCfg.PrivPassphrase,
+		},
+		Telnet: OLTTelnetConfig{
+			Transport:      telnetCfg.Transport,
+			Host:           telnetCfg.Host,
+			Port:           telnetCfg.Port,
+			Username:       telnetCfg.Username,
+			Password:       telnetCfg.Password,
+			EnablePassword: telnetCfg.EnablePassword,
+			SSHKeyFile:     telnetCfg.SSHKeyFile,
+			SSHHostKeys:    telnetCfg.SSHHostKeys,
+
+			SSHLegacyAlgorithms: getEnv("SSH_LEGACY_ALGORITHMS", "false") == "true",
+		},
+		Traps: OLTTrapConfig{
+			OnuStatusChange: getEnv("TRAP_ONU_STATUS_CHANGE_OID", ""),
+			OnuDyingGasp:    getEnv("TRAP_ONU_DYING_GASP_OID", ""),
+			OnuLOS:          getEnv("TRAP_ONU_LOS_OID", ""),
+			PonPortDown:     getEnv("TRAP_PON_PORT_DOWN_OID", ""),
+			PonPortUp:       getEnv("TRAP_PON_PORT_UP_OID", ""),
+			CardOffline:     getEnv("TRAP_CARD_OFFLINE_OID", ""),
+			CardOnline:      getEnv("TRAP_CARD_ONLINE_OID", ""),
+		},
+	}
+}
+
+// TelnetConfig returns the CLI configuration for this device.
+// Timeouts, retries and prompts are shared and come from the TELNET_* environment variables.
+func (d *OLTDeviceConfig) TelnetConfig() *TelnetConfig {
+	cfg := LoadTelnetConfig()
+	if d.Telnet.Transport != "" && d.Telnet.Transport != cfg.Transport {
+		cfg.Transport = d.Telnet.Transport
+		cfg.Port = cliPort(cfg.Transport)
+	}
+	if d.Telnet.Host != "" {
+		cfg.Host = d.Telnet.Host
+	}
+	if d.Telnet.Port != 0 {
+		cfg.Port = d.Telnet.Port
+	}
+	if d.Telnet.Username != "" {
+		cfg.Username = d.Telnet.Username
+	}
+	if d.Telnet.Password != "" {
+		cfg.Password = d.Telnet.Password
+	}
+	if d.Telnet.EnablePassword != "" {
+		cfg.EnablePassword = d.Telnet.EnablePassword
+	}
+	if d.Telnet.SSHKeyFile != "" {
+		cfg.SSHKeyFile = d.Telnet.SSHKeyFile
+	}
+	if len(d.Telnet.SSHHostKeys) > 0 {
+		cfg.SSHHostKeys = d.Telnet.SSHHostKeys
+	}
+	cfg.SSHLegacyAlgorithms = d.Telnet.SSHLegacyAlgorithms
+	return cfg
+}
+
+// OIDProfile returns the OID profile of the device firmware with the notification OIDs of the device.
+// The OID environment variables (OLT_BASE_OID, ONU_*_PREFIX, ...) describe the default OLT and only apply to it.
+func (d *OLTDeviceConfig) OIDProfile() *OIDProfile {
+	profile := GetOIDProfileForVersion(d.firmware())
+	if d.ID == DefaultOLTID {
+		profile = withOIDOverrides(profile)
+	} else {
+		copied := *profile
+		profile = &copied
+	}
+	profile.TrapOnuStatusChangeOID = d.Traps.OnuStatusChange
+	profile.TrapOnuDyingGaspOID = d.Traps.OnuDyingGasp
+	profile.TrapOnuLOSOID = d.Traps.OnuLOS
+	profile.TrapPonPortDownOID = d.Traps.PonPortDown
+	profile.TrapPonPortUpOID = d.Traps.PonPortUp
+	profile.TrapCardOfflineOID = d.Traps.CardOffline
+	profile.TrapCardOnlineOID = d.Traps.CardOnline
+	return profile
+}
+
+// firmware returns the firmware profile of the device; a firmware that was not detected (yet)
+// falls back to the pinned ZTE_FIRMWARE_VERSION or V2.1
+func (d *OLTDeviceConfig) firmware() FirmwareVersion {
+	if d.Firmware == FirmwareAuto || d.Firmware == "" {
+		return GetCurrentFirmwareVersion()
+	}
+	return d.Firmware
+}
+
+// DeviceConfig derives the per-device application configuration from the base configuration.
+// The OID mappings are generated from the profile of the device's own firmware. Devices other
+// than the default OLT also get a dedicated backup directory and S3 prefix.
+func (d *OLTDeviceConfig) DeviceConfig(base *Config) (*Config, error) {
+	cfg := *base
+	cfg.SnmpCfg = d.Snmp.SnmpConfig()
+	cfg.SnmpCfg.StartupProbe = base.SnmpCfg.StartupProbe
+	cfg.OltCfg.Host = d.Telnet.Host
+
+	profile := d.OIDProfile()
+	boardPonMap, err := InitializeBoardPonMapForProfile(profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Board/PON OID mappings for OLT %s: %w", d.ID, err)
+	}
+	cfg.Firmware = d.firmware()
+	cfg.OIDProfile = profile
+	cfg.BoardPonMap = boardPonMap
+	cfg.oidMu = new(sync.RWMutex) // Not shared with base: the OID mappings of each OLT are switched separately
+	cfg.OltCfg.BaseOID1 = profile.BaseOID
+	cfg.OltCfg.OnuIDNameAllPon = profile.OnuIDNamePrefix
+	cfg.OltCfg.OnuTypeAllPon = profile.OnuTypePrefix
+
+	if d.ID == DefaultOLTID {
+		cfg.OltCfg.BaseOID1 = getEnv("OLT_BASE_OID_1", profile.BaseOID)
+		return &cfg, nil
+	}
+
+	if base.OltCfg.BackupDir != "" {
+		cfg.OltCfg.BackupDir = filepath.Join(base.OltCfg.BackupDir, d.ID)
+	}
+	cfg.BackupStore.S3Prefix = path.Join(base.BackupStore.S3Prefix, d.ID)
+
+	return &cfg, nil
+}
@@ -0,0 +1,306 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseOLTRegistry(t *testing.T) {
+	data := []byte(`{"olts":[
+		{"id":"core-1","name":"Core 1","firmware":"v2.2","snmp":{"host":"10.0.0.1","community":"public"}},
+		{"id":"edge_2","snmp":{"host":"10.0.0.2","port":1161,"community":"private"},"telnet":{"host":"10.0.1.2","port":2323,"username":"ops","password":"secret"}}
+	]}`)
+
+	devices, err := ParseOLTRegistry(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(devices) != 2 {
+		t.Fatalf("Expected 2 devices, got %d", len(devices))
+	}
+
+	if devices[0].Firmware != FirmwareV22 {
+		t.Errorf("Expected firmware %s, got %s", FirmwareV22, devices[0].Firmware)
+	}
+	if devices[0].Snmp.Port != 161 {
+		t.Errorf("Expected default SNMP port 161, got %d", devices[0].Snmp.Port)
+	}
+	if devices[0].Telnet.Host != "10.0.0.1" {
+		t.Errorf("Expected telnet host to default to SNMP host, got %s", devices[0].Telnet.Host)
+	}
+	if devices[1].Name != "edge_2" {
+		t.Errorf("Expected name to default to id, got %s", devices[1].Name)
+	}
+	if want := ConfiguredFirmwareVersion(); devices[1].Firmware != want {
+		t.Errorf("Expected firmware to default to %s, got %s", want, devices[1].Firmware)
+	}
+
+	telnetCfg := devices[1].TelnetConfig()
+	if telnetCfg.Host != "10.0.1.2" || telnetCfg.Port != 2323 || telnetCfg.Username != "ops" || telnetCfg.Password != "secret" {
+		t.Errorf("Unexpected telnet config: %+v", telnetCfg)
+	}
+}
+
+func TestParseOLTRegistry_SNMPv3(t *testing.T) {
+	data := []byte(`{"olts":[{"id":"secure","snmp":{"host":"10.0.0.3","version":"3","user":"nms",
+		"auth_protocol":"SHA-256","auth_passphrase":"auth-secret","priv_protocol":"AES","priv_passphrase":"priv-secret"}}]}`)
+
+	devices, err := ParseOLTRegistry(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cfg, err := devices[0].DeviceConfig(&Config{SnmpCfg: SnmpConfig{StartupProbe: true}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !cfg.SnmpCfg.IsV3() || cfg.SnmpCfg.User != "nms" || cfg.SnmpCfg.SecurityLevel() != "authPriv" {
+		t.Errorf("Unexpected SNMP config: %+v", cfg.SnmpCfg)
+	}
+	if !cfg.SnmpCfg.StartupProbe {
+		t.Error("Expected the startup probe setting to be kept")
+	}
+}
+
+func TestParseOLTRegistry_SSH(t *testing.T) {
+	t.Setenv("CLI_TRANSPORT", "")
+	t.Setenv("SSH_PORT", "")
+	data := []byte(`{"olts":[{"id":"new","snmp":{"host":"10.0.0.4","community":"public"},"telnet":{"transport":"ssh","username":"ops",
+		"ssh_key_file":"/etc/c320/id_ed25519","ssh_host_keys":["SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s"]}},
+		{"id":"old","snmp":{"host":"10.0.0.5","community":"public"},"telnet":{"transport":"ssh","password":"secret",
+		"ssh_host_keys":["SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s"],"ssh_legacy_algorithms":true}}]}`)
+
+	devices, err := ParseOLTRegistry(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	cfg := devices[0].TelnetConfig()
+	if cfg.Transport != TransportSSH || cfg.Port != 22 || cfg.Host != "10.0.0.4" {
+		t.Errorf("Expected SSH to 10.0.0.4:22, got %s to %s:%d", cfg.Transport, cfg.Host, cfg.Port)
+	}
+	if cfg.SSHKeyFile != "/etc/c320/id_ed25519" || len(cfg.SSHHostKeys) != 1 {
+		t.Errorf("Unexpected SSH settings: key file %q, host keys %v", cfg.SSHKeyFile, cfg.SSHHostKeys)
+	}
+	if cfg.SSHLegacyAlgorithms || !devices[1].TelnetConfig().SSHLegacyAlgorithms {
+		t.Error("Expected the legacy SSH algorithms only for the OLT that opted in")
+	}
+}
+
+func TestParseOLTRegistry_Traps(t *testing.T) {
+	data := []byte(`{"olts":[{"id":"a","firmware":"v2.2","snmp":{"host":"10.0.0.1","community":"public"},
+		"traps":{"onu_los":".1.3.6.1.4.1.32473.1.0.3","card_online":"1.3.6.1.4.1.32473.3.0.2"}},
+		{"id":"b","firmware":"v2.2","snmp":{"host":"10.0.0.2","community":"public"}}]}`)
+
+	devices, err := ParseOLTRegistry(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	profile := devices[0].OIDProfile()
+	if profile.TrapOnuLOSOID != ".1.3.6.1.4.1.32473.1.0.3" || profile.TrapCardOnlineOID != "1.3.6.1.4.1.32473.3.0.2" {
+		t.Errorf("Expected the configured trap OIDs, got %s and %s", profile.TrapOnuLOSOID, profile.TrapCardOnlineOID)
+	}
+	if profile.TrapOnuStatusChangeOID != "" || devices[1].OIDProfile().TrapOnuLOSOID != "" {
+		t.Error("Expected notification OIDs only where they are configured")
+	}
+	if GetOIDProfileForVersion(FirmwareV22).TrapOnuLOSOID != "" {
+		t.Error("Expected the shared firmware profile to be left unchanged")
+	}
+}
+
+func TestParseOLTRegistry_Invalid(t *testing.T) {
+	t.Setenv("CLI_TRANSPORT", "")
+	t.Setenv("SSH_HOST_KEY_FINGERPRINTS", "")
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty list", `{"olts":[]}`, "at least one OLT"},
+		{"invalid id", `{"olts":[{"id":"a/b","snmp":{"host":"h","community":"c"}}]}`, "invalid OLT id"},
+		{"missing host", `{"olts":[{"id":"a","snmp":{"community":"c"}}]}`, "snmp host is required"},
+		{"missing community", `{"olts":[{"id":"a","snmp":{"host":"h"}}]}`, "snmp community is required"},
+		{"unknown firmware", `{"olts":[{"id":"a","firmware":"v9","snmp":{"host":"h","community":"c"}}]}`, "unsupported firmware"},
+		{"unknown firmware build", `{"olts":[{"id":"a","firmware":"v2.3","snmp":{"host":"h","community":"c"}}]}`, "unsupported firmware"},
+		{"duplicate id", `{"olts":[{"id":"a","snmp":{"host":"h","community":"c"}},{"id":"a","snmp":{"host":"h","community":"c"}}]}`, "duplicate OLT id"},
+		{"malformed json", `{"olts":`, "failed to parse"},
+		{"v3 without user", `{"olts":[{"id":"a","snmp":{"host":"h","version":"3"}}]}`, "snmp user is required"},
+		{"unknown transport", `{"olts":[{"id":"a","snmp":{"host":"h","community":"c"},"telnet":{"transport":"rsh"}}]}`, "unsupported CLI transport"},
+		{"ssh without host key", `{"olts":[{"id":"a","snmp":{"host":"h","community":"c"},"telnet":{"transport":"ssh"}}]}`, "requires a pinned host key"},
+		{"invalid trap oid", `{"olts":[{"id":"a","snmp":{"host":"h","community":"c"},"traps":{"onu_los":"zteOnuLos"}}]}`, "traps.onu_los"},
+		{"v3 short passphrase", `{"olts":[{"id":"a","snmp":{"host":"h","version":"3","user":"u","auth_protocol":"SHA","auth_passphrase":"short"}}]}`, "auth_passphrase must be at least 8 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseOLTRegistry([]byte(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadOLTRegistry_DefaultFromEnv(t *testing.T) {
+	os.Unsetenv("OLT_REGISTRY_FILE")
+
+	base := &Config{SnmpCfg: SnmpConfig{IP: "192.168.1.1", Port: 161, Community: "public"}}
+	devices, err := LoadOLTRegistry(base)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(devices) != 1 || devices[0].ID != DefaultOLTID {
+		t.Fatalf("Expected single default OLT, got %+v", devices)
+	}
+	if devices[0].Snmp.Host != "192.168.1.1" {
+		t.Errorf("Expected SNMP host 192.168.1.1, got %s", devices[0].Snmp.Host)
+	}
+
+	t.Setenv("CLI_TRANSPORT", "ssh")
+	t.Setenv("SSH_HOST_KEY_FINGERPRINTS", "")
+	if _, err := LoadOLTRegistry(base); err == nil || !strings.Contains(err.Error(), "requires a pinned host key") {
+		t.Errorf("Expected SSH without host key to be rejected, got %v", err)
+	}
+}
+
+func TestLoadOLTRegistry_FromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "olts.json")
+	if err := os.WriteFile(path, []byte(`{"olts":[{"id":"core-1","snmp":{"host":"10.0.0.1","community":"public"}}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OLT_REGISTRY_FILE", path)
+
+	devices, err := LoadOLTRegistry(&Config{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(devices) != 1 || devices[0].ID != "core-1" {
+		t.Errorf("Expected core-1, got %+v", devices)
+	}
+}
+
+func TestOLTDeviceConfig_DeviceConfig(t *testing.T) {
+	base, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load base config: %v", err)
+	}
+	base.OltCfg.BackupDir = "/tmp/backups"
+	base.BackupStore.S3Prefix = "olt-backups"
+
+	other := FirmwareV22
+	if GetCurrentFirmwareVersion() == FirmwareV22 {
+		other = FirmwareV21
+	}
+
+	device := OLTDeviceConfig{
+		ID:       "core-1",
+		Firmware: other,
+		Snmp:     OLTSnmpConfig{Host: "10.0.0.1", Port: 161, Community: "public"},
+		Telnet:   OLTTelnetConfig{Host: "10.0.0.1"},
+	}
+
+	cfg, err := device.DeviceConfig(base)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	profile := GetOIDProfileForVersion(other)
+	if cfg.OltCfg.BaseOID1 != profile.BaseOID {
+		t.Errorf("Expected base OID %s, got %s", profile.BaseOID, cfg.OltCfg.BaseOID1)
+	}
+	if cfg.SnmpCfg.IP != "10.0.0.1" {
+		t.Errorf("Expected SNMP IP 10.0.0.1, got %s", cfg.SnmpCfg.IP)
+	}
+	if cfg.OltCfg.BackupDir != filepath.Join("/tmp/backups", "core-1") {
+		t.Errorf("Expected per-OLT backup dir, got %s", cfg.OltCfg.BackupDir)
+	}
+	if cfg.BackupStore.S3Prefix != "olt-backups/core-1" {
+		t.Errorf("Expected per-OLT S3 prefix, got %s", cfg.BackupStore.S3Prefix)
+	}
+
+	expected, _ := GenerateBoardPonOIDForProfile(profile, 1, 1)
+	if cfg.BoardPonMap[BoardPonKey{BoardID: 1, PonID: 1}].OnuIDNameOID != expected.OnuIDNameOID {
+		t.Errorf("Expected BoardPonMap generated from %s profile", other)
+	}
+	if base.OltCfg.BackupDir != "/tmp/backups" {
+		t.Error("Expected base config to be left untouched")
+	}
+}
+
+func TestOLTDeviceConfig_DeviceConfig_DetectedDefault(t *testing.T) {
+	base, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load base config: %v", err)
+	}
+	base.OltCfg.BackupDir = "/tmp/backups"
+
+	other := FirmwareV22
+	if GetCurrentFirmwareVersion() == FirmwareV22 {
+		other = FirmwareV21
+	}
+
+	// The default OLT detected on another firmware gets the OID mappings of that profile
+	device := defaultOLTDevice(base)
+	device.Firmware = other
+	cfg, err := device.DeviceConfig(base)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if profile := GetOIDProfileForVersion(other); cfg.OltCfg.BaseOID1 != profile.BaseOID || device.OIDProfile().Name != profile.Name {
+		t.Errorf("Expected the %s profile, got base OID %s", other, cfg.OltCfg.BaseOID1)
+	}
+	if cfg.Firmware != other {
+		t.Errorf("Expected firmware %s, got %s", other, cfg.Firmware)
+	}
+	if cfg.OltCfg.BackupDir != "/tmp/backups" {
+		t.Errorf("Expected the default OLT to keep the backup dir, got %s", cfg.OltCfg.BackupDir)
+	}
+
+	// Not detected yet: the pinned firmware or V2.1 is used
+	device.Firmware = FirmwareAuto
+	cfg, err = device.DeviceConfig(base)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := GetOIDProfileForVersion(GetCurrentFirmwareVersion()).BaseOID; cfg.OltCfg.BaseOID1 != want {
+		t.Errorf("Expected base OID %s, got %s", want, cfg.OltCfg.BaseOID1)
+	}
+}
+
+func TestOLTDeviceConfig_DeviceConfig_OIDOverrides(t *testing.T) {
+	t.Setenv("ONU_TYPE_PREFIX", ".3.99.1")
+	t.Setenv("TRAP_ONU_LOS_OID", ".1.3.6.1.4.1.3902.99.0.3")
+	base, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("Failed to load base config: %v", err)
+	}
+
+	// The environment overrides describe the default OLT, whatever firmware it is detected on
+	for _, firmware := range []FirmwareVersion{FirmwareV21, FirmwareV22} {
+		device := defaultOLTDevice(base)
+		device.Firmware = firmware
+		cfg, err := device.DeviceConfig(base)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if cfg.OltCfg.OnuTypeAllPon != ".3.99.1" || !strings.HasPrefix(cfg.BoardPonMap[BoardPonKey{BoardID: 1, PonID: 1}].OnuTypeOID, ".3.99.1.") {
+			t.Errorf("%s: expected the ONU type override, got %s", firmware, cfg.OltCfg.OnuTypeAllPon)
+		}
+		if got := device.OIDProfile().TrapOnuLOSOID; got != ".1.3.6.1.4.1.3902.99.0.3" {
+			t.Errorf("%s: expected the trap OID override, got %s", firmware, got)
+		}
+	}
+
+	// OLTs of the registry file are read with their plain firmware profile
+	device := OLTDeviceConfig{ID: "core-1", Firmware: GetCurrentFirmwareVersion(), Snmp: OLTSnmpConfig{Host: "10.0.0.1"}}
+	cfg, err := device.DeviceConfig(base)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if profile := GetOIDProfileForVersion(device.Firmware); cfg.OltCfg.OnuTypeAllPon != profile.OnuTypePrefix {
+		t.Errorf("Expected the %s ONU type prefix, got %s", device.Firmware, cfg.OltCfg.OnuTypeAllPon)
+	}
+}
@@ -2,13 +2,21 @@ package config
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
-// TelnetConfig holds configuration for Telnet connections
+// CLI transports
+const (
+	TransportTelnet = "telnet"
+	TransportSSH    = "ssh"
+)
+
+// TelnetConfig holds configuration for CLI connections over Telnet or SSH
 type TelnetConfig struct {
+	Transport      string        // "telnet" (default) or "ssh"
 	Host           string        // OLT IP address
-	Port           int           // Telnet port (usually 23)
+	Port           int           // Telnet port (usually 23) or SSH port (usually 22)
 	Username       string        // Telnet username
 	Password       string        // Telnet password
 	EnablePassword string        // Enable mode password
@@ -18,28 +26,35 @@ type TelnetConfig struct {
 	WriteTimeout   time.Duration // Write timeout
 	RetryCount     int           // Number of retry attempts
 	RetryDelay     time.Duration // Delay between retries
-	PoolSize       int           // Connection pool size
+	PoolSize       int           // Maximum number of concurrent sessions to the OLT
 	MaxIdleTime    time.Duration // Max idle time before closing connection
 	PromptUser     string        // User mode prompt (e.g., "ZXAN>")
 	PromptEnable   string        // Enable mode prompt (e.g., "ZXAN#")
 	PromptConfig   string        // Config mode prompt (e.g., "ZXAN(config)#")
+	SSHKeyFile     string        // Private key for SSH public key authentication (optional)
+	SSHHostKeys    []string      // Pinned SHA256 fingerprints of the OLT's SSH host key
+
+	// SSHLegacyAlgorithms offers SHA-1 key exchanges, CBC ciphers and HMAC-SHA1. Set per OLT
+	// (ssh_legacy_algorithms, SSH_LEGACY_ALGORITHMS for the default OLT), never by LoadTelnetConfig.
+	SSHLegacyAlgorithms bool
 }
 
 // LoadTelnetConfig loads telnet configuration from environment variables
 func LoadTelnetConfig() *TelnetConfig {
-	port, _ := strconv.Atoi(getEnv("TELNET_PORT", "23"))
+	transport := strings.ToLower(getEnv("CLI_TRANSPORT", TransportTelnet))
 	timeout, _ := strconv.Atoi(getEnv("TELNET_TIMEOUT", "30"))
 	connectTimeout, _ := strconv.Atoi(getEnv("TELNET_CONNECT_TIMEOUT", "10"))
 	readTimeout, _ := strconv.Atoi(getEnv("TELNET_READ_TIMEOUT", "30"))
 	writeTimeout, _ := strconv.Atoi(getEnv("TELNET_WRITE_TIMEOUT", "10"))
 	retryCount, _ := strconv.Atoi(getEnv("TELNET_RETRY_COUNT", "3"))
 	retryDelay, _ := strconv.Atoi(getEnv("TELNET_RETRY_DELAY", "2"))
-	poolSize, _ := strconv.Atoi(getEnv("TELNET_POOL_SIZE", "1"))
+	poolSize, _ := strconv.Atoi(getEnv("TELNET_POOL_SIZE", "2"))
 	maxIdleTime, _ := strconv.Atoi(getEnv("TELNET_MAX_IDLE_TIME", "300"))
 
 	return &TelnetConfig{
+		Transport:      transport,
 		Host:           getEnv("TELNET_HOST", "136.1.1.100"),
-		Port:           port,
+		Port:           cliPort(transport),
 		Username:       getEnv("TELNET_USERNAME", "admin"),
 		Password:       getEnv("TELNET_PASSWORD", ""),
 		EnablePassword: getEnv("TELNET_ENABLE_PASSWORD", ""),
@@ -54,9 +69,32 @@ func LoadTelnetConfig() *TelnetConfig {
 		PromptUser:     getEnv("TELNET_PROMPT_USER", "ZXAN>"),
 		PromptEnable:   getEnv("TELNET_PROMPT_ENABLE", "ZXAN#"),
 		PromptConfig:   getEnv("TELNET_PROMPT_CONFIG", "ZXAN(config)#"),
+		SSHKeyFile:     getEnv("SSH_KEY_FILE", ""),
+		SSHHostKeys:    splitList(getEnv("SSH_HOST_KEY_FINGERPRINTS", "")),
 	}
 }
 
+// cliPort returns the port of a transport: TELNET_PORT (default 23) or SSH_PORT (default 22)
+func cliPort(transport string) int {
+	if transport == TransportSSH {
+		port, _ := strconv.Atoi(getEnv("SSH_PORT", "22"))
+		return port
+	}
+	port, _ := strconv.Atoi(getEnv("TELNET_PORT", "23"))
+	return port
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // Validate validates telnet configuration
 func (c *TelnetConfig) Validate() error {
 	if c.Host == "" {
@@ -65,12 +103,18 @@ func (c *TelnetConfig) Validate() error {
 	if c.Port <= 0 || c.Port > 65535 {
 		return ErrInvalidConfig("telnet port must be between 1 and 65535")
 	}
+	if c.Transport != TransportTelnet && c.Transport != TransportSSH {
+		return ErrInvalidConfig("CLI transport must be telnet or ssh")
+	}
 	if c.Username == "" {
 		return ErrInvalidConfig("telnet username is required")
 	}
-	if c.Password == "" {
+	if c.Password == "" && (c.Transport != TransportSSH || c.SSHKeyFile == "") {
 		return ErrInvalidConfig("telnet password is required")
 	}
+	if c.Transport == TransportSSH && len(c.SSHHostKeys) == 0 {
+		return ErrInvalidConfig("SSH host key fingerprint is required (SSH_HOST_KEY_FINGERPRINTS)")
+	}
 	if c.Timeout <= 0 {
 		return ErrInvalidConfig("telnet timeout must be positive")
 	}
@@ -15,6 +15,9 @@ const (
 	ErrorTypeRedis      ErrorType = "REDIS_ERROR"      // Error type for Redis operations
 	ErrorTypeConfig     ErrorType = "CONFIG_ERROR"     // Error type for configuration issues
 	ErrorTypeInternal   ErrorType = "INTERNAL_ERROR"   // Error type for internal server errors
+
+	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED" // Error type for missing or invalid credentials
+	ErrorTypeForbidden    ErrorType = "FORBIDDEN"    // Error type for credentials lacking a scope or board/PON
 )
 
 // AppError represents a structured application error
@@ -99,3 +102,22 @@ func NewInternalError(message string, err error) *AppError {
 		Err:     err,
 	}
 }
+
+// NewUnauthorizedError creates a new unauthorized error
+// Used when a request carries no credentials or credentials that are not accepted.
+func NewUnauthorizedError(message string) *AppError {
+	return &AppError{
+		Type:    ErrorTypeUnauthorized,
+		Message: message,
+	}
+}
+
+// NewForbiddenError creates a new forbidden error
+// Used when valid credentials are not allowed to perform the request.
+func NewForbiddenError(message string, details map[string]interface{}) *AppError {
+	return &AppError{
+		Type:    ErrorTypeForbidden,
+		Message: message,
+		Details: details,
+	}
+}
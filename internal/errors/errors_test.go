@@ -384,3 +384,27 @@ func TestAppError_ComplexDetails(t *testing.T) {
 		t.Error("Expected metadata with source 'SNMP'")
 	}
 }
+
+func TestNewUnauthorizedError(t *testing.T) {
+	err := NewUnauthorizedError("API key is required")
+
+	if err.Type != ErrorTypeUnauthorized {
+		t.Errorf("Expected type ErrorTypeUnauthorized, got %s", err.Type)
+	}
+
+	if err.Error() != "UNAUTHORIZED: API key is required" {
+		t.Errorf("Expected error string 'UNAUTHORIZED: API key is required', got '%s'", err.Error())
+	}
+}
+
+func TestNewForbiddenError(t *testing.T) {
+	err := NewForbiddenError("missing scope", map[string]interface{}{"required_scope": "batch"})
+
+	if err.Type != ErrorTypeForbidden {
+		t.Errorf("Expected type ErrorTypeForbidden, got %s", err.Type)
+	}
+
+	if err.Details["required_scope"] != "batch" {
+		t.Errorf("Expected required_scope 'batch', got %v", err.Details["required_scope"])
+	}
+}
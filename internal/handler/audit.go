@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// AuditHandler serves the audit log of OLT changes
+type AuditHandler struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+// NewAuditHandler creates a new audit log handler
+func NewAuditHandler(auditUsecase usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{auditUsecase: auditUsecase}
+}
+
+// ListEntries godoc
+// @Summary      List audit log entries
+// @Description  Get the recorded OLT-changing operations (register, delete, VLAN, T-CONT, GEM port, DBA profile, block, reboot, restore and batch operations) with the caller, request ID, target ONUs, CLI commands and raw OLT output, newest first
+// @Tags         Audit
+// @Produce      json
+// @Param        olt_id    query string false "Only entries of this OLT"
+// @Param        user      query string false "Only entries of this user, e.g. alice or api_key:noc-dashboard"
+// @Param        operation query string false "Only this operation (onu.block) or operation group (onu, batch)"
+// @Param        board     query int    false "Only entries targeting this board"
+// @Param        pon       query int    false "Only entries targeting this PON port"
+// @Param        onu_id    query int    false "Only entries targeting this ONU"
+// @Param        since     query string false "Only entries at or after this time (RFC 3339)"
+// @Param        until     query string false "Only entries before this time (RFC 3339)"
+// @Param        limit     query int    false "Maximum number of entries (default 100, max 1000)"
+// @Success      200 {object} utils.WebResponse{data=[]model.AuditEntry}
+// @Failure      400 {object} utils.ErrorResponse
+// @Failure      403 {object} utils.ErrorResponse
+// @Router       /api/v1/audit [get]
+func (h *AuditHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	query, err := parseAuditQuery(r.URL.Query())
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	entries, err := h.auditUsecase.ListEntries(r.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list audit log entries")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   entries,
+	})
+}
+
+// VerifyLog godoc
+// @Summary      Verify the audit log
+// @Description  Recompute the hash chain of the audit log. An edited, inserted or removed entry makes the log invalid from that entry on; compare last_hash with a copy kept elsewhere to detect removed trailing entries.
+// @Tags         Audit
+// @Produce      json
+// @Success      200 {object} utils.WebResponse{data=model.AuditVerification}
+// @Failure      403 {object} utils.ErrorResponse
+// @Failure      500 {object} utils.ErrorResponse
+// @Router       /api/v1/audit/verify [get]
+func (h *AuditHandler) VerifyLog(w http.ResponseWriter, r *http.Request) {
+	result, err := h.auditUsecase.Verify(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to verify audit log")
+		utils.HandleError(w, err)
+		return
+	}
+	if !result.Valid {
+		log.Error().Int64("broken_at", result.BrokenAt).Str("reason", result.Reason).Msg("Audit log hash chain is broken")
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   result,
+	})
+}
+
+// parseAuditQuery reads the audit log filters from the query parameters
+func parseAuditQuery(values url.Values) (model.AuditQuery, error) {
+	query := model.AuditQuery{
+		OLTID:     values.Get("olt_id"),
+		User:      values.Get("user"),
+		Operation: values.Get("operation"),
+	}
+
+	ints := map[string]*int{
+		"board":  &query.Board,
+		"pon":    &query.PON,
+		"onu_id": &query.ONUID,
+		"limit":  &query.Limit,
+	}
+	for name, target := range ints {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return query, apperrors.NewValidationError(fmt.Sprintf("%s must be a positive integer", name), map[string]interface{}{
+				name: value,
+			})
+		}
+		*target = parsed
+	}
+
+	times := map[string]*time.Time{
+		"since": &query.Since,
+		"until": &query.Until,
+	}
+	for name, target := range times {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, apperrors.NewValidationError(fmt.Sprintf("%s must be an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z", name), map[string]interface{}{
+				name: value,
+			})
+		}
+		*target = parsed
+	}
+
+	return query, nil
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/middleware"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// APIKeyHandler handles the administration of API keys
+type APIKeyHandler struct {
+	apiKeyUsecase usecase.APIKeyUsecase
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyUsecase usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyUsecase: apiKeyUsecase}
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description Returns all API keys with their scopes and board/PON restrictions, including revoked keys. Keys themselves are never returned.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} utils.WebResponse{data=[]model.APIKey}
+// @Failure 401 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Router /api/v1/auth/keys [get]
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.apiKeyUsecase.ListKeys(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list API keys")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   keys,
+	})
+}
+
+// GetKey godoc
+// @Summary Get API key
+// @Description Returns the scopes, restrictions and state of an API key
+// @Tags Authentication
+// @Produce json
+// @Param keyId path string true "Key ID"
+// @Success 200 {object} utils.WebResponse{data=model.APIKey}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/auth/keys/{keyId} [get]
+func (h *APIKeyHandler) GetKey(w http.ResponseWriter, r *http.Request) {
+	key, err := h.apiKeyUsecase.GetKey(r.Context(), chi.URLParam(r, "keyId"))
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   key,
+	})
+}
+
+// IssueKey godoc
+// @Summary Issue API key
+// @Description Issues an API key with the given scopes, optionally restricted to boards or PON ports.
+// @Description The key is only returned in this response; store it safely.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body model.APIKeyRequest true "Key name, scopes and restrictions"
+// @Success 201 {object} utils.WebResponse{data=model.IssuedAPIKey}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/auth/keys [post]
+func (h *APIKeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	var req model.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		utils.HandleError(w, apperrors.NewValidationError("Invalid request body", map[string]interface{}{"error": err.Error()}))
+		return
+	}
+
+	createdBy := ""
+	if principal, ok := middleware.GetPrincipal(r.Context()); ok {
+		createdBy = principal.Identity()
+	}
+
+	issued, err := h.apiKeyUsecase.IssueKey(r.Context(), &req, createdBy)
+	if err != nil {
+		log.Error().Err(err).Str("name", req.Name).Msg("Failed to issue API key")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusCreated, utils.WebResponse{
+		Code:   http.StatusCreated,
+		Status: "Created",
+		Data:   issued,
+	})
+}
+
+// RotateKey godoc
+// @Summary Rotate API key
+// @Description Replaces the secret of an API key, keeping its ID, scopes and restrictions. The old key stops working immediately.
+// @Tags Authentication
+// @Produce json
+// @Param keyId path string true "Key ID"
+// @Success 200 {object} utils.WebResponse{data=model.IssuedAPIKey}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/auth/keys/{keyId}/rotate [post]
+func (h *APIKeyHandler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "keyId")
+
+	issued, err := h.apiKeyUsecase.RotateKey(r.Context(), keyID)
+	if err != nil {
+		log.Error().Err(err).Str("key_id", keyID).Msg("Failed to rotate API key")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   issued,
+	})
+}
+
+// RevokeKey godoc
+// @Summary Revoke API key
+// @Description Rejects an API key from now on. Revoked keys stay listed with their revocation time.
+// @Tags Authentication
+// @Produce json
+// @Param keyId path string true "Key ID"
+// @Success 200 {object} utils.WebResponse{data=model.APIKey}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/auth/keys/{keyId} [delete]
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	keyID := chi.URLParam(r, "keyId")
+
+	key, err := h.apiKeyUsecase.RevokeKey(r.Context(), keyID)
+	if err != nil {
+		log.Error().Err(err).Str("key_id", keyID).Msg("Failed to revoke API key")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   key,
+	})
+}
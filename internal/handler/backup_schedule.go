@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// BackupScheduleHandler handles scheduled backup requests
+type BackupScheduleHandler struct {
+	backupSchedulerUsecase usecase.BackupSchedulerUsecase
+}
+
+// NewBackupScheduleHandler creates a new backup schedule handler
+func NewBackupScheduleHandler(backupSchedulerUsecase usecase.BackupSchedulerUsecase) *BackupScheduleHandler {
+	return &BackupScheduleHandler{
+		backupSchedulerUsecase: backupSchedulerUsecase,
+	}
+}
+
+// ListSchedules godoc
+// @Summary List backup schedules
+// @Description Returns all scheduled OLT backups with their retention policy and last run status
+// @Tags Config Backup
+// @Produce json
+// @Success 200 {object} utils.WebResponse{data=[]model.BackupSchedule}
+// @Router /api/v1/config/schedules [get]
+func (h *BackupScheduleHandler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   h.backupSchedulerUsecase.ListSchedules(),
+	})
+}
+
+// GetSchedule godoc
+// @Summary Get backup schedule
+// @Description Returns a backup schedule with its next planned run and last run status
+// @Tags Config Backup
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} utils.WebResponse{data=model.BackupSchedule}
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/config/schedules/{scheduleId} [get]
+func (h *BackupScheduleHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := chi.URLParam(r, "scheduleId")
+
+	schedule, err := h.backupSchedulerUsecase.GetSchedule(scheduleID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   schedule,
+	})
+}
+
+// CreateSchedule godoc
+// @Summary Create backup schedule
+// @Description Creates a recurring OLT backup. Backups are tagged "scheduled" and with the schedule ID, and pruned by the retention policy.
+// @Tags Config Backup
+// @Accept json
+// @Produce json
+// @Param request body model.BackupScheduleRequest true "Schedule"
+// @Success 201 {object} utils.WebResponse{data=model.BackupSchedule}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/schedules [post]
+func (h *BackupScheduleHandler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	var req model.BackupScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		utils.HandleError(w, apperrors.NewValidationError("Invalid request body", map[string]interface{}{"error": err.Error()}))
+		return
+	}
+
+	schedule, err := h.backupSchedulerUsecase.CreateSchedule(&req)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", req.ID).Msg("Failed to create backup schedule")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusCreated, utils.WebResponse{
+		Code:   http.StatusCreated,
+		Status: "Created",
+		Data:   schedule,
+	})
+}
+
+// UpdateSchedule godoc
+// @Summary Update backup schedule
+// @Description Updates the cron expression, tags, retention policy or enabled state of a schedule. Omitted fields are unchanged.
+// @Tags Config Backup
+// @Accept json
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Param request body model.BackupScheduleRequest true "Fields to change"
+// @Success 200 {object} utils.WebResponse{data=model.BackupSchedule}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/schedules/{scheduleId} [put]
+func (h *BackupScheduleHandler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := chi.URLParam(r, "scheduleId")
+
+	var req model.BackupScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Error().Err(err).Msg("Failed to decode request body")
+		utils.HandleError(w, apperrors.NewValidationError("Invalid request body", map[string]interface{}{"error": err.Error()}))
+		return
+	}
+
+	schedule, err := h.backupSchedulerUsecase.UpdateSchedule(scheduleID, &req)
+	if err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to update backup schedule")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   schedule,
+	})
+}
+
+// DeleteSchedule godoc
+// @Summary Delete backup schedule
+// @Description Deletes a schedule. Backups it created are kept.
+// @Tags Config Backup
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 200 {object} utils.WebResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/schedules/{scheduleId} [delete]
+func (h *BackupScheduleHandler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := chi.URLParam(r, "scheduleId")
+
+	if err := h.backupSchedulerUsecase.DeleteSchedule(scheduleID); err != nil {
+		log.Error().Err(err).Str("schedule_id", scheduleID).Msg("Failed to delete backup schedule")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   map[string]string{"message": "Schedule deleted successfully"},
+	})
+}
+
+// RunSchedule godoc
+// @Summary Run backup schedule now
+// @Description Starts a run of the schedule in the background. The outcome is reported in last_status of the schedule.
+// @Tags Config Backup
+// @Produce json
+// @Param scheduleId path string true "Schedule ID"
+// @Success 202 {object} utils.WebResponse{data=model.BackupSchedule}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/config/schedules/{scheduleId}/run [post]
+func (h *BackupScheduleHandler) RunSchedule(w http.ResponseWriter, r *http.Request) {
+	scheduleID := chi.URLParam(r, "scheduleId")
+
+	schedule, err := h.backupSchedulerUsecase.TriggerSchedule(scheduleID)
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusAccepted, utils.WebResponse{
+		Code:   http.StatusAccepted,
+		Status: "Accepted",
+		Data:   schedule,
+	})
+}
@@ -33,12 +33,12 @@ func NewBatchOperationsHandler(batchUsecase usecase.BatchOperationsUsecaseInterf
 
 // BatchRebootONUs godoc
 // @Summary      Batch Reboot ONUs
-// @Description  Reboot multiple ONUs in a single operation (max 50 ONUs)
+// @Description  Reboot multiple ONUs in a single operation as a background job (max 1000 ONUs)
 // @Tags         Batch Operations
 // @Accept       json
 // @Produce      json
 // @Param        request body model.BatchONURebootRequest true "Batch Reboot Request"
-// @Success      200 {object} utils.WebResponse{data=model.BatchONURebootResponse}
+// @Success      202 {object} utils.WebResponse{data=model.Job}
 // @Failure      400 {object} utils.ErrorResponse
 // @Failure      500 {object} utils.ErrorResponse
 // @Router       /api/v1/batch/reboot [post]
@@ -52,29 +52,24 @@ func (h *BatchOperationsHandler) BatchRebootONUs(w http.ResponseWriter, r *http.
 		return
 	}
 
-	response, err := h.batchUsecase.BatchRebootONUs(ctx, &req)
+	job, err := h.batchUsecase.BatchRebootONUs(ctx, &req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute batch reboot")
+		log.Error().Err(err).Msg("Failed to submit batch reboot")
 		utils.HandleError(w, err)
 		return
 	}
 
-	webResp := utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   response,
-	}
-	utils.SendJSONResponse(w, http.StatusOK, webResp)
+	sendJobAccepted(w, job)
 }
 
 // BatchBlockONUs godoc
 // @Summary      Batch Block ONUs
-// @Description  Block (disable) multiple ONUs in a single operation (max 50 ONUs)
+// @Description  Block (disable) multiple ONUs in a single operation as a background job (max 1000 ONUs)
 // @Tags         Batch Operations
 // @Accept       json
 // @Produce      json
 // @Param        request body model.BatchONUBlockRequest true "Batch Block Request"
-// @Success      200 {object} utils.WebResponse{data=model.BatchONUBlockResponse}
+// @Success      202 {object} utils.WebResponse{data=model.Job}
 // @Failure      400 {object} utils.ErrorResponse
 // @Failure      500 {object} utils.ErrorResponse
 // @Router       /api/v1/batch/block [post]
@@ -90,29 +85,24 @@ func (h *BatchOperationsHandler) BatchBlockONUs(w http.ResponseWriter, r *http.R
 
 	req.Block = true
 
-	response, err := h.batchUsecase.BatchBlockONUs(ctx, &req)
+	job, err := h.batchUsecase.BatchBlockONUs(ctx, &req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute batch block")
+		log.Error().Err(err).Msg("Failed to submit batch block")
 		utils.HandleError(w, err)
 		return
 	}
 
-	webResp := utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   response,
-	}
-	utils.SendJSONResponse(w, http.StatusOK, webResp)
+	sendJobAccepted(w, job)
 }
 
 // BatchUnblockONUs godoc
 // @Summary      Batch Unblock ONUs
-// @Description  Unblock (enable) multiple ONUs in a single operation (max 50 ONUs)
+// @Description  Unblock (enable) multiple ONUs in a single operation as a background job (max 1000 ONUs)
 // @Tags         Batch Operations
 // @Accept       json
 // @Produce      json
 // @Param        request body model.BatchONUBlockRequest true "Batch Unblock Request (without block field)"
-// @Success      200 {object} utils.WebResponse{data=model.BatchONUBlockResponse}
+// @Success      202 {object} utils.WebResponse{data=model.Job}
 // @Failure      400 {object} utils.ErrorResponse
 // @Failure      500 {object} utils.ErrorResponse
 // @Router       /api/v1/batch/unblock [post]
@@ -128,29 +118,24 @@ func (h *BatchOperationsHandler) BatchUnblockONUs(w http.ResponseWriter, r *http
 
 	req.Block = false
 
-	response, err := h.batchUsecase.BatchUnblockONUs(ctx, &req)
+	job, err := h.batchUsecase.BatchUnblockONUs(ctx, &req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute batch unblock")
+		log.Error().Err(err).Msg("Failed to submit batch unblock")
 		utils.HandleError(w, err)
 		return
 	}
 
-	webResp := utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   response,
-	}
-	utils.SendJSONResponse(w, http.StatusOK, webResp)
+	sendJobAccepted(w, job)
 }
 
 // BatchDeleteONUs godoc
 // @Summary      Batch Delete ONUs
-// @Description  Delete multiple ONU configurations in a single operation (max 50 ONUs)
+// @Description  Delete multiple ONU configurations in a single operation as a background job (max 1000 ONUs)
 // @Tags         Batch Operations
 // @Accept       json
 // @Produce      json
 // @Param        request body model.BatchONUDeleteRequest true "Batch Delete Request"
-// @Success      200 {object} utils.WebResponse{data=model.BatchONUDeleteResponse}
+// @Success      202 {object} utils.WebResponse{data=model.Job}
 // @Failure      400 {object} utils.ErrorResponse
 // @Failure      500 {object} utils.ErrorResponse
 // @Router       /api/v1/batch/delete [post]
@@ -164,29 +149,24 @@ func (h *BatchOperationsHandler) BatchDeleteONUs(w http.ResponseWriter, r *http.
 		return
 	}
 
-	response, err := h.batchUsecase.BatchDeleteONUs(ctx, &req)
+	job, err := h.batchUsecase.BatchDeleteONUs(ctx, &req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute batch delete")
+		log.Error().Err(err).Msg("Failed to submit batch delete")
 		utils.HandleError(w, err)
 		return
 	}
 
-	webResp := utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   response,
-	}
-	utils.SendJSONResponse(w, http.StatusOK, webResp)
+	sendJobAccepted(w, job)
 }
 
 // BatchUpdateDescriptions godoc
 // @Summary      Batch Update ONU Descriptions
-// @Description  Update descriptions for multiple ONUs in a single operation (max 50 ONUs)
+// @Description  Update descriptions for multiple ONUs in a single operation as a background job (max 1000 ONUs)
 // @Tags         Batch Operations
 // @Accept       json
 // @Produce      json
 // @Param        request body model.BatchONUDescriptionRequest true "Batch Description Update Request"
-// @Success      200 {object} utils.WebResponse{data=model.BatchONUDescriptionResponse}
+// @Success      202 {object} utils.WebResponse{data=model.Job}
 // @Failure      400 {object} utils.ErrorResponse
 // @Failure      500 {object} utils.ErrorResponse
 // @Router       /api/v1/batch/descriptions [put]
@@ -200,17 +180,12 @@ func (h *BatchOperationsHandler) BatchUpdateDescriptions(w http.ResponseWriter,
 		return
 	}
 
-	response, err := h.batchUsecase.BatchUpdateDescriptions(ctx, &req)
+	job, err := h.batchUsecase.BatchUpdateDescriptions(ctx, &req)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to execute batch description update")
+		log.Error().Err(err).Msg("Failed to submit batch description update")
 		utils.HandleError(w, err)
 		return
 	}
 
-	webResp := utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   response,
-	}
-	utils.SendJSONResponse(w, http.StatusOK, webResp)
+	sendJobAccepted(w, job)
 }
@@ -2,25 +2,34 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
 	"github.com/s4lfanet/go-api-c320/internal/model"
 	"github.com/s4lfanet/go-api-c320/internal/usecase"
 	"github.com/s4lfanet/go-api-c320/internal/utils"
 )
 
+// maxBackupImportSize limits uploaded backup documents (32MB)
+const maxBackupImportSize = 32 << 20
+
 // ConfigBackupHandler handles configuration backup and restore requests
 type ConfigBackupHandler struct {
 	configBackupUsecase usecase.ConfigBackupUsecase
+	jobs                usecase.JobSubmitter // Runs OLT backups and restores in the background
 }
 
 // NewConfigBackupHandler creates a new config backup handler
-func NewConfigBackupHandler(configBackupUsecase usecase.ConfigBackupUsecase) *ConfigBackupHandler {
+func NewConfigBackupHandler(configBackupUsecase usecase.ConfigBackupUsecase, jobs usecase.JobSubmitter) *ConfigBackupHandler {
 	return &ConfigBackupHandler{
 		configBackupUsecase: configBackupUsecase,
+		jobs:                jobs,
 	}
 }
 
@@ -60,7 +69,7 @@ func (h *ConfigBackupHandler) BackupONU(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Create backup
-	backup, err := h.configBackupUsecase.BackupONU(ponPort, onuID, req.Description, req.Tags)
+	backup, err := h.configBackupUsecase.BackupONU(r.Context(), ponPort, onuID, req.Description, req.Tags)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to create ONU backup")
 		utils.HandleError(w, err)
@@ -76,12 +85,12 @@ func (h *ConfigBackupHandler) BackupONU(w http.ResponseWriter, r *http.Request)
 
 // BackupOLT godoc
 // @Summary Backup entire OLT configuration
-// @Description Creates a complete backup of all ONU configurations on the OLT
+// @Description Submits a job that creates a complete backup of all ONU configurations on the OLT. The backup is the result of the job, see GET /api/v1/jobs/{jobId}.
 // @Tags Config Backup
 // @Accept json
 // @Produce json
 // @Param request body model.BackupCreateRequest false "Backup options"
-// @Success 200 {object} utils.WebResponse{data=model.ConfigBackup}
+// @Success 202 {object} utils.WebResponse{data=model.Job}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/v1/config/backup/olt [post]
@@ -94,18 +103,14 @@ func (h *ConfigBackupHandler) BackupOLT(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
-	backup, err := h.configBackupUsecase.BackupOLT(req.Description, req.Tags)
+	job, err := h.jobs.SubmitJob(r.Context(), model.JobTypeBackupOLT, &req, nil)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to create OLT backup")
+		log.Error().Err(err).Msg("Failed to submit OLT backup job")
 		utils.HandleError(w, err)
 		return
 	}
 
-	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
-		Code:   http.StatusOK,
-		Status: "OK",
-		Data:   backup,
-	})
+	sendJobAccepted(w, job)
 }
 
 // ListBackups godoc
@@ -201,13 +206,14 @@ func (h *ConfigBackupHandler) DeleteBackup(w http.ResponseWriter, r *http.Reques
 
 // RestoreFromBackup godoc
 // @Summary Restore configuration from backup
-// @Description Restores ONU or OLT configuration from a previously created backup
+// @Description Restores ONU or OLT configuration from a previously created backup. A dry run answers with the plan right away; a restore is submitted as a job whose result is the RestoreResult, see GET /api/v1/jobs/{jobId}.
 // @Tags Config Backup
 // @Accept json
 // @Produce json
 // @Param backupId path string true "Backup ID (UUID)"
 // @Param request body model.RestoreRequest true "Restore options"
-// @Success 200 {object} utils.WebResponse{data=model.RestoreResult}
+// @Success 200 {object} utils.WebResponse{data=model.RestoreResult} "Dry run"
+// @Success 202 {object} utils.WebResponse{data=model.Job}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 404 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
@@ -225,7 +231,25 @@ func (h *ConfigBackupHandler) RestoreFromBackup(w http.ResponseWriter, r *http.R
 	// Set backup ID from path parameter
 	req.BackupID = backupID
 
-	result, err := h.configBackupUsecase.RestoreFromBackup(&req)
+	if !req.DryRun {
+		// Report a missing backup now rather than as a failed job
+		if _, err := h.configBackupUsecase.GetBackup(backupID); err != nil {
+			log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to restore from backup")
+			utils.HandleError(w, err)
+			return
+		}
+
+		job, err := h.jobs.SubmitJob(r.Context(), model.JobTypeRestore, &req, nil)
+		if err != nil {
+			log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to submit restore job")
+			utils.HandleError(w, err)
+			return
+		}
+		sendJobAccepted(w, job)
+		return
+	}
+
+	result, err := h.configBackupUsecase.RestoreFromBackup(r.Context(), &req)
 	if err != nil {
 		log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to restore from backup")
 		utils.HandleError(w, err)
@@ -270,48 +294,165 @@ func (h *ConfigBackupHandler) ExportBackup(w http.ResponseWriter, r *http.Reques
 
 // ImportBackup godoc
 // @Summary Import backup from file
-// @Description Imports a configuration backup from an uploaded JSON file
+// @Description Imports a configuration backup from an uploaded JSON file (e.g. an export of another instance). The document is validated against the backup schema, older schema versions are migrated, and the backup is stored under a new ID.
 // @Tags Config Backup
 // @Accept multipart/form-data
 // @Produce json
 // @Param file formData file true "Backup JSON file"
-// @Success 200 {object} utils.WebResponse{data=model.ConfigBackup}
+// @Success 201 {object} utils.WebResponse{data=model.ConfigBackup}
 // @Failure 400 {object} utils.ErrorResponse
 // @Failure 500 {object} utils.ErrorResponse
 // @Router /api/v1/config/backup/import [post]
 func (h *ConfigBackupHandler) ImportBackup(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form (max 32MB)
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
+	if err := r.ParseMultipartForm(maxBackupImportSize); err != nil {
 		log.Error().Err(err).Msg("Failed to parse multipart form")
-		utils.HandleError(w, err)
+		utils.HandleError(w, apperrors.NewValidationError("Invalid multipart form", map[string]interface{}{"error": err.Error()}))
 		return
 	}
 
 	file, header, err := r.FormFile("file")
 	if err != nil {
 		log.Error().Err(err).Msg("No file uploaded")
-		utils.HandleError(w, err)
+		utils.HandleError(w, apperrors.NewValidationError("Missing backup file (form field \"file\")", map[string]interface{}{"error": err.Error()}))
 		return
 	}
 	defer file.Close()
 
-	// Read backup from file
-	var backup model.ConfigBackup
-	if err := json.NewDecoder(file).Decode(&backup); err != nil {
-		log.Error().Err(err).Msg("Failed to decode backup file")
+	data, err := io.ReadAll(io.LimitReader(file, maxBackupImportSize+1))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read backup file")
+		utils.HandleError(w, apperrors.NewInternalError("failed to read backup file", err))
+		return
+	}
+	if len(data) > maxBackupImportSize {
+		utils.HandleError(w, apperrors.NewValidationError("Backup file too large", map[string]interface{}{"max_bytes": maxBackupImportSize}))
+		return
+	}
+
+	backup, err := h.configBackupUsecase.ImportBackup(data)
+	if err != nil {
+		log.Error().Err(err).Str("filename", header.Filename).Msg("Failed to import backup")
+		utils.HandleError(w, err)
+		return
+	}
+
+	log.Info().Str("filename", header.Filename).Str("backup_id", backup.ID).Msg("Backup file imported")
+
+	utils.SendJSONResponse(w, http.StatusCreated, utils.WebResponse{
+		Code:   http.StatusCreated,
+		Status: "Created",
+		Data:   backup,
+	})
+}
+
+// GetRunningConfig godoc
+// @Summary Get OLT running configuration
+// @Description Retrieves "show running-config" from the OLT and returns it parsed (ONU declarations, T-CONT, GEM port, service-port, pon-onu-mng, DBA profiles, VLANs) or as raw text
+// @Tags Config Backup
+// @Accept json
+// @Produce json
+// @Produce plain
+// @Param format query string false "Response format: json (default) or raw"
+// @Param include_raw query bool false "Include the raw output in the JSON response"
+// @Success 200 {object} utils.WebResponse{data=model.RunningConfigResponse}
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/running [get]
+func (h *ConfigBackupHandler) GetRunningConfig(w http.ResponseWriter, r *http.Request) {
+	runningConfig, raw, err := h.configBackupUsecase.GetRunningConfig()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get running-config")
+		utils.HandleError(w, err)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "raw" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(raw))
+		return
+	}
+
+	response := model.RunningConfigResponse{
+		Config:    runningConfig,
+		Lines:     strings.Count(raw, "\n") + 1,
+		Retrieved: time.Now().Format(time.RFC3339),
+	}
+	if includeRaw, _ := strconv.ParseBool(r.URL.Query().Get("include_raw")); includeRaw {
+		response.Raw = raw
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   response,
+	})
+}
+
+// DetectDrift godoc
+// @Summary Detect configuration drift
+// @Description Compares a backup against the live OLT configuration and reports, per ONU, what was added, removed or changed (VLAN, T-CONT, GEM port, service port, name, admin state)
+// @Tags Config Backup
+// @Accept json
+// @Produce json
+// @Param backupId path string true "Backup ID (UUID)"
+// @Success 200 {object} utils.WebResponse{data=model.ConfigDrift}
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/backup/{backupId}/drift [get]
+func (h *ConfigBackupHandler) DetectDrift(w http.ResponseWriter, r *http.Request) {
+	backupID := chi.URLParam(r, "backupId")
+
+	drift, err := h.configBackupUsecase.DetectDrift(backupID)
+	if err != nil {
+		log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to detect configuration drift")
 		utils.HandleError(w, err)
 		return
 	}
 
-	// Import backup (save to backup directory with new ID)
-	// For now, just return the uploaded backup
-	// TODO: Implement proper import with file saving
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   drift,
+	})
+}
+
+// DiffBackups godoc
+// @Summary Compare two backups
+// @Description Returns a structured diff of two configuration backups (ONUs added/removed and field-level changes) plus a unified-text rendering
+// @Tags Config Backup
+// @Accept json
+// @Produce json
+// @Produce plain
+// @Param from query string true "Older backup ID"
+// @Param to query string true "Newer backup ID"
+// @Param format query string false "Response format: json (default) or text (unified diff only)"
+// @Success 200 {object} utils.WebResponse{data=model.ConfigDiff}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Failure 500 {object} utils.ErrorResponse
+// @Router /api/v1/config/backups/diff [get]
+func (h *ConfigBackupHandler) DiffBackups(w http.ResponseWriter, r *http.Request) {
+	fromID := r.URL.Query().Get("from")
+	toID := r.URL.Query().Get("to")
+
+	diff, err := h.configBackupUsecase.DiffBackups(fromID, toID)
+	if err != nil {
+		log.Error().Err(err).Str("from", fromID).Str("to", toID).Msg("Failed to compare backups")
+		utils.HandleError(w, err)
+		return
+	}
 
-	log.Info().Str("filename", header.Filename).Msg("Backup file uploaded")
+	if r.URL.Query().Get("format") == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(diff.Unified))
+		return
+	}
 
 	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
-		Data:   backup,
+		Data:   diff,
 	})
 }
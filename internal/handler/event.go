@@ -0,0 +1,110 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// EventHandler handles requests for events received as SNMP traps and informs
+type EventHandler struct {
+	eventUsecase usecase.EventUsecase
+}
+
+// NewEventHandler creates a new event handler instance
+func NewEventHandler(eventUsecase usecase.EventUsecase) *EventHandler {
+	return &EventHandler{eventUsecase: eventUsecase}
+}
+
+// ListEvents godoc
+// @Summary      List OLT events
+// @Description  Get events decoded from SNMP traps and informs (ONU online/offline, dying gasp, LOS, PON port and card events), newest first
+// @Tags         Events
+// @Produce      json
+// @Param        olt_id query string false "Only events of this OLT"
+// @Param        type   query string false "Event type (onu_online, onu_offline, onu_dying_gasp, onu_los, onu_state_change, pon_port_down, pon_port_up, card_offline, card_online, unknown)"
+// @Param        board  query int    false "Only events on this board"
+// @Param        pon    query int    false "Only events on this PON port"
+// @Param        onu_id query int    false "Only events of this ONU"
+// @Param        since  query string false "Only events received at or after this time (RFC 3339)"
+// @Param        until  query string false "Only events received before this time (RFC 3339)"
+// @Param        limit  query int    false "Maximum number of events (default 100, max 1000)"
+// @Success      200 {object} utils.WebResponse{data=[]model.OLTEvent}
+// @Failure      400 {object} utils.ErrorResponse
+// @Router       /api/v1/events [get]
+func (h *EventHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	query, err := parseEventQuery(r.URL.Query())
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	events, err := h.eventUsecase.ListEvents(query)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to list events")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   events,
+	})
+}
+
+// parseEventQuery reads the event filters from the query parameters
+func parseEventQuery(values url.Values) (model.EventQuery, error) {
+	query := model.EventQuery{
+		OLTID: values.Get("olt_id"),
+		Type:  values.Get("type"),
+	}
+
+	ints := map[string]*int{
+		"board":  &query.Board,
+		"pon":    &query.PON,
+		"onu_id": &query.ONUID,
+		"limit":  &query.Limit,
+	}
+	for name, target := range ints {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return query, apperrors.NewValidationError(fmt.Sprintf("%s must be a positive integer", name), map[string]interface{}{
+				name: value,
+			})
+		}
+		*target = parsed
+	}
+
+	times := map[string]*time.Time{
+		"since": &query.Since,
+		"until": &query.Until,
+	}
+	for name, target := range times {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return query, apperrors.NewValidationError(fmt.Sprintf("%s must be an RFC 3339 timestamp, e.g. 2024-01-02T15:04:05Z", name), map[string]interface{}{
+				name: value,
+			})
+		}
+		*target = parsed
+	}
+
+	return query, nil
+}
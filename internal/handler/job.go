@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// JobHandler serves the background jobs of all OLTs
+type JobHandler struct {
+	jobUsecase usecase.JobUsecase
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobUsecase usecase.JobUsecase) *JobHandler {
+	return &JobHandler{jobUsecase: jobUsecase}
+}
+
+// ListJobs godoc
+// @Summary List jobs
+// @Description Get the batch operations, OLT backups, restores and PON monitoring jobs the caller may see, newest first. Finished jobs are kept for JOB_RETENTION.
+// @Tags Jobs
+// @Produce json
+// @Param olt_id query string false "Only jobs of this OLT"
+// @Param type   query string false "Only this job type (batch.reboot) or type group (batch)"
+// @Param status query string false "Only jobs in this state: queued, running, completed, failed or canceled"
+// @Param limit  query int    false "Maximum number of jobs (default 100, max 1000)"
+// @Success 200 {object} utils.WebResponse{data=[]model.Job}
+// @Failure 400 {object} utils.ErrorResponse
+// @Router /api/v1/jobs [get]
+func (h *JobHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseJobQuery(r.URL.Query())
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	jobs, err := h.jobUsecase.ListJobs(r.Context(), query)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list jobs")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   jobs,
+	})
+}
+
+// GetJob godoc
+// @Summary Get job
+// @Description Get the state, per-target progress and result of a job
+// @Tags Jobs
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} utils.WebResponse{data=model.Job}
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/jobs/{jobId} [get]
+func (h *JobHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobId")
+
+	job, err := h.jobUsecase.GetJob(r.Context(), jobID)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", jobID).Msg("Failed to get job")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   job,
+	})
+}
+
+// CancelJob godoc
+// @Summary Cancel job
+// @Description Cancel a queued job, or stop a running job. A running job finishes the ONU it is working on first; its remaining targets stay pending until the job is resumed.
+// @Tags Jobs
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 200 {object} utils.WebResponse{data=model.Job}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/jobs/{jobId}/cancel [post]
+func (h *JobHandler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobId")
+
+	job, err := h.jobUsecase.CancelJob(r.Context(), jobID)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", jobID).Msg("Failed to cancel job")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   job,
+	})
+}
+
+// ResumeJob godoc
+// @Summary Resume job
+// @Description Queue a failed or canceled job again. Targets that succeeded are skipped; failed and pending targets run again. A job without targets runs again as a whole.
+// @Tags Jobs
+// @Produce json
+// @Param jobId path string true "Job ID"
+// @Success 202 {object} utils.WebResponse{data=model.Job}
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/jobs/{jobId}/resume [post]
+func (h *JobHandler) ResumeJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobId")
+
+	job, err := h.jobUsecase.ResumeJob(r.Context(), jobID)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", jobID).Msg("Failed to resume job")
+		utils.HandleError(w, err)
+		return
+	}
+
+	sendJobAccepted(w, job)
+}
+
+// sendJobAccepted responds with a submitted job; the Location header points to its state
+func sendJobAccepted(w http.ResponseWriter, job *model.Job) {
+	w.Header().Set("Location", "/api/v1/jobs/"+job.ID)
+	utils.SendJSONResponse(w, http.StatusAccepted, utils.WebResponse{
+		Code:   http.StatusAccepted,
+		Status: "Accepted",
+		Data:   job,
+	})
+}
+
+// parseJobQuery reads the job filters from the query parameters
+func parseJobQuery(values url.Values) (model.JobQuery, error) {
+	query := model.JobQuery{
+		OLTID:  values.Get("olt_id"),
+		Type:   values.Get("type"),
+		Status: values.Get("status"),
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return query, apperrors.NewValidationError("limit must be a positive integer", map[string]interface{}{
+				"limit": value,
+			})
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/middleware"
+	"github.com/s4lfanet/go-api-c320/internal/model"
 	"github.com/s4lfanet/go-api-c320/internal/usecase"
 	"github.com/s4lfanet/go-api-c320/internal/utils"
 )
@@ -13,18 +15,21 @@ import (
 // MonitoringHandler handles monitoring-related HTTP requests
 type MonitoringHandler struct {
 	monitoringUsecase *usecase.MonitoringUsecase
+	jobs              usecase.JobSubmitter // Reads PON ports in the background
 }
 
 // NewMonitoringHandler creates a new MonitoringHandler instance
-func NewMonitoringHandler(monitoringUsecase *usecase.MonitoringUsecase) *MonitoringHandler {
+func NewMonitoringHandler(monitoringUsecase *usecase.MonitoringUsecase, jobs usecase.JobSubmitter) *MonitoringHandler {
 	return &MonitoringHandler{
 		monitoringUsecase: monitoringUsecase,
+		jobs:              jobs,
 	}
 }
 
 // GetONUMonitoring godoc
-// @Summary Get real-time ONU monitoring data
-// @Description Retrieves current monitoring status, statistics, and information for a specific ONU
+// @Summary Get real-time ONU monitoring data on board 1
+// @Description Retrieves current monitoring status, statistics, and information for a specific ONU on board 1.
+// @Description Use /api/v1/monitoring/board/{board_id}/pon/{pon_id}/onu/{onu_id} for other boards.
 // @Tags Monitoring
 // @Accept json
 // @Produce json
@@ -35,13 +40,38 @@ func NewMonitoringHandler(monitoringUsecase *usecase.MonitoringUsecase) *Monitor
 // @Failure 500 {object} webresponse.WebResponse "Internal server error"
 // @Router /api/v1/monitoring/onu/{pon}/{onuId} [get]
 func (h *MonitoringHandler) GetONUMonitoring(w http.ResponseWriter, r *http.Request) {
-	ponPort := chi.URLParam(r, "pon")
-	onuIDStr := chi.URLParam(r, "onuId")
-	onuID, _ := strconv.Atoi(onuIDStr)
+	ponID, _ := strconv.Atoi(chi.URLParam(r, "pon"))
+	onuID, _ := strconv.Atoi(chi.URLParam(r, "onuId"))
+
+	h.sendONUMonitoring(w, r, 1, ponID, onuID)
+}
+
+// GetBoardONUMonitoring godoc
+// @Summary Get real-time ONU monitoring data
+// @Description Retrieves current monitoring status, statistics, and information for a specific ONU on any board
+// @Tags Monitoring
+// @Accept json
+// @Produce json
+// @Param board_id path int true "Board ID (1-2)"
+// @Param pon_id path int true "PON Port Number (1-16)"
+// @Param onu_id path int true "ONU ID (1-128)"
+// @Success 200 {object} webresponse.WebResponse{data=model.ONUMonitoringInfo} "Successfully retrieved ONU monitoring data"
+// @Failure 400 {object} webresponse.WebResponse "Invalid board, PON or ONU ID"
+// @Failure 500 {object} webresponse.WebResponse "Internal server error"
+// @Router /api/v1/monitoring/board/{board_id}/pon/{pon_id}/onu/{onu_id} [get]
+func (h *MonitoringHandler) GetBoardONUMonitoring(w http.ResponseWriter, r *http.Request) {
+	boardID, _ := middleware.GetBoardID(r.Context())
+	ponID, _ := middleware.GetPonID(r.Context())
+	onuID, _ := middleware.GetOnuID(r.Context())
+
+	h.sendONUMonitoring(w, r, boardID, ponID, onuID)
+}
 
-	log.Info().Str("pon", ponPort).Int("onu_id", onuID).Msg("Getting ONU monitoring data")
+// sendONUMonitoring responds with the monitoring data of an ONU
+func (h *MonitoringHandler) sendONUMonitoring(w http.ResponseWriter, r *http.Request, boardID, ponID, onuID int) {
+	log.Info().Int("board", boardID).Int("pon", ponID).Int("onu_id", onuID).Msg("Getting ONU monitoring data")
 
-	monitoring, err := h.monitoringUsecase.GetONUMonitoring(r.Context(), ponPort, onuID)
+	monitoring, err := h.monitoringUsecase.GetONUMonitoring(r.Context(), boardID, ponID, onuID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get ONU monitoring")
 		utils.HandleError(w, err)
@@ -56,8 +86,9 @@ func (h *MonitoringHandler) GetONUMonitoring(w http.ResponseWriter, r *http.Requ
 }
 
 // GetPONMonitoring godoc
-// @Summary Get PON port monitoring data
-// @Description Retrieves aggregated monitoring data for a PON port including all ONUs
+// @Summary Get PON port monitoring data on board 1
+// @Description Retrieves aggregated monitoring data for a PON port on board 1 including all ONUs.
+// @Description Use /api/v1/monitoring/board/{board_id}/pon/{pon_id} for other boards.
 // @Tags Monitoring
 // @Accept json
 // @Produce json
@@ -67,11 +98,35 @@ func (h *MonitoringHandler) GetONUMonitoring(w http.ResponseWriter, r *http.Requ
 // @Failure 500 {object} webresponse.WebResponse "Internal server error"
 // @Router /api/v1/monitoring/pon/{pon} [get]
 func (h *MonitoringHandler) GetPONMonitoring(w http.ResponseWriter, r *http.Request) {
-	ponPort := chi.URLParam(r, "pon")
+	ponID, _ := strconv.Atoi(chi.URLParam(r, "pon"))
 
-	log.Info().Str("pon", ponPort).Msg("Getting PON monitoring data")
+	h.sendPONMonitoring(w, r, 1, ponID)
+}
 
-	monitoring, err := h.monitoringUsecase.GetPONMonitoring(r.Context(), ponPort)
+// GetBoardPONMonitoring godoc
+// @Summary Get PON port monitoring data
+// @Description Retrieves aggregated monitoring data for a PON port on any board including all ONUs
+// @Tags Monitoring
+// @Accept json
+// @Produce json
+// @Param board_id path int true "Board ID (1-2)"
+// @Param pon_id path int true "PON Port Number (1-16)"
+// @Success 200 {object} webresponse.WebResponse{data=model.PONMonitoringInfo} "Successfully retrieved PON monitoring data"
+// @Failure 400 {object} webresponse.WebResponse "Invalid board or PON ID"
+// @Failure 500 {object} webresponse.WebResponse "Internal server error"
+// @Router /api/v1/monitoring/board/{board_id}/pon/{pon_id} [get]
+func (h *MonitoringHandler) GetBoardPONMonitoring(w http.ResponseWriter, r *http.Request) {
+	boardID, _ := middleware.GetBoardID(r.Context())
+	ponID, _ := middleware.GetPonID(r.Context())
+
+	h.sendPONMonitoring(w, r, boardID, ponID)
+}
+
+// sendPONMonitoring responds with the monitoring data of a PON port
+func (h *MonitoringHandler) sendPONMonitoring(w http.ResponseWriter, r *http.Request, boardID, ponID int) {
+	log.Info().Int("board", boardID).Int("pon", ponID).Msg("Getting PON monitoring data")
+
+	monitoring, err := h.monitoringUsecase.GetPONMonitoring(r.Context(), boardID, ponID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get PON monitoring")
 		utils.HandleError(w, err)
@@ -85,9 +140,34 @@ func (h *MonitoringHandler) GetPONMonitoring(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// SubmitPONMonitoringJob godoc
+// @Summary Read PON port monitoring data in the background
+// @Description Submits a job that reads the monitoring data of all ONUs of a PON port, including their optical readings. The PONMonitoringInfo is the result of the job, see GET /api/v1/jobs/{jobId}.
+// @Tags Monitoring
+// @Produce json
+// @Param board_id path int true "Board ID (1-2)"
+// @Param pon_id path int true "PON Port Number (1-16)"
+// @Success 202 {object} webresponse.WebResponse{data=model.Job} "Job submitted"
+// @Failure 400 {object} webresponse.WebResponse "Invalid board or PON ID"
+// @Failure 500 {object} webresponse.WebResponse "Internal server error"
+// @Router /api/v1/monitoring/board/{board_id}/pon/{pon_id}/jobs [post]
+func (h *MonitoringHandler) SubmitPONMonitoringJob(w http.ResponseWriter, r *http.Request) {
+	boardID, _ := middleware.GetBoardID(r.Context())
+	ponID, _ := middleware.GetPonID(r.Context())
+
+	job, err := h.jobs.SubmitJob(r.Context(), model.JobTypePONMonitoring, &model.PONMonitoringJobRequest{Board: boardID, PON: ponID}, nil)
+	if err != nil {
+		log.Error().Err(err).Int("board", boardID).Int("pon", ponID).Msg("Failed to submit PON monitoring job")
+		utils.HandleError(w, err)
+		return
+	}
+
+	sendJobAccepted(w, job)
+}
+
 // GetOLTMonitoring godoc
 // @Summary Get OLT monitoring summary
-// @Description Retrieves overall OLT monitoring summary including all PON ports and ONUs
+// @Description Retrieves overall OLT monitoring summary including ONU counts per board and all PON ports and ONUs
 // @Tags Monitoring
 // @Accept json
 // @Produce json
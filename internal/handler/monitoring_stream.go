@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// streamHeartbeat is the interval of the comments that keep idle streams open through proxies
+const streamHeartbeat = 15 * time.Second
+
+// MonitoringStreamHandler serves the live monitoring stream of one OLT as Server-Sent Events
+type MonitoringStreamHandler struct {
+	oltID  string
+	stream usecase.MonitoringStreamUsecase
+}
+
+// NewMonitoringStreamHandler creates the stream handler of an OLT
+func NewMonitoringStreamHandler(oltID string, stream usecase.MonitoringStreamUsecase) *MonitoringStreamHandler {
+	return &MonitoringStreamHandler{oltID: oltID, stream: stream}
+}
+
+// StreamMonitoring godoc
+// @Summary Live monitoring stream
+// @Description Server-Sent Events with ONU status changes (onu_status), optical readings (onu_optical), PON counters (pon_counters), removed ONUs (onu_removed) and SNMP trap events (event) of the OLT.
+// @Description The stream starts with the last known state matching the filter. The OLT is walked once every STREAM_POLL_INTERVAL for all clients, by the collector of the OLT metrics; traps are pushed as they arrive.
+// @Tags Monitoring
+// @Produce text/event-stream
+// @Param board  query int false "Only this board"
+// @Param pon    query int false "Only this PON port (requires board)"
+// @Param onu_id query int false "Only this ONU (requires board and pon)"
+// @Success 200 {object} model.MonitoringUpdate "Stream of updates; the SSE event name is the update type"
+// @Failure 400 {object} utils.ErrorResponse
+// @Failure 403 {object} utils.ErrorResponse
+// @Failure 404 {object} utils.ErrorResponse
+// @Router /api/v1/monitoring/stream [get]
+func (h *MonitoringStreamHandler) StreamMonitoring(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStreamFilter(r.URL.Query())
+	if err != nil {
+		utils.HandleError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.HandleError(w, apperrors.NewInternalError("streaming is not supported by the connection", nil))
+		return
+	}
+
+	snapshot, updates, err := h.stream.Subscribe(r.Context(), h.oltID, filter)
+	if err != nil {
+		log.Warn().Err(err).Str("olt_id", h.oltID).Msg("Failed to subscribe to the monitoring stream")
+		utils.HandleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable response buffering of nginx
+	w.WriteHeader(http.StatusOK)
+
+	for _, update := range snapshot {
+		if err := writeStreamUpdate(w, update); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case update, ok := <-updates:
+			if !ok {
+				return // Stream stopped or the client fell behind; EventSource reconnects
+			}
+			if err := writeStreamUpdate(w, update); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeStreamUpdate writes an update as SSE event named by its type
+func writeStreamUpdate(w io.Writer, update model.MonitoringUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", update.Type, data)
+	return err
+}
+
+// parseStreamFilter reads the subscription filter from the query parameters
+func parseStreamFilter(values url.Values) (model.MonitoringStreamFilter, error) {
+	var filter model.MonitoringStreamFilter
+
+	ints := map[string]*int{
+		"board":  &filter.Board,
+		"pon":    &filter.PON,
+		"onu_id": &filter.ONUID,
+	}
+	for name, target := range ints {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return filter, apperrors.NewValidationError(fmt.Sprintf("%s must be a positive integer", name), map[string]interface{}{
+				name: value,
+			})
+		}
+		*target = parsed
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// OLTHandler handles OLT registry requests
+type OLTHandler struct {
+	oltUsecase usecase.OLTUsecaseInterface
+}
+
+// NewOLTHandler creates a new OLT handler instance
+func NewOLTHandler(oltUsecase usecase.OLTUsecaseInterface) *OLTHandler {
+	return &OLTHandler{oltUsecase: oltUsecase}
+}
+
+// ListOLTs godoc
+// @Summary      List registered OLTs
+// @Description  Get all OLTs managed by this instance. Each OLT is addressable under /api/v1/olts/{olt_id}
+// @Tags         OLT
+// @Produce      json
+// @Success      200 {object} utils.WebResponse{data=[]model.OLTInfo}
+// @Router       /api/v1/olts [get]
+func (h *OLTHandler) ListOLTs(w http.ResponseWriter, r *http.Request) {
+	olts := h.oltUsecase.ListOLTs(r.Context())
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   olts,
+	})
+}
+
+// GetOLT godoc
+// @Summary      Get OLT details
+// @Description  Get a registered OLT including its Telnet session status
+// @Tags         OLT
+// @Produce      json
+// @Param        olt_id path string true "OLT ID"
+// @Success      200 {object} utils.WebResponse{data=model.OLTInfo}
+// @Failure      404 {object} utils.ErrorResponse
+// @Router       /api/v1/olts/{olt_id} [get]
+func (h *OLTHandler) GetOLT(w http.ResponseWriter, r *http.Request) {
+	oltID := chi.URLParam(r, "olt_id")
+
+	olt, err := h.oltUsecase.GetOLT(r.Context(), oltID)
+	if err != nil {
+		log.Warn().Str("olt_id", oltID).Msg("OLT not found")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   olt,
+	})
+}
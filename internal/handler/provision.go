@@ -210,7 +210,7 @@ func (h *ProvisionHandler) DeleteONU(w http.ResponseWriter, r *http.Request) {
 		Int("onu_id", onuID).
 		Msg("Deleting ONU")
 
-	err = h.provisionUsecase.DeleteONU(ctx, ponPort, onuID)
+	snapshot, err := h.provisionUsecase.DeleteONU(ctx, ponPort, onuID)
 	if err != nil {
 		log.Error().Err(err).
 			Str("pon_port", ponPort).
@@ -231,6 +231,9 @@ func (h *ProvisionHandler) DeleteONU(w http.ResponseWriter, r *http.Request) {
 		"message":    "ONU deleted successfully",
 		"deleted_at": time.Now().Format(time.RFC3339),
 	}
+	if snapshot != nil {
+		responseData["snapshot"] = snapshot
+	}
 
 	response := utils.WebResponse{
 		Code:   http.StatusOK,
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/usecase"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// SystemHandler handles OLT system information requests
+type SystemHandler struct {
+	systemUsecase usecase.SystemUsecaseInterface
+}
+
+// NewSystemHandler creates a new system handler instance
+func NewSystemHandler(systemUsecase usecase.SystemUsecaseInterface) *SystemHandler {
+	return &SystemHandler{systemUsecase: systemUsecase}
+}
+
+// GetSystemInfo godoc
+// @Summary      Get OLT system information
+// @Description  Get the OLT model and software version detected over SNMP (sysDescr/sysObjectID) or the CLI (show version),
+// @Description  and the OID profile the OLT is read with
+// @Tags         System
+// @Produce      json
+// @Success      200 {object} utils.WebResponse{data=model.SystemInfo}
+// @Failure      500 {object} utils.ErrorResponse
+// @Router       /api/v1/system/info [get]
+func (h *SystemHandler) GetSystemInfo(w http.ResponseWriter, r *http.Request) {
+	info, err := h.systemUsecase.GetSystemInfo(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get system info")
+		utils.HandleError(w, err)
+		return
+	}
+
+	utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{
+		Code:   http.StatusOK,
+		Status: "OK",
+		Data:   info,
+	})
+}
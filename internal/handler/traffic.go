@@ -363,7 +363,7 @@ func (h *TrafficHandler) DeleteTCONT(w http.ResponseWriter, r *http.Request) {
 		Int("tcont_id", tcontID).
 		Msg("Deleting T-CONT")
 
-	err = h.trafficUsecase.DeleteTCONT(ctx, ponPort, onuID, tcontID)
+	snapshot, err := h.trafficUsecase.DeleteTCONT(ctx, ponPort, onuID, tcontID)
 	if err != nil {
 		log.Error().Err(err).
 			Str("pon_port", ponPort).
@@ -377,7 +377,7 @@ func (h *TrafficHandler) DeleteTCONT(w http.ResponseWriter, r *http.Request) {
 	response := utils.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
-		Data:   map[string]string{"message": "T-CONT deleted successfully"},
+		Data:   deleteResponseData("T-CONT deleted successfully", snapshot),
 	}
 	utils.SendJSONResponse(w, http.StatusOK, response)
 }
@@ -467,7 +467,7 @@ func (h *TrafficHandler) DeleteGEMPort(w http.ResponseWriter, r *http.Request) {
 		Int("gemport_id", gemportID).
 		Msg("Deleting GEM port")
 
-	err = h.trafficUsecase.DeleteGEMPort(ctx, ponPort, onuID, gemportID)
+	snapshot, err := h.trafficUsecase.DeleteGEMPort(ctx, ponPort, onuID, gemportID)
 	if err != nil {
 		log.Error().Err(err).
 			Str("pon_port", ponPort).
@@ -481,7 +481,16 @@ func (h *TrafficHandler) DeleteGEMPort(w http.ResponseWriter, r *http.Request) {
 	response := utils.WebResponse{
 		Code:   http.StatusOK,
 		Status: "OK",
-		Data:   map[string]string{"message": "GEM port deleted successfully"},
+		Data:   deleteResponseData("GEM port deleted successfully", snapshot),
 	}
 	utils.SendJSONResponse(w, http.StatusOK, response)
 }
+
+// deleteResponseData builds the data of a delete response, linking the pre-change snapshot if one was taken
+func deleteResponseData(message string, snapshot *model.PreChangeSnapshot) map[string]interface{} {
+	data := map[string]interface{}{"message": message}
+	if snapshot != nil {
+		data["snapshot"] = snapshot
+	}
+	return data
+}
@@ -271,7 +271,7 @@ func (h *VLANHandler) DeleteVLAN(w http.ResponseWriter, r *http.Request) {
 		Int("onu_id", onuID).
 		Msg("Deleting ONU VLAN")
 
-	err = h.vlanUsecase.DeleteVLAN(ctx, ponPort, onuID)
+	snapshot, err := h.vlanUsecase.DeleteVLAN(ctx, ponPort, onuID)
 	if err != nil {
 		log.Error().Err(err).
 			Str("pon_port", ponPort).
@@ -292,6 +292,9 @@ func (h *VLANHandler) DeleteVLAN(w http.ResponseWriter, r *http.Request) {
 		"message":    "VLAN deleted successfully",
 		"deleted_at": time.Now().Format(time.RFC3339),
 	}
+	if snapshot != nil {
+		responseData["snapshot"] = snapshot
+	}
 
 	response := utils.WebResponse{
 		Code:   http.StatusOK,
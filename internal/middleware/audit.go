@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// AuditRecorder appends entries to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, entry *model.AuditEntry) error
+}
+
+// OperationPolicy returns the audited operation of a route pattern, e.g. "onu.block" for
+// POST /onu-management/block. An empty operation means the route does not change the OLT.
+type OperationPolicy func(method, pattern string) string
+
+// maxAuditResponse limits how much of a response is kept for the error message of a failed request
+const maxAuditResponse = 64 << 10
+
+// Audit records every request to a route with an operation in the audit log of the OLT oltID: the caller,
+// the request ID, the addressed ONUs, the request body, the CLI commands sent to the OLT with their raw
+// output, the result and the duration. The route is resolved on routes, like Authorize does.
+// The entry is written after the response; a failure to write it is logged as an error.
+func Audit(routes chi.Routes, recorder AuditRecorder, policy OperationPolicy, oltID string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			routeCtx := chi.NewRouteContext()
+			pattern := routes.Find(routeCtx, r.Method, routePath(r))
+			operation := ""
+			if pattern != "" {
+				operation = policy(r.Method, pattern)
+			}
+			if operation == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				body, _ = io.ReadAll(r.Body) // A failed read is reported by the handler's own read
+				r.Body = io.NopCloser(bytes.NewReader(body))
+			}
+
+			transcript := &model.CLITranscript{}
+			response := &cappedBuffer{limit: maxAuditResponse}
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(response)
+
+			// Record the request even if the handler panics; Logger recovers from the panic
+			defer func() {
+				recovered := recover()
+				status := ww.Status()
+				switch {
+				case recovered != nil:
+					status = http.StatusInternalServerError
+				case status == 0:
+					status = http.StatusOK // Nothing written: net/http sends 200
+				}
+				recordAudit(r, recorder, &model.AuditEntry{
+					Timestamp:  start,
+					OLTID:      oltID,
+					Operation:  operation,
+					StatusCode: status,
+					DurationMs: time.Since(start).Milliseconds(),
+					Commands:   transcript.Commands(),
+				}, routeCtx, body, response.Bytes())
+				if recovered != nil {
+					panic(recovered)
+				}
+			}()
+
+			next.ServeHTTP(ww, r.WithContext(model.ContextWithCLITranscript(r.Context(), transcript)))
+		})
+	}
+}
+
+// recordAudit completes the entry of a served request and writes it
+func recordAudit(r *http.Request, recorder AuditRecorder, entry *model.AuditEntry, routeCtx *chi.Context, body, response []byte) {
+	entry.User = model.AuditAnonymous
+	entry.RequestID = GetRequestID(r.Context())
+	entry.Method = r.Method
+	entry.Path = r.URL.Path
+	entry.Params = routeParams(routeCtx)
+	if principal, ok := GetPrincipal(r.Context()); ok {
+		entry.User = principal.Identity()
+	}
+	if compact := new(bytes.Buffer); json.Compact(compact, body) == nil {
+		entry.Request = compact.Bytes()
+	}
+	entry.Targets = auditTargets(routeCtx, body, entry.Commands)
+
+	entry.Result = model.AuditResultSuccess
+	if entry.StatusCode < 200 || entry.StatusCode > 299 {
+		entry.Result = model.AuditResultFailure
+		entry.Error = responseMessage(response, entry.StatusCode)
+	}
+
+	// The client may be gone by now; the entry must still be written
+	if err := recorder.Record(context.WithoutCancel(r.Context()), entry); err != nil {
+		log.Error().Err(err).
+			Str("request_id", entry.RequestID).
+			Str("user", entry.User).
+			Str("operation", entry.Operation).
+			Msg("Failed to write audit log entry")
+	}
+}
+
+// routeParams returns the URL parameters of a resolved route
+func routeParams(routeCtx *chi.Context) map[string]string {
+	var params map[string]string
+	for i, key := range routeCtx.URLParams.Keys {
+		if key == "*" {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[key] = routeCtx.URLParams.Values[i]
+	}
+	return params
+}
+
+// auditBody holds the fields of request bodies that address ONUs
+type auditBody struct {
+	PONPort     string `json:"pon_port"`
+	ONUID       int    `json:"onu_id"`
+	TargetPON   string `json:"target_pon"`    // Restore
+	TargetONUID int    `json:"target_onu_id"` // Restore
+	Targets     []struct {
+		PONPort string `json:"pon_port"`
+		ONUID   int    `json:"onu_id"`
+	} `json:"targets"`
+}
+
+// auditTargets returns the ONUs addressed by the URL parameters and the JSON body of a request,
+// and the ONU interfaces of the configuration commands it sent (e.g. the ONUs of a restore)
+func auditTargets(routeCtx *chi.Context, body []byte, commands []model.CLICommand) []model.AuditTarget {
+	var targets []model.AuditTarget
+	seen := make(map[model.AuditTarget]bool)
+	addTarget := func(target model.AuditTarget) {
+		if !seen[target] {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+	add := func(ponPort string, onuID int) {
+		if ponPort == "" {
+			return
+		}
+		if pon, ok := parsePONTarget(ponPort); ok {
+			addTarget(model.AuditTarget{Board: pon.Board, PON: pon.PON, ONUID: onuID})
+		}
+	}
+
+	onuID, _ := strconv.Atoi(routeCtx.URLParam("onu_id"))
+	add(routeCtx.URLParam("pon"), onuID)
+
+	var b auditBody
+	if json.Unmarshal(body, &b) == nil {
+		add(b.PONPort, b.ONUID)
+		add(b.TargetPON, b.TargetONUID)
+		for _, t := range b.Targets {
+			add(t.PONPort, t.ONUID)
+		}
+	}
+
+	for _, target := range model.CommandTargets(commands) {
+		addTarget(target)
+	}
+
+	return targets
+}
+
+// responseMessage returns the message of an error response, or the status text
+func responseMessage(body []byte, status int) string {
+	var resp struct {
+		Message json.RawMessage `json:"message"`
+	}
+	if json.Unmarshal(body, &resp) == nil && len(resp.Message) > 0 {
+		var message string
+		if json.Unmarshal(resp.Message, &message) == nil {
+			return message
+		}
+		return string(resp.Message)
+	}
+	return http.StatusText(status)
+}
+
+// cappedBuffer keeps the first limit bytes written to it and discards the rest
+type cappedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+// Write never fails, so the response is not affected
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
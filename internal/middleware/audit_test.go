@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// fakeAuditRecorder keeps the recorded entries
+type fakeAuditRecorder struct {
+	mu      sync.Mutex
+	entries []*model.AuditEntry
+}
+
+func (f *fakeAuditRecorder) Record(_ context.Context, entry *model.AuditEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries = append(f.entries, entry)
+	return nil
+}
+
+// newAuditRouter returns a router with RequestID, Authenticate and Audit and routes that send CLI commands
+func newAuditRouter(recorder AuditRecorder) http.Handler {
+	policy := func(method, pattern string) string {
+		switch method + " " + pattern {
+		case "POST /onu-management/block":
+			return "onu.block"
+		case "DELETE /vlan/onu/{pon}/{onu_id}":
+			return "vlan.delete"
+		case "POST /config/restore/{backupId}":
+			return "config.restore"
+		}
+		return ""
+	}
+	sendCommands := func(r *http.Request, commands ...model.CLICommand) {
+		model.CLITranscriptFromContext(r.Context()).Add(commands...)
+	}
+
+	r := chi.NewRouter()
+	r.Use(RequestID)
+	r.Use(Authenticate(staticAuthenticator{"tech": {ID: "2b7c", Name: "alice", Method: model.AuthMethodOIDC}}))
+	r.Use(Audit(r, recorder, policy, "core-1"))
+
+	r.Get("/onu/unconfigured", func(w http.ResponseWriter, r *http.Request) {
+		sendCommands(r, model.CLICommand{Command: "show gpon onu uncfg", Mode: model.CLIModeExec, Success: true})
+		w.WriteHeader(http.StatusOK)
+	})
+	r.Post("/onu-management/block", func(w http.ResponseWriter, r *http.Request) {
+		sendCommands(r,
+			model.CLICommand{Command: "interface gpon-olt_1/1/3", Mode: model.CLIModeConfig, Success: true},
+			model.CLICommand{Command: "onu 7 state disable", Mode: model.CLIModeConfig, Output: "onu 7 state disable\n", Success: true})
+		utils.SendJSONResponse(w, http.StatusOK, utils.WebResponse{Code: http.StatusOK, Status: "OK"})
+	})
+	r.Delete("/vlan/onu/{pon}/{onu_id}", func(w http.ResponseWriter, r *http.Request) {
+		sendCommands(r, model.CLICommand{Command: "no service-port 12", Mode: model.CLIModeConfig,
+			Output: "%Error 20209: The ONU does not exist.", Error: "%Error 20209: The ONU does not exist."})
+		utils.HandleError(w, apperrors.NewNotFoundError("ONU", "1/1/2:5"))
+	})
+	r.Post("/config/restore/{backupId}", func(w http.ResponseWriter, r *http.Request) {
+		sendCommands(r, model.CLICommand{Command: "interface gpon-onu_1/2/4:9", Mode: model.CLIModeConfig, Success: true})
+		panic("restore failed")
+	})
+	return r
+}
+
+func TestAudit(t *testing.T) {
+	recorder := &fakeAuditRecorder{}
+	router := newAuditRouter(recorder)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", "tech")
+		req.Header.Set("X-Request-ID", "req-"+strings.ToLower(method))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		return rr
+	}
+
+	// Routes without an operation are not audited
+	serve("GET", "/onu/unconfigured", "")
+	if len(recorder.entries) != 0 {
+		t.Fatalf("GET was audited: %+v", recorder.entries[0])
+	}
+
+	// The handler reads the body the middleware has read
+	if rr := serve("POST", "/onu-management/block", `{"pon_port": "1/1/3", "onu_id": 7}`); rr.Code != http.StatusOK {
+		t.Fatalf("block status = %d", rr.Code)
+	}
+	if len(recorder.entries) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(recorder.entries))
+	}
+	block := recorder.entries[0]
+	if block.User != "alice" || block.RequestID != "req-post" || block.OLTID != "core-1" || block.Operation != "onu.block" {
+		t.Errorf("entry = %+v", block)
+	}
+	if block.Result != model.AuditResultSuccess || block.StatusCode != http.StatusOK || block.Error != "" {
+		t.Errorf("result = %s %d %q, want success", block.Result, block.StatusCode, block.Error)
+	}
+	if len(block.Commands) != 2 || block.Commands[1].Output != "onu 7 state disable\n" {
+		t.Errorf("commands = %+v", block.Commands)
+	}
+	if string(block.Request) != `{"pon_port":"1/1/3","onu_id":7}` {
+		t.Errorf("request = %s", block.Request)
+	}
+	if len(block.Targets) != 1 || block.Targets[0] != (model.AuditTarget{Board: 1, PON: 3, ONUID: 7}) {
+		t.Errorf("targets = %+v", block.Targets)
+	}
+
+	// Failed requests keep the error message and the OLT output
+	serve("DELETE", "/vlan/onu/1%2F1%2F2/5", "")
+	failed := recorder.entries[1]
+	if failed.Result != model.AuditResultFailure || failed.StatusCode != http.StatusNotFound || !strings.Contains(failed.Error, "not found") {
+		t.Errorf("result = %s %d %q, want not found failure", failed.Result, failed.StatusCode, failed.Error)
+	}
+	if failed.Params["pon"] != "1%2F1%2F2" || failed.Params["onu_id"] != "5" {
+		t.Errorf("params = %v", failed.Params)
+	}
+	if len(failed.Targets) != 1 || failed.Targets[0] != (model.AuditTarget{Board: 1, PON: 2, ONUID: 5}) {
+		t.Errorf("targets = %+v", failed.Targets)
+	}
+
+	// A panicking handler is recorded before the panic goes on to Logger
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic was swallowed")
+			}
+		}()
+		serve("POST", "/config/restore/backup-1", `{"overwrite": true}`)
+	}()
+	if len(recorder.entries) != 3 {
+		t.Fatalf("recorded %d entries, want 3", len(recorder.entries))
+	}
+	restore := recorder.entries[2]
+	if restore.StatusCode != http.StatusInternalServerError || restore.Result != model.AuditResultFailure {
+		t.Errorf("result = %s %d, want failure 500", restore.Result, restore.StatusCode)
+	}
+	if len(restore.Targets) != 1 || restore.Targets[0] != (model.AuditTarget{Board: 2, PON: 4, ONUID: 9}) {
+		t.Errorf("targets from commands = %+v", restore.Targets)
+	}
+}
+
+func TestAudit_Anonymous(t *testing.T) {
+	recorder := &fakeAuditRecorder{}
+	r := chi.NewRouter()
+	r.Use(Audit(r, recorder, func(string, string) string { return "onu.reboot" }, "default"))
+	r.Post("/onu-management/reboot", func(w http.ResponseWriter, r *http.Request) {})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/onu-management/reboot", strings.NewReader("not json")))
+
+	if len(recorder.entries) != 1 || recorder.entries[0].User != model.AuditAnonymous ||
+		recorder.entries[0].Request != nil || recorder.entries[0].Result != model.AuditResultSuccess {
+		t.Errorf("entries = %+v, want one successful anonymous entry without request body", recorder.entries)
+	}
+}
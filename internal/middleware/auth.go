@@ -0,0 +1,258 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// APIKeyHeader is the request header carrying the API key; "Authorization: Bearer <key or token>" is accepted as well
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator resolves the caller of a request from its API key or bearer token
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*model.Principal, error)
+}
+
+// ScopePolicy returns the scope required for a route pattern, e.g. "/batch/delete".
+// An empty scope skips authorization, for routes that are authorized by a mounted router.
+type ScopePolicy func(method, pattern string) string
+
+// GetPrincipal retrieves the authenticated caller from request context
+func GetPrincipal(ctx context.Context) (*model.Principal, bool) {
+	return model.PrincipalFromContext(ctx)
+}
+
+// Authenticate rejects requests without a valid API key or bearer token with 401 Unauthorized,
+// stores the caller in the request context and reports its identity to Logger.
+func Authenticate(auth Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := requestCredential(r)
+			if credential == "" {
+				unauthorized(w, apperrors.NewUnauthorizedError("API key or bearer token is required"))
+				return
+			}
+
+			principal, err := auth.Authenticate(r.Context(), credential)
+			if err != nil {
+				unauthorized(w, err)
+				return
+			}
+
+			setRequestUser(r, principal.Identity())
+			next.ServeHTTP(w, r.WithContext(model.ContextWithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Authorize rejects requests with 403 Forbidden if the caller lacks the scope of the route,
+// or is restricted to boards/PONs and the request addresses another PON port or none at all.
+// The route is resolved on routes, so authorization runs before the handler's own middlewares.
+// Requests without a principal (authentication disabled) are passed through.
+func Authorize(routes chi.Routes, policy ScopePolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := GetPrincipal(r.Context())
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			routeCtx := chi.NewRouteContext()
+			pattern := routes.Find(routeCtx, r.Method, routePath(r))
+			if pattern == "" {
+				next.ServeHTTP(w, r) // Unknown route: let the router answer 404/405
+				return
+			}
+
+			scope := policy(r.Method, pattern)
+			if scope == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !principal.HasScope(scope) {
+				utils.HandleError(w, apperrors.NewForbiddenError("caller lacks the required scope", map[string]interface{}{
+					"principal":      principal.Identity(),
+					"required_scope": scope,
+				}))
+				return
+			}
+
+			if principal.Restricted() {
+				targets, err := requestTargets(r, routeCtx)
+				if err != nil {
+					utils.HandleError(w, err)
+					return
+				}
+				if err := checkTargets(principal, targets); err != nil {
+					utils.HandleError(w, err)
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requestCredential returns the API key of the X-API-Key header, or the key or token of the Authorization header
+func requestCredential(r *http.Request) string {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// unauthorized sends a 401 response with a WWW-Authenticate challenge; other errors are sent as they are
+func unauthorized(w http.ResponseWriter, err error) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="go-api-c320"`)
+	}
+	utils.HandleError(w, err)
+}
+
+// routePath returns the path of the request relative to the router the middleware is used on
+func routePath(r *http.Request) string {
+	if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePath != "" {
+		return rctx.RoutePath
+	}
+	if r.URL.RawPath != "" {
+		return r.URL.RawPath
+	}
+	return r.URL.Path
+}
+
+// targetBody holds the fields of request bodies that address PON ports
+type targetBody struct {
+	PONPort string `json:"pon_port"`
+	Targets []struct {
+		PONPort string `json:"pon_port"`
+	} `json:"targets"`
+}
+
+// requestTargets returns the PON ports addressed by the URL parameters, the board and pon query
+// parameters and the JSON body of a request. Board-level routes and filters yield a target with PON 0.
+// The body is restored for the handler.
+func requestTargets(r *http.Request, routeCtx *chi.Context) ([]model.BoardPON, error) {
+	var targets []model.BoardPON
+
+	if board := routeCtx.URLParam("board_id"); board != "" {
+		boardID, _ := strconv.Atoi(board)
+		ponID, _ := strconv.Atoi(routeCtx.URLParam("pon_id"))
+		targets = append(targets, model.BoardPON{Board: boardID, PON: ponID})
+	}
+	if pon := routeCtx.URLParam("pon"); pon != "" {
+		target, ok := parsePONTarget(pon)
+		if !ok {
+			return nil, apperrors.NewValidationError("invalid PON port format", map[string]interface{}{"pon": pon})
+		}
+		targets = append(targets, target)
+	}
+	// Filters of /events and /monitoring/stream; without a board they address the whole OLT
+	if query := r.URL.Query(); query.Get("board") != "" {
+		boardID, err := strconv.Atoi(query.Get("board"))
+		if err != nil {
+			return nil, apperrors.NewValidationError("board must be a positive integer", map[string]interface{}{"board": query.Get("board")})
+		}
+		ponID, _ := strconv.Atoi(query.Get("pon"))
+		targets = append(targets, model.BoardPON{Board: boardID, PON: ponID})
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return targets, nil
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apperrors.NewValidationError("failed to read request body", map[string]interface{}{"error": err.Error()})
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+
+	var body targetBody
+	if err := json.Unmarshal(data, &body); err != nil {
+		return targets, nil // Not a JSON object: the handler reports the invalid body
+	}
+	ponPorts := []string{body.PONPort}
+	for _, t := range body.Targets {
+		ponPorts = append(ponPorts, t.PONPort)
+	}
+	for _, ponPort := range ponPorts {
+		if ponPort == "" {
+			continue
+		}
+		target, ok := parsePONTarget(ponPort)
+		if !ok {
+			return nil, apperrors.NewValidationError("invalid PON port format", map[string]interface{}{"pon_port": ponPort})
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// parsePONTarget parses a PON port as "rack/board/pon", "board/pon" or "pon" (board 1, as on the legacy routes)
+func parsePONTarget(ponPort string) (model.BoardPON, bool) {
+	if unescaped, err := url.PathUnescape(ponPort); err == nil {
+		ponPort = unescaped
+	}
+
+	parts := strings.Split(strings.TrimPrefix(ponPort, "gpon-olt_"), "/")
+	if len(parts) > 3 {
+		return model.BoardPON{}, false
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return model.BoardPON{}, false
+		}
+		numbers[i] = n
+	}
+
+	switch len(numbers) {
+	case 1:
+		return model.BoardPON{Board: 1, PON: numbers[0]}, true
+	case 2:
+		return model.BoardPON{Board: numbers[0], PON: numbers[1]}, true
+	default:
+		return model.BoardPON{Board: numbers[1], PON: numbers[2]}, true
+	}
+}
+
+// checkTargets returns a forbidden error unless the request addresses PON ports and the principal may access all of them
+func checkTargets(principal *model.Principal, targets []model.BoardPON) error {
+	if len(targets) == 0 {
+		return apperrors.NewForbiddenError("API key is restricted to boards/PONs and the request does not address a PON port", map[string]interface{}{
+			"key_id": principal.ID,
+		})
+	}
+
+	for _, target := range targets {
+		if !principal.AllowsPON(target.Board, target.PON) {
+			return apperrors.NewForbiddenError("API key is not allowed on this board/PON", map[string]interface{}{
+				"key_id": principal.ID,
+				"board":  target.Board,
+				"pon":    target.PON,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// staticAuthenticator accepts the keys of a map
+type staticAuthenticator map[string]*model.Principal
+
+func (a staticAuthenticator) Authenticate(_ context.Context, key string) (*model.Principal, error) {
+	if principal, ok := a[key]; ok {
+		return principal, nil
+	}
+	return nil, apperrors.NewUnauthorizedError("invalid API key")
+}
+
+// newAuthRouter returns a router with Authenticate and Authorize and a few routes shaped like the API
+func newAuthRouter() http.Handler {
+	auth := staticAuthenticator{
+		"admin":    {ID: "admin", Scopes: model.Scopes},
+		"reader":   {ID: "reader", Scopes: []string{model.ScopeReadONU}},
+		"pon-2-4":  {ID: "pon-2-4", Scopes: []string{model.ScopeReadONU, model.ScopeWriteONU, model.ScopeBatch}, PONs: []model.BoardPON{{Board: 2, PON: 4}}},
+		"board-1":  {ID: "board-1", Scopes: []string{model.ScopeReadONU, model.ScopeWriteONU}, Boards: []int{1}},
+		"no-batch": {ID: "no-batch", Scopes: []string{model.ScopeWriteONU}},
+	}
+	policy := func(method, pattern string) string {
+		switch {
+		case strings.HasPrefix(pattern, "/batch/"):
+			return model.ScopeBatch
+		case method == http.MethodGet:
+			return model.ScopeReadONU
+		default:
+			return model.ScopeWriteONU
+		}
+	}
+
+	r := chi.NewRouter()
+	r.Use(Authenticate(auth))
+	r.Use(Authorize(r, policy))
+
+	ok := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}
+	r.Route("/board/{board_id}/pon/{pon_id}", func(r chi.Router) {
+		r.Use(ValidateBoardPonParams)
+		r.Get("/", ok)
+	})
+	r.Get("/onu/unconfigured", ok)
+	r.Get("/monitoring/stream", ok)
+	r.Delete("/onu/{pon}/{onu_id}", ok)
+	r.Post("/onu-management/reboot", ok)
+	r.Post("/batch/delete", ok)
+	return r
+}
+
+func TestAuthenticate(t *testing.T) {
+	router := newAuthRouter()
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   int
+	}{
+		{"no key", "", "", http.StatusUnauthorized},
+		{"invalid key", "X-API-Key", "guess", http.StatusUnauthorized},
+		{"X-API-Key", "X-API-Key", "reader", http.StatusOK},
+		{"bearer", "Authorization", "Bearer reader", http.StatusOK},
+		{"basic", "Authorization", "Basic reader", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/onu/unconfigured", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("WWW-Authenticate header missing on 401")
+			}
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	router := newAuthRouter()
+
+	tests := []struct {
+		name   string
+		key    string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"admin batch delete", "admin", http.MethodPost, "/batch/delete", `{"targets":[{"pon_port":"1/1/1","onu_id":1}]}`, http.StatusOK},
+		{"reader cannot write", "reader", http.MethodPost, "/onu-management/reboot", `{"pon_port":"1/1/1","onu_id":1}`, http.StatusForbidden},
+		{"missing batch scope", "no-batch", http.MethodPost, "/batch/delete", `{"targets":[]}`, http.StatusForbidden},
+		{"restricted own pon", "pon-2-4", http.MethodGet, "/board/2/pon/4/", "", http.StatusOK},
+		{"restricted other pon", "pon-2-4", http.MethodGet, "/board/2/pon/5/", "", http.StatusForbidden},
+		{"restricted body", "pon-2-4", http.MethodPost, "/onu-management/reboot", `{"pon_port":"1/2/4","onu_id":1}`, http.StatusOK},
+		{"restricted body other pon", "pon-2-4", http.MethodPost, "/onu-management/reboot", `{"pon_port":"1/1/4","onu_id":1}`, http.StatusForbidden},
+		{"restricted batch mixed", "pon-2-4", http.MethodPost, "/batch/delete", `{"targets":[{"pon_port":"1/2/4"},{"pon_port":"1/1/1"}]}`, http.StatusForbidden},
+		{"restricted path pon", "pon-2-4", http.MethodDelete, "/onu/1%2F2%2F4/3", "", http.StatusOK},
+		{"restricted OLT-wide route", "pon-2-4", http.MethodGet, "/onu/unconfigured", "", http.StatusForbidden},
+		{"board restriction", "board-1", http.MethodGet, "/board/1/pon/9/", "", http.StatusOK},
+		{"board restriction other board", "board-1", http.MethodGet, "/board/2/pon/9/", "", http.StatusForbidden},
+		{"restricted stream own pon", "pon-2-4", http.MethodGet, "/monitoring/stream?board=2&pon=4", "", http.StatusOK},
+		{"restricted stream board", "pon-2-4", http.MethodGet, "/monitoring/stream?board=2", "", http.StatusForbidden},
+		{"restricted stream whole OLT", "pon-2-4", http.MethodGet, "/monitoring/stream", "", http.StatusForbidden},
+		{"board restriction stream", "board-1", http.MethodGet, "/monitoring/stream?board=1", "", http.StatusOK},
+		{"restricted stream invalid board", "board-1", http.MethodGet, "/monitoring/stream?board=x", "", http.StatusBadRequest},
+		{"unknown route", "pon-2-4", http.MethodGet, "/nothing", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("X-API-Key", tt.key)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d, want %d (%s)", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.want == http.StatusOK && rr.Body.String() != tt.body {
+				t.Errorf("handler body = %q, want the request body %q", rr.Body.String(), tt.body)
+			}
+		})
+	}
+}
+
+func TestAuthorize_WithoutPrincipal(t *testing.T) {
+	r := chi.NewRouter()
+	r.Use(Authorize(r, func(string, string) string { return model.ScopeAdminConfig }))
+	r.Get("/", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rr.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 with authentication disabled", rr.Code)
+	}
+}
+
+func TestParsePONTarget(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   model.BoardPON
+		wantOK bool
+	}{
+		{"1/2/4", model.BoardPON{Board: 2, PON: 4}, true},
+		{"1%2F2%2F4", model.BoardPON{Board: 2, PON: 4}, true},
+		{"gpon-olt_1/1/16", model.BoardPON{Board: 1, PON: 16}, true},
+		{"2/3", model.BoardPON{Board: 2, PON: 3}, true},
+		{"7", model.BoardPON{Board: 1, PON: 7}, true},
+		{"1/1/1/1", model.BoardPON{}, false},
+		{"one", model.BoardPON{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := parsePONTarget(tt.input)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parsePONTarget(%q) = %+v, %v, want %+v, %v", tt.input, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
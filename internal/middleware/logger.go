@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"runtime/debug"
 	"time"
@@ -12,12 +13,15 @@ import (
 // Logger is a middleware function that logs incoming HTTP requests and their details
 // using the provided zerolog.Logger instance. It captures information such as request
 // time, remote address, request path, protocol, method, user agent, response status,
-// bytes in/out, and elapsed time. It also handles panics and logs them as errors
+// bytes in/out, and elapsed time. It also handles panics and logs them as errors.
+// The identity of the caller is logged as "user" once Authenticate has resolved it.
 func Logger(logger zerolog.Logger) func(next http.Handler) http.Handler { // Function to return Logger middleware handler
 	return func(next http.Handler) http.Handler { // Return the actual middleware function
 		fn := func(w http.ResponseWriter, r *http.Request) { // Define the handler function
-			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor) // Wrap the response writer to capture status and size
-			startTime := time.Now()                                 // Record start time of request
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)                   // Wrap the response writer to capture status and size
+			startTime := time.Now()                                                   // Record start time of request
+			user := &requestUser{}                                                    // Caller identity, filled in by Authenticate
+			r = r.WithContext(context.WithValue(r.Context(), requestUserKey{}, user)) // Pass the holder down the chain
 
 			defer func() { // Defer logging execution until after the request is processed
 				endTime := time.Now()                 // Record end time
@@ -29,7 +33,7 @@ func Logger(logger zerolog.Logger) func(next http.Handler) http.Handler { // Fun
 				}
 
 				// Log request details using structured logging
-				logger.Info().Fields(map[string]interface{}{
+				fields := map[string]interface{}{
 					"time":         startTime.Format(time.RFC3339), // Format start time as RFC3339
 					"remote_addr":  r.RemoteAddr,                   // Remote IP address
 					"path":         r.URL.Path,                     // Request path
@@ -41,7 +45,11 @@ func Logger(logger zerolog.Logger) func(next http.Handler) http.Handler { // Fun
 					"bytes_in":     r.ContentLength,                // Request content length
 					"bytes_out":    ww.BytesWritten(),              // Response body size
 					"elapsed_time": elapsedTime.String(),           // Processing duration as a string
-				}).Msg("incoming_request") // Log message
+				}
+				if user.identity != "" {
+					fields["user"] = user.identity // Authenticated caller
+				}
+				logger.Info().Fields(fields).Msg("incoming_request") // Log message
 			}()
 
 			next.ServeHTTP(ww, r) // Serve the request using the wrapped response writer
@@ -50,3 +58,19 @@ func Logger(logger zerolog.Logger) func(next http.Handler) http.Handler { // Fun
 		return http.HandlerFunc(fn) // Return the handler function
 	}
 }
+
+// requestUserKey is the context key for the requestUser of a request
+type requestUserKey struct{}
+
+// requestUser carries the identity of the caller from Authenticate back to Logger,
+// which runs before authentication and cannot see the context it adds
+type requestUser struct {
+	identity string
+}
+
+// setRequestUser records the identity of the caller for the request log
+func setRequestUser(r *http.Request, identity string) {
+	if user, ok := r.Context().Value(requestUserKey{}).(*requestUser); ok {
+		user.identity = identity
+	}
+}
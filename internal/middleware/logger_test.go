@@ -235,3 +235,39 @@ func TestLogger_BytesInOut(t *testing.T) {
 		t.Error("Expected 'bytes_out' field in log")
 	}
 }
+
+func TestLogger_AuthenticatedUser(t *testing.T) {
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+
+	auth := staticAuthenticator{"token": {ID: "2b7c", Name: "alice", Method: "oidc", Scopes: []string{"read:onu"}}}
+	handler := Logger(logger)(Authenticate(auth)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	tests := []struct {
+		name  string
+		token string
+		want  interface{}
+	}{
+		{"authenticated", "token", "alice"},
+		{"rejected", "guess", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest("GET", "/api/v1/board/1/pon/1", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			var logEntry map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &logEntry); err != nil {
+				t.Fatalf("Failed to parse log as JSON: %v", err)
+			}
+			if logEntry["user"] != tt.want {
+				t.Errorf("Expected user %v, got %v", tt.want, logEntry["user"])
+			}
+		})
+	}
+}
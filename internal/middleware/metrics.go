@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/s4lfanet/go-api-c320/pkg/metrics"
+)
+
+// httpRequestDuration is the request latency by route pattern, exported on /metrics
+var httpRequestDuration = metrics.NewHistogramVec("c320_http_request_duration_seconds",
+	"Latency of API requests by method, route and status code", nil, "method", "route", "status")
+
+// Metrics records the latency of every request by its route pattern (e.g. /api/v1/board/{board_id}/pon/{pon_id}/),
+// so the cardinality does not depend on the requested IDs. Unmatched requests share the route "unmatched".
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		httpRequestDuration.Observe(time.Since(start).Seconds(), r.Method, route, strconv.Itoa(ww.Status()))
+	})
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestMetrics_RecordsRoutePattern(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Metrics)
+	router.Get("/board/{board_id}/pon/{pon_id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	for _, path := range []string{"/board/1/pon/1", "/board/2/pon/7", "/nope"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	counts := make(map[string]float64)
+	for _, family := range httpRequestDuration.Collect() {
+		for _, sample := range family.Samples {
+			if sample.Suffix == "_count" {
+				counts[sample.Labels["route"]+" "+sample.Labels["status"]] = sample.Value
+			}
+		}
+	}
+
+	if got := counts["/board/{board_id}/pon/{pon_id} 418"]; got != 2 {
+		t.Errorf("requests of route pattern = %v, want 2 (counts %v)", got, counts)
+	}
+	if got := counts["unmatched 404"]; got != 1 {
+		t.Errorf("unmatched requests = %v, want 1 (counts %v)", got, counts)
+	}
+}
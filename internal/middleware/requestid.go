@@ -5,15 +5,11 @@ import (
 	"net/http"
 
 	"github.com/rs/xid"
+	"github.com/s4lfanet/go-api-c320/internal/model"
 )
 
-// requestIDKey is the context key for request ID
-type requestIDKey string
-
-const (
-	// RequestIDKey is the context key for the request ID
-	RequestIDKey requestIDKey = "requestID"
-)
+// RequestIDKey is the context key for the request ID; usecases read it with model.RequestIDFromContext
+const RequestIDKey = model.RequestIDKey
 
 // RequestID adds a unique request ID to each request for tracking and debugging
 // The request ID can be provided by the client via X-Request-ID header,
@@ -38,3 +34,8 @@ func RequestID(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// GetRequestID returns the request ID stored by RequestID, or an empty string
+func GetRequestID(ctx context.Context) string {
+	return model.RequestIDFromContext(ctx)
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -34,9 +35,17 @@ func SecurityHeaders(next http.Handler) http.Handler {
 
 // RequestTimeout adds a timeout context to requests,
 // ensuring they do not run indefinitely.
-func RequestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+// Requests whose path ends with one of exempt (long-lived streams) are not limited.
+func RequestTimeout(timeout time.Duration, exempt ...string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, suffix := range exempt {
+				if strings.HasSuffix(r.URL.Path, suffix) {
+					next.ServeHTTP(w, r) // Ends when the client disconnects
+					return
+				}
+			}
+
 			ctx, cancel := context.WithTimeout(r.Context(), timeout) // Create context with timeout
 			defer cancel()                                           // Ensure the cancellation function is called to release resources
 
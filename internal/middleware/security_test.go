@@ -89,6 +89,25 @@ func TestRequestTimeout(t *testing.T) {
 			t.Error("Context should have a deadline")
 		}
 	})
+	// Test that exempt paths (streams) have no deadline
+	t.Run("Exempt path has no timeout", func(t *testing.T) {
+		var contextHasDeadline bool
+		testHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, contextHasDeadline = r.Context().Deadline()
+			w.WriteHeader(http.StatusOK)
+		})
+
+		handler := RequestTimeout(1*time.Second, "/monitoring/stream")(testHandler)
+
+		req := httptest.NewRequest("GET", "/api/v1/olts/core-1/monitoring/stream", nil)
+		rr := httptest.NewRecorder()
+
+		handler.ServeHTTP(rr, req)
+
+		if contextHasDeadline {
+			t.Error("Context of an exempt path should not have a deadline")
+		}
+	})
 }
 
 func TestRateLimiter(t *testing.T) {
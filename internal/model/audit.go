@@ -0,0 +1,168 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Results of audited operations
+const (
+	AuditResultSuccess = "success" // The API answered with a 2xx status
+	AuditResultFailure = "failure" // The request was rejected or failed, possibly after some commands were applied
+)
+
+// AuditAnonymous is the user of operations performed while authentication is disabled
+const AuditAnonymous = "anonymous"
+
+// AuditEntry records an API request, or a run of a background job, that changes an OLT. Entries are
+// hash-chained: Hash covers all other fields including PrevHash, the hash of the previous entry, so an
+// edited, inserted or removed entry breaks the chain from that point on.
+type AuditEntry struct {
+	Sequence   int64             `json:"sequence"`              // Position in the log, starting at 1
+	Timestamp  time.Time         `json:"timestamp"`             // When the request was received (UTC)
+	User       string            `json:"user"`                  // Identity of the caller, AuditAnonymous without authentication
+	RequestID  string            `json:"request_id"`            // X-Request-ID of the request
+	OLTID      string            `json:"olt_id"`                // OLT the request was sent to
+	Operation  string            `json:"operation"`             // Operation name, e.g. "onu.block" or "batch.delete"
+	JobID      string            `json:"job_id,omitempty"`      // Job of a run of a background job
+	Method     string            `json:"method,omitempty"`      // HTTP method, empty for job runs
+	Path       string            `json:"path,omitempty"`        // Request path, empty for job runs
+	Params     map[string]string `json:"params,omitempty"`      // URL parameters, e.g. pon and onu_id
+	Request    json.RawMessage   `json:"request,omitempty"`     // JSON body of the request
+	Targets    []AuditTarget     `json:"targets,omitempty"`     // ONUs addressed by the request or its CLI commands
+	Commands   []CLICommand      `json:"commands"`              // CLI commands sent to the OLT, in order
+	Result     string            `json:"result"`                // AuditResultSuccess or AuditResultFailure
+	StatusCode int               `json:"status_code,omitempty"` // HTTP status of the response, 0 for job runs
+	Error      string            `json:"error,omitempty"`       // Error message of a failed request
+	DurationMs int64             `json:"duration_ms"`           // Time taken by the request
+	HashAlg    string            `json:"hash_alg,omitempty"`    // AuditHashHMAC if Hash is keyed, empty for plain SHA-256
+	PrevHash   string            `json:"prev_hash"`             // Hash of the previous entry, empty for the first one
+	Hash       string            `json:"hash"`                  // HMAC-SHA256 or SHA-256 (hex) of the entry without this field
+}
+
+// AuditHashHMAC marks entries hashed with the HMAC-SHA256 of AUDIT_HMAC_KEY. Without the key a
+// rewritten log cannot be chained again, so the log stays tamper-evident across restarts.
+const AuditHashHMAC = "hmac-sha256"
+
+// AuditTarget is an ONU, or with ONUID 0 a PON port, changed by an audited operation
+type AuditTarget struct {
+	Board int `json:"board"`
+	PON   int `json:"pon"`
+	ONUID int `json:"onu_id,omitempty"`
+}
+
+// AuditQuery filters audit entries; zero values match everything
+type AuditQuery struct {
+	OLTID     string    // Only entries of this OLT
+	User      string    // Only entries of this user
+	Operation string    // Only this operation ("onu.block"), or all operations of a group ("onu")
+	Board     int       // Only entries targeting this board
+	PON       int       // Only entries targeting this PON port
+	ONUID     int       // Only entries targeting this ONU
+	Since     time.Time // Only entries at or after this time
+	Until     time.Time // Only entries before this time
+	Limit     int       // Maximum number of entries, newest first
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log
+type AuditVerification struct {
+	Valid        bool   `json:"valid"`               // Every entry matches its hash and links to its predecessor
+	Entries      int64  `json:"entries"`             // Number of entries checked
+	LastSequence int64  `json:"last_sequence"`       // Sequence of the last entry
+	LastHash     string `json:"last_hash"`           // Hash of the last entry; keep a copy elsewhere to detect truncation
+	BrokenAt     int64  `json:"broken_at,omitempty"` // Sequence of the first entry that fails the check
+	Reason       string `json:"reason,omitempty"`    // Why the chain is broken
+}
+
+// CLI modes of recorded commands
+const (
+	CLIModeExec   = "exec"   // Privileged EXEC mode, e.g. "show" commands and "write"
+	CLIModeConfig = "config" // Global configuration mode
+)
+
+// CLICommand is a command sent to the OLT CLI with its raw output
+type CLICommand struct {
+	Command string `json:"command"`
+	Mode    string `json:"mode"` // CLIModeExec or CLIModeConfig
+	Output  string `json:"output"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// onuInterface matches the ONU interfaces of CLI commands, e.g. "interface gpon-onu_1/2/3:4"
+var onuInterface = regexp.MustCompile(`gpon-onu_\d+/(\d+)/(\d+):(\d+)`)
+
+// CommandTargets returns the ONUs whose interfaces the configuration commands address, e.g. the ONUs
+// of a restore. Exec mode commands are skipped: they only read, e.g. for pre-change snapshots.
+func CommandTargets(commands []CLICommand) []AuditTarget {
+	var targets []AuditTarget
+	for _, command := range commands {
+		if command.Mode != CLIModeConfig {
+			continue
+		}
+		if m := onuInterface.FindStringSubmatch(command.Command); m != nil {
+			board, _ := strconv.Atoi(m[1])
+			pon, _ := strconv.Atoi(m[2])
+			onuID, _ := strconv.Atoi(m[3])
+			targets = append(targets, AuditTarget{Board: board, PON: pon, ONUID: onuID})
+		}
+	}
+	return targets
+}
+
+// CLITranscript collects the CLI commands sent on behalf of a request. It is safe for concurrent use,
+// as batch operations run their commands in parallel, and a nil transcript ignores all commands.
+type CLITranscript struct {
+	mu       sync.Mutex
+	commands []CLICommand
+}
+
+// Add appends commands to the transcript
+func (t *CLITranscript) Add(commands ...CLICommand) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.commands = append(t.commands, commands...)
+}
+
+// Commands returns a copy of the recorded commands
+func (t *CLITranscript) Commands() []CLICommand {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]CLICommand(nil), t.commands...)
+}
+
+// cliTranscriptKey is the context key of the CLI transcript
+type cliTranscriptKey struct{}
+
+// ContextWithCLITranscript returns a context whose CLI commands are recorded in t
+func ContextWithCLITranscript(ctx context.Context, t *CLITranscript) context.Context {
+	return context.WithValue(ctx, cliTranscriptKey{}, t)
+}
+
+// CLITranscriptFromContext returns the CLI transcript of ctx, or nil if commands are not recorded
+func CLITranscriptFromContext(ctx context.Context) *CLITranscript {
+	t, _ := ctx.Value(cliTranscriptKey{}).(*CLITranscript)
+	return t
+}
+
+// requestIDKey is the type of RequestIDKey
+type requestIDKey string
+
+// RequestIDKey is the context key of the request ID, set by middleware.RequestID
+const RequestIDKey requestIDKey = "requestID"
+
+// RequestIDFromContext returns the request ID of ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+	return requestID
+}
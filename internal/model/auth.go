@@ -0,0 +1,162 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+// API key scopes. A request needs the scope of its route; see the authentication section of the API reference.
+const (
+	ScopeReadONU      = "read:onu"      // Read ONU, PON, card, VLAN, traffic and monitoring data
+	ScopeWriteONU     = "write:onu"     // Provision, configure, reboot and delete ONUs
+	ScopeWriteVLAN    = "write:vlan"    // Change service ports and ONU VLANs
+	ScopeWriteTraffic = "write:traffic" // Change DBA profiles, T-CONTs and GEM ports
+	ScopeAdminConfig  = "admin:config"  // Back up, restore and save the OLT configuration
+	ScopeBatch        = "batch"         // Run batch operations
+	ScopeAdminKeys    = "admin:keys"    // Issue, rotate and revoke API keys
+	ScopeReadAudit    = "read:audit"    // Read and verify the audit log of OLT changes
+	ScopeReadMetrics  = "read:metrics"  // Scrape the Prometheus metrics of /metrics
+)
+
+// Scopes lists all scopes, e.g. for validating issue requests
+var Scopes = []string{
+	ScopeReadONU, ScopeWriteONU, ScopeWriteVLAN, ScopeWriteTraffic,
+	ScopeAdminConfig, ScopeBatch, ScopeAdminKeys, ScopeReadAudit, ScopeReadMetrics,
+}
+
+// Roles of OIDC users; token claims are mapped to them with AUTH_OIDC_ROLE_MAPPING
+const (
+	RoleViewer     = "viewer"     // Read-only access
+	RoleTechnician = "technician" // Provision and change ONUs, VLANs and traffic profiles, run batches
+	RoleAdmin      = "admin"      // All scopes, including backups, API keys and the audit log
+)
+
+// RoleScopes lists the scopes granted by each role
+var RoleScopes = map[string][]string{
+	RoleViewer:     {ScopeReadONU},
+	RoleTechnician: {ScopeReadONU, ScopeWriteONU, ScopeWriteVLAN, ScopeWriteTraffic, ScopeBatch},
+	RoleAdmin:      Scopes,
+}
+
+// Authentication methods of a Principal
+const (
+	AuthMethodAPIKey = "api_key" // Key issued by POST /api/v1/auth/keys
+	AuthMethodAdmin  = "admin"   // Bootstrap key from AUTH_ADMIN_KEY
+	AuthMethodOIDC   = "oidc"    // Bearer token of the OIDC provider
+)
+
+// BoardPON identifies a PON port of an OLT
+type BoardPON struct {
+	Board int `json:"board"` // Board ID (1-2)
+	PON   int `json:"pon"`   // PON ID (1-16)
+}
+
+// APIKey represents an issued API key. The key itself is only returned once; the API keeps its SHA-256 hash.
+type APIKey struct {
+	ID        string     `json:"id"`                   // Key ID, also part of the key
+	Name      string     `json:"name"`                 // Description of the key holder, e.g. "noc-dashboard"
+	Scopes    []string   `json:"scopes"`               // Granted scopes
+	Boards    []int      `json:"boards,omitempty"`     // Boards the key is restricted to
+	PONs      []BoardPON `json:"pons,omitempty"`       // PON ports the key is restricted to
+	CreatedBy string     `json:"created_by,omitempty"` // Identity of the caller that issued this key
+	CreatedAt time.Time  `json:"created_at"`           // When the key was issued
+	RotatedAt *time.Time `json:"rotated_at,omitempty"` // When the key was last rotated
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Key is rejected after this time
+	RevokedAt *time.Time `json:"revoked_at,omitempty"` // Key is rejected since this time
+	Hash      string     `json:"-"`                    // Hex SHA-256 of the key
+}
+
+// Restricted reports whether the key only grants access to some boards or PON ports
+func (k *APIKey) Restricted() bool {
+	return len(k.Boards) > 0 || len(k.PONs) > 0
+}
+
+// APIKeyRequest represents a request to issue an API key
+type APIKeyRequest struct {
+	Name      string     `json:"name"`                 // Description of the key holder
+	Scopes    []string   `json:"scopes"`               // Granted scopes, at least one
+	Boards    []int      `json:"boards,omitempty"`     // Restrict the key to these boards
+	PONs      []BoardPON `json:"pons,omitempty"`       // Restrict the key to these PON ports
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // Optional expiry
+}
+
+// IssuedAPIKey is returned when a key is issued or rotated. It is the only response that contains the key.
+type IssuedAPIKey struct {
+	Key    string  `json:"key"`     // The API key, sent as "X-API-Key" or "Authorization: Bearer" header
+	APIKey *APIKey `json:"api_key"` // Metadata of the key
+}
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	ID     string     `json:"id"`               // Key ID, or the "sub" claim of a token
+	Name   string     `json:"name"`             // Key name, or the user name of a token
+	Method string     `json:"method"`           // AuthMethodAPIKey, AuthMethodAdmin or AuthMethodOIDC
+	Roles  []string   `json:"roles,omitempty"`  // Roles of an OIDC user
+	Scopes []string   `json:"scopes"`           // Granted scopes
+	Boards []int      `json:"boards,omitempty"` // Boards the caller is restricted to
+	PONs   []BoardPON `json:"pons,omitempty"`   // PON ports the caller is restricted to
+
+	KeyRotatedAt *time.Time `json:"-"` // Last rotation of the API key, to tell the key a job was submitted with from a rotated one
+}
+
+// Identity names the caller in backups and logs: the user name of a token, "api_key:<name>" for API keys
+// and "admin" for the bootstrap key
+func (p *Principal) Identity() string {
+	switch p.Method {
+	case AuthMethodAPIKey:
+		return AuthMethodAPIKey + ":" + p.Name
+	case AuthMethodAdmin:
+		return p.ID
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.ID
+}
+
+// HasScope reports whether the principal was granted the scope
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Restricted reports whether the principal only has access to some boards or PON ports
+func (p *Principal) Restricted() bool {
+	return len(p.Boards) > 0 || len(p.PONs) > 0
+}
+
+// AllowsPON reports whether the principal has access to a PON port: either its board or the port itself is listed
+func (p *Principal) AllowsPON(boardID, ponID int) bool {
+	if !p.Restricted() {
+		return true
+	}
+	for _, board := range p.Boards {
+		if board == boardID {
+			return true
+		}
+	}
+	for _, pon := range p.PONs {
+		if pon.Board == boardID && pon.PON == ponID {
+			return true
+		}
+	}
+	return false
+}
+
+// principalKey is the context key for the authenticated caller
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the authenticated caller
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the authenticated caller carried by ctx
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
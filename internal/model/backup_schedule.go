@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Automatic tags of scheduled backups; the schedule ID is added as a tag as well
+const (
+	BackupTagScheduled = "scheduled" // Set on every backup taken by the scheduler
+)
+
+// RetentionPolicy decides which scheduled backups are kept.
+// A backup is kept if any rule selects it; with all rules at 0 nothing is pruned.
+type RetentionPolicy struct {
+	KeepLast   int `json:"keep_last"`   // Keep the N most recent backups
+	KeepDaily  int `json:"keep_daily"`  // Keep the most recent backup of each of the last N days that have one
+	KeepWeekly int `json:"keep_weekly"` // Keep the most recent backup of each of the last N ISO weeks that have one
+}
+
+// BackupSchedule represents a recurring OLT backup
+type BackupSchedule struct {
+	ID          string          `json:"id"`                    // Schedule ID, also used as a backup tag (e.g., "daily")
+	Cron        string          `json:"cron"`                  // Cron expression, e.g., "0 2 * * *" or "@daily"
+	Enabled     bool            `json:"enabled"`               // Disabled schedules are kept but never run
+	Description string          `json:"description,omitempty"` // Description stored on each backup
+	Tags        []string        `json:"tags,omitempty"`        // Extra tags added to each backup
+	Retention   RetentionPolicy `json:"retention"`             // Pruning rules for backups of this schedule
+
+	// Run state, maintained by the scheduler
+	NextRun      *time.Time `json:"next_run,omitempty"`       // Next planned run
+	LastRun      *time.Time `json:"last_run,omitempty"`       // Start of the last run
+	LastStatus   string     `json:"last_status,omitempty"`    // "success", "partial" or "failed"
+	LastError    string     `json:"last_error,omitempty"`     // Error of the last run
+	LastBackupID string     `json:"last_backup_id,omitempty"` // Backup created by the last run
+	LastPruned   int        `json:"last_pruned,omitempty"`    // Backups deleted by retention in the last run
+}
+
+// BackupScheduleRequest represents a request to create or update a backup schedule.
+// On update, omitted fields keep their current value.
+type BackupScheduleRequest struct {
+	ID          string           `json:"id,omitempty"`          // Schedule ID (create only): letters, digits, "-" and "_"
+	Cron        *string          `json:"cron,omitempty"`        // Cron expression
+	Enabled     *bool            `json:"enabled,omitempty"`     // Enable or disable the schedule (default enabled)
+	Description *string          `json:"description,omitempty"` // Backup description
+	Tags        []string         `json:"tags,omitempty"`        // Extra backup tags
+	Retention   *RetentionPolicy `json:"retention,omitempty"`   // Retention rules
+}
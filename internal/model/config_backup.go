@@ -2,14 +2,26 @@ package model
 
 import "time"
 
+// BackupSchemaVersion is the version of the backup document format written by this build.
+// Older documents are migrated when they are loaded or imported:
+//
+//	0: early export format (backup_id, top-level pon_port/onu_id, configuration)
+//	1: id/type/timestamp/metadata/config layout without schema_version
+//	2: schema_version and metadata.checksum; ONU VLANs are carried by service_ports
+const BackupSchemaVersion = 2
+
+// BackupTagPreChange marks backups taken automatically before a destructive operation
+const BackupTagPreChange = "pre-change"
+
 // ConfigBackup represents a configuration backup for an ONU or entire OLT
 type ConfigBackup struct {
-	ID          string         `json:"id"`                    // UUID for backup
-	Type        string         `json:"type"`                  // "onu" or "olt"
-	Timestamp   time.Time      `json:"timestamp"`             // When backup was created
-	Description string         `json:"description,omitempty"` // User-provided description
-	Metadata    BackupMetadata `json:"metadata"`              // Additional metadata
-	Config      interface{}    `json:"config"`                // ONU config or OLT config
+	SchemaVersion int            `json:"schema_version"`        // Backup document format (BackupSchemaVersion)
+	ID            string         `json:"id"`                    // UUID for backup
+	Type          string         `json:"type"`                  // "onu" or "olt"
+	Timestamp     time.Time      `json:"timestamp"`             // When backup was created
+	Description   string         `json:"description,omitempty"` // User-provided description
+	Metadata      BackupMetadata `json:"metadata"`              // Additional metadata
+	Config        interface{}    `json:"config"`                // ONU config or OLT config
 }
 
 // BackupMetadata contains additional information about the backup
@@ -20,6 +32,7 @@ type BackupMetadata struct {
 	TotalONUs    int               `json:"total_onus,omitempty"`    // For OLT-level backups
 	Tags         []string          `json:"tags,omitempty"`          // Custom tags
 	CustomFields map[string]string `json:"custom_fields,omitempty"` // Additional custom metadata
+	Checksum     string            `json:"checksum,omitempty"`      // SHA-256 of the configuration ("sha256:<hex>")
 }
 
 // ONUConfigBackup represents configuration for a single ONU
@@ -136,17 +149,18 @@ type BackupListItem struct {
 	ONUCount    int       `json:"onu_count,omitempty"`   // Number of ONUs in backup
 	Source      string    `json:"source,omitempty"`      // Source identifier
 	Tags        []string  `json:"tags,omitempty"`        // Tags
+	Checksum    string    `json:"checksum,omitempty"`    // SHA-256 of the configuration
 }
 
 // RestoreRequest represents a request to restore configuration
 type RestoreRequest struct {
 	BackupID     string   `json:"backup_id"`               // ID of backup to restore
 	TargetType   string   `json:"target_type,omitempty"`   // "same", "different" - where to restore
-	TargetPON    string   `json:"target_pon,omitempty"`    // Target PON (if different from backup)
-	TargetONUID  int      `json:"target_onu_id,omitempty"` // Target ONU ID (if different)
-	Overwrite    bool     `json:"overwrite,omitempty"`     // Overwrite existing config
-	DryRun       bool     `json:"dry_run,omitempty"`       // Simulate restore without applying
-	RestoreItems []string `json:"restore_items,omitempty"` // Specific items to restore: "vlan", "tcont", "gemport", "service_port"
+	TargetPON    string   `json:"target_pon,omitempty"`    // Target PON (ONU backup: restore onto this PON; OLT backup: only restore ONUs of this PON)
+	TargetONUID  int      `json:"target_onu_id,omitempty"` // Target ONU ID (ONU backup: restore as this ID; OLT backup: only restore this ONU ID)
+	Overwrite    bool     `json:"overwrite,omitempty"`     // Replace conflicting config on the target instead of failing
+	DryRun       bool     `json:"dry_run,omitempty"`       // Simulate restore without applying; returns the commands that would run
+	RestoreItems []string `json:"restore_items,omitempty"` // Specific items to restore: "onu", "dba_profile", "tcont", "gemport", "service_port" ("vlan" is an alias of "service_port"); empty restores all
 }
 
 // RestoreResult represents the result of a restore operation
@@ -162,12 +176,13 @@ type RestoreResult struct {
 
 // RestoreItemResult represents result for individual restore item
 type RestoreItemResult struct {
-	PONPort  string `json:"pon_port,omitempty"` // PON port
-	ONUID    int    `json:"onu_id,omitempty"`   // ONU ID
-	ItemType string `json:"item_type"`          // "onu", "vlan", "tcont", etc.
-	Success  bool   `json:"success"`            // Item restore success
-	Message  string `json:"message,omitempty"`  // Result message
-	Error    string `json:"error,omitempty"`    // Error message if failed
+	PONPort  string   `json:"pon_port,omitempty"` // PON port
+	ONUID    int      `json:"onu_id,omitempty"`   // ONU ID
+	ItemType string   `json:"item_type"`          // "onu", "dba_profile", "cleanup", "tcont", "gemport", "service_port"
+	Success  bool     `json:"success"`            // Item restore success
+	Message  string   `json:"message,omitempty"`  // Result message
+	Error    string   `json:"error,omitempty"`    // Error message if failed
+	Commands []string `json:"commands,omitempty"` // CLI commands executed (or, in dry-run mode, that would be executed)
 }
 
 // BackupCreateRequest represents request to create a backup
@@ -179,3 +194,11 @@ type BackupCreateRequest struct {
 	Tags         []string `json:"tags,omitempty"`          // Custom tags
 	IncludeItems []string `json:"include_items,omitempty"` // What to include: "vlan", "tcont", "gemport", "service_port"
 }
+
+// PreChangeSnapshot links the backup taken automatically before a destructive operation.
+// Restoring it undoes the operation.
+type PreChangeSnapshot struct {
+	BackupID        string `json:"backup_id"`        // ONU backup taken before the change
+	Operation       string `json:"operation"`        // Operation that triggered the snapshot (also a backup tag)
+	RestoreEndpoint string `json:"restore_endpoint"` // Endpoint that restores the snapshot, relative to the API base
+}
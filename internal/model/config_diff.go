@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// Change kinds used in ONU and field level diffs
+const (
+	ChangeAdded   = "added"   // Present in the newer configuration only
+	ChangeRemoved = "removed" // Present in the older configuration only
+	ChangeChanged = "changed" // Present in both with different values
+)
+
+// FieldChange represents a single difference inside an ONU configuration
+type FieldChange struct {
+	Field  string      `json:"field"`         // e.g., "name", "admin_state", "tcont 1", "service_port 2", "vlan 100/100"
+	Change string      `json:"change"`        // "added", "removed" or "changed"
+	Old    interface{} `json:"old,omitempty"` // Value in the older configuration
+	New    interface{} `json:"new,omitempty"` // Value in the newer configuration
+}
+
+// ONUDiff represents the differences of a single ONU between two configurations
+type ONUDiff struct {
+	PONPort string        `json:"pon_port"`          // PON port
+	ONUID   int           `json:"onu_id"`            // ONU ID
+	Change  string        `json:"change"`            // "added", "removed" or "changed"
+	Changes []FieldChange `json:"changes,omitempty"` // Field level changes (for "changed")
+}
+
+// DiffSummary counts ONUs per change kind
+type DiffSummary struct {
+	Added     int `json:"added"`     // ONUs only present in the newer configuration
+	Removed   int `json:"removed"`   // ONUs only present in the older configuration
+	Changed   int `json:"changed"`   // ONUs present in both with differences
+	Unchanged int `json:"unchanged"` // ONUs present in both without differences
+}
+
+// ConfigDrift represents the differences between a stored backup and the live OLT configuration
+type ConfigDrift struct {
+	BackupID        string      `json:"backup_id"`          // Backup compared against
+	BackupType      string      `json:"backup_type"`        // "onu" or "olt"
+	BackupTimestamp time.Time   `json:"backup_timestamp"`   // When the backup was created
+	CheckedAt       time.Time   `json:"checked_at"`         // When the live configuration was read
+	HasDrift        bool        `json:"has_drift"`          // True if any ONU differs
+	Summary         DiffSummary `json:"summary"`            // Per-kind ONU counts
+	ONUs            []ONUDiff   `json:"onus"`               // Per-ONU differences (backup = old, live = new)
+	Warnings        []string    `json:"warnings,omitempty"` // Parts of the live configuration that could not be read
+}
+
+// BackupRef identifies one side of a backup diff
+type BackupRef struct {
+	ID          string    `json:"id"`                    // Backup ID
+	Type        string    `json:"type"`                  // "onu" or "olt"
+	Timestamp   time.Time `json:"timestamp"`             // When the backup was created
+	Description string    `json:"description,omitempty"` // Backup description
+}
+
+// ConfigDiff represents the differences between two stored backups
+type ConfigDiff struct {
+	From          BackupRef     `json:"from"`                     // Older side of the diff
+	To            BackupRef     `json:"to"`                       // Newer side of the diff
+	Identical     bool          `json:"identical"`                // True if no differences were found
+	Summary       DiffSummary   `json:"summary"`                  // Per-kind ONU counts
+	ONUs          []ONUDiff     `json:"onus"`                     // Per-ONU differences
+	GlobalChanges []FieldChange `json:"global_changes,omitempty"` // DBA profile and VLAN changes (OLT backups)
+	Unified       string        `json:"unified"`                  // Unified-text rendering of the diff
+}
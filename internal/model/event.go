@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// Event types decoded from SNMP traps and informs
+const (
+	EventOnuOnline      = "onu_online"       // ONU came online
+	EventOnuOffline     = "onu_offline"      // ONU went offline (deregistered, authentication failure, ...)
+	EventOnuDyingGasp   = "onu_dying_gasp"   // ONU lost power
+	EventOnuLOS         = "onu_los"          // ONU loss of signal (fiber cut)
+	EventOnuStateChange = "onu_state_change" // Other ONU state transitions (logging, synchronization)
+	EventPonPortDown    = "pon_port_down"    // PON port link down
+	EventPonPortUp      = "pon_port_up"      // PON port link up
+	EventCardOffline    = "card_offline"     // Card removed or offline
+	EventCardOnline     = "card_online"      // Card inserted or online
+	EventUnknown        = "unknown"          // Notification without a mapping in the OID profile
+)
+
+// EventTypes lists all event types, e.g. for validating filters
+var EventTypes = []string{
+	EventOnuOnline, EventOnuOffline, EventOnuDyingGasp, EventOnuLOS, EventOnuStateChange,
+	EventPonPortDown, EventPonPortUp, EventCardOffline, EventCardOnline, EventUnknown,
+}
+
+// Event severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// OLTEvent is a normalized event received from an OLT as SNMP trap or inform
+type OLTEvent struct {
+	ID         string            `json:"id"`                  // UUID of the event
+	OLTID      string            `json:"olt_id"`              // OLT that sent the notification
+	Type       string            `json:"type"`                // Event type (EventOnuOffline, ...)
+	Severity   string            `json:"severity"`            // "info", "warning" or "critical"
+	Message    string            `json:"message"`             // Human-readable summary
+	ReceivedAt time.Time         `json:"received_at"`         // When the notification was received
+	Source     string            `json:"source"`              // Address the notification came from
+	TrapOID    string            `json:"trap_oid"`            // Notification OID (snmpTrapOID.0)
+	Inform     bool              `json:"inform,omitempty"`    // Received as acknowledged inform instead of trap
+	Board      int               `json:"board,omitempty"`     // Board of ONU and PON events
+	PON        int               `json:"pon,omitempty"`       // PON port of ONU and PON events
+	ONUID      int               `json:"onu_id,omitempty"`    // ONU of ONU events
+	Rack       int               `json:"rack,omitempty"`      // Rack of card events
+	Shelf      int               `json:"shelf,omitempty"`     // Shelf of card events
+	Slot       int               `json:"slot,omitempty"`      // Slot of card events
+	Status     string            `json:"status,omitempty"`    // ONU state reported with the event, e.g. "Offline"
+	Variables  map[string]string `json:"variables,omitempty"` // Variable bindings of the notification (OID -> value)
+}
+
+// EventQuery filters events; zero values match everything
+type EventQuery struct {
+	OLTID string    // Only events of this OLT
+	Type  string    // Only events of this type
+	Board int       // Only events on this board
+	PON   int       // Only events on this PON port
+	ONUID int       // Only events of this ONU
+	Since time.Time // Only events received at or after this time
+	Until time.Time // Only events received before this time
+	Limit int       // Maximum number of events, newest first
+}
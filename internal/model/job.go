@@ -0,0 +1,161 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job types
+const (
+	JobTypeBatchReboot       = "batch.reboot"              // Reboot the target ONUs
+	JobTypeBatchBlock        = "batch.block"               // Block (disable) the target ONUs
+	JobTypeBatchUnblock      = "batch.unblock"             // Unblock (enable) the target ONUs
+	JobTypeBatchDelete       = "batch.delete"              // Delete the target ONUs
+	JobTypeBatchDescriptions = "batch.update_descriptions" // Set the descriptions of the target ONUs
+	JobTypeBackupOLT         = "config.backup_olt"         // Back up the configuration of all ONUs of the OLT
+	JobTypeRestore           = "config.restore"            // Restore a backup onto the OLT
+	JobTypePONMonitoring     = "monitoring.pon"            // Read the monitoring data of all ONUs of a PON port
+)
+
+// JobType describes what a job type requires and does
+type JobType struct {
+	Scope      string // Scope needed to submit, see, cancel and resume jobs of the type
+	ChangesOLT bool   // Runs are recorded in the audit log
+}
+
+// JobTypes lists all job types
+var JobTypes = map[string]JobType{
+	JobTypeBatchReboot:       {Scope: ScopeBatch, ChangesOLT: true},
+	JobTypeBatchBlock:        {Scope: ScopeBatch, ChangesOLT: true},
+	JobTypeBatchUnblock:      {Scope: ScopeBatch, ChangesOLT: true},
+	JobTypeBatchDelete:       {Scope: ScopeBatch, ChangesOLT: true},
+	JobTypeBatchDescriptions: {Scope: ScopeBatch, ChangesOLT: true},
+	JobTypeBackupOLT:         {Scope: ScopeAdminConfig},
+	JobTypeRestore:           {Scope: ScopeAdminConfig, ChangesOLT: true},
+	JobTypePONMonitoring:     {Scope: ScopeReadONU},
+}
+
+// Job states
+const (
+	JobQueued    = "queued"    // Waiting for the OLT's earlier jobs
+	JobRunning   = "running"   // Being executed; also after a restart until the job continues
+	JobCompleted = "completed" // Every target succeeded
+	JobFailed    = "failed"    // Finished with failed targets, or the job failed; can be resumed
+	JobCanceled  = "canceled"  // Canceled before all targets ran; can be resumed
+)
+
+// Job target states
+const (
+	JobTargetPending   = "pending"   // Not run yet
+	JobTargetRunning   = "running"   // Being executed
+	JobTargetSucceeded = "succeeded" // Done
+	JobTargetFailed    = "failed"    // Failed, retried when the job is resumed
+)
+
+// JobOwner identifies the caller a job runs as. Only the identity is stored: its current scopes and
+// board/PON restrictions are looked up before each target, so a revoked, rotated or narrowed key stops the job.
+type JobOwner struct {
+	Method       string     `json:"method"`                   // AuthMethodAPIKey, AuthMethodAdmin or AuthMethodOIDC
+	ID           string     `json:"id"`                       // Key ID, or the "sub" claim of a token
+	Name         string     `json:"name,omitempty"`           // Key name, or the user name of a token
+	Roles        []string   `json:"roles,omitempty"`          // Roles of the OIDC token the job was submitted with
+	KeyRotatedAt *time.Time `json:"key_rotated_at,omitempty"` // Last rotation of the API key at submission
+}
+
+// NewJobOwner returns the identity of a principal
+func NewJobOwner(p *Principal) *JobOwner {
+	return &JobOwner{Method: p.Method, ID: p.ID, Name: p.Name, Roles: p.Roles, KeyRotatedAt: p.KeyRotatedAt}
+}
+
+// Identity names the owner like Principal.Identity
+func (o *JobOwner) Identity() string {
+	return (&Principal{ID: o.ID, Name: o.Name, Method: o.Method}).Identity()
+}
+
+// Job is an operation that runs in the background on one OLT. Jobs that change an OLT run one after
+// another, read-only jobs beside them; jobs are kept in Redis and continue after a restart.
+type Job struct {
+	ID              string          `json:"id"`                         // UUID of the job
+	Type            string          `json:"type"`                       // Job type, e.g. JobTypeBatchReboot
+	OLTID           string          `json:"olt_id"`                     // OLT the job runs on
+	Status          string          `json:"status"`                     // JobQueued, JobRunning, JobCompleted, JobFailed or JobCanceled
+	CreatedBy       string          `json:"created_by,omitempty"`       // Identity of the caller that submitted the job
+	ResumedBy       string          `json:"resumed_by,omitempty"`       // Identity of the caller that last resumed the job
+	RunAs           *JobOwner       `json:"run_as,omitempty"`           // Caller the job runs as: the submitter, or the caller that last resumed it
+	RequestID       string          `json:"request_id,omitempty"`       // X-Request-ID of the submission
+	Request         json.RawMessage `json:"request,omitempty"`          // Parameters of the job
+	Targets         []JobTarget     `json:"targets,omitempty"`          // ONUs of batch jobs with their progress
+	Progress        JobProgress     `json:"progress"`                   // Counts of the targets by state
+	Result          json.RawMessage `json:"result,omitempty"`           // Result of a job without targets, e.g. the backup
+	Error           string          `json:"error,omitempty"`            // Why the job failed
+	Attempts        int             `json:"attempts"`                   // Number of runs, including resumes
+	CancelRequested bool            `json:"cancel_requested,omitempty"` // Cancel was requested; the running target finishes first
+	CreatedAt       time.Time       `json:"created_at"`                 // When the job was submitted
+	StartedAt       *time.Time      `json:"started_at,omitempty"`       // When the last run started
+	FinishedAt      *time.Time      `json:"finished_at,omitempty"`      // When the last run ended
+	DurationMs      int64           `json:"duration_ms,omitempty"`      // Duration of the last run
+	UpdatedAt       time.Time       `json:"updated_at"`                 // Last change of the job
+	ExpiresAt       *time.Time      `json:"expires_at,omitempty"`       // When a finished job is removed
+}
+
+// Finished reports whether the job is completed, failed or canceled
+func (j *Job) Finished() bool {
+	return j.Status == JobCompleted || j.Status == JobFailed || j.Status == JobCanceled
+}
+
+// UpdateProgress recounts the targets. A job without targets counts as a single target.
+func (j *Job) UpdateProgress() {
+	progress := JobProgress{Total: len(j.Targets)}
+	if len(j.Targets) == 0 {
+		progress.Total = 1
+		switch j.Status {
+		case JobCompleted:
+			progress.Succeeded = 1
+		case JobFailed:
+			progress.Failed = 1
+		default:
+			progress.Pending = 1
+		}
+		j.Progress = progress
+		return
+	}
+
+	for _, target := range j.Targets {
+		switch target.Status {
+		case JobTargetSucceeded:
+			progress.Succeeded++
+		case JobTargetFailed:
+			progress.Failed++
+		default:
+			progress.Pending++
+		}
+	}
+	j.Progress = progress
+}
+
+// JobTarget is an ONU a batch job applies its operation to
+type JobTarget struct {
+	PONPort     string     `json:"pon_port"`              // e.g. "1/1/1"
+	ONUID       int        `json:"onu_id"`                // ONU ID on the PON
+	Description string     `json:"description,omitempty"` // New description (batch description update)
+	Status      string     `json:"status"`                // JobTargetPending, JobTargetRunning, JobTargetSucceeded or JobTargetFailed
+	Message     string     `json:"message,omitempty"`     // Result message
+	Error       string     `json:"error,omitempty"`       // Why the target failed
+	FinishedAt  *time.Time `json:"finished_at,omitempty"` // When the target was last run
+}
+
+// JobProgress counts the targets of a job by state; running targets count as pending
+type JobProgress struct {
+	Total     int `json:"total"`
+	Pending   int `json:"pending"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// JobQuery filters jobs; zero values match everything
+type JobQuery struct {
+	OLTID  string // Only jobs of this OLT
+	Type   string // Only this job type ("batch.reboot"), or all types of a group ("batch")
+	Status string // Only jobs in this state
+	Limit  int    // Maximum number of jobs, newest first
+}
@@ -4,14 +4,15 @@ import "time"
 
 // ONUMonitoringInfo represents real-time monitoring data for a single ONU
 type ONUMonitoringInfo struct {
+	Board        int            `json:"board"`
 	PonPort      string         `json:"pon_port"`
 	OnuID        int            `json:"onu_id"`
 	SerialNumber string         `json:"serial_number"`
 	Model        string         `json:"model"`
-	FirmwareVer  string         `json:"firmware_version"`
-	OnlineStatus int            `json:"online_status"` // 1=online, 0=offline
-	Statistics   *ONUStatistics `json:"statistics,omitempty"`
-	Optical      *OpticalInfo   `json:"optical,omitempty"` // Optical power info (via Telnet)
+	FirmwareVer  string         `json:"firmware_version"`     // Empty if the firmware has no SNMP column for it (V2.2)
+	OnlineStatus int            `json:"online_status"`        // 1=online, 0=offline
+	Statistics   *ONUStatistics `json:"statistics,omitempty"` // Not available on V2.2
+	Optical      *OpticalInfo   `json:"optical,omitempty"`    // Optical power info (via Telnet)
 	LastUpdate   time.Time      `json:"last_update"`
 }
 
@@ -37,6 +38,7 @@ type OpticalInfo struct {
 
 // PONMonitoringInfo represents aggregated monitoring for a PON port
 type PONMonitoringInfo struct {
+	Board        int                 `json:"board"`
 	PonPort      string              `json:"pon_port"`
 	PonIndex     int                 `json:"pon_index"`
 	OnuCount     int                 `json:"onu_count"`
@@ -56,9 +58,25 @@ type PONStatistics struct {
 
 // OLTMonitoringSummary represents overall OLT monitoring summary
 type OLTMonitoringSummary struct {
-	TotalONUs   int                 `json:"total_onus"`
-	OnlineONUs  int                 `json:"online_onus"`
-	OfflineONUs int                 `json:"offline_onus"`
-	PONPorts    []PONMonitoringInfo `json:"pon_ports"`
-	LastUpdate  time.Time           `json:"last_update"`
+	TotalONUs   int                      `json:"total_onus"`
+	OnlineONUs  int                      `json:"online_onus"`
+	OfflineONUs int                      `json:"offline_onus"`
+	Boards      []BoardMonitoringSummary `json:"boards"`
+	PONPorts    []PONMonitoringInfo      `json:"pon_ports"`
+	LastUpdate  time.Time                `json:"last_update"`
+}
+
+// BoardMonitoringSummary represents the ONU counts of one GPON board
+type BoardMonitoringSummary struct {
+	Board       int `json:"board"`
+	PONPorts    int `json:"pon_ports"` // Number of PON ports read
+	TotalONUs   int `json:"total_onus"`
+	OnlineONUs  int `json:"online_onus"`
+	OfflineONUs int `json:"offline_onus"`
+}
+
+// PONMonitoringJobRequest holds the parameters of a PON monitoring job
+type PONMonitoringJobRequest struct {
+	Board int `json:"board"`
+	PON   int `json:"pon"`
 }
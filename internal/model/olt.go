@@ -0,0 +1,14 @@
+package model
+
+// OLTInfo describes an OLT registered with this API instance
+type OLTInfo struct {
+	ID         string                 `json:"id"`                      // OLT identifier used in /api/v1/olts/{olt_id}
+	Name       string                 `json:"name"`                    // Human-readable name
+	Firmware   string                 `json:"firmware"`                // Firmware profile (v2.1 or v2.2)
+	SnmpHost   string                 `json:"snmp_host"`               // SNMP agent address
+	SnmpPort   uint16                 `json:"snmp_port"`               // SNMP port
+	TelnetHost string                 `json:"telnet_host"`             // Telnet host
+	TelnetPort int                    `json:"telnet_port"`             // Telnet port
+	Default    bool                   `json:"default"`                 // Whether this OLT is also served under /api/v1
+	Telnet     map[string]interface{} `json:"telnet_status,omitempty"` // Telnet session pool status
+}
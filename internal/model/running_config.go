@@ -0,0 +1,90 @@
+package model
+
+// RunningConfig is the structured form of the C320 "show running-config" output
+type RunningConfig struct {
+	PONPorts      []PONRunningConfig    `json:"pon_ports"`                // interface gpon-olt_x/y/z blocks
+	ONUs          []ONURunningConfig    `json:"onus"`                     // interface gpon-onu_x/y/z:n blocks
+	ONUManagement []ONUManagementConfig `json:"onu_management,omitempty"` // pon-onu-mng gpon-onu_x/y/z:n blocks
+	DBAProfiles   []DBAProfileConfig    `json:"dba_profiles,omitempty"`   // DBA (T-CONT bandwidth) profiles
+	VLANs         []GlobalVLANConfig    `json:"vlans,omitempty"`          // Global VLAN definitions
+}
+
+// RunningConfigResponse is returned by GET /api/v1/config/running
+type RunningConfigResponse struct {
+	Config    *RunningConfig `json:"config"`        // Parsed configuration
+	Raw       string         `json:"raw,omitempty"` // Raw CLI output (only when requested)
+	Lines     int            `json:"lines"`         // Number of lines in the raw output
+	Retrieved string         `json:"retrieved_at"`  // RFC3339 timestamp of retrieval
+}
+
+// PONRunningConfig represents the running-config of "interface gpon-olt_x/y/z"
+type PONRunningConfig struct {
+	PONPort     string           `json:"pon_port"`              // e.g., "1/1/1"
+	Name        string           `json:"name,omitempty"`        // PON port name
+	Description string           `json:"description,omitempty"` // PON port description
+	ONUs        []ONUDeclaration `json:"onus"`                  // ONUs declared on this PON
+}
+
+// ONUDeclaration represents an ONU declared under "interface gpon-olt_x/y/z" in the running-config
+type ONUDeclaration struct {
+	ONUID        int    `json:"onu_id"`
+	Type         string `json:"type"`                  // ONU type as used in "onu N type <type> sn <sn>"
+	SerialNumber string `json:"serial_number"`         // Serial number used for authentication
+	Name         string `json:"name,omitempty"`        // "onu N name <name>"
+	Description  string `json:"description,omitempty"` // "onu N description <text>"
+	AdminState   string `json:"admin_state"`           // "enabled" or "disabled" ("onu N state disable")
+}
+
+// ONURunningConfig represents the parsed running-config of "interface gpon-onu_x/y/z:n"
+type ONURunningConfig struct {
+	PONPort      string                 `json:"pon_port"`
+	ONUID        int                    `json:"onu_id"`
+	Name         string                 `json:"name,omitempty"`
+	Description  string                 `json:"description,omitempty"`
+	TCONTs       []ONUTCONTConfig       `json:"tconts,omitempty"`
+	GEMPorts     []ONUGEMPortConfig     `json:"gemports,omitempty"`
+	ServicePorts []ONUServicePortConfig `json:"service_ports,omitempty"`
+}
+
+// ONUManagementConfig represents the running-config of "pon-onu-mng gpon-onu_x/y/z:n" (OMCI settings)
+type ONUManagementConfig struct {
+	PONPort   string           `json:"pon_port"`
+	ONUID     int              `json:"onu_id"`
+	Services  []ONUMngService  `json:"services,omitempty"`   // "service <name> gemport <n> vlan <v>"
+	VLANPorts []ONUMngVLANPort `json:"vlan_ports,omitempty"` // "vlan port <port> mode <mode> vlan <v>"
+	Lines     []string         `json:"lines,omitempty"`      // All lines of the block, in order
+}
+
+// ONUMngService represents a service mapping in a pon-onu-mng block
+type ONUMngService struct {
+	Name      string `json:"name"`
+	GEMPortID int    `json:"gemport_id"`
+	VLAN      int    `json:"vlan,omitempty"`
+}
+
+// ONUMngVLANPort represents a UNI port VLAN setting in a pon-onu-mng block
+type ONUMngVLANPort struct {
+	Port string `json:"port"`           // e.g., "eth_0/1", "wifi_0/1"
+	Mode string `json:"mode"`           // "tag", "trunk", "transparent", ...
+	VLAN int    `json:"vlan,omitempty"` // VLAN ID
+}
+
+// PON returns the gpon-olt block of a PON port, or nil if it is not configured
+func (c *RunningConfig) PON(ponPort string) *PONRunningConfig {
+	for i := range c.PONPorts {
+		if c.PONPorts[i].PONPort == ponPort {
+			return &c.PONPorts[i]
+		}
+	}
+	return nil
+}
+
+// ONU returns the gpon-onu block of an ONU, or nil if it is not configured
+func (c *RunningConfig) ONU(ponPort string, onuID int) *ONURunningConfig {
+	for i := range c.ONUs {
+		if c.ONUs[i].PONPort == ponPort && c.ONUs[i].ONUID == onuID {
+			return &c.ONUs[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package model
+
+import "time"
+
+// Types of the updates of the live monitoring stream, sent as SSE event names
+const (
+	StreamONUStatus   = "onu_status"   // ONU went online or offline; the first poll of a PON reports every ONU
+	StreamONUOptical  = "onu_optical"  // Optical readings of an ONU, on every poll
+	StreamPONCounters = "pon_counters" // ONU counts and traffic counters of a PON port, on every poll
+	StreamONURemoved  = "onu_removed"  // ONU missing from the latest poll of its PON port; its state is dropped
+	StreamEvent       = "event"        // Event received as SNMP trap or inform
+)
+
+// Sources of stream updates
+const (
+	StreamSourcePoll = "poll" // Polled by the state collector of the OLT
+	StreamSourceTrap = "trap" // Received as SNMP trap or inform
+)
+
+// MonitoringUpdate is a message of the live monitoring stream.
+// Exactly one of Status, Optical, Counters and Event is set, depending on Type; none for onu_removed.
+type MonitoringUpdate struct {
+	Type     string           `json:"type"`               // StreamONUStatus, StreamONUOptical, ...
+	Source   string           `json:"source"`             // StreamSourcePoll or StreamSourceTrap
+	OLTID    string           `json:"olt_id"`             // OLT the update belongs to
+	Board    int              `json:"board,omitempty"`    // Board of ONU and PON updates
+	PON      int              `json:"pon,omitempty"`      // PON port of ONU and PON updates
+	ONUID    int              `json:"onu_id,omitempty"`   // ONU of ONU updates
+	Time     time.Time        `json:"time"`               // When the state was polled or the trap received
+	Status   *ONUStatusChange `json:"status,omitempty"`   // onu_status
+	Optical  *OpticalInfo     `json:"optical,omitempty"`  // onu_optical
+	Counters *PONCounters     `json:"counters,omitempty"` // pon_counters
+	Event    *OLTEvent        `json:"event,omitempty"`    // event
+}
+
+// ONUStatusChange is the state of an ONU reported by onu_status updates
+type ONUStatusChange struct {
+	Online       bool   `json:"online"`
+	WasOnline    *bool  `json:"was_online,omitempty"`    // Previous state; missing when the ONU was not known yet
+	SerialNumber string `json:"serial_number,omitempty"` // Serial number, if polled before
+	Reason       string `json:"reason,omitempty"`        // State reported by the trap, e.g. "LOS"
+}
+
+// PONCounters are the ONU counts and traffic counters of a PON port reported by pon_counters updates
+type PONCounters struct {
+	OnuCount     int            `json:"onu_count"`
+	OnlineCount  int            `json:"online_count"`
+	OfflineCount int            `json:"offline_count"`
+	Statistics   *PONStatistics `json:"statistics,omitempty"` // Missing if the firmware has no PON counters
+}
+
+// MonitoringStreamFilter selects the updates of a stream subscription; zero values match everything
+type MonitoringStreamFilter struct {
+	Board int // Only updates of this board
+	PON   int // Only updates of this PON port (requires Board)
+	ONUID int // Only updates of this ONU (requires Board and PON)
+}
+
+// Matches reports whether an update is selected by the filter.
+// Updates without a board (card events) only match unfiltered subscriptions, PON updates no ONU subscription.
+func (f MonitoringStreamFilter) Matches(update MonitoringUpdate) bool {
+	if f.Board != 0 && update.Board != f.Board {
+		return false
+	}
+	if f.PON != 0 && update.PON != f.PON {
+		return false
+	}
+	if f.ONUID != 0 && update.ONUID != f.ONUID {
+		return false
+	}
+	return true
+}
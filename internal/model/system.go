@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Sources of the detected software version
+const (
+	FirmwareSourceSNMP       = "snmp"       // sysDescr / sysObjectID
+	FirmwareSourceCLI        = "cli"        // "show version"
+	FirmwareSourceConfigured = "configured" // Not detected, ZTE_FIRMWARE_VERSION or the registry entry is used
+)
+
+// SystemInfo describes the OLT model and software version and the OID profile the API reads it with
+type SystemInfo struct {
+	OLTID            string    `json:"olt_id"`                      // OLT identifier
+	Model            string    `json:"model,omitempty"`             // OLT model, e.g. "ZXA10 C320"
+	SoftwareVersion  string    `json:"software_version,omitempty"`  // Software version reported by the OLT, e.g. "V2.1.0"
+	SysDescr         string    `json:"sys_descr,omitempty"`         // SNMPv2-MIB::sysDescr.0
+	SysObjectID      string    `json:"sys_object_id,omitempty"`     // SNMPv2-MIB::sysObjectID.0
+	SysName          string    `json:"sys_name,omitempty"`          // SNMPv2-MIB::sysName.0
+	Uptime           string    `json:"uptime,omitempty"`            // Time since the OLT booted
+	Source           string    `json:"source"`                      // Where the version came from: snmp, cli or configured
+	DetectedFirmware string    `json:"detected_firmware,omitempty"` // Firmware profile matching the software version
+	KnownBuild       bool      `json:"known_build"`                 // Whether the software version matches a verified profile
+	Firmware         string    `json:"firmware"`                    // Firmware profile the API reads the OLT with (v2.1 or v2.2)
+	ProfileName      string    `json:"profile_name"`                // Name of that OID profile
+	Pinned           bool      `json:"pinned"`                      // Profile pinned by configuration instead of detected
+	ProfileMismatch  bool      `json:"profile_mismatch"`            // OLT reports another firmware than the profile: it is pinned or switching failed
+	Warning          string    `json:"warning,omitempty"`           // Why the profile may not match the OLT
+	DetectedAt       time.Time `json:"detected_at"`                 // Time of the last detection
+}
+
+// OLTVersion is the OLT model and software version parsed from sysDescr or "show version"
+type OLTVersion struct {
+	Model   string // e.g. "ZXA10 C320"
+	Version string // e.g. "V2.2.0"
+	Uptime  string // "show version" only, e.g. "0 days 3 hours 25 minutes 8 seconds"
+}
@@ -28,6 +28,7 @@ type TelnetBatchResponse struct {
 
 // TelnetConnectionInfo represents information about a Telnet connection
 type TelnetConnectionInfo struct {
+	Transport  string    `json:"transport"` // "telnet" or "ssh"
 	Host       string    `json:"host"`
 	Port       int       `json:"port"`
 	Connected  bool      `json:"connected"`
@@ -193,10 +194,11 @@ type ONUDeleteRequest struct {
 
 // ONUDeleteResponse represents the response after ONU deletion
 type ONUDeleteResponse struct {
-	PONPort string `json:"pon_port"`
-	ONUID   int    `json:"onu_id"`
-	Success bool   `json:"success"`
-	Message string `json:"message"`
+	PONPort  string             `json:"pon_port"`
+	ONUID    int                `json:"onu_id"`
+	Success  bool               `json:"success"`
+	Message  string             `json:"message"`
+	Snapshot *PreChangeSnapshot `json:"snapshot,omitempty"` // Backup taken before the deletion
 }
 
 // ONUVLANInfo represents VLAN configuration for an ONU
@@ -223,14 +225,15 @@ type VLANConfigRequest struct {
 
 // VLANConfigResponse represents the response after VLAN configuration
 type VLANConfigResponse struct {
-	PONPort       string `json:"pon_port"`
-	ONUID         int    `json:"onu_id"`
-	SVLAN         int    `json:"svlan"`
-	CVLAN         int    `json:"cvlan"`
-	VLANMode      string `json:"vlan_mode"`
-	Success       bool   `json:"success"`
-	Message       string `json:"message"`
-	ServicePortID int    `json:"service_port_id,omitempty"`
+	PONPort       string             `json:"pon_port"`
+	ONUID         int                `json:"onu_id"`
+	SVLAN         int                `json:"svlan"`
+	CVLAN         int                `json:"cvlan"`
+	VLANMode      string             `json:"vlan_mode"`
+	Success       bool               `json:"success"`
+	Message       string             `json:"message"`
+	ServicePortID int                `json:"service_port_id,omitempty"`
+	Snapshot      *PreChangeSnapshot `json:"snapshot,omitempty"` // Backup taken before a modification
 }
 
 // DBAProfileInfo represents DBA (Dynamic Bandwidth Allocation) profile information
@@ -347,62 +350,25 @@ type ONUTarget struct {
 	ONUID   int    `json:"onu_id" validate:"required,min=1,max=128"`
 }
 
-// BatchOperationResult represents the result of a single operation in a batch
-type BatchOperationResult struct {
-	PONPort string `json:"pon_port"`
-	ONUID   int    `json:"onu_id"`
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	Error   string `json:"error,omitempty"`
-}
-
 // BatchONURebootRequest represents a request to reboot multiple ONUs
 type BatchONURebootRequest struct {
-	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=50,dive"`
-}
-
-// BatchONURebootResponse represents the response after batch ONU reboot
-type BatchONURebootResponse struct {
-	TotalTargets    int                    `json:"total_targets"`
-	SuccessCount    int                    `json:"success_count"`
-	FailureCount    int                    `json:"failure_count"`
-	Results         []BatchOperationResult `json:"results"`
-	ExecutionTimeMs int64                  `json:"execution_time_ms"`
+	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=1000,dive"`
 }
 
 // BatchONUBlockRequest represents a request to block/unblock multiple ONUs
 type BatchONUBlockRequest struct {
-	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=50,dive"`
+	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=1000,dive"`
 	Block   bool        `json:"block"` // true=block, false=unblock
 }
 
-// BatchONUBlockResponse represents the response after batch ONU block/unblock
-type BatchONUBlockResponse struct {
-	Blocked         bool                   `json:"blocked"` // Operation type (blocked or unblocked)
-	TotalTargets    int                    `json:"total_targets"`
-	SuccessCount    int                    `json:"success_count"`
-	FailureCount    int                    `json:"failure_count"`
-	Results         []BatchOperationResult `json:"results"`
-	ExecutionTimeMs int64                  `json:"execution_time_ms"`
-}
-
 // BatchONUDeleteRequest represents a request to delete multiple ONUs
 type BatchONUDeleteRequest struct {
-	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=50,dive"`
-}
-
-// BatchONUDeleteResponse represents the response after batch ONU deletion
-type BatchONUDeleteResponse struct {
-	TotalTargets    int                    `json:"total_targets"`
-	SuccessCount    int                    `json:"success_count"`
-	FailureCount    int                    `json:"failure_count"`
-	Results         []BatchOperationResult `json:"results"`
-	ExecutionTimeMs int64                  `json:"execution_time_ms"`
+	Targets []ONUTarget `json:"targets" validate:"required,min=1,max=1000,dive"`
 }
 
 // BatchONUDescriptionRequest represents a request to update descriptions for multiple ONUs
 type BatchONUDescriptionRequest struct {
-	Targets []ONUDescriptionTarget `json:"targets" validate:"required,min=1,max=50,dive"`
+	Targets []ONUDescriptionTarget `json:"targets" validate:"required,min=1,max=1000,dive"`
 }
 
 // ONUDescriptionTarget represents a single ONU with description for batch update
@@ -411,12 +377,3 @@ type ONUDescriptionTarget struct {
 	ONUID       int    `json:"onu_id" validate:"required,min=1,max=128"`
 	Description string `json:"description" validate:"required,max=64"`
 }
-
-// BatchONUDescriptionResponse represents the response after batch description update
-type BatchONUDescriptionResponse struct {
-	TotalTargets    int                    `json:"total_targets"`
-	SuccessCount    int                    `json:"success_count"`
-	FailureCount    int                    `json:"failure_count"`
-	Results         []BatchOperationResult `json:"results"`
-	ExecutionTimeMs int64                  `json:"execution_time_ms"`
-}
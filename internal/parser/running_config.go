@@ -0,0 +1,400 @@
+// Package parser converts ZTE C320 CLI output into typed configuration models.
+package parser
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+var (
+	// interface gpon-olt_1/1/1
+	ponInterfaceRegex = regexp.MustCompile(`^interface\s+gpon-olt_(\d+/\d+/\d+)$`)
+	// interface gpon-onu_1/1/1:1
+	onuInterfaceRegex = regexp.MustCompile(`^interface\s+gpon-onu_(\d+/\d+/\d+):(\d+)$`)
+	// pon-onu-mng gpon-onu_1/1/1:1
+	onuMngRegex = regexp.MustCompile(`^pon-onu-mng\s+gpon-onu_(\d+/\d+/\d+):(\d+)$`)
+	// gpon-onu-profile dba-profile UP-10M
+	dbaProfileBlockRegex = regexp.MustCompile(`^gpon-onu-profile\s+dba-profile\s+(\S+)$`)
+	// vlan 100 / vlan 100,200-205
+	vlanBlockRegex = regexp.MustCompile(`^vlan\s+([\d,-]+)$`)
+
+	// onu 1 type ZTE-F660 sn ZTEGC0FFEE01
+	onuDeclarationRegex = regexp.MustCompile(`^onu\s+(\d+)\s+type\s+(\S+)\s+sn\s+(\S+)`)
+	// onu 1 name "Customer 1" / onu 1 description text
+	onuAttributeRegex = regexp.MustCompile(`^onu\s+(\d+)\s+(name|description)\s+(.+)$`)
+	// onu 1 state disable
+	onuStateRegex = regexp.MustCompile(`^onu\s+(\d+)\s+state\s+(enable|disable)`)
+	// tcont 1 name TCONT_DATA profile UP-10M
+	tcontLineRegex = regexp.MustCompile(`^tcont\s+(\d+)(?:\s+name\s+(\S+))?\s+profile\s+(\S+)`)
+	// gemport 1 name GEM_DATA tcont 1 [queue 1]
+	gemportLineRegex = regexp.MustCompile(`^gemport\s+(\d+)(?:\s+name\s+(\S+))?\s+(?:unicast\s+)?tcont\s+(\d+)`)
+	// service-port 1 vport 1 user-vlan 100 vlan 100
+	servicePortLineRegex = regexp.MustCompile(`^service-port\s+(\d+)\s+vport\s+(\d+)\s+user-vlan\s+(\S+)\s+vlan\s+(\d+)`)
+	// type 3 assure 5120 max 10240 (inside gpon-onu-profile dba-profile)
+	dbaTypeLineRegex = regexp.MustCompile(`^type\s+(\d)\b(.*)$`)
+	// profile tcont UP-10M type 4 maximum 10240 (inside gpon)
+	tcontProfileLineRegex = regexp.MustCompile(`^profile\s+tcont\s+(\S+)\s+type\s+(\d)\b(.*)$`)
+	// service INTERNET gemport 1 vlan 100
+	mngServiceRegex = regexp.MustCompile(`^service\s+(\S+)\s+gemport\s+(\d+)(?:.*\svlan\s+(\d+))?`)
+	// vlan port eth_0/1 mode tag vlan 100
+	mngVLANPortRegex = regexp.MustCompile(`^vlan\s+port\s+(\S+)\s+mode\s+(\S+)(?:\s+vlan\s+(\d+))?`)
+)
+
+// block types of the running-config
+const (
+	blockNone = iota
+	blockPON
+	blockONU
+	blockONUMng
+	blockDBAProfile
+	blockVLAN
+	blockGPON
+	blockOther
+)
+
+// ParseRunningConfig parses the full "show running-config" output.
+// Blocks are recognised by their header line, so the parser does not rely on indentation.
+func ParseRunningConfig(output string) *model.RunningConfig {
+	cfg := &model.RunningConfig{
+		PONPorts: []model.PONRunningConfig{},
+		ONUs:     []model.ONURunningConfig{},
+	}
+
+	block := blockNone
+	var pon *model.PONRunningConfig
+	var onu *model.ONURunningConfig
+	var mng *model.ONUManagementConfig
+	var dba *model.DBAProfileConfig
+	var vlans []int
+	ponIndex := make(map[int]int)  // onuID -> position in pon.ONUs
+	vlanIndex := make(map[int]int) // VLAN ID -> position in cfg.VLANs
+
+	// flush appends the block being parsed to the result
+	flush := func() {
+		switch block {
+		case blockPON:
+			cfg.PONPorts = append(cfg.PONPorts, *pon)
+		case blockONU:
+			cfg.ONUs = append(cfg.ONUs, *onu)
+		case blockONUMng:
+			cfg.ONUManagement = append(cfg.ONUManagement, *mng)
+		case blockDBAProfile:
+			cfg.DBAProfiles = append(cfg.DBAProfiles, *dba)
+		}
+		block = blockNone
+	}
+
+	for _, rawLine := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" || strings.HasPrefix(line, "Building configuration") {
+			continue
+		}
+		if line == "!" || line == "end" || line == "exit" {
+			flush()
+			continue
+		}
+
+		// Block headers
+		if matches := ponInterfaceRegex.FindStringSubmatch(line); matches != nil {
+			flush()
+			block = blockPON
+			pon = &model.PONRunningConfig{PONPort: matches[1], ONUs: []model.ONUDeclaration{}}
+			ponIndex = make(map[int]int)
+			continue
+		}
+		if matches := onuInterfaceRegex.FindStringSubmatch(line); matches != nil {
+			flush()
+			block = blockONU
+			onuID, _ := strconv.Atoi(matches[2])
+			onu = &model.ONURunningConfig{PONPort: matches[1], ONUID: onuID}
+			continue
+		}
+		if matches := onuMngRegex.FindStringSubmatch(line); matches != nil {
+			flush()
+			block = blockONUMng
+			onuID, _ := strconv.Atoi(matches[2])
+			mng = &model.ONUManagementConfig{PONPort: matches[1], ONUID: onuID}
+			continue
+		}
+		if matches := dbaProfileBlockRegex.FindStringSubmatch(line); matches != nil {
+			flush()
+			block = blockDBAProfile
+			dba = &model.DBAProfileConfig{Name: matches[1]}
+			continue
+		}
+		if block != blockONUMng {
+			if matches := vlanBlockRegex.FindStringSubmatch(line); matches != nil {
+				flush()
+				block = blockVLAN
+				vlans = expandVLANList(matches[1])
+				for _, id := range vlans {
+					if _, ok := vlanIndex[id]; !ok {
+						vlanIndex[id] = len(cfg.VLANs)
+						cfg.VLANs = append(cfg.VLANs, model.GlobalVLANConfig{VLANID: id})
+					}
+				}
+				continue
+			}
+		}
+		if line == "gpon" {
+			flush()
+			block = blockGPON
+			continue
+		}
+		if strings.HasPrefix(line, "interface ") {
+			flush()
+			block = blockOther
+			continue
+		}
+
+		// Block contents
+		switch block {
+		case blockPON:
+			parsePONLine(pon, ponIndex, line)
+		case blockONU:
+			parseONULine(onu, line)
+		case blockONUMng:
+			parseONUMngLine(mng, line)
+		case blockDBAProfile:
+			if matches := dbaTypeLineRegex.FindStringSubmatch(line); matches != nil {
+				applyBandwidth(dba, matches[1], matches[2])
+			}
+		case blockVLAN:
+			// Names only apply to single-VLAN blocks
+			if len(vlans) == 1 {
+				vlan := &cfg.VLANs[vlanIndex[vlans[0]]]
+				if value, ok := cutKeyword(line, "name"); ok {
+					vlan.Name = value
+				} else if value, ok := cutKeyword(line, "description"); ok {
+					vlan.Description = value
+				}
+			}
+		case blockGPON:
+			if matches := tcontProfileLineRegex.FindStringSubmatch(line); matches != nil {
+				profile := model.DBAProfileConfig{Name: matches[1]}
+				applyBandwidth(&profile, matches[2], matches[3])
+				cfg.DBAProfiles = append(cfg.DBAProfiles, profile)
+			}
+		}
+	}
+	flush()
+
+	return cfg
+}
+
+// ParsePONDeclarations parses ONU declarations from "show running-config interface gpon-olt_x/y/z".
+// The interface header is optional.
+func ParsePONDeclarations(output string) []model.ONUDeclaration {
+	pon := &model.PONRunningConfig{ONUs: []model.ONUDeclaration{}}
+	index := make(map[int]int)
+
+	for _, line := range strings.Split(output, "\n") {
+		parsePONLine(pon, index, strings.TrimSpace(line))
+	}
+
+	return pon.ONUs
+}
+
+// ParseONUConfig parses TCONT, GEM port and service-port lines from
+// "show running-config interface gpon-onu_x/y/z:n". The interface header is optional.
+func ParseONUConfig(output string) *model.ONURunningConfig {
+	onu := &model.ONURunningConfig{}
+
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if matches := onuInterfaceRegex.FindStringSubmatch(line); matches != nil {
+			onu.PONPort = matches[1]
+			onu.ONUID, _ = strconv.Atoi(matches[2])
+			continue
+		}
+		parseONULine(onu, line)
+	}
+
+	return onu
+}
+
+// parsePONLine parses a line inside interface gpon-olt_x/y/z
+func parsePONLine(pon *model.PONRunningConfig, index map[int]int, line string) {
+	if matches := onuDeclarationRegex.FindStringSubmatch(line); matches != nil {
+		onuID, _ := strconv.Atoi(matches[1])
+		index[onuID] = len(pon.ONUs)
+		pon.ONUs = append(pon.ONUs, model.ONUDeclaration{
+			ONUID:        onuID,
+			Type:         matches[2],
+			SerialNumber: matches[3],
+			AdminState:   "enabled",
+		})
+		return
+	}
+
+	if matches := onuAttributeRegex.FindStringSubmatch(line); matches != nil {
+		onuID, _ := strconv.Atoi(matches[1])
+		pos, ok := index[onuID]
+		if !ok {
+			return
+		}
+		value := unquote(matches[3])
+		if matches[2] == "name" {
+			pon.ONUs[pos].Name = value
+		} else {
+			pon.ONUs[pos].Description = value
+		}
+		return
+	}
+
+	if matches := onuStateRegex.FindStringSubmatch(line); matches != nil {
+		onuID, _ := strconv.Atoi(matches[1])
+		if pos, ok := index[onuID]; ok && matches[2] == "disable" {
+			pon.ONUs[pos].AdminState = "disabled"
+		}
+		return
+	}
+
+	if value, ok := cutKeyword(line, "name"); ok {
+		pon.Name = value
+	} else if value, ok := cutKeyword(line, "description"); ok {
+		pon.Description = value
+	}
+}
+
+// parseONULine parses a line inside interface gpon-onu_x/y/z:n
+func parseONULine(onu *model.ONURunningConfig, line string) {
+	if value, ok := cutKeyword(line, "name"); ok {
+		onu.Name = value
+		return
+	}
+	if value, ok := cutKeyword(line, "description"); ok {
+		onu.Description = value
+		return
+	}
+
+	if matches := tcontLineRegex.FindStringSubmatch(line); matches != nil {
+		tcontID, _ := strconv.Atoi(matches[1])
+		onu.TCONTs = append(onu.TCONTs, model.ONUTCONTConfig{
+			TCONTID:     tcontID,
+			Name:        matches[2],
+			ProfileName: matches[3],
+		})
+		return
+	}
+
+	if matches := gemportLineRegex.FindStringSubmatch(line); matches != nil {
+		gemportID, _ := strconv.Atoi(matches[1])
+		tcontID, _ := strconv.Atoi(matches[3])
+		onu.GEMPorts = append(onu.GEMPorts, model.ONUGEMPortConfig{
+			GEMPortID: gemportID,
+			Name:      matches[2],
+			TCONTID:   tcontID,
+		})
+		return
+	}
+
+	if matches := servicePortLineRegex.FindStringSubmatch(line); matches != nil {
+		portID, _ := strconv.Atoi(matches[1])
+		vport, _ := strconv.Atoi(matches[2])
+		userVLAN, _ := strconv.Atoi(matches[3]) // "untagged" becomes 0
+		serviceVLAN, _ := strconv.Atoi(matches[4])
+		onu.ServicePorts = append(onu.ServicePorts, model.ONUServicePortConfig{
+			PortID:      portID,
+			VPort:       vport,
+			UserVLAN:    userVLAN,
+			ServiceVLAN: serviceVLAN,
+			GEMPortID:   vport, // vport N is bound to gemport N on the C320
+		})
+	}
+}
+
+// parseONUMngLine parses a line inside pon-onu-mng gpon-onu_x/y/z:n
+func parseONUMngLine(mng *model.ONUManagementConfig, line string) {
+	mng.Lines = append(mng.Lines, line)
+
+	if matches := mngServiceRegex.FindStringSubmatch(line); matches != nil {
+		gemportID, _ := strconv.Atoi(matches[2])
+		vlan, _ := strconv.Atoi(matches[3])
+		mng.Services = append(mng.Services, model.ONUMngService{
+			Name:      matches[1],
+			GEMPortID: gemportID,
+			VLAN:      vlan,
+		})
+		return
+	}
+
+	if matches := mngVLANPortRegex.FindStringSubmatch(line); matches != nil {
+		vlan, _ := strconv.Atoi(matches[3])
+		mng.VLANPorts = append(mng.VLANPorts, model.ONUMngVLANPort{
+			Port: matches[1],
+			Mode: matches[2],
+			VLAN: vlan,
+		})
+	}
+}
+
+// applyBandwidth parses "fix|fixed N", "assure|assured N" and "max|maximum N" into a DBA profile
+func applyBandwidth(profile *model.DBAProfileConfig, profileType, params string) {
+	profile.Type = profileType
+
+	fields := strings.Fields(params)
+	for i := 0; i+1 < len(fields); i++ {
+		value, err := strconv.Atoi(fields[i+1])
+		if err != nil {
+			continue
+		}
+		switch fields[i] {
+		case "fix", "fixed":
+			profile.Fixed = value
+		case "assure", "assured":
+			profile.Assured = value
+		case "max", "maximum":
+			profile.Maximum = value
+		default:
+			continue
+		}
+		i++
+	}
+}
+
+// expandVLANList expands "100,200-202" into its VLAN IDs
+func expandVLANList(list string) []int {
+	var ids []int
+	for _, part := range strings.Split(list, ",") {
+		bounds := strings.SplitN(part, "-", 2)
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil {
+			continue
+		}
+		end := start
+		if len(bounds) == 2 {
+			if end, err = strconv.Atoi(bounds[1]); err != nil || end < start {
+				continue
+			}
+		}
+		for id := start; id <= end && id <= 4094; id++ {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// cutKeyword returns the unquoted value of a "<keyword> <value>" line
+func cutKeyword(line, keyword string) (string, bool) {
+	if !strings.HasPrefix(line, keyword+" ") {
+		return "", false
+	}
+	return unquote(strings.TrimPrefix(line, keyword+" ")), true
+}
+
+// unquote trims whitespace and surrounding double quotes from a CLI value, unescaping \" and \\
+// inside them. A quoted value that is not a valid Go string literal only loses its quotes.
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestParsePONDeclarations(t *testing.T) {
+	output := `show running-config interface gpon-olt_1/1/1
+Building configuration...
+interface gpon-olt_1/1/1
+  onu 1 type ZTE-F660 sn ZTEGC0FFEE01
+  onu 2 type ZTE-F609 sn ZTEGC0FFEE02
+  onu 1 name "Customer One"
+  onu 2 description backbone
+  onu 2 state disable
+!
+end`
+
+	declarations := ParsePONDeclarations(output)
+	if len(declarations) != 2 {
+		t.Fatalf("Expected 2 declarations, got %d", len(declarations))
+	}
+
+	first := declarations[0]
+	if first.ONUID != 1 || first.Type != "ZTE-F660" || first.SerialNumber != "ZTEGC0FFEE01" {
+		t.Errorf("Unexpected first declaration: %+v", first)
+	}
+	if first.Name != "Customer One" {
+		t.Errorf("Expected name 'Customer One', got %q", first.Name)
+	}
+	if first.AdminState != "enabled" {
+		t.Errorf("Expected admin state enabled, got %s", first.AdminState)
+	}
+
+	second := declarations[1]
+	if second.Description != "backbone" {
+		t.Errorf("Expected description 'backbone', got %q", second.Description)
+	}
+	if second.AdminState != "disabled" {
+		t.Errorf("Expected admin state disabled, got %s", second.AdminState)
+	}
+}
+
+func TestParseONUConfig(t *testing.T) {
+	output := `interface gpon-onu_1/1/1:1
+  name Customer_1
+  tcont 1 name TCONT_DATA profile UP-10M
+  tcont 2 profile UP-VOIP
+  gemport 1 name GEM_DATA tcont 1 queue 1
+  gemport 2 tcont 2
+  service-port 1 vport 1 user-vlan 100 vlan 100
+  service-port 2 vport 2 user-vlan untagged vlan 200
+!`
+
+	cfg := ParseONUConfig(output)
+
+	if cfg.Name != "Customer_1" {
+		t.Errorf("Expected name Customer_1, got %q", cfg.Name)
+	}
+	if len(cfg.TCONTs) != 2 {
+		t.Fatalf("Expected 2 TCONTs, got %d", len(cfg.TCONTs))
+	}
+	if cfg.TCONTs[0].Name != "TCONT_DATA" || cfg.TCONTs[0].ProfileName != "UP-10M" {
+		t.Errorf("Unexpected TCONT 1: %+v", cfg.TCONTs[0])
+	}
+	if cfg.TCONTs[1].TCONTID != 2 || cfg.TCONTs[1].ProfileName != "UP-VOIP" {
+		t.Errorf("Unexpected TCONT 2: %+v", cfg.TCONTs[1])
+	}
+
+	if len(cfg.GEMPorts) != 2 {
+		t.Fatalf("Expected 2 GEM ports, got %d", len(cfg.GEMPorts))
+	}
+	if cfg.GEMPorts[0].GEMPortID != 1 || cfg.GEMPorts[0].TCONTID != 1 || cfg.GEMPorts[0].Name != "GEM_DATA" {
+		t.Errorf("Unexpected GEM port 1: %+v", cfg.GEMPorts[0])
+	}
+
+	if len(cfg.ServicePorts) != 2 {
+		t.Fatalf("Expected 2 service ports, got %d", len(cfg.ServicePorts))
+	}
+	if cfg.ServicePorts[0].UserVLAN != 100 || cfg.ServicePorts[0].ServiceVLAN != 100 {
+		t.Errorf("Unexpected service port 1: %+v", cfg.ServicePorts[0])
+	}
+	if cfg.ServicePorts[1].UserVLAN != 0 || cfg.ServicePorts[1].ServiceVLAN != 200 || cfg.ServicePorts[1].VPort != 2 {
+		t.Errorf("Unexpected service port 2: %+v", cfg.ServicePorts[1])
+	}
+}
+
+func TestParseRunningConfig(t *testing.T) {
+	output := `show running-config
+Building configuration...
+!
+vlan 100
+  name INTERNET
+  description "Internet service"
+!
+vlan 200-202
+!
+vlan 201
+  name VOIP
+!
+gpon
+  profile tcont UP-VOIP type 2 assured 1024
+!
+gpon-onu-profile dba-profile UP-10M
+  type 4 max 10240
+!
+interface gpon-olt_1/1/1
+  name uplink-a
+  onu 1 type ZTE-F660 sn ZTEGC0FFEE01
+  onu 1 name "Customer One"
+!
+interface gpon-olt_1/1/2
+!
+interface gpon-onu_1/1/1:1
+  name Customer_1
+  tcont 1 name TCONT_DATA profile UP-10M
+  gemport 1 name GEM_DATA tcont 1
+  service-port 1 vport 1 user-vlan 100 vlan 100
+!
+pon-onu-mng gpon-onu_1/1/1:1
+  service INTERNET gemport 1 vlan 100
+  vlan port eth_0/1 mode tag vlan 100
+  wan-ip 1 mode dhcp vlan-profile INTERNET host 1
+!
+interface gei_1/4/1
+  switchport vlan 100 tag
+!
+end`
+
+	cfg := ParseRunningConfig(output)
+
+	if len(cfg.VLANs) != 4 {
+		t.Fatalf("Expected 4 VLANs, got %d: %+v", len(cfg.VLANs), cfg.VLANs)
+	}
+	if cfg.VLANs[0].VLANID != 100 || cfg.VLANs[0].Name != "INTERNET" || cfg.VLANs[0].Description != "Internet service" {
+		t.Errorf("Unexpected VLAN 100: %+v", cfg.VLANs[0])
+	}
+	if cfg.VLANs[2].VLANID != 201 || cfg.VLANs[2].Name != "VOIP" {
+		t.Errorf("Expected VLAN 201 named once declared again, got %+v", cfg.VLANs[2])
+	}
+	if cfg.VLANs[3].VLANID != 202 {
+		t.Errorf("Expected VLAN range to expand to 202, got %+v", cfg.VLANs[3])
+	}
+
+	if len(cfg.DBAProfiles) != 2 {
+		t.Fatalf("Expected 2 DBA profiles, got %d", len(cfg.DBAProfiles))
+	}
+	if p := cfg.DBAProfiles[0]; p.Name != "UP-VOIP" || p.Type != "2" || p.Assured != 1024 {
+		t.Errorf("Unexpected gpon tcont profile: %+v", p)
+	}
+	if p := cfg.DBAProfiles[1]; p.Name != "UP-10M" || p.Type != "4" || p.Maximum != 10240 {
+		t.Errorf("Unexpected dba-profile: %+v", p)
+	}
+
+	if len(cfg.PONPorts) != 2 {
+		t.Fatalf("Expected 2 PON ports, got %d", len(cfg.PONPorts))
+	}
+	pon := cfg.PON("1/1/1")
+	if pon == nil || pon.Name != "uplink-a" || len(pon.ONUs) != 1 || pon.ONUs[0].Name != "Customer One" {
+		t.Errorf("Unexpected PON 1/1/1: %+v", pon)
+	}
+	if empty := cfg.PON("1/1/2"); empty == nil || len(empty.ONUs) != 0 {
+		t.Errorf("Expected empty PON 1/1/2, got %+v", empty)
+	}
+
+	onu := cfg.ONU("1/1/1", 1)
+	if onu == nil {
+		t.Fatal("Expected ONU 1/1/1:1")
+	}
+	if onu.Name != "Customer_1" || len(onu.TCONTs) != 1 || len(onu.GEMPorts) != 1 || len(onu.ServicePorts) != 1 {
+		t.Errorf("Unexpected ONU config: %+v", onu)
+	}
+	if cfg.ONU("1/1/1", 2) != nil {
+		t.Error("Expected no ONU 1/1/1:2")
+	}
+
+	if len(cfg.ONUManagement) != 1 {
+		t.Fatalf("Expected 1 pon-onu-mng block, got %d", len(cfg.ONUManagement))
+	}
+	mng := cfg.ONUManagement[0]
+	if mng.PONPort != "1/1/1" || mng.ONUID != 1 || len(mng.Lines) != 3 {
+		t.Errorf("Unexpected pon-onu-mng block: %+v", mng)
+	}
+	if len(mng.Services) != 1 || mng.Services[0].Name != "INTERNET" || mng.Services[0].GEMPortID != 1 || mng.Services[0].VLAN != 100 {
+		t.Errorf("Unexpected services: %+v", mng.Services)
+	}
+	if len(mng.VLANPorts) != 1 || mng.VLANPorts[0].Port != "eth_0/1" || mng.VLANPorts[0].Mode != "tag" {
+		t.Errorf("Unexpected VLAN ports: %+v", mng.VLANPorts)
+	}
+}
+
+func TestExpandVLANList(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int
+	}{
+		{"100", 1},
+		{"100,200", 2},
+		{"100-104", 5},
+		{"10,20-21", 3},
+		{"5-1", 0},
+	}
+
+	for _, tt := range tests {
+		if got := expandVLANList(tt.input); len(got) != tt.want {
+			t.Errorf("expandVLANList(%q) = %v, want %d IDs", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUnquote(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{`plain`, `plain`},
+		{` "Customer One" `, `Customer One`},
+		{`"say \"hi\""`, `say "hi"`},
+		{`"back\\slash"`, `back\slash`},
+		{`"C:\path"`, `C:\path`}, // Not a valid escape: only the quotes are removed
+		{`"`, `"`},
+	}
+
+	for _, tt := range tests {
+		if got := unquote(tt.value); got != tt.want {
+			t.Errorf("unquote(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+var (
+	// ZXA10 C320 / ZTE ZXA10 C300
+	oltModelRegex = regexp.MustCompile(`\b(ZXA10\s+C\d{3}\w*)`)
+	// Version: V2.1.0 / Version V2.2.1P2
+	softwareVersionRegex = regexp.MustCompile(`(?i)\bversion\s*:?\s*(V\d+\.\d+[\w.]*)`)
+	// A bare version number, e.g. "ZXA10 C320 V2.1.0"
+	bareVersionRegex = regexp.MustCompile(`\b(V\d+\.\d+\.\d+\w*)\b`)
+	// System uptime is 0 days 3 hours 25 minutes 8 seconds
+	uptimeRegex = regexp.MustCompile(`(?i)uptime\s+is\s+(.+)`)
+)
+
+// ParseVersion extracts the OLT model and software version from sysDescr or the "show version" output.
+// Fields that are not found are left empty.
+func ParseVersion(output string) *model.OLTVersion {
+	version := &model.OLTVersion{}
+
+	if m := oltModelRegex.FindStringSubmatch(output); m != nil {
+		version.Model = strings.Join(strings.Fields(m[1]), " ")
+	}
+	if m := softwareVersionRegex.FindStringSubmatch(output); m != nil {
+		version.Version = strings.TrimRight(m[1], ".")
+	} else if m := bareVersionRegex.FindStringSubmatch(output); m != nil {
+		version.Version = m[1]
+	}
+	if m := uptimeRegex.FindStringSubmatch(output); m != nil {
+		version.Uptime = strings.TrimSpace(m[1])
+	}
+
+	return version
+}
@@ -0,0 +1,52 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   model.OLTVersion
+	}{
+		{
+			name:   "sysDescr",
+			output: "ZXA10 C320, ZTE ZXA10 C320 Software, Version: V2.1.0",
+			want:   model.OLTVersion{Model: "ZXA10 C320", Version: "V2.1.0"},
+		},
+		{
+			name: "show version",
+			output: "ZTE ZXA10 C320 Software, Version: V2.2.1P2, Release software\n" +
+				"Copyright (c) 2013 by ZTE Corporation\n" +
+				"System uptime is 3 days 4 hours 5 minutes 6 seconds",
+			want: model.OLTVersion{Model: "ZXA10 C320", Version: "V2.2.1P2", Uptime: "3 days 4 hours 5 minutes 6 seconds"},
+		},
+		{
+			name:   "bare version",
+			output: "ZXA10 C300 V1.2.5",
+			want:   model.OLTVersion{Model: "ZXA10 C300", Version: "V1.2.5"},
+		},
+		{
+			name:   "no version",
+			output: "ZXA10 C320",
+			want:   model.OLTVersion{Model: "ZXA10 C320"},
+		},
+		{
+			name:   "empty",
+			output: "",
+			want:   model.OLTVersion{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseVersion(tt.output); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseVersion() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
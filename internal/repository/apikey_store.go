@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// Redis keys of the API key store
+const (
+	apiKeyPrefix = "auth:apikey:" // auth:apikey:<id> holds the JSON of a key including its hash
+	apiKeySetKey = "auth:apikeys" // Set of all key IDs
+)
+
+// APIKeyStore persists issued API keys
+type APIKeyStore interface {
+	// Save creates or replaces a key
+	Save(ctx context.Context, key *model.APIKey) error
+
+	// Get returns a key by ID
+	Get(ctx context.Context, id string) (*model.APIKey, error)
+
+	// List returns all keys, including revoked ones, oldest first
+	List(ctx context.Context) ([]*model.APIKey, error)
+}
+
+// redisAPIKeyStore keeps API keys in Redis without expiry
+type redisAPIKeyStore struct {
+	redisClient *redis.Client
+}
+
+// storedAPIKey is the Redis representation of a key; model.APIKey hides the hash from API responses
+type storedAPIKey struct {
+	model.APIKey
+	Hash string `json:"hash"`
+}
+
+// NewRedisAPIKeyStore creates an API key store on the given Redis client.
+// Keys are shared by all OLTs, so the store does not use an OLT key prefix.
+func NewRedisAPIKeyStore(redisClient *redis.Client) APIKeyStore {
+	return &redisAPIKeyStore{redisClient: redisClient}
+}
+
+// Save creates or replaces a key
+func (s *redisAPIKeyStore) Save(ctx context.Context, key *model.APIKey) error {
+	data, err := json.Marshal(storedAPIKey{APIKey: *key, Hash: key.Hash})
+	if err != nil {
+		return apperrors.NewInternalError("failed to marshal API key", err)
+	}
+
+	if err := s.redisClient.Set(ctx, apiKeyPrefix+key.ID, data, 0).Err(); err != nil {
+		return apperrors.NewRedisError("Set", err)
+	}
+	if err := s.redisClient.SAdd(ctx, apiKeySetKey, key.ID).Err(); err != nil {
+		return apperrors.NewRedisError("SAdd", err)
+	}
+
+	return nil
+}
+
+// Get returns a key by ID
+func (s *redisAPIKeyStore) Get(ctx context.Context, id string) (*model.APIKey, error) {
+	data, err := s.redisClient.Get(ctx, apiKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.NewNotFoundError("API key", id)
+	}
+	if err != nil {
+		return nil, apperrors.NewRedisError("Get", err)
+	}
+
+	return decodeAPIKey(data)
+}
+
+// List returns all keys, including revoked ones, oldest first
+func (s *redisAPIKeyStore) List(ctx context.Context) ([]*model.APIKey, error) {
+	ids, err := s.redisClient.SMembers(ctx, apiKeySetKey).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError("SMembers", err)
+	}
+
+	keys := make([]*model.APIKey, 0, len(ids))
+	if len(ids) == 0 {
+		return keys, nil
+	}
+
+	redisKeys := make([]string, len(ids))
+	for i, id := range ids {
+		redisKeys[i] = apiKeyPrefix + id
+	}
+	values, err := s.redisClient.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError("MGet", err)
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // Removed from Redis but still in the set
+		}
+		key, err := decodeAPIKey([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+// decodeAPIKey parses a stored key and restores its hash
+func decodeAPIKey(data []byte) (*model.APIKey, error) {
+	var stored storedAPIKey
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, apperrors.NewInternalError("failed to unmarshal API key", err)
+	}
+
+	key := stored.APIKey
+	key.Hash = stored.Hash
+	return &key, nil
+}
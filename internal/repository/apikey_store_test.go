@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestRedisAPIKeyStore_SaveAndGet(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	store := NewRedisAPIKeyStore(db)
+	ctx := context.Background()
+
+	key := &model.APIKey{
+		ID:        "0123456789abcdef",
+		Name:      "noc",
+		Scopes:    []string{model.ScopeReadONU},
+		PONs:      []model.BoardPON{{Board: 1, PON: 3}},
+		CreatedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Hash:      "5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+	}
+	data, _ := json.Marshal(storedAPIKey{APIKey: *key, Hash: key.Hash})
+
+	mock.ExpectSet("auth:apikey:0123456789abcdef", data, 0).SetVal("OK")
+	mock.ExpectSAdd("auth:apikeys", "0123456789abcdef").SetVal(1)
+	if err := store.Save(ctx, key); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	mock.ExpectGet("auth:apikey:0123456789abcdef").SetVal(string(data))
+	got, err := store.Get(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got.Hash != key.Hash || got.Name != "noc" || len(got.PONs) != 1 || got.PONs[0].PON != 3 {
+		t.Errorf("Get() = %+v, want the saved key with its hash", got)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRedisAPIKeyStore_HashNotInResponses(t *testing.T) {
+	data, _ := json.Marshal(&model.APIKey{ID: "a", Hash: "secret-hash"})
+	if string(data) != `{"id":"a","name":"","scopes":null,"created_at":"0001-01-01T00:00:00Z"}` {
+		t.Errorf("json.Marshal(APIKey) = %s, want no hash", data)
+	}
+}
+
+func TestRedisAPIKeyStore_GetErrors(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	store := NewRedisAPIKeyStore(db)
+	ctx := context.Background()
+
+	mock.ExpectGet("auth:apikey:missing").RedisNil()
+	_, err := store.Get(ctx, "missing")
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("Get(missing) error = %v, want not found", err)
+	}
+
+	mock.ExpectGet("auth:apikey:down").SetErr(errors.New("connection refused"))
+	_, err = store.Get(ctx, "down")
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeRedis {
+		t.Errorf("Get(down) error = %v, want Redis error", err)
+	}
+}
+
+func TestRedisAPIKeyStore_List(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	store := NewRedisAPIKeyStore(db)
+
+	older, _ := json.Marshal(storedAPIKey{APIKey: model.APIKey{ID: "b", CreatedAt: time.Unix(100, 0)}})
+	newer, _ := json.Marshal(storedAPIKey{APIKey: model.APIKey{ID: "a", CreatedAt: time.Unix(200, 0)}})
+
+	mock.ExpectSMembers("auth:apikeys").SetVal([]string{"a", "b", "gone"})
+	mock.ExpectMGet("auth:apikey:a", "auth:apikey:b", "auth:apikey:gone").SetVal([]interface{}{string(newer), string(older), nil})
+
+	keys, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(keys) != 2 || keys[0].ID != "b" || keys[1].ID != "a" {
+		t.Errorf("List() = %+v, want b and a, oldest first", keys)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
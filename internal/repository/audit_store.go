@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// ErrCorruptAuditLog is returned by AuditStore.Scan for a line that is not a valid audit entry
+var ErrCorruptAuditLog = errors.New("corrupt audit log")
+
+// ErrAuditEntryTooLarge is returned by AuditStore.Append for an entry longer than MaxAuditEntrySize
+var ErrAuditEntryTooLarge = errors.New("audit entry too large")
+
+// MaxAuditEntrySize limits the JSON of an audit entry, which holds the raw OLT output of all its
+// commands, including the line break. Longer lines could not be read back.
+const MaxAuditEntrySize = 16 << 20
+
+// AuditStore persists the audit log. Entries can only be appended, never changed or removed.
+type AuditStore interface {
+	// Append adds an entry at the end of the log and returns once it is on disk
+	Append(ctx context.Context, entry *model.AuditEntry) error
+
+	// Scan calls fn for every entry, oldest first, until fn returns an error
+	Scan(ctx context.Context, fn func(entry *model.AuditEntry) error) error
+
+	// ScanReverse calls fn for every entry, newest first, until fn returns an error
+	ScanReverse(ctx context.Context, fn func(entry *model.AuditEntry) error) error
+
+	// Segments lists the files of the log, oldest first. All but the last one are rotated and no longer written.
+	Segments() ([]AuditSegment, error)
+
+	// ScanSegment calls fn for every entry of a segment, oldest first, until fn returns an error
+	ScanSegment(ctx context.Context, segment AuditSegment, fn func(entry *model.AuditEntry) error) error
+
+	// Close closes the log
+	Close() error
+}
+
+// AuditSegment is one file of the audit log
+type AuditSegment struct {
+	Path    string
+	Size    int64
+	ModTime time.Time
+	Active  bool // Still written; the others are rotated
+
+	info os.FileInfo // Identifies the file once it is renamed by a rotation
+}
+
+// fileAuditStore keeps the audit log as JSON Lines files, one entry per line. The active file is
+// renamed to path.N (N counting up from 1) once it reaches maxSize, and a new one is started.
+type fileAuditStore struct {
+	mu      sync.Mutex
+	path    string
+	maxSize int64    // Size at which the file is rotated; 0 never rotates it
+	file    *os.File // Opened for appending
+	size    int64    // Size of file
+}
+
+// NewFileAuditStore opens the audit log at path for appending, creating the file and its
+// directory if needed. The files are readable by the owner only. Once the file would grow beyond
+// maxSize bytes it is rotated; 0 keeps a single file.
+func NewFileAuditStore(path string, maxSize int64) (AuditStore, error) {
+	if path == "" {
+		return nil, fmt.Errorf("audit log file is not configured")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	s := &fileAuditStore{path: path, maxSize: maxSize}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens the active file for appending
+func (s *fileAuditStore) open() error {
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// Append writes the entry as a single line and syncs the file, rotating the file first if the entry
+// would take it beyond maxSize. Entries longer than MaxAuditEntrySize are rejected with
+// ErrAuditEntryTooLarge, as they would end every later Scan.
+func (s *fileAuditStore) Append(_ context.Context, entry *model.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	if len(data)+1 > MaxAuditEntrySize {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrAuditEntryTooLarge, len(data)+1, MaxAuditEntrySize)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(data))+1 > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	s.size += int64(len(data)) + 1
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+	return nil
+}
+
+// rotate renames the active file to the next free path.N and starts a new one. The chain continues
+// in the new file: its first entry links to the last entry of the rotated one.
+func (s *fileAuditStore) rotate() error {
+	rotated, err := s.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	next := 1
+	if len(rotated) > 0 {
+		next = rotated[len(rotated)-1].number + 1
+	}
+
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log: %w", err)
+	}
+	if err := os.Rename(s.path, fmt.Sprintf("%s.%d", s.path, next)); err != nil {
+		// Keep appending to the old file rather than losing entries
+		if openErr := s.open(); openErr != nil {
+			return fmt.Errorf("failed to rotate audit log: %w", openErr)
+		}
+		log.Error().Err(err).Str("file", s.path).Msg("Failed to rotate audit log")
+		return nil
+	}
+	if err := s.open(); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+	syncDir(filepath.Dir(s.path))
+	return nil
+}
+
+// syncDir flushes a rename in dir to disk
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
+
+// rotatedFile is a rotated file of the log with the number of its path suffix
+type rotatedFile struct {
+	path   string
+	number int
+}
+
+// rotatedFiles lists the rotated files, oldest first
+func (s *fileAuditStore) rotatedFiles() ([]rotatedFile, error) {
+	entries, err := os.ReadDir(filepath.Dir(s.path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit log files: %w", err)
+	}
+	prefix := filepath.Base(s.path) + "."
+	var files []rotatedFile
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), prefix)
+		if !ok || entry.IsDir() {
+			continue
+		}
+		number, err := strconv.Atoi(suffix)
+		if err != nil || number < 1 || strconv.Itoa(number) != suffix {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(filepath.Dir(s.path), entry.Name()), number: number})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].number < files[j].number })
+	return files, nil
+}
+
+// Segments lists the rotated files and the active one
+func (s *fileAuditStore) Segments() ([]AuditSegment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rotated, err := s.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	segments := make([]AuditSegment, 0, len(rotated)+1)
+	for _, file := range rotated {
+		info, err := os.Stat(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+		segments = append(segments, newAuditSegment(file.path, info, false))
+	}
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return append(segments, newAuditSegment(s.path, info, true)), nil
+}
+
+func newAuditSegment(path string, info os.FileInfo, active bool) AuditSegment {
+	return AuditSegment{Path: path, Size: info.Size(), ModTime: info.ModTime(), Active: active, info: info}
+}
+
+// openSegment opens the file of a segment, following it if it was rotated since it was listed
+func (s *fileAuditStore) openSegment(segment AuditSegment) (*os.File, error) {
+	file, err := os.Open(segment.Path)
+	if err == nil {
+		info, statErr := file.Stat()
+		if segment.info == nil || (statErr == nil && os.SameFile(info, segment.info)) {
+			return file, nil
+		}
+		file.Close()
+	} else if segment.info == nil || !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+
+	segments, err := s.Segments()
+	if err != nil {
+		return nil, err
+	}
+	for _, current := range segments {
+		if os.SameFile(current.info, segment.info) {
+			return os.Open(current.Path)
+		}
+	}
+	return nil, fmt.Errorf("failed to open audit log: %s was removed", segment.Path)
+}
+
+// Scan reads the segments from the start. Lines that fail to parse or exceed MaxAuditEntrySize end
+// the scan with ErrCorruptAuditLog.
+func (s *fileAuditStore) Scan(ctx context.Context, fn func(entry *model.AuditEntry) error) error {
+	segments, err := s.Segments()
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if err := s.ScanSegment(ctx, segment, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ScanSegment reads one segment from the start, like Scan
+func (s *fileAuditStore) ScanSegment(ctx context.Context, segment AuditSegment, fn func(entry *model.AuditEntry) error) error {
+	file, err := s.openSegment(segment)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	name := filepath.Base(segment.Path)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), MaxAuditEntrySize)
+	line := 1
+	for ; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var entry model.AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("%w: %s line %d: %v", ErrCorruptAuditLog, name, line, err)
+		}
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return fmt.Errorf("%w: %s line %d: longer than %d bytes", ErrCorruptAuditLog, name, line, MaxAuditEntrySize)
+	} else if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return nil
+}
+
+// ScanReverse reads the segments backwards from their end, so the newest entries are read without
+// reading the whole log. Lines that fail to parse or exceed MaxAuditEntrySize end the scan with
+// ErrCorruptAuditLog, after the entries that follow them.
+func (s *fileAuditStore) ScanReverse(ctx context.Context, fn func(entry *model.AuditEntry) error) error {
+	segments, err := s.Segments()
+	if err != nil {
+		return err
+	}
+	for i := len(segments) - 1; i >= 0; i-- {
+		if err := s.scanSegmentReverse(ctx, segments[i], fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditReadChunk is the size of the blocks read backwards by ScanReverse; it grows for longer lines
+const auditReadChunk = 64 * 1024
+
+// scanSegmentReverse reads the lines of one segment from the last to the first
+func (s *fileAuditStore) scanSegmentReverse(ctx context.Context, segment AuditSegment, fn func(entry *model.AuditEntry) error) error {
+	file, err := s.openSegment(segment)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	name := filepath.Base(segment.Path)
+	parse := func(line []byte, offset int64) error {
+		if len(line)+1 > MaxAuditEntrySize {
+			return fmt.Errorf("%w: %s offset %d: longer than %d bytes", ErrCorruptAuditLog, name, offset, MaxAuditEntrySize)
+		}
+		var entry model.AuditEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return fmt.Errorf("%w: %s offset %d: %v", ErrCorruptAuditLog, name, offset, err)
+		}
+		return fn(&entry)
+	}
+
+	// pending holds the bytes from pos up to the end of the line being read
+	pos := info.Size()
+	var pending []byte
+	last := true // The file ends with a line break, which does not start another line
+	for pos > 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := min(pos, max(auditReadChunk, int64(len(pending))))
+		pos -= n
+		chunk := make([]byte, n, n+int64(len(pending)))
+		if _, err := file.ReadAt(chunk, pos); err != nil {
+			return fmt.Errorf("failed to read audit log: %w", err)
+		}
+		pending = append(chunk, pending...)
+
+		for {
+			i := bytes.LastIndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			if line := pending[i+1:]; len(line) > 0 || !last {
+				if err := parse(line, pos+int64(i)+1); err != nil {
+					return err
+				}
+			}
+			last = false
+			pending = pending[:i]
+		}
+		if len(pending) >= MaxAuditEntrySize {
+			return fmt.Errorf("%w: %s offset %d: longer than %d bytes", ErrCorruptAuditLog, name, pos, MaxAuditEntrySize)
+		}
+	}
+	if len(pending) > 0 {
+		return parse(pending, 0)
+	}
+	return nil
+}
+
+// Close closes the file
+func (s *fileAuditStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
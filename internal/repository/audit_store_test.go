@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// scanAll returns the sequences of all entries of the store
+func scanAll(t *testing.T, store AuditStore) ([]int64, error) {
+	t.Helper()
+	var sequences []int64
+	err := store.Scan(context.Background(), func(entry *model.AuditEntry) error {
+		sequences = append(sequences, entry.Sequence)
+		return nil
+	})
+	return sequences, err
+}
+
+func TestFileAuditStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	ctx := context.Background()
+
+	store, err := NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	for _, seq := range []int64{1, 2} {
+		if err := store.Append(ctx, &model.AuditEntry{Sequence: seq, Operation: "onu.block"}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	store.Close()
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Reopening appends to the existing entries
+	store, err = NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	defer store.Close()
+	if err := store.Append(ctx, &model.AuditEntry{Sequence: 3}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	sequences, err := scanAll(t, store)
+	if err != nil || len(sequences) != 3 || sequences[0] != 1 || sequences[2] != 3 {
+		t.Errorf("Scan() = %v, %v, want [1 2 3]", sequences, err)
+	}
+
+	// A line that is not an entry ends the scan
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.WriteString("{\"sequence\":4,\"oper\n")
+	file.Close()
+
+	sequences, err = scanAll(t, store)
+	if !errors.Is(err, ErrCorruptAuditLog) || len(sequences) != 3 {
+		t.Errorf("Scan() of a corrupt log = %v, %v, want 3 entries and ErrCorruptAuditLog", sequences, err)
+	}
+}
+
+func TestFileAuditStore_EntrySizeLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	ctx := context.Background()
+
+	store, err := NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	defer store.Close()
+	if err := store.Append(ctx, &model.AuditEntry{Sequence: 1}); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+
+	// An entry that could not be read back is not written
+	large := &model.AuditEntry{Sequence: 2, Commands: []model.CLICommand{{Output: strings.Repeat("x", MaxAuditEntrySize)}}}
+	if err := store.Append(ctx, large); !errors.Is(err, ErrAuditEntryTooLarge) {
+		t.Errorf("Append() of a large entry error = %v, want ErrAuditEntryTooLarge", err)
+	}
+	if sequences, err := scanAll(t, store); err != nil || len(sequences) != 1 {
+		t.Errorf("Scan() = %v, %v, want the first entry only", sequences, err)
+	}
+
+	// A line written past the limit by other means is reported as corruption
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.WriteString(strings.Repeat("x", MaxAuditEntrySize) + "\n")
+	file.Close()
+
+	sequences, err := scanAll(t, store)
+	if !errors.Is(err, ErrCorruptAuditLog) || len(sequences) != 1 {
+		t.Errorf("Scan() of an oversized line = %v, %v, want 1 entry and ErrCorruptAuditLog", sequences, err)
+	}
+}
+
+// scanReverse returns the sequences of all entries of the store, newest first
+func scanReverse(t *testing.T, store AuditStore) ([]int64, error) {
+	t.Helper()
+	var sequences []int64
+	err := store.ScanReverse(context.Background(), func(entry *model.AuditEntry) error {
+		sequences = append(sequences, entry.Sequence)
+		return nil
+	})
+	return sequences, err
+}
+
+func TestFileAuditStore_ScanReverse(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	ctx := context.Background()
+
+	store, err := NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	defer store.Close()
+
+	// Lines longer than a read chunk are read whole
+	for seq := int64(1); seq <= 5; seq++ {
+		entry := &model.AuditEntry{Sequence: seq}
+		if seq%2 == 0 {
+			entry.Commands = []model.CLICommand{{Output: strings.Repeat("x", 3*auditReadChunk)}}
+		}
+		if err := store.Append(ctx, entry); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	sequences, err := scanReverse(t, store)
+	if err != nil || fmt.Sprint(sequences) != "[5 4 3 2 1]" {
+		t.Errorf("ScanReverse() = %v, %v, want [5 4 3 2 1]", sequences, err)
+	}
+
+	// The scan stops at the error of fn
+	stop := errors.New("stop")
+	count := 0
+	err = store.ScanReverse(ctx, func(*model.AuditEntry) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("ScanReverse() = %d entries, %v, want 1 and the error of fn", count, err)
+	}
+
+	// A damaged line ends the scan after the entries that follow it
+	file, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	file.WriteString("{\"sequence\":6,\"oper\n{\"sequence\":7}")
+	file.Close()
+
+	sequences, err = scanReverse(t, store)
+	if !errors.Is(err, ErrCorruptAuditLog) || fmt.Sprint(sequences) != "[7]" {
+		t.Errorf("ScanReverse() of a corrupt log = %v, %v, want [7] and ErrCorruptAuditLog", sequences, err)
+	}
+}
+
+func TestFileAuditStore_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "audit.log")
+	ctx := context.Background()
+
+	// Each entry is 172 bytes, so every file holds two entries
+	store, err := NewFileAuditStore(path, 400)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	for seq := int64(1); seq <= 3; seq++ {
+		if err := store.Append(ctx, &model.AuditEntry{Sequence: seq}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	store.Close()
+
+	// Reopening continues the active file and the numbering of the rotated ones
+	store, err = NewFileAuditStore(path, 400)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	defer store.Close()
+	for seq := int64(4); seq <= 5; seq++ {
+		if err := store.Append(ctx, &model.AuditEntry{Sequence: seq}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	segments, err := store.Segments()
+	if err != nil || len(segments) != 3 {
+		t.Fatalf("Segments() = %+v, %v, want 3 segments", segments, err)
+	}
+	for i, want := range []string{"audit.log.1", "audit.log.2", "audit.log"} {
+		if filepath.Base(segments[i].Path) != want || segments[i].Active != (i == 2) {
+			t.Errorf("segment %d = %s (active %v), want %s", i, segments[i].Path, segments[i].Active, want)
+		}
+	}
+	if info, err := os.Stat(segments[0].Path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("rotated file mode = %v, %v, want 0600", info, err)
+	}
+
+	sequences, err := scanAll(t, store)
+	if err != nil || fmt.Sprint(sequences) != "[1 2 3 4 5]" {
+		t.Errorf("Scan() = %v, %v, want [1 2 3 4 5]", sequences, err)
+	}
+	sequences, err = scanReverse(t, store)
+	if err != nil || fmt.Sprint(sequences) != "[5 4 3 2 1]" {
+		t.Errorf("ScanReverse() = %v, %v, want [5 4 3 2 1]", sequences, err)
+	}
+
+	// A segment listed before a rotation is still read from its new path
+	active := segments[2]
+	for seq := int64(6); seq <= 7; seq++ {
+		if err := store.Append(ctx, &model.AuditEntry{Sequence: seq}); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	sequences = nil
+	err = store.ScanSegment(ctx, active, func(entry *model.AuditEntry) error {
+		sequences = append(sequences, entry.Sequence)
+		return nil
+	})
+	if err != nil || fmt.Sprint(sequences) != "[5 6]" {
+		t.Errorf("ScanSegment() of the rotated active file = %v, %v, want [5 6]", sequences, err)
+	}
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// Errors returned by backup stores
+var (
+	ErrBackupNotFound  = errors.New("backup not found")
+	ErrInvalidBackupID = errors.New("invalid backup ID")
+)
+
+// backupIDPattern restricts backup IDs to characters that are safe in file names and object keys
+var backupIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-][A-Za-z0-9._-]{0,127}$`)
+
+// Header of AES-GCM encrypted backup objects, followed by the nonce and the sealed gzip stream
+var encryptedBackupMagic = []byte("C320ENC1")
+
+// gzip streams start with these two bytes
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// BackupObject describes a stored backup
+type BackupObject struct {
+	ID      string    // Backup ID
+	Size    int64     // Stored size in bytes (after compression and encryption)
+	ModTime time.Time // Last modification time
+}
+
+// BackupStore persists serialized configuration backups by ID
+type BackupStore interface {
+	// Put stores a backup, replacing an existing one with the same ID
+	Put(ctx context.Context, id string, data []byte) error
+
+	// Get returns a stored backup, or ErrBackupNotFound
+	Get(ctx context.Context, id string) ([]byte, error)
+
+	// Delete removes a stored backup, or returns ErrBackupNotFound
+	Delete(ctx context.Context, id string) error
+
+	// List returns all stored backups
+	List(ctx context.Context) ([]BackupObject, error)
+}
+
+// NewBackupStore creates the backup store selected by cfg.BackupStore.
+// Backups are gzip-compressed and, when an encryption key is configured, AES-256-GCM encrypted.
+func NewBackupStore(cfg *config.Config) (BackupStore, error) {
+	key, err := cfg.BackupStore.EncryptionKeyBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var store BackupStore
+	switch cfg.BackupStore.Type {
+	case "", "local":
+		store, err = NewLocalBackupStore(cfg.OltCfg.BackupDir)
+	case "s3":
+		store, err = NewS3BackupStore(cfg.BackupStore)
+	default:
+		return nil, fmt.Errorf("unknown backup store %q (supported: local, s3)", cfg.BackupStore.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEncodedBackupStore(store, key)
+}
+
+// validateBackupID rejects IDs that could escape the backup directory or prefix
+func validateBackupID(id string) error {
+	if !backupIDPattern.MatchString(id) {
+		return fmt.Errorf("%w: %q", ErrInvalidBackupID, id)
+	}
+	return nil
+}
+
+// encodedBackupStore compresses, and optionally encrypts, backups before handing them to the underlying store
+type encodedBackupStore struct {
+	BackupStore
+	aead cipher.AEAD // nil if encryption is disabled
+}
+
+// NewEncodedBackupStore wraps a store with gzip compression and, if key is set, AES-256-GCM encryption.
+// Plain JSON and unencrypted gzip backups written by earlier versions can still be read.
+func NewEncodedBackupStore(store BackupStore, key []byte) (BackupStore, error) {
+	s := &encodedBackupStore{BackupStore: store}
+
+	if len(key) > 0 {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("backup encryption key must be 32 bytes, got %d", len(key))
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		if s.aead, err = cipher.NewGCM(block); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Put compresses (and encrypts) a backup before storing it
+func (s *encodedBackupStore) Put(ctx context.Context, id string, data []byte) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	encoded := buf.Bytes()
+
+	if s.aead != nil {
+		nonce := make([]byte, s.aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		sealed := make([]byte, 0, len(encryptedBackupMagic)+len(nonce)+len(encoded)+s.aead.Overhead())
+		sealed = append(sealed, encryptedBackupMagic...)
+		sealed = append(sealed, nonce...)
+		encoded = s.aead.Seal(sealed, nonce, encoded, encryptedBackupMagic)
+	}
+
+	return s.BackupStore.Put(ctx, id, encoded)
+}
+
+// Get returns the decrypted and decompressed backup
+func (s *encodedBackupStore) Get(ctx context.Context, id string) ([]byte, error) {
+	data, err := s.BackupStore.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(data, encryptedBackupMagic) {
+		if s.aead == nil {
+			return nil, fmt.Errorf("backup %s is encrypted but no encryption key is configured", id)
+		}
+		sealed := data[len(encryptedBackupMagic):]
+		if len(sealed) < s.aead.NonceSize() {
+			return nil, fmt.Errorf("backup %s is truncated", id)
+		}
+		nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+		if data, err = s.aead.Open(nil, nonce, ciphertext, encryptedBackupMagic); err != nil {
+			return nil, fmt.Errorf("failed to decrypt backup %s: %w", id, err)
+		}
+	}
+
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress backup %s: %w", id, err)
+		}
+		defer zr.Close()
+		if data, err = io.ReadAll(zr); err != nil {
+			return nil, fmt.Errorf("failed to decompress backup %s: %w", id, err)
+		}
+	}
+
+	return data, nil
+}
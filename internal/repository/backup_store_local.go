@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// File extensions of stored backups; ".json" files were written by earlier versions
+const (
+	backupFileExt       = ".backup"
+	legacyBackupFileExt = ".json"
+)
+
+// localBackupStore keeps backups as files in a directory
+type localBackupStore struct {
+	dir string
+}
+
+// NewLocalBackupStore creates a backup store in dir, creating the directory if needed.
+// A directory that cannot be created is only logged; writes fail until it exists.
+func NewLocalBackupStore(dir string) (BackupStore, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("backup directory is not configured")
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.Error().Err(err).Str("dir", dir).Msg("Failed to create backup directory")
+	}
+
+	return &localBackupStore{dir: dir}, nil
+}
+
+// Put writes the backup to a temporary file and renames it, readable by the owner only
+func (s *localBackupStore) Put(_ context.Context, id string, data []byte) error {
+	if err := validateBackupID(id); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(s.dir, "."+id+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("failed to set backup file mode: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(id, backupFileExt)); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+
+	// Drop a legacy plain-JSON copy so no unencrypted duplicate is left behind
+	if err := os.Remove(s.path(id, legacyBackupFileExt)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove legacy backup file: %w", err)
+	}
+
+	return nil
+}
+
+// Get reads a backup, falling back to the legacy ".json" file
+func (s *localBackupStore) Get(_ context.Context, id string) ([]byte, error) {
+	if err := validateBackupID(id); err != nil {
+		return nil, err
+	}
+
+	for _, ext := range []string{backupFileExt, legacyBackupFileExt} {
+		data, err := os.ReadFile(s.path(id, ext))
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read backup file: %w", err)
+		}
+	}
+
+	return nil, ErrBackupNotFound
+}
+
+// Delete removes a backup and any legacy copy of it
+func (s *localBackupStore) Delete(_ context.Context, id string) error {
+	if err := validateBackupID(id); err != nil {
+		return err
+	}
+
+	deleted := false
+	for _, ext := range []string{backupFileExt, legacyBackupFileExt} {
+		err := os.Remove(s.path(id, ext))
+		if err == nil {
+			deleted = true
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to delete backup file: %w", err)
+		}
+	}
+
+	if !deleted {
+		return ErrBackupNotFound
+	}
+	return nil
+}
+
+// List returns all backups in the directory
+func (s *localBackupStore) List(_ context.Context) ([]BackupObject, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var objects []BackupObject
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		ext := filepath.Ext(name)
+		if ext != backupFileExt && ext != legacyBackupFileExt {
+			continue
+		}
+		id := strings.TrimSuffix(name, ext)
+		if seen[id] || validateBackupID(id) != nil {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed while listing
+		}
+
+		seen[id] = true
+		objects = append(objects, BackupObject{ID: id, Size: info.Size(), ModTime: info.ModTime()})
+	}
+
+	return objects, nil
+}
+
+// path returns the file path of a backup
+func (s *localBackupStore) path(id, ext string) string {
+	return filepath.Join(s.dir, id+ext)
+}
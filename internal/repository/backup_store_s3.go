@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// s3BackupStore keeps backups as objects in an S3-compatible bucket (AWS S3, MinIO, Ceph RGW, ...).
+// Requests use path-style addressing and AWS Signature Version 4.
+type s3BackupStore struct {
+	client    *http.Client
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	prefix    string
+	accessKey string
+	secretKey string
+	now       func() time.Time // Clock, replaced in tests
+}
+
+// NewS3BackupStore creates a backup store in an S3-compatible bucket
+func NewS3BackupStore(cfg config.BackupStoreConfig) (BackupStore, error) {
+	if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+		return nil, fmt.Errorf("BACKUP_S3_ENDPOINT and BACKUP_S3_BUCKET are required for the s3 backup store")
+	}
+	if cfg.S3AccessKey == "" || cfg.S3SecretKey == "" {
+		return nil, fmt.Errorf("BACKUP_S3_ACCESS_KEY and BACKUP_S3_SECRET_KEY are required for the s3 backup store")
+	}
+
+	endpoint, err := url.Parse(cfg.S3Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("invalid BACKUP_S3_ENDPOINT %q", cfg.S3Endpoint)
+	}
+
+	region := cfg.S3Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &s3BackupStore{
+		client:    &http.Client{Timeout: 60 * time.Second},
+		endpoint:  endpoint,
+		region:    region,
+		bucket:    cfg.S3Bucket,
+		prefix:    strings.Trim(cfg.S3Prefix, "/"),
+		accessKey: cfg.S3AccessKey,
+		secretKey: cfg.S3SecretKey,
+		now:       time.Now,
+	}, nil
+}
+
+// Put uploads a backup object
+func (s *s3BackupStore) Put(ctx context.Context, id string, data []byte) error {
+	if err := validateBackupID(id); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, s.objectKey(id), nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "put", id)
+	}
+	return nil
+}
+
+// Get downloads a backup object
+func (s *s3BackupStore) Get(ctx context.Context, id string) ([]byte, error) {
+	if err := validateBackupID(id); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, s.objectKey(id), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrBackupNotFound
+	default:
+		return nil, s3Error(resp, "get", id)
+	}
+}
+
+// Delete removes a backup object. S3 reports success for missing keys, so existence is checked first.
+func (s *s3BackupStore) Delete(ctx context.Context, id string) error {
+	if err := validateBackupID(id); err != nil {
+		return err
+	}
+
+	head, err := s.do(ctx, http.MethodHead, s.objectKey(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	head.Body.Close()
+	switch head.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return ErrBackupNotFound
+	default:
+		return s3Error(head, "head", id)
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, s.objectKey(id), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "delete", id)
+	}
+	return nil
+}
+
+// s3ListResult is the subset of a ListObjectsV2 response used by List
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns all backup objects below the prefix, following pagination
+func (s *s3BackupStore) List(ctx context.Context) ([]BackupObject, error) {
+	listPrefix := ""
+	if s.prefix != "" {
+		listPrefix = s.prefix + "/"
+	}
+
+	var objects []BackupObject
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {listPrefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp, "list", listPrefix)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse S3 list response: %w", err)
+		}
+
+		for _, obj := range result.Contents {
+			name := strings.TrimPrefix(obj.Key, listPrefix)
+			if strings.Contains(name, "/") || !strings.HasSuffix(name, backupFileExt) {
+				continue // Nested "folders" belong to other OLTs
+			}
+			id := strings.TrimSuffix(name, backupFileExt)
+			if validateBackupID(id) != nil {
+				continue
+			}
+			objects = append(objects, BackupObject{ID: id, Size: obj.Size, ModTime: obj.LastModified})
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// objectKey returns the object key of a backup
+func (s *s3BackupStore) objectKey(id string) string {
+	if s.prefix == "" {
+		return id + backupFileExt
+	}
+	return s.prefix + "/" + id + backupFileExt
+}
+
+// do sends a signed request for an object key (or the bucket, if key is empty)
+func (s *s3BackupStore) do(ctx context.Context, method, key string, query url.Values, body []byte) (*http.Response, error) {
+	objectPath := path.Join("/", s.endpoint.Path, s.bucket)
+	if key != "" {
+		objectPath += "/" + key
+	}
+
+	u := *s.endpoint
+	u.Path = objectPath
+	u.RawPath = awsURIEncode(objectPath, false)
+	u.RawQuery = canonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	payloadHash := sha256.Sum256(body)
+	signV4(req, hex.EncodeToString(payloadHash[:]), s.now(), s.accessKey, s.secretKey, s.region)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s request failed: %w", strings.ToLower(method), err)
+	}
+	return resp, nil
+}
+
+// s3Error builds an error from a failed S3 response, including the S3 error code if present
+func s3Error(resp *http.Response, op, id string) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("S3 %s %s failed: %s: %s (%s)", op, id, resp.Status, body.Code, body.Message)
+	}
+	return fmt.Errorf("S3 %s %s failed: %s", op, id, resp.Status)
+}
+
+// signV4 signs a request with AWS Signature Version 4 for the S3 service.
+// The host, range and all x-amz-* headers are signed.
+func signV4(req *http.Request, payloadHash string, now time.Time, accessKey, secretKey, region string) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsURIEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters sorted by name, as required by Signature Version 4
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+
+	var pairs []string
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, awsURIEncode(name, true)+"="+awsURIEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, "&")
+}
+
+// awsURIEncode percent-encodes everything except unreserved characters (and "/" unless encodeSlash is set)
+func awsURIEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// hmacSHA256 computes HMAC-SHA256(key, data)
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
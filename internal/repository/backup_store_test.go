@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+func testEncryptionKey() []byte {
+	return bytes.Repeat([]byte{0x42}, 32)
+}
+
+func TestLocalBackupStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	store, err := NewLocalBackupStore(dir)
+	if err != nil {
+		t.Fatalf("NewLocalBackupStore() error = %v", err)
+	}
+
+	if err := store.Put(ctx, "backup-1", []byte("one")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	info, err := os.Stat(filepath.Join(dir, "backup-1.backup"))
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	// Backups written by earlier versions are still readable
+	if err := os.WriteFile(filepath.Join(dir, "legacy.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Unrelated files and directories are ignored
+	if err := os.MkdirAll(filepath.Join(dir, "schedules"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 2 || objects[0].ID != "backup-1" || objects[0].Size != 3 || objects[1].ID != "legacy" {
+		t.Errorf("List() = %+v", objects)
+	}
+
+	data, err := store.Get(ctx, "legacy")
+	if err != nil || string(data) != "{}" {
+		t.Errorf("Get(legacy) = %q, %v", data, err)
+	}
+
+	if err := store.Delete(ctx, "backup-1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, "backup-1"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("Get() after delete error = %v, want ErrBackupNotFound", err)
+	}
+	if err := store.Delete(ctx, "backup-1"); !errors.Is(err, ErrBackupNotFound) {
+		t.Errorf("Delete() twice error = %v, want ErrBackupNotFound", err)
+	}
+
+	for _, id := range []string{"../etc/passwd", "a/b", "", ".hidden"} {
+		if _, err := store.Get(ctx, id); !errors.Is(err, ErrInvalidBackupID) {
+			t.Errorf("Get(%q) error = %v, want ErrInvalidBackupID", id, err)
+		}
+	}
+}
+
+func TestEncodedBackupStore(t *testing.T) {
+	ctx := context.Background()
+	plain := []byte(`{"id":"backup-1","config":{"serial_number":"ZTEGC0000001"}}`)
+
+	tests := []struct {
+		name string
+		key  []byte
+	}{
+		{name: "gzip", key: nil},
+		{name: "gzip and AES-GCM", key: testEncryptionKey()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			local, _ := NewLocalBackupStore(dir)
+			store, err := NewEncodedBackupStore(local, tt.key)
+			if err != nil {
+				t.Fatalf("NewEncodedBackupStore() error = %v", err)
+			}
+
+			if err := store.Put(ctx, "backup-1", plain); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+
+			raw, _ := local.Get(ctx, "backup-1")
+			if tt.key == nil && !bytes.HasPrefix(raw, gzipMagic) {
+				t.Error("stored object is not gzip-compressed")
+			}
+			if tt.key != nil && (!bytes.HasPrefix(raw, encryptedBackupMagic) || bytes.Contains(raw, []byte("ZTEGC0000001"))) {
+				t.Error("stored object is not encrypted")
+			}
+
+			got, err := store.Get(ctx, "backup-1")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if !bytes.Equal(got, plain) {
+				t.Errorf("Get() = %q, want %q", got, plain)
+			}
+		})
+	}
+}
+
+func TestEncodedBackupStore_KeyHandling(t *testing.T) {
+	ctx := context.Background()
+	local, _ := NewLocalBackupStore(t.TempDir())
+
+	encrypted, _ := NewEncodedBackupStore(local, testEncryptionKey())
+	if err := encrypted.Put(ctx, "secret", []byte("{}")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	unencrypted, _ := NewEncodedBackupStore(local, nil)
+	if _, err := unencrypted.Get(ctx, "secret"); err == nil {
+		t.Error("expected error reading an encrypted backup without key")
+	}
+
+	wrongKey, _ := NewEncodedBackupStore(local, bytes.Repeat([]byte{0x01}, 32))
+	if _, err := wrongKey.Get(ctx, "secret"); err == nil {
+		t.Error("expected error reading an encrypted backup with the wrong key")
+	}
+
+	// Unencrypted backups stay readable after encryption is enabled
+	if err := unencrypted.Put(ctx, "old", []byte("{}")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if data, err := encrypted.Get(ctx, "old"); err != nil || string(data) != "{}" {
+		t.Errorf("Get(old) = %q, %v", data, err)
+	}
+
+	if _, err := NewEncodedBackupStore(local, []byte("short")); err == nil {
+		t.Error("expected error for a short key")
+	}
+}
+
+func TestNewBackupStore(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.OltCfg.BackupDir = t.TempDir()
+
+	if _, err := NewBackupStore(cfg); err != nil {
+		t.Errorf("NewBackupStore(local) error = %v", err)
+	}
+
+	cfg.BackupStore.Type = "ftp"
+	if _, err := NewBackupStore(cfg); err == nil {
+		t.Error("expected error for unknown store type")
+	}
+
+	cfg.BackupStore.Type = "s3"
+	if _, err := NewBackupStore(cfg); err == nil {
+		t.Error("expected error for s3 store without endpoint")
+	}
+
+	cfg.BackupStore.Type = "local"
+	cfg.BackupStore.EncryptionKey = "not-a-key"
+	if _, err := NewBackupStore(cfg); err == nil {
+		t.Error("expected error for invalid encryption key")
+	}
+}
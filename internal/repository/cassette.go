@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// CassetteVersion is the format version written to cassette files
+const CassetteVersion = 1
+
+// Interaction kinds of a cassette
+const (
+	CassetteSnmpGet  = "snmp_get"  // SNMP GET of one or more OIDs
+	CassetteSnmpWalk = "snmp_walk" // SNMP walk below an OID
+	CassetteCLI      = "cli"       // CLI command over Telnet or SSH
+)
+
+// redacted replaces credentials in recorded exchanges
+const redacted = "<redacted>"
+
+// ErrNotInCassette is returned by the replay drivers for an exchange that was not recorded
+var ErrNotInCassette = errors.New("exchange not recorded in cassette")
+
+// cliSecretPattern matches the argument of CLI keywords that carry credentials,
+// e.g. "pw 12345678", "password 0 zte" or "snmp-server community public"
+var cliSecretPattern = regexp.MustCompile(`(?i)\b(password|secret|community|passphrase|pw)(:?\s+)(\d\s+)?(\S+)`)
+
+// minSecretLength skips short configured secrets, which would mangle unrelated output when redacted
+const minSecretLength = 4
+
+// Cassette holds the SNMP and CLI exchanges with an OLT in the order they happened. It is filled by the
+// recording drivers and served back by the replay drivers, so a misbehaving parser can be reproduced
+// from the exact output of a customer's OLT. Credentials are redacted when an exchange is recorded.
+type Cassette struct {
+	Version      int                   `json:"version"`
+	RecordedAt   time.Time             `json:"recorded_at"`
+	Interactions []CassetteInteraction `json:"interactions"`
+
+	mu      sync.Mutex
+	secrets []string         // Configured credentials, replaced wherever they appear
+	index   map[string][]int // Interactions per replay key, built on first replay
+	next    map[string]int   // Next interaction to replay per key
+}
+
+// CassetteInteraction is a single recorded exchange
+type CassetteInteraction struct {
+	Kind       string             `json:"kind"`                  // snmp_get, snmp_walk or cli
+	OIDs       []string           `json:"oids,omitempty"`        // Requested OIDs (snmp_get)
+	OID        string             `json:"oid,omitempty"`         // Root OID (snmp_walk)
+	Variables  []CassetteVariable `json:"variables,omitempty"`   // Returned variables (snmp_get, snmp_walk)
+	Command    string             `json:"command,omitempty"`     // Command sent to the CLI (cli)
+	Output     string             `json:"output,omitempty"`      // Command output without echo and prompt (cli)
+	Error      string             `json:"error,omitempty"`       // Error returned to the caller
+	DurationMs int64              `json:"duration_ms,omitempty"` // Time the OLT took to answer
+}
+
+// CassetteVariable is an SNMP variable with the type names of snmpwalk output
+// (STRING, Hex-STRING, INTEGER, Counter32, ...)
+type CassetteVariable struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value,omitempty"`
+}
+
+// NewCassette creates an empty cassette. The given secrets are redacted in addition to
+// the arguments of CLI credential keywords; empty and very short secrets are ignored.
+func NewCassette(secrets ...string) *Cassette {
+	c := &Cassette{
+		Version:    CassetteVersion,
+		RecordedAt: time.Now().UTC(),
+	}
+	for _, secret := range secrets {
+		if len(secret) >= minSecretLength {
+			c.secrets = append(c.secrets, secret)
+		}
+	}
+	return c
+}
+
+// CassetteSecrets returns the credentials of an OLT that must not end up in a cassette
+func CassetteSecrets(snmpCfg config.SnmpConfig, telnetCfg *config.TelnetConfig) []string {
+	secrets := []string{snmpCfg.Community, snmpCfg.AuthPassphrase, snmpCfg.PrivPassphrase}
+	if telnetCfg != nil {
+		secrets = append(secrets, telnetCfg.Password, telnetCfg.EnablePassword)
+	}
+	return secrets
+}
+
+// LoadCassette reads a cassette file for replay
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version != CassetteVersion {
+		return nil, fmt.Errorf("cassette %s has unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, replacing an existing file atomically
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false) // Keep "<redacted>" and CLI output readable
+	encoder.SetIndent("", "  ")
+	c.mu.Lock()
+	err := encoder.Encode(c)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	data := buf.Bytes()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Len returns the number of recorded interactions
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.Interactions)
+}
+
+// record redacts an interaction and appends it
+func (c *Cassette) record(interaction CassetteInteraction) {
+	interaction.Command = c.redactCLI(interaction.Command)
+	interaction.Output = c.redactCLI(interaction.Output)
+	interaction.Error = c.redact(interaction.Error)
+	for i, variable := range interaction.Variables {
+		if variable.Type == "STRING" {
+			interaction.Variables[i].Value = c.redact(variable.Value)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.Interactions = append(c.Interactions, interaction)
+	c.index = nil
+}
+
+// replay returns the next recorded interaction of kind for key. Repeated exchanges are served in
+// recording order; once they are used up, the last one is served again (e.g. for polling).
+func (c *Cassette) replay(kind, key string) (CassetteInteraction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index == nil {
+		c.index = make(map[string][]int)
+		c.next = make(map[string]int)
+		for i, interaction := range c.Interactions {
+			k := interaction.Kind + " " + interaction.key()
+			c.index[k] = append(c.index[k], i)
+		}
+	}
+
+	k := kind + " " + key
+	matches := c.index[k]
+	if len(matches) == 0 {
+		return CassetteInteraction{}, fmt.Errorf("%w: %s", ErrNotInCassette, k)
+	}
+	n := c.next[k]
+	if n < len(matches)-1 {
+		c.next[k] = n + 1
+	}
+	return c.Interactions[matches[n]], nil
+}
+
+// key identifies the request of an interaction for replay
+func (i CassetteInteraction) key() string {
+	switch i.Kind {
+	case CassetteSnmpGet:
+		return snmpGetKey(i.OIDs)
+	case CassetteSnmpWalk:
+		return strings.TrimPrefix(i.OID, ".")
+	default:
+		return i.Command
+	}
+}
+
+// snmpGetKey identifies a GET request independent of leading dots
+func snmpGetKey(oids []string) string {
+	trimmed := make([]string, len(oids))
+	for i, oid := range oids {
+		trimmed[i] = strings.TrimPrefix(oid, ".")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+// redact replaces the configured secrets in s
+func (c *Cassette) redact(s string) string {
+	for _, secret := range c.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// redactCLI replaces the configured secrets and the arguments of CLI credential keywords in s
+func (c *Cassette) redactCLI(s string) string {
+	return redactCLIKeywords(c.redact(s))
+}
+
+// redactCLIKeywords replaces the arguments of CLI credential keywords. Replay applies it to
+// incoming commands as well, so commands carrying a password still match their recording.
+func redactCLIKeywords(s string) string {
+	return cliSecretPattern.ReplaceAllString(s, "${1}${2}${3}"+redacted)
+}
+
+// encodeCassetteVariable converts a PDU to its cassette form
+func encodeCassetteVariable(pdu gosnmp.SnmpPDU) CassetteVariable {
+	variable := CassetteVariable{Name: pdu.Name}
+	switch pdu.Type {
+	case gosnmp.OctetString:
+		b, _ := pdu.Value.([]byte)
+		if isPrintableASCII(b) {
+			variable.Type, variable.Value = "STRING", string(b)
+		} else {
+			variable.Type, variable.Value = "Hex-STRING", fmt.Sprintf("% X", b)
+		}
+	case gosnmp.Integer:
+		variable.Type, variable.Value = "INTEGER", fmt.Sprint(pdu.Value)
+	case gosnmp.Counter32:
+		variable.Type, variable.Value = "Counter32", fmt.Sprint(pdu.Value)
+	case gosnmp.Gauge32:
+		variable.Type, variable.Value = "Gauge32", fmt.Sprint(pdu.Value)
+	case gosnmp.TimeTicks:
+		variable.Type, variable.Value = "Timeticks", fmt.Sprint(pdu.Value)
+	case gosnmp.Counter64:
+		variable.Type, variable.Value = "Counter64", fmt.Sprint(pdu.Value)
+	case gosnmp.Uinteger32:
+		variable.Type, variable.Value = "Uinteger32", fmt.Sprint(pdu.Value)
+	case gosnmp.OpaqueFloat:
+		variable.Type, variable.Value = "Opaque-Float", fmt.Sprint(pdu.Value)
+	case gosnmp.OpaqueDouble:
+		variable.Type, variable.Value = "Opaque-Double", fmt.Sprint(pdu.Value)
+	case gosnmp.ObjectIdentifier:
+		variable.Type, variable.Value = "OID", fmt.Sprint(pdu.Value)
+	case gosnmp.IPAddress:
+		variable.Type, variable.Value = "IpAddress", fmt.Sprint(pdu.Value)
+	default: // Null, NoSuchObject, NoSuchInstance, EndOfMibView
+		variable.Type = pdu.Type.String()
+	}
+	return variable
+}
+
+// decodeCassetteVariable converts a cassette variable back to the PDU gosnmp returned, with the same Go value types
+func decodeCassetteVariable(variable CassetteVariable) (gosnmp.SnmpPDU, error) {
+	pdu := gosnmp.SnmpPDU{Name: variable.Name}
+	var err error
+	switch variable.Type {
+	case "STRING":
+		pdu.Type, pdu.Value = gosnmp.OctetString, []byte(variable.Value)
+	case "Hex-STRING":
+		var b []byte
+		b, err = hex.DecodeString(strings.ReplaceAll(variable.Value, " ", ""))
+		pdu.Type, pdu.Value = gosnmp.OctetString, b
+	case "INTEGER":
+		var v int
+		v, err = strconv.Atoi(variable.Value)
+		pdu.Type, pdu.Value = gosnmp.Integer, v
+	case "Counter32", "Gauge32":
+		var v uint64
+		v, err = strconv.ParseUint(variable.Value, 10, 32)
+		pdu.Type, pdu.Value = gosnmp.Counter32, uint(v)
+		if variable.Type == "Gauge32" {
+			pdu.Type = gosnmp.Gauge32
+		}
+	case "Timeticks", "Uinteger32":
+		var v uint64
+		v, err = strconv.ParseUint(variable.Value, 10, 32)
+		pdu.Type, pdu.Value = gosnmp.TimeTicks, uint32(v)
+		if variable.Type == "Uinteger32" {
+			pdu.Type = gosnmp.Uinteger32
+		}
+	case "Counter64":
+		var v uint64
+		v, err = strconv.ParseUint(variable.Value, 10, 64)
+		pdu.Type, pdu.Value = gosnmp.Counter64, v
+	case "Opaque-Float":
+		var v float64
+		v, err = strconv.ParseFloat(variable.Value, 32)
+		pdu.Type, pdu.Value = gosnmp.OpaqueFloat, float32(v)
+	case "Opaque-Double":
+		var v float64
+		v, err = strconv.ParseFloat(variable.Value, 64)
+		pdu.Type, pdu.Value = gosnmp.OpaqueDouble, v
+	case "OID":
+		pdu.Type, pdu.Value = gosnmp.ObjectIdentifier, variable.Value
+	case "IpAddress":
+		pdu.Type, pdu.Value = gosnmp.IPAddress, variable.Value
+	case gosnmp.Null.String():
+		pdu.Type = gosnmp.Null
+	case gosnmp.NoSuchObject.String():
+		pdu.Type = gosnmp.NoSuchObject
+	case gosnmp.NoSuchInstance.String():
+		pdu.Type = gosnmp.NoSuchInstance
+	case gosnmp.EndOfMibView.String():
+		pdu.Type = gosnmp.EndOfMibView
+	default:
+		return pdu, fmt.Errorf("variable %s has unsupported type %q", variable.Name, variable.Type)
+	}
+	if err != nil {
+		return pdu, fmt.Errorf("variable %s: invalid %s value %q", variable.Name, variable.Type, variable.Value)
+	}
+	return pdu, nil
+}
+
+// isPrintableASCII reports whether b can be stored as a STRING without losing bytes
+func isPrintableASCII(b []byte) bool {
+	for _, c := range b {
+		if (c < 0x20 || c > 0x7e) && c != '\r' && c != '\n' && c != '\t' {
+			return false
+		}
+	}
+	return true
+}
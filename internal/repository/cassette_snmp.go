@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// recordingSnmpRepository passes SNMP requests to an OLT and records them into a cassette
+type recordingSnmpRepository struct {
+	inner    SnmpRepositoryInterface
+	cassette *Cassette
+}
+
+// NewRecordingSnmpRepository wraps an SNMP repository so every GET and walk is recorded into cassette
+func NewRecordingSnmpRepository(inner SnmpRepositoryInterface, cassette *Cassette) SnmpRepositoryInterface {
+	return &recordingSnmpRepository{inner: inner, cassette: cassette}
+}
+
+// Get performs the GET and records the returned variables
+func (r *recordingSnmpRepository) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	start := time.Now()
+	result, err := r.inner.Get(oids)
+
+	interaction := CassetteInteraction{
+		Kind:       CassetteSnmpGet,
+		OIDs:       append([]string(nil), oids...),
+		Error:      errorString(err),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if result != nil {
+		for _, pdu := range result.Variables {
+			interaction.Variables = append(interaction.Variables, encodeCassetteVariable(pdu))
+		}
+	}
+	r.cassette.record(interaction)
+
+	return result, err
+}
+
+// Walk performs the walk and records every variable handed to walkFunc
+func (r *recordingSnmpRepository) Walk(oid string, walkFunc func(pdu gosnmp.SnmpPDU) error) error {
+	var variables []CassetteVariable
+	start := time.Now()
+	err := r.inner.Walk(oid, func(pdu gosnmp.SnmpPDU) error {
+		variables = append(variables, encodeCassetteVariable(pdu))
+		return walkFunc(pdu)
+	})
+
+	r.cassette.record(CassetteInteraction{
+		Kind:       CassetteSnmpWalk,
+		OID:        oid,
+		Variables:  variables,
+		Error:      errorString(err),
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+
+	return err
+}
+
+// replaySnmpRepository serves the SNMP exchanges of a cassette instead of querying an OLT
+type replaySnmpRepository struct {
+	cassette *Cassette
+}
+
+// NewReplaySnmpRepository creates an SNMP repository that answers from the recordings of cassette.
+// Requests that were not recorded fail with ErrNotInCassette.
+func NewReplaySnmpRepository(cassette *Cassette) SnmpRepositoryInterface {
+	return &replaySnmpRepository{cassette: cassette}
+}
+
+// Get returns the recorded result of the same GET
+func (r *replaySnmpRepository) Get(oids []string) (*gosnmp.SnmpPacket, error) {
+	interaction, err := r.cassette.replay(CassetteSnmpGet, snmpGetKey(oids))
+	if err != nil {
+		return nil, err
+	}
+	if interaction.Error != "" {
+		return nil, errors.New(interaction.Error)
+	}
+
+	packet := &gosnmp.SnmpPacket{Version: gosnmp.Version2c, PDUType: gosnmp.GetResponse}
+	for _, variable := range interaction.Variables {
+		pdu, err := decodeCassetteVariable(variable)
+		if err != nil {
+			return nil, err
+		}
+		packet.Variables = append(packet.Variables, pdu)
+	}
+	return packet, nil
+}
+
+// Walk hands the recorded variables of the same walk to walkFunc
+func (r *replaySnmpRepository) Walk(oid string, walkFunc func(pdu gosnmp.SnmpPDU) error) error {
+	interaction, err := r.cassette.replay(CassetteSnmpWalk, strings.TrimPrefix(oid, "."))
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range interaction.Variables {
+		pdu, err := decodeCassetteVariable(variable)
+		if err != nil {
+			return err
+		}
+		if err := walkFunc(pdu); err != nil {
+			return fmt.Errorf("SNMP Walk failed: %w", err) // Same wrapping as snmpRepository
+		}
+	}
+	if interaction.Error != "" {
+		return errors.New(interaction.Error)
+	}
+	return nil
+}
+
+// errorString returns the message of err, or "" for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// recordingTelnetRepository passes CLI commands to an OLT and records them into a cassette.
+// Connection handling and mode changes are passed through unrecorded.
+type recordingTelnetRepository struct {
+	TelnetRepository
+	cassette *Cassette
+}
+
+// NewRecordingTelnetRepository wraps a CLI session so every command and its output is recorded into cassette
+func NewRecordingTelnetRepository(inner TelnetRepository, cassette *Cassette) TelnetRepository {
+	return &recordingTelnetRepository{TelnetRepository: inner, cassette: cassette}
+}
+
+// NewRecordingTelnetSessionManager creates a session manager whose sessions record into cassette
+func NewRecordingTelnetSessionManager(cfg *config.TelnetConfig, cassette *Cassette) *TelnetSessionManager {
+	return newTelnetSessionManager(cfg, func(cfg *config.TelnetConfig) TelnetRepository {
+		return NewRecordingTelnetRepository(NewTelnetRepository(cfg), cassette)
+	})
+}
+
+// Execute executes the command and records its output
+func (r *recordingTelnetRepository) Execute(ctx context.Context, command string) (*model.TelnetResponse, error) {
+	start := time.Now()
+	resp, err := r.TelnetRepository.Execute(ctx, command)
+	r.recordResponse(command, resp, err, time.Since(start))
+	return resp, err
+}
+
+// ExecuteMulti executes the commands and records every response
+func (r *recordingTelnetRepository) ExecuteMulti(ctx context.Context, commands []string) (*model.TelnetBatchResponse, error) {
+	result, err := r.TelnetRepository.ExecuteMulti(ctx, commands)
+	if result != nil {
+		for i := range result.Responses {
+			resp := &result.Responses[i]
+			r.recordResponse(resp.Command, resp, nil, 0)
+		}
+	}
+	return result, err
+}
+
+// ExecuteWithExpect executes the command and records the result
+func (r *recordingTelnetRepository) ExecuteWithExpect(ctx context.Context, command, expectPattern string) (*model.TelnetResponse, error) {
+	start := time.Now()
+	resp, err := r.TelnetRepository.ExecuteWithExpect(ctx, command, expectPattern)
+	r.recordResponse(command, resp, err, time.Since(start))
+	return resp, err
+}
+
+// SaveConfig saves the configuration and records the result as the "write" command
+func (r *recordingTelnetRepository) SaveConfig() error {
+	start := time.Now()
+	err := r.TelnetRepository.SaveConfig()
+	r.cassette.record(CassetteInteraction{
+		Kind:       CassetteCLI,
+		Command:    "write",
+		Error:      errorString(err),
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+	return err
+}
+
+// ShowRunningConfig retrieves the running configuration and records it
+func (r *recordingTelnetRepository) ShowRunningConfig() (string, error) {
+	start := time.Now()
+	output, err := r.TelnetRepository.ShowRunningConfig()
+	r.cassette.record(CassetteInteraction{
+		Kind:       CassetteCLI,
+		Command:    "show running-config",
+		Output:     output,
+		Error:      errorString(err),
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+	return output, err
+}
+
+// recordResponse records the response of a command; resp is nil if the session was not connected
+func (r *recordingTelnetRepository) recordResponse(command string, resp *model.TelnetResponse, err error, duration time.Duration) {
+	interaction := CassetteInteraction{
+		Kind:       CassetteCLI,
+		Command:    command,
+		Error:      errorString(err),
+		DurationMs: duration.Milliseconds(),
+	}
+	if resp != nil {
+		interaction.Output = resp.Output
+		if interaction.Error == "" && !resp.Success {
+			interaction.Error = resp.Error
+		}
+	}
+	r.cassette.record(interaction)
+}
+
+// replayTelnetRepository serves the CLI exchanges of a cassette instead of talking to an OLT.
+// It is always reachable and tracks the CLI mode like a real session.
+type replayTelnetRepository struct {
+	cassette    *Cassette
+	mu          sync.Mutex
+	connected   bool
+	mode        string
+	connectedAt time.Time
+}
+
+// NewReplayTelnetRepository creates a CLI session that answers from the recordings of cassette.
+// Commands that were not recorded fail with ErrNotInCassette.
+func NewReplayTelnetRepository(cassette *Cassette) TelnetRepository {
+	return &replayTelnetRepository{cassette: cassette, mode: "disconnected"}
+}
+
+// NewReplayTelnetSessionManager creates a session manager whose sessions replay cassette
+func NewReplayTelnetSessionManager(cfg *config.TelnetConfig, cassette *Cassette) *TelnetSessionManager {
+	return newTelnetSessionManager(cfg, func(*config.TelnetConfig) TelnetRepository {
+		return NewReplayTelnetRepository(cassette)
+	})
+}
+
+// Connect marks the session as connected in enable mode
+func (r *replayTelnetRepository) Connect() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.connected, r.mode, r.connectedAt = true, "enable", time.Now()
+	return nil
+}
+
+// Close marks the session as disconnected
+func (r *replayTelnetRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.connected, r.mode = false, "disconnected"
+	return nil
+}
+
+// IsConnected returns the connection status
+func (r *replayTelnetRepository) IsConnected() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.connected
+}
+
+// Reconnect reconnects the session
+func (r *replayTelnetRepository) Reconnect() error {
+	_ = r.Close()
+	return r.Connect()
+}
+
+// Ping succeeds while the session is connected
+func (r *replayTelnetRepository) Ping(ctx context.Context) error {
+	if !r.IsConnected() {
+		return model.NewTelnetError(model.ErrCodeDisconnected, "not connected to OLT", true)
+	}
+	return nil
+}
+
+// Execute returns the recorded output of the command
+func (r *replayTelnetRepository) Execute(ctx context.Context, command string) (*model.TelnetResponse, error) {
+	if !r.IsConnected() {
+		return nil, model.NewTelnetError(model.ErrCodeDisconnected, "not connected to OLT", true)
+	}
+
+	resp := &model.TelnetResponse{
+		Command:   command,
+		Timestamp: time.Now().Format(time.RFC3339),
+	}
+	interaction, err := r.cassette.replay(CassetteCLI, redactCLIKeywords(command))
+	if err != nil {
+		resp.Error = err.Error()
+		return resp, err
+	}
+
+	resp.Output = interaction.Output
+	if interaction.Error != "" {
+		resp.Error = interaction.Error
+		return resp, errors.New(interaction.Error)
+	}
+	resp.Success = true
+	return resp, nil
+}
+
+// ExecuteMulti returns the recorded outputs of the commands
+func (r *replayTelnetRepository) ExecuteMulti(ctx context.Context, commands []string) (*model.TelnetBatchResponse, error) {
+	startTime := time.Now()
+	responses := make([]model.TelnetResponse, 0, len(commands))
+	allSuccess := true
+
+	for _, cmd := range commands {
+		resp, err := r.Execute(ctx, cmd)
+		if err != nil {
+			allSuccess = false
+		}
+		if resp == nil {
+			return &model.TelnetBatchResponse{Responses: responses, TotalTime: time.Since(startTime).String()}, err
+		}
+		responses = append(responses, *resp)
+	}
+
+	return &model.TelnetBatchResponse{
+		Responses: responses,
+		Success:   allSuccess,
+		TotalTime: time.Since(startTime).String(),
+	}, nil
+}
+
+// ExecuteWithExpect returns the recorded result of the command
+func (r *replayTelnetRepository) ExecuteWithExpect(ctx context.Context, command, expectPattern string) (*model.TelnetResponse, error) {
+	return r.Execute(ctx, command)
+}
+
+// EnterEnableMode switches to enable mode
+func (r *replayTelnetRepository) EnterEnableMode() error {
+	return r.setMode("enable")
+}
+
+// EnterConfigMode switches to config mode
+func (r *replayTelnetRepository) EnterConfigMode() error {
+	return r.setMode("config")
+}
+
+// ExitConfigMode returns to enable mode
+func (r *replayTelnetRepository) ExitConfigMode() error {
+	return r.setMode("enable")
+}
+
+// GetCurrentMode returns the current CLI mode
+func (r *replayTelnetRepository) GetCurrentMode() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.mode
+}
+
+// SaveConfig fails like the recorded "write" did
+func (r *replayTelnetRepository) SaveConfig() error {
+	if _, err := r.Execute(context.Background(), "write"); err != nil {
+		return model.NewTelnetError(model.ErrCodeConfigSaveFailed,
+			fmt.Sprintf("failed to save config: %v", err), true)
+	}
+	return nil
+}
+
+// ShowRunningConfig returns the recorded running configuration
+func (r *replayTelnetRepository) ShowRunningConfig() (string, error) {
+	resp, err := r.Execute(context.Background(), "show running-config")
+	if err != nil {
+		return "", err
+	}
+	return resp.Output, nil
+}
+
+// GetConnectionInfo returns connection information of the replay session
+func (r *replayTelnetRepository) GetConnectionInfo() *model.TelnetConnectionInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	uptime := "0s"
+	if r.connected {
+		uptime = time.Since(r.connectedAt).String()
+	}
+	return &model.TelnetConnectionInfo{
+		Transport: "replay",
+		Host:      "cassette",
+		Connected: r.connected,
+		Mode:      r.mode,
+		Uptime:    uptime,
+	}
+}
+
+// setMode changes the tracked CLI mode of a connected session
+func (r *replayTelnetRepository) setMode(mode string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.connected {
+		return model.NewTelnetError(model.ErrCodeDisconnected, "not connected to OLT", true)
+	}
+	r.mode = mode
+	return nil
+}
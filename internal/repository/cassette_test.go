@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+// saveAndLoad writes a cassette to a temporary file and reads it back like a replay would
+func saveAndLoad(t *testing.T, cassette *Cassette) *Cassette {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "olt-1.json")
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("LoadCassette() error = %v", err)
+	}
+	return loaded
+}
+
+func TestCassette_RecordAndReplaySNMP(t *testing.T) {
+	records, err := simulator.Fixture(config.FirmwareV21)
+	if err != nil {
+		t.Fatalf("Fixture() error = %v", err)
+	}
+	agent := simulator.NewSNMPAgent(simulator.SNMPConfig{Community: "public"}, records)
+	if err := agent.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer agent.Close()
+
+	getOIDs := []string{
+		".1.3.6.1.2.1.1.1.0",                                // STRING
+		".1.3.6.1.2.1.1.3.0",                                // Timeticks
+		".1.3.6.1.4.1.3902.1012.3.31.4.1.6.268501248.1",     // Counter64
+		".1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1",   // INTEGER
+		".1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.999", // NoSuchInstance
+	}
+	walkOID := "1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248" // ONU serial numbers (Hex-STRING)
+
+	// Record against the simulated OLT
+	cassette := NewCassette()
+	recorder := NewRecordingSnmpRepository(NewSnmpRepository(agent.SnmpConfig()), cassette)
+	want, err := recorder.Get(getOIDs)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var wantWalk []gosnmp.SnmpPDU
+	if err := recorder.Walk(walkOID, func(pdu gosnmp.SnmpPDU) error {
+		wantWalk = append(wantWalk, pdu)
+		return nil
+	}); err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if len(wantWalk) != 3 || cassette.Len() != 2 {
+		t.Fatalf("recorded %d walk variables and %d interactions, want 3 and 2", len(wantWalk), cassette.Len())
+	}
+
+	// Replay without the OLT: same variables with the same Go types
+	agent.Close()
+	replay := NewReplaySnmpRepository(saveAndLoad(t, cassette))
+
+	got, err := replay.Get(getOIDs)
+	if err != nil {
+		t.Fatalf("replayed Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Variables, want.Variables) {
+		t.Errorf("replayed Get() = %+v, want %+v", got.Variables, want.Variables)
+	}
+
+	var gotWalk []gosnmp.SnmpPDU
+	if err := replay.Walk("."+walkOID, func(pdu gosnmp.SnmpPDU) error {
+		gotWalk = append(gotWalk, pdu)
+		return nil
+	}); err != nil {
+		t.Fatalf("replayed Walk() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotWalk, wantWalk) {
+		t.Errorf("replayed Walk() = %+v, want %+v", gotWalk, wantWalk)
+	}
+
+	// A walk callback error stops the replay like a live walk
+	stop := errors.New("stop")
+	err = replay.Walk(walkOID, func(gosnmp.SnmpPDU) error { return stop })
+	if !errors.Is(err, stop) {
+		t.Errorf("replayed Walk() error = %v, want %v", err, stop)
+	}
+
+	if _, err := replay.Get([]string{".1.3.6.1.2.1.1.5.0"}); !errors.Is(err, ErrNotInCassette) {
+		t.Errorf("Get() of an unrecorded OID error = %v, want ErrNotInCassette", err)
+	}
+}
+
+func TestCassette_RecordAndReplayCLI(t *testing.T) {
+	state := simulator.NewDemoState()
+	state.Update("1/1/1", 2, func(onu *simulator.ONU) {
+		onu.Optical = simulator.Optical{OLTRx: -29.5, ONURx: -30.1, ONUTx: 2.4, Temperature: 48, Voltage: 3.3, BiasCurrent: 12.5}
+	})
+	server := simulator.NewCLIServer(simulator.CLIConfig{}, state)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Record against the simulated OLT
+	cassette := NewCassette(server.TelnetConfig().Password)
+	recorder := NewRecordingTelnetSessionManager(server.TelnetConfig(), cassette)
+	wantOptical, err := recorder.GetPONOpticalInfo(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetPONOpticalInfo() error = %v", err)
+	}
+	wantPorts, err := recorder.GetAllServicePorts(ctx)
+	if err != nil {
+		t.Fatalf("GetAllServicePorts() error = %v", err)
+	}
+	wantConfig, _, err := recorder.GetRunningConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetRunningConfig() error = %v", err)
+	}
+	recorder.Close()
+	server.Close()
+
+	// Replay without the OLT: the parsers see the recorded output again
+	replay := NewReplayTelnetSessionManager(server.TelnetConfig(), saveAndLoad(t, cassette))
+	defer replay.Close()
+
+	gotOptical, err := replay.GetPONOpticalInfo(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("replayed GetPONOpticalInfo() error = %v", err)
+	}
+	if len(gotOptical) != 3 || !reflect.DeepEqual(gotOptical, wantOptical) {
+		t.Errorf("replayed GetPONOpticalInfo() = %+v, want %+v", gotOptical, wantOptical)
+	}
+	gotPorts, err := replay.GetAllServicePorts(ctx)
+	if err != nil {
+		t.Fatalf("replayed GetAllServicePorts() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotPorts, wantPorts) {
+		t.Errorf("replayed GetAllServicePorts() = %+v, want %+v", gotPorts, wantPorts)
+	}
+	gotConfig, _, err := replay.GetRunningConfig(ctx)
+	if err != nil {
+		t.Fatalf("replayed GetRunningConfig() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotConfig, wantConfig) {
+		t.Errorf("replayed GetRunningConfig() differs from the recording")
+	}
+
+	if _, err := replay.ExecuteCommand(ctx, "show gpon onu state gpon-olt_1/1/2"); !errors.Is(err, ErrNotInCassette) {
+		t.Errorf("ExecuteCommand() of an unrecorded command error = %v, want ErrNotInCassette", err)
+	}
+}
+
+func TestCassette_Redaction(t *testing.T) {
+	cassette := NewCassette("olt-community", "telnet-pass", "", "zte")
+	cassette.record(CassetteInteraction{
+		Kind:    CassetteCLI,
+		Command: "onu 9 type ZTE-F609 sn ZTEGC0FFEE09 pw 12345678",
+	})
+	cassette.record(CassetteInteraction{
+		Kind:    CassetteCLI,
+		Command: "show running-config",
+		Output:  "username admin password 0 telnet-pass privilege 15\nsnmp-server community olt-community view AllView ro\nzte keeps this",
+	})
+	cassette.record(CassetteInteraction{
+		Kind:      CassetteSnmpGet,
+		OIDs:      []string{".1.3.6.1.2.1.1.4.0"},
+		Variables: []CassetteVariable{{Name: ".1.3.6.1.2.1.1.4.0", Type: "STRING", Value: "noc olt-community"}},
+	})
+
+	path := filepath.Join(t.TempDir(), "olt-1.json")
+	if err := cassette.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"olt-community", "telnet-pass", "12345678"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "password 0 <redacted>") || !strings.Contains(string(data), "zte keeps this") {
+		t.Errorf("unexpected redaction: %s", data)
+	}
+
+	// Commands carrying a password still match their redacted recording
+	replay := NewReplayTelnetRepository(cassette)
+	if err := replay.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	resp, err := replay.Execute(context.Background(), "onu 9 type ZTE-F609 sn ZTEGC0FFEE09 pw 12345678")
+	if err != nil || !resp.Success {
+		t.Errorf("Execute() = %+v, %v", resp, err)
+	}
+}
+
+func TestCassette_ReplayOrderAndErrors(t *testing.T) {
+	cassette := NewCassette()
+	cassette.record(CassetteInteraction{Kind: CassetteCLI, Command: "show clock", Output: "10:00:00"})
+	cassette.record(CassetteInteraction{Kind: CassetteCLI, Command: "show clock", Output: "10:00:05"})
+	cassette.record(CassetteInteraction{Kind: CassetteCLI, Command: "write", Error: "command timeout"})
+	cassette.record(CassetteInteraction{Kind: CassetteSnmpWalk, OID: "1.3.6.1.2.1.1", Error: "SNMP Walk failed: request timeout"})
+
+	replay := NewReplayTelnetRepository(cassette)
+	ctx := context.Background()
+	if _, err := replay.Execute(ctx, "show clock"); err == nil {
+		t.Fatal("Execute() on a disconnected session succeeded")
+	}
+	if err := replay.Connect(); err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+
+	// Repeated commands are served in recording order, then the last one repeats
+	for _, want := range []string{"10:00:00", "10:00:05", "10:00:05"} {
+		resp, err := replay.Execute(ctx, "show clock")
+		if err != nil || resp.Output != want {
+			t.Errorf("Execute() = %+v, %v, want output %q", resp, err, want)
+		}
+	}
+
+	if err := replay.SaveConfig(); err == nil || !strings.Contains(err.Error(), "command timeout") {
+		t.Errorf("SaveConfig() error = %v, want the recorded timeout", err)
+	}
+	err := NewReplaySnmpRepository(cassette).Walk(".1.3.6.1.2.1.1", func(gosnmp.SnmpPDU) error { return nil })
+	if err == nil || err.Error() != "SNMP Walk failed: request timeout" {
+		t.Errorf("Walk() error = %v, want the recorded error", err)
+	}
+}
+
+func TestLoadCassette_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadCassette(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadCassette() of a missing file succeeded")
+	}
+
+	path := filepath.Join(dir, "future.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "interactions": []}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCassette(path); err == nil || !strings.Contains(err.Error(), "unsupported version") {
+		t.Errorf("LoadCassette() error = %v, want unsupported version", err)
+	}
+}
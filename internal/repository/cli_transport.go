@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/ssh"
+	"github.com/ziutek/telnet"
+)
+
+// CLITransport is the byte stream to the OLT command line. Login, prompt detection and
+// mode handling in telnetRepository work the same over every transport.
+type CLITransport interface {
+	io.ReadWriteCloser
+	SetReadDeadline(t time.Time) error
+	SetWriteDeadline(t time.Time) error
+}
+
+// dialCLI connects to the OLT command line with the transport of the configuration
+func dialCLI(cfg *config.TelnetConfig) (CLITransport, error) {
+	address := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+
+	if cfg.Transport != config.TransportSSH {
+		conn, err := telnet.DialTimeout("tcp", address, cfg.ConnectTimeout)
+		if err != nil {
+			return nil, model.NewTelnetError(model.ErrCodeConnectionFailed,
+				fmt.Sprintf("failed to connect: %v", err), true)
+		}
+		return conn, nil
+	}
+
+	sshCfg := &ssh.Config{
+		User:                cfg.Username,
+		Password:            cfg.Password,
+		HostKeyFingerprints: cfg.SSHHostKeys,
+		LegacyAlgorithms:    cfg.SSHLegacyAlgorithms,
+		Timeout:             cfg.ConnectTimeout,
+	}
+	if cfg.SSHKeyFile != "" {
+		key, err := os.ReadFile(cfg.SSHKeyFile)
+		if err != nil {
+			return nil, model.NewTelnetError(model.ErrCodeAuthFailed,
+				fmt.Sprintf("failed to read SSH key: %v", err), false)
+		}
+		if sshCfg.Signer, err = ssh.ParsePrivateKey(key); err != nil {
+			return nil, model.NewTelnetError(model.ErrCodeAuthFailed,
+				fmt.Sprintf("invalid SSH key %s: %v", cfg.SSHKeyFile, err), false)
+		}
+	}
+
+	conn, err := ssh.Dial("tcp", address, sshCfg)
+	if err != nil {
+		var hostKeyErr *ssh.HostKeyError
+		switch {
+		case errors.As(err, &hostKeyErr):
+			// Not recoverable: the key changed or was never pinned
+			return nil, model.NewTelnetError(model.ErrCodeAuthFailed,
+				fmt.Sprintf("SSH host key %s of %s is not pinned, add it to the OLT's ssh_host_keys or SSH_HOST_KEY_FINGERPRINTS after verifying it",
+					hostKeyErr.Fingerprint, address), false)
+		case errors.Is(err, ssh.ErrAuthFailed):
+			return nil, model.NewTelnetError(model.ErrCodeAuthFailed, err.Error(), false)
+		}
+		return nil, model.NewTelnetError(model.ErrCodeConnectionFailed,
+			fmt.Sprintf("failed to connect: %v", err), true)
+	}
+	return conn, nil
+}
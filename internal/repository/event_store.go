@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"sync"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// EventStore keeps the events received from the OLTs
+type EventStore interface {
+	// Add stores an event
+	Add(event model.OLTEvent)
+
+	// List returns the events matching the query, newest first
+	List(query model.EventQuery) []model.OLTEvent
+}
+
+// memoryEventStore keeps the most recent events in a ring buffer
+type memoryEventStore struct {
+	mu     sync.RWMutex
+	events []model.OLTEvent // Ring buffer
+	next   int              // Position of the next event
+	full   bool             // The buffer has wrapped around
+}
+
+// NewMemoryEventStore creates an event store that keeps the last capacity events in memory.
+// Older events are dropped; all events are lost on restart.
+func NewMemoryEventStore(capacity int) EventStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &memoryEventStore{events: make([]model.OLTEvent, capacity)}
+}
+
+// Add stores an event, replacing the oldest one if the buffer is full
+func (s *memoryEventStore) Add(event model.OLTEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events[s.next] = event
+	s.next = (s.next + 1) % len(s.events)
+	if s.next == 0 {
+		s.full = true
+	}
+}
+
+// List returns the events matching the query, newest first
+func (s *memoryEventStore) List(query model.EventQuery) []model.OLTEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := s.next
+	if s.full {
+		count = len(s.events)
+	}
+
+	result := make([]model.OLTEvent, 0)
+	for i := 1; i <= count; i++ {
+		event := s.events[(s.next-i+len(s.events))%len(s.events)]
+		if !matchesEventQuery(&event, &query) {
+			continue
+		}
+		result = append(result, event)
+		if query.Limit > 0 && len(result) == query.Limit {
+			break
+		}
+	}
+
+	return result
+}
+
+// matchesEventQuery reports whether an event passes all filters of the query
+func matchesEventQuery(event *model.OLTEvent, query *model.EventQuery) bool {
+	switch {
+	case query.OLTID != "" && event.OLTID != query.OLTID:
+		return false
+	case query.Type != "" && event.Type != query.Type:
+		return false
+	case query.Board != 0 && event.Board != query.Board:
+		return false
+	case query.PON != 0 && event.PON != query.PON:
+		return false
+	case query.ONUID != 0 && event.ONUID != query.ONUID:
+		return false
+	case !query.Since.IsZero() && event.ReceivedAt.Before(query.Since):
+		return false
+	case !query.Until.IsZero() && !event.ReceivedAt.Before(query.Until):
+		return false
+	}
+	return true
+}
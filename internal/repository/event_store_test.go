@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestMemoryEventStore(t *testing.T) {
+	store := NewMemoryEventStore(3)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 1; i <= 4; i++ {
+		store.Add(model.OLTEvent{
+			ID:         string(rune('a' + i - 1)),
+			OLTID:      "olt-1",
+			Type:       model.EventOnuOffline,
+			PON:        i % 2,
+			ReceivedAt: start.Add(time.Duration(i) * time.Minute),
+		})
+	}
+
+	// The oldest event was dropped, the rest is returned newest first
+	events := store.List(model.EventQuery{})
+	if len(events) != 3 {
+		t.Fatalf("List() returned %d events, want 3", len(events))
+	}
+	for i, want := range []string{"d", "c", "b"} {
+		if events[i].ID != want {
+			t.Errorf("events[%d].ID = %q, want %q", i, events[i].ID, want)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query model.EventQuery
+		want  []string
+	}{
+		{"limit", model.EventQuery{Limit: 1}, []string{"d"}},
+		{"pon", model.EventQuery{PON: 1}, []string{"c"}},
+		{"type", model.EventQuery{Type: model.EventOnuLOS}, nil},
+		{"olt", model.EventQuery{OLTID: "olt-2"}, nil},
+		{"since and until", model.EventQuery{Since: start.Add(3 * time.Minute), Until: start.Add(4 * time.Minute)}, []string{"c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := store.List(tt.query)
+			if len(events) != len(tt.want) {
+				t.Fatalf("List() returned %d events, want %d", len(events), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if events[i].ID != want {
+					t.Errorf("events[%d].ID = %q, want %q", i, events[i].ID, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// Redis keys of the job store
+const (
+	jobPrefix   = "jobs:job:"  // jobs:job:<id> holds the JSON of a job
+	jobIndexKey = "jobs:index" // Sorted set of all job IDs, scored by submission time
+)
+
+// JobStore persists background jobs
+type JobStore interface {
+	// Save creates or replaces a job. Finished jobs expire after the retention of the store.
+	Save(ctx context.Context, job *model.Job) error
+
+	// Get returns a job by ID
+	Get(ctx context.Context, id string) (*model.Job, error)
+
+	// List returns all jobs, newest first
+	List(ctx context.Context) ([]*model.Job, error)
+}
+
+// redisJobStore keeps jobs in Redis
+type redisJobStore struct {
+	redisClient *redis.Client
+	retention   time.Duration
+}
+
+// NewRedisJobStore creates a job store on the given Redis client. Jobs of all OLTs share the store;
+// finished jobs are removed after retention, unfinished jobs are kept until they finish.
+func NewRedisJobStore(redisClient *redis.Client, retention time.Duration) JobStore {
+	return &redisJobStore{redisClient: redisClient, retention: retention}
+}
+
+// Save creates or replaces a job
+func (s *redisJobStore) Save(ctx context.Context, job *model.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return apperrors.NewInternalError("failed to marshal job", err)
+	}
+
+	var expiration time.Duration
+	if job.Finished() {
+		expiration = s.retention
+	}
+	if err := s.redisClient.Set(ctx, jobPrefix+job.ID, data, expiration).Err(); err != nil {
+		return apperrors.NewRedisError("Set", err)
+	}
+	member := redis.Z{Score: float64(job.CreatedAt.UnixNano()), Member: job.ID}
+	if err := s.redisClient.ZAdd(ctx, jobIndexKey, member).Err(); err != nil {
+		return apperrors.NewRedisError("ZAdd", err)
+	}
+
+	return nil
+}
+
+// Get returns a job by ID
+func (s *redisJobStore) Get(ctx context.Context, id string) (*model.Job, error) {
+	data, err := s.redisClient.Get(ctx, jobPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.NewNotFoundError("job", id)
+	}
+	if err != nil {
+		return nil, apperrors.NewRedisError("Get", err)
+	}
+
+	return decodeJob(data)
+}
+
+// List returns all jobs, newest first. Expired jobs are removed from the index.
+func (s *redisJobStore) List(ctx context.Context) ([]*model.Job, error) {
+	ids, err := s.redisClient.ZRevRange(ctx, jobIndexKey, 0, -1).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError("ZRevRange", err)
+	}
+
+	jobs := make([]*model.Job, 0, len(ids))
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+
+	redisKeys := make([]string, len(ids))
+	for i, id := range ids {
+		redisKeys[i] = jobPrefix + id
+	}
+	values, err := s.redisClient.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		return nil, apperrors.NewRedisError("MGet", err)
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		job, err := decodeJob([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	if len(expired) > 0 {
+		if err := s.redisClient.ZRem(ctx, jobIndexKey, expired...).Err(); err != nil {
+			return nil, apperrors.NewRedisError("ZRem", err)
+		}
+	}
+
+	return jobs, nil
+}
+
+// decodeJob parses a stored job
+func decodeJob(data []byte) (*model.Job, error) {
+	var job model.Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, apperrors.NewInternalError("failed to unmarshal job", err)
+	}
+	return &job, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/redis/go-redis/v9"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestRedisJobStore_Save(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	store := NewRedisJobStore(db, 24*time.Hour)
+	ctx := context.Background()
+
+	job := &model.Job{ID: "a1", Type: model.JobTypeBatchReboot, Status: model.JobRunning, CreatedAt: time.Unix(100, 0)}
+	data, _ := json.Marshal(job)
+
+	// Unfinished jobs are kept until they finish
+	mock.ExpectSet("jobs:job:a1", data, 0).SetVal("OK")
+	mock.ExpectZAdd("jobs:index", redis.Z{Score: float64(time.Unix(100, 0).UnixNano()), Member: "a1"}).SetVal(1)
+	if err := store.Save(ctx, job); err != nil {
+		t.Fatalf("Save(running) error = %v", err)
+	}
+
+	job.Status = model.JobCompleted
+	data, _ = json.Marshal(job)
+	mock.ExpectSet("jobs:job:a1", data, 24*time.Hour).SetVal("OK")
+	mock.ExpectZAdd("jobs:index", redis.Z{Score: float64(time.Unix(100, 0).UnixNano()), Member: "a1"}).SetVal(0)
+	if err := store.Save(ctx, job); err != nil {
+		t.Fatalf("Save(completed) error = %v", err)
+	}
+
+	mock.ExpectGet("jobs:job:a1").SetVal(string(data))
+	got, err := store.Get(ctx, "a1")
+	if err != nil || got.Status != model.JobCompleted || got.Type != model.JobTypeBatchReboot {
+		t.Errorf("Get() = %+v, %v", got, err)
+	}
+
+	mock.ExpectGet("jobs:job:missing").RedisNil()
+	var appErr *apperrors.AppError
+	if _, err := store.Get(ctx, "missing"); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("Get(missing) error = %v, want not found", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRedisJobStore_List(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	store := NewRedisJobStore(db, time.Hour)
+
+	newer, _ := json.Marshal(&model.Job{ID: "b", CreatedAt: time.Unix(200, 0)})
+	older, _ := json.Marshal(&model.Job{ID: "a", CreatedAt: time.Unix(100, 0)})
+
+	mock.ExpectZRevRange("jobs:index", 0, -1).SetVal([]string{"b", "expired", "a"})
+	mock.ExpectMGet("jobs:job:b", "jobs:job:expired", "jobs:job:a").SetVal([]interface{}{string(newer), nil, string(older)})
+	mock.ExpectZRem("jobs:index", "expired").SetVal(1)
+
+	jobs, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(jobs) != 2 || jobs[0].ID != "b" || jobs[1].ID != "a" {
+		t.Errorf("List() = %+v, want b and a, newest first", jobs)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
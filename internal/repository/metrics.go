@@ -0,0 +1,21 @@
+package repository
+
+import "github.com/s4lfanet/go-api-c320/pkg/metrics"
+
+// Instruments of the SNMP and Telnet access, exported on /metrics
+var (
+	snmpWalkDuration = metrics.NewHistogramVec("c320_snmp_walk_duration_seconds",
+		"Duration of SNMP walks by agent and result", nil, "target", "result")
+	telnetSessionWait = metrics.NewHistogramVec("c320_telnet_session_wait_seconds",
+		"Time spent waiting for the Telnet session of an OLT", nil, "target")
+	telnetSessionQueueDepth = metrics.NewGaugeVec("c320_telnet_session_queue_depth",
+		"Requests currently waiting for the Telnet session of an OLT", "target")
+)
+
+// resultLabel returns the result label of an operation
+func resultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
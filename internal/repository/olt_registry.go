@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// OLTConnection bundles the per-device configuration and connections of a single OLT
+type OLTConnection struct {
+	Device               config.OLTDeviceConfig      // Device definition from the registry
+	Config               *config.Config              // Per-device application configuration (OIDs, SNMP, backup dir)
+	TelnetConfig         *config.TelnetConfig        // Per-device Telnet configuration
+	SnmpConn             *gosnmp.GoSNMP              // Shared SNMP connection; nil when replaying a cassette
+	SnmpRepo             SnmpRepositoryInterface     // SNMP repository (Get/Walk)
+	RedisRepo            OnuRedisRepositoryInterface // Redis cache repository, namespaced per OLT
+	OnuRepo              *OnuRepository              // ONU repository for monitoring
+	TelnetSessionManager *TelnetSessionManager       // Dedicated Telnet session manager
+	BackupStore          BackupStore                 // Storage for configuration backups
+	Cassette             *Cassette                   // Recorded or replayed OLT exchanges (CASSETTE_MODE); nil if disabled
+	CassetteFile         string                      // File the recorded exchanges are written to on Close; empty for replay
+}
+
+// OLTRegistry keeps track of all OLTs managed by this instance
+type OLTRegistry struct {
+	mu        sync.RWMutex
+	olts      map[string]*OLTConnection
+	order     []string // Registration order, used for stable listing
+	defaultID string
+}
+
+// NewOLTRegistry creates an empty OLT registry
+func NewOLTRegistry() *OLTRegistry {
+	return &OLTRegistry{
+		olts: make(map[string]*OLTConnection),
+	}
+}
+
+// Register adds an OLT to the registry. The first registered OLT, or the one with
+// ID config.DefaultOLTID, becomes the default OLT served under /api/v1.
+func (r *OLTRegistry) Register(conn *OLTConnection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := conn.Device.ID
+	if _, exists := r.olts[id]; exists {
+		return fmt.Errorf("OLT %s is already registered", id)
+	}
+
+	r.olts[id] = conn
+	r.order = append(r.order, id)
+
+	if r.defaultID == "" || id == config.DefaultOLTID {
+		r.defaultID = id
+	}
+
+	log.Info().Str("olt_id", id).Str("firmware", string(conn.Device.Firmware)).Msg("OLT registered")
+	return nil
+}
+
+// Get returns the OLT with the given ID
+func (r *OLTRegistry) Get(id string) (*OLTConnection, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	conn, ok := r.olts[id]
+	return conn, ok
+}
+
+// Default returns the default OLT, or nil if the registry is empty
+func (r *OLTRegistry) Default() *OLTConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.olts[r.defaultID]
+}
+
+// DefaultID returns the ID of the default OLT
+func (r *OLTRegistry) DefaultID() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.defaultID
+}
+
+// List returns all registered OLTs in registration order
+func (r *OLTRegistry) List() []*OLTConnection {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*OLTConnection, 0, len(r.order))
+	for _, id := range r.order {
+		result = append(result, r.olts[id])
+	}
+	return result
+}
+
+// Close closes the SNMP and Telnet connections of all registered OLTs and writes recorded cassettes
+func (r *OLTRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for _, id := range r.order {
+		conn := r.olts[id]
+
+		if conn.SnmpConn != nil && conn.SnmpConn.Conn != nil {
+			if err := conn.SnmpConn.Conn.Close(); err != nil {
+				log.Error().Err(err).Str("olt_id", id).Msg("Failed to close SNMP connection")
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+
+		if conn.TelnetSessionManager != nil {
+			if err := conn.TelnetSessionManager.Close(); err != nil {
+				log.Error().Err(err).Str("olt_id", id).Msg("Failed to close telnet session manager")
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}
+
+		if conn.Cassette != nil && conn.CassetteFile != "" {
+			if err := conn.Cassette.Save(conn.CassetteFile); err != nil {
+				log.Error().Err(err).Str("olt_id", id).Msg("Failed to save cassette")
+				if firstErr == nil {
+					firstErr = err
+				}
+			} else {
+				log.Info().Str("olt_id", id).Str("cassette", conn.CassetteFile).Int("interactions", conn.Cassette.Len()).Msg("Cassette saved")
+			}
+		}
+	}
+
+	return firstErr
+}
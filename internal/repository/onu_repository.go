@@ -2,7 +2,6 @@ package repository
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/gosnmp/gosnmp"
 	"github.com/rs/zerolog/log"
@@ -13,41 +12,33 @@ import (
 
 // OnuRepository handles ONU data retrieval operations
 type OnuRepository struct {
-	snmp *gosnmp.GoSNMP
+	snmp SnmpRepositoryInterface
 	cfg  *config.Config
 }
 
 // NewOnuRepository creates a new OnuRepository instance
-func NewOnuRepository(snmp *gosnmp.GoSNMP, cfg *config.Config) *OnuRepository {
+func NewOnuRepository(snmp SnmpRepositoryInterface, cfg *config.Config) *OnuRepository {
 	return &OnuRepository{
 		snmp: snmp,
 		cfg:  cfg,
 	}
 }
 
-// CalculatePonIndex calculates PON index based on ZTE C320 V2.1 formula
-func CalculatePonIndex(boardID, ponID int) int {
-	// Formula: 268500992 + (board * 8192) + (pon * 256)
-	return 268500992 + (boardID * 8192) + (ponID * 256)
-}
-
 // GetByBoardIDAndPonID retrieves all ONUs for a specific board and PON
 func (r *OnuRepository) GetByBoardIDAndPonID(ctx context.Context, boardID, ponID int) ([]model.OnuSerialNumber, error) {
 	log.Info().Int("board", boardID).Int("pon", ponID).Msg("Getting ONUs for board and PON")
 
-	ponIndex := CalculatePonIndex(boardID, ponID)
+	ponCfg, err := r.cfg.GetBoardPonConfig(boardID, ponID)
+	if err != nil {
+		return nil, err
+	}
 	var onus []model.OnuSerialNumber
 
-	// Walk ONU table to get all ONUs (OID suffix 5 = Device SN)
-	baseOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.13.3.1.5.%d", ponIndex)
-	err := r.snmp.Walk(baseOID, func(variable gosnmp.SnmpPDU) error {
+	// Walk the serial number column of the firmware profile to get all ONUs
+	baseOID := r.cfg.BaseOID() + ponCfg.OnuSerialNumberOID
+	err = r.snmp.Walk(baseOID, func(variable gosnmp.SnmpPDU) error {
 		// Extract ONU ID from OID (last part after PON index)
-		oidParts := utils.ParseOID(variable.Name)
-		if len(oidParts) < 2 {
-			return nil
-		}
-
-		onuID := oidParts[len(oidParts)-1]
+		onuID := utils.ExtractIDOnuID(variable.Name)
 		serialNumber := utils.ExtractSerialNumber(variable.Value)
 
 		onu := model.OnuSerialNumber{
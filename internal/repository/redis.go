@@ -28,17 +28,29 @@ type OnuRedisRepositoryInterface interface {
 // onuRedisRepo implements OnuRedisRepositoryInterface
 type onuRedisRepo struct {
 	redisClient *redis.Client // Redis client instance
+	keyPrefix   string        // Optional key namespace (e.g. "olt:core-1:") to isolate OLTs sharing one Redis
 }
 
 // NewOnuRedisRepo will create an object that represents the auth repository
 func NewOnuRedisRepo(redisClient *redis.Client) OnuRedisRepositoryInterface { // Constructor for OnuRedisRepository
-	return &onuRedisRepo{redisClient} // Return a new instance with an injected client
+	return &onuRedisRepo{redisClient: redisClient} // Return a new instance with an injected client
+}
+
+// NewOnuRedisRepoWithPrefix creates a repository whose keys are namespaced with the given prefix,
+// so several OLTs can share one Redis database without cache collisions.
+func NewOnuRedisRepoWithPrefix(redisClient *redis.Client, keyPrefix string) OnuRedisRepositoryInterface {
+	return &onuRedisRepo{redisClient: redisClient, keyPrefix: keyPrefix}
+}
+
+// key returns the namespaced Redis key
+func (r *onuRedisRepo) key(key string) string {
+	return r.keyPrefix + key
 }
 
 // GetOnuIDCtx is a method to get onu id from redis
 func (r *onuRedisRepo) GetOnuIDCtx(ctx context.Context, key string) ([]model.OnuID, error) {
 
-	onuBytes, err := r.redisClient.Get(ctx, key).Bytes() // Get value as bytes from Redis using a key
+	onuBytes, err := r.redisClient.Get(ctx, r.key(key)).Bytes() // Get value as bytes from Redis using a key
 
 	// Check for error
 	if err != nil {
@@ -69,7 +81,7 @@ func (r *onuRedisRepo) SetOnuIDCtx(ctx context.Context, key string, seconds int,
 	}
 
 	// Set the key in Redis with the marshaled bytes and expiration time
-	if err := r.redisClient.Set(ctx, key, onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+	if err := r.redisClient.Set(ctx, r.key(key), onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to set onu id to redis") // Log error
 		return apperrors.NewRedisError("Set", err)                                // Return wrapped Redis error
 	}
@@ -79,7 +91,7 @@ func (r *onuRedisRepo) SetOnuIDCtx(ctx context.Context, key string, seconds int,
 
 // DeleteOnuIDCtx is a method to delete onu id from redis
 func (r *onuRedisRepo) DeleteOnuIDCtx(ctx context.Context, key string) error {
-	if err := r.redisClient.Del(ctx, key).Err(); err != nil { // Delete key from Redis
+	if err := r.redisClient.Del(ctx, r.key(key)).Err(); err != nil { // Delete key from Redis
 		log.Error().Err(err).Str("key", key).Msg("Failed to delete onu id from redis") // Log error
 		return apperrors.NewRedisError("Del", err)                                     // Return wrapped Redis error
 	}
@@ -98,7 +110,7 @@ func (r *onuRedisRepo) SaveONUInfoList(
 	}
 
 	// Set key in Redis with expiration
-	if err := r.redisClient.Set(ctx, key, onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+	if err := r.redisClient.Set(ctx, r.key(key), onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to set onu info list to redis") // Log error
 		return apperrors.NewRedisError("Set", err)                                       // Return wrapped Redis error
 	}
@@ -108,7 +120,7 @@ func (r *onuRedisRepo) SaveONUInfoList(
 
 // GetONUInfoList is a method to get one info list from redis
 func (r *onuRedisRepo) GetONUInfoList(ctx context.Context, key string) ([]model.ONUInfoPerBoard, error) {
-	onuBytes, err := r.redisClient.Get(ctx, key).Bytes() // Get value as bytes from Redis
+	onuBytes, err := r.redisClient.Get(ctx, r.key(key)).Bytes() // Get value as bytes from Redis
 	if err != nil {                                      // Check for error
 		// Cache miss is normal behavior, not an error - log as debug only
 		log.Debug().Str("key", key).Msg("Cache miss - key not found in Redis")
@@ -126,7 +138,7 @@ func (r *onuRedisRepo) GetONUInfoList(ctx context.Context, key string) ([]model.
 
 // GetOnlyOnuIDCtx is a method to get only onu id from redis
 func (r *onuRedisRepo) GetOnlyOnuIDCtx(ctx context.Context, key string) ([]model.OnuOnlyID, error) {
-	onuBytes, err := r.redisClient.Get(ctx, key).Bytes() // Get value as bytes from Redis
+	onuBytes, err := r.redisClient.Get(ctx, r.key(key)).Bytes() // Get value as bytes from Redis
 	if err != nil {                                      // Check for error
 		// Cache miss is normal behavior, not an error - log as debug only
 		log.Debug().Str("key", key).Msg("Cache miss - key not found in Redis")
@@ -151,7 +163,7 @@ func (r *onuRedisRepo) SaveOnlyOnuIDCtx(ctx context.Context, key string, seconds
 	}
 
 	// Set key in Redis with expiration
-	if err := r.redisClient.Set(ctx, key, onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
+	if err := r.redisClient.Set(ctx, r.key(key), onuBytes, time.Second*time.Duration(seconds)).Err(); err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to set onu id to redis") // Log error
 		return apperrors.NewRedisError("Set", err)                                // Return wrapped Redis error
 	}
@@ -162,7 +174,7 @@ func (r *onuRedisRepo) SaveOnlyOnuIDCtx(ctx context.Context, key string, seconds
 // Delete is a method to delete any key from redis
 func (r *onuRedisRepo) Delete(ctx context.Context, key string) error {
 	// Delete key from Redis
-	result, err := r.redisClient.Del(ctx, key).Result()
+	result, err := r.redisClient.Del(ctx, r.key(key)).Result()
 	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to delete key from redis")
 		return apperrors.NewRedisError("Delete", err)
@@ -500,3 +500,26 @@ func TestDelete_Error(t *testing.T) {
 		t.Errorf("Unmet expectations: %v", err)
 	}
 }
+
+func TestOnuRedisRepoWithPrefix_NamespacesKeys(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	repo := NewOnuRedisRepoWithPrefix(db, "olt:core-1:")
+
+	ctx := context.Background()
+	expectedData := []model.OnuID{{Board: 1, PON: 1, ID: 1}}
+	dataBytes, _ := json.Marshal(expectedData)
+
+	mock.ExpectGet("olt:core-1:board_1_pon_1").SetVal(string(dataBytes))
+	mock.ExpectDel("olt:core-1:board_1_pon_1").SetVal(1)
+
+	if _, err := repo.GetOnuIDCtx(ctx, "board_1_pon_1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := repo.Delete(ctx, "board_1_pon_1"); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
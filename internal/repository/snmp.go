@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/pkg/snmp"
 )
 
 // SnmpRepositoryInterface is an interface that represents the SNMP repository contract
@@ -16,9 +18,10 @@ type SnmpRepositoryInterface interface {
 
 // snmpRepository is a struct that implements SnmpRepositoryInterface
 type snmpRepository struct {
-	target    string // SNMP target IP address
-	community string // SNMP community string
-	port      uint16 // SNMP port number
+	target    string             // SNMP target IP address
+	community string             // SNMP community string
+	port      uint16             // SNMP port number
+	v3        *config.SnmpConfig // SNMPv3 credentials; nil uses the v2c community
 }
 
 // NewPonRepository is a constructor function to create a new instance of snmpRepository
@@ -30,6 +33,19 @@ func NewPonRepository(target string, community string, port uint16) SnmpReposito
 	}
 }
 
+// NewSnmpRepository creates an SNMP repository from the connection settings of an OLT (v2c or v3)
+func NewSnmpRepository(cfg config.SnmpConfig) SnmpRepositoryInterface {
+	repo := &snmpRepository{
+		target:    cfg.IP,
+		community: cfg.Community,
+		port:      cfg.Port,
+	}
+	if cfg.IsV3() {
+		repo.v3 = &cfg
+	}
+	return repo
+}
+
 // buildSNMPInstance for creating a new SNMP instance
 func (r *snmpRepository) buildSNMPInstance() (*gosnmp.GoSNMP, error) {
 	params := &gosnmp.GoSNMP{ // Initialize GoSNMP struct with parameters
@@ -40,6 +56,9 @@ func (r *snmpRepository) buildSNMPInstance() (*gosnmp.GoSNMP, error) {
 		Timeout:   time.Duration(3) * time.Second, // SNMP timeout set to 3 seconds
 		Retries:   1,                              // Number of retries for SNMP requests
 	}
+	if r.v3 != nil {
+		snmp.ApplySecurity(params, *r.v3) // Switch to SNMPv3 with the USM credentials
+	}
 
 	// Set logger to nil to disable logging (default behavior of gosnmp if not set)
 	// Connect creates a udp connection
@@ -82,7 +101,9 @@ func (r *snmpRepository) Walk(oid string, walkFunc func(pdu gosnmp.SnmpPDU) erro
 		}
 	}(snmp.Conn)
 
+	start := time.Now()
 	err = snmp.Walk(oid, walkFunc) // Perform SNMP WALK operation with the callback function
+	snmpWalkDuration.Observe(time.Since(start).Seconds(), r.target, resultLabel(err))
 	if err != nil {
 		return fmt.Errorf("SNMP Walk failed: %w", err) // Return wrapped error on failure
 	}
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
 )
 
 func TestNewPonRepository(t *testing.T) {
@@ -233,6 +234,38 @@ func TestSnmpRepository_StructFields(t *testing.T) {
 	}
 }
 
+func TestNewSnmpRepository_SNMPv3(t *testing.T) {
+	repo := NewSnmpRepository(config.SnmpConfig{
+		IP:             "127.0.0.1",
+		Port:           161,
+		Version:        "3",
+		User:           "nms",
+		AuthProtocol:   "SHA",
+		AuthPassphrase: "auth-secret",
+		PrivProtocol:   "DES",
+		PrivPassphrase: "priv-secret",
+	})
+
+	snmpRepo, ok := repo.(*snmpRepository)
+	if !ok {
+		t.Fatal("Failed to type assert to *snmpRepository")
+	}
+
+	// Connect only opens a UDP socket, no agent is needed
+	instance, err := snmpRepo.buildSNMPInstance()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer instance.Conn.Close()
+
+	if instance.Version != gosnmp.Version3 || instance.MsgFlags&gosnmp.AuthPriv != gosnmp.AuthPriv {
+		t.Errorf("Expected SNMPv3 authPriv, got version %v, flags %v", instance.Version, instance.MsgFlags)
+	}
+	if usm, ok := instance.SecurityParameters.(*gosnmp.UsmSecurityParameters); !ok || usm.UserName != "nms" || usm.PrivacyProtocol != gosnmp.DES {
+		t.Errorf("Unexpected USM parameters: %+v", instance.SecurityParameters)
+	}
+}
+
 func TestSnmpRepository_ZeroPort(t *testing.T) {
 	// Test with port 0 (should be allowed but won't connect)
 	repo := NewPonRepository("localhost", "public", 0)
@@ -12,7 +12,6 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/s4lfanet/go-api-c320/config"
 	"github.com/s4lfanet/go-api-c320/internal/model"
-	"github.com/ziutek/telnet"
 )
 
 // TelnetRepository defines the interface for Telnet operations
@@ -22,6 +21,7 @@ type TelnetRepository interface {
 	Close() error
 	IsConnected() bool
 	Reconnect() error
+	Ping(ctx context.Context) error
 
 	// Command execution
 	Execute(ctx context.Context, command string) (*model.TelnetResponse, error)
@@ -40,10 +40,12 @@ type TelnetRepository interface {
 	GetConnectionInfo() *model.TelnetConnectionInfo
 }
 
-// telnetRepository implements TelnetRepository interface
+// telnetRepository implements TelnetRepository interface over Telnet or SSH
 type telnetRepository struct {
 	config       *config.TelnetConfig
-	conn         *telnet.Conn
+	dial         func(cfg *config.TelnetConfig) (CLITransport, error)
+	subModes     *regexp.Regexp // Prompts of configuration sub-modes, e.g. ZXAN(config-if)#
+	conn         CLITransport
 	currentMode  string
 	connected    bool
 	connectedAt  time.Time
@@ -54,8 +56,11 @@ type telnetRepository struct {
 
 // NewTelnetRepository creates a new telnet repository instance
 func NewTelnetRepository(cfg *config.TelnetConfig) TelnetRepository {
+	hostname := strings.TrimSuffix(cfg.PromptEnable, "#")
 	return &telnetRepository{
 		config:      cfg,
+		dial:        dialCLI,
+		subModes:    regexp.MustCompile(regexp.QuoteMeta(hostname) + `\([\w-]+\)#`),
 		currentMode: "disconnected",
 		connected:   false,
 	}
@@ -73,13 +78,12 @@ func (r *telnetRepository) Connect() error {
 
 	// Establish connection
 	address := fmt.Sprintf("%s:%d", r.config.Host, r.config.Port)
-	log.Info().Str("address", address).Msg("Connecting to OLT via Telnet")
+	log.Info().Str("address", address).Str("transport", r.transport()).Msg("Connecting to OLT CLI")
 
-	conn, err := telnet.DialTimeout("tcp", address, r.config.ConnectTimeout)
+	conn, err := r.dial(r.config)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to connect to OLT")
-		return model.NewTelnetError(model.ErrCodeConnectionFailed,
-			fmt.Sprintf("failed to connect: %v", err), true)
+		return err
 	}
 
 	r.conn = conn
@@ -91,56 +95,74 @@ func (r *telnetRepository) Connect() error {
 	_ = r.conn.SetWriteDeadline(time.Now().Add(r.config.WriteTimeout))
 
 	// Login sequence
-	if err := r.login(); err != nil {
+	mode, err := r.login()
+	if err != nil {
 		r.conn.Close()
 		r.conn = nil
 		return err
 	}
 
 	r.connected = true
-	r.currentMode = "user"
+	r.currentMode = mode
 
-	log.Info().Str("mode", r.currentMode).Msg("Telnet connection established")
+	log.Info().Str("mode", r.currentMode).Str("transport", r.transport()).Msg("CLI connection established")
 	return nil
 }
 
-// login performs the login sequence
-func (r *telnetRepository) login() error {
+// transport returns the name of the configured transport
+func (r *telnetRepository) transport() string {
+	if r.config.Transport == "" {
+		return config.TransportTelnet
+	}
+	return r.config.Transport
+}
+
+// login performs the login sequence and returns the mode the CLI starts in.
+// Over SSH the user is authenticated by the SSH handshake and the CLI usually starts at a prompt;
+// Telnet (and SSH servers that run the CLI login themselves) ask for username and password first.
+func (r *telnetRepository) login() (string, error) {
 	// Wait for username prompt
 	log.Debug().Msg("Waiting for username prompt")
-	if err := r.expectString("Username:", r.config.Timeout); err != nil {
-		return model.NewTelnetError(model.ErrCodeAuthFailed,
+	output, err := r.readUntil([]string{"Username:", r.config.PromptUser, r.config.PromptEnable}, r.config.Timeout)
+	if err != nil {
+		return "", model.NewTelnetError(model.ErrCodeAuthFailed,
 			"username prompt not received", false)
 	}
+	if !strings.Contains(output, "Username:") {
+		log.Info().Msg("Login successful")
+		return r.modeFromPrompt(output), nil
+	}
 
 	// Send username
 	log.Debug().Str("username", r.config.Username).Msg("Sending username")
 	if err := r.sendCommand(r.config.Username); err != nil {
-		return err
+		return "", err
 	}
 
 	// Wait for password prompt
 	log.Debug().Msg("Waiting for password prompt")
 	if err := r.expectString("Password:", r.config.Timeout); err != nil {
-		return model.NewTelnetError(model.ErrCodeAuthFailed,
+		return "", model.NewTelnetError(model.ErrCodeAuthFailed,
 			"password prompt not received", false)
 	}
 
 	// Send password
 	log.Debug().Msg("Sending password")
 	if err := r.sendCommand(r.config.Password); err != nil {
-		return err
+		return "", err
 	}
 
-	// Wait for user prompt
+	// Wait for user prompt; privileged accounts land directly at the enable prompt.
+	// A new username prompt means the credentials were rejected.
 	log.Debug().Str("prompt", r.config.PromptUser).Msg("Waiting for user prompt")
-	if err := r.expectString(r.config.PromptUser, r.config.Timeout); err != nil {
-		return model.NewTelnetError(model.ErrCodeAuthFailed,
+	output, err = r.readUntil([]string{r.config.PromptUser, r.config.PromptEnable, "Username:"}, r.config.Timeout)
+	if err != nil || r.modeFromPrompt(output) == "" {
+		return "", model.NewTelnetError(model.ErrCodeAuthFailed,
 			"login failed - user prompt not received", false)
 	}
 
 	log.Info().Msg("Login successful")
-	return nil
+	return r.modeFromPrompt(output), nil
 }
 
 // Close closes the telnet connection
@@ -166,7 +188,7 @@ func (r *telnetRepository) Close() error {
 	r.connected = false
 	r.currentMode = "disconnected"
 
-	log.Info().Msg("Telnet connection closed")
+	log.Info().Str("transport", r.transport()).Msg("CLI connection closed")
 	return err
 }
 
@@ -184,6 +206,66 @@ func (r *telnetRepository) Reconnect() error {
 	return r.Connect()
 }
 
+// Ping checks that the session is alive by sending an empty line and waiting for the prompt.
+// The tracked mode is corrected from the prompt, e.g. after a command timed out in config mode.
+func (r *telnetRepository) Ping(ctx context.Context) error {
+	r.commandMu.Lock()
+	defer r.commandMu.Unlock()
+
+	if !r.IsConnected() {
+		return model.NewTelnetError(model.ErrCodeDisconnected,
+			"not connected to OLT", true)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	timeout := r.config.Timeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	if err := r.sendCommand(""); err != nil {
+		return err
+	}
+	output, err := r.readUntilPrompt(timeout)
+	if err != nil {
+		return err
+	}
+
+	if mode := r.modeFromPrompt(output); mode != "" {
+		r.currentMode = mode
+	}
+	return nil
+}
+
+// modeFromPrompt returns the mode of the last prompt in output, or "" if there is none
+func (r *telnetRepository) modeFromPrompt(output string) string {
+	mode, last := "", -1
+	for prompt, m := range map[string]string{
+		r.config.PromptUser:   "user",
+		r.config.PromptEnable: "enable",
+		r.config.PromptConfig: "config",
+	} {
+		if i := strings.LastIndex(output, prompt); prompt != "" && i > last {
+			mode, last = m, i
+		}
+	}
+	if loc := r.lastSubModePrompt(output); loc != nil && loc[0] > last {
+		mode = "config"
+	}
+	return mode
+}
+
+// lastSubModePrompt returns the position of the last configuration sub-mode prompt in output, or nil
+func (r *telnetRepository) lastSubModePrompt(output string) []int {
+	matches := r.subModes.FindAllStringIndex(output, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[len(matches)-1]
+}
+
 // EnterEnableMode enters enable (privileged) mode
 func (r *telnetRepository) EnterEnableMode() error {
 	r.mu.Lock()
@@ -205,7 +287,7 @@ func (r *telnetRepository) EnterEnableMode() error {
 	}
 
 	// Check if password prompt appears
-	output, _ := r.readUntilPrompt(r.config.Timeout)
+	output, _ := r.readUntil([]string{"Password:", r.config.PromptEnable, r.config.PromptUser}, r.config.Timeout)
 	if strings.Contains(output, "Password:") {
 		// Send enable password
 		if err := r.sendCommand(r.config.EnablePassword); err != nil {
@@ -423,12 +505,15 @@ func (r *telnetRepository) ExecuteWithExpect(ctx context.Context, command, expec
 func (r *telnetRepository) SaveConfig() error {
 	log.Info().Msg("Saving configuration")
 
-	// Exit to enable mode if in config mode
+	// "write" is a privileged command: exit config mode or enter enable mode first
 	if r.GetCurrentMode() == "config" {
 		if err := r.ExitConfigMode(); err != nil {
 			return err
 		}
 	}
+	if err := r.EnterEnableMode(); err != nil {
+		return err
+	}
 
 	resp, err := r.Execute(context.Background(), "write")
 	if err != nil {
@@ -436,9 +521,9 @@ func (r *telnetRepository) SaveConfig() error {
 			fmt.Sprintf("failed to save config: %v", err), true)
 	}
 
-	if !resp.Success {
+	if !resp.Success || strings.Contains(strings.ToLower(resp.Output), "error") {
 		return model.NewTelnetError(model.ErrCodeConfigSaveFailed,
-			"config save command failed", true)
+			fmt.Sprintf("config save command failed: %s", resp.Output), true)
 	}
 
 	log.Info().Msg("Configuration saved successfully")
@@ -465,6 +550,7 @@ func (r *telnetRepository) GetConnectionInfo() *model.TelnetConnectionInfo {
 	}
 
 	return &model.TelnetConnectionInfo{
+		Transport:  r.transport(),
 		Host:       r.config.Host,
 		Port:       r.config.Port,
 		Connected:  r.connected,
@@ -492,6 +578,12 @@ func (r *telnetRepository) sendCommand(command string) error {
 
 // expectString waits for a specific string to appear
 func (r *telnetRepository) expectString(pattern string, timeout time.Duration) error {
+	_, err := r.readUntil([]string{pattern}, timeout)
+	return err
+}
+
+// readUntil reads until one of the patterns appears and returns the output read so far
+func (r *telnetRepository) readUntil(patterns []string, timeout time.Duration) (string, error) {
 	r.lastActivity = time.Now()
 	_ = r.conn.SetReadDeadline(time.Now().Add(timeout))
 
@@ -503,21 +595,23 @@ func (r *telnetRepository) expectString(pattern string, timeout time.Duration) e
 		n, err := r.conn.Read(data)
 		if err != nil {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-				return model.NewTelnetError(model.ErrCodeTimeout,
-					fmt.Sprintf("timeout waiting for '%s'", pattern), true)
+				return buffer, model.NewTelnetError(model.ErrCodeTimeout,
+					fmt.Sprintf("timeout waiting for '%s'", strings.Join(patterns, "' or '")), true)
 			}
-			return model.NewTelnetError(model.ErrCodeCommandFailed,
+			return buffer, model.NewTelnetError(model.ErrCodeCommandFailed,
 				fmt.Sprintf("read error: %v", err), true)
 		}
 
 		buffer += string(data[:n])
-		if strings.Contains(buffer, pattern) {
-			return nil
+		for _, pattern := range patterns {
+			if strings.Contains(buffer, pattern) {
+				return buffer, nil
+			}
 		}
 	}
 
-	return model.NewTelnetError(model.ErrCodeTimeout,
-		fmt.Sprintf("timeout waiting for '%s'", pattern), true)
+	return buffer, model.NewTelnetError(model.ErrCodeTimeout,
+		fmt.Sprintf("timeout waiting for '%s'", strings.Join(patterns, "' or '")), true)
 }
 
 // readUntilPrompt reads until a prompt is detected
@@ -536,10 +630,8 @@ func (r *telnetRepository) readUntilPrompt(timeout time.Duration) (string, error
 		if err != nil {
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
 				// Timeout - check if we have a prompt
-				for _, prompt := range prompts {
-					if strings.Contains(buffer, prompt) {
-						return buffer, nil
-					}
+				if r.hasPrompt(buffer, prompts) {
+					return buffer, nil
 				}
 				return buffer, model.NewTelnetError(model.ErrCodeTimeout,
 					"timeout reading response", true)
@@ -551,10 +643,8 @@ func (r *telnetRepository) readUntilPrompt(timeout time.Duration) (string, error
 		buffer += string(data[:n])
 
 		// Check for prompts
-		for _, prompt := range prompts {
-			if strings.Contains(buffer, prompt) {
-				return buffer, nil
-			}
+		if r.hasPrompt(buffer, prompts) {
+			return buffer, nil
 		}
 	}
 
@@ -562,6 +652,16 @@ func (r *telnetRepository) readUntilPrompt(timeout time.Duration) (string, error
 		"timeout waiting for prompt", true)
 }
 
+// hasPrompt reports whether output contains one of the prompts or a configuration sub-mode prompt
+func (r *telnetRepository) hasPrompt(output string, prompts []string) bool {
+	for _, prompt := range prompts {
+		if strings.Contains(output, prompt) {
+			return true
+		}
+	}
+	return r.subModes.MatchString(output)
+}
+
 // cleanOutput removes command echo and prompt from output
 func (r *telnetRepository) cleanOutput(output, command string) string {
 	// Remove command echo
@@ -572,6 +672,7 @@ func (r *telnetRepository) cleanOutput(output, command string) string {
 	for _, prompt := range prompts {
 		output = strings.ReplaceAll(output, prompt, "")
 	}
+	output = r.subModes.ReplaceAllString(output, "")
 
 	// Remove ANSI escape codes
 	ansiRegex := regexp.MustCompile(`\x1b\[[0-9;]*[a-zA-Z]`)
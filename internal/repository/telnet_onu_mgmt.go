@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -16,27 +17,15 @@ func (m *TelnetSessionManager) RebootONU(ctx context.Context, req *model.ONURebo
 		Int("onu_id", req.ONUID).
 		Msg("Rebooting ONU via Telnet")
 
-	// Enter PON interface configuration mode
-	interfaceCmd := fmt.Sprintf("interface gpon-olt_%s", req.PONPort)
-	if _, err := m.ExecuteCommand(ctx, interfaceCmd); err != nil {
-		return fmt.Errorf("failed to enter interface mode: %w", err)
-	}
-
 	// Execute ONU reset command
 	resetCmd := fmt.Sprintf("onu reset %d", req.ONUID)
-	if _, err := m.ExecuteCommand(ctx, resetCmd); err != nil {
-		// Check if error is due to ONU not existing
-		if strings.Contains(err.Error(), "not exist") || strings.Contains(err.Error(), "invalid") {
+	if err := m.executeONUCommand(ctx, req.PONPort, req.ONUID, resetCmd); err != nil {
+		if errors.Is(err, errONUNotExist) {
 			return fmt.Errorf("ONU %s/%d not found", req.PONPort, req.ONUID)
 		}
 		return fmt.Errorf("failed to reset ONU: %w", err)
 	}
 
-	// Exit interface mode
-	if _, err := m.ExecuteCommand(ctx, "exit"); err != nil {
-		log.Warn().Err(err).Msg("Failed to exit interface mode, session will auto-recover")
-	}
-
 	log.Info().
 		Str("pon_port", req.PONPort).
 		Int("onu_id", req.ONUID).
@@ -53,27 +42,15 @@ func (m *TelnetSessionManager) BlockONU(ctx context.Context, req *model.ONUBlock
 		Bool("block", req.Block).
 		Msg("Blocking ONU via Telnet")
 
-	// Enter PON interface configuration mode
-	interfaceCmd := fmt.Sprintf("interface gpon-olt_%s", req.PONPort)
-	if _, err := m.ExecuteCommand(ctx, interfaceCmd); err != nil {
-		return fmt.Errorf("failed to enter interface mode: %w", err)
-	}
-
 	// Execute ONU state disable command
 	stateCmd := fmt.Sprintf("onu %d state disable", req.ONUID)
-	if _, err := m.ExecuteCommand(ctx, stateCmd); err != nil {
-		// Check if error is due to ONU not existing
-		if strings.Contains(err.Error(), "not exist") || strings.Contains(err.Error(), "invalid") {
+	if err := m.executeONUCommand(ctx, req.PONPort, req.ONUID, stateCmd); err != nil {
+		if errors.Is(err, errONUNotExist) {
 			return fmt.Errorf("ONU %s/%d not found", req.PONPort, req.ONUID)
 		}
 		return fmt.Errorf("failed to disable ONU: %w", err)
 	}
 
-	// Exit interface mode
-	if _, err := m.ExecuteCommand(ctx, "exit"); err != nil {
-		log.Warn().Err(err).Msg("Failed to exit interface mode, session will auto-recover")
-	}
-
 	log.Info().
 		Str("pon_port", req.PONPort).
 		Int("onu_id", req.ONUID).
@@ -90,27 +67,15 @@ func (m *TelnetSessionManager) UnblockONU(ctx context.Context, req *model.ONUBlo
 		Bool("block", req.Block).
 		Msg("Unblocking ONU via Telnet")
 
-	// Enter PON interface configuration mode
-	interfaceCmd := fmt.Sprintf("interface gpon-olt_%s", req.PONPort)
-	if _, err := m.ExecuteCommand(ctx, interfaceCmd); err != nil {
-		return fmt.Errorf("failed to enter interface mode: %w", err)
-	}
-
 	// Execute ONU state enable command
 	stateCmd := fmt.Sprintf("onu %d state enable", req.ONUID)
-	if _, err := m.ExecuteCommand(ctx, stateCmd); err != nil {
-		// Check if error is due to ONU not existing
-		if strings.Contains(err.Error(), "not exist") || strings.Contains(err.Error(), "invalid") {
+	if err := m.executeONUCommand(ctx, req.PONPort, req.ONUID, stateCmd); err != nil {
+		if errors.Is(err, errONUNotExist) {
 			return fmt.Errorf("ONU %s/%d not found", req.PONPort, req.ONUID)
 		}
 		return fmt.Errorf("failed to enable ONU: %w", err)
 	}
 
-	// Exit interface mode
-	if _, err := m.ExecuteCommand(ctx, "exit"); err != nil {
-		log.Warn().Err(err).Msg("Failed to exit interface mode, session will auto-recover")
-	}
-
 	log.Info().
 		Str("pon_port", req.PONPort).
 		Int("onu_id", req.ONUID).
@@ -127,29 +92,17 @@ func (m *TelnetSessionManager) UpdateDescription(ctx context.Context, req *model
 		Str("description", req.Description).
 		Msg("Updating ONU description via Telnet")
 
-	// Enter PON interface configuration mode
-	interfaceCmd := fmt.Sprintf("interface gpon-olt_%s", req.PONPort)
-	if _, err := m.ExecuteCommand(ctx, interfaceCmd); err != nil {
-		return fmt.Errorf("failed to enter interface mode: %w", err)
-	}
-
 	// Execute ONU name command
 	// Escape quotes in description
 	description := strings.ReplaceAll(req.Description, `"`, `\"`)
 	nameCmd := fmt.Sprintf(`onu %d name "%s"`, req.ONUID, description)
-	if _, err := m.ExecuteCommand(ctx, nameCmd); err != nil {
-		// Check if error is due to ONU not existing
-		if strings.Contains(err.Error(), "not exist") || strings.Contains(err.Error(), "invalid") {
+	if err := m.executeONUCommand(ctx, req.PONPort, req.ONUID, nameCmd); err != nil {
+		if errors.Is(err, errONUNotExist) {
 			return fmt.Errorf("ONU %s/%d not found", req.PONPort, req.ONUID)
 		}
 		return fmt.Errorf("failed to update ONU description: %w", err)
 	}
 
-	// Exit interface mode
-	if _, err := m.ExecuteCommand(ctx, "exit"); err != nil {
-		log.Warn().Err(err).Msg("Failed to exit interface mode, session will auto-recover")
-	}
-
 	log.Info().
 		Str("pon_port", req.PONPort).
 		Int("onu_id", req.ONUID).
@@ -166,27 +119,15 @@ func (m *TelnetSessionManager) DeleteONU(ctx context.Context, req *model.ONUDele
 		Int("onu_id", req.ONUID).
 		Msg("Deleting ONU configuration via Telnet")
 
-	// Enter PON interface configuration mode
-	interfaceCmd := fmt.Sprintf("interface gpon-olt_%s", req.PONPort)
-	if _, err := m.ExecuteCommand(ctx, interfaceCmd); err != nil {
-		return fmt.Errorf("failed to enter interface mode: %w", err)
-	}
-
 	// Execute no onu command to delete ONU
 	deleteCmd := fmt.Sprintf("no onu %d", req.ONUID)
-	if _, err := m.ExecuteCommand(ctx, deleteCmd); err != nil {
-		// Check if error is due to ONU not existing
-		if strings.Contains(err.Error(), "not exist") || strings.Contains(err.Error(), "invalid") {
+	if err := m.executeONUCommand(ctx, req.PONPort, req.ONUID, deleteCmd); err != nil {
+		if errors.Is(err, errONUNotExist) {
 			return fmt.Errorf("ONU %s/%d not found", req.PONPort, req.ONUID)
 		}
 		return fmt.Errorf("failed to delete ONU: %w", err)
 	}
 
-	// Exit interface mode
-	if _, err := m.ExecuteCommand(ctx, "exit"); err != nil {
-		log.Warn().Err(err).Msg("Failed to exit interface mode, session will auto-recover")
-	}
-
 	log.Info().
 		Str("pon_port", req.PONPort).
 		Int("onu_id", req.ONUID).
@@ -194,3 +135,31 @@ func (m *TelnetSessionManager) DeleteONU(ctx context.Context, req *model.ONUDele
 
 	return nil
 }
+
+// errONUNotExist is returned by executeONUCommand when the OLT does not know the ONU
+var errONUNotExist = errors.New("ONU does not exist")
+
+// executeONUCommand runs an ONU command inside interface gpon-olt_<ponPort> in configuration mode
+func (m *TelnetSessionManager) executeONUCommand(ctx context.Context, ponPort string, onuID int, command string) error {
+	commands := []string{
+		fmt.Sprintf("interface gpon-olt_%s", ponPort),
+		command,
+		"exit",
+	}
+
+	result, err := m.ExecuteInConfigMode(ctx, commands)
+	if err != nil {
+		return err
+	}
+
+	for _, resp := range result.Responses {
+		if resp.Success {
+			continue
+		}
+		if strings.Contains(strings.ToLower(resp.Output), "not exist") {
+			return errONUNotExist
+		}
+		return fmt.Errorf("%s on ONU %s/%d: %s", resp.Command, ponPort, onuID, resp.Error)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/parser"
+)
+
+// ShowRunningConfig retrieves the raw output of "show running-config"
+func (m *TelnetSessionManager) ShowRunningConfig(ctx context.Context) (string, error) {
+	// Disable paging so the whole configuration is returned at once
+	if _, err := m.ExecuteCommand(ctx, "terminal length 0"); err != nil {
+		log.Warn().Err(err).Msg("Failed to disable terminal paging")
+	}
+
+	resp, err := m.ExecuteCommand(ctx, "show running-config")
+	if err != nil {
+		return "", fmt.Errorf("failed to get running-config: %w", err)
+	}
+
+	return resp.Output, nil
+}
+
+// GetRunningConfig retrieves and parses "show running-config".
+// The raw output is returned alongside the parsed configuration.
+func (m *TelnetSessionManager) GetRunningConfig(ctx context.Context) (*model.RunningConfig, string, error) {
+	raw, err := m.ShowRunningConfig(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return parser.ParseRunningConfig(raw), raw, nil
+}
+
+// GetPONRunningConfig retrieves the ONU declarations of a PON port from "show running-config interface gpon-olt_x/y/z"
+func (m *TelnetSessionManager) GetPONRunningConfig(ctx context.Context, ponPort string) ([]model.ONUDeclaration, error) {
+	cmd := fmt.Sprintf("show running-config interface gpon-olt_%s", ponPort)
+
+	resp, err := m.ExecuteCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PON running-config: %w", err)
+	}
+
+	return parser.ParsePONDeclarations(resp.Output), nil
+}
+
+// GetONURunningConfig retrieves TCONT, GEM port and service-port configuration of an ONU
+// from "show running-config interface gpon-onu_x/y/z:n"
+func (m *TelnetSessionManager) GetONURunningConfig(ctx context.Context, ponPort string, onuID int) (*model.ONURunningConfig, error) {
+	cmd := fmt.Sprintf("show running-config interface gpon-onu_%s:%d", ponPort, onuID)
+
+	resp, err := m.ExecuteCommand(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ONU running-config: %w", err)
+	}
+
+	cfg := parser.ParseONUConfig(resp.Output)
+	cfg.PONPort = ponPort
+	cfg.ONUID = onuID
+
+	return cfg, nil
+}
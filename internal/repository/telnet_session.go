@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,112 +12,286 @@ import (
 	"github.com/s4lfanet/go-api-c320/internal/model"
 )
 
-// TelnetSessionPool manages a pool of telnet connections
+// sessionWaitTimeout bounds the time a request waits in the queue for a free session
+const sessionWaitTimeout = 30 * time.Second
+
+// pooledSession is a Telnet session owned by the pool
+type pooledSession struct {
+	id       int
+	session  TelnetRepository
+	inUse    bool
+	lastUsed time.Time
+}
+
+// PoolStats are the counters of a Telnet session pool
+type PoolStats struct {
+	Size                int     `json:"size"`                  // Maximum number of sessions (TELNET_POOL_SIZE)
+	Open                int     `json:"open"`                  // Sessions created so far
+	InUse               int     `json:"in_use"`                // Sessions checked out
+	Idle                int     `json:"idle"`                  // Sessions available
+	Waiting             int     `json:"waiting"`               // Requests queued for a session
+	Checkouts           uint64  `json:"checkouts"`             // Sessions handed out
+	Waits               uint64  `json:"waits"`                 // Checkouts that had to queue
+	WaitTimeouts        uint64  `json:"wait_timeouts"`         // Queued requests that gave up (timeout or canceled)
+	AvgWaitMs           float64 `json:"avg_wait_ms"`           // Average queue time of the checkouts that queued
+	MaxWaitMs           float64 `json:"max_wait_ms"`           // Longest queue time
+	HealthCheckFailures uint64  `json:"health_check_failures"` // Failed checks on checkout (followed by a reconnect)
+	Reconnects          uint64  `json:"reconnects"`            // Sessions re-established by the pool
+}
+
+// TelnetSessionPool manages up to TELNET_POOL_SIZE authenticated Telnet sessions to one OLT.
+// Sessions are opened on demand. Requests that find no free session wait in a FIFO queue and
+// get the next released session handed over directly, so a long batch cannot starve other requests.
+// Every checkout checks the session with a prompt round trip and leaves config mode left over by an
+// interrupted operation; broken sessions are reconnected.
 type TelnetSessionPool struct {
-	config    *config.TelnetConfig
-	session   TelnetRepository
-	mu        sync.Mutex
-	lastUsed  time.Time
-	inUse     bool
-	closeChan chan struct{}
-	closed    bool
+	config     *config.TelnetConfig
+	newSession func(cfg *config.TelnetConfig) TelnetRepository
+	size       int
+
+	mu         sync.Mutex
+	sessions   []*pooledSession
+	idle       []*pooledSession                    // Available sessions, most recently used last
+	waiters    []chan *pooledSession               // FIFO queue; a session is handed over on the channel
+	checkedOut map[TelnetRepository]*pooledSession // Sessions in use by their repository
+	closeChan  chan struct{}
+	closed     bool
+
+	stats     PoolStats
+	totalWait time.Duration
+	maxWait   time.Duration
 }
 
 // NewTelnetSessionPool creates a new telnet session pool
 func NewTelnetSessionPool(cfg *config.TelnetConfig) *TelnetSessionPool {
+	return newTelnetSessionPool(cfg, NewTelnetRepository)
+}
+
+// newTelnetSessionPool creates a pool whose sessions are created by newSession
+func newTelnetSessionPool(cfg *config.TelnetConfig, newSession func(cfg *config.TelnetConfig) TelnetRepository) *TelnetSessionPool {
+	size := cfg.PoolSize
+	if size < 1 {
+		size = 1
+	}
 	return &TelnetSessionPool{
-		config:    cfg,
-		session:   NewTelnetRepository(cfg),
-		closeChan: make(chan struct{}),
-		closed:    false,
+		config:     cfg,
+		newSession: newSession,
+		size:       size,
+		checkedOut: make(map[TelnetRepository]*pooledSession),
+		closeChan:  make(chan struct{}),
 	}
 }
 
-// GetSession acquires a session from the pool
+// GetSession checks out a connected session; it must be returned with ReleaseSession
 func (p *TelnetSessionPool) GetSession(ctx context.Context) (TelnetRepository, error) {
+	ps, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.prepare(ctx, ps); err != nil {
+		p.release(ps)
+		return nil, err
+	}
+
+	return ps.session, nil
+}
+
+// ReleaseSession returns a session to the pool, handing it to the longest waiting request if any
+func (p *TelnetSessionPool) ReleaseSession(session TelnetRepository) {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	ps, ok := p.checkedOut[session]
+	p.mu.Unlock()
+	if !ok {
+		log.Warn().Msg("Released a Telnet session that is not checked out")
+		return
+	}
+
+	p.release(ps)
+	log.Debug().Int("session", ps.id).Msg("Session released back to pool")
+}
+
+// acquire takes an idle session, opens a new one if the pool is not full, or queues the request
+func (p *TelnetSessionPool) acquire(ctx context.Context) (*pooledSession, error) {
+	start := time.Now()
 
+	p.mu.Lock()
 	if p.closed {
+		p.mu.Unlock()
 		return nil, model.NewTelnetError(model.ErrCodeSessionBusy,
 			"session pool is closed", false)
 	}
 
-	// Check if session is in use
-	deadline := time.Now().Add(30 * time.Second)
-	for p.inUse {
-		// Release lock and wait
-		p.mu.Unlock()
+	// Queued requests are served first, a new request never overtakes them
+	if len(p.waiters) == 0 {
+		if n := len(p.idle); n > 0 {
+			ps := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			p.checkoutLocked(ps)
+			p.mu.Unlock()
+			telnetSessionWait.Observe(0, p.config.Host)
+			return ps, nil
+		}
+		if len(p.sessions) < p.size {
+			ps := &pooledSession{id: len(p.sessions) + 1, session: p.newSession(p.config)}
+			p.sessions = append(p.sessions, ps)
+			p.checkoutLocked(ps)
+			p.mu.Unlock()
+			telnetSessionWait.Observe(0, p.config.Host)
+			return ps, nil
+		}
+	}
 
-		select {
-		case <-ctx.Done():
-			p.mu.Lock()
-			return nil, ctx.Err()
-		case <-time.After(100 * time.Millisecond):
-			// Check if deadline exceeded
-			if time.Now().After(deadline) {
-				p.mu.Lock()
-				return nil, model.NewTelnetError(model.ErrCodeSessionBusy,
-					"timeout waiting for available session", true)
-			}
+	waiter := make(chan *pooledSession, 1)
+	p.waiters = append(p.waiters, waiter)
+	telnetSessionQueueDepth.Set(float64(len(p.waiters)), p.config.Host)
+	p.mu.Unlock()
+
+	timer := time.NewTimer(sessionWaitTimeout)
+	defer timer.Stop()
+
+	select {
+	case ps := <-waiter:
+		p.recordWait(time.Since(start))
+		return ps, nil
+	case <-ctx.Done():
+		return nil, p.abandon(waiter, ctx.Err())
+	case <-timer.C:
+		return nil, p.abandon(waiter, model.NewTelnetError(model.ErrCodeSessionBusy,
+			fmt.Sprintf("timeout waiting for available session (%d of %d busy)", p.size, p.size), true))
+	case <-p.closeChan:
+		return nil, p.abandon(waiter, model.NewTelnetError(model.ErrCodeSessionBusy,
+			"session pool is closed", false))
+	}
+}
+
+// abandon removes a waiter from the queue. A session handed over in the meantime is passed on.
+func (p *TelnetSessionPool) abandon(waiter chan *pooledSession, err error) error {
+	p.mu.Lock()
+	for i, w := range p.waiters {
+		if w == waiter {
+			p.waiters = append(p.waiters[:i], p.waiters[i+1:]...)
+			break
 		}
+	}
+	p.stats.WaitTimeouts++
+	telnetSessionQueueDepth.Set(float64(len(p.waiters)), p.config.Host)
+	p.mu.Unlock()
+
+	select {
+	case ps := <-waiter:
+		p.release(ps)
+	default:
+	}
+	return err
+}
 
-		p.mu.Lock()
+// release returns a session to the first waiter, or to the idle list
+func (p *TelnetSessionPool) release(ps *pooledSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.checkedOut, ps.session)
+	ps.inUse = false
+	ps.lastUsed = time.Now()
+
+	if p.closed {
+		return
 	}
 
-	// Mark as in use
-	p.inUse = true
-	p.lastUsed = time.Now()
+	if len(p.waiters) > 0 {
+		waiter := p.waiters[0]
+		p.waiters = p.waiters[1:]
+		telnetSessionQueueDepth.Set(float64(len(p.waiters)), p.config.Host)
+		p.checkoutLocked(ps)
+		waiter <- ps // Buffered, never blocks
+		return
+	}
+
+	p.idle = append(p.idle, ps)
+}
+
+// checkoutLocked marks a session as checked out; p.mu must be held
+func (p *TelnetSessionPool) checkoutLocked(ps *pooledSession) {
+	ps.inUse = true
+	p.checkedOut[ps.session] = ps
+	p.stats.Checkouts++
+}
+
+// recordWait adds the queue time of a checkout to the statistics
+func (p *TelnetSessionPool) recordWait(wait time.Duration) {
+	telnetSessionWait.Observe(wait.Seconds(), p.config.Host)
 
-	// Ensure connection is established
-	if !p.session.IsConnected() {
-		log.Info().Msg("Session not connected, establishing connection")
-		if err := p.session.Connect(); err != nil {
-			p.inUse = false
-			return nil, err
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stats.Waits++
+	p.totalWait += wait
+	if wait > p.maxWait {
+		p.maxWait = wait
+	}
+}
+
+// prepare makes a checked out session ready for use: connected, responsive and out of config mode
+func (p *TelnetSessionPool) prepare(ctx context.Context, ps *pooledSession) error {
+	session := ps.session
+	if !session.IsConnected() {
+		log.Info().Int("session", ps.id).Msg("Session not connected, establishing connection")
+		return session.Connect()
+	}
+
+	// Health check: the OLT answers with a prompt
+	if err := session.Ping(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
+		p.mu.Lock()
+		p.stats.HealthCheckFailures++
+		p.mu.Unlock()
+		log.Warn().Err(err).Int("session", ps.id).Msg("Telnet session failed health check, reconnecting")
+		return p.reconnect(ps)
 	}
 
-	// Check if connection is stale
-	if time.Since(p.lastUsed) > p.config.MaxIdleTime {
-		log.Info().Msg("Session idle too long, reconnecting")
-		if err := p.session.Reconnect(); err != nil {
-			p.inUse = false
-			return nil, err
+	// Mode reset: an interrupted operation may have left the session in config mode
+	if session.GetCurrentMode() == "config" {
+		log.Info().Int("session", ps.id).Msg("Session left in config mode, returning to enable mode")
+		if err := session.ExitConfigMode(); err != nil {
+			log.Warn().Err(err).Int("session", ps.id).Msg("Failed to leave config mode, reconnecting")
+			return p.reconnect(ps)
 		}
 	}
 
-	return p.session, nil
+	return nil
 }
 
-// ReleaseSession releases the session back to the pool
-func (p *TelnetSessionPool) ReleaseSession() {
+// reconnect re-establishes a checked out session
+func (p *TelnetSessionPool) reconnect(ps *pooledSession) error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	p.stats.Reconnects++
+	p.mu.Unlock()
 
-	p.inUse = false
-	p.lastUsed = time.Now()
-
-	log.Debug().Msg("Session released back to pool")
+	return ps.session.Reconnect()
 }
 
-// Close closes all sessions in the pool
+// Close closes all sessions in the pool; queued requests fail
 func (p *TelnetSessionPool) Close() error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	if p.closed {
+		p.mu.Unlock()
 		return nil
 	}
-
 	p.closed = true
 	close(p.closeChan)
+	sessions := append([]*pooledSession(nil), p.sessions...)
+	p.mu.Unlock()
 
-	if p.session != nil {
-		return p.session.Close()
+	var firstErr error
+	for _, ps := range sessions {
+		if err := ps.session.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	return nil
+	return firstErr
 }
 
 // StartIdleCleanup starts a goroutine to clean up idle connections
@@ -136,90 +311,126 @@ func (p *TelnetSessionPool) StartIdleCleanup() {
 	}()
 }
 
-// cleanupIdleSessions closes idle sessions
+// cleanupIdleSessions disconnects sessions that have not been used for MaxIdleTime.
+// They stay in the pool and reconnect on their next checkout.
 func (p *TelnetSessionPool) cleanupIdleSessions() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	if p.inUse {
-		return
-	}
-
-	if p.session == nil || !p.session.IsConnected() {
-		return
+	var expired []*pooledSession
+	remaining := p.idle[:0]
+	for _, ps := range p.idle {
+		if time.Since(ps.lastUsed) > p.config.MaxIdleTime && ps.session.IsConnected() {
+			ps.inUse = true // Not available while closing
+			p.checkedOut[ps.session] = ps
+			expired = append(expired, ps)
+			continue
+		}
+		remaining = append(remaining, ps)
 	}
+	p.idle = remaining
+	p.mu.Unlock()
 
-	// Close if idle for too long
-	idleDuration := time.Since(p.lastUsed)
-	if idleDuration > p.config.MaxIdleTime {
+	for _, ps := range expired {
 		log.Info().
-			Dur("idle_duration", idleDuration).
+			Int("session", ps.id).
+			Dur("idle_duration", time.Since(ps.lastUsed)).
 			Msg("Closing idle telnet session")
 
-		if err := p.session.Close(); err != nil {
+		if err := ps.session.Close(); err != nil {
 			log.Error().Err(err).Msg("Failed to close idle session")
 		}
+		p.release(ps)
 	}
 }
 
-// GetStatus returns the current status of the pool
-func (p *TelnetSessionPool) GetStatus() map[string]interface{} {
+// Stats returns the current pool statistics
+func (p *TelnetSessionPool) Stats() PoolStats {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	status := map[string]interface{}{
-		"closed":    p.closed,
-		"in_use":    p.inUse,
-		"last_used": p.lastUsed.Format(time.RFC3339),
-		"idle_time": time.Since(p.lastUsed).String(),
+	stats := p.stats
+	stats.Size = p.size
+	stats.Open = len(p.sessions)
+	stats.InUse = len(p.checkedOut)
+	stats.Idle = len(p.idle)
+	stats.Waiting = len(p.waiters)
+	if stats.Waits > 0 {
+		stats.AvgWaitMs = float64(p.totalWait.Milliseconds()) / float64(stats.Waits)
 	}
+	stats.MaxWaitMs = float64(p.maxWait.Milliseconds())
+	return stats
+}
+
+// GetStatus returns the current status of the pool and of every session
+func (p *TelnetSessionPool) GetStatus() map[string]interface{} {
+	stats := p.Stats()
+
+	p.mu.Lock()
+	closed := p.closed
+	sessions := append([]*pooledSession(nil), p.sessions...)
+	p.mu.Unlock()
 
-	if p.session != nil {
-		connInfo := p.session.GetConnectionInfo()
-		status["connected"] = connInfo.Connected
-		status["mode"] = connInfo.Mode
-		status["uptime"] = connInfo.Uptime
+	sessionStatus := make([]map[string]interface{}, 0, len(sessions))
+	for _, ps := range sessions {
+		p.mu.Lock()
+		inUse, lastUsed := ps.inUse, ps.lastUsed
+		p.mu.Unlock()
+
+		connInfo := ps.session.GetConnectionInfo()
+		sessionStatus = append(sessionStatus, map[string]interface{}{
+			"id":        ps.id,
+			"in_use":    inUse,
+			"connected": connInfo.Connected,
+			"mode":      connInfo.Mode,
+			"uptime":    connInfo.Uptime,
+			"last_used": lastUsed.Format(time.RFC3339),
+		})
 	}
 
-	return status
+	return map[string]interface{}{
+		"closed":   closed,
+		"pool":     stats,
+		"sessions": sessionStatus,
+	}
 }
 
-// TelnetSessionManager manages the global telnet session pool
+// TelnetSessionManager manages the telnet session pool of one OLT
 type TelnetSessionManager struct {
 	pool *TelnetSessionPool
 	mu   sync.RWMutex
 }
 
-var (
-	globalSessionManager *TelnetSessionManager
-	sessionManagerOnce   sync.Once
-)
-
-// GetGlobalSessionManager returns the singleton session manager
-func GetGlobalSessionManager(cfg *config.TelnetConfig) *TelnetSessionManager {
-	sessionManagerOnce.Do(func() {
-		pool := NewTelnetSessionPool(cfg)
-		pool.StartIdleCleanup()
-
-		globalSessionManager = &TelnetSessionManager{
-			pool: pool,
-		}
+// NewTelnetSessionManager creates a session manager with its own session pool.
+// Each managed OLT gets a dedicated manager so sessions are never shared across devices.
+func NewTelnetSessionManager(cfg *config.TelnetConfig) *TelnetSessionManager {
+	return newTelnetSessionManager(cfg, NewTelnetRepository)
+}
 
-		log.Info().Msg("Global telnet session manager initialized")
-	})
+// newTelnetSessionManager creates a session manager whose pool opens sessions with newSession
+func newTelnetSessionManager(cfg *config.TelnetConfig, newSession func(cfg *config.TelnetConfig) TelnetRepository) *TelnetSessionManager {
+	pool := newTelnetSessionPool(cfg, newSession)
+	pool.StartIdleCleanup()
 
-	return globalSessionManager
+	return &TelnetSessionManager{
+		pool: pool,
+	}
 }
 
-// ExecuteCommand executes a command using the session pool
+// ExecuteCommand executes a command using the session pool.
+// The command and its output are added to the CLI transcript of ctx, if any.
 func (m *TelnetSessionManager) ExecuteCommand(ctx context.Context, command string) (*model.TelnetResponse, error) {
 	session, err := m.pool.GetSession(ctx)
 	if err != nil {
 		return nil, err
 	}
-	defer m.pool.ReleaseSession()
+	defer m.pool.ReleaseSession(session)
 
-	return session.Execute(ctx, command)
+	resp, err := session.Execute(ctx, command)
+	if resp != nil {
+		recordCLI(ctx, model.CLIModeExec, *resp)
+	} else if err != nil {
+		recordCLI(ctx, model.CLIModeExec, model.TelnetResponse{Command: command, Error: err.Error()})
+	}
+	return resp, err
 }
 
 // ExecuteCommands executes multiple commands using the session pool
@@ -228,9 +439,13 @@ func (m *TelnetSessionManager) ExecuteCommands(ctx context.Context, commands []s
 	if err != nil {
 		return nil, err
 	}
-	defer m.pool.ReleaseSession()
+	defer m.pool.ReleaseSession(session)
 
-	return session.ExecuteMulti(ctx, commands)
+	result, err := session.ExecuteMulti(ctx, commands)
+	if result != nil {
+		recordCLI(ctx, model.CLIModeExec, result.Responses...)
+	}
+	return result, err
 }
 
 // ExecuteInConfigMode executes commands in configuration mode
@@ -239,7 +454,7 @@ func (m *TelnetSessionManager) ExecuteInConfigMode(ctx context.Context, commands
 	if err != nil {
 		return nil, err
 	}
-	defer m.pool.ReleaseSession()
+	defer m.pool.ReleaseSession(session)
 
 	// Enter config mode
 	if err := session.EnterEnableMode(); err != nil {
@@ -252,6 +467,10 @@ func (m *TelnetSessionManager) ExecuteInConfigMode(ctx context.Context, commands
 
 	// Execute commands
 	result, execErr := session.ExecuteMulti(ctx, commands)
+	markCLIErrors(result)
+	if result != nil {
+		recordCLI(ctx, model.CLIModeConfig, result.Responses...)
+	}
 
 	// Always try to exit config mode
 	if exitErr := session.ExitConfigMode(); exitErr != nil {
@@ -261,15 +480,57 @@ func (m *TelnetSessionManager) ExecuteInConfigMode(ctx context.Context, commands
 	return result, execErr
 }
 
+// markCLIErrors marks the responses whose output is a CLI error as failed. The OLT rejects a configuration
+// command with a message like "%Error 20209: The ONU does not exist." or "%Code 32310-GPONSRV : ..."
+// and still returns to the prompt.
+func markCLIErrors(result *model.TelnetBatchResponse) {
+	if result == nil {
+		return
+	}
+	for i := range result.Responses {
+		resp := &result.Responses[i]
+		output := strings.ToLower(resp.Output)
+		if resp.Success && (strings.Contains(output, "%error") || strings.Contains(output, "%code") || strings.Contains(output, "invalid input")) {
+			resp.Success = false
+			resp.Error = strings.TrimSpace(resp.Output)
+			result.Success = false
+		}
+	}
+}
+
 // SaveConfiguration saves the OLT configuration
 func (m *TelnetSessionManager) SaveConfiguration(ctx context.Context) error {
 	session, err := m.pool.GetSession(ctx)
 	if err != nil {
 		return err
 	}
-	defer m.pool.ReleaseSession()
+	defer m.pool.ReleaseSession(session)
 
-	return session.SaveConfig()
+	// SaveConfig only reports errors; the error carries the output of a rejected "write"
+	err = session.SaveConfig()
+	saved := model.TelnetResponse{Command: "write", Success: err == nil}
+	if err != nil {
+		saved.Error = err.Error()
+	}
+	recordCLI(ctx, model.CLIModeExec, saved)
+	return err
+}
+
+// recordCLI adds the responses to the CLI transcript of ctx; it does nothing if ctx has no transcript
+func recordCLI(ctx context.Context, mode string, responses ...model.TelnetResponse) {
+	transcript := model.CLITranscriptFromContext(ctx)
+	if transcript == nil {
+		return
+	}
+	for _, resp := range responses {
+		transcript.Add(model.CLICommand{
+			Command: resp.Command,
+			Mode:    mode,
+			Output:  resp.Output,
+			Success: resp.Success,
+			Error:   resp.Error,
+		})
+	}
 }
 
 // GetConnectionStatus returns the connection status
@@ -311,12 +572,7 @@ func (m *TelnetSessionManager) WithRetry(ctx context.Context, fn func() error) e
 				return ctx.Err()
 			}
 
-			// Try to reconnect
-			session, err := m.pool.GetSession(ctx)
-			if err == nil {
-				_ = session.Reconnect()
-				m.pool.ReleaseSession()
-			}
+			// Broken sessions are reconnected by the pool's health check on the next checkout
 		}
 
 		lastErr = fn()
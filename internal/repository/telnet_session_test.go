@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// fakeTelnetSession is a TelnetRepository that records the pool's calls
+type fakeTelnetSession struct {
+	mu         sync.Mutex
+	connected  bool
+	mode       string
+	pingErr    error
+	connects   int
+	reconnects int
+	pings      int
+}
+
+func (f *fakeTelnetSession) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connects++
+	f.connected, f.mode = true, "user"
+	return nil
+}
+
+func (f *fakeTelnetSession) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connected = false
+	return nil
+}
+
+func (f *fakeTelnetSession) IsConnected() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connected
+}
+
+func (f *fakeTelnetSession) Reconnect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconnects++
+	f.connected, f.mode, f.pingErr = true, "user", nil
+	return nil
+}
+
+func (f *fakeTelnetSession) Ping(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pings++
+	return f.pingErr
+}
+
+func (f *fakeTelnetSession) Execute(context.Context, string) (*model.TelnetResponse, error) {
+	return &model.TelnetResponse{Success: true}, nil
+}
+
+func (f *fakeTelnetSession) ExecuteMulti(context.Context, []string) (*model.TelnetBatchResponse, error) {
+	return &model.TelnetBatchResponse{}, nil
+}
+
+func (f *fakeTelnetSession) ExecuteWithExpect(context.Context, string, string) (*model.TelnetResponse, error) {
+	return &model.TelnetResponse{Success: true}, nil
+}
+
+func (f *fakeTelnetSession) EnterEnableMode() error { return f.setMode("enable") }
+func (f *fakeTelnetSession) EnterConfigMode() error { return f.setMode("config") }
+func (f *fakeTelnetSession) ExitConfigMode() error  { return f.setMode("enable") }
+
+func (f *fakeTelnetSession) setMode(mode string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mode = mode
+	return nil
+}
+
+func (f *fakeTelnetSession) GetCurrentMode() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.mode
+}
+
+func (f *fakeTelnetSession) SaveConfig() error                  { return nil }
+func (f *fakeTelnetSession) ShowRunningConfig() (string, error) { return "", nil }
+
+func (f *fakeTelnetSession) GetConnectionInfo() *model.TelnetConnectionInfo {
+	return &model.TelnetConnectionInfo{Connected: f.IsConnected(), Mode: f.GetCurrentMode()}
+}
+
+// newFakePool creates a pool of fake sessions and returns the sessions it created
+func newFakePool(size int) (*TelnetSessionPool, *[]*fakeTelnetSession) {
+	var mu sync.Mutex
+	sessions := &[]*fakeTelnetSession{}
+	pool := newTelnetSessionPool(&config.TelnetConfig{Host: "test", PoolSize: size, MaxIdleTime: time.Minute},
+		func(*config.TelnetConfig) TelnetRepository {
+			mu.Lock()
+			defer mu.Unlock()
+			s := &fakeTelnetSession{}
+			*sessions = append(*sessions, s)
+			return s
+		})
+	return pool, sessions
+}
+
+// waitForWaiters waits until n requests are queued
+func waitForWaiters(t *testing.T, pool *TelnetSessionPool, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for pool.Stats().Waiting != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Waiting = %d, want %d", pool.Stats().Waiting, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestTelnetSessionPool_ConcurrentSessions(t *testing.T) {
+	pool, sessions := newFakePool(2)
+	ctx := context.Background()
+
+	first, err := pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	second, err := pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if first == second {
+		t.Fatal("GetSession() returned the same session twice")
+	}
+	if len(*sessions) != 2 {
+		t.Fatalf("pool opened %d sessions, want 2", len(*sessions))
+	}
+	for i, s := range *sessions {
+		if s.connects != 1 {
+			t.Errorf("session %d connected %d times, want 1", i, s.connects)
+		}
+	}
+
+	// The pool is full: a third request waits until a session is released
+	got := make(chan TelnetRepository)
+	go func() {
+		s, err := pool.GetSession(ctx)
+		if err != nil {
+			t.Errorf("GetSession() error = %v", err)
+		}
+		got <- s
+	}()
+	waitForWaiters(t, pool, 1)
+
+	pool.ReleaseSession(first)
+	if s := <-got; s != first {
+		t.Error("waiter did not get the released session")
+	}
+	if len(*sessions) != 2 {
+		t.Errorf("pool opened %d sessions, want 2", len(*sessions))
+	}
+
+	stats := pool.Stats()
+	if stats.InUse != 2 || stats.Waiting != 0 || stats.Checkouts != 3 || stats.Waits != 1 {
+		t.Errorf("Stats() = %+v, want 2 in use, 0 waiting, 3 checkouts, 1 wait", stats)
+	}
+}
+
+func TestTelnetSessionPool_FIFO(t *testing.T) {
+	pool, _ := newFakePool(1)
+	ctx := context.Background()
+
+	held, err := pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	order := make(chan int, 3)
+	var wg sync.WaitGroup
+	for i := 1; i <= 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s, err := pool.GetSession(ctx)
+			if err != nil {
+				t.Errorf("GetSession() error = %v", err)
+				return
+			}
+			order <- i
+			pool.ReleaseSession(s)
+		}(i)
+		waitForWaiters(t, pool, i)
+	}
+
+	pool.ReleaseSession(held)
+	wg.Wait()
+	close(order)
+
+	want := 1
+	for got := range order {
+		if got != want {
+			t.Errorf("waiter %d got the session, want waiter %d", got, want)
+		}
+		want++
+	}
+}
+
+func TestTelnetSessionPool_CanceledWaiter(t *testing.T) {
+	pool, _ := newFakePool(1)
+
+	held, err := pool.GetSession(context.Background())
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() {
+		_, err := pool.GetSession(ctx)
+		errs <- err
+	}()
+	waitForWaiters(t, pool, 1)
+	cancel()
+
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("GetSession() error = %v, want context.Canceled", err)
+	}
+	stats := pool.Stats()
+	if stats.Waiting != 0 || stats.WaitTimeouts != 1 {
+		t.Errorf("Stats() = %+v, want 0 waiting and 1 wait timeout", stats)
+	}
+
+	// The session goes back to the idle list, not to the canceled waiter
+	pool.ReleaseSession(held)
+	if stats := pool.Stats(); stats.Idle != 1 {
+		t.Errorf("Idle = %d, want 1", stats.Idle)
+	}
+}
+
+func TestTelnetSessionPool_CheckoutHealth(t *testing.T) {
+	pool, sessions := newFakePool(1)
+	ctx := context.Background()
+
+	s, err := pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	fake := (*sessions)[0]
+
+	// An operation left the session in config mode
+	_ = s.EnterConfigMode()
+	pool.ReleaseSession(s)
+
+	s, err = pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	if mode := s.GetCurrentMode(); mode != "enable" {
+		t.Errorf("mode after checkout = %q, want enable", mode)
+	}
+	if fake.pings != 1 {
+		t.Errorf("pings = %d, want 1", fake.pings)
+	}
+	pool.ReleaseSession(s)
+
+	// A session that does not answer is reconnected
+	fake.mu.Lock()
+	fake.pingErr = errors.New("connection reset")
+	fake.mu.Unlock()
+
+	s, err = pool.GetSession(ctx)
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+	pool.ReleaseSession(s)
+	if fake.reconnects != 1 {
+		t.Errorf("reconnects = %d, want 1", fake.reconnects)
+	}
+	stats := pool.Stats()
+	if stats.HealthCheckFailures != 1 || stats.Reconnects != 1 {
+		t.Errorf("Stats() = %+v, want 1 health check failure and 1 reconnect", stats)
+	}
+}
+
+func TestTelnetSessionPool_Close(t *testing.T) {
+	pool, sessions := newFakePool(1)
+
+	held, err := pool.GetSession(context.Background())
+	if err != nil {
+		t.Fatalf("GetSession() error = %v", err)
+	}
+
+	errs := make(chan error)
+	go func() {
+		_, err := pool.GetSession(context.Background())
+		errs <- err
+	}()
+	waitForWaiters(t, pool, 1)
+
+	if err := pool.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if err := <-errs; err == nil {
+		t.Error("queued GetSession() succeeded after Close()")
+	}
+	if (*sessions)[0].IsConnected() {
+		t.Error("session still connected after Close()")
+	}
+	pool.ReleaseSession(held)
+
+	if _, err := pool.GetSession(context.Background()); err == nil {
+		t.Error("GetSession() succeeded after Close()")
+	}
+}
+
+func TestMarkCLIErrors(t *testing.T) {
+	result := &model.TelnetBatchResponse{Success: true, Responses: []model.TelnetResponse{
+		{Command: "tcont 1 profile UP-10M", Success: true},
+		{Command: "no tcont 1", Success: true, Output: "%Error 20352: The T-CONT is bound to a GEM port."},
+		{Command: "onu 1 type F660 sn ZTEGC0000001", Success: true, Output: "%Code 32310-GPONSRV : The ONU type does not exist.\n"},
+		{Command: "onu 2 name error-free", Success: true},
+	}}
+	markCLIErrors(result)
+
+	want := []bool{true, false, false, true}
+	for i, resp := range result.Responses {
+		if resp.Success != want[i] {
+			t.Errorf("%q success = %v, want %v", resp.Command, resp.Success, want[i])
+		}
+	}
+	if result.Success || result.Responses[2].Error != "%Code 32310-GPONSRV : The ONU type does not exist." {
+		t.Errorf("result = %+v, want failed with the CLI error", result)
+	}
+}
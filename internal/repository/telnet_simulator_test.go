@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+// newSimulatedOLT starts a CLI simulator for state and returns a session manager connected to it
+func newSimulatedOLT(t *testing.T, cfg simulator.CLIConfig, state *simulator.State) (*simulator.CLIServer, *TelnetSessionManager) {
+	t.Helper()
+
+	server := simulator.NewCLIServer(cfg, state)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	manager := NewTelnetSessionManager(server.TelnetConfig())
+	t.Cleanup(func() {
+		manager.Close()
+		server.Close()
+	})
+	return server, manager
+}
+
+func TestTelnetRepository_Simulator(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      simulator.CLIConfig
+		wantMode string
+	}{
+		{name: "user prompt", wantMode: "user"},
+		{name: "enable password", cfg: simulator.CLIConfig{EnablePassword: "zte"}, wantMode: "user"},
+		{name: "privileged login", cfg: simulator.CLIConfig{Privileged: true}, wantMode: "enable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := simulator.NewCLIServer(tt.cfg, simulator.NewDemoState())
+			if err := server.Listen("127.0.0.1:0"); err != nil {
+				t.Fatalf("Listen() error = %v", err)
+			}
+			defer server.Close()
+
+			repo := NewTelnetRepository(server.TelnetConfig())
+			if err := repo.Connect(); err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer repo.Close()
+
+			if mode := repo.GetCurrentMode(); mode != tt.wantMode {
+				t.Fatalf("GetCurrentMode() = %q, want %q", mode, tt.wantMode)
+			}
+			if err := repo.EnterConfigMode(); err != nil {
+				t.Fatalf("EnterConfigMode() error = %v", err)
+			}
+
+			// Sub-mode prompts (ZXAN(config-if)#) end the response like the known prompts
+			result, err := repo.ExecuteMulti(context.Background(), []string{
+				"interface gpon-olt_1/1/1",
+				"onu 9 type ZTE-F609 sn ZTEGC0FFEE09",
+				"exit",
+			})
+			if err != nil || !result.Success {
+				t.Fatalf("ExecuteMulti() = %+v, %v", result, err)
+			}
+			for _, resp := range result.Responses {
+				if strings.Contains(resp.Output, "(config") {
+					t.Errorf("output of %q contains a prompt: %q", resp.Command, resp.Output)
+				}
+			}
+			if err := repo.ExitConfigMode(); err != nil {
+				t.Fatalf("ExitConfigMode() error = %v", err)
+			}
+			if err := repo.Ping(context.Background()); err != nil {
+				t.Fatalf("Ping() error = %v", err)
+			}
+			if mode := repo.GetCurrentMode(); mode != "enable" {
+				t.Errorf("GetCurrentMode() = %q, want enable", mode)
+			}
+			if _, ok := server.State().ONU("1/1/1", 9); !ok {
+				t.Error("ONU 1/1/1:9 was not registered")
+			}
+		})
+	}
+}
+
+func TestTelnetRepository_SimulatorWrongPassword(t *testing.T) {
+	server := simulator.NewCLIServer(simulator.CLIConfig{}, nil)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer server.Close()
+
+	cfg := server.TelnetConfig()
+	cfg.Password = "wrong"
+	if err := NewTelnetRepository(cfg).Connect(); err == nil {
+		t.Fatal("Connect() with a wrong password succeeded")
+	}
+}
+
+func TestTelnetSessionManager_OpticalInfo(t *testing.T) {
+	state := simulator.NewDemoState()
+	state.Update("1/1/1", 2, func(onu *simulator.ONU) {
+		onu.Optical = simulator.Optical{OLTRx: -29.5, ONURx: -30.1, ONUTx: 2.4, Temperature: 48, Voltage: 3.3, BiasCurrent: 12.5}
+	})
+	_, manager := newSimulatedOLT(t, simulator.CLIConfig{}, state)
+	ctx := context.Background()
+
+	info, err := manager.GetONUOpticalInfo(ctx, 1, 1, 2)
+	if err != nil {
+		t.Fatalf("GetONUOpticalInfo() error = %v", err)
+	}
+	if info.OLTRxPower != -29.5 || info.TxPower != 2.4 || info.Temperature != 48 || info.Voltage != 3.3 || info.BiasCurrent != 12.5 {
+		t.Errorf("GetONUOpticalInfo() = %+v", info)
+	}
+	if info.RxPowerStatus != "low" {
+		t.Errorf("RxPowerStatus = %q, want low", info.RxPowerStatus)
+	}
+
+	list, err := manager.GetPONOpticalInfo(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetPONOpticalInfo() error = %v", err)
+	}
+	if len(list) != 3 {
+		t.Fatalf("GetPONOpticalInfo() returned %d ONUs, want 3", len(list))
+	}
+	if list[1].OnuID != 2 || list[1].TxPower != 2.4 || list[1].Temperature != 48 {
+		t.Errorf("GetPONOpticalInfo()[1] = %+v", list[1])
+	}
+}
+
+func TestTelnetSessionManager_RunningConfig(t *testing.T) {
+	_, manager := newSimulatedOLT(t, simulator.CLIConfig{}, simulator.NewDemoState())
+	ctx := context.Background()
+
+	cfg, _, err := manager.GetRunningConfig(ctx)
+	if err != nil {
+		t.Fatalf("GetRunningConfig() error = %v", err)
+	}
+	if len(cfg.DBAProfiles) != 2 {
+		t.Errorf("DBAProfiles = %+v, want 2", cfg.DBAProfiles)
+	}
+	if len(cfg.PONPorts) != 1 || len(cfg.ONUs) != 3 {
+		t.Errorf("GetRunningConfig() has %d PON ports and %d ONUs, want 1 and 3", len(cfg.PONPorts), len(cfg.ONUs))
+	}
+
+	onus, err := manager.GetPONRunningConfig(ctx, "1/1/1")
+	if err != nil {
+		t.Fatalf("GetPONRunningConfig() error = %v", err)
+	}
+	if len(onus) != 3 || onus[0].SerialNumber == "" {
+		t.Errorf("GetPONRunningConfig() = %+v", onus)
+	}
+
+	onu, err := manager.GetONURunningConfig(ctx, "1/1/1", 1)
+	if err != nil {
+		t.Fatalf("GetONURunningConfig() error = %v", err)
+	}
+	if len(onu.TCONTs) == 0 || len(onu.GEMPorts) == 0 {
+		t.Errorf("GetONURunningConfig() = %+v", onu)
+	}
+}
+
+func TestTelnetSessionManager_ONUManagement(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.CLIConfig{}, simulator.NewDemoState())
+	ctx := context.Background()
+	state := server.State()
+
+	if err := manager.RebootONU(ctx, &model.ONURebootRequest{PONPort: "1/1/1", ONUID: 1}); err != nil {
+		t.Fatalf("RebootONU() error = %v", err)
+	}
+	if err := manager.BlockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2, Block: true}); err != nil {
+		t.Fatalf("BlockONU() error = %v", err)
+	}
+	if err := manager.UpdateDescription(ctx, &model.ONUDescriptionRequest{PONPort: "1/1/1", ONUID: 3, Description: `Shop "North"`}); err != nil {
+		t.Fatalf("UpdateDescription() error = %v", err)
+	}
+
+	if onu, _ := state.ONU("1/1/1", 1); onu.Reboots != 1 {
+		t.Errorf("Reboots = %d, want 1", onu.Reboots)
+	}
+	if onu, _ := state.ONU("1/1/1", 2); !onu.Disabled {
+		t.Error("ONU 2 is not disabled")
+	}
+	if onu, _ := state.ONU("1/1/1", 3); onu.Name != `Shop "North"` {
+		t.Errorf("Name = %q", onu.Name)
+	}
+
+	if err := manager.UnblockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2}); err != nil {
+		t.Fatalf("UnblockONU() error = %v", err)
+	}
+	if onu, _ := state.ONU("1/1/1", 2); onu.Disabled {
+		t.Error("ONU 2 is still disabled")
+	}
+
+	if err := manager.DeleteONU(ctx, &model.ONUDeleteRequest{PONPort: "1/1/1", ONUID: 3}); err != nil {
+		t.Fatalf("DeleteONU() error = %v", err)
+	}
+	if _, ok := state.ONU("1/1/1", 3); ok {
+		t.Error("ONU 3 still exists")
+	}
+
+	err := manager.RebootONU(ctx, &model.ONURebootRequest{PONPort: "1/1/1", ONUID: 42})
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("RebootONU() of a missing ONU error = %v, want not found", err)
+	}
+}
+
+func TestTelnetSessionManager_CLITranscript(t *testing.T) {
+	_, manager := newSimulatedOLT(t, simulator.CLIConfig{}, simulator.NewDemoState())
+	transcript := &model.CLITranscript{}
+	ctx := model.ContextWithCLITranscript(context.Background(), transcript)
+
+	if err := manager.BlockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2, Block: true}); err != nil {
+		t.Fatalf("BlockONU() error = %v", err)
+	}
+	_ = manager.RebootONU(ctx, &model.ONURebootRequest{PONPort: "1/1/1", ONUID: 42})
+
+	commands := transcript.Commands()
+	var block, missing *model.CLICommand
+	for i := range commands {
+		switch {
+		case commands[i].Command == "onu 2 state disable":
+			block = &commands[i]
+		case commands[i].Command == "onu reset 42":
+			missing = &commands[i]
+		}
+	}
+	if block == nil || block.Mode != model.CLIModeConfig || !block.Success {
+		t.Errorf("transcript has no successful config mode disable command: %+v", commands)
+	}
+	if missing == nil || missing.Success || missing.Output == "" {
+		t.Errorf("transcript has no failed command with the OLT output for ONU 42: %+v", commands)
+	}
+
+	// Without a transcript nothing is recorded
+	if err := manager.UnblockONU(context.Background(), &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2}); err != nil {
+		t.Fatalf("UnblockONU() error = %v", err)
+	}
+	if len(transcript.Commands()) != len(commands) {
+		t.Errorf("commands without a transcript in ctx were recorded")
+	}
+}
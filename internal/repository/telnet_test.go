@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"bufio"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// pipeDialer returns a dial function whose server side runs serve on an in-memory connection
+func pipeDialer(serve func(conn net.Conn)) func(*config.TelnetConfig) (CLITransport, error) {
+	return func(*config.TelnetConfig) (CLITransport, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			serve(server)
+		}()
+		return client, nil
+	}
+}
+
+func TestTelnetRepository_Login(t *testing.T) {
+	tests := []struct {
+		name      string
+		transport string
+		serve     func(conn net.Conn)
+		wantMode  string
+	}{
+		{
+			name:      "telnet username and password",
+			transport: config.TransportTelnet,
+			serve: func(conn net.Conn) {
+				r := bufio.NewReader(conn)
+				conn.Write([]byte("\r\nUsername:"))
+				if user, _ := r.ReadString('\n'); user != "admin\n" {
+					return
+				}
+				conn.Write([]byte("Password:"))
+				if password, _ := r.ReadString('\n'); password != "secret\n" {
+					return
+				}
+				conn.Write([]byte("\r\nZXAN>"))
+				r.ReadString('\n')
+			},
+			wantMode: "user",
+		},
+		{
+			name:      "ssh lands at the enable prompt",
+			transport: config.TransportSSH,
+			serve: func(conn net.Conn) {
+				conn.Write([]byte("Welcome to ZXAN\r\nZXAN#"))
+				bufio.NewReader(conn).ReadString('\n')
+			},
+			wantMode: "enable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewTelnetRepository(&config.TelnetConfig{
+				Transport:    tt.transport,
+				Username:     "admin",
+				Password:     "secret",
+				Timeout:      2 * time.Second,
+				WriteTimeout: 2 * time.Second,
+				PromptUser:   "ZXAN>",
+				PromptEnable: "ZXAN#",
+				PromptConfig: "ZXAN(config)#",
+			}).(*telnetRepository)
+			repo.dial = pipeDialer(tt.serve)
+
+			if err := repo.Connect(); err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			defer repo.Close()
+
+			if mode := repo.GetCurrentMode(); mode != tt.wantMode {
+				t.Errorf("GetCurrentMode() = %q, want %q", mode, tt.wantMode)
+			}
+			if info := repo.GetConnectionInfo(); info.Transport != tt.transport {
+				t.Errorf("Transport = %q, want %q", info.Transport, tt.transport)
+			}
+		})
+	}
+}
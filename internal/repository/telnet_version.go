@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/parser"
+)
+
+// GetVersion retrieves the OLT model and software version from "show version"
+func (m *TelnetSessionManager) GetVersion(ctx context.Context) (*model.OLTVersion, error) {
+	resp, err := m.ExecuteCommand(ctx, "show version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get software version: %w", err)
+	}
+
+	return parser.ParseVersion(resp.Output), nil
+}
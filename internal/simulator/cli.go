@@ -0,0 +1,437 @@
+// Package simulator provides in-process stand-ins for a ZTE C320 OLT, so the repositories,
+// usecases and CLI parsers can be exercised in tests and demos without real hardware.
+package simulator
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// Telnet protocol bytes
+const (
+	telnetIAC  = 255
+	telnetDont = 254
+	telnetDo   = 253
+	telnetWont = 252
+	telnetWill = 251
+	telnetSB   = 250
+	telnetSE   = 240
+
+	telnetOptEcho = 1
+	telnetOptSGA  = 3
+)
+
+const banner = "\r\n************************************************\r\n" +
+	"Welcome to ZXAN product C320 of ZTE Corporation\r\n" +
+	"************************************************\r\n\r\n"
+
+// CLIConfig configures the simulated CLI
+type CLIConfig struct {
+	Hostname       string // Prompt prefix (default "ZXAN")
+	Username       string // Login user (default "admin")
+	Password       string // Login password (default "admin")
+	EnablePassword string // Asked by "enable" when set
+	Privileged     bool   // Log in directly to the enable prompt instead of the user prompt
+	MaxSessions    int    // Concurrent sessions, like the OLT's VTY lines (0: unlimited)
+	Version        string // Software version reported by "show version" (default "V2.1.0")
+}
+
+// Handler produces the output of a scripted command; match holds the submatches of its pattern
+type Handler func(state *State, match []string) string
+
+// scriptedCommand is a command registered with CLIServer.Handle
+type scriptedCommand struct {
+	pattern *regexp.Regexp
+	handler Handler
+}
+
+// CLIServer is a simulated C320 command line served over Telnet. It keeps the ONU, DBA profile and
+// service-port configuration in a State shared by all sessions.
+type CLIServer struct {
+	cfg     CLIConfig
+	state   *State
+	started time.Time
+
+	mu       sync.Mutex
+	listener net.Listener
+	sessions map[net.Conn]struct{}
+	scripts  []scriptedCommand
+	commands []string
+	wg       sync.WaitGroup
+}
+
+// NewCLIServer creates a CLI simulator for state (a new empty State if nil)
+func NewCLIServer(cfg CLIConfig, state *State) *CLIServer {
+	if cfg.Hostname == "" {
+		cfg.Hostname = "ZXAN"
+	}
+	if cfg.Username == "" {
+		cfg.Username = "admin"
+	}
+	if cfg.Password == "" {
+		cfg.Password = "admin"
+	}
+	if cfg.Version == "" {
+		cfg.Version = "V2.1.0"
+	}
+	if state == nil {
+		state = NewState()
+	}
+	return &CLIServer{
+		cfg:      cfg,
+		state:    state,
+		started:  time.Now(),
+		sessions: make(map[net.Conn]struct{}),
+	}
+}
+
+// State returns the configuration of the simulated OLT
+func (s *CLIServer) State() *State {
+	return s.state
+}
+
+// Handle scripts the output of commands matching pattern (a regular expression matched against the whole
+// command line). Scripted commands take precedence over the built-in ones in every mode, e.g. to reproduce
+// the output of a specific firmware or to inject errors and delays.
+func (s *CLIServer) Handle(pattern string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scripts = append(s.scripts, scriptedCommand{pattern: regexp.MustCompile("^(?:" + pattern + ")$"), handler: handler})
+}
+
+// Commands returns every command received after login, in order
+func (s *CLIServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+// Listen starts serving Telnet on addr (e.g. "127.0.0.1:0") in the background
+func (s *CLIServer) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the server listens on
+func (s *CLIServer) Addr() *net.TCPAddr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr().(*net.TCPAddr)
+}
+
+// DropSessions disconnects all sessions, like an OLT reboot or a network outage
+func (s *CLIServer) DropSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.sessions {
+		conn.Close()
+	}
+}
+
+// Close stops the server and disconnects all sessions
+func (s *CLIServer) Close() error {
+	s.mu.Lock()
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	s.mu.Unlock()
+
+	s.DropSessions()
+	s.wg.Wait()
+	return err
+}
+
+// serve runs one Telnet session
+func (s *CLIServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	s.mu.Lock()
+	full := s.cfg.MaxSessions > 0 && len(s.sessions) >= s.cfg.MaxSessions
+	if !full {
+		s.sessions[conn] = struct{}{}
+	}
+	s.mu.Unlock()
+	if full {
+		_, _ = conn.Write([]byte("\r\n%Error 20000: No free VTY line, the connection is closed.\r\n"))
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.sessions, conn)
+		s.mu.Unlock()
+	}()
+
+	sess := &cliSession{server: s, conn: conn, r: bufio.NewReader(conn)}
+	_ = sess.run() // Ends with "exit" or when either side closes the connection
+}
+
+// scripted returns the output of a scripted command, if one matches
+func (s *CLIServer) scripted(command string) (string, bool) {
+	s.mu.Lock()
+	scripts := s.scripts
+	s.mu.Unlock()
+
+	for i := len(scripts) - 1; i >= 0; i-- {
+		if match := scripts[i].pattern.FindStringSubmatch(command); match != nil {
+			return scripts[i].handler(s.state, match), true
+		}
+	}
+	return "", false
+}
+
+// record adds a command to the command log
+func (s *CLIServer) record(command string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.commands = append(s.commands, command)
+}
+
+// CLI modes
+const (
+	modeUser = iota
+	modeEnable
+	modeConfig
+	modeInterfaceOLT
+	modeInterfaceONU
+	modeDBAProfile
+)
+
+// cliSession is the state of one logged-in CLI session
+type cliSession struct {
+	server *CLIServer
+	conn   net.Conn
+	r      *bufio.Reader
+	skipLF bool // The last line ended with CR; a following LF or NUL belongs to it
+
+	mode    int
+	ponPort string // interface gpon-olt_ / gpon-onu_ of the current mode
+	onuID   int
+	profile string // DBA profile of the current mode
+}
+
+// run performs the login and then the command loop
+func (c *cliSession) run() error {
+	// Like the OLT: the server echoes and suppresses go-ahead
+	if err := c.write(string([]byte{telnetIAC, telnetWill, telnetOptEcho, telnetIAC, telnetWill, telnetOptSGA}) + banner); err != nil {
+		return err
+	}
+	if err := c.login(); err != nil {
+		return err
+	}
+
+	for {
+		if err := c.write(c.prompt()); err != nil {
+			return err
+		}
+		line, err := c.readLine(true)
+		if err != nil {
+			return err
+		}
+		command := strings.TrimSpace(line)
+		if command == "" {
+			continue
+		}
+		c.server.record(command)
+
+		output, closeSession := c.execute(command)
+		if output != "" {
+			if err := c.write(strings.ReplaceAll(strings.TrimRight(output, "\n"), "\n", "\r\n") + "\r\n"); err != nil {
+				return err
+			}
+		}
+		if closeSession {
+			return nil
+		}
+	}
+}
+
+// login asks for username and password; three failed attempts close the session
+func (c *cliSession) login() error {
+	cfg := c.server.cfg
+	for attempt := 0; attempt < 3; attempt++ {
+		if err := c.write("Username:"); err != nil {
+			return err
+		}
+		user, err := c.readLine(true)
+		if err != nil {
+			return err
+		}
+		if err := c.write("Password:"); err != nil {
+			return err
+		}
+		password, err := c.readLine(false)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(user) == cfg.Username && password == cfg.Password {
+			c.mode = modeUser
+			if cfg.Privileged {
+				c.mode = modeEnable
+			}
+			return c.write("\r\n")
+		}
+		if err := c.write("\r\n%Error 20201: Username or password is wrong.\r\n"); err != nil {
+			return err
+		}
+	}
+	return errors.New("too many failed logins")
+}
+
+// prompt returns the prompt of the current mode
+func (c *cliSession) prompt() string {
+	host := c.server.cfg.Hostname
+	switch c.mode {
+	case modeUser:
+		return host + ">"
+	case modeConfig:
+		return host + "(config)#"
+	case modeInterfaceOLT, modeInterfaceONU:
+		return host + "(config-if)#"
+	case modeDBAProfile:
+		return host + "(config-dba)#"
+	}
+	return host + "#"
+}
+
+// readLine reads one input line, filtering Telnet commands and optionally echoing it
+func (c *cliSession) readLine(echo bool) (string, error) {
+	var line []byte
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case b == telnetIAC:
+			if b, err = c.readTelnetCommand(); err != nil {
+				return "", err
+			}
+			if b != telnetIAC {
+				continue
+			}
+		case c.skipLF && (b == '\n' || b == 0):
+			c.skipLF = false
+			continue
+		case b == '\r' || b == '\n':
+			c.skipLF = b == '\r'
+			if echo {
+				if err := c.write("\r\n"); err != nil {
+					return "", err
+				}
+			}
+			return string(line), nil
+		case b == 0x7f || b == '\b':
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				if echo {
+					if err := c.write("\b \b"); err != nil {
+						return "", err
+					}
+				}
+			}
+			continue
+		}
+
+		c.skipLF = false
+		line = append(line, b)
+		if echo {
+			if err := c.write(string(b)); err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// readTelnetCommand consumes a Telnet command after IAC. It returns IAC for an escaped 0xFF data byte.
+func (c *cliSession) readTelnetCommand() (byte, error) {
+	cmd, err := c.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch cmd {
+	case telnetIAC:
+		return telnetIAC, nil
+	case telnetDo, telnetDont, telnetWill, telnetWont:
+		_, err = c.r.ReadByte() // Option; every negotiation is accepted silently
+		return cmd, err
+	case telnetSB:
+		// Skip the subnegotiation up to IAC SE
+		for {
+			b, err := c.r.ReadByte()
+			if err != nil {
+				return 0, err
+			}
+			if b == telnetIAC {
+				if b, err = c.r.ReadByte(); err != nil || b == telnetSE {
+					return telnetSE, err
+				}
+			}
+		}
+	}
+	return cmd, nil
+}
+
+// write sends text to the client
+func (c *cliSession) write(text string) error {
+	_, err := c.conn.Write([]byte(text))
+	return err
+}
+
+// TelnetConfig returns a client configuration for the running server with the credentials and prompts
+// of the simulator and timeouts suited to tests
+func (s *CLIServer) TelnetConfig() *config.TelnetConfig {
+	addr := s.Addr()
+	return &config.TelnetConfig{
+		Transport:      config.TransportTelnet,
+		Host:           addr.IP.String(),
+		Port:           addr.Port,
+		Username:       s.cfg.Username,
+		Password:       s.cfg.Password,
+		EnablePassword: s.cfg.EnablePassword,
+		Timeout:        5 * time.Second,
+		ConnectTimeout: 2 * time.Second,
+		ReadTimeout:    5 * time.Second,
+		WriteTimeout:   2 * time.Second,
+		RetryCount:     1,
+		RetryDelay:     100 * time.Millisecond,
+		PoolSize:       2,
+		MaxIdleTime:    time.Minute,
+		PromptUser:     s.cfg.Hostname + ">",
+		PromptEnable:   s.cfg.Hostname + "#",
+		PromptConfig:   s.cfg.Hostname + "(config)#",
+	}
+}
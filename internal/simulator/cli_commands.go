@@ -0,0 +1,644 @@
+package simulator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Error messages in the format of the C320 firmware
+const (
+	errInvalidInput     = "%Error 20200: Invalid input detected at '^' marker."
+	errONUNotExist      = "%Error 20209: The ONU does not exist."
+	errONUExists        = "%Error 20210: The ONU ID is already in use."
+	errSerialRegistered = "%Error 20211: The SN is already registered."
+	errProfileNotExist  = "%Error 20341: The DBA profile does not exist."
+	errProfileInUse     = "%Error 20342: The DBA profile is in use."
+	errTCONTNotExist    = "%Error 20351: The T-CONT does not exist."
+	errTCONTInUse       = "%Error 20352: The T-CONT is bound to a GEM port."
+	errGEMPortNotExist  = "%Error 20361: The GEM port does not exist."
+	errServicePortNone  = "%Error 20371: The service-port does not exist."
+	errWrongPassword    = "%Error 20202: Password is wrong."
+	errNoInformation    = "%Code 32310-GPONSRV : No related information to show."
+)
+
+var (
+	ponInterfaceRegex = regexp.MustCompile(`^gpon-olt_(\d+/\d+/\d+)$`)
+	onuInterfaceRegex = regexp.MustCompile(`^gpon-onu_(\d+/\d+/\d+):(\d+)$`)
+
+	// onu 1 type ZTE-F660 sn ZTEGC0FFEE01
+	onuDeclareRegex = regexp.MustCompile(`^onu (\d+) type (\S+) sn (\S+)$`)
+	// onu 1 name "Customer 1" / onu 1 description text
+	onuAttributeRegex = regexp.MustCompile(`^onu (\d+) (name|description) (.+)$`)
+	// onu 1 state disable
+	onuStateRegex = regexp.MustCompile(`^onu (\d+) state (enable|disable)$`)
+	// tcont 1 [name TCONT_1] profile UP-10M
+	tcontRegex = regexp.MustCompile(`^tcont (\d+)(?: name (\S+))? profile (\S+)$`)
+	// gemport 1 [name GEM_1] [unicast] tcont 1 [queue 1]
+	gemportRegex = regexp.MustCompile(`^gemport (\d+)(?: name (\S+))?(?: unicast)? tcont (\d+)(?: queue (\d+))?$`)
+	// service-port 1 vport 1 user-vlan 100 vlan 100
+	onuServicePortRegex = regexp.MustCompile(`^service-port (\d+) vport (\d+) user-vlan (\S+) vlan (\d+)$`)
+	// service-port vlan 100 gpon 1/1/1 gemport 1 multi-service user-vlan 100 [mode] [rx-cttr N tx-cttr N].
+	// The repository addresses the ONU with the gemport number.
+	createServicePortRegex = regexp.MustCompile(`^service-port vlan (\d+) gpon (\d+/\d+/\d+) gemport (\d+) multi-service user-vlan (\d+)(.*)$`)
+	// service-port 5 vlan 200 [user-vlan 200]
+	modifyServicePortRegex = regexp.MustCompile(`^service-port (\d+) vlan (\d+)(?: user-vlan (\d+))?$`)
+	// type 3 assure 5120 max 10240
+	dbaTypeRegex = regexp.MustCompile(`^type ([1-5])((?: (?:fix|assure|max) \d+)+)$`)
+
+	opticalInfoRegex  = regexp.MustCompile(`^show gpon onu optical-info gpon-olt_(\d+/\d+/\d+)(?: (\d+))?$`)
+	uncfgRegex        = regexp.MustCompile(`^show gpon onu uncfg(?: gpon-olt_(\d+/\d+/\d+))?$`)
+	remoteONURegex    = regexp.MustCompile(`^show gpon remote-onu interface gpon-onu_(\d+/\d+/\d+):(\d+)$`)
+	remoteONUVLAN     = regexp.MustCompile(`^show gpon remote-onu interface gpon-olt_(\d+/\d+/\d+) id (\d+)$`)
+	showServicePort   = regexp.MustCompile(`^show service-port(?: id (\d+))?$`)
+	showDBAProfile    = regexp.MustCompile(`^show gpon-onu-profile dba-profile(?: (\S+))?$`)
+	showRunningConfig = regexp.MustCompile(`^show running-config(?: interface (\S+))?$`)
+)
+
+// execute runs a command line and returns its output; closeSession ends the session
+func (c *cliSession) execute(command string) (output string, closeSession bool) {
+	if output, ok := c.server.scripted(command); ok {
+		return output, false
+	}
+
+	switch {
+	case command == "exit":
+		switch c.mode {
+		case modeUser, modeEnable:
+			return "", true
+		case modeConfig:
+			c.mode = modeEnable
+		default:
+			c.mode = modeConfig
+		}
+		return "", false
+	case command == "end":
+		if c.mode >= modeConfig {
+			c.mode = modeEnable
+			return "", false
+		}
+	case command == "terminal length 0":
+		return "", false
+	case strings.HasPrefix(command, "show "):
+		return c.show(command), false
+	}
+
+	switch c.mode {
+	case modeUser:
+		return c.userCommand(command), false
+	case modeEnable:
+		return c.enableCommand(command), false
+	case modeConfig:
+		return c.configCommand(command), false
+	case modeInterfaceOLT:
+		return c.ponCommand(command), false
+	case modeInterfaceONU:
+		return c.onuCommand(command), false
+	case modeDBAProfile:
+		return c.dbaProfileCommand(command), false
+	}
+	return errInvalidInput, false
+}
+
+// userCommand handles commands at the ZXAN> prompt
+func (c *cliSession) userCommand(command string) string {
+	if command != "enable" {
+		return errInvalidInput
+	}
+	if c.server.cfg.EnablePassword != "" {
+		if err := c.write("Password:"); err != nil {
+			return ""
+		}
+		password, err := c.readLine(false)
+		if err != nil {
+			return ""
+		}
+		if password != c.server.cfg.EnablePassword {
+			return "\n" + errWrongPassword
+		}
+		c.mode = modeEnable
+		return "\n"
+	}
+	c.mode = modeEnable
+	return ""
+}
+
+// enableCommand handles commands at the ZXAN# prompt
+func (c *cliSession) enableCommand(command string) string {
+	switch command {
+	case "configure terminal", "conf t":
+		c.mode = modeConfig
+		return "Enter configuration commands, one per line.  End with CTRL/Z."
+	case "disable":
+		c.mode = modeUser
+		return ""
+	case "write":
+		st := c.server.state
+		st.mu.Lock()
+		st.saves++
+		st.mu.Unlock()
+		return "Building configuration...\n.....[OK]"
+	}
+	return errInvalidInput
+}
+
+// configCommand handles commands at the ZXAN(config)# prompt
+func (c *cliSession) configCommand(command string) string {
+	st := c.server.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if name, ok := strings.CutPrefix(command, "interface "); ok {
+		if m := ponInterfaceRegex.FindStringSubmatch(name); m != nil {
+			c.mode, c.ponPort = modeInterfaceOLT, m[1]
+			return ""
+		}
+		if m := onuInterfaceRegex.FindStringSubmatch(name); m != nil {
+			onuID, _ := strconv.Atoi(m[2])
+			if _, ok := st.onuLocked(m[1], onuID); !ok {
+				return errONUNotExist
+			}
+			c.mode, c.ponPort, c.onuID = modeInterfaceONU, m[1], onuID
+			return ""
+		}
+		return errInvalidInput
+	}
+
+	if name, ok := strings.CutPrefix(command, "gpon-onu-profile dba-profile "); ok && !strings.Contains(name, " ") {
+		if _, exists := st.dbaProfiles[name]; !exists {
+			st.dbaProfiles[name] = &DBAProfile{Name: name}
+		}
+		c.mode, c.profile = modeDBAProfile, name
+		return ""
+	}
+	if name, ok := strings.CutPrefix(command, "no gpon-onu-profile dba-profile "); ok {
+		if _, exists := st.dbaProfiles[name]; !exists {
+			return errProfileNotExist
+		}
+		for _, onus := range st.pons {
+			for _, onu := range onus {
+				for _, tcont := range onu.TCONTs {
+					if tcont.Profile == name {
+						return errProfileInUse
+					}
+				}
+			}
+		}
+		delete(st.dbaProfiles, name)
+		return ""
+	}
+
+	if m := createServicePortRegex.FindStringSubmatch(command); m != nil {
+		onuID, _ := strconv.Atoi(m[3])
+		if _, ok := st.onuLocked(m[2], onuID); !ok {
+			return errONUNotExist
+		}
+		sp := &ServicePort{ID: 1, PONPort: m[2], ONUID: onuID, Mode: "tag-transform"}
+		for st.servicePorts[sp.ID] != nil {
+			sp.ID++
+		}
+		sp.VLAN, _ = strconv.Atoi(m[1])
+		sp.UserVLAN, _ = strconv.Atoi(m[4])
+		switch {
+		case strings.Contains(m[5], "vlan-translation"):
+			sp.Mode = "vlan-translation"
+		case strings.Contains(m[5], "vlan-transparent"):
+			sp.Mode = "vlan-transparent"
+		}
+		st.servicePorts[sp.ID] = sp
+		return ""
+	}
+	if m := modifyServicePortRegex.FindStringSubmatch(command); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		sp, ok := st.servicePorts[id]
+		if !ok {
+			return errServicePortNone
+		}
+		sp.VLAN, _ = strconv.Atoi(m[2])
+		if m[3] != "" {
+			sp.UserVLAN, _ = strconv.Atoi(m[3])
+		}
+		return ""
+	}
+	if idText, ok := strings.CutPrefix(command, "no service-port "); ok {
+		id, err := strconv.Atoi(idText)
+		if err != nil {
+			return errInvalidInput
+		}
+		if _, ok := st.servicePorts[id]; !ok {
+			return errServicePortNone
+		}
+		delete(st.servicePorts, id)
+		return ""
+	}
+
+	return errInvalidInput
+}
+
+// ponCommand handles commands inside interface gpon-olt_x/y/z
+func (c *cliSession) ponCommand(command string) string {
+	st := c.server.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if m := onuDeclareRegex.FindStringSubmatch(command); m != nil {
+		onuID, _ := strconv.Atoi(m[1])
+		if _, ok := st.onuLocked(c.ponPort, onuID); ok {
+			return errONUExists
+		}
+		if pon, _ := st.findSerialLocked(m[3]); pon != "" {
+			return errSerialRegistered
+		}
+		_ = st.addONULocked(c.ponPort, ONU{ID: onuID, Type: m[2], SerialNumber: m[3]})
+		return ""
+	}
+
+	// The remaining commands address an existing ONU
+	var onu *ONU
+	onuFor := func(id string) bool {
+		onuID, _ := strconv.Atoi(id)
+		var ok bool
+		onu, ok = st.onuLocked(c.ponPort, onuID)
+		return ok
+	}
+
+	if m := onuAttributeRegex.FindStringSubmatch(command); m != nil {
+		if !onuFor(m[1]) {
+			return errONUNotExist
+		}
+		value := unquote(m[3])
+		if m[2] == "name" {
+			onu.Name = value
+		} else {
+			onu.Description = value
+		}
+		return ""
+	}
+	if m := onuStateRegex.FindStringSubmatch(command); m != nil {
+		if !onuFor(m[1]) {
+			return errONUNotExist
+		}
+		onu.Disabled = m[2] == "disable"
+		return ""
+	}
+	if id, ok := strings.CutPrefix(command, "onu reset "); ok {
+		if !onuFor(id) {
+			return errONUNotExist
+		}
+		onu.Reboots++
+		return ""
+	}
+	if id, ok := strings.CutPrefix(command, "no onu "); ok {
+		onuID, err := strconv.Atoi(id)
+		if err != nil {
+			return errInvalidInput
+		}
+		if !st.deleteONULocked(c.ponPort, onuID) {
+			return errONUNotExist
+		}
+		return ""
+	}
+
+	return errInvalidInput
+}
+
+// onuCommand handles commands inside interface gpon-onu_x/y/z:n
+func (c *cliSession) onuCommand(command string) string {
+	st := c.server.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	onu, ok := st.onuLocked(c.ponPort, c.onuID)
+	if !ok {
+		return errONUNotExist // Deleted by another session
+	}
+
+	if value, ok := strings.CutPrefix(command, "name "); ok {
+		onu.Name = unquote(value)
+		return ""
+	}
+	if value, ok := strings.CutPrefix(command, "description "); ok {
+		onu.Description = unquote(value)
+		return ""
+	}
+
+	if m := tcontRegex.FindStringSubmatch(command); m != nil {
+		if _, ok := st.dbaProfiles[m[3]]; !ok {
+			return errProfileNotExist
+		}
+		id, _ := strconv.Atoi(m[1])
+		tcont := TCONT{ID: id, Name: m[2], Profile: m[3]}
+		onu.TCONTs = upsert(onu.TCONTs, tcont, func(t TCONT) bool { return t.ID == id })
+		return ""
+	}
+	if m := gemportRegex.FindStringSubmatch(command); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		tcontID, _ := strconv.Atoi(m[3])
+		if index(onu.TCONTs, func(t TCONT) bool { return t.ID == tcontID }) < 0 {
+			return errTCONTNotExist
+		}
+		queue, _ := strconv.Atoi(m[4])
+		gem := GEMPort{ID: id, Name: m[2], TCONT: tcontID, Queue: queue}
+		onu.GEMPorts = upsert(onu.GEMPorts, gem, func(g GEMPort) bool { return g.ID == id })
+		return ""
+	}
+	if m := onuServicePortRegex.FindStringSubmatch(command); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		vport, _ := strconv.Atoi(m[2])
+		vlan, _ := strconv.Atoi(m[4])
+		sp := ONUServicePort{ID: id, VPort: vport, UserVLAN: m[3], VLAN: vlan}
+		onu.ServicePorts = upsert(onu.ServicePorts, sp, func(s ONUServicePort) bool { return s.ID == id })
+		return ""
+	}
+
+	if rest, ok := strings.CutPrefix(command, "no "); ok {
+		fields := strings.Fields(rest)
+		if len(fields) != 2 {
+			return errInvalidInput
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return errInvalidInput
+		}
+		switch fields[0] {
+		case "tcont":
+			i := index(onu.TCONTs, func(t TCONT) bool { return t.ID == id })
+			if i < 0 {
+				return errTCONTNotExist
+			}
+			if index(onu.GEMPorts, func(g GEMPort) bool { return g.TCONT == id }) >= 0 {
+				return errTCONTInUse
+			}
+			onu.TCONTs = append(onu.TCONTs[:i], onu.TCONTs[i+1:]...)
+			return ""
+		case "gemport":
+			i := index(onu.GEMPorts, func(g GEMPort) bool { return g.ID == id })
+			if i < 0 {
+				return errGEMPortNotExist
+			}
+			onu.GEMPorts = append(onu.GEMPorts[:i], onu.GEMPorts[i+1:]...)
+			return ""
+		case "service-port":
+			i := index(onu.ServicePorts, func(s ONUServicePort) bool { return s.ID == id })
+			if i < 0 {
+				return errServicePortNone
+			}
+			onu.ServicePorts = append(onu.ServicePorts[:i], onu.ServicePorts[i+1:]...)
+			return ""
+		}
+	}
+
+	return errInvalidInput
+}
+
+// dbaProfileCommand handles commands inside gpon-onu-profile dba-profile NAME
+func (c *cliSession) dbaProfileCommand(command string) string {
+	st := c.server.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	profile, ok := st.dbaProfiles[c.profile]
+	if !ok {
+		return errProfileNotExist
+	}
+
+	if command == "no type" {
+		*profile = DBAProfile{Name: profile.Name}
+		return ""
+	}
+	m := dbaTypeRegex.FindStringSubmatch(command)
+	if m == nil {
+		return errInvalidInput
+	}
+
+	updated := DBAProfile{Name: profile.Name}
+	updated.Type, _ = strconv.Atoi(m[1])
+	fields := strings.Fields(m[2])
+	for i := 0; i+1 < len(fields); i += 2 {
+		value, _ := strconv.Atoi(fields[i+1])
+		switch fields[i] {
+		case "fix":
+			updated.Fixed = value
+		case "assure":
+			updated.Assured = value
+		case "max":
+			updated.Maximum = value
+		}
+	}
+	*profile = updated
+	return ""
+}
+
+// show handles the show commands, which work in every mode
+func (c *cliSession) show(command string) string {
+	if command == "show version" {
+		return c.server.showVersion()
+	}
+
+	st := c.server.state
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if m := uncfgRegex.FindStringSubmatch(command); m != nil {
+		return st.showUncfgLocked(m[1])
+	}
+	if m := opticalInfoRegex.FindStringSubmatch(command); m != nil {
+		return st.showOpticalInfoLocked(m[1], m[2])
+	}
+	if m := remoteONURegex.FindStringSubmatch(command); m != nil {
+		onuID, _ := strconv.Atoi(m[2])
+		onu, ok := st.onuLocked(m[1], onuID)
+		if !ok {
+			return errONUNotExist
+		}
+		var lines []string
+		for _, tcont := range onu.TCONTs {
+			lines = append(lines, tcont.configLine())
+		}
+		for _, gem := range onu.GEMPorts {
+			lines = append(lines, gem.configLine())
+		}
+		return strings.Join(lines, "\n")
+	}
+	if m := remoteONUVLAN.FindStringSubmatch(command); m != nil {
+		onuID, _ := strconv.Atoi(m[2])
+		if _, ok := st.onuLocked(m[1], onuID); !ok {
+			return errONUNotExist
+		}
+		lines := []string{fmt.Sprintf("ONU interface: gpon-onu_%s:%d", m[1], onuID)}
+		for _, sp := range st.sortedServicePortsLocked() {
+			if sp.PONPort == m[1] && sp.ONUID == onuID {
+				lines = append(lines, fmt.Sprintf("service-port %d vlan %d user-vlan %d %s", sp.ID, sp.VLAN, sp.UserVLAN, sp.Mode))
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	if m := showServicePort.FindStringSubmatch(command); m != nil {
+		return st.showServicePortsLocked(m[1])
+	}
+	if m := showDBAProfile.FindStringSubmatch(command); m != nil {
+		return st.showDBAProfilesLocked(m[1])
+	}
+	if m := showRunningConfig.FindStringSubmatch(command); m != nil {
+		switch {
+		case m[1] == "":
+			return st.runningConfigLocked()
+		case ponInterfaceRegex.MatchString(m[1]):
+			return "Building configuration...\n" + st.ponConfigLocked(ponInterfaceRegex.FindStringSubmatch(m[1])[1]) + "end"
+		case onuInterfaceRegex.MatchString(m[1]):
+			om := onuInterfaceRegex.FindStringSubmatch(m[1])
+			onuID, _ := strconv.Atoi(om[2])
+			onu, ok := st.onuLocked(om[1], onuID)
+			if !ok {
+				return errONUNotExist
+			}
+			return "Building configuration...\n" + onu.interfaceConfig(om[1]) + "end"
+		}
+	}
+
+	return errInvalidInput
+}
+
+// showVersion renders "show version" with the configured software version and the uptime of the server
+func (s *CLIServer) showVersion() string {
+	uptime := time.Since(s.started)
+	return fmt.Sprintf("ZTE ZXA10 C320 Software, Version: %s, Release software\n"+
+		"Copyright (c) 2013 by ZTE Corporation\n"+
+		"System uptime is %d days %d hours %d minutes %d seconds",
+		s.cfg.Version, int(uptime.Hours())/24, int(uptime.Hours())%24, int(uptime.Minutes())%60, int(uptime.Seconds())%60)
+}
+
+// showUncfgLocked renders "show gpon onu uncfg [gpon-olt_x/y/z]"
+func (st *State) showUncfgLocked(ponPort string) string {
+	var rows []string
+	indexes := make(map[string]int)
+	for _, u := range st.uncfg {
+		indexes[u.PONPort]++
+		if ponPort != "" && u.PONPort != ponPort {
+			continue
+		}
+		rows = append(rows, fmt.Sprintf("%-24s %-19s %s", fmt.Sprintf("gpon-onu_%s:%d", u.PONPort, indexes[u.PONPort]), u.SerialNumber, "unknown"))
+	}
+	if len(rows) == 0 {
+		return errNoInformation
+	}
+	header := fmt.Sprintf("%-24s %-19s %s\n%s", "OnuIndex", "Sn", "State", strings.Repeat("-", 69))
+	return header + "\n" + strings.Join(rows, "\n")
+}
+
+// showOpticalInfoLocked renders "show gpon onu optical-info gpon-olt_x/y/z [onu]"
+func (st *State) showOpticalInfoLocked(ponPort, onuIDText string) string {
+	if onuIDText != "" {
+		onuID, _ := strconv.Atoi(onuIDText)
+		onu, ok := st.onuLocked(ponPort, onuID)
+		if !ok {
+			return errONUNotExist
+		}
+		o := onu.Optical
+		return fmt.Sprintf("ONU: gpon-onu_%s:%d\n"+
+			"OLT-Rx Optical-Power(dBm)  : %.2f\n"+
+			"ONU-Rx Optical-Power(dBm)  : %.2f\n"+
+			"ONU-Tx Optical-Power(dBm)  : %.2f\n"+
+			"ONU Laser BIAS-Current(mA) : %.2f\n"+
+			"ONU Temperature(C)         : %.2f\n"+
+			"ONU Voltage(V)             : %.2f",
+			ponPort, onuID, o.OLTRx, o.ONURx, o.ONUTx, o.BiasCurrent, o.Temperature, o.Voltage)
+	}
+
+	onus := st.sortedONUsLocked(ponPort)
+	if len(onus) == 0 {
+		return errNoInformation
+	}
+	lines := []string{
+		"OnuId  OLT-Rx(dBm)  ONU-Rx(dBm)  ONU-Tx(dBm)  Temp(C)  Voltage(V)  Current(mA)",
+		strings.Repeat("-", 79),
+	}
+	for _, onu := range onus {
+		o := onu.Optical
+		lines = append(lines, fmt.Sprintf("%-6d %-12.2f %-12.2f %-12.2f %-8.2f %-11.2f %.2f",
+			onu.ID, o.OLTRx, o.ONURx, o.ONUTx, o.Temperature, o.Voltage, o.BiasCurrent))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showServicePortsLocked renders "show service-port" and "show service-port id N"
+func (st *State) showServicePortsLocked(idText string) string {
+	if idText != "" {
+		id, _ := strconv.Atoi(idText)
+		sp, ok := st.servicePorts[id]
+		if !ok {
+			return errServicePortNone
+		}
+		return fmt.Sprintf("Index  VLAN  User-VLAN  Mode\n-----  ----  ---------  ----\n%-6d %-5d %-10d %s",
+			sp.ID, sp.VLAN, sp.UserVLAN, sp.Mode)
+	}
+
+	ports := st.sortedServicePortsLocked()
+	if len(ports) == 0 {
+		return errNoInformation
+	}
+	lines := []string{
+		"Index  VLAN  Gpon-Port   Gem-Port  User-VLAN  Mode",
+		"-----  ----  ----------  --------  ---------  ----",
+	}
+	for _, sp := range ports {
+		lines = append(lines, fmt.Sprintf("%-6d %-5d %-11s %-9d %-10d %s",
+			sp.ID, sp.VLAN, fmt.Sprintf("%s:%d", sp.PONPort, sp.ONUID), sp.ONUID, sp.UserVLAN, sp.Mode))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// showDBAProfilesLocked renders "show gpon-onu-profile dba-profile [NAME]"
+func (st *State) showDBAProfilesLocked(name string) string {
+	if name != "" {
+		profile, ok := st.dbaProfiles[name]
+		if !ok {
+			return errProfileNotExist
+		}
+		return fmt.Sprintf("dba-profile %s\n  %s", name, profile.typeLine())
+	}
+
+	var lines []string
+	for _, profile := range st.dbaProfiles {
+		lines = append(lines, fmt.Sprintf("dba-profile %s", profile.Name))
+	}
+	if len(lines) == 0 {
+		return errNoInformation
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// upsert replaces the first element matching same or appends item
+func upsert[T any](items []T, item T, same func(T) bool) []T {
+	if i := index(items, same); i >= 0 {
+		items[i] = item
+		return items
+	}
+	return append(items, item)
+}
+
+// index returns the position of the first element matching fn, or -1
+func index[T any](items []T, fn func(T) bool) int {
+	for i, item := range items {
+		if fn(item) {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote strips surrounding double quotes and unescapes \" and \\ inside them
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return unquoted
+		}
+		value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	}
+	return value
+}
@@ -0,0 +1,466 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ONU is an ONU registered on a PON port of the simulated OLT
+type ONU struct {
+	ID           int
+	Type         string
+	SerialNumber string
+	Name         string
+	Description  string
+	Disabled     bool // "onu N state disable"
+	TCONTs       []TCONT
+	GEMPorts     []GEMPort
+	ServicePorts []ONUServicePort
+	Optical      Optical
+	Reboots      int // Number of "onu reset N"
+}
+
+// TCONT is a T-CONT of an ONU ("tcont 1 name TCONT_1 profile UP-10M")
+type TCONT struct {
+	ID      int
+	Name    string
+	Profile string
+}
+
+// GEMPort is a GEM port of an ONU ("gemport 1 name GEM_1 tcont 1 queue 1")
+type GEMPort struct {
+	ID    int
+	Name  string
+	TCONT int
+	Queue int
+}
+
+// ONUServicePort is a service-port inside interface gpon-onu ("service-port 1 vport 1 user-vlan 100 vlan 100")
+type ONUServicePort struct {
+	ID       int
+	VPort    int
+	UserVLAN string // VLAN ID or "untagged"
+	VLAN     int
+}
+
+// Optical holds the optical readings of an ONU
+type Optical struct {
+	OLTRx       float64 // dBm
+	ONURx       float64 // dBm
+	ONUTx       float64 // dBm
+	Temperature float64 // °C
+	Voltage     float64 // V
+	BiasCurrent float64 // mA
+}
+
+// UnconfiguredONU is an ONU that answers on a PON port but is not registered
+type UnconfiguredONU struct {
+	PONPort      string
+	SerialNumber string
+}
+
+// DBAProfile is a DBA profile ("gpon-onu-profile dba-profile NAME" + "type 4 max 10240")
+type DBAProfile struct {
+	Name    string
+	Type    int
+	Fixed   int
+	Assured int
+	Maximum int
+}
+
+// ServicePort is an OLT-wide service-port ("service-port vlan 100 gpon 1/1/1 gemport 1 ...")
+type ServicePort struct {
+	ID       int
+	PONPort  string
+	ONUID    int
+	VLAN     int
+	UserVLAN int
+	Mode     string // tag-transform, vlan-translation or vlan-transparent
+}
+
+// State is the configuration of a simulated OLT. It is shared by all CLI sessions and safe for concurrent use;
+// tests set it up before connecting and inspect it after the code under test ran.
+type State struct {
+	mu           sync.Mutex
+	pons         map[string]map[int]*ONU
+	uncfg        []UnconfiguredONU
+	dbaProfiles  map[string]*DBAProfile
+	servicePorts map[int]*ServicePort
+	saves        int
+}
+
+// NewState creates an empty OLT configuration
+func NewState() *State {
+	return &State{
+		pons:         make(map[string]map[int]*ONU),
+		dbaProfiles:  make(map[string]*DBAProfile),
+		servicePorts: make(map[int]*ServicePort),
+	}
+}
+
+// NewDemoState creates an OLT with a few ONUs, DBA profiles and unconfigured ONUs
+func NewDemoState() *State {
+	st := NewState()
+	st.AddDBAProfile(DBAProfile{Name: "UP-10M", Type: 4, Maximum: 10240})
+	st.AddDBAProfile(DBAProfile{Name: "UP-50M", Type: 3, Assured: 10240, Maximum: 51200})
+
+	for i, sn := range []string{"ZTEGC8F10001", "ZTEGC8F10002", "HWTC1F14CAAD"} {
+		onu := ONU{
+			ID:           i + 1,
+			Type:         "ZTE-F660",
+			SerialNumber: sn,
+			Name:         fmt.Sprintf("customer-%d", i+1),
+			TCONTs:       []TCONT{{ID: 1, Name: "TCONT_1", Profile: "UP-10M"}},
+			GEMPorts:     []GEMPort{{ID: 1, Name: "GEM_1", TCONT: 1}},
+			ServicePorts: []ONUServicePort{{ID: 1, VPort: 1, UserVLAN: "100", VLAN: 100}},
+		}
+		_ = st.AddONU("1/1/1", onu) // IDs and serial numbers are unique
+	}
+	st.AddUnconfiguredONU("1/1/1", "ZTEGD824CDF3")
+	st.AddUnconfiguredONU("1/1/2", "ZTEGDA5918AC")
+	return st
+}
+
+// AddONU registers an ONU. Unset optical readings get plausible values.
+func (st *State) AddONU(ponPort string, onu ONU) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.addONULocked(ponPort, onu)
+}
+
+func (st *State) addONULocked(ponPort string, onu ONU) error {
+	if _, ok := st.onuLocked(ponPort, onu.ID); ok {
+		return fmt.Errorf("ONU %d already exists on %s", onu.ID, ponPort)
+	}
+	if pon, _ := st.findSerialLocked(onu.SerialNumber); pon != "" {
+		return fmt.Errorf("serial number %s is already registered on %s", onu.SerialNumber, pon)
+	}
+	if onu.Optical == (Optical{}) {
+		onu.Optical = defaultOptical(onu.ID)
+	}
+	if st.pons[ponPort] == nil {
+		st.pons[ponPort] = make(map[int]*ONU)
+	}
+	st.pons[ponPort][onu.ID] = &onu
+
+	// A registered ONU is no longer unconfigured
+	remaining := st.uncfg[:0]
+	for _, u := range st.uncfg {
+		if u.SerialNumber != onu.SerialNumber {
+			remaining = append(remaining, u)
+		}
+	}
+	st.uncfg = remaining
+	return nil
+}
+
+// AddUnconfiguredONU makes an unregistered ONU visible on a PON port
+func (st *State) AddUnconfiguredONU(ponPort, serialNumber string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.uncfg = append(st.uncfg, UnconfiguredONU{PONPort: ponPort, SerialNumber: serialNumber})
+}
+
+// AddDBAProfile adds or replaces a DBA profile
+func (st *State) AddDBAProfile(profile DBAProfile) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.dbaProfiles[profile.Name] = &profile
+}
+
+// AddServicePort adds or replaces an OLT-wide service-port
+func (st *State) AddServicePort(sp ServicePort) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.servicePorts[sp.ID] = &sp
+}
+
+// ONU returns a copy of a registered ONU
+func (st *State) ONU(ponPort string, onuID int) (ONU, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	onu, ok := st.onuLocked(ponPort, onuID)
+	if !ok {
+		return ONU{}, false
+	}
+	return onu.clone(), true
+}
+
+// ONUs returns copies of the ONUs of a PON port ordered by ID
+func (st *State) ONUs(ponPort string) []ONU {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var onus []ONU
+	for _, onu := range st.sortedONUsLocked(ponPort) {
+		onus = append(onus, onu.clone())
+	}
+	return onus
+}
+
+// UnconfiguredONUs returns the unregistered ONUs
+func (st *State) UnconfiguredONUs() []UnconfiguredONU {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return append([]UnconfiguredONU(nil), st.uncfg...)
+}
+
+// DBAProfile returns a DBA profile by name
+func (st *State) DBAProfile(name string) (DBAProfile, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	profile, ok := st.dbaProfiles[name]
+	if !ok {
+		return DBAProfile{}, false
+	}
+	return *profile, true
+}
+
+// ServicePorts returns the OLT-wide service-ports ordered by ID
+func (st *State) ServicePorts() []ServicePort {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	var ports []ServicePort
+	for _, sp := range st.sortedServicePortsLocked() {
+		ports = append(ports, *sp)
+	}
+	return ports
+}
+
+// Saves returns how often the configuration was saved with "write"
+func (st *State) Saves() int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.saves
+}
+
+// Update runs fn with exclusive access to an ONU, e.g. to change its optical readings
+func (st *State) Update(ponPort string, onuID int, fn func(onu *ONU)) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	onu, ok := st.onuLocked(ponPort, onuID)
+	if ok {
+		fn(onu)
+	}
+	return ok
+}
+
+func (st *State) onuLocked(ponPort string, onuID int) (*ONU, bool) {
+	onu, ok := st.pons[ponPort][onuID]
+	return onu, ok
+}
+
+// findSerialLocked returns the PON port and ONU with the serial number, or "" if it is not registered
+func (st *State) findSerialLocked(serialNumber string) (string, *ONU) {
+	for ponPort, onus := range st.pons {
+		for _, onu := range onus {
+			if strings.EqualFold(onu.SerialNumber, serialNumber) {
+				return ponPort, onu
+			}
+		}
+	}
+	return "", nil
+}
+
+// deleteONULocked removes an ONU with its service-ports; the ONU shows up as unconfigured again
+func (st *State) deleteONULocked(ponPort string, onuID int) bool {
+	onu, ok := st.onuLocked(ponPort, onuID)
+	if !ok {
+		return false
+	}
+	delete(st.pons[ponPort], onuID)
+	for id, sp := range st.servicePorts {
+		if sp.PONPort == ponPort && sp.ONUID == onuID {
+			delete(st.servicePorts, id)
+		}
+	}
+	st.uncfg = append(st.uncfg, UnconfiguredONU{PONPort: ponPort, SerialNumber: onu.SerialNumber})
+	return true
+}
+
+func (st *State) sortedPONsLocked() []string {
+	pons := make([]string, 0, len(st.pons))
+	for pon, onus := range st.pons {
+		if len(onus) > 0 {
+			pons = append(pons, pon)
+		}
+	}
+	sort.Slice(pons, func(i, j int) bool { return portLess(pons[i], pons[j]) })
+	return pons
+}
+
+func (st *State) sortedONUsLocked(ponPort string) []*ONU {
+	onus := make([]*ONU, 0, len(st.pons[ponPort]))
+	for _, onu := range st.pons[ponPort] {
+		onus = append(onus, onu)
+	}
+	sort.Slice(onus, func(i, j int) bool { return onus[i].ID < onus[j].ID })
+	return onus
+}
+
+func (st *State) sortedServicePortsLocked() []*ServicePort {
+	ports := make([]*ServicePort, 0, len(st.servicePorts))
+	for _, sp := range st.servicePorts {
+		ports = append(ports, sp)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i].ID < ports[j].ID })
+	return ports
+}
+
+// runningConfigLocked renders "show running-config"
+func (st *State) runningConfigLocked() string {
+	var b strings.Builder
+	b.WriteString("Building configuration...\n!\n")
+
+	names := make([]string, 0, len(st.dbaProfiles))
+	for name := range st.dbaProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		profile := st.dbaProfiles[name]
+		fmt.Fprintf(&b, "gpon-onu-profile dba-profile %s\n", name)
+		if line := profile.typeLine(); line != "" {
+			fmt.Fprintf(&b, "  %s\n", line)
+		}
+		b.WriteString("!\n")
+	}
+
+	pons := st.sortedPONsLocked()
+	for _, pon := range pons {
+		b.WriteString(st.ponConfigLocked(pon))
+	}
+	for _, pon := range pons {
+		for _, onu := range st.sortedONUsLocked(pon) {
+			b.WriteString(onu.interfaceConfig(pon))
+		}
+	}
+
+	for _, sp := range st.sortedServicePortsLocked() {
+		fmt.Fprintf(&b, "%s\n", sp.configLine())
+	}
+	b.WriteString("!\nend")
+	return b.String()
+}
+
+// ponConfigLocked renders "show running-config interface gpon-olt_x/y/z"
+func (st *State) ponConfigLocked(ponPort string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "interface gpon-olt_%s\n", ponPort)
+	onus := st.sortedONUsLocked(ponPort)
+	for _, onu := range onus {
+		fmt.Fprintf(&b, "  onu %d type %s sn %s\n", onu.ID, onu.Type, onu.SerialNumber)
+	}
+	for _, onu := range onus {
+		if onu.Name != "" {
+			fmt.Fprintf(&b, "  onu %d name %s\n", onu.ID, quote(onu.Name))
+		}
+		if onu.Description != "" {
+			fmt.Fprintf(&b, "  onu %d description %s\n", onu.ID, quote(onu.Description))
+		}
+		if onu.Disabled {
+			fmt.Fprintf(&b, "  onu %d state disable\n", onu.ID)
+		}
+	}
+	b.WriteString("!\n")
+	return b.String()
+}
+
+// interfaceConfig renders "show running-config interface gpon-onu_x/y/z:n"
+func (onu *ONU) interfaceConfig(ponPort string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "interface gpon-onu_%s:%d\n", ponPort, onu.ID)
+	for _, tcont := range onu.TCONTs {
+		fmt.Fprintf(&b, "  %s\n", tcont.configLine())
+	}
+	for _, gem := range onu.GEMPorts {
+		fmt.Fprintf(&b, "  %s\n", gem.configLine())
+	}
+	for _, sp := range onu.ServicePorts {
+		fmt.Fprintf(&b, "  service-port %d vport %d user-vlan %s vlan %d\n", sp.ID, sp.VPort, sp.UserVLAN, sp.VLAN)
+	}
+	b.WriteString("!\n")
+	return b.String()
+}
+
+func (onu *ONU) clone() ONU {
+	c := *onu
+	c.TCONTs = append([]TCONT(nil), onu.TCONTs...)
+	c.GEMPorts = append([]GEMPort(nil), onu.GEMPorts...)
+	c.ServicePorts = append([]ONUServicePort(nil), onu.ServicePorts...)
+	return c
+}
+
+func (t TCONT) configLine() string {
+	if t.Name != "" {
+		return fmt.Sprintf("tcont %d name %s profile %s", t.ID, t.Name, t.Profile)
+	}
+	return fmt.Sprintf("tcont %d profile %s", t.ID, t.Profile)
+}
+
+func (g GEMPort) configLine() string {
+	line := fmt.Sprintf("gemport %d", g.ID)
+	if g.Name != "" {
+		line += " name " + g.Name
+	}
+	line += fmt.Sprintf(" tcont %d", g.TCONT)
+	if g.Queue > 0 {
+		line += fmt.Sprintf(" queue %d", g.Queue)
+	}
+	return line
+}
+
+func (sp *ServicePort) configLine() string {
+	return fmt.Sprintf("service-port %d vlan %d gpon %s:%d user-vlan %d %s", sp.ID, sp.VLAN, sp.PONPort, sp.ONUID, sp.UserVLAN, sp.Mode)
+}
+
+// typeLine renders the bandwidth line of a DBA profile ("" if the type is not set)
+func (p *DBAProfile) typeLine() string {
+	switch p.Type {
+	case 1:
+		return fmt.Sprintf("type 1 fix %d", p.Fixed)
+	case 2:
+		return fmt.Sprintf("type 2 assure %d", p.Assured)
+	case 3, 5:
+		return fmt.Sprintf("type %d assure %d max %d", p.Type, p.Assured, p.Maximum)
+	case 4:
+		return fmt.Sprintf("type 4 max %d", p.Maximum)
+	}
+	return ""
+}
+
+// defaultOptical returns readings in the normal range that differ per ONU
+func defaultOptical(onuID int) Optical {
+	offset := float64(onuID%10) * 0.37
+	return Optical{
+		OLTRx:       -18.5 - offset,
+		ONURx:       -19.2 - offset,
+		ONUTx:       2.1 + offset/4,
+		Temperature: 41.0 + offset,
+		Voltage:     3.28,
+		BiasCurrent: 14.6 + offset,
+	}
+}
+
+// portLess orders PON ports "r/s/p" numerically
+func portLess(a, b string) bool {
+	var ar, as, ap, br, bs, bp int
+	fmt.Sscanf(a, "%d/%d/%d", &ar, &as, &ap)
+	fmt.Sscanf(b, "%d/%d/%d", &br, &bs, &bp)
+	if ar != br {
+		return ar < br
+	}
+	if as != bs {
+		return as < bs
+	}
+	return ap < bp
+}
+
+// quote quotes a CLI value that contains spaces, quotes or backslashes, escaping the latter two
+func quote(value string) string {
+	if strings.ContainsAny(value, " \t\"\\") {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+	}
+	return value
+}
@@ -0,0 +1,204 @@
+package simulator
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cliClient is a minimal line-mode client for the simulator
+type cliClient struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// dialCLI starts a server for state and logs in
+func dialCLI(t *testing.T, cfg CLIConfig, state *State) (*CLIServer, *cliClient) {
+	t.Helper()
+
+	server := NewCLIServer(cfg, state)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c := &cliClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	c.expect("Username:")
+	c.send(server.cfg.Username)
+	c.expect("Password:")
+	c.send(server.cfg.Password)
+	return server, c
+}
+
+func (c *cliClient) send(line string) {
+	c.t.Helper()
+	if _, err := c.conn.Write([]byte(line + "\r\n")); err != nil {
+		c.t.Fatalf("write %q: %v", line, err)
+	}
+}
+
+// expect reads until pattern and returns the text before it
+func (c *cliClient) expect(pattern string) string {
+	c.t.Helper()
+	_ = c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var buf strings.Builder
+	for !strings.HasSuffix(buf.String(), pattern) {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			c.t.Fatalf("waiting for %q after %q: %v", pattern, buf.String(), err)
+		}
+		if b != telnetIAC {
+			buf.WriteByte(b)
+			continue
+		}
+		// Skip the negotiation sent with the banner
+		if _, err := c.r.Discard(2); err != nil {
+			c.t.Fatalf("telnet negotiation: %v", err)
+		}
+	}
+	return strings.TrimSuffix(buf.String(), pattern)
+}
+
+// run sends a command and returns its output up to the next prompt
+func (c *cliClient) run(command, prompt string) string {
+	c.t.Helper()
+	c.send(command)
+	output := c.expect(prompt)
+	return strings.TrimSpace(strings.TrimPrefix(output, command))
+}
+
+func TestCLIServer_Modes(t *testing.T) {
+	server, c := dialCLI(t, CLIConfig{}, NewDemoState())
+	c.expect("ZXAN>")
+
+	if out := c.run("interface gpon-olt_1/1/1", "ZXAN>"); !strings.Contains(out, "Invalid input") {
+		t.Errorf("config command in user mode = %q, want an error", out)
+	}
+	c.run("enable", "ZXAN#")
+	c.run("configure terminal", "ZXAN(config)#")
+	c.run("interface gpon-olt_1/1/1", "ZXAN(config-if)#")
+	c.run("onu 4 type ZTE-F609 sn ZTEGD824CDF3", "ZXAN(config-if)#")
+	if out := c.run("onu 5 type ZTE-F609 sn ZTEGD824CDF3", "ZXAN(config-if)#"); !strings.Contains(out, "SN is already registered") {
+		t.Errorf("duplicate serial = %q, want an error", out)
+	}
+	c.run("exit", "ZXAN(config)#")
+	c.run("interface gpon-onu_1/1/1:4", "ZXAN(config-if)#")
+	if out := c.run("tcont 1 profile NOPE", "ZXAN(config-if)#"); !strings.Contains(out, "DBA profile does not exist") {
+		t.Errorf("tcont with an unknown profile = %q, want an error", out)
+	}
+	c.run("tcont 1 name T1 profile UP-10M", "ZXAN(config-if)#")
+	c.run("gemport 1 name G1 tcont 1", "ZXAN(config-if)#")
+	c.run("end", "ZXAN#")
+	if out := c.run("write", "ZXAN#"); !strings.Contains(out, "[OK]") {
+		t.Errorf("write = %q", out)
+	}
+
+	onu, ok := server.State().ONU("1/1/1", 4)
+	if !ok || len(onu.TCONTs) != 1 || len(onu.GEMPorts) != 1 {
+		t.Errorf("ONU 4 = %+v, %v", onu, ok)
+	}
+	for _, u := range server.State().UnconfiguredONUs() {
+		if u.SerialNumber == "ZTEGD824CDF3" {
+			t.Error("registered ONU is still unconfigured")
+		}
+	}
+	if saves := server.State().Saves(); saves != 1 {
+		t.Errorf("Saves() = %d, want 1", saves)
+	}
+}
+
+func TestCLIServer_Show(t *testing.T) {
+	_, c := dialCLI(t, CLIConfig{Privileged: true}, NewDemoState())
+	c.expect("ZXAN#")
+
+	tests := []struct {
+		command string
+		want    []string
+	}{
+		{"show gpon onu uncfg", []string{"OnuIndex", "gpon-onu_1/1/1:1", "ZTEGD824CDF3", "gpon-onu_1/1/2:1", "ZTEGDA5918AC"}},
+		{"show gpon onu uncfg gpon-olt_1/1/2", []string{"ZTEGDA5918AC"}},
+		{"show gpon onu optical-info gpon-olt_1/1/1 1", []string{"OLT-Rx Optical-Power(dBm)", "ONU Voltage(V)"}},
+		{"show gpon onu optical-info gpon-olt_1/1/1", []string{"OnuId", "Current(mA)"}},
+		{"show gpon-onu-profile dba-profile UP-50M", []string{"type 3 assure 10240 max 51200"}},
+		{"show gpon-onu-profile dba-profile", []string{"dba-profile UP-10M", "dba-profile UP-50M"}},
+		{"show running-config interface gpon-onu_1/1/1:2", []string{"tcont 1 name TCONT_1 profile UP-10M", "service-port 1 vport 1 user-vlan 100 vlan 100"}},
+		{"show service-port id 7", []string{"service-port does not exist"}},
+		{"show gpon onu state", []string{"Invalid input"}},
+	}
+	for _, tt := range tests {
+		out := c.run(tt.command, "ZXAN#")
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s = %q, want it to contain %q", tt.command, out, want)
+			}
+		}
+	}
+
+	if out := c.run("show gpon onu uncfg gpon-olt_1/1/2", "ZXAN#"); strings.Contains(out, "ZTEGD824CDF3") {
+		t.Errorf("uncfg of 1/1/2 lists ONUs of other ports: %q", out)
+	}
+}
+
+func TestCLIServer_ServicePorts(t *testing.T) {
+	server, c := dialCLI(t, CLIConfig{Privileged: true}, NewDemoState())
+	c.expect("ZXAN#")
+	c.run("configure terminal", "ZXAN(config)#")
+	c.run("service-port vlan 200 gpon 1/1/1 gemport 2 multi-service user-vlan 20 vlan-translation 20", "ZXAN(config)#")
+	c.run("service-port vlan 300 gpon 1/1/1 gemport 3 multi-service user-vlan 30 tag-transform default", "ZXAN(config)#")
+	c.run("service-port 1 vlan 201 user-vlan 21", "ZXAN(config)#")
+	c.run("no service-port 2", "ZXAN(config)#")
+	if out := c.run("service-port vlan 400 gpon 1/1/1 gemport 9 multi-service user-vlan 40", "ZXAN(config)#"); !strings.Contains(out, "ONU does not exist") {
+		t.Errorf("service-port of a missing ONU = %q, want an error", out)
+	}
+
+	ports := server.State().ServicePorts()
+	if len(ports) != 1 || ports[0].VLAN != 201 || ports[0].UserVLAN != 21 || ports[0].Mode != "vlan-translation" {
+		t.Fatalf("ServicePorts() = %+v", ports)
+	}
+
+	out := c.run("show gpon remote-onu interface gpon-olt_1/1/1 id 2", "ZXAN(config)#")
+	if !strings.Contains(out, "service-port 1 vlan 201") {
+		t.Errorf("remote-onu = %q", out)
+	}
+}
+
+func TestCLIServer_Handle(t *testing.T) {
+	server, c := dialCLI(t, CLIConfig{}, NewDemoState())
+	server.Handle(`show version`, func(*State, []string) string { return "ZXAN C320 V2.1.0" })
+	server.Handle(`show gpon onu detail-info gpon-onu_(\S+)`, func(_ *State, m []string) string {
+		return "ONU interface: gpon-onu_" + m[1]
+	})
+	c.expect("ZXAN>")
+
+	if out := c.run("show version", "ZXAN>"); out != "ZXAN C320 V2.1.0" {
+		t.Errorf("show version = %q", out)
+	}
+	if out := c.run("show gpon onu detail-info gpon-onu_1/1/1:2", "ZXAN>"); out != "ONU interface: gpon-onu_1/1/1:2" {
+		t.Errorf("detail-info = %q", out)
+	}
+	if cmds := server.Commands(); len(cmds) != 2 || cmds[0] != "show version" {
+		t.Errorf("Commands() = %q", cmds)
+	}
+}
+
+func TestCLIServer_MaxSessions(t *testing.T) {
+	server, c := dialCLI(t, CLIConfig{MaxSessions: 1}, nil)
+	c.expect("ZXAN>")
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	second := &cliClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+	second.expect("No free VTY line")
+}
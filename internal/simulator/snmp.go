@@ -0,0 +1,442 @@
+package simulator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/pkg/snmp"
+)
+
+// USM statistics reported to SNMPv3 requests that cannot be processed (RFC 3414)
+const (
+	usmStatsUnsupportedSecLevels = ".1.3.6.1.6.3.15.1.1.1.0"
+	usmStatsUnknownUserNames     = ".1.3.6.1.6.3.15.1.1.3.0"
+	usmStatsUnknownEngineIDs     = ".1.3.6.1.6.3.15.1.1.4.0"
+	usmStatsWrongDigests         = ".1.3.6.1.6.3.15.1.1.5.0"
+)
+
+// maxBulkVarbinds caps the variable bindings of a GetBulk response to keep it in one datagram
+const maxBulkVarbinds = 100
+
+// SNMPConfig configures the simulated SNMP agent. v2c requests are answered for Community;
+// SNMPv3 requests are answered for User when it is set, with the same protocol names as config.SnmpConfig.
+type SNMPConfig struct {
+	Community      string // v2c community (default "public")
+	User           string // SNMPv3 user; empty disables SNMPv3
+	AuthProtocol   string // "MD5", "SHA" or "SHA256"; empty for noAuthNoPriv
+	AuthPassphrase string
+	PrivProtocol   string // "DES" or "AES"; empty for no privacy
+	PrivPassphrase string
+	EngineID       string // SNMPv3 authoritative engine ID (default derived from the ZTE enterprise number)
+}
+
+// SNMPAgent is a simulated C320 SNMP agent serving Get, GetNext and GetBulk requests from a record set,
+// typically loaded from snmpwalk output. Values can be changed while it runs, directly through Records
+// or periodically with Every.
+type SNMPAgent struct {
+	cfg     SNMPConfig
+	records *Records
+	started time.Time
+
+	// SNMPv3 user, with keys localized to the engine ID
+	usm      *gosnmp.UsmSecurityParameters
+	usmFlags gosnmp.SnmpV3MsgFlags
+
+	mu       sync.Mutex
+	conn     net.PacketConn
+	requests int
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewSNMPAgent creates an SNMP agent for records (a new empty record set if nil)
+func NewSNMPAgent(cfg SNMPConfig, records *Records) *SNMPAgent {
+	if cfg.Community == "" {
+		cfg.Community = "public"
+	}
+	if cfg.EngineID == "" {
+		// RFC 3411 format: enterprise 3902 (ZTE), text format
+		cfg.EngineID = "\x80\x00\x0f\x3e\x04go-api-c320"
+	}
+	if records == nil {
+		records = NewRecords()
+	}
+	return &SNMPAgent{
+		cfg:     cfg,
+		records: records,
+		started: time.Now(),
+		stop:    make(chan struct{}),
+	}
+}
+
+// Records returns the records served by the agent
+func (a *SNMPAgent) Records() *Records {
+	return a.records
+}
+
+// Requests returns the number of requests answered so far
+func (a *SNMPAgent) Requests() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.requests
+}
+
+// Listen starts serving SNMP on the UDP address addr (e.g. "127.0.0.1:0") in the background
+func (a *SNMPAgent) Listen(addr string) error {
+	if a.cfg.User != "" {
+		if err := a.initUSM(); err != nil {
+			return err
+		}
+	}
+
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	a.conn = conn
+	a.mu.Unlock()
+
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		buf := make([]byte, 65535)
+		for {
+			n, peer, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if response := a.handle(append([]byte(nil), buf[:n]...)); response != nil {
+				_, _ = conn.WriteTo(response, peer)
+			}
+		}
+	}()
+	return nil
+}
+
+// Addr returns the address the agent listens on
+func (a *SNMPAgent) Addr() *net.UDPAddr {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.conn == nil {
+		return nil
+	}
+	return a.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// Every applies mutation to the records at each interval until the agent is closed
+func (a *SNMPAgent) Every(interval time.Duration, mutation Mutation) {
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+				mutation(a.records)
+			}
+		}
+	}()
+}
+
+// Close stops the agent and its mutations
+func (a *SNMPAgent) Close() error {
+	a.mu.Lock()
+	var err error
+	if a.conn != nil {
+		err = a.conn.Close()
+	}
+	select {
+	case <-a.stop:
+	default:
+		close(a.stop)
+	}
+	a.mu.Unlock()
+
+	a.wg.Wait()
+	return err
+}
+
+// SnmpConfig returns client settings for the running agent: SNMPv3 when a user is configured, v2c otherwise
+func (a *SNMPAgent) SnmpConfig() config.SnmpConfig {
+	addr := a.Addr()
+	cfg := config.SnmpConfig{
+		IP:        addr.IP.String(),
+		Port:      uint16(addr.Port),
+		Community: a.cfg.Community,
+		Version:   config.SnmpVersion2c,
+	}
+	if a.cfg.User != "" {
+		cfg.Version = config.SnmpVersion3
+		cfg.User = a.cfg.User
+		cfg.AuthProtocol, cfg.AuthPassphrase = a.cfg.AuthProtocol, a.cfg.AuthPassphrase
+		cfg.PrivProtocol, cfg.PrivPassphrase = a.cfg.PrivProtocol, a.cfg.PrivPassphrase
+	}
+	return cfg
+}
+
+// initUSM derives the security parameters of the SNMPv3 user, localized to the engine ID
+func (a *SNMPAgent) initUSM() error {
+	cfg := config.SnmpConfig{
+		Version:        config.SnmpVersion3,
+		User:           a.cfg.User,
+		AuthProtocol:   a.cfg.AuthProtocol,
+		AuthPassphrase: a.cfg.AuthPassphrase,
+		PrivProtocol:   a.cfg.PrivProtocol,
+		PrivPassphrase: a.cfg.PrivPassphrase,
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid SNMPv3 user: %w", err)
+	}
+
+	var params gosnmp.GoSNMP
+	snmp.ApplySecurity(&params, cfg)
+	usm := params.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	usm.AuthoritativeEngineID = a.cfg.EngineID
+	if err := usm.InitSecurityKeys(); err != nil {
+		return fmt.Errorf("failed to derive SNMPv3 keys: %w", err)
+	}
+	a.usm, a.usmFlags = usm, params.MsgFlags
+	return nil
+}
+
+// handle answers one request datagram; nil means no response is sent
+func (a *SNMPAgent) handle(msg []byte) []byte {
+	version, err := messageVersion(msg)
+	if err != nil {
+		return nil
+	}
+
+	var response *gosnmp.SnmpPacket
+	switch version {
+	case gosnmp.Version2c:
+		response = a.handleV2c(msg)
+	case gosnmp.Version3:
+		if a.usm != nil {
+			response = a.handleV3(msg)
+		}
+	}
+	if response == nil {
+		return nil
+	}
+
+	out, err := response.MarshalMsg()
+	if err != nil {
+		return nil
+	}
+	a.mu.Lock()
+	a.requests++
+	a.mu.Unlock()
+	return out
+}
+
+// handleV2c answers a v2c request; requests with a wrong community are dropped like on the OLT
+func (a *SNMPAgent) handleV2c(msg []byte) *gosnmp.SnmpPacket {
+	decoder := &gosnmp.GoSNMP{Version: gosnmp.Version2c, Logger: gosnmp.Logger{}}
+	request, err := decoder.SnmpDecodePacket(msg)
+	if err != nil || request.Community != a.cfg.Community {
+		return nil
+	}
+	response := a.respond(request)
+	if response != nil {
+		response.Version, response.Community = gosnmp.Version2c, request.Community
+	}
+	return response
+}
+
+// handleV3 answers an SNMPv3 request: engine discovery, USM checks, then the request itself
+func (a *SNMPAgent) handleV3(msg []byte) *gosnmp.SnmpPacket {
+	raw := append([]byte(nil), msg...) // Decoding modifies msg in place
+	decoder := &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           a.usmFlags,
+		SecurityParameters: a.usm.Copy(),
+		Logger:             gosnmp.Logger{},
+	}
+	request, err := decoder.SnmpDecodePacket(msg)
+	if err != nil {
+		return nil // Unknown user with privacy, wrong priv passphrase or malformed message
+	}
+	params, ok := request.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return nil
+	}
+
+	switch {
+	case params.AuthoritativeEngineID != a.cfg.EngineID:
+		return a.report(request, usmStatsUnknownEngineIDs)
+	case params.UserName != a.cfg.User:
+		return a.report(request, usmStatsUnknownUserNames)
+	case request.MsgFlags&gosnmp.AuthPriv != a.usmFlags&gosnmp.AuthPriv:
+		return a.report(request, usmStatsUnsupportedSecLevels)
+	case a.usmFlags&gosnmp.AuthNoPriv != 0 && !authentic(raw, params):
+		return a.report(request, usmStatsWrongDigests)
+	}
+
+	response := a.respond(request)
+	if response == nil {
+		return nil
+	}
+	a.secure(response, request, a.usmFlags)
+	return response
+}
+
+// report builds an unauthenticated Report PDU for an SNMPv3 request, as sent during engine discovery
+func (a *SNMPAgent) report(request *gosnmp.SnmpPacket, counter string) *gosnmp.SnmpPacket {
+	response := &gosnmp.SnmpPacket{
+		PDUType:   gosnmp.Report,
+		RequestID: request.RequestID,
+		Variables: []gosnmp.SnmpPDU{{Name: counter, Type: gosnmp.Counter32, Value: uint32(1)}},
+	}
+	a.secure(response, request, gosnmp.NoAuthNoPriv)
+	return response
+}
+
+// secure fills the SNMPv3 header and USM parameters of a response at the given security level
+func (a *SNMPAgent) secure(response, request *gosnmp.SnmpPacket, flags gosnmp.SnmpV3MsgFlags) {
+	params := a.usm.Copy().(*gosnmp.UsmSecurityParameters)
+	params.AuthoritativeEngineBoots = 1
+	params.AuthoritativeEngineTime = uint32(time.Since(a.started).Seconds())
+	if requestParams, ok := request.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+		params.UserName = requestParams.UserName
+	}
+	if flags&gosnmp.AuthPriv == gosnmp.AuthPriv {
+		params.PrivacyParameters = make([]byte, 8)
+		_, _ = rand.Read(params.PrivacyParameters)
+	}
+
+	response.Version = gosnmp.Version3
+	response.MsgID = request.MsgID
+	response.MsgMaxSize = request.MsgMaxSize
+	response.MsgFlags = flags
+	response.SecurityModel = gosnmp.UserSecurityModel
+	response.SecurityParameters = params
+	response.ContextEngineID = a.cfg.EngineID
+	response.ContextName = request.ContextName
+	response.Logger = gosnmp.Logger{}
+}
+
+// authentic verifies the HMAC of an authenticated SNMPv3 message against the localized key of the user
+func authentic(msg []byte, params *gosnmp.UsmSecurityParameters) bool {
+	digest := []byte(params.AuthenticationParameters)
+	i := bytes.Index(msg, digest)
+	if len(digest) == 0 || i < 0 {
+		return false
+	}
+	// The digest is computed with the authentication parameters zeroed
+	copy(msg[i:i+len(digest)], make([]byte, len(digest)))
+
+	mac := hmac.New(params.AuthenticationProtocol.HashType().New, params.SecretKey)
+	mac.Write(msg)
+	return hmac.Equal(mac.Sum(nil)[:len(digest)], digest)
+}
+
+// respond answers the PDU of a request from the records
+func (a *SNMPAgent) respond(request *gosnmp.SnmpPacket) *gosnmp.SnmpPacket {
+	response := &gosnmp.SnmpPacket{
+		PDUType:   gosnmp.GetResponse,
+		RequestID: request.RequestID,
+		Logger:    gosnmp.Logger{},
+	}
+
+	switch request.PDUType {
+	case gosnmp.GetRequest:
+		for _, v := range request.Variables {
+			response.Variables = append(response.Variables, a.get(v.Name))
+		}
+	case gosnmp.GetNextRequest:
+		for _, v := range request.Variables {
+			response.Variables = append(response.Variables, a.next(v.Name))
+		}
+	case gosnmp.GetBulkRequest:
+		response.Variables = a.bulk(request.Variables, int(request.NonRepeaters), int(request.MaxRepetitions))
+	case gosnmp.SetRequest:
+		// The record set is changed through Records, not over SNMP
+		response.Variables = request.Variables
+		response.Error = gosnmp.NotWritable
+		response.ErrorIndex = 1
+	default:
+		return nil
+	}
+	return response
+}
+
+// get returns the variable binding of oid for a Get request
+func (a *SNMPAgent) get(oid string) gosnmp.SnmpPDU {
+	rec, ok := a.records.Get(oid)
+	if !ok {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.NoSuchObject}
+	}
+	return gosnmp.SnmpPDU{Name: rec.OID, Type: rec.Type, Value: rec.Value}
+}
+
+// next returns the variable binding following oid for a GetNext request
+func (a *SNMPAgent) next(oid string) gosnmp.SnmpPDU {
+	rec, ok := a.records.Next(oid)
+	if !ok {
+		return gosnmp.SnmpPDU{Name: oid, Type: gosnmp.EndOfMibView}
+	}
+	return gosnmp.SnmpPDU{Name: rec.OID, Type: rec.Type, Value: rec.Value}
+}
+
+// bulk answers a GetBulk request (RFC 3416 section 4.2.3)
+func (a *SNMPAgent) bulk(variables []gosnmp.SnmpPDU, nonRepeaters, maxRepetitions int) []gosnmp.SnmpPDU {
+	if nonRepeaters > len(variables) {
+		nonRepeaters = len(variables)
+	}
+	var result []gosnmp.SnmpPDU
+	for _, v := range variables[:nonRepeaters] {
+		result = append(result, a.next(v.Name))
+	}
+
+	repeaters := variables[nonRepeaters:]
+	last := make([]string, len(repeaters))
+	for i, v := range repeaters {
+		last[i] = v.Name
+	}
+	for r := 0; r < maxRepetitions && len(repeaters) > 0; r++ {
+		if len(result)+len(repeaters) > maxBulkVarbinds {
+			break
+		}
+		done := true
+		for i := range repeaters {
+			pdu := a.next(last[i])
+			result = append(result, pdu)
+			if pdu.Type != gosnmp.EndOfMibView {
+				last[i], done = pdu.Name, false
+			}
+		}
+		if done {
+			break
+		}
+	}
+	return result
+}
+
+// messageVersion reads the version field at the start of an SNMP message
+func messageVersion(msg []byte) (gosnmp.SnmpVersion, error) {
+	// SEQUENCE, length (short or long form), INTEGER 1 byte: version
+	if len(msg) < 2 || msg[0] != byte(gosnmp.Sequence) {
+		return 0, errors.New("not an SNMP message")
+	}
+	i := 2
+	if msg[1]&0x80 != 0 {
+		i += int(msg[1] & 0x7f)
+	}
+	if len(msg) < i+3 || msg[i] != byte(gosnmp.Integer) || msg[i+1] != 1 {
+		return 0, errors.New("not an SNMP message")
+	}
+	return gosnmp.SnmpVersion(msg[i+2]), nil
+}
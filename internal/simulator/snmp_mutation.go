@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// Mutation changes the records of an agent, e.g. to take an ONU offline or to increase traffic counters.
+// Mutations are applied directly or periodically with SNMPAgent.Every.
+type Mutation func(records *Records)
+
+// SetValue sets oid to value on every application
+func SetValue(oid string, typ gosnmp.Asn1BER, value interface{}) Mutation {
+	return func(records *Records) {
+		records.Set(oid, typ, value)
+	}
+}
+
+// CycleValues sets oid to the next of values on every application, wrapping around,
+// e.g. an ONU status alternating between online and offline
+func CycleValues(oid string, typ gosnmp.Asn1BER, values ...interface{}) Mutation {
+	var mu sync.Mutex
+	next := 0
+	return func(records *Records) {
+		if len(values) == 0 {
+			return
+		}
+		mu.Lock()
+		value := values[next%len(values)]
+		next++
+		mu.Unlock()
+		records.Set(oid, typ, value)
+	}
+}
+
+// IncrementCounters adds delta to every Counter32 and Counter64 at or below oid.
+// Counter32 values wrap around at 2^32 like on the OLT.
+func IncrementCounters(oid string, delta uint64) Mutation {
+	return func(records *Records) {
+		records.Update(oid, func(rec *Record) {
+			switch v := rec.Value.(type) {
+			case uint32:
+				if rec.Type == gosnmp.Counter32 {
+					rec.Value = v + uint32(delta)
+				}
+			case uint64:
+				if rec.Type == gosnmp.Counter64 {
+					rec.Value = v + delta
+				}
+			}
+		})
+	}
+}
+
+// ParseMutation parses a periodic mutation given as "<interval> <action> <oid> <arguments>":
+//
+//	30s inc .1.3.6.1.4.1.3902.1012.3.31.4.1 1500            Increase counters below an OID
+//	1m cycle .1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.2 INTEGER 1,2   Alternate a value
+//	5m set .1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.3 INTEGER 2       Set a value once per interval
+//
+// Values use the type names of snmpwalk output (INTEGER, STRING, Hex-STRING, Counter32, ...).
+func ParseMutation(spec string) (time.Duration, Mutation, error) {
+	fields := strings.Fields(spec)
+	if len(fields) < 4 {
+		return 0, nil, fmt.Errorf("mutation %q: expected \"<interval> <action> <oid> <arguments>\"", spec)
+	}
+	interval, err := time.ParseDuration(fields[0])
+	if err != nil || interval <= 0 {
+		return 0, nil, fmt.Errorf("mutation %q: invalid interval %q", spec, fields[0])
+	}
+	oid := normalizeOID(fields[2])
+	if _, err := parseOID(oid); err != nil {
+		return 0, nil, fmt.Errorf("mutation %q: %w", spec, err)
+	}
+
+	switch fields[1] {
+	case "inc":
+		delta, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("mutation %q: invalid increment %q", spec, fields[3])
+		}
+		return interval, IncrementCounters(oid, delta), nil
+	case "set", "cycle":
+		if len(fields) < 5 {
+			return 0, nil, fmt.Errorf("mutation %q: expected a type and a value", spec)
+		}
+		kind, text := fields[3], strings.Join(fields[4:], " ")
+		if fields[1] == "set" {
+			typ, value, err := parseRecordValue(kind + ": " + text)
+			if err != nil {
+				return 0, nil, fmt.Errorf("mutation %q: %w", spec, err)
+			}
+			return interval, SetValue(oid, typ, value), nil
+		}
+
+		var typ gosnmp.Asn1BER
+		var values []interface{}
+		for _, item := range strings.Split(text, ",") {
+			t, value, err := parseRecordValue(kind + ": " + strings.TrimSpace(item))
+			if err != nil {
+				return 0, nil, fmt.Errorf("mutation %q: %w", spec, err)
+			}
+			typ, values = t, append(values, value)
+		}
+		return interval, CycleValues(oid, typ, values...), nil
+	}
+	return 0, nil, fmt.Errorf("mutation %q: unknown action %q (inc, set or cycle)", spec, fields[1])
+}
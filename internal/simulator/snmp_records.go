@@ -0,0 +1,415 @@
+package simulator
+
+import (
+	"bufio"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+//go:embed fixtures/*.snmpwalk
+var fixtures embed.FS
+
+// fixtureFiles maps the firmware profiles of config.OIDProfiles to their record files
+var fixtureFiles = map[config.FirmwareVersion]string{
+	config.FirmwareV21: "fixtures/c320-v2.1.snmpwalk",
+	config.FirmwareV22: "fixtures/c320-v2.2.snmpwalk",
+}
+
+// Record is one object served by the SNMP agent, like a line of snmpwalk output.
+// Values use the Go types gosnmp decodes them to: int for INTEGER, []byte for STRING and Hex-STRING,
+// uint32 for Counter32, Gauge32 and Timeticks, uint64 for Counter64 and string for OID and IpAddress.
+type Record struct {
+	OID   string // Numeric OID with a leading dot
+	Type  gosnmp.Asn1BER
+	Value interface{}
+}
+
+// recordEntry is a Record with its parsed OID, for ordering
+type recordEntry struct {
+	Record
+	parsed []uint32
+}
+
+// Records is the ordered set of objects served by an SNMP agent. It is safe for concurrent use,
+// so values can be changed while the agent serves requests.
+type Records struct {
+	mu      sync.RWMutex
+	entries []*recordEntry // Sorted by OID
+	byOID   map[string]*recordEntry
+}
+
+// NewRecords creates an empty record set
+func NewRecords() *Records {
+	return &Records{byOID: make(map[string]*recordEntry)}
+}
+
+// Fixture returns the records of a C320 with the given firmware profile: two line cards, traffic and VLAN
+// profiles and ONUs on PON 1 and 2 of board 1 and PON 1 of board 2. Every call returns a new copy.
+func Fixture(version config.FirmwareVersion) (*Records, error) {
+	name, ok := fixtureFiles[version]
+	if !ok {
+		return nil, fmt.Errorf("no SNMP fixture for firmware %q", version)
+	}
+	file, err := fixtures.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseRecords(file)
+}
+
+// LoadRecords reads snmpwalk record files; later files override OIDs of earlier ones
+func LoadRecords(paths ...string) (*Records, error) {
+	records := NewRecords()
+	for _, path := range paths {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		err = records.parse(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return records, nil
+}
+
+// ParseRecords reads records in the output format of "snmpwalk -On", e.g.
+//
+//	.1.3.6.1.2.1.1.5.0 = STRING: "OLT-1"
+//	.1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1 = INTEGER: 1
+//
+// Blank lines and lines starting with # are ignored; lines that do not start with an OID continue the
+// value of the previous record, like wrapped Hex-STRING and multi-line STRING values.
+func ParseRecords(r io.Reader) (*Records, error) {
+	records := NewRecords()
+	if err := records.parse(r); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// parse adds the records read from r
+func (r *Records) parse(reader io.Reader) error {
+	var oid, value string
+	var line, start int
+
+	flush := func() error {
+		if oid == "" {
+			return nil
+		}
+		typ, v, err := parseRecordValue(value)
+		if err != nil {
+			return fmt.Errorf("line %d: %s: %w", start, oid, err)
+		}
+		r.Set(oid, typ, v)
+		oid = ""
+		return nil
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			continue
+		case strings.HasPrefix(trimmed, ".") || strings.HasPrefix(trimmed, "iso.") || isDigit(trimmed[0]):
+			if err := flush(); err != nil {
+				return err
+			}
+			name, rest, ok := strings.Cut(trimmed, "=")
+			if !ok {
+				return fmt.Errorf("line %d: missing \"=\"", line)
+			}
+			oid, value, start = normalizeOID(strings.TrimSpace(name)), strings.TrimSpace(rest), line
+			if _, err := parseOID(oid); err != nil {
+				return fmt.Errorf("line %d: %w", line, err)
+			}
+		case oid != "":
+			value += "\n" + text
+		default:
+			return fmt.Errorf("line %d: expected an OID", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// parseRecordValue parses the part of a snmpwalk line after "="
+func parseRecordValue(text string) (gosnmp.Asn1BER, interface{}, error) {
+	if text == `""` {
+		return gosnmp.OctetString, []byte{}, nil
+	}
+	kind, value, ok := strings.Cut(text, ":")
+	if !ok {
+		return 0, nil, fmt.Errorf("missing value type in %q", text)
+	}
+	value = strings.TrimSpace(value)
+
+	switch kind {
+	case "STRING":
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			return gosnmp.OctetString, []byte(unquoted), nil
+		}
+		return gosnmp.OctetString, []byte(strings.Trim(value, `"`)), nil
+	case "Hex-STRING":
+		b, err := hex.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid Hex-STRING: %w", err)
+		}
+		return gosnmp.OctetString, b, nil
+	case "INTEGER":
+		// Enumerations are printed as name(value)
+		if open := strings.LastIndex(value, "("); open >= 0 && strings.HasSuffix(value, ")") {
+			value = value[open+1 : len(value)-1]
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid INTEGER: %w", err)
+		}
+		return gosnmp.Integer, n, nil
+	case "Counter32", "Gauge32", "Unsigned32", "Timeticks":
+		// Timeticks are printed as (ticks) d:hh:mm:ss.cc
+		if ticks, _, ok := strings.Cut(strings.TrimPrefix(value, "("), ")"); ok {
+			value = ticks
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid %s: %w", kind, err)
+		}
+		typ := map[string]gosnmp.Asn1BER{
+			"Counter32":  gosnmp.Counter32,
+			"Gauge32":    gosnmp.Gauge32,
+			"Unsigned32": gosnmp.Gauge32,
+			"Timeticks":  gosnmp.TimeTicks,
+		}[kind]
+		return typ, uint32(n), nil
+	case "Counter64":
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid Counter64: %w", err)
+		}
+		return gosnmp.Counter64, n, nil
+	case "OID":
+		oid := normalizeOID(value)
+		if _, err := parseOID(oid); err != nil {
+			return 0, nil, err
+		}
+		return gosnmp.ObjectIdentifier, oid, nil
+	case "IpAddress":
+		return gosnmp.IPAddress, value, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported value type %q", kind)
+}
+
+// String formats the record like a line of "snmpwalk -On" output
+func (rec Record) String() string {
+	var value string
+	switch rec.Type {
+	case gosnmp.OctetString:
+		b, _ := rec.Value.([]byte)
+		if isPrintable(b) {
+			value = "STRING: " + strconv.Quote(string(b))
+		} else {
+			value = "Hex-STRING: " + strings.ToUpper(strings.TrimSpace(fmt.Sprintf("% x", b)))
+		}
+	case gosnmp.Integer:
+		value = fmt.Sprintf("INTEGER: %v", rec.Value)
+	case gosnmp.Counter32:
+		value = fmt.Sprintf("Counter32: %v", rec.Value)
+	case gosnmp.Gauge32:
+		value = fmt.Sprintf("Gauge32: %v", rec.Value)
+	case gosnmp.TimeTicks:
+		value = fmt.Sprintf("Timeticks: (%v)", rec.Value)
+	case gosnmp.Counter64:
+		value = fmt.Sprintf("Counter64: %v", rec.Value)
+	case gosnmp.ObjectIdentifier:
+		value = fmt.Sprintf("OID: %v", rec.Value)
+	case gosnmp.IPAddress:
+		value = fmt.Sprintf("IpAddress: %v", rec.Value)
+	default:
+		value = fmt.Sprintf("%v: %v", rec.Type, rec.Value)
+	}
+	return rec.OID + " = " + value
+}
+
+// Set adds or replaces the value of oid
+func (r *Records) Set(oid string, typ gosnmp.Asn1BER, value interface{}) {
+	oid = normalizeOID(oid)
+	parsed, err := parseOID(oid)
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entry, ok := r.byOID[oid]; ok {
+		entry.Type, entry.Value = typ, value
+		return
+	}
+	entry := &recordEntry{Record: Record{OID: oid, Type: typ, Value: value}, parsed: parsed}
+	i := sort.Search(len(r.entries), func(i int) bool { return compareOIDs(r.entries[i].parsed, parsed) > 0 })
+	r.entries = append(r.entries, nil)
+	copy(r.entries[i+1:], r.entries[i:])
+	r.entries[i] = entry
+	r.byOID[oid] = entry
+}
+
+// Get returns the record of oid
+func (r *Records) Get(oid string) (Record, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, ok := r.byOID[normalizeOID(oid)]
+	if !ok {
+		return Record{}, false
+	}
+	return entry.Record, true
+}
+
+// Next returns the first record after oid in lexicographic OID order, as answered to GetNext
+func (r *Records) Next(oid string) (Record, bool) {
+	parsed, err := parseOID(normalizeOID(oid))
+	if err != nil {
+		return Record{}, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	i := sort.Search(len(r.entries), func(i int) bool { return compareOIDs(r.entries[i].parsed, parsed) > 0 })
+	if i == len(r.entries) {
+		return Record{}, false
+	}
+	return r.entries[i].Record, true
+}
+
+// Delete removes oid and every record below it and returns how many were removed
+func (r *Records) Delete(oid string) int {
+	oid = normalizeOID(oid)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.entries[:0]
+	for _, entry := range r.entries {
+		if underOID(entry.OID, oid) {
+			delete(r.byOID, entry.OID)
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	removed := len(r.entries) - len(kept)
+	r.entries = kept
+	return removed
+}
+
+// Update calls fn for oid and every record below it, in order, and stores the changed type and value.
+// It returns how many records were visited.
+func (r *Records) Update(oid string, fn func(rec *Record)) int {
+	oid = normalizeOID(oid)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, entry := range r.entries {
+		if !underOID(entry.OID, oid) {
+			continue
+		}
+		rec := entry.Record
+		fn(&rec)
+		entry.Type, entry.Value = rec.Type, rec.Value
+		count++
+	}
+	return count
+}
+
+// All returns every record in OID order
+func (r *Records) All() []Record {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	all := make([]Record, len(r.entries))
+	for i, entry := range r.entries {
+		all[i] = entry.Record
+	}
+	return all
+}
+
+// Len returns the number of records
+func (r *Records) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.entries)
+}
+
+// normalizeOID returns oid in numeric form with a leading dot
+func normalizeOID(oid string) string {
+	oid = strings.TrimSpace(oid)
+	if strings.HasPrefix(oid, "iso.") {
+		oid = "1" + oid[len("iso"):]
+	}
+	if !strings.HasPrefix(oid, ".") {
+		oid = "." + oid
+	}
+	return oid
+}
+
+// parseOID splits a normalized OID into its sub-identifiers
+func parseOID(oid string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimPrefix(oid, "."), ".")
+	parsed := make([]uint32, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid OID %q", oid)
+		}
+		parsed[i] = uint32(n)
+	}
+	return parsed, nil
+}
+
+// compareOIDs orders OIDs lexicographically by sub-identifier
+func compareOIDs(a, b []uint32) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+// underOID reports whether oid is root or below it
+func underOID(oid, root string) bool {
+	return oid == root || strings.HasPrefix(oid, root+".")
+}
+
+// isPrintable reports whether b is printable ASCII text
+func isPrintable(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigit reports whether c is an ASCII digit
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
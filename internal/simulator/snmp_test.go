@@ -0,0 +1,411 @@
+package simulator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/pkg/snmp"
+)
+
+const testRecords = `# comment
+.1.3.6.1.2.1.1.1.0 = STRING: "ZXA10 C320"
+iso.3.6.1.2.1.1.3.0 = Timeticks: (4200) 0:00:42.00
+.1.3.6.1.2.1.1.5.0 = STRING: "multi
+line"
+.1.3.6.1.4.1.3902.1012.3.13.3.1.2.268501248.1 = Hex-STRING: 5A 54 45 47 C8 F1 00 01
+.1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.10 = STRING: "ten"
+.1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.2 = STRING: "two"
+.1.3.6.1.4.1.3902.1012.3.31.4.1.3.268501248.1 = Counter64: 18446744073709551615
+.1.3.6.1.4.1.3902.1012.3.31.4.1.4.268501248.1 = Counter32: 4294967295
+.1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1 = INTEGER: online(1)
+.1.3.6.1.4.1.3902.1012.3.50.16.1.1.10.268501248.1.1 = IpAddress: 10.10.1.11
+.1.3.6.1.4.1.3902.1012.3.50.16.1.1.11.268501248.1.1 = OID: .1.3.6.1.4.1.3902
+.1.3.6.1.4.1.3902.1012.3.50.16.1.1.12.268501248.1.1 = ""
+`
+
+// startAgent serves records with cfg on a random local port
+func startAgent(t *testing.T, cfg SNMPConfig, records *Records) *SNMPAgent {
+	t.Helper()
+
+	agent := NewSNMPAgent(cfg, records)
+	if err := agent.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { agent.Close() })
+	return agent
+}
+
+// connect opens a client for cfg, as the API does
+func connect(t *testing.T, cfg config.SnmpConfig) *gosnmp.GoSNMP {
+	t.Helper()
+
+	client, err := snmp.ConnectSnmp(cfg)
+	if err != nil {
+		t.Fatalf("ConnectSnmp() error = %v", err)
+	}
+	// Requests that the agent ignores fail fast
+	client.Timeout, client.Retries = 500*time.Millisecond, 0
+	t.Cleanup(func() { client.Conn.Close() })
+	return client
+}
+
+func parseTestRecords(t *testing.T) *Records {
+	t.Helper()
+
+	records, err := ParseRecords(strings.NewReader(testRecords))
+	if err != nil {
+		t.Fatalf("ParseRecords() error = %v", err)
+	}
+	return records
+}
+
+func TestParseRecords(t *testing.T) {
+	records := parseTestRecords(t)
+	if records.Len() != 12 {
+		t.Fatalf("Len() = %d, want 12", records.Len())
+	}
+
+	tests := []struct {
+		oid   string
+		typ   gosnmp.Asn1BER
+		value interface{}
+	}{
+		{".1.3.6.1.2.1.1.1.0", gosnmp.OctetString, "ZXA10 C320"},
+		{".1.3.6.1.2.1.1.3.0", gosnmp.TimeTicks, uint32(4200)},
+		{".1.3.6.1.2.1.1.5.0", gosnmp.OctetString, "multi\nline"},
+		{".1.3.6.1.4.1.3902.1012.3.13.3.1.2.268501248.1", gosnmp.OctetString, "ZTEG\xc8\xf1\x00\x01"},
+		{".1.3.6.1.4.1.3902.1012.3.31.4.1.3.268501248.1", gosnmp.Counter64, uint64(18446744073709551615)},
+		{".1.3.6.1.4.1.3902.1012.3.31.4.1.4.268501248.1", gosnmp.Counter32, uint32(4294967295)},
+		{".1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1", gosnmp.Integer, 1},
+		{".1.3.6.1.4.1.3902.1012.3.50.16.1.1.10.268501248.1.1", gosnmp.IPAddress, "10.10.1.11"},
+		{".1.3.6.1.4.1.3902.1012.3.50.16.1.1.11.268501248.1.1", gosnmp.ObjectIdentifier, ".1.3.6.1.4.1.3902"},
+		{".1.3.6.1.4.1.3902.1012.3.50.16.1.1.12.268501248.1.1", gosnmp.OctetString, ""},
+	}
+	for _, tt := range tests {
+		rec, ok := records.Get(tt.oid)
+		if !ok {
+			t.Errorf("Get(%s) not found", tt.oid)
+			continue
+		}
+		value := rec.Value
+		if b, isBytes := value.([]byte); isBytes {
+			value = string(b)
+		}
+		if rec.Type != tt.typ || value != tt.value {
+			t.Errorf("Get(%s) = %v %#v, want %v %#v", tt.oid, rec.Type, value, tt.typ, tt.value)
+		}
+	}
+
+	// Written records parse back to the same values
+	var out strings.Builder
+	for _, rec := range records.All() {
+		out.WriteString(rec.String() + "\n")
+	}
+	again, err := ParseRecords(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("ParseRecords(String()) error = %v\n%s", err, out.String())
+	}
+	if again.Len() != records.Len() {
+		t.Errorf("round trip Len() = %d, want %d", again.Len(), records.Len())
+	}
+}
+
+func TestParseRecordsErrors(t *testing.T) {
+	tests := []string{
+		".1.3.6.1.2.1.1.1.0 STRING: \"missing separator\"",
+		".1.3.6.1.x.1 = INTEGER: 1",
+		".1.3.6.1.2.1.1.1.0 = INTEGER: one",
+		".1.3.6.1.2.1.1.1.0 = Hex-STRING: 5A ZZ",
+		".1.3.6.1.2.1.1.1.0 = Opaque: 1",
+	}
+	for _, input := range tests {
+		if _, err := ParseRecords(strings.NewReader(input)); err == nil {
+			t.Errorf("ParseRecords(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestRecordsNext(t *testing.T) {
+	records := parseTestRecords(t)
+
+	// Numeric order: .2 before .10
+	tests := []struct {
+		oid  string
+		want string
+	}{
+		{".1.3.6.1.4.1.3902.1012.3.13.3.1.5", ".1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.2"},
+		{".1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.2", ".1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.10"},
+		{"1.3.6.1.2.1.1.1.0", ".1.3.6.1.2.1.1.3.0"},
+		{".1", ".1.3.6.1.2.1.1.1.0"},
+	}
+	for _, tt := range tests {
+		rec, ok := records.Next(tt.oid)
+		if !ok || rec.OID != tt.want {
+			t.Errorf("Next(%s) = %s, %v, want %s", tt.oid, rec.OID, ok, tt.want)
+		}
+	}
+	if rec, ok := records.Next(".1.3.6.1.4.1.3902.1012.3.50.16.1.1.12.268501248.1.1"); ok {
+		t.Errorf("Next(last) = %s, want end of MIB", rec.OID)
+	}
+
+	if n := records.Delete(".1.3.6.1.4.1.3902.1012.3.13.3.1.5"); n != 2 {
+		t.Errorf("Delete() = %d, want 2", n)
+	}
+	if _, ok := records.Get(".1.3.6.1.4.1.3902.1012.3.13.3.1.5.268501248.2"); ok {
+		t.Error("Get() found a deleted record")
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	for version, profile := range config.OIDProfiles {
+		t.Run(string(version), func(t *testing.T) {
+			records, err := Fixture(version)
+			if err != nil {
+				t.Fatalf("Fixture() error = %v", err)
+			}
+			if _, ok := records.Get(".1.3.6.1.2.1.1.1.0"); !ok {
+				t.Error("fixture has no sysDescr")
+			}
+
+			// The ONU columns of the profile have rows for board 1 PON 1
+			ponMap, err := config.InitializeBoardPonMapForProfile(profile)
+			if err != nil {
+				t.Fatalf("InitializeBoardPonMapForProfile() error = %v", err)
+			}
+			pon := ponMap[config.BoardPonKey{BoardID: 1, PonID: 1}]
+			columns := map[string]string{
+				"name":   profile.BaseOID + pon.OnuIDNameOID,
+				"serial": profile.BaseOID + pon.OnuSerialNumberOID,
+				"status": profile.BaseOID + pon.OnuStatusOID,
+			}
+			for name, oid := range columns {
+				rec, ok := records.Next(oid)
+				if !ok || !underOID(rec.OID, oid) {
+					t.Errorf("no %s rows below %s", name, oid)
+				}
+			}
+		})
+	}
+
+	if _, err := Fixture("v9.9"); err == nil {
+		t.Error("Fixture(v9.9) error = nil, want error")
+	}
+}
+
+func TestSNMPAgentV2c(t *testing.T) {
+	agent := startAgent(t, SNMPConfig{Community: "secret"}, parseTestRecords(t))
+	client := connect(t, agent.SnmpConfig())
+
+	result, err := client.Get([]string{".1.3.6.1.2.1.1.1.0", ".1.3.6.1.2.1.1.9.0"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := string(result.Variables[0].Value.([]byte)); got != "ZXA10 C320" {
+		t.Errorf("sysDescr = %q, want %q", got, "ZXA10 C320")
+	}
+	if result.Variables[1].Type != gosnmp.NoSuchObject {
+		t.Errorf("missing OID type = %v, want NoSuchObject", result.Variables[1].Type)
+	}
+
+	var names []string
+	err = client.Walk(".1.3.6.1.4.1.3902.1012.3.13.3.1.5", func(pdu gosnmp.SnmpPDU) error {
+		names = append(names, string(pdu.Value.([]byte)))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk() error = %v", err)
+	}
+	if strings.Join(names, ",") != "two,ten" {
+		t.Errorf("Walk() = %v, want [two ten]", names)
+	}
+
+	var count int
+	err = client.BulkWalk(".1.3.6.1.4.1.3902.1012", func(pdu gosnmp.SnmpPDU) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BulkWalk() error = %v", err)
+	}
+	if count != 9 {
+		t.Errorf("BulkWalk() returned %d values, want 9", count)
+	}
+
+	result, err = client.Get([]string{".1.3.6.1.4.1.3902.1012.3.31.4.1.3.268501248.1"})
+	if err != nil {
+		t.Fatalf("Get(Counter64) error = %v", err)
+	}
+	if got := gosnmp.ToBigInt(result.Variables[0].Value).Uint64(); got != 18446744073709551615 {
+		t.Errorf("Counter64 = %d, want max uint64", got)
+	}
+}
+
+func TestSNMPAgentWrongCommunity(t *testing.T) {
+	agent := startAgent(t, SNMPConfig{}, parseTestRecords(t))
+	cfg := agent.SnmpConfig()
+	cfg.Community = "private"
+	client := connect(t, cfg)
+
+	if _, err := client.Get([]string{".1.3.6.1.2.1.1.1.0"}); err == nil {
+		t.Error("Get() with a wrong community succeeded, want timeout")
+	}
+	if agent.Requests() != 0 {
+		t.Errorf("Requests() = %d, want 0", agent.Requests())
+	}
+}
+
+func TestSNMPAgentV3(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  SNMPConfig
+	}{
+		{"authPriv SHA/AES", SNMPConfig{User: "monitor", AuthProtocol: "SHA", AuthPassphrase: "authpass123", PrivProtocol: "AES", PrivPassphrase: "privpass123"}},
+		{"authPriv SHA256/DES", SNMPConfig{User: "monitor", AuthProtocol: "SHA256", AuthPassphrase: "authpass123", PrivProtocol: "DES", PrivPassphrase: "privpass123"}},
+		{"authNoPriv MD5", SNMPConfig{User: "monitor", AuthProtocol: "MD5", AuthPassphrase: "authpass123"}},
+		{"noAuthNoPriv", SNMPConfig{User: "monitor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent := startAgent(t, tt.cfg, parseTestRecords(t))
+			client := connect(t, agent.SnmpConfig())
+
+			result, err := client.Get([]string{".1.3.6.1.2.1.1.1.0"})
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if got := string(result.Variables[0].Value.([]byte)); got != "ZXA10 C320" {
+				t.Errorf("sysDescr = %q, want %q", got, "ZXA10 C320")
+			}
+
+			var count int
+			err = client.Walk(".1.3.6.1.4.1.3902.1012.3.13.3.1.5", func(gosnmp.SnmpPDU) error {
+				count++
+				return nil
+			})
+			if err != nil || count != 2 {
+				t.Errorf("Walk() = %d values, %v, want 2", count, err)
+			}
+		})
+	}
+}
+
+func TestSNMPAgentV3WrongPassphrase(t *testing.T) {
+	cfg := SNMPConfig{User: "monitor", AuthProtocol: "SHA", AuthPassphrase: "authpass123", PrivProtocol: "AES", PrivPassphrase: "privpass123"}
+	agent := startAgent(t, cfg, parseTestRecords(t))
+
+	tests := []struct {
+		name   string
+		modify func(c *config.SnmpConfig)
+	}{
+		{"auth", func(c *config.SnmpConfig) { c.AuthPassphrase = "wrongpass123" }},
+		{"priv", func(c *config.SnmpConfig) { c.PrivPassphrase = "wrongpass123" }},
+		{"user", func(c *config.SnmpConfig) { c.User = "admin" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientCfg := agent.SnmpConfig()
+			tt.modify(&clientCfg)
+			client := connect(t, clientCfg)
+			if _, err := client.Get([]string{".1.3.6.1.2.1.1.1.0"}); err == nil {
+				t.Error("Get() succeeded with wrong credentials")
+			}
+		})
+	}
+}
+
+func TestSNMPAgentInvalidUser(t *testing.T) {
+	agent := NewSNMPAgent(SNMPConfig{User: "monitor", AuthProtocol: "SHA", AuthPassphrase: "short"}, nil)
+	if err := agent.Listen("127.0.0.1:0"); err == nil {
+		agent.Close()
+		t.Error("Listen() error = nil, want invalid passphrase error")
+	}
+}
+
+func TestMutations(t *testing.T) {
+	records := parseTestRecords(t)
+	status := ".1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1"
+
+	cycle := CycleValues(status, gosnmp.Integer, 2, 1)
+	for _, want := range []int{2, 1, 2} {
+		cycle(records)
+		if rec, _ := records.Get(status); rec.Value != want {
+			t.Errorf("CycleValues() status = %v, want %d", rec.Value, want)
+		}
+	}
+
+	IncrementCounters(".1.3.6.1.4.1.3902.1012.3.31.4.1", 2)(records)
+	if rec, _ := records.Get(".1.3.6.1.4.1.3902.1012.3.31.4.1.4.268501248.1"); rec.Value != uint32(1) {
+		t.Errorf("Counter32 after increment = %v, want wrap to 1", rec.Value)
+	}
+	if rec, _ := records.Get(".1.3.6.1.4.1.3902.1012.3.31.4.1.3.268501248.1"); rec.Value != uint64(1) {
+		t.Errorf("Counter64 after increment = %v, want wrap to 1", rec.Value)
+	}
+	if rec, _ := records.Get(status); rec.Value != 2 {
+		t.Errorf("IncrementCounters() changed an INTEGER to %v", rec.Value)
+	}
+
+	SetValue(".1.3.6.1.2.1.1.5.0", gosnmp.OctetString, "renamed")(records)
+	if rec, _ := records.Get(".1.3.6.1.2.1.1.5.0"); rec.Value != "renamed" {
+		t.Errorf("SetValue() = %v, want renamed", rec.Value)
+	}
+}
+
+func TestSNMPAgentEvery(t *testing.T) {
+	agent := startAgent(t, SNMPConfig{}, parseTestRecords(t))
+	agent.Every(10*time.Millisecond, IncrementCounters(".1.3.6.1.4.1.3902.1012.3.31.4.1.4", 1))
+
+	oid := ".1.3.6.1.4.1.3902.1012.3.31.4.1.4.268501248.1"
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if rec, _ := agent.Records().Get(oid); rec.Value.(uint32) < 1000 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("counter was not incremented")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := agent.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestParseMutation(t *testing.T) {
+	tests := []struct {
+		spec     string
+		interval time.Duration
+		wantErr  bool
+	}{
+		{"30s inc .1.3.6.1.4.1.3902.1012.3.31.4.1 1500", 30 * time.Second, false},
+		{"1m cycle .1.3.6.1.4.1.3902.1012.3.31.4.1.100.268501248.1 INTEGER 1,2", time.Minute, false},
+		{"5m set .1.3.6.1.2.1.1.5.0 STRING \"OLT 2\"", 5 * time.Minute, false},
+		{"30s inc .1.3.6.1", 0, true},
+		{"soon inc .1.3.6.1 1", 0, true},
+		{"-1s inc .1.3.6.1 1", 0, true},
+		{"30s inc .1.3.x 1", 0, true},
+		{"30s inc .1.3.6.1 many", 0, true},
+		{"30s set .1.3.6.1 INTEGER", 0, true},
+		{"30s set .1.3.6.1 INTEGER one", 0, true},
+		{"30s remove .1.3.6.1 INTEGER 1", 0, true},
+	}
+	for _, tt := range tests {
+		interval, mutation, err := ParseMutation(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMutation(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (interval != tt.interval || mutation == nil) {
+			t.Errorf("ParseMutation(%q) = %v, %v, want %v", tt.spec, interval, mutation != nil, tt.interval)
+		}
+	}
+
+	records := parseTestRecords(t)
+	_, mutation, _ := ParseMutation("5m set .1.3.6.1.2.1.1.5.0 STRING \"OLT 2\"")
+	mutation(records)
+	if rec, _ := records.Get(".1.3.6.1.2.1.1.5.0"); string(rec.Value.([]byte)) != "OLT 2" {
+		t.Errorf("set mutation = %q, want \"OLT 2\"", rec.Value)
+	}
+}
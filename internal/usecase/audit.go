@@ -0,0 +1,415 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// Maximum number of entries returned by ListEntries
+const maxAuditLimit = 1000
+
+// truncatedOutputMarker ends CLI output cut to fit the entry in the audit log
+const truncatedOutputMarker = "\n[output truncated, %d bytes removed]"
+
+// hashPlaceholder stands for the hash, which is not set yet when the size of an entry is checked
+const hashPlaceholder = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditUsecase keeps the tamper-evident audit log of the operations that change an OLT
+type AuditUsecase interface {
+	// Record appends an entry to the log, setting its sequence number and hash chain fields
+	Record(ctx context.Context, entry *model.AuditEntry) error
+
+	// ListEntries returns the entries matching the query, newest first
+	ListEntries(ctx context.Context, query model.AuditQuery) ([]*model.AuditEntry, error)
+
+	// Verify checks the hash chain of the whole log
+	Verify(ctx context.Context) (*model.AuditVerification, error)
+}
+
+type auditUsecase struct {
+	store repository.AuditStore
+	key   []byte // Key of the HMAC chaining new entries; nil chains them with plain SHA-256
+
+	mu           sync.Mutex // Serializes Record, so every entry links to the one before it
+	loaded       bool       // lastSequence and lastHash were read from the store
+	lastSequence int64
+	lastHash     string
+
+	verifyMu sync.Mutex                 // Serializes Verify
+	verified map[string]verifiedSegment // Rotated segments that verified, by path
+}
+
+// verifiedSegment is a rotated segment of the log that verified. It is not read again by Verify while
+// its size and modification time stay the same; only its link to the segment before it is checked.
+type verifiedSegment struct {
+	size          int64
+	modTime       time.Time
+	firstSequence int64
+	firstPrevHash string
+	entries       int64
+	lastSequence  int64
+	lastHash      string
+}
+
+// NewAuditUsecase creates the audit usecase on a store; the end of the chain is read on the first Record.
+// New entries are hashed with the HMAC-SHA256 of key, or with plain SHA-256 if key is nil.
+func NewAuditUsecase(store repository.AuditStore, key []byte) AuditUsecase {
+	return &auditUsecase{store: store, key: key, verified: make(map[string]verifiedSegment)}
+}
+
+// Record chains the entry to the last one and appends it
+func (u *auditUsecase) Record(ctx context.Context, entry *model.AuditEntry) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if !u.loaded {
+		if err := u.loadLast(ctx); err != nil {
+			return err
+		}
+	}
+
+	entry.Sequence = u.lastSequence + 1
+	entry.Timestamp = entry.Timestamp.UTC()
+	entry.PrevHash = u.lastHash
+	entry.HashAlg = ""
+	if u.key != nil {
+		entry.HashAlg = model.AuditHashHMAC
+	}
+	if err := normalizeAuditEntry(entry); err != nil {
+		return err
+	}
+	if err := truncateAuditOutput(entry); err != nil {
+		return err
+	}
+	hash, err := auditHash(entry, u.key)
+	if err != nil {
+		return err
+	}
+	entry.Hash = hash
+
+	if err := u.store.Append(ctx, entry); err != nil {
+		return err
+	}
+	u.lastSequence, u.lastHash = entry.Sequence, entry.Hash
+	return nil
+}
+
+// loadLast reads the sequence and hash of the last entry from the end of the log. A log whose end is
+// corrupt is continued after its last readable entry; Verify reports the damage.
+func (u *auditUsecase) loadLast(ctx context.Context) error {
+	var last *model.AuditEntry
+	err := u.store.ScanReverse(ctx, func(entry *model.AuditEntry) error {
+		last = entry
+		return errAuditScanDone
+	})
+	if errors.Is(err, repository.ErrCorruptAuditLog) && last == nil {
+		err = u.store.Scan(ctx, func(entry *model.AuditEntry) error {
+			last = entry
+			return nil
+		})
+	}
+	if err != nil && !errors.Is(err, errAuditScanDone) && !errors.Is(err, repository.ErrCorruptAuditLog) {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if errors.Is(err, repository.ErrCorruptAuditLog) {
+		log.Error().Err(err).Msg("Audit log is damaged, continuing after its last readable entry")
+	}
+
+	if last != nil {
+		u.lastSequence, u.lastHash = last.Sequence, last.Hash
+		if u.key != nil && last.HashAlg != model.AuditHashHMAC {
+			log.Error().Msg("Audit log was written without AUDIT_HMAC_KEY and fails verification with it: move it aside to start a keyed log")
+		}
+	}
+	u.loaded = true
+	return nil
+}
+
+// errAuditScanDone stops a scan of the log that found what it was looking for
+var errAuditScanDone = errors.New("audit scan done")
+
+// normalizeAuditEntry round-trips the entry through JSON, so its hash does not change once it is
+// stored and read back (invalid UTF-8 in OLT output becomes U+FFFD, request bodies are compacted)
+func normalizeAuditEntry(entry *model.AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+	*entry = model.AuditEntry{}
+	return json.Unmarshal(data, entry)
+}
+
+// truncateAuditOutput shortens the CLI output of the entry until it fits in the audit log, keeping the
+// start of every output and marking the cut. Outputs are cut at rune boundaries, so the entry stays
+// normalized and its hash matches the stored line.
+func truncateAuditOutput(entry *model.AuditEntry) error {
+	original := make([]string, len(entry.Commands))
+	longest := 0
+	for i, command := range entry.Commands {
+		original[i] = command.Output
+		longest = max(longest, len(command.Output))
+	}
+
+	for limit := longest; ; limit /= 2 {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit entry: %w", err)
+		}
+		excess := len(data) + 1 + len(hashPlaceholder) - repository.MaxAuditEntrySize
+		if excess <= 0 || limit == 0 {
+			return nil // An entry that is still too large is rejected by the store
+		}
+		limit = max(min(limit, longest-excess), 0)
+
+		for i, output := range original {
+			if len(output) > limit {
+				cut := limit
+				for cut > 0 && !utf8.RuneStart(output[cut]) {
+					cut--
+				}
+				entry.Commands[i].Output = output[:cut] + fmt.Sprintf(truncatedOutputMarker, len(output)-cut)
+			}
+		}
+	}
+}
+
+// auditHash returns the hex hash of the JSON of the entry with an empty Hash field: the HMAC-SHA256
+// with key for keyed entries, the SHA-256 for the others
+func auditHash(entry *model.AuditEntry, key []byte) (string, error) {
+	unhashed := *entry
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	switch entry.HashAlg {
+	case "":
+		sum := sha256.Sum256(data)
+		return hex.EncodeToString(sum[:]), nil
+	case model.AuditHashHMAC:
+		if key == nil {
+			return "", errAuditKeyMissing
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write(data)
+		return hex.EncodeToString(mac.Sum(nil)), nil
+	default:
+		return "", fmt.Errorf("unknown audit hash algorithm %q", entry.HashAlg)
+	}
+}
+
+// errAuditKeyMissing is returned for keyed entries when no key is configured
+var errAuditKeyMissing = errors.New("entry is keyed, but AUDIT_HMAC_KEY is not set")
+
+// ListEntries reads the log backwards and returns the newest entries matching the query
+func (u *auditUsecase) ListEntries(ctx context.Context, query model.AuditQuery) ([]*model.AuditEntry, error) {
+	if query.Limit < 0 || query.Limit > maxAuditLimit {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit), map[string]interface{}{
+			"limit": query.Limit,
+		})
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return nil, apperrors.NewValidationError("since must be before until", nil)
+	}
+
+	// The log is read from its end until enough entries match
+	result := make([]*model.AuditEntry, 0, query.Limit)
+	err := u.store.ScanReverse(ctx, func(entry *model.AuditEntry) error {
+		if !matchesAuditQuery(entry, &query) {
+			return nil
+		}
+		result = append(result, entry)
+		if len(result) == query.Limit {
+			return errAuditScanDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errAuditScanDone) && !errors.Is(err, repository.ErrCorruptAuditLog) {
+		return nil, apperrors.NewInternalError("failed to read audit log", err)
+	}
+	if errors.Is(err, repository.ErrCorruptAuditLog) {
+		log.Error().Err(err).Msg("Audit log is damaged, listing the entries after the damage")
+	}
+	return result, nil
+}
+
+// matchesAuditQuery reports whether an entry passes all filters of the query
+func matchesAuditQuery(entry *model.AuditEntry, query *model.AuditQuery) bool {
+	switch {
+	case query.OLTID != "" && entry.OLTID != query.OLTID:
+		return false
+	case query.User != "" && entry.User != query.User:
+		return false
+	case query.Operation != "" && entry.Operation != query.Operation && !strings.HasPrefix(entry.Operation, query.Operation+"."):
+		return false
+	case !query.Since.IsZero() && entry.Timestamp.Before(query.Since):
+		return false
+	case !query.Until.IsZero() && !entry.Timestamp.Before(query.Until):
+		return false
+	}
+
+	if query.Board == 0 && query.PON == 0 && query.ONUID == 0 {
+		return true
+	}
+	for _, target := range entry.Targets {
+		if (query.Board == 0 || target.Board == query.Board) &&
+			(query.PON == 0 || target.PON == query.PON) &&
+			(query.ONUID == 0 || target.ONUID == query.ONUID) {
+			return true
+		}
+	}
+	return false
+}
+
+// errChainBroken ends the scan of Verify at the first bad entry
+var errChainBroken = errors.New("audit chain broken")
+
+// Verify checks that the sequence numbers are consecutive, every entry matches its hash and links
+// to the hash of its predecessor, and the log still contains the last entry written by this process.
+// With a key every entry must be keyed: an unkeyed log could have been rewritten by anyone.
+// Rotated segments that verified before and did not change since are not read again.
+func (u *auditUsecase) Verify(ctx context.Context) (*model.AuditVerification, error) {
+	u.verifyMu.Lock()
+	defer u.verifyMu.Unlock()
+
+	// Entries written from now on may be missing from the segments listed
+	u.mu.Lock()
+	v := &auditVerifier{
+		usecase:     u,
+		result:      &model.AuditVerification{Valid: true},
+		written:     u.lastSequence,
+		writtenHash: u.lastHash,
+		verified:    make(map[string]verifiedSegment),
+	}
+	u.mu.Unlock()
+
+	segments, err := u.store.Segments()
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to read audit log", err)
+	}
+	for _, segment := range segments {
+		if err = v.verifySegment(ctx, segment); err != nil {
+			break
+		}
+	}
+	u.verified = v.verified
+
+	result := v.result
+	switch {
+	case errors.Is(err, errChainBroken):
+		return result, nil
+	case errors.Is(err, repository.ErrCorruptAuditLog):
+		_ = v.broken(result.LastSequence+1, err.Error())
+		return result, nil
+	case err != nil:
+		return nil, apperrors.NewInternalError("failed to read audit log", err)
+	}
+
+	// Entries removed from the end, or a rewritten chain, still verify: compare with what this process wrote
+	switch {
+	case v.written > result.LastSequence:
+		_ = v.broken(result.LastSequence+1, fmt.Sprintf("log ends at entry %d, but entry %d was written", result.LastSequence, v.written))
+	case v.written > 0 && v.seenHash != v.writtenHash:
+		_ = v.broken(v.written, "entry differs from the entry written")
+	}
+	return result, nil
+}
+
+// auditVerifier carries the state of one Verify through the segments of the log
+type auditVerifier struct {
+	usecase     *auditUsecase
+	result      *model.AuditVerification
+	written     int64                      // Sequence of the last entry written by this process
+	writtenHash string                     // Hash of that entry
+	seenHash    string                     // Hash of that entry in the log
+	verified    map[string]verifiedSegment // Rotated segments that verified in this run
+}
+
+// broken marks the chain as broken at sequence and ends the scan
+func (v *auditVerifier) broken(sequence int64, reason string) error {
+	v.result.Valid, v.result.BrokenAt, v.result.Reason = false, sequence, reason
+	return errChainBroken
+}
+
+// check verifies the next entry of the log
+func (v *auditVerifier) check(entry *model.AuditEntry) error {
+	result, key := v.result, v.usecase.key
+	expected := result.LastSequence + 1
+	switch {
+	case entry.Sequence != expected:
+		return v.broken(expected, fmt.Sprintf("expected entry %d, found entry %d", expected, entry.Sequence))
+	case entry.PrevHash != result.LastHash:
+		return v.broken(entry.Sequence, "previous hash does not match the preceding entry")
+	case key != nil && entry.HashAlg != model.AuditHashHMAC:
+		return v.broken(entry.Sequence, "entry is not keyed with AUDIT_HMAC_KEY")
+	}
+	hash, err := auditHash(entry, key)
+	if err != nil {
+		return v.broken(entry.Sequence, err.Error())
+	}
+	if !hmac.Equal([]byte(hash), []byte(entry.Hash)) {
+		return v.broken(entry.Sequence, "entry does not match its hash")
+	}
+
+	result.Entries++
+	result.LastSequence, result.LastHash = entry.Sequence, entry.Hash
+	if entry.Sequence == v.written {
+		v.seenHash = entry.Hash
+	}
+	return nil
+}
+
+// verifySegment checks the entries of a segment, or only its link to the previous segment if it is a
+// rotated segment that verified before and did not change. The last entry written is always in a
+// segment that is read: it is only followed by a rotation when a later entry is written.
+func (v *auditVerifier) verifySegment(ctx context.Context, segment repository.AuditSegment) error {
+	result := v.result
+	previous, ok := v.usecase.verified[segment.Path]
+	if ok && !segment.Active && previous.size == segment.Size && previous.modTime.Equal(segment.ModTime) &&
+		previous.firstSequence == result.LastSequence+1 && previous.firstPrevHash == result.LastHash {
+		result.Entries += previous.entries
+		result.LastSequence, result.LastHash = previous.lastSequence, previous.lastHash
+		if previous.lastSequence == v.written {
+			v.seenHash = previous.lastHash
+		}
+		v.verified[segment.Path] = previous
+		return nil
+	}
+
+	// A segment that no longer links to its predecessor is read to report the entry that breaks the chain
+	current := verifiedSegment{size: segment.Size, modTime: segment.ModTime}
+	entries := result.Entries
+	err := v.usecase.store.ScanSegment(ctx, segment, func(entry *model.AuditEntry) error {
+		if result.Entries == entries {
+			current.firstSequence, current.firstPrevHash = entry.Sequence, entry.PrevHash
+		}
+		return v.check(entry)
+	})
+	if err != nil {
+		return err
+	}
+	if !segment.Active && result.Entries > entries {
+		current.entries = result.Entries - entries
+		current.lastSequence, current.lastHash = result.LastSequence, result.LastHash
+		v.verified[segment.Path] = current
+	}
+	return nil
+}
@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// testAuditKey is the HMAC key of the test logs
+var testAuditKey = bytes.Repeat([]byte{0x5a}, 32)
+
+// newTestAuditUsecase returns an audit usecase with testAuditKey on a log file in a temporary directory
+func newTestAuditUsecase(t *testing.T) (AuditUsecase, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	store, err := repository.NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatalf("NewFileAuditStore() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return NewAuditUsecase(store, testAuditKey), path
+}
+
+// recordTestEntries records a block by alice, a VLAN change by bob and a batch delete by alice
+func recordTestEntries(t *testing.T, u AuditUsecase) time.Time {
+	t.Helper()
+	start := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	entries := []*model.AuditEntry{
+		{User: "alice", Operation: "onu.block", Targets: []model.AuditTarget{{Board: 1, PON: 1, ONUID: 2}},
+			Commands: []model.CLICommand{{Command: "onu 2 state disable", Mode: model.CLIModeConfig, Output: "\xff\xfe", Success: true}}},
+		{User: "bob", Operation: "vlan.configure", Targets: []model.AuditTarget{{Board: 1, PON: 3, ONUID: 7}},
+			Request: []byte(`{ "pon_port": "1/1/3", "onu_id": 7, "name": "<svc>" }`)},
+		{User: "alice", Operation: "batch.delete", Targets: []model.AuditTarget{{Board: 1, PON: 1, ONUID: 4}, {Board: 2, PON: 1, ONUID: 2}}},
+	}
+	for i, entry := range entries {
+		entry.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		entry.Result = model.AuditResultSuccess
+		if err := u.Record(context.Background(), entry); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	return start
+}
+
+func TestAuditUsecase_RecordChainsEntries(t *testing.T) {
+	u, path := newTestAuditUsecase(t)
+	recordTestEntries(t, u)
+
+	entries, err := u.ListEntries(context.Background(), model.AuditQuery{})
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("ListEntries() = %d entries, %v", len(entries), err)
+	}
+	if entries[0].Sequence != 3 || entries[2].Sequence != 1 {
+		t.Errorf("sequences = %d..%d, want newest first", entries[0].Sequence, entries[2].Sequence)
+	}
+	if entries[2].PrevHash != "" || entries[1].PrevHash != entries[2].Hash || entries[0].PrevHash != entries[1].Hash {
+		t.Error("entries are not chained by hash")
+	}
+
+	// A restarted process continues the chain
+	store, err := repository.NewFileAuditStore(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	restarted := NewAuditUsecase(store, testAuditKey)
+	next := &model.AuditEntry{User: "carol", Operation: "onu.reboot", Timestamp: time.Now()}
+	if err := restarted.Record(context.Background(), next); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if next.Sequence != 4 || next.PrevHash != entries[0].Hash {
+		t.Errorf("Record() after restart = sequence %d, prev %q", next.Sequence, next.PrevHash)
+	}
+
+	result, err := restarted.Verify(context.Background())
+	if err != nil || !result.Valid || result.Entries != 4 || result.LastHash != next.Hash {
+		t.Errorf("Verify() = %+v, %v, want 4 valid entries", result, err)
+	}
+}
+
+func TestAuditUsecase_RecordTruncatesOutput(t *testing.T) {
+	u, path := newTestAuditUsecase(t)
+	output := strings.Repeat("é", 5<<20) // 10 MiB of two-byte runes per command
+	entry := &model.AuditEntry{User: "alice", Operation: "config.restore", Timestamp: time.Now(), Commands: []model.CLICommand{
+		{Command: "show running-config", Mode: model.CLIModeExec, Output: output, Success: true},
+		{Command: "write", Mode: model.CLIModeExec, Output: "OK", Success: true},
+		{Command: "show running-config", Mode: model.CLIModeExec, Output: output, Success: true},
+	}}
+	if err := u.Record(context.Background(), entry); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	for _, i := range []int{0, 2} {
+		got := entry.Commands[i].Output
+		if !strings.HasPrefix(got, "éé") || !strings.Contains(got, "[output truncated,") || !utf8.ValidString(got) {
+			t.Errorf("output %d = %d bytes, want the start of the output and a marker", i, len(got))
+		}
+	}
+	if entry.Commands[1].Output != "OK" {
+		t.Errorf("output 1 = %q, want short outputs unchanged", entry.Commands[1].Output)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > repository.MaxAuditEntrySize || info.Size() < repository.MaxAuditEntrySize/2 {
+		t.Errorf("log size = %d, want close to the limit of %d", info.Size(), repository.MaxAuditEntrySize)
+	}
+	if result, err := u.Verify(context.Background()); err != nil || !result.Valid || result.Entries != 1 {
+		t.Errorf("Verify() = %+v, %v, want a valid entry", result, err)
+	}
+}
+
+func TestAuditUsecase_ListEntriesFilters(t *testing.T) {
+	u, _ := newTestAuditUsecase(t)
+	start := recordTestEntries(t, u)
+
+	tests := []struct {
+		name  string
+		query model.AuditQuery
+		want  []string // Operations, newest first
+	}{
+		{"user", model.AuditQuery{User: "alice"}, []string{"batch.delete", "onu.block"}},
+		{"operation", model.AuditQuery{Operation: "onu.block"}, []string{"onu.block"}},
+		{"operation group", model.AuditQuery{Operation: "batch"}, []string{"batch.delete"}},
+		{"operation prefix is not a group", model.AuditQuery{Operation: "onu.b"}, nil},
+		{"ONU", model.AuditQuery{PON: 1, ONUID: 2}, []string{"batch.delete", "onu.block"}},
+		{"ONU on board", model.AuditQuery{Board: 2, PON: 1, ONUID: 2}, []string{"batch.delete"}},
+		{"time range", model.AuditQuery{Since: start.Add(time.Hour), Until: start.Add(2 * time.Hour)}, []string{"vlan.configure"}},
+		{"limit", model.AuditQuery{Limit: 2}, []string{"batch.delete", "vlan.configure"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := u.ListEntries(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("ListEntries() error = %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Operation)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListEntries() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := u.ListEntries(context.Background(), model.AuditQuery{Limit: maxAuditLimit + 1}); err == nil {
+		t.Error("ListEntries() accepted a limit above the maximum")
+	}
+}
+
+func TestAuditUsecase_VerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(lines []string) []string
+		brokenAt int64
+	}{
+		{"edited entry", func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"user":"bob"`, `"user":"mallory"`, 1)
+			return lines
+		}, 2},
+		{"removed entry", func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}, 2},
+		{"truncated log", func(lines []string) []string {
+			return lines[:2]
+		}, 3},
+		{"corrupt line", func(lines []string) []string {
+			lines[2] = lines[2][:20]
+			return lines
+		}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, path := newTestAuditUsecase(t)
+			recordTestEntries(t, u)
+
+			if result, err := u.Verify(context.Background()); err != nil || !result.Valid || result.Entries != 3 {
+				t.Fatalf("Verify() before tampering = %+v, %v", result, err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+			if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := u.Verify(context.Background())
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if result.Valid || result.BrokenAt != tt.brokenAt || result.Reason == "" {
+				t.Errorf("Verify() = %+v, want broken at %d", result, tt.brokenAt)
+			}
+		})
+	}
+}
+
+func TestAuditUsecase_KeyedChain(t *testing.T) {
+	u, path := newTestAuditUsecase(t)
+	recordTestEntries(t, u)
+	entries, _ := u.ListEntries(context.Background(), model.AuditQuery{})
+	if entries[0].HashAlg != model.AuditHashHMAC {
+		t.Fatalf("HashAlg = %q, want %s", entries[0].HashAlg, model.AuditHashHMAC)
+	}
+
+	// reopen verifies the log as a restarted process with key
+	reopen := func(key []byte) *model.AuditVerification {
+		t.Helper()
+		store, err := repository.NewFileAuditStore(path, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		result, err := NewAuditUsecase(store, key).Verify(context.Background())
+		if err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+		return result
+	}
+	if result := reopen(testAuditKey); !result.Valid || result.Entries != 3 {
+		t.Fatalf("Verify() after restart = %+v, want 3 valid entries", result)
+	}
+	if result := reopen(bytes.Repeat([]byte{0x42}, 32)); result.Valid || result.BrokenAt != 1 {
+		t.Errorf("Verify() with another key = %+v, want broken at 1", result)
+	}
+	if result := reopen(nil); result.Valid || result.BrokenAt != 1 || !strings.Contains(result.Reason, "AUDIT_HMAC_KEY") {
+		t.Errorf("Verify() without key = %+v, want broken at 1", result)
+	}
+
+	// Rewriting the log without the key: an edited entry chained again with plain SHA-256
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rewritten []string
+	var prev string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		var entry model.AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		entry.User = strings.Replace(entry.User, "bob", "mallory", 1)
+		entry.HashAlg, entry.PrevHash = "", prev
+		if entry.Hash, err = auditHash(&entry, nil); err != nil {
+			t.Fatal(err)
+		}
+		prev = entry.Hash
+		line, _ := json.Marshal(&entry)
+		rewritten = append(rewritten, string(line))
+	}
+	if err := os.WriteFile(path, []byte(strings.Join(rewritten, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if result := reopen(nil); !result.Valid {
+		t.Fatalf("Verify() of the rewritten log without key = %+v, want valid", result)
+	}
+	if result := reopen(testAuditKey); result.Valid || result.BrokenAt != 1 {
+		t.Errorf("Verify() of the rewritten log = %+v, want broken at 1", result)
+	}
+}
+
+func TestAuditUsecase_RotatedLog(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "audit.log")
+	open := func() AuditUsecase {
+		t.Helper()
+		store, err := repository.NewFileAuditStore(path, 1) // Every entry starts a new file
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return NewAuditUsecase(store, testAuditKey)
+	}
+	u := open()
+	recordTestEntries(t, u)
+
+	entries, err := u.ListEntries(ctx, model.AuditQuery{Limit: 2})
+	if err != nil || len(entries) != 2 || entries[0].Sequence != 3 || entries[1].Sequence != 2 {
+		t.Fatalf("ListEntries() = %d entries, %v, want entries 3 and 2", len(entries), err)
+	}
+	entries, err = u.ListEntries(ctx, model.AuditQuery{User: "bob"})
+	if err != nil || len(entries) != 1 || entries[0].Sequence != 2 {
+		t.Fatalf("ListEntries() by bob = %d entries, %v, want entry 2", len(entries), err)
+	}
+
+	// The chain continues across the files, also after a restart
+	restarted := open()
+	next := &model.AuditEntry{User: "carol", Operation: "onu.reboot", Timestamp: time.Now()}
+	if err := restarted.Record(ctx, next); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if next.Sequence != 4 {
+		t.Fatalf("Sequence after restart = %d, want 4", next.Sequence)
+	}
+	for i := 0; i < 2; i++ {
+		if result, err := restarted.Verify(ctx); err != nil || !result.Valid || result.Entries != 4 || result.LastHash != next.Hash {
+			t.Fatalf("Verify() #%d = %+v, %v, want 4 valid entries", i+1, result, err)
+		}
+	}
+
+	// A rotated file that changed after it verified is read again
+	rotated := path + ".2"
+	data, err := os.ReadFile(rotated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(rotated, bytes.Replace(data, []byte(`"user":"bob"`), []byte(`"user":"mallory"`), 1), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := restarted.Verify(ctx); err != nil || result.Valid || result.BrokenAt != 2 {
+		t.Errorf("Verify() of an edited rotated file = %+v, %v, want broken at 2", result, err)
+	}
+
+	// So is one that follows a removed file
+	os.WriteFile(rotated, data, 0600)
+	if err := os.Remove(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	if result, err := restarted.Verify(ctx); err != nil || result.Valid || result.BrokenAt != 1 {
+		t.Errorf("Verify() without the first file = %+v, %v, want broken at 1", result, err)
+	}
+}
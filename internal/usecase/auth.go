@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// API keys have the form "c320_<id>_<secret>": a 16 hex character ID and 32 random bytes, base64url encoded
+const (
+	apiKeyPrefix      = "c320_"
+	apiKeyIDBytes     = 8
+	apiKeySecretBytes = 32
+	maxAPIKeyName     = 64
+)
+
+// adminPrincipalID is the principal ID of the bootstrap admin key
+const adminPrincipalID = "admin"
+
+// APIKeyUsecase issues API keys and authenticates requests with them
+type APIKeyUsecase interface {
+	// IssueKey creates a key; the returned key is not stored and cannot be shown again
+	IssueKey(ctx context.Context, req *model.APIKeyRequest, createdBy string) (*model.IssuedAPIKey, error)
+
+	// ListKeys returns all keys, including revoked ones
+	ListKeys(ctx context.Context) ([]*model.APIKey, error)
+
+	// GetKey returns a key by ID
+	GetKey(ctx context.Context, id string) (*model.APIKey, error)
+
+	// RotateKey replaces the secret of a key; the old key stops working immediately
+	RotateKey(ctx context.Context, id string) (*model.IssuedAPIKey, error)
+
+	// RevokeKey rejects a key from now on; revoked keys are kept for reference
+	RevokeKey(ctx context.Context, id string) (*model.APIKey, error)
+
+	// Authenticate returns the caller of a key, or an unauthorized error
+	Authenticate(ctx context.Context, key string) (*model.Principal, error)
+
+	// ResolvePrincipal returns the current rights of the admin key or API key a job runs as, or an
+	// unauthorized error if the key was revoked, rotated, has expired or is no longer configured
+	ResolvePrincipal(ctx context.Context, owner *model.JobOwner) (*model.Principal, error)
+}
+
+type apiKeyUsecase struct {
+	store     repository.APIKeyStore
+	adminHash []byte           // SHA-256 of the bootstrap admin key, nil if not configured
+	now       func() time.Time // Clock, replaced in tests
+}
+
+// NewAPIKeyUsecase creates the API key usecase. The bootstrap admin key of the configuration
+// is accepted with all scopes, so the first keys can be issued.
+func NewAPIKeyUsecase(cfg config.AuthConfig, store repository.APIKeyStore) APIKeyUsecase {
+	u := &apiKeyUsecase{store: store, now: time.Now}
+	if cfg.AdminKey != "" {
+		sum := sha256.Sum256([]byte(cfg.AdminKey))
+		u.adminHash = sum[:]
+	}
+	return u
+}
+
+// IssueKey validates the request and stores a new key
+func (u *apiKeyUsecase) IssueKey(ctx context.Context, req *model.APIKeyRequest, createdBy string) (*model.IssuedAPIKey, error) {
+	if req == nil {
+		return nil, apperrors.NewValidationError("request body is required", nil)
+	}
+	if err := u.validateKeyRequest(req); err != nil {
+		return nil, err
+	}
+
+	id, err := randomBytes(apiKeyIDBytes)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to generate API key", err)
+	}
+
+	key := &model.APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      req.Name,
+		Scopes:    uniqueScopes(req.Scopes),
+		Boards:    req.Boards,
+		PONs:      req.PONs,
+		CreatedBy: createdBy,
+		CreatedAt: u.now().UTC(),
+		ExpiresAt: req.ExpiresAt,
+	}
+	secret, err := u.newSecret(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.store.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("key_id", key.ID).Str("name", key.Name).Strs("scopes", key.Scopes).
+		Str("created_by", createdBy).Msg("API key issued")
+	return &model.IssuedAPIKey{Key: secret, APIKey: key}, nil
+}
+
+// ListKeys returns all keys, including revoked ones
+func (u *apiKeyUsecase) ListKeys(ctx context.Context) ([]*model.APIKey, error) {
+	return u.store.List(ctx)
+}
+
+// GetKey returns a key by ID
+func (u *apiKeyUsecase) GetKey(ctx context.Context, id string) (*model.APIKey, error) {
+	return u.store.Get(ctx, id)
+}
+
+// RotateKey replaces the secret of a key, keeping its ID, scopes and restrictions
+func (u *apiKeyUsecase) RotateKey(ctx context.Context, id string) (*model.IssuedAPIKey, error) {
+	key, err := u.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, apperrors.NewValidationError("revoked API keys cannot be rotated", map[string]interface{}{
+			"id": id,
+		})
+	}
+
+	secret, err := u.newSecret(key)
+	if err != nil {
+		return nil, err
+	}
+	rotatedAt := u.now().UTC()
+	key.RotatedAt = &rotatedAt
+	if err := u.store.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("key_id", key.ID).Str("name", key.Name).Msg("API key rotated")
+	return &model.IssuedAPIKey{Key: secret, APIKey: key}, nil
+}
+
+// RevokeKey marks a key as revoked; revoking a revoked key keeps the first revocation time
+func (u *apiKeyUsecase) RevokeKey(ctx context.Context, id string) (*model.APIKey, error) {
+	key, err := u.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	revokedAt := u.now().UTC()
+	key.RevokedAt = &revokedAt
+	if err := u.store.Save(ctx, key); err != nil {
+		return nil, err
+	}
+
+	log.Info().Str("key_id", key.ID).Str("name", key.Name).Msg("API key revoked")
+	return key, nil
+}
+
+// Authenticate checks a key against the bootstrap admin key and the stored keys
+func (u *apiKeyUsecase) Authenticate(ctx context.Context, key string) (*model.Principal, error) {
+	sum := sha256.Sum256([]byte(key))
+	if u.adminHash != nil && subtle.ConstantTimeCompare(sum[:], u.adminHash) == 1 {
+		return adminPrincipal(), nil
+	}
+
+	id, ok := parseAPIKeyID(key)
+	if !ok {
+		return nil, apperrors.NewUnauthorizedError("invalid API key")
+	}
+
+	stored, err := u.store.Get(ctx, id)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound {
+			return nil, apperrors.NewUnauthorizedError("invalid API key")
+		}
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(sum[:])), []byte(stored.Hash)) != 1 {
+		return nil, apperrors.NewUnauthorizedError("invalid API key")
+	}
+	return u.keyPrincipal(stored)
+}
+
+// ResolvePrincipal looks up the admin key or API key a job runs as again
+func (u *apiKeyUsecase) ResolvePrincipal(ctx context.Context, owner *model.JobOwner) (*model.Principal, error) {
+	if owner.Method == model.AuthMethodAdmin {
+		if u.adminHash == nil {
+			return nil, apperrors.NewUnauthorizedError("the bootstrap admin key is no longer configured")
+		}
+		return adminPrincipal(), nil
+	}
+
+	stored, err := u.store.Get(ctx, owner.ID)
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeNotFound {
+			return nil, apperrors.NewUnauthorizedError("API key no longer exists")
+		}
+		return nil, err
+	}
+	if !sameTime(stored.RotatedAt, owner.KeyRotatedAt) {
+		return nil, apperrors.NewUnauthorizedError("API key has been rotated")
+	}
+	return u.keyPrincipal(stored)
+}
+
+// keyPrincipal returns the caller of a stored key, or an unauthorized error if it was revoked or has expired
+func (u *apiKeyUsecase) keyPrincipal(stored *model.APIKey) (*model.Principal, error) {
+	if stored.RevokedAt != nil {
+		return nil, apperrors.NewUnauthorizedError("API key has been revoked")
+	}
+	if stored.ExpiresAt != nil && !u.now().Before(*stored.ExpiresAt) {
+		return nil, apperrors.NewUnauthorizedError("API key has expired")
+	}
+
+	return &model.Principal{
+		ID:           stored.ID,
+		Name:         stored.Name,
+		Method:       model.AuthMethodAPIKey,
+		Scopes:       stored.Scopes,
+		Boards:       stored.Boards,
+		PONs:         stored.PONs,
+		KeyRotatedAt: stored.RotatedAt,
+	}, nil
+}
+
+// adminPrincipal returns the caller of the bootstrap admin key
+func adminPrincipal() *model.Principal {
+	return &model.Principal{
+		ID:     adminPrincipalID,
+		Name:   "bootstrap admin key",
+		Method: model.AuthMethodAdmin,
+		Scopes: model.Scopes,
+	}
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// validateKeyRequest checks the name, scopes, board/PON restrictions and expiry of an issue request
+func (u *apiKeyUsecase) validateKeyRequest(req *model.APIKeyRequest) error {
+	if req.Name == "" || len(req.Name) > maxAPIKeyName {
+		return apperrors.NewValidationError("name must be 1-64 characters", map[string]interface{}{
+			"name": req.Name,
+		})
+	}
+
+	if len(req.Scopes) == 0 {
+		return apperrors.NewValidationError("at least one scope is required", map[string]interface{}{
+			"valid_scopes": model.Scopes,
+		})
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.Scopes, scope) {
+			return apperrors.NewValidationError("unknown scope", map[string]interface{}{
+				"scope":        scope,
+				"valid_scopes": model.Scopes,
+			})
+		}
+	}
+
+	for _, board := range req.Boards {
+		if board != 1 && board != 2 {
+			return apperrors.NewValidationError("boards must be 1 or 2", map[string]interface{}{
+				"board": board,
+			})
+		}
+	}
+	for _, pon := range req.PONs {
+		if (pon.Board != 1 && pon.Board != 2) || pon.PON < 1 || pon.PON > 16 {
+			return apperrors.NewValidationError("pons must have board 1 or 2 and pon between 1 and 16", map[string]interface{}{
+				"pon": pon,
+			})
+		}
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(u.now()) {
+		return apperrors.NewValidationError("expires_at must be in the future", map[string]interface{}{
+			"expires_at": req.ExpiresAt,
+		})
+	}
+
+	return nil
+}
+
+// newSecret generates the key string for a key and sets its hash
+func (u *apiKeyUsecase) newSecret(key *model.APIKey) (string, error) {
+	secret, err := randomBytes(apiKeySecretBytes)
+	if err != nil {
+		return "", apperrors.NewInternalError("failed to generate API key", err)
+	}
+
+	plain := apiKeyPrefix + key.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	sum := sha256.Sum256([]byte(plain))
+	key.Hash = hex.EncodeToString(sum[:])
+	return plain, nil
+}
+
+// parseAPIKeyID returns the key ID of a key string in the form "c320_<id>_<secret>"
+func parseAPIKeyID(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, apiKeyPrefix)
+	idLength := hex.EncodedLen(apiKeyIDBytes)
+	if !ok || len(rest) <= idLength+1 || rest[idLength] != '_' {
+		return "", false
+	}
+
+	id := rest[:idLength]
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", false
+	}
+	return id, true
+}
+
+// uniqueScopes returns the scopes without duplicates, keeping their order
+func uniqueScopes(scopes []string) []string {
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(unique, scope) {
+			unique = append(unique, scope)
+		}
+	}
+	return unique
+}
+
+// randomBytes returns n bytes from the system's secure random source
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
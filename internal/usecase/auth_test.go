@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// memoryAPIKeyStore is an in-memory repository.APIKeyStore
+type memoryAPIKeyStore struct {
+	keys map[string]model.APIKey
+}
+
+func (s *memoryAPIKeyStore) Save(_ context.Context, key *model.APIKey) error {
+	s.keys[key.ID] = *key
+	return nil
+}
+
+func (s *memoryAPIKeyStore) Get(_ context.Context, id string) (*model.APIKey, error) {
+	key, ok := s.keys[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("API key", id)
+	}
+	return &key, nil
+}
+
+func (s *memoryAPIKeyStore) List(_ context.Context) ([]*model.APIKey, error) {
+	keys := make([]*model.APIKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, &key)
+	}
+	return keys, nil
+}
+
+const testAdminKey = "test-admin-key-0123456789abcdefghij"
+
+func newTestAPIKeyUsecase() (*apiKeyUsecase, *memoryAPIKeyStore) {
+	store := &memoryAPIKeyStore{keys: make(map[string]model.APIKey)}
+	u := NewAPIKeyUsecase(config.AuthConfig{Enabled: true, AdminKey: testAdminKey}, store).(*apiKeyUsecase)
+	return u, store
+}
+
+// assertUnauthorized fails the test unless err is an unauthorized error containing message
+func assertUnauthorized(t *testing.T, err error, message string) {
+	t.Helper()
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeUnauthorized || !strings.Contains(appErr.Message, message) {
+		t.Errorf("error = %v, want unauthorized %q", err, message)
+	}
+}
+
+func TestAPIKeyUsecase_IssueAndAuthenticate(t *testing.T) {
+	u, store := newTestAPIKeyUsecase()
+	ctx := context.Background()
+
+	issued, err := u.IssueKey(ctx, &model.APIKeyRequest{
+		Name:   "field-tech",
+		Scopes: []string{model.ScopeReadONU, model.ScopeWriteONU, model.ScopeReadONU},
+		PONs:   []model.BoardPON{{Board: 2, PON: 4}},
+	}, "admin")
+	if err != nil {
+		t.Fatalf("IssueKey() error = %v", err)
+	}
+	if !strings.HasPrefix(issued.Key, "c320_"+issued.APIKey.ID+"_") || len(issued.APIKey.ID) != 16 {
+		t.Errorf("IssueKey() key = %q, id %q, want c320_<16 hex>_<secret>", issued.Key, issued.APIKey.ID)
+	}
+	if len(issued.APIKey.Scopes) != 2 || issued.APIKey.CreatedBy != "admin" {
+		t.Errorf("IssueKey() = %+v, want 2 unique scopes created by admin", issued.APIKey)
+	}
+	if stored := store.keys[issued.APIKey.ID]; stored.Hash == "" || strings.Contains(stored.Hash, issued.Key) {
+		t.Errorf("stored hash = %q, want the SHA-256 of the key", stored.Hash)
+	}
+
+	principal, err := u.Authenticate(ctx, issued.Key)
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.ID != issued.APIKey.ID || principal.Method != model.AuthMethodAPIKey || !principal.HasScope(model.ScopeWriteONU) {
+		t.Errorf("Authenticate() = %+v, want the issued key", principal)
+	}
+	if !principal.AllowsPON(2, 4) || principal.AllowsPON(2, 5) || principal.AllowsPON(1, 4) {
+		t.Errorf("Authenticate() = %+v, want access to PON 2/4 only", principal)
+	}
+
+	// Wrong secret with a valid ID, unknown ID and malformed keys
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, issued.Key+"x")), "invalid API key")
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, "c320_0000000000000000_secret")), "invalid API key")
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, "not-a-key")), "invalid API key")
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, "")), "invalid API key")
+}
+
+func TestAPIKeyUsecase_AdminKey(t *testing.T) {
+	u, _ := newTestAPIKeyUsecase()
+
+	principal, err := u.Authenticate(context.Background(), testAdminKey)
+	if err != nil {
+		t.Fatalf("Authenticate(admin key) error = %v", err)
+	}
+	if principal.Method != model.AuthMethodAdmin || principal.Restricted() || !principal.HasScope(model.ScopeAdminKeys) {
+		t.Errorf("Authenticate(admin key) = %+v, want unrestricted admin with all scopes", principal)
+	}
+
+	without := NewAPIKeyUsecase(config.AuthConfig{Enabled: true}, &memoryAPIKeyStore{keys: map[string]model.APIKey{}})
+	assertUnauthorized(t, errorOf(without.Authenticate(context.Background(), "")), "invalid API key")
+}
+
+func TestAPIKeyUsecase_RotateRevokeExpire(t *testing.T) {
+	u, _ := newTestAPIKeyUsecase()
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	u.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	issued, err := u.IssueKey(ctx, &model.APIKeyRequest{Name: "noc", Scopes: []string{model.ScopeReadONU}, ExpiresAt: &expires}, "admin")
+	if err != nil {
+		t.Fatalf("IssueKey() error = %v", err)
+	}
+
+	rotated, err := u.RotateKey(ctx, issued.APIKey.ID)
+	if err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	if rotated.Key == issued.Key || rotated.APIKey.ID != issued.APIKey.ID || rotated.APIKey.RotatedAt == nil {
+		t.Errorf("RotateKey() = %+v, want a new key with the same ID", rotated)
+	}
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, issued.Key)), "invalid API key")
+	if _, err := u.Authenticate(ctx, rotated.Key); err != nil {
+		t.Errorf("Authenticate(rotated key) error = %v", err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, rotated.Key)), "expired")
+	now = now.Add(-2 * time.Hour)
+
+	revoked, err := u.RevokeKey(ctx, issued.APIKey.ID)
+	if err != nil || revoked.RevokedAt == nil {
+		t.Fatalf("RevokeKey() = %+v, %v, want revoked key", revoked, err)
+	}
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, rotated.Key)), "revoked")
+	if _, err := u.RotateKey(ctx, issued.APIKey.ID); err == nil {
+		t.Error("RotateKey(revoked) error = nil, want validation error")
+	}
+
+	if _, err := u.RevokeKey(ctx, "missing"); err == nil {
+		t.Error("RevokeKey(missing) error = nil, want not found")
+	}
+}
+
+func TestAPIKeyUsecase_ResolvePrincipal(t *testing.T) {
+	u, store := newTestAPIKeyUsecase()
+	ctx := context.Background()
+
+	issued, err := u.IssueKey(ctx, &model.APIKeyRequest{Name: "noc", Scopes: []string{model.ScopeBatch}}, "admin")
+	if err != nil {
+		t.Fatalf("IssueKey() error = %v", err)
+	}
+	submitter, _ := u.Authenticate(ctx, issued.Key)
+	owner := model.NewJobOwner(submitter)
+
+	// The current scopes and restrictions of the key are returned
+	key := store.keys[issued.APIKey.ID]
+	key.PONs = []model.BoardPON{{Board: 1, PON: 3}}
+	store.keys[key.ID] = key
+	principal, err := u.ResolvePrincipal(ctx, owner)
+	if err != nil {
+		t.Fatalf("ResolvePrincipal() error = %v", err)
+	}
+	if principal.ID != issued.APIKey.ID || !principal.HasScope(model.ScopeBatch) || principal.AllowsPON(1, 1) || !principal.AllowsPON(1, 3) {
+		t.Errorf("ResolvePrincipal() = %+v, want the key restricted to PON 1/3", principal)
+	}
+
+	rotated, err := u.RotateKey(ctx, issued.APIKey.ID)
+	if err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	assertUnauthorized(t, errorOf(u.ResolvePrincipal(ctx, owner)), "rotated")
+	submitter, _ = u.Authenticate(ctx, rotated.Key)
+	owner = model.NewJobOwner(submitter)
+	if _, err := u.ResolvePrincipal(ctx, owner); err != nil {
+		t.Errorf("ResolvePrincipal(rotated key) error = %v", err)
+	}
+
+	if _, err := u.RevokeKey(ctx, issued.APIKey.ID); err != nil {
+		t.Fatalf("RevokeKey() error = %v", err)
+	}
+	assertUnauthorized(t, errorOf(u.ResolvePrincipal(ctx, owner)), "revoked")
+	delete(store.keys, issued.APIKey.ID)
+	assertUnauthorized(t, errorOf(u.ResolvePrincipal(ctx, owner)), "no longer exists")
+
+	admin := &model.JobOwner{Method: model.AuthMethodAdmin, ID: adminPrincipalID}
+	if principal, err := u.ResolvePrincipal(ctx, admin); err != nil || !principal.HasScope(model.ScopeAdminKeys) {
+		t.Errorf("ResolvePrincipal(admin) = %+v, %v", principal, err)
+	}
+	without := NewAPIKeyUsecase(config.AuthConfig{Enabled: true}, store)
+	assertUnauthorized(t, errorOf(without.ResolvePrincipal(ctx, admin)), "no longer configured")
+}
+
+func TestAPIKeyUsecase_IssueValidation(t *testing.T) {
+	u, _ := newTestAPIKeyUsecase()
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		req  *model.APIKeyRequest
+		want string
+	}{
+		{"no body", nil, "request body is required"},
+		{"no name", &model.APIKeyRequest{Scopes: []string{model.ScopeBatch}}, "name"},
+		{"no scopes", &model.APIKeyRequest{Name: "noc"}, "at least one scope"},
+		{"unknown scope", &model.APIKeyRequest{Name: "noc", Scopes: []string{"write:all"}}, "unknown scope"},
+		{"bad board", &model.APIKeyRequest{Name: "noc", Scopes: []string{model.ScopeReadONU}, Boards: []int{3}}, "boards"},
+		{"bad pon", &model.APIKeyRequest{Name: "noc", Scopes: []string{model.ScopeReadONU}, PONs: []model.BoardPON{{Board: 1, PON: 17}}}, "pons"},
+		{"expired", &model.APIKeyRequest{Name: "noc", Scopes: []string{model.ScopeReadONU}, ExpiresAt: &past}, "expires_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := u.IssueKey(context.Background(), tt.req, "admin")
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeValidation || !strings.Contains(appErr.Message, tt.want) {
+				t.Errorf("IssueKey() error = %v, want validation error %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// errorOf returns the error of a (value, error) result
+func errorOf(_ *model.Principal, err error) error {
+	return err
+}
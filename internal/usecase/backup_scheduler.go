@@ -0,0 +1,611 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/cron"
+)
+
+// Run states of a backup schedule
+const (
+	scheduleStatusSuccess = "success"
+	scheduleStatusPartial = "partial"
+	scheduleStatusFailed  = "failed"
+)
+
+var scheduleIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// BackupSchedulerUsecase takes OLT backups on cron schedules and prunes them by retention policy
+type BackupSchedulerUsecase interface {
+	// List all schedules
+	ListSchedules() []*model.BackupSchedule
+
+	// Get a schedule by ID
+	GetSchedule(id string) (*model.BackupSchedule, error)
+
+	// Create a new schedule
+	CreateSchedule(req *model.BackupScheduleRequest) (*model.BackupSchedule, error)
+
+	// Update schedule, cron expression or retention
+	UpdateSchedule(id string, req *model.BackupScheduleRequest) (*model.BackupSchedule, error)
+
+	// Delete a schedule (its backups are kept)
+	DeleteSchedule(id string) error
+
+	// Start a run of the schedule now, in the background
+	TriggerSchedule(id string) (*model.BackupSchedule, error)
+
+	// Run the scheduler loop until the context is canceled
+	Run(ctx context.Context)
+}
+
+type backupScheduler struct {
+	backupUsecase ConfigBackupUsecase
+	path          string           // File the schedules are persisted in
+	now           func() time.Time // Clock, replaced in tests
+
+	mu        sync.Mutex
+	schedules map[string]*model.BackupSchedule
+	parsed    map[string]*cron.Schedule
+	running   map[string]bool
+	wake      chan struct{} // Signals the run loop that schedules changed
+}
+
+// NewBackupSchedulerUsecase creates the backup scheduler of an OLT.
+// Schedules are persisted in "schedules/schedules.json" inside the backup directory.
+func NewBackupSchedulerUsecase(cfg *config.Config, backupUsecase ConfigBackupUsecase) BackupSchedulerUsecase {
+	s := &backupScheduler{
+		backupUsecase: backupUsecase,
+		path:          filepath.Join(backupDirectory(cfg), "schedules", "schedules.json"),
+		now:           time.Now,
+		schedules:     make(map[string]*model.BackupSchedule),
+		parsed:        make(map[string]*cron.Schedule),
+		running:       make(map[string]bool),
+		wake:          make(chan struct{}, 1),
+	}
+
+	if err := s.load(); err != nil {
+		log.Error().Err(err).Str("file", s.path).Msg("Failed to load backup schedules")
+	}
+
+	return s
+}
+
+// ListSchedules returns all schedules ordered by ID
+func (s *backupScheduler) ListSchedules() []*model.BackupSchedule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedules := make([]*model.BackupSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		schedules = append(schedules, copySchedule(schedule))
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	return schedules
+}
+
+// GetSchedule returns a schedule by ID
+func (s *backupScheduler) GetSchedule(id string) (*model.BackupSchedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("backup schedule", id)
+	}
+
+	return copySchedule(schedule), nil
+}
+
+// CreateSchedule validates and stores a new schedule
+func (s *backupScheduler) CreateSchedule(req *model.BackupScheduleRequest) (*model.BackupSchedule, error) {
+	if req == nil {
+		return nil, apperrors.NewValidationError("request body is required", nil)
+	}
+	if !scheduleIDPattern.MatchString(req.ID) {
+		return nil, apperrors.NewValidationError("schedule id must be 1-64 letters, digits, '-' or '_'", map[string]interface{}{
+			"id": req.ID,
+		})
+	}
+	if req.Cron == nil {
+		return nil, apperrors.NewValidationError("cron is required", nil)
+	}
+
+	schedule := &model.BackupSchedule{ID: req.ID, Enabled: true}
+	parsed, err := applyScheduleRequest(schedule, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.schedules[schedule.ID]; exists {
+		return nil, apperrors.NewValidationError("schedule already exists", map[string]interface{}{
+			"id": schedule.ID,
+		})
+	}
+
+	s.schedules[schedule.ID] = schedule
+	s.parsed[schedule.ID] = parsed
+	s.planNextRun(schedule, s.now())
+
+	if err := s.saveLocked(); err != nil {
+		delete(s.schedules, schedule.ID)
+		delete(s.parsed, schedule.ID)
+		return nil, apperrors.NewInternalError("failed to save backup schedules", err)
+	}
+	s.notify()
+
+	log.Info().Str("schedule_id", schedule.ID).Str("cron", schedule.Cron).Msg("Backup schedule created")
+	return copySchedule(schedule), nil
+}
+
+// UpdateSchedule applies the given fields to an existing schedule
+func (s *backupScheduler) UpdateSchedule(id string, req *model.BackupScheduleRequest) (*model.BackupSchedule, error) {
+	if req == nil {
+		return nil, apperrors.NewValidationError("request body is required", nil)
+	}
+	if req.ID != "" && req.ID != id {
+		return nil, apperrors.NewValidationError("schedule id cannot be changed", map[string]interface{}{
+			"id": req.ID,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, ok := s.schedules[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("backup schedule", id)
+	}
+
+	updated := copySchedule(current)
+	parsed, err := applyScheduleRequest(updated, req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.schedules[id] = updated
+	s.parsed[id] = parsed
+	s.planNextRun(updated, s.now())
+
+	if err := s.saveLocked(); err != nil {
+		s.schedules[id] = current
+		s.parsed[id], _ = cron.Parse(current.Cron)
+		return nil, apperrors.NewInternalError("failed to save backup schedules", err)
+	}
+	s.notify()
+
+	log.Info().Str("schedule_id", id).Str("cron", updated.Cron).Bool("enabled", updated.Enabled).Msg("Backup schedule updated")
+	return copySchedule(updated), nil
+}
+
+// DeleteSchedule removes a schedule. Backups it created are kept.
+func (s *backupScheduler) DeleteSchedule(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule, ok := s.schedules[id]
+	if !ok {
+		return apperrors.NewNotFoundError("backup schedule", id)
+	}
+
+	delete(s.schedules, id)
+	if err := s.saveLocked(); err != nil {
+		s.schedules[id] = schedule
+		return apperrors.NewInternalError("failed to save backup schedules", err)
+	}
+	delete(s.parsed, id)
+	s.notify()
+
+	log.Info().Str("schedule_id", id).Msg("Backup schedule deleted")
+	return nil
+}
+
+// TriggerSchedule starts a run of the schedule in the background; the planned next run is unchanged
+func (s *backupScheduler) TriggerSchedule(id string) (*model.BackupSchedule, error) {
+	s.mu.Lock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		s.mu.Unlock()
+		return nil, apperrors.NewNotFoundError("backup schedule", id)
+	}
+	if s.running[id] {
+		s.mu.Unlock()
+		return nil, apperrors.NewValidationError("schedule is already running", map[string]interface{}{
+			"id": id,
+		})
+	}
+	s.running[id] = true
+	result := copySchedule(schedule)
+	s.mu.Unlock()
+
+	go s.execute(id)
+
+	return result, nil
+}
+
+// Run executes due schedules until the context is canceled
+func (s *backupScheduler) Run(ctx context.Context) {
+	log.Info().Int("schedules", len(s.ListSchedules())).Msg("Backup scheduler started")
+
+	for {
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if next, ok := s.nextDue(); ok {
+			timer = time.NewTimer(next.Sub(s.now()))
+			fire = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			log.Info().Msg("Backup scheduler stopped")
+			return
+		case <-s.wake:
+		case <-fire:
+			s.runDue()
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// nextDue returns the earliest planned run of all enabled schedules
+func (s *backupScheduler) nextDue() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, schedule := range s.schedules {
+		if !schedule.Enabled || schedule.NextRun == nil {
+			continue
+		}
+		if next.IsZero() || schedule.NextRun.Before(next) {
+			next = *schedule.NextRun
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// runDue runs every enabled schedule whose planned run has passed, one after another
+func (s *backupScheduler) runDue() {
+	now := s.now()
+
+	s.mu.Lock()
+	var due []string
+	for id, schedule := range s.schedules {
+		if !schedule.Enabled || schedule.NextRun == nil || schedule.NextRun.After(now) {
+			continue
+		}
+		// Plan the following run first so a slow backup does not fire twice
+		s.planNextRun(schedule, now)
+		if s.running[id] {
+			log.Warn().Str("schedule_id", id).Msg("Skipping scheduled backup, previous run still in progress")
+			continue
+		}
+		s.running[id] = true
+		due = append(due, id)
+	}
+	s.mu.Unlock()
+
+	sort.Strings(due)
+	for _, id := range due {
+		s.execute(id)
+	}
+}
+
+// execute takes a backup for the schedule, applies its retention policy and records the outcome.
+// The caller must have marked the schedule as running.
+func (s *backupScheduler) execute(id string) {
+	s.mu.Lock()
+	schedule, ok := s.schedules[id]
+	if !ok {
+		delete(s.running, id)
+		s.mu.Unlock()
+		return
+	}
+	snapshot := copySchedule(schedule)
+	s.mu.Unlock()
+
+	started := s.now()
+	log.Info().Str("schedule_id", id).Msg("Running scheduled OLT backup")
+
+	status, backupID, pruned, runErr := s.backupAndPrune(snapshot)
+	if runErr != nil {
+		log.Error().Err(runErr).Str("schedule_id", id).Str("status", status).Msg("Scheduled backup failed")
+	} else {
+		log.Info().Str("schedule_id", id).Str("backup_id", backupID).Str("status", status).Int("pruned", pruned).Msg("Scheduled backup completed")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.running, id)
+	schedule, ok = s.schedules[id]
+	if !ok {
+		return // Deleted while running
+	}
+
+	schedule.LastRun = &started
+	schedule.LastStatus = status
+	schedule.LastBackupID = backupID
+	schedule.LastPruned = pruned
+	schedule.LastError = ""
+	if runErr != nil {
+		schedule.LastError = runErr.Error()
+	}
+
+	if err := s.saveLocked(); err != nil {
+		log.Error().Err(err).Str("file", s.path).Msg("Failed to save backup schedules")
+	}
+}
+
+// backupAndPrune takes the backup of a run and prunes older backups of the schedule.
+// Nothing is pruned when the backup fails.
+func (s *backupScheduler) backupAndPrune(schedule *model.BackupSchedule) (string, string, int, error) {
+	backup, err := s.backupUsecase.BackupOLT(context.Background(), schedule.Description, scheduleTags(schedule))
+	if err != nil {
+		return scheduleStatusFailed, "", 0, err
+	}
+
+	status := scheduleStatusSuccess
+	if backup.Metadata.CustomFields["partial"] == "true" {
+		status = scheduleStatusPartial
+	}
+
+	pruned, err := s.prune(schedule)
+	if err != nil {
+		return status, backup.ID, pruned, fmt.Errorf("retention: %w", err)
+	}
+
+	return status, backup.ID, pruned, nil
+}
+
+// prune deletes the backups of a schedule that its retention policy does not keep
+func (s *backupScheduler) prune(schedule *model.BackupSchedule) (int, error) {
+	if schedule.Retention == (model.RetentionPolicy{}) {
+		return 0, nil
+	}
+
+	items, err := s.backupUsecase.ListBackups("olt", 0)
+	if err != nil {
+		return 0, err
+	}
+
+	var owned []*model.BackupListItem
+	for _, item := range items {
+		if hasTag(item.Tags, model.BackupTagScheduled) && hasTag(item.Tags, schedule.ID) {
+			owned = append(owned, item)
+		}
+	}
+
+	var errs []error
+	pruned := 0
+	for _, item := range selectPrunable(owned, schedule.Retention) {
+		if err := s.backupUsecase.DeleteBackup(item.ID); err != nil {
+			errs = append(errs, fmt.Errorf("delete %s: %w", item.ID, err))
+			continue
+		}
+		pruned++
+	}
+
+	return pruned, errors.Join(errs...)
+}
+
+// selectPrunable returns the backups not kept by any retention rule.
+// Rules count from the most recent backup; daily and weekly rules keep the most recent backup per day or ISO week.
+func selectPrunable(items []*model.BackupListItem, policy model.RetentionPolicy) []*model.BackupListItem {
+	sorted := make([]*model.BackupListItem, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+
+	keep := make(map[*model.BackupListItem]bool, len(sorted))
+	for i := 0; i < policy.KeepLast && i < len(sorted); i++ {
+		keep[sorted[i]] = true
+	}
+
+	keepPerPeriod := func(limit int, period func(time.Time) string) {
+		seen := make(map[string]bool)
+		for _, item := range sorted {
+			if len(seen) >= limit {
+				return
+			}
+			key := period(item.Timestamp)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			keep[item] = true
+		}
+	}
+	keepPerPeriod(policy.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPerPeriod(policy.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	})
+
+	var prunable []*model.BackupListItem
+	for _, item := range sorted {
+		if !keep[item] {
+			prunable = append(prunable, item)
+		}
+	}
+
+	return prunable
+}
+
+// planNextRun sets the next run of a schedule after the given time. Must be called with the lock held.
+func (s *backupScheduler) planNextRun(schedule *model.BackupSchedule, after time.Time) {
+	schedule.NextRun = nil
+
+	parsed, ok := s.parsed[schedule.ID]
+	if !ok || !schedule.Enabled {
+		return
+	}
+	if next := parsed.Next(after); !next.IsZero() {
+		schedule.NextRun = &next
+	}
+}
+
+// notify wakes the run loop so it picks up changed schedules
+func (s *backupScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// load reads the persisted schedules. A missing file means no schedules.
+func (s *backupScheduler) load() error {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var schedules []*model.BackupSchedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return fmt.Errorf("invalid schedules file: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for _, schedule := range schedules {
+		parsed, err := cron.Parse(schedule.Cron)
+		if err != nil {
+			log.Error().Err(err).Str("schedule_id", schedule.ID).Msg("Disabling backup schedule with invalid cron expression")
+			schedule.Enabled = false
+		} else {
+			s.parsed[schedule.ID] = parsed
+		}
+		s.schedules[schedule.ID] = schedule
+		s.planNextRun(schedule, now)
+	}
+
+	return nil
+}
+
+// saveLocked persists all schedules. Must be called with the lock held.
+func (s *backupScheduler) saveLocked() error {
+	schedules := make([]*model.BackupSchedule, 0, len(s.schedules))
+	for _, schedule := range s.schedules {
+		schedules = append(schedules, schedule)
+	}
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].ID < schedules[j].ID
+	})
+
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file behind
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+// applyScheduleRequest copies the set fields of a request onto a schedule and returns the parsed cron expression
+func applyScheduleRequest(schedule *model.BackupSchedule, req *model.BackupScheduleRequest) (*cron.Schedule, error) {
+	if req.Cron != nil {
+		schedule.Cron = *req.Cron
+	}
+	if req.Enabled != nil {
+		schedule.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		schedule.Description = *req.Description
+	}
+	if req.Tags != nil {
+		schedule.Tags = req.Tags
+	}
+	if req.Retention != nil {
+		schedule.Retention = *req.Retention
+	}
+
+	parsed, err := cron.Parse(schedule.Cron)
+	if err != nil {
+		return nil, apperrors.NewValidationError("invalid cron expression", map[string]interface{}{
+			"cron":  schedule.Cron,
+			"error": err.Error(),
+		})
+	}
+
+	r := schedule.Retention
+	if r.KeepLast < 0 || r.KeepDaily < 0 || r.KeepWeekly < 0 {
+		return nil, apperrors.NewValidationError("retention values must not be negative", map[string]interface{}{
+			"retention": r,
+		})
+	}
+
+	return parsed, nil
+}
+
+// scheduleTags returns the tags of a scheduled backup: "scheduled", the schedule ID and the extra tags
+func scheduleTags(schedule *model.BackupSchedule) []string {
+	tags := []string{model.BackupTagScheduled, schedule.ID}
+	for _, tag := range schedule.Tags {
+		if !hasTag(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// copySchedule returns a copy that can be handed out without holding the lock
+func copySchedule(schedule *model.BackupSchedule) *model.BackupSchedule {
+	c := *schedule
+	if schedule.Tags != nil {
+		c.Tags = append([]string(nil), schedule.Tags...)
+	}
+	return &c
+}
@@ -0,0 +1,239 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// fakeScheduledBackups records backups taken by the scheduler; other ConfigBackupUsecase methods are not used
+type fakeScheduledBackups struct {
+	ConfigBackupUsecase
+	now     func() time.Time
+	backups map[string]*model.BackupListItem
+	fail    error
+	seq     int
+}
+
+func (f *fakeScheduledBackups) BackupOLT(_ context.Context, description string, tags []string) (*model.ConfigBackup, error) {
+	if f.fail != nil {
+		return nil, f.fail
+	}
+	f.seq++
+	id := fmt.Sprintf("backup-%d", f.seq)
+	f.backups[id] = &model.BackupListItem{ID: id, Type: "olt", Timestamp: f.now(), Description: description, Tags: tags}
+	return &model.ConfigBackup{ID: id, Type: "olt", Timestamp: f.now(), Description: description}, nil
+}
+
+func (f *fakeScheduledBackups) ListBackups(backupType string, limit int) ([]*model.BackupListItem, error) {
+	var items []*model.BackupListItem
+	for _, item := range f.backups {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (f *fakeScheduledBackups) DeleteBackup(backupID string) error {
+	delete(f.backups, backupID)
+	return nil
+}
+
+func newTestScheduler(cfg *config.Config, backups *fakeScheduledBackups) *backupScheduler {
+	s := NewBackupSchedulerUsecase(cfg, backups).(*backupScheduler)
+	s.now = backups.now
+	return s
+}
+
+func stringPtr(s string) *string { return &s }
+
+func TestSelectPrunable(t *testing.T) {
+	base := time.Date(2026, 1, 14, 2, 0, 0, 0, time.UTC) // Wednesday
+	var items []*model.BackupListItem
+	// Two backups a day for 20 days
+	for day := 0; day < 20; day++ {
+		for _, hour := range []int{0, 12} {
+			ts := base.AddDate(0, 0, -day).Add(time.Duration(hour) * time.Hour)
+			items = append(items, &model.BackupListItem{ID: ts.Format("01-02T15"), Timestamp: ts})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		policy   model.RetentionPolicy
+		wantKept []string
+	}{
+		{
+			name:     "keep last",
+			policy:   model.RetentionPolicy{KeepLast: 3},
+			wantKept: []string{"01-14T14", "01-14T02", "01-13T14"},
+		},
+		{
+			name:     "keep daily",
+			policy:   model.RetentionPolicy{KeepDaily: 2},
+			wantKept: []string{"01-14T14", "01-13T14"},
+		},
+		{
+			name:   "keep weekly",
+			policy: model.RetentionPolicy{KeepWeekly: 3},
+			// Newest per ISO week: week of Jan 12, Jan 5, Dec 29
+			wantKept: []string{"01-14T14", "01-11T14", "01-04T14"},
+		},
+		{
+			name:     "rules combine",
+			policy:   model.RetentionPolicy{KeepLast: 2, KeepDaily: 2, KeepWeekly: 2},
+			wantKept: []string{"01-14T14", "01-14T02", "01-13T14", "01-11T14"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pruned := make(map[string]bool)
+			for _, item := range selectPrunable(items, tt.policy) {
+				pruned[item.ID] = true
+			}
+
+			var kept []string
+			for _, item := range items {
+				if !pruned[item.ID] {
+					kept = append(kept, item.ID)
+				}
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(kept)))
+			want := append([]string(nil), tt.wantKept...)
+			sort.Sort(sort.Reverse(sort.StringSlice(want)))
+
+			if fmt.Sprint(kept) != fmt.Sprint(want) {
+				t.Errorf("kept = %v, want %v", kept, want)
+			}
+		})
+	}
+}
+
+func TestBackupScheduler_CRUDAndPersistence(t *testing.T) {
+	now := time.Date(2026, 1, 14, 10, 0, 0, 0, time.UTC)
+	backups := &fakeScheduledBackups{now: func() time.Time { return now }, backups: map[string]*model.BackupListItem{}}
+	cfg := newBackupTestConfig(t)
+	s := newTestScheduler(cfg, backups)
+
+	if _, err := s.CreateSchedule(&model.BackupScheduleRequest{ID: "bad id", Cron: stringPtr("@daily")}); err == nil {
+		t.Error("expected error for invalid id")
+	}
+	if _, err := s.CreateSchedule(&model.BackupScheduleRequest{ID: "daily", Cron: stringPtr("0 25 * * *")}); err == nil {
+		t.Error("expected error for invalid cron")
+	}
+
+	created, err := s.CreateSchedule(&model.BackupScheduleRequest{
+		ID:        "daily",
+		Cron:      stringPtr("0 2 * * *"),
+		Retention: &model.RetentionPolicy{KeepLast: 2},
+	})
+	if err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+	if !created.Enabled || created.NextRun == nil || !created.NextRun.Equal(time.Date(2026, 1, 15, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("created = %+v", created)
+	}
+
+	var appErr *apperrors.AppError
+	if _, err := s.CreateSchedule(&model.BackupScheduleRequest{ID: "daily", Cron: stringPtr("@daily")}); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeValidation {
+		t.Errorf("duplicate error = %v, want validation error", err)
+	}
+
+	disabled := false
+	updated, err := s.UpdateSchedule("daily", &model.BackupScheduleRequest{Enabled: &disabled})
+	if err != nil {
+		t.Fatalf("UpdateSchedule() error = %v", err)
+	}
+	if updated.Enabled || updated.NextRun != nil || updated.Cron != "0 2 * * *" || updated.Retention.KeepLast != 2 {
+		t.Errorf("updated = %+v", updated)
+	}
+
+	// A new scheduler on the same directory sees the persisted schedule
+	reloaded := newTestScheduler(cfg, backups)
+	got, err := reloaded.GetSchedule("daily")
+	if err != nil {
+		t.Fatalf("GetSchedule() error = %v", err)
+	}
+	if got.Enabled || got.Retention.KeepLast != 2 {
+		t.Errorf("reloaded = %+v", got)
+	}
+
+	if err := s.DeleteSchedule("daily"); err != nil {
+		t.Fatalf("DeleteSchedule() error = %v", err)
+	}
+	if _, err := s.GetSchedule("daily"); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("error = %v, want not found error", err)
+	}
+}
+
+func TestBackupScheduler_RunDueTagsAndPrunes(t *testing.T) {
+	now := time.Date(2026, 1, 14, 1, 59, 0, 0, time.UTC)
+	backups := &fakeScheduledBackups{now: func() time.Time { return now }, backups: map[string]*model.BackupListItem{
+		// Manual backups are never pruned
+		"manual": {ID: "manual", Type: "olt", Timestamp: now.AddDate(0, 0, -30)},
+	}}
+	s := newTestScheduler(newBackupTestConfig(t), backups)
+
+	if _, err := s.CreateSchedule(&model.BackupScheduleRequest{
+		ID:        "daily",
+		Cron:      stringPtr("0 2 * * *"),
+		Tags:      []string{"nightly"},
+		Retention: &model.RetentionPolicy{KeepLast: 2},
+	}); err != nil {
+		t.Fatalf("CreateSchedule() error = %v", err)
+	}
+
+	// Not due yet
+	s.runDue()
+	if len(backups.backups) != 1 {
+		t.Fatalf("backups = %d, want 1", len(backups.backups))
+	}
+
+	for day := 0; day < 3; day++ {
+		now = time.Date(2026, 1, 14+day, 2, 0, 0, 0, time.UTC)
+		s.runDue()
+	}
+
+	if _, ok := backups.backups["manual"]; !ok {
+		t.Error("manual backup was pruned")
+	}
+	if len(backups.backups) != 3 {
+		t.Errorf("backups = %v, want manual + 2 scheduled", backups.backups)
+	}
+	if _, ok := backups.backups["backup-1"]; ok {
+		t.Error("oldest scheduled backup was not pruned")
+	}
+
+	latest := backups.backups["backup-3"]
+	if latest == nil || fmt.Sprint(latest.Tags) != "[scheduled daily nightly]" {
+		t.Errorf("latest backup = %+v", latest)
+	}
+
+	schedule, _ := s.GetSchedule("daily")
+	if schedule.LastStatus != scheduleStatusSuccess || schedule.LastBackupID != "backup-3" || schedule.LastPruned != 1 {
+		t.Errorf("schedule = %+v", schedule)
+	}
+	if schedule.NextRun == nil || !schedule.NextRun.Equal(time.Date(2026, 1, 17, 2, 0, 0, 0, time.UTC)) {
+		t.Errorf("next run = %v", schedule.NextRun)
+	}
+
+	// Failures are recorded on the schedule
+	backups.fail = errors.New("telnet unreachable")
+	now = time.Date(2026, 1, 17, 2, 0, 0, 0, time.UTC)
+	s.runDue()
+
+	schedule, _ = s.GetSchedule("daily")
+	if schedule.LastStatus != scheduleStatusFailed || schedule.LastError != "telnet unreachable" || schedule.LastBackupID != "" {
+		t.Errorf("schedule = %+v", schedule)
+	}
+	if len(backups.backups) != 3 {
+		t.Errorf("backups pruned after a failed run: %v", backups.backups)
+	}
+}
@@ -0,0 +1,304 @@
+package usecase
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// Placeholder written for unknown serial numbers and ONU types by early versions
+const legacyUnknownValue = "UNKNOWN"
+
+// backupMigrations upgrade a backup document by one schema version; index i migrates version i to i+1
+var backupMigrations = []func(doc map[string]interface{}) error{
+	migrateBackupV0,
+	migrateBackupV1,
+}
+
+// migrateBackupDocument upgrades a serialized backup to model.BackupSchemaVersion.
+// Documents that are already current are returned unchanged.
+func migrateBackupDocument(data []byte) ([]byte, error) {
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backup: %w", err)
+	}
+	if doc == nil {
+		return nil, apperrors.NewValidationError("backup document must be a JSON object", nil)
+	}
+
+	version, err := backupSchemaVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+	if version == model.BackupSchemaVersion {
+		return data, nil
+	}
+
+	for v := version; v < model.BackupSchemaVersion; v++ {
+		if err := backupMigrations[v](doc); err != nil {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("failed to migrate backup document from schema version %d: %v", v, err), map[string]interface{}{
+				"schema_version": v,
+				"error":          err.Error(),
+			})
+		}
+	}
+	doc["schema_version"] = model.BackupSchemaVersion
+
+	return json.Marshal(doc)
+}
+
+// backupSchemaVersion detects the schema version of a backup document
+func backupSchemaVersion(doc map[string]interface{}) (int, error) {
+	value, ok := doc["schema_version"]
+	if !ok {
+		if _, early := doc["backup_id"]; early {
+			return 0, nil
+		}
+		if _, early := doc["configuration"]; early {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	number, ok := value.(float64)
+	if !ok || number != math.Trunc(number) || number < 0 {
+		return 0, apperrors.NewValidationError(fmt.Sprintf("invalid schema_version %v", value), map[string]interface{}{
+			"schema_version": value,
+		})
+	}
+	if number > model.BackupSchemaVersion {
+		return 0, apperrors.NewValidationError(fmt.Sprintf("unsupported backup schema version %d (supported: up to %d)", int(number), model.BackupSchemaVersion), map[string]interface{}{
+			"schema_version": int(number),
+			"supported":      model.BackupSchemaVersion,
+		})
+	}
+
+	return int(number), nil
+}
+
+// migrateBackupV0 converts the early export format, which kept the ONU location at the top level
+// and its settings in "configuration" (onu_type, vlan {svlan, cvlan}, traffic {dba_profile, tcont_id}).
+func migrateBackupV0(doc map[string]interface{}) error {
+	if id, ok := doc["backup_id"]; ok {
+		doc["id"] = id
+		delete(doc, "backup_id")
+	}
+
+	configuration, _ := doc["configuration"].(map[string]interface{})
+	delete(doc, "configuration")
+
+	metadata, _ := doc["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	if total, ok := doc["total_onus"]; ok {
+		metadata["total_onus"] = total
+		delete(doc, "total_onus")
+	}
+	doc["metadata"] = metadata
+
+	if _, ok := doc["type"]; !ok {
+		doc["type"] = "olt"
+		if _, ok := doc["pon_port"]; ok {
+			doc["type"] = "onu"
+		}
+	}
+
+	if doc["type"] != "onu" {
+		// OLT exports of this format carried no per-ONU configuration
+		if configuration == nil {
+			configuration = map[string]interface{}{"onus": []interface{}{}}
+		}
+		doc["config"] = configuration
+		return nil
+	}
+
+	if configuration == nil {
+		return fmt.Errorf("ONU backup has no configuration")
+	}
+
+	onu := map[string]interface{}{
+		"pon_port":      doc["pon_port"],
+		"onu_id":        doc["onu_id"],
+		"serial_number": configuration["serial_number"],
+		"type":          configuration["onu_type"],
+		"name":          configuration["name"],
+	}
+	delete(doc, "pon_port")
+	delete(doc, "onu_id")
+
+	if vlan, ok := configuration["vlan"].(map[string]interface{}); ok {
+		userVLAN, _ := vlan["cvlan"].(float64)
+		serviceVLAN, _ := vlan["svlan"].(float64)
+		onu["vlans"] = []interface{}{map[string]interface{}{
+			"user_vlan":    userVLAN,
+			"service_vlan": serviceVLAN,
+			"mode":         vlanMode(int(userVLAN), int(serviceVLAN)),
+		}}
+	}
+
+	if traffic, ok := configuration["traffic"].(map[string]interface{}); ok {
+		tcontID, _ := traffic["tcont_id"].(float64)
+		if tcontID == 0 {
+			tcontID = 1
+		}
+		onu["tconts"] = []interface{}{map[string]interface{}{
+			"tcont_id":     tcontID,
+			"profile_name": traffic["dba_profile"],
+		}}
+	}
+
+	doc["config"] = onu
+	return nil
+}
+
+// migrateBackupV1 derives service-ports from the VLANs of ONUs that have none (restore replays
+// service-ports) and clears the "UNKNOWN" serial number and type placeholders of early versions.
+func migrateBackupV1(doc map[string]interface{}) error {
+	config, _ := doc["config"].(map[string]interface{})
+	if config == nil {
+		return nil
+	}
+
+	switch doc["type"] {
+	case "onu":
+		migrateONUBackupV1(config)
+	case "olt":
+		onus, _ := config["onus"].([]interface{})
+		for _, item := range onus {
+			if onu, ok := item.(map[string]interface{}); ok {
+				migrateONUBackupV1(onu)
+			}
+		}
+	}
+
+	return nil
+}
+
+// migrateONUBackupV1 upgrades a single ONU configuration from schema version 1
+func migrateONUBackupV1(onu map[string]interface{}) {
+	for _, field := range []string{"serial_number", "type"} {
+		if onu[field] == legacyUnknownValue {
+			delete(onu, field)
+		}
+	}
+
+	if servicePorts, _ := onu["service_ports"].([]interface{}); len(servicePorts) > 0 {
+		return
+	}
+	vlans, _ := onu["vlans"].([]interface{})
+
+	servicePorts := make([]interface{}, 0, len(vlans))
+	for _, item := range vlans {
+		vlan, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		portID := len(servicePorts) + 1
+		servicePorts = append(servicePorts, map[string]interface{}{
+			"port_id":      portID,
+			"vport":        portID,
+			"user_vlan":    vlan["user_vlan"],
+			"service_vlan": vlan["service_vlan"],
+		})
+	}
+	if len(servicePorts) > 0 {
+		onu["service_ports"] = servicePorts
+	}
+}
+
+// vlanMode returns the VLAN mode of a service-port, as derived from the running-config
+func vlanMode(userVLAN, serviceVLAN int) string {
+	switch {
+	case userVLAN == 0:
+		return "untag"
+	case userVLAN != serviceVLAN:
+		return "translation"
+	default:
+		return "tag"
+	}
+}
+
+// validateBackupDocument checks a decoded backup against the current schema and reports all problems at once
+func validateBackupDocument(backup *model.ConfigBackup) error {
+	var problems []string
+
+	switch config := backup.Config.(type) {
+	case model.ONUConfigBackup:
+		problems = validateONUBackupConfig("config", &config, problems)
+	case model.OLTConfigBackup:
+		seen := make(map[string]bool)
+		for i := range config.ONUs {
+			onu := &config.ONUs[i]
+			path := fmt.Sprintf("config.onus[%d]", i)
+			problems = validateONUBackupConfig(path, onu, problems)
+
+			key := fmt.Sprintf("%s:%d", onu.PONPort, onu.ONUID)
+			if seen[key] {
+				problems = append(problems, fmt.Sprintf("%s: duplicate ONU gpon-onu_%s", path, key))
+			}
+			seen[key] = true
+		}
+		for i, profile := range config.DBAProfiles {
+			if profile.Name == "" {
+				problems = append(problems, fmt.Sprintf("config.dba_profiles[%d].name is required", i))
+			}
+		}
+		for i, vlan := range config.GlobalVLANs {
+			if vlan.VLANID < 1 || vlan.VLANID > 4094 {
+				problems = append(problems, fmt.Sprintf("config.global_vlans[%d].vlan_id must be 1-4094", i))
+			}
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("type must be onu or olt, got %q", backup.Type))
+	}
+
+	if len(problems) > 0 {
+		return apperrors.NewValidationError("backup document does not match the backup schema: "+strings.Join(problems, "; "), map[string]interface{}{
+			"schema_version": model.BackupSchemaVersion,
+			"errors":         problems,
+		})
+	}
+
+	return nil
+}
+
+// validateONUBackupConfig appends the problems of an ONU configuration, prefixed with its path
+func validateONUBackupConfig(path string, onu *model.ONUConfigBackup, problems []string) []string {
+	if _, _, err := parseBoardPon(onu.PONPort); err != nil {
+		problems = append(problems, fmt.Sprintf("%s.pon_port must be rack/board/pon (e.g. 1/1/1), got %q", path, onu.PONPort))
+	}
+	if onu.ONUID < 1 || onu.ONUID > 128 {
+		problems = append(problems, fmt.Sprintf("%s.onu_id must be 1-128, got %d", path, onu.ONUID))
+	}
+
+	for i, tcont := range onu.TCONTs {
+		if tcont.TCONTID < 1 || tcont.TCONTID > 8 {
+			problems = append(problems, fmt.Sprintf("%s.tconts[%d].tcont_id must be 1-8", path, i))
+		}
+	}
+	for i, gemport := range onu.GEMPorts {
+		if gemport.GEMPortID < 1 {
+			problems = append(problems, fmt.Sprintf("%s.gemports[%d].gemport_id must be positive", path, i))
+		}
+	}
+	for i, vlan := range onu.VLANs {
+		if vlan.ServiceVLAN < 1 || vlan.ServiceVLAN > 4094 || vlan.UserVLAN < 0 || vlan.UserVLAN > 4094 {
+			problems = append(problems, fmt.Sprintf("%s.vlans[%d]: VLAN IDs must be within 1-4094", path, i))
+		}
+	}
+	for i, sp := range onu.ServicePorts {
+		if sp.PortID < 1 {
+			problems = append(problems, fmt.Sprintf("%s.service_ports[%d].port_id must be positive", path, i))
+		}
+		if sp.ServiceVLAN < 1 || sp.ServiceVLAN > 4094 || sp.UserVLAN < 0 || sp.UserVLAN > 4094 {
+			problems = append(problems, fmt.Sprintf("%s.service_ports[%d]: VLAN IDs must be within 1-4094", path, i))
+		}
+	}
+
+	return problems
+}
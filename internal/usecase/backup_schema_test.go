@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestImportBackup_CurrentSchema(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	source := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil)
+
+	original, err := source.BackupOLT(context.Background(), "staging", []string{"staging"})
+	if err != nil {
+		t.Fatalf("BackupOLT() error = %v", err)
+	}
+	exported, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Import into another instance
+	uc := NewConfigBackupUsecase(newBackupTestConfig(t), nil, nil, nil)
+	imported, err := uc.ImportBackup(exported)
+	if err != nil {
+		t.Fatalf("ImportBackup() error = %v", err)
+	}
+
+	if imported.ID == original.ID {
+		t.Error("imported backup kept the original ID")
+	}
+	if imported.Metadata.CustomFields["imported_from"] != original.ID {
+		t.Errorf("imported_from = %q, want %q", imported.Metadata.CustomFields["imported_from"], original.ID)
+	}
+	if imported.SchemaVersion != model.BackupSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", imported.SchemaVersion, model.BackupSchemaVersion)
+	}
+
+	backups, err := uc.ListBackups("", 0)
+	if err != nil {
+		t.Fatalf("ListBackups() error = %v", err)
+	}
+	if len(backups) != 1 || backups[0].ID != imported.ID || backups[0].ONUCount != 2 {
+		t.Errorf("ListBackups() = %+v, want the imported backup with 2 ONUs", backups)
+	}
+
+	// A corrupted export fails the checksum
+	tampered := strings.Replace(string(exported), "ZTEGC0000001", "ZTEGC0000009", 1)
+	if _, err := uc.ImportBackup([]byte(tampered)); !isValidationError(err) || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("ImportBackup(tampered) error = %v, want checksum validation error", err)
+	}
+}
+
+func TestImportBackup_MigratesOlderSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		check    func(t *testing.T, onu model.ONUConfigBackup)
+	}{
+		{
+			name: "version 0 export",
+			document: `{
+				"backup_id": "backup_20260112_100000_onu_1_1_1_5",
+				"type": "onu",
+				"pon_port": "1/1/1",
+				"onu_id": 5,
+				"timestamp": "2026-01-12T10:00:00Z",
+				"configuration": {
+					"serial_number": "ZTEG1234ABCD",
+					"onu_type": "ZTE-F660",
+					"name": "Customer_001",
+					"vlan": {"svlan": 100, "cvlan": 200},
+					"traffic": {"dba_profile": "100M_Profile", "tcont_id": 1}
+				}
+			}`,
+			check: func(t *testing.T, onu model.ONUConfigBackup) {
+				if onu.PONPort != "1/1/1" || onu.ONUID != 5 || onu.SerialNumber != "ZTEG1234ABCD" || onu.Type != "ZTE-F660" {
+					t.Errorf("ONU = %+v", onu)
+				}
+				if len(onu.VLANs) != 1 || onu.VLANs[0].Mode != "translation" {
+					t.Errorf("VLANs = %+v, want one translation VLAN", onu.VLANs)
+				}
+				if len(onu.ServicePorts) != 1 || onu.ServicePorts[0].UserVLAN != 200 || onu.ServicePorts[0].ServiceVLAN != 100 {
+					t.Errorf("ServicePorts = %+v, want user-vlan 200 vlan 100", onu.ServicePorts)
+				}
+				if len(onu.TCONTs) != 1 || onu.TCONTs[0].ProfileName != "100M_Profile" {
+					t.Errorf("TCONTs = %+v", onu.TCONTs)
+				}
+			},
+		},
+		{
+			name: "version 1 document",
+			document: `{
+				"id": "8d0f6c1e-0000-4000-8000-000000000001",
+				"type": "onu",
+				"timestamp": "2025-06-01T00:00:00Z",
+				"metadata": {"created_by": "system", "source": "10.0.0.1", "version": "v2.1.0"},
+				"config": {
+					"pon_port": "1/2/3",
+					"onu_id": 7,
+					"serial_number": "UNKNOWN",
+					"type": "UNKNOWN",
+					"vlans": [{"user_vlan": 0, "service_vlan": 300, "mode": "untag"}]
+				}
+			}`,
+			check: func(t *testing.T, onu model.ONUConfigBackup) {
+				if onu.SerialNumber != "" || onu.Type != "" {
+					t.Errorf("placeholders not cleared: %q/%q", onu.SerialNumber, onu.Type)
+				}
+				if len(onu.ServicePorts) != 1 || onu.ServicePorts[0].PortID != 1 || onu.ServicePorts[0].ServiceVLAN != 300 {
+					t.Errorf("ServicePorts = %+v, want service-port 1 vlan 300", onu.ServicePorts)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewConfigBackupUsecase(newBackupTestConfig(t), nil, nil, nil)
+
+			imported, err := uc.ImportBackup([]byte(tt.document))
+			if err != nil {
+				t.Fatalf("ImportBackup() error = %v", err)
+			}
+
+			stored, err := uc.GetBackup(imported.ID)
+			if err != nil {
+				t.Fatalf("GetBackup() error = %v", err)
+			}
+			if stored.SchemaVersion != model.BackupSchemaVersion || stored.Metadata.Checksum == "" {
+				t.Errorf("stored backup: schema_version %d, checksum %q", stored.SchemaVersion, stored.Metadata.Checksum)
+			}
+
+			onu, ok := stored.Config.(model.ONUConfigBackup)
+			if !ok {
+				t.Fatalf("Config type = %T, want model.ONUConfigBackup", stored.Config)
+			}
+			tt.check(t, onu)
+		})
+	}
+}
+
+func TestImportBackup_RejectsInvalidDocuments(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		want     string
+	}{
+		{name: "not JSON", document: `backup`, want: "invalid backup document"},
+		{name: "not an object", document: `[1, 2]`, want: "invalid backup document"},
+		{name: "newer schema", document: `{"schema_version": 99, "type": "onu", "config": {}}`, want: "unsupported backup schema version 99"},
+		{name: "unknown type", document: `{"schema_version": 2, "type": "switch", "config": {}}`, want: "type must be onu or olt"},
+		{
+			name:     "invalid ONU",
+			document: `{"schema_version": 2, "type": "onu", "config": {"pon_port": "1-1-1", "onu_id": 200, "tconts": [{"tcont_id": 9}]}}`,
+			want:     "config.pon_port must be rack/board/pon",
+		},
+		{
+			name: "duplicate ONUs",
+			document: `{"schema_version": 2, "type": "olt", "config": {"onus": [
+				{"pon_port": "1/1/1", "onu_id": 1}, {"pon_port": "1/1/1", "onu_id": 1}]}}`,
+			want: "config.onus[1]: duplicate ONU gpon-onu_1/1/1:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewConfigBackupUsecase(newBackupTestConfig(t), nil, nil, nil)
+
+			_, err := uc.ImportBackup([]byte(tt.document))
+			if !isValidationError(err) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ImportBackup() error = %v, want validation error containing %q", err, tt.want)
+			}
+
+			if backups, _ := uc.ListBackups("", 0); len(backups) != 0 {
+				t.Errorf("invalid document was stored: %+v", backups)
+			}
+		})
+	}
+}
+
+// isValidationError reports whether err is an apperrors validation error
+func isValidationError(err error) bool {
+	var appErr *apperrors.AppError
+	return errors.As(err, &appErr) && appErr.Type == apperrors.ErrorTypeValidation
+}
@@ -4,355 +4,93 @@ import (
 	"context"
 	"fmt"
 	"regexp"
-	"time"
 
-	"github.com/rs/zerolog/log"
-	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
 	"github.com/s4lfanet/go-api-c320/internal/model"
-	"github.com/s4lfanet/go-api-c320/internal/repository"
 )
 
+// maxBatchTargets is the largest number of ONUs of a batch operation
+const maxBatchTargets = 1000
+
 // BatchOperationsUsecaseInterface defines business logic for batch ONU operations
 type BatchOperationsUsecaseInterface interface {
-	BatchRebootONUs(ctx context.Context, req *model.BatchONURebootRequest) (*model.BatchONURebootResponse, error)
-	BatchBlockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.BatchONUBlockResponse, error)
-	BatchUnblockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.BatchONUBlockResponse, error)
-	BatchDeleteONUs(ctx context.Context, req *model.BatchONUDeleteRequest) (*model.BatchONUDeleteResponse, error)
-	BatchUpdateDescriptions(ctx context.Context, req *model.BatchONUDescriptionRequest) (*model.BatchONUDescriptionResponse, error)
+	BatchRebootONUs(ctx context.Context, req *model.BatchONURebootRequest) (*model.Job, error)
+	BatchBlockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.Job, error)
+	BatchUnblockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.Job, error)
+	BatchDeleteONUs(ctx context.Context, req *model.BatchONUDeleteRequest) (*model.Job, error)
+	BatchUpdateDescriptions(ctx context.Context, req *model.BatchONUDescriptionRequest) (*model.Job, error)
 }
 
-// BatchOperationsUsecase implements batch ONU operations business logic
+// BatchOperationsUsecase implements batch ONU operations business logic. Batch operations run as
+// background jobs, which apply the operation to one ONU after another (see NewJobExecutor).
 type BatchOperationsUsecase struct {
-	telnetSessionManager *repository.TelnetSessionManager
-	onuMgmtUsecase       ONUManagementUsecaseInterface
-	cfg                  *config.Config
+	jobs JobSubmitter
 }
 
-// NewBatchOperationsUsecase creates a new batch operations usecase
-func NewBatchOperationsUsecase(
-	telnetSessionManager *repository.TelnetSessionManager,
-	onuMgmtUsecase ONUManagementUsecaseInterface,
-	cfg *config.Config,
-) BatchOperationsUsecaseInterface {
+// NewBatchOperationsUsecase creates a new batch operations usecase that submits its jobs to jobs
+func NewBatchOperationsUsecase(jobs JobSubmitter) BatchOperationsUsecaseInterface {
 	return &BatchOperationsUsecase{
-		telnetSessionManager: telnetSessionManager,
-		onuMgmtUsecase:       onuMgmtUsecase,
-		cfg:                  cfg,
+		jobs: jobs,
 	}
 }
 
-// BatchRebootONUs reboots multiple ONUs in parallel
-func (u *BatchOperationsUsecase) BatchRebootONUs(ctx context.Context, req *model.BatchONURebootRequest) (*model.BatchONURebootResponse, error) {
-	startTime := time.Now()
-
-	// Validate targets
+// BatchRebootONUs submits a job that reboots multiple ONUs
+func (u *BatchOperationsUsecase) BatchRebootONUs(ctx context.Context, req *model.BatchONURebootRequest) (*model.Job, error) {
 	if err := u.validateBatchTargets(req.Targets); err != nil {
-		return nil, err
+		return nil, apperrors.NewValidationError(err.Error(), nil)
 	}
 
-	log.Info().Int("target_count", len(req.Targets)).Msg("Starting batch ONU reboot operation")
-
-	// Execute operations sequentially (Telnet session is single-threaded)
-	results := make([]model.BatchOperationResult, 0, len(req.Targets))
-	successCount := 0
-	failureCount := 0
-
-	for _, target := range req.Targets {
-		rebootReq := &model.ONURebootRequest{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		resp, err := u.onuMgmtUsecase.RebootONU(ctx, rebootReq)
-
-		result := model.BatchOperationResult{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		if err != nil {
-			result.Success = false
-			result.Message = "Reboot failed"
-			result.Error = err.Error()
-			failureCount++
-			log.Warn().
-				Str("pon_port", target.PONPort).
-				Int("onu_id", target.ONUID).
-				Err(err).
-				Msg("Failed to reboot ONU in batch")
-		} else {
-			result.Success = resp.Success
-			result.Message = resp.Message
-			if resp.Success {
-				successCount++
-			} else {
-				failureCount++
-			}
-		}
-
-		results = append(results, result)
-	}
-
-	executionTime := time.Since(startTime).Milliseconds()
-
-	response := &model.BatchONURebootResponse{
-		TotalTargets:    len(req.Targets),
-		SuccessCount:    successCount,
-		FailureCount:    failureCount,
-		Results:         results,
-		ExecutionTimeMs: executionTime,
-	}
-
-	log.Info().
-		Int("total", len(req.Targets)).
-		Int("success", successCount).
-		Int("failure", failureCount).
-		Int64("execution_time_ms", executionTime).
-		Msg("Batch ONU reboot operation completed")
-
-	return response, nil
+	return u.jobs.SubmitJob(ctx, model.JobTypeBatchReboot, req, onuJobTargets(req.Targets))
 }
 
-// BatchBlockONUs blocks multiple ONUs
-func (u *BatchOperationsUsecase) BatchBlockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.BatchONUBlockResponse, error) {
-	startTime := time.Now()
-
-	// Validate targets
+// BatchBlockONUs submits a job that blocks or unblocks multiple ONUs, depending on req.Block
+func (u *BatchOperationsUsecase) BatchBlockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.Job, error) {
 	if err := u.validateBatchTargets(req.Targets); err != nil {
-		return nil, err
+		return nil, apperrors.NewValidationError(err.Error(), nil)
 	}
 
-	action := "block"
+	jobType := model.JobTypeBatchBlock
 	if !req.Block {
-		action = "unblock"
-	}
-
-	log.Info().
-		Int("target_count", len(req.Targets)).
-		Str("action", action).
-		Msg("Starting batch ONU block/unblock operation")
-
-	// Execute operations sequentially
-	results := make([]model.BatchOperationResult, 0, len(req.Targets))
-	successCount := 0
-	failureCount := 0
-
-	for _, target := range req.Targets {
-		blockReq := &model.ONUBlockRequest{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-			Block:   req.Block,
-		}
-
-		var resp *model.ONUBlockResponse
-		var err error
-
-		if req.Block {
-			resp, err = u.onuMgmtUsecase.BlockONU(ctx, blockReq)
-		} else {
-			resp, err = u.onuMgmtUsecase.UnblockONU(ctx, blockReq)
-		}
-
-		result := model.BatchOperationResult{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		if err != nil {
-			result.Success = false
-			result.Message = fmt.Sprintf("%s failed", action)
-			result.Error = err.Error()
-			failureCount++
-			log.Warn().
-				Str("pon_port", target.PONPort).
-				Int("onu_id", target.ONUID).
-				Str("action", action).
-				Err(err).
-				Msg("Failed to block/unblock ONU in batch")
-		} else {
-			result.Success = resp.Success
-			result.Message = resp.Message
-			if resp.Success {
-				successCount++
-			} else {
-				failureCount++
-			}
-		}
-
-		results = append(results, result)
+		jobType = model.JobTypeBatchUnblock
 	}
-
-	executionTime := time.Since(startTime).Milliseconds()
-
-	response := &model.BatchONUBlockResponse{
-		Blocked:         req.Block,
-		TotalTargets:    len(req.Targets),
-		SuccessCount:    successCount,
-		FailureCount:    failureCount,
-		Results:         results,
-		ExecutionTimeMs: executionTime,
-	}
-
-	log.Info().
-		Int("total", len(req.Targets)).
-		Int("success", successCount).
-		Int("failure", failureCount).
-		Str("action", action).
-		Int64("execution_time_ms", executionTime).
-		Msg("Batch ONU block/unblock operation completed")
-
-	return response, nil
+	return u.jobs.SubmitJob(ctx, jobType, req, onuJobTargets(req.Targets))
 }
 
-// BatchUnblockONUs is a convenience method for unblocking ONUs
-func (u *BatchOperationsUsecase) BatchUnblockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.BatchONUBlockResponse, error) {
+// BatchUnblockONUs submits a job that unblocks multiple ONUs
+func (u *BatchOperationsUsecase) BatchUnblockONUs(ctx context.Context, req *model.BatchONUBlockRequest) (*model.Job, error) {
 	req.Block = false
 	return u.BatchBlockONUs(ctx, req)
 }
 
-// BatchDeleteONUs deletes multiple ONUs
-func (u *BatchOperationsUsecase) BatchDeleteONUs(ctx context.Context, req *model.BatchONUDeleteRequest) (*model.BatchONUDeleteResponse, error) {
-	startTime := time.Now()
-
-	// Validate targets
+// BatchDeleteONUs submits a job that deletes multiple ONUs
+func (u *BatchOperationsUsecase) BatchDeleteONUs(ctx context.Context, req *model.BatchONUDeleteRequest) (*model.Job, error) {
 	if err := u.validateBatchTargets(req.Targets); err != nil {
-		return nil, err
-	}
-
-	log.Info().Int("target_count", len(req.Targets)).Msg("Starting batch ONU delete operation")
-
-	// Execute operations sequentially
-	results := make([]model.BatchOperationResult, 0, len(req.Targets))
-	successCount := 0
-	failureCount := 0
-
-	for _, target := range req.Targets {
-		deleteReq := &model.ONUDeleteRequest{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		resp, err := u.onuMgmtUsecase.DeleteONU(ctx, deleteReq)
-
-		result := model.BatchOperationResult{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		if err != nil {
-			result.Success = false
-			result.Message = "Delete failed"
-			result.Error = err.Error()
-			failureCount++
-			log.Warn().
-				Str("pon_port", target.PONPort).
-				Int("onu_id", target.ONUID).
-				Err(err).
-				Msg("Failed to delete ONU in batch")
-		} else {
-			result.Success = resp.Success
-			result.Message = resp.Message
-			if resp.Success {
-				successCount++
-			} else {
-				failureCount++
-			}
-		}
-
-		results = append(results, result)
-	}
-
-	executionTime := time.Since(startTime).Milliseconds()
-
-	response := &model.BatchONUDeleteResponse{
-		TotalTargets:    len(req.Targets),
-		SuccessCount:    successCount,
-		FailureCount:    failureCount,
-		Results:         results,
-		ExecutionTimeMs: executionTime,
+		return nil, apperrors.NewValidationError(err.Error(), nil)
 	}
 
-	log.Info().
-		Int("total", len(req.Targets)).
-		Int("success", successCount).
-		Int("failure", failureCount).
-		Int64("execution_time_ms", executionTime).
-		Msg("Batch ONU delete operation completed")
-
-	return response, nil
+	return u.jobs.SubmitJob(ctx, model.JobTypeBatchDelete, req, onuJobTargets(req.Targets))
 }
 
-// BatchUpdateDescriptions updates descriptions for multiple ONUs
-func (u *BatchOperationsUsecase) BatchUpdateDescriptions(ctx context.Context, req *model.BatchONUDescriptionRequest) (*model.BatchONUDescriptionResponse, error) {
-	startTime := time.Now()
-
-	// Validate targets
+// BatchUpdateDescriptions submits a job that updates the descriptions of multiple ONUs
+func (u *BatchOperationsUsecase) BatchUpdateDescriptions(ctx context.Context, req *model.BatchONUDescriptionRequest) (*model.Job, error) {
 	if err := u.validateBatchDescriptionTargets(req.Targets); err != nil {
-		return nil, err
+		return nil, apperrors.NewValidationError(err.Error(), nil)
 	}
 
-	log.Info().Int("target_count", len(req.Targets)).Msg("Starting batch ONU description update operation")
-
-	// Execute operations sequentially
-	results := make([]model.BatchOperationResult, 0, len(req.Targets))
-	successCount := 0
-	failureCount := 0
-
-	for _, target := range req.Targets {
-		descReq := &model.ONUDescriptionRequest{
-			PONPort:     target.PONPort,
-			ONUID:       target.ONUID,
-			Description: target.Description,
-		}
-
-		resp, err := u.onuMgmtUsecase.UpdateDescription(ctx, descReq)
-
-		result := model.BatchOperationResult{
-			PONPort: target.PONPort,
-			ONUID:   target.ONUID,
-		}
-
-		if err != nil {
-			result.Success = false
-			result.Message = "Description update failed"
-			result.Error = err.Error()
-			failureCount++
-			log.Warn().
-				Str("pon_port", target.PONPort).
-				Int("onu_id", target.ONUID).
-				Err(err).
-				Msg("Failed to update ONU description in batch")
-		} else {
-			result.Success = resp.Success
-			result.Message = resp.Message
-			if resp.Success {
-				successCount++
-			} else {
-				failureCount++
-			}
-		}
-
-		results = append(results, result)
+	targets := make([]model.JobTarget, len(req.Targets))
+	for i, target := range req.Targets {
+		targets[i] = model.JobTarget{PONPort: target.PONPort, ONUID: target.ONUID, Description: target.Description}
 	}
+	return u.jobs.SubmitJob(ctx, model.JobTypeBatchDescriptions, req, targets)
+}
 
-	executionTime := time.Since(startTime).Milliseconds()
-
-	response := &model.BatchONUDescriptionResponse{
-		TotalTargets:    len(req.Targets),
-		SuccessCount:    successCount,
-		FailureCount:    failureCount,
-		Results:         results,
-		ExecutionTimeMs: executionTime,
+// onuJobTargets converts the targets of a batch request to job targets
+func onuJobTargets(targets []model.ONUTarget) []model.JobTarget {
+	jobTargets := make([]model.JobTarget, len(targets))
+	for i, target := range targets {
+		jobTargets[i] = model.JobTarget{PONPort: target.PONPort, ONUID: target.ONUID}
 	}
-
-	log.Info().
-		Int("total", len(req.Targets)).
-		Int("success", successCount).
-		Int("failure", failureCount).
-		Int64("execution_time_ms", executionTime).
-		Msg("Batch ONU description update operation completed")
-
-	return response, nil
+	return jobTargets
 }
 
 // ============================================
@@ -365,8 +103,8 @@ func (u *BatchOperationsUsecase) validateBatchTargets(targets []model.ONUTarget)
 		return fmt.Errorf("at least one target is required")
 	}
 
-	if len(targets) > 50 {
-		return fmt.Errorf("maximum 50 targets allowed per batch operation, got %d", len(targets))
+	if len(targets) > maxBatchTargets {
+		return fmt.Errorf("maximum %d targets allowed per batch operation, got %d", maxBatchTargets, len(targets))
 	}
 
 	// Validate each target
@@ -401,8 +139,8 @@ func (u *BatchOperationsUsecase) validateBatchDescriptionTargets(targets []model
 		return fmt.Errorf("at least one target is required")
 	}
 
-	if len(targets) > 50 {
-		return fmt.Errorf("maximum 50 targets allowed per batch operation, got %d", len(targets))
+	if len(targets) > maxBatchTargets {
+		return fmt.Errorf("maximum %d targets allowed per batch operation, got %d", maxBatchTargets, len(targets))
 	}
 
 	// Validate each target
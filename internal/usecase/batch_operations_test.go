@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestBatchOperationsUsecase_Simulator(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	jobs, submitter := startJobUsecase(t, newMemoryJobStore(), nil, NewJobExecutor(NewONUManagementUsecase(manager, nil, nil), nil, nil))
+	uc := NewBatchOperationsUsecase(submitter)
+	ctx := context.Background()
+	state := server.State()
+
+	targets := []model.ONUTarget{{PONPort: "1/1/1", ONUID: 1}, {PONPort: "1/1/1", ONUID: 2}, {PONPort: "1/1/1", ONUID: 9}}
+
+	job, err := uc.BatchRebootONUs(ctx, &model.BatchONURebootRequest{Targets: targets})
+	if err != nil {
+		t.Fatalf("BatchRebootONUs() error = %v", err)
+	}
+	reboot := waitForJob(t, jobs, job.ID)
+	if reboot.Progress.Succeeded != 2 || reboot.Progress.Failed != 1 || reboot.Targets[2].Status != model.JobTargetFailed {
+		t.Errorf("BatchRebootONUs() = %+v, want ONU 9 to fail", reboot)
+	}
+
+	job, err = uc.BatchBlockONUs(ctx, &model.BatchONUBlockRequest{Targets: targets[:2], Block: true})
+	if err != nil || job.Type != model.JobTypeBatchBlock {
+		t.Fatalf("BatchBlockONUs() = %+v, %v", job, err)
+	}
+	if block := waitForJob(t, jobs, job.ID); block.Status != model.JobCompleted {
+		t.Fatalf("BatchBlockONUs() = %+v", block)
+	}
+	for _, id := range []int{1, 2} {
+		if onu, _ := state.ONU("1/1/1", id); !onu.Disabled || onu.Reboots != 1 {
+			t.Errorf("ONU %d = %+v, want disabled after one reboot", id, onu)
+		}
+	}
+
+	job, err = uc.BatchUpdateDescriptions(ctx, &model.BatchONUDescriptionRequest{Targets: []model.ONUDescriptionTarget{
+		{PONPort: "1/1/1", ONUID: 1, Description: "Tower A"},
+		{PONPort: "1/1/1", ONUID: 3, Description: "Tower C"},
+	}})
+	if err != nil {
+		t.Fatalf("BatchUpdateDescriptions() error = %v", err)
+	}
+	if descriptions := waitForJob(t, jobs, job.ID); descriptions.Progress.Succeeded != 2 {
+		t.Fatalf("BatchUpdateDescriptions() = %+v", descriptions)
+	}
+	if onu, _ := state.ONU("1/1/1", 3); onu.Name != "Tower C" {
+		t.Errorf("Name = %q, want Tower C", onu.Name)
+	}
+
+	job, err = uc.BatchDeleteONUs(ctx, &model.BatchONUDeleteRequest{Targets: targets[1:]})
+	if err != nil {
+		t.Fatalf("BatchDeleteONUs() error = %v", err)
+	}
+	if deleted := waitForJob(t, jobs, job.ID); deleted.Progress.Succeeded != 1 || deleted.Progress.Failed != 1 {
+		t.Errorf("BatchDeleteONUs() = %+v", deleted)
+	}
+	if onus := state.ONUs("1/1/1"); len(onus) != 2 {
+		t.Errorf("ONUs() = %d, want 2", len(onus))
+	}
+
+	// Invalid batches are rejected before a job is submitted
+	var appErr *apperrors.AppError
+	duplicate := []model.ONUTarget{{PONPort: "1/1/1", ONUID: 1}, {PONPort: "1/1/1", ONUID: 1}}
+	if _, err := uc.BatchRebootONUs(ctx, &model.BatchONURebootRequest{Targets: duplicate}); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeValidation {
+		t.Errorf("BatchRebootONUs(duplicate) error = %v, want validation error", err)
+	}
+}
@@ -54,8 +54,8 @@ func (u *cardUsecase) GetAllCards(ctx context.Context) ([]*model.CardInfo, error
 	baseOID := "1.3.6.1.4.1.3902.1015.2.1.1.3.1"
 
 	err := u.snmpRepository.Walk(baseOID, func(pdu gosnmp.SnmpPDU) error {
-		// Extract rack, shelf, slot from OID
-		oidParts := strings.Split(pdu.Name, ".")
+		// Extract rack, shelf, slot from OID (gosnmp returns names with a leading dot, baseOID has none)
+		oidParts := strings.Split(strings.TrimPrefix(pdu.Name, "."), ".")
 
 		// OID format: ...3902.1015.2.1.1.3.1.{col}.{rack}.{shelf}.{slot}
 		// Base OID parts count
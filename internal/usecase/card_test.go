@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+func TestCardUsecase_Simulator(t *testing.T) {
+	for _, version := range []config.FirmwareVersion{config.FirmwareV21, config.FirmwareV22} {
+		t.Run(string(version), func(t *testing.T) {
+			_, cfg := newSimulatedSNMP(t, version)
+			u := NewCardUsecase(repository.NewSnmpRepository(cfg.SnmpCfg), &mockRedisRepository{}, cfg)
+
+			cards, err := u.GetAllCards(context.Background())
+			if err != nil {
+				t.Fatalf("GetAllCards() error = %v", err)
+			}
+			if len(cards) != 4 {
+				t.Fatalf("GetAllCards() returned %d cards, want 4", len(cards))
+			}
+			sort.Slice(cards, func(i, j int) bool { return cards[i].Slot < cards[j].Slot })
+
+			want := model.CardInfo{
+				Rack: 1, Shelf: 1, Slot: 1, CardType: "GTGO", SerialNumber: "ZTE0A1B2C3D01",
+				HardwareVer: "v1", SoftwareVer: "v210", Status: "active", Description: "desc_0",
+			}
+			if *cards[0] != want {
+				t.Errorf("GetAllCards()[0] = %+v, want %+v", *cards[0], want)
+			}
+			if cards[2].Status != "online" {
+				t.Errorf("slot 3 status = %q, want online", cards[2].Status)
+			}
+
+			card, err := u.GetCard(context.Background(), 1, 1, 1)
+			if err != nil {
+				t.Fatalf("GetCard() error = %v", err)
+			}
+			if card.CardType != "GTGO" || card.SerialNumber != "ZTE0A1B2C3D01" || card.Status != "active" {
+				t.Errorf("GetCard() = %+v", *card)
+			}
+		})
+	}
+}
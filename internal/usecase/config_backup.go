@@ -1,27 +1,37 @@
 package usecase
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gosnmp/gosnmp"
 	"github.com/rs/zerolog/log"
 	"github.com/s4lfanet/go-api-c320/config"
 	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
 	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
 )
 
 // ConfigBackupUsecase handles configuration backup and restore operations
 type ConfigBackupUsecase interface {
-	// Backup single ONU configuration
-	BackupONU(ponPort string, onuID int, description string, tags []string) (*model.ConfigBackup, error)
+	// Backup single ONU configuration; the caller of ctx is recorded as its creator
+	BackupONU(ctx context.Context, ponPort string, onuID int, description string, tags []string) (*model.ConfigBackup, error)
 
-	// Backup entire OLT configuration (all ONUs)
-	BackupOLT(description string, tags []string) (*model.ConfigBackup, error)
+	// Backup entire OLT configuration (all ONUs); the caller of ctx is recorded as its creator
+	BackupOLT(ctx context.Context, description string, tags []string) (*model.ConfigBackup, error)
 
 	// List all backups with optional filters
 	ListBackups(backupType string, limit int) ([]*model.BackupListItem, error)
@@ -32,85 +42,81 @@ type ConfigBackupUsecase interface {
 	// Delete backup by ID
 	DeleteBackup(backupID string) error
 
-	// Restore configuration from backup
-	RestoreFromBackup(req *model.RestoreRequest) (*model.RestoreResult, error)
+	// Restore configuration from backup; the restore is not aborted when ctx is canceled
+	RestoreFromBackup(ctx context.Context, req *model.RestoreRequest) (*model.RestoreResult, error)
 
 	// Export backup to file
 	ExportBackup(backupID string, outputPath string) error
 
-	// Import backup from file
-	ImportBackup(inputPath string) (*model.ConfigBackup, error)
+	// Import a backup document (e.g. exported by another instance) under a new ID
+	ImportBackup(data []byte) (*model.ConfigBackup, error)
+
+	// Get the parsed running-config of the OLT together with its raw output
+	GetRunningConfig() (*model.RunningConfig, string, error)
+
+	// Compare a backup against the live OLT configuration
+	DetectDrift(backupID string) (*model.ConfigDrift, error)
+
+	// Compare two stored backups
+	DiffBackups(fromID, toID string) (*model.ConfigDiff, error)
+
+	// Back up an ONU before a destructive operation (PreChangeSnapshotter)
+	SnapshotONU(ctx context.Context, ponPort string, onuID int, operation string) (*model.PreChangeSnapshot, error)
 }
 
 type configBackupUsecase struct {
-	cfg              *config.Config
-	onuMgmtUsecase   ONUManagementUsecaseInterface
-	vlanUsecase      VLANUsecaseInterface
-	trafficUsecase   TrafficUsecaseInterface
-	provisionUsecase ProvisionUseCaseInterface
-	backupDir        string
+	cfg                  *config.Config
+	snmpRepository       repository.SnmpRepositoryInterface
+	telnetSessionManager *repository.TelnetSessionManager
+	backupStore          repository.BackupStore
+	indexMu              sync.Mutex // Serializes reads and writes of the backup index
 }
 
-// NewConfigBackupUsecase creates a new config backup usecase
+// backupIndexID names the object that holds the list entries of all backups of the store, so that
+// listing does not load, decrypt and parse every backup
+const backupIndexID = "index"
+
+// NewConfigBackupUsecase creates a new config backup usecase.
+// If backupStore is nil, backups are stored gzip-compressed in the configured backup directory.
 func NewConfigBackupUsecase(
 	cfg *config.Config,
-	onuMgmtUsecase ONUManagementUsecaseInterface,
-	vlanUsecase VLANUsecaseInterface,
-	trafficUsecase TrafficUsecaseInterface,
-	provisionUsecase ProvisionUseCaseInterface,
+	backupStore repository.BackupStore,
+	snmpRepository repository.SnmpRepositoryInterface,
+	telnetSessionManager *repository.TelnetSessionManager,
 ) ConfigBackupUsecase {
-	// Set backup directory from config or use default
-	backupDir := "/var/lib/go-snmp-olt/backups"
-	if cfg != nil && cfg.OltCfg.BackupDir != "" {
-		backupDir = cfg.OltCfg.BackupDir
-	}
-
-	// Create backup directory if it doesn't exist
-	if err := os.MkdirAll(backupDir, 0755); err != nil {
-		log.Error().Err(err).Str("dir", backupDir).Msg("Failed to create backup directory")
+	if backupStore == nil {
+		local, _ := repository.NewLocalBackupStore(backupDirectory(cfg))
+		backupStore, _ = repository.NewEncodedBackupStore(local, nil)
 	}
 
 	return &configBackupUsecase{
-		cfg:              cfg,
-		onuMgmtUsecase:   onuMgmtUsecase,
-		vlanUsecase:      vlanUsecase,
-		trafficUsecase:   trafficUsecase,
-		provisionUsecase: provisionUsecase,
-		backupDir:        backupDir,
+		cfg:                  cfg,
+		snmpRepository:       snmpRepository,
+		telnetSessionManager: telnetSessionManager,
+		backupStore:          backupStore,
 	}
 }
 
 // BackupONU creates a backup of single ONU configuration
-func (u *configBackupUsecase) BackupONU(ponPort string, onuID int, description string, tags []string) (*model.ConfigBackup, error) {
+func (u *configBackupUsecase) BackupONU(ctx context.Context, ponPort string, onuID int, description string, tags []string) (*model.ConfigBackup, error) {
 	log.Info().
 		Str("pon_port", ponPort).
 		Int("onu_id", onuID).
+		Str("user", backupCreator(ctx)).
 		Msg("Creating ONU configuration backup")
 
 	// Get ONU details
-	onuConfig, err := u.getONUConfiguration(ponPort, onuID)
+	onuConfig, err := u.getONUConfiguration(ctx, ponPort, onuID)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get ONU configuration")
 		return nil, apperrors.NewInternalError("failed to get ONU configuration", err)
 	}
 
 	// Create backup object
-	backup := &model.ConfigBackup{
-		ID:          uuid.New().String(),
-		Type:        "onu",
-		Timestamp:   time.Now(),
-		Description: description,
-		Metadata: model.BackupMetadata{
-			CreatedBy: "system",
-			Source:    u.cfg.OltCfg.Host,
-			Version:   "v2.1.0", // TODO: Get from OLT
-			Tags:      tags,
-		},
-		Config: onuConfig,
-	}
+	backup := u.newONUBackup(ctx, onuConfig, description, tags)
 
 	// Save backup to file
-	if err := u.saveBackupToFile(backup); err != nil {
+	if err := u.saveBackup(backup); err != nil {
 		log.Error().Err(err).Msg("Failed to save backup to file")
 		return nil, apperrors.NewInternalError("failed to save backup", err)
 	}
@@ -124,78 +130,155 @@ func (u *configBackupUsecase) BackupONU(ponPort string, onuID int, description s
 	return backup, nil
 }
 
+// SnapshotONU backs up an ONU before a destructive operation, tagged with the operation name
+func (u *configBackupUsecase) SnapshotONU(ctx context.Context, ponPort string, onuID int, operation string) (*model.PreChangeSnapshot, error) {
+	onuConfig, err := u.getONUConfiguration(ctx, ponPort, onuID)
+	if err != nil {
+		return nil, err
+	}
+
+	description := fmt.Sprintf("Automatic snapshot before %s on gpon-onu_%s:%d", operation, ponPort, onuID)
+	backup := u.newONUBackup(ctx, onuConfig, description, []string{model.BackupTagPreChange, operation})
+
+	if err := u.saveBackup(backup); err != nil {
+		return nil, fmt.Errorf("failed to save snapshot: %w", err)
+	}
+
+	return &model.PreChangeSnapshot{
+		BackupID:        backup.ID,
+		Operation:       operation,
+		RestoreEndpoint: "POST /config/restore/" + backup.ID,
+	}, nil
+}
+
 // BackupOLT creates a backup of entire OLT configuration
-func (u *configBackupUsecase) BackupOLT(description string, tags []string) (*model.ConfigBackup, error) {
-	log.Info().Msg("Creating OLT configuration backup (all ONUs)")
+// ONUs are discovered per board/PON via SNMP; their TCONT, GEM port and service-port
+// configuration, DBA profiles and VLANs are taken from the parsed running-config.
+func (u *configBackupUsecase) BackupOLT(ctx context.Context, description string, tags []string) (*model.ConfigBackup, error) {
+	createdBy := backupCreator(ctx)
+	log.Info().Str("user", createdBy).Msg("Creating OLT configuration backup (all ONUs)")
+
+	// Collecting all ONUs takes a while; finish the backup even if the client disconnects
+	ctx = context.WithoutCancel(ctx)
+
+	var warnings []string
+	var runningConfig *model.RunningConfig
+	if u.telnetSessionManager != nil {
+		parsed, _, err := u.telnetSessionManager.GetRunningConfig(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to read running-config, backup will only contain SNMP data")
+			warnings = append(warnings, fmt.Sprintf("running-config: %v", err))
+		} else {
+			runningConfig = parsed
+		}
+	}
+
+	oltConfig, collectWarnings, err := u.collectOLTConfiguration(runningConfig)
+	warnings = append(warnings, collectWarnings...)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to collect OLT configuration")
+		return nil, apperrors.NewInternalError("failed to collect OLT configuration", err)
+	}
+
+	metadata := model.BackupMetadata{
+		CreatedBy: createdBy,
+		Source:    u.cfg.OltCfg.Host,
+		Version:   oltConfig.FirmwareVersion,
+		TotalONUs: len(oltConfig.ONUs),
+		Tags:      tags,
+	}
+	if len(warnings) > 0 {
+		metadata.CustomFields = map[string]string{
+			"partial":  "true",
+			"warnings": strconv.Itoa(len(warnings)),
+		}
+	}
 
-	// TODO: Implement OLT-wide backup
-	// This requires getting list of all ONUs and backing up each one
-	// For now, return not implemented
+	backup := &model.ConfigBackup{
+		ID:          uuid.New().String(),
+		Type:        "olt",
+		Timestamp:   time.Now(),
+		Description: description,
+		Metadata:    metadata,
+		Config:      oltConfig,
+	}
 
-	return nil, apperrors.NewInternalError("OLT-wide backup not yet implemented", nil)
+	// Save backup to file
+	if err := u.saveBackup(backup); err != nil {
+		log.Error().Err(err).Msg("Failed to save backup to file")
+		return nil, apperrors.NewInternalError("failed to save backup", err)
+	}
+
+	log.Info().
+		Str("backup_id", backup.ID).
+		Int("onu_count", len(oltConfig.ONUs)).
+		Int("dba_profiles", len(oltConfig.DBAProfiles)).
+		Int("vlans", len(oltConfig.GlobalVLANs)).
+		Int("warnings", len(warnings)).
+		Msg("OLT backup created successfully")
+
+	return backup, nil
 }
 
-// ListBackups lists all available backups
+// ListBackups lists all available backups from the backup index. Backups missing from the index
+// (written by earlier versions, or while the index could not be updated) are loaded once and added.
 func (u *configBackupUsecase) ListBackups(backupType string, limit int) ([]*model.BackupListItem, error) {
 	log.Info().
 		Str("type", backupType).
 		Int("limit", limit).
 		Msg("Listing configuration backups")
 
-	// Read all backup files from directory
-	files, err := os.ReadDir(u.backupDir)
+	ctx := context.Background()
+	objects, err := u.backupStore.List(ctx)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to read backup directory")
+		log.Error().Err(err).Msg("Failed to list backups")
 		return nil, apperrors.NewInternalError("failed to read backups", err)
 	}
 
-	var backups []*model.BackupListItem
+	u.indexMu.Lock()
+	defer u.indexMu.Unlock()
+	index := u.loadIndex(ctx)
+	changed := false
 
-	for _, file := range files {
-		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+	var backups []*model.BackupListItem
+	stored := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		if object.ID == backupIndexID {
 			continue
 		}
+		stored[object.ID] = true
 
-		// Read backup metadata
-		backupPath := filepath.Join(u.backupDir, file.Name())
-		backup, err := u.loadBackupFromFile(backupPath)
-		if err != nil {
-			log.Warn().Err(err).Str("file", file.Name()).Msg("Failed to load backup")
-			continue
+		item, ok := index[object.ID]
+		if !ok {
+			backup, err := u.loadBackup(object.ID)
+			if err != nil {
+				log.Warn().Err(err).Str("backup_id", object.ID).Msg("Failed to load backup")
+				continue
+			}
+			item = backupListItem(backup)
+			index[object.ID] = item
+			changed = true
 		}
 
 		// Filter by type if specified
-		if backupType != "" && backup.Type != backupType {
+		if backupType != "" && item.Type != backupType {
 			continue
 		}
 
-		// Get file info for size
-		fileInfo, err := file.Info()
-		if err != nil {
-			log.Warn().Err(err).Str("file", file.Name()).Msg("Failed to get file info")
-			continue
-		}
+		listed := *item
+		listed.Size = object.Size
+		backups = append(backups, &listed)
+	}
 
-		// Count ONUs
-		onuCount := 0
-		if backup.Type == "onu" {
-			onuCount = 1
-		} else if backup.Type == "olt" {
-			if oltBackup, ok := backup.Config.(model.OLTConfigBackup); ok {
-				onuCount = len(oltBackup.ONUs)
-			}
+	// Backups deleted by other means than DeleteBackup
+	for id := range index {
+		if !stored[id] {
+			delete(index, id)
+			changed = true
 		}
-
-		backups = append(backups, &model.BackupListItem{
-			ID:          backup.ID,
-			Type:        backup.Type,
-			Timestamp:   backup.Timestamp,
-			Description: backup.Description,
-			Size:        fileInfo.Size(),
-			ONUCount:    onuCount,
-			Source:      backup.Metadata.Source,
-			Tags:        backup.Metadata.Tags,
-		})
+	}
+	if changed {
+		u.saveIndex(ctx, index)
 	}
 
 	// Sort by timestamp (newest first)
@@ -216,12 +299,13 @@ func (u *configBackupUsecase) ListBackups(backupType string, limit int) ([]*mode
 func (u *configBackupUsecase) GetBackup(backupID string) (*model.ConfigBackup, error) {
 	log.Info().Str("backup_id", backupID).Msg("Getting backup details")
 
-	backupPath := filepath.Join(u.backupDir, fmt.Sprintf("%s.json", backupID))
-
-	backup, err := u.loadBackupFromFile(backupPath)
+	backup, err := u.loadBackup(backupID)
+	if errors.Is(err, repository.ErrBackupNotFound) || errors.Is(err, repository.ErrInvalidBackupID) {
+		return nil, apperrors.NewNotFoundError("backup", backupID)
+	}
 	if err != nil {
 		log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to load backup")
-		return nil, apperrors.NewNotFoundError("backup", backupID)
+		return nil, apperrors.NewInternalError("failed to load backup", err)
 	}
 
 	return backup, nil
@@ -231,70 +315,168 @@ func (u *configBackupUsecase) GetBackup(backupID string) (*model.ConfigBackup, e
 func (u *configBackupUsecase) DeleteBackup(backupID string) error {
 	log.Info().Str("backup_id", backupID).Msg("Deleting backup")
 
-	backupPath := filepath.Join(u.backupDir, fmt.Sprintf("%s.json", backupID))
-
-	// Check if file exists
-	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+	err := repository.ErrBackupNotFound
+	if backupID != backupIndexID {
+		err = u.backupStore.Delete(context.Background(), backupID)
+	}
+	if errors.Is(err, repository.ErrBackupNotFound) || errors.Is(err, repository.ErrInvalidBackupID) {
 		return apperrors.NewNotFoundError("backup", backupID)
 	}
-
-	// Delete file
-	if err := os.Remove(backupPath); err != nil {
+	if err != nil {
 		log.Error().Err(err).Str("backup_id", backupID).Msg("Failed to delete backup")
 		return apperrors.NewInternalError("failed to delete backup", err)
 	}
+	u.updateIndex(func(index map[string]*model.BackupListItem) {
+		delete(index, backupID)
+	})
 
 	log.Info().Str("backup_id", backupID).Msg("Backup deleted successfully")
 	return nil
 }
 
 // RestoreFromBackup restores configuration from a backup
-func (u *configBackupUsecase) RestoreFromBackup(req *model.RestoreRequest) (*model.RestoreResult, error) {
+func (u *configBackupUsecase) RestoreFromBackup(ctx context.Context, req *model.RestoreRequest) (*model.RestoreResult, error) {
 	log.Info().
 		Str("backup_id", req.BackupID).
 		Bool("dry_run", req.DryRun).
+		Bool("overwrite", req.Overwrite).
 		Msg("Restoring configuration from backup")
 
+	items, err := parseRestoreItems(req.RestoreItems)
+	if err != nil {
+		return nil, err
+	}
+	if req.TargetPON != "" {
+		if _, _, err := parseBoardPon(req.TargetPON); err != nil {
+			return nil, err
+		}
+	}
+	if req.TargetONUID < 0 || req.TargetONUID > 128 {
+		return nil, apperrors.NewValidationError("invalid target ONU ID", map[string]interface{}{
+			"target_onu_id": req.TargetONUID,
+			"allowed":       "1-128",
+		})
+	}
+
 	// Load backup
 	backup, err := u.GetBackup(req.BackupID)
 	if err != nil {
 		return nil, err
 	}
 
+	// A half-applied restore is worse than a late response: keep going if the client disconnects
+	ctx = context.WithoutCancel(ctx)
 	result := &model.RestoreResult{
 		BackupID: req.BackupID,
 		DryRun:   req.DryRun,
 	}
 
-	// Handle ONU backup restoration
-	if backup.Type == "onu" {
+	var onus []model.ONUConfigBackup
+	var targets []restoreTarget
+
+	switch backup.Type {
+	case "onu":
 		onuConfig, ok := backup.Config.(model.ONUConfigBackup)
 		if !ok {
 			return nil, apperrors.NewInternalError("invalid ONU backup format", nil)
 		}
 
-		// Restore ONU
-		itemResult, err := u.restoreONU(&onuConfig, req)
-		result.Details = append(result.Details, *itemResult)
+		// TargetPON/TargetONUID redirect the ONU to a different location
+		target := restoreTarget{PONPort: onuConfig.PONPort, ONUID: onuConfig.ONUID}
+		if req.TargetPON != "" {
+			target.PONPort = req.TargetPON
+		}
+		if req.TargetONUID > 0 {
+			target.ONUID = req.TargetONUID
+		}
+		onus = append(onus, onuConfig)
+		targets = append(targets, target)
 
-		if err != nil {
-			result.Success = false
-			result.FailedONUs = 1
-			result.Message = fmt.Sprintf("Failed to restore ONU: %v", err)
+	case "olt":
+		oltConfig, ok := backup.Config.(model.OLTConfigBackup)
+		if !ok {
+			return nil, apperrors.NewInternalError("invalid OLT backup format", nil)
+		}
+
+		// TargetPON/TargetONUID select a subset of the ONUs in the backup
+		for _, onu := range oltConfig.ONUs {
+			if req.TargetPON != "" && onu.PONPort != req.TargetPON {
+				continue
+			}
+			if req.TargetONUID > 0 && onu.ONUID != req.TargetONUID {
+				continue
+			}
+			onus = append(onus, onu)
+			targets = append(targets, restoreTarget{PONPort: onu.PONPort, ONUID: onu.ONUID})
+		}
+		if len(onus) == 0 {
+			return nil, apperrors.NewNotFoundError("ONU in backup", fmt.Sprintf("%s:%d", req.TargetPON, req.TargetONUID))
+		}
+
+		// DBA profiles must exist before T-CONTs reference them
+		if items[restoreItemDBAProfile] && len(oltConfig.DBAProfiles) > 0 {
+			result.Details = append(result.Details, u.restoreDBAProfiles(ctx, oltConfig.DBAProfiles, req)...)
+		}
+
+	default:
+		return nil, apperrors.NewInternalError(fmt.Sprintf("unsupported backup type %q", backup.Type), nil)
+	}
+
+	applied := false
+	for i := range onus {
+		itemResults, ok := u.restoreONU(ctx, &onus[i], targets[i], items, req)
+		result.Details = append(result.Details, itemResults...)
+		if ok {
+			result.RestoredONUs++
 		} else {
-			result.Success = true
-			result.RestoredONUs = 1
-			result.Message = "ONU configuration restored successfully"
+			result.FailedONUs++
+		}
+		for _, item := range itemResults {
+			if item.Success && len(item.Commands) > 0 {
+				applied = true
+			}
+		}
+	}
+
+	for _, item := range result.Details {
+		if item.ItemType == restoreItemDBAProfile && item.Success && len(item.Commands) > 0 {
+			applied = true
+		}
+	}
+
+	// Persist the restored configuration
+	if applied && !req.DryRun {
+		if err := u.telnetSessionManager.SaveConfiguration(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to save configuration after restore")
+			result.Details = append(result.Details, model.RestoreItemResult{
+				ItemType: "save",
+				Error:    fmt.Sprintf("failed to save configuration: %v", err),
+			})
+		}
+	}
+
+	// OLT-wide items (DBA profiles, save) carry no ONU and count against overall success
+	result.Success = result.FailedONUs == 0
+	for _, item := range result.Details {
+		if item.ONUID == 0 && item.Error != "" {
+			result.Success = false
 		}
-	} else if backup.Type == "olt" {
-		// TODO: Handle OLT-wide restoration
-		return nil, apperrors.NewInternalError("OLT-wide restore not yet implemented", nil)
+	}
+
+	switch {
+	case req.DryRun:
+		result.Message = fmt.Sprintf("Dry run: %d ONU(s) can be restored, %d would fail", result.RestoredONUs, result.FailedONUs)
+	case result.Success:
+		result.Message = fmt.Sprintf("%d ONU(s) restored successfully", result.RestoredONUs)
+	default:
+		result.Message = fmt.Sprintf("%d ONU(s) restored, %d failed", result.RestoredONUs, result.FailedONUs)
 	}
 
 	log.Info().
 		Str("backup_id", req.BackupID).
 		Bool("success", result.Success).
 		Int("restored", result.RestoredONUs).
+		Int("failed", result.FailedONUs).
 		Msg("Restore operation completed")
 
 	return result, nil
@@ -320,7 +502,7 @@ func (u *configBackupUsecase) ExportBackup(backupID string, outputPath string) e
 	}
 
 	// Write to file
-	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+	if err := os.WriteFile(outputPath, data, 0600); err != nil {
 		log.Error().Err(err).Msg("Failed to write backup file")
 		return apperrors.NewInternalError("failed to write backup file", err)
 	}
@@ -329,96 +511,682 @@ func (u *configBackupUsecase) ExportBackup(backupID string, outputPath string) e
 	return nil
 }
 
-// ImportBackup imports a backup from a file
-func (u *configBackupUsecase) ImportBackup(inputPath string) (*model.ConfigBackup, error) {
-	log.Info().Str("input", inputPath).Msg("Importing backup")
+// ImportBackup validates a backup document, migrating older schema versions, and stores it under a new ID
+func (u *configBackupUsecase) ImportBackup(data []byte) (*model.ConfigBackup, error) {
+	log.Info().Int("size", len(data)).Msg("Importing backup")
 
-	backup, err := u.loadBackupFromFile(inputPath)
+	backup, err := decodeBackup(data)
 	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		return nil, apperrors.NewValidationError("invalid backup document: "+err.Error(), nil)
+	}
+
+	if err := validateBackupDocument(backup); err != nil {
 		return nil, err
 	}
 
+	// Keep a reference to the original backup
+	if backup.Metadata.CustomFields == nil {
+		backup.Metadata.CustomFields = make(map[string]string)
+	}
+	if backup.ID != "" {
+		backup.Metadata.CustomFields["imported_from"] = backup.ID
+	}
+	if !backup.Timestamp.IsZero() {
+		backup.Metadata.CustomFields["original_timestamp"] = backup.Timestamp.Format(time.RFC3339)
+	}
+
 	// Generate new ID and timestamp for imported backup
 	backup.ID = uuid.New().String()
 	backup.Timestamp = time.Now()
 
-	// Save to backup directory
-	if err := u.saveBackupToFile(backup); err != nil {
-		return nil, err
+	// Save to backup store
+	if err := u.saveBackup(backup); err != nil {
+		log.Error().Err(err).Msg("Failed to save imported backup")
+		return nil, apperrors.NewInternalError("failed to save backup", err)
 	}
 
-	log.Info().Str("backup_id", backup.ID).Msg("Backup imported successfully")
+	log.Info().
+		Str("backup_id", backup.ID).
+		Str("imported_from", backup.Metadata.CustomFields["imported_from"]).
+		Str("type", backup.Type).
+		Msg("Backup imported successfully")
+
 	return backup, nil
 }
 
+// GetRunningConfig retrieves and parses the running-config of the OLT
+func (u *configBackupUsecase) GetRunningConfig() (*model.RunningConfig, string, error) {
+	if u.telnetSessionManager == nil {
+		return nil, "", apperrors.NewConfigError("Telnet is not configured", nil)
+	}
+
+	runningConfig, raw, err := u.telnetSessionManager.GetRunningConfig(context.Background())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read running-config")
+		return nil, "", apperrors.NewInternalError("failed to read running-config", err)
+	}
+
+	log.Info().
+		Int("pon_ports", len(runningConfig.PONPorts)).
+		Int("onus", len(runningConfig.ONUs)).
+		Int("dba_profiles", len(runningConfig.DBAProfiles)).
+		Int("vlans", len(runningConfig.VLANs)).
+		Msg("Running-config retrieved")
+
+	return runningConfig, raw, nil
+}
+
+// DetectDrift compares a stored backup against the live OLT configuration.
+// The backup is the old side and the live configuration the new side, so "added" means
+// configured on the OLT after the backup was taken.
+func (u *configBackupUsecase) DetectDrift(backupID string) (*model.ConfigDrift, error) {
+	log.Info().Str("backup_id", backupID).Msg("Detecting configuration drift")
+
+	backup, err := u.GetBackup(backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	backupONUList, err := backupONUs(backup)
+	if err != nil {
+		return nil, apperrors.NewInternalError("invalid backup format", err)
+	}
+
+	// Without Telnet every T-CONT, GEM port and service-port would be reported as removed
+	if u.telnetSessionManager == nil {
+		return nil, apperrors.NewConfigError("Telnet is not configured, drift detection needs the running-config", nil)
+	}
+
+	ctx := context.Background()
+	drift := &model.ConfigDrift{
+		BackupID:        backup.ID,
+		BackupType:      backup.Type,
+		BackupTimestamp: backup.Timestamp,
+	}
+
+	var liveONUs []model.ONUConfigBackup
+	switch backup.Type {
+	case "onu":
+		onu := backupONUList[0]
+		live, err := u.getONUConfiguration(ctx, onu.PONPort, onu.ONUID)
+		if err != nil {
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+				return nil, apperrors.NewInternalError("failed to read live ONU configuration", err)
+			}
+			// ONU no longer exists on the OLT: reported as removed
+		} else {
+			liveONUs = append(liveONUs, *live)
+		}
+
+	case "olt":
+		runningConfig, _, err := u.telnetSessionManager.GetRunningConfig(ctx)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to read running-config", err)
+		}
+
+		live, warnings, err := u.collectOLTConfiguration(runningConfig)
+		if err != nil {
+			return nil, apperrors.NewInternalError("failed to collect live OLT configuration", err)
+		}
+		liveONUs = live.ONUs
+		drift.Warnings = warnings
+
+	default:
+		return nil, apperrors.NewInternalError(fmt.Sprintf("unsupported backup type %q", backup.Type), nil)
+	}
+
+	drift.CheckedAt = time.Now()
+	drift.ONUs, drift.Summary = diffONUConfigs(backupONUList, liveONUs)
+	drift.HasDrift = len(drift.ONUs) > 0
+
+	log.Info().
+		Str("backup_id", backupID).
+		Bool("has_drift", drift.HasDrift).
+		Int("added", drift.Summary.Added).
+		Int("removed", drift.Summary.Removed).
+		Int("changed", drift.Summary.Changed).
+		Msg("Drift detection completed")
+
+	return drift, nil
+}
+
+// DiffBackups compares two stored backups; "from" is the old side and "to" the new side
+func (u *configBackupUsecase) DiffBackups(fromID, toID string) (*model.ConfigDiff, error) {
+	if fromID == "" || toID == "" {
+		return nil, apperrors.NewValidationError("both from and to backup IDs are required", map[string]interface{}{
+			"from": fromID,
+			"to":   toID,
+		})
+	}
+
+	log.Info().Str("from", fromID).Str("to", toID).Msg("Comparing backups")
+
+	fromBackup, err := u.GetBackup(fromID)
+	if err != nil {
+		return nil, err
+	}
+	toBackup, err := u.GetBackup(toID)
+	if err != nil {
+		return nil, err
+	}
+
+	fromONUs, err := backupONUs(fromBackup)
+	if err != nil {
+		return nil, apperrors.NewInternalError("invalid backup format", err)
+	}
+	toONUs, err := backupONUs(toBackup)
+	if err != nil {
+		return nil, apperrors.NewInternalError("invalid backup format", err)
+	}
+
+	diff := &model.ConfigDiff{
+		From:          backupRef(fromBackup),
+		To:            backupRef(toBackup),
+		GlobalChanges: diffGlobalConfig(fromBackup, toBackup),
+	}
+	diff.ONUs, diff.Summary = diffONUConfigs(fromONUs, toONUs)
+	diff.Identical = len(diff.ONUs) == 0 && len(diff.GlobalChanges) == 0
+	diff.Unified = renderUnifiedDiff(diff, fromONUs, toONUs)
+
+	return diff, nil
+}
+
 // Helper methods
 
+// backupDirectory returns the backup directory from config or the default
+func backupDirectory(cfg *config.Config) string {
+	if cfg != nil && cfg.OltCfg.BackupDir != "" {
+		return cfg.OltCfg.BackupDir
+	}
+	return "/var/lib/go-snmp-olt/backups"
+}
+
 // getONUConfiguration retrieves complete configuration for an ONU
-func (u *configBackupUsecase) getONUConfiguration(ponPort string, onuID int) (*model.ONUConfigBackup, error) {
-	// For now, create a basic configuration
-	// TODO: Actually retrieve real configuration from ONU via Telnet/SNMP
+func (u *configBackupUsecase) getONUConfiguration(ctx context.Context, ponPort string, onuID int) (*model.ONUConfigBackup, error) {
+	boardID, ponID, err := parseBoardPon(ponPort)
+	if err != nil {
+		return nil, err
+	}
+
+	onus, err := u.collectPONONUs(boardID, ponID)
+	if err != nil {
+		return nil, err
+	}
+
+	var onuConfig *model.ONUConfigBackup
+	for i := range onus {
+		if onus[i].ONUID == onuID {
+			onuConfig = &onus[i]
+			break
+		}
+	}
+	if onuConfig == nil {
+		return nil, apperrors.NewNotFoundError("ONU", fmt.Sprintf("%s:%d", ponPort, onuID))
+	}
+
+	if u.telnetSessionManager != nil {
+		declarations, err := u.telnetSessionManager.GetPONRunningConfig(ctx, ponPort)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range declarations {
+			if decl.ONUID == onuID {
+				applyONUDeclaration(onuConfig, decl)
+			}
+		}
 
-	config := &model.ONUConfigBackup{
-		PONPort:      ponPort,
-		ONUID:        onuID,
-		SerialNumber: "UNKNOWN", // TODO: Get from ONU
-		Type:         "UNKNOWN", // TODO: Get from ONU
-		Name:         fmt.Sprintf("ONU_%s_%d", ponPort, onuID),
-		AdminState:   "enabled",
-		OperState:    "unknown",
-		VLANs:        []model.ONUVLANConfig{},
-		TCONTs:       []model.ONUTCONTConfig{},
-		GEMPorts:     []model.ONUGEMPortConfig{},
-		ServicePorts: []model.ONUServicePortConfig{},
-		CustomConfig: make(map[string]interface{}),
+		runningConfig, err := u.telnetSessionManager.GetONURunningConfig(ctx, ponPort, onuID)
+		if err != nil {
+			return nil, err
+		}
+		applyONURunningConfig(onuConfig, runningConfig)
 	}
 
-	return config, nil
+	return onuConfig, nil
 }
 
-// restoreONU restores a single ONU configuration
-func (u *configBackupUsecase) restoreONU(onuConfig *model.ONUConfigBackup, req *model.RestoreRequest) (*model.RestoreItemResult, error) {
-	result := &model.RestoreItemResult{
-		PONPort:  onuConfig.PONPort,
-		ONUID:    onuConfig.ONUID,
-		ItemType: "onu",
+// collectOLTConfiguration walks every board/PON in BoardPonMap and builds the OLT-wide configuration.
+// ONU status comes from SNMP; declarations, T-CONTs, GEM ports, service-ports, DBA profiles and VLANs
+// come from the parsed running-config (nil if unavailable). Failures on a single PON are logged and
+// returned as warnings so one bad port doesn't abort the backup.
+func (u *configBackupUsecase) collectOLTConfiguration(runningConfig *model.RunningConfig) (*model.OLTConfigBackup, []string, error) {
+	if u.snmpRepository == nil {
+		return nil, nil, fmt.Errorf("SNMP repository is not configured")
 	}
 
-	if req.DryRun {
-		result.Success = true
-		result.Message = "Dry run: ONU configuration would be restored"
-		return result, nil
+	oltConfig := &model.OLTConfigBackup{
+		OLTIP:           u.cfg.SnmpCfg.IP,
+		Hostname:        u.cfg.OltCfg.Host,
+		Model:           "C320",
+		FirmwareVersion: u.firmwareVersion(),
+		ONUs:            []model.ONUConfigBackup{},
 	}
+	var warnings []string
 
-	// TODO: Actually restore configuration via Telnet
-	// For now, just return success
-	result.Success = true
-	result.Message = "ONU configuration restore not yet implemented"
+	if runningConfig != nil {
+		oltConfig.DBAProfiles = runningConfig.DBAProfiles
+		oltConfig.GlobalVLANs = runningConfig.VLANs
+	}
 
-	return result, nil
+	// Walk board/PON combinations in a stable order
+	keys := u.cfg.BoardPonKeys()
+
+	for _, key := range keys {
+		ponPort := formatPONPort(key.BoardID, key.PonID)
+
+		onus, err := u.collectPONONUs(key.BoardID, key.PonID)
+		if err != nil {
+			log.Warn().Err(err).Str("pon_port", ponPort).Msg("Failed to walk ONUs on PON port")
+			warnings = append(warnings, fmt.Sprintf("%s: %v", ponPort, err))
+		}
+
+		if runningConfig != nil {
+			onus = mergeRunningConfig(runningConfig, ponPort, onus)
+		}
+		if len(onus) == 0 {
+			continue
+		}
+
+		activeONUs := 0
+		for _, onu := range onus {
+			if onu.OperState == "online" {
+				activeONUs++
+			}
+		}
+
+		oltConfig.ONUs = append(oltConfig.ONUs, onus...)
+		oltConfig.PONPorts = append(oltConfig.PONPorts, model.PONPortConfig{
+			PONPort:    ponPort,
+			ActiveONUs: activeONUs,
+		})
+	}
+
+	return oltConfig, warnings, nil
 }
 
-// saveBackupToFile saves a backup to a JSON file
-func (u *configBackupUsecase) saveBackupToFile(backup *model.ConfigBackup) error {
-	filePath := filepath.Join(u.backupDir, fmt.Sprintf("%s.json", backup.ID))
+// mergeRunningConfig adds the running-config of a PON to the ONUs discovered via SNMP.
+// ONUs that are declared but were not returned by SNMP are added with an unknown state.
+func mergeRunningConfig(runningConfig *model.RunningConfig, ponPort string, onus []model.ONUConfigBackup) []model.ONUConfigBackup {
+	pon := runningConfig.PON(ponPort)
+	if pon != nil {
+		known := make(map[int]bool, len(onus))
+		for _, onu := range onus {
+			known[onu.ONUID] = true
+		}
+		for _, decl := range pon.ONUs {
+			if !known[decl.ONUID] {
+				onus = append(onus, model.ONUConfigBackup{
+					PONPort:      ponPort,
+					ONUID:        decl.ONUID,
+					AuthMethod:   "sn",
+					OperState:    "unknown",
+					CustomConfig: make(map[string]interface{}),
+				})
+			}
+		}
+		sort.Slice(onus, func(i, j int) bool {
+			return onus[i].ONUID < onus[j].ONUID
+		})
+	}
 
-	data, err := json.MarshalIndent(backup, "", "  ")
+	for i := range onus {
+		onu := &onus[i]
+		if pon != nil {
+			for _, decl := range pon.ONUs {
+				if decl.ONUID == onu.ONUID {
+					applyONUDeclaration(onu, decl)
+					break
+				}
+			}
+		}
+		if onuConfig := runningConfig.ONU(ponPort, onu.ONUID); onuConfig != nil {
+			applyONURunningConfig(onu, onuConfig)
+		}
+	}
+
+	return onus
+}
+
+// collectPONONUs discovers the ONUs of a board/PON via SNMP (ID, name, serial number, type and status)
+func (u *configBackupUsecase) collectPONONUs(boardID, ponID int) ([]model.ONUConfigBackup, error) {
+	if u.snmpRepository == nil {
+		return nil, fmt.Errorf("SNMP repository is not configured")
+	}
+
+	ponCfg, err := u.cfg.GetBoardPonConfig(boardID, ponID)
+	if err != nil {
+		return nil, apperrors.NewConfigError("invalid board/pon combination", err)
+	}
+
+	ponPort := formatPONPort(boardID, ponID)
+	onuMap := make(map[int]*model.ONUConfigBackup)
+
+	// ONU ID and name define which ONUs exist on this PON
+	err = u.snmpRepository.Walk(u.cfg.BaseOID()+ponCfg.OnuIDNameOID, func(pdu gosnmp.SnmpPDU) error {
+		onuID := utils.ExtractIDOnuID(pdu.Name)
+		onuMap[onuID] = &model.ONUConfigBackup{
+			PONPort:      ponPort,
+			ONUID:        onuID,
+			Name:         utils.ExtractName(pdu.Value),
+			AuthMethod:   "sn",
+			AdminState:   "enabled",
+			OperState:    "unknown",
+			CustomConfig: make(map[string]interface{}),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, apperrors.NewSNMPError("Walk", err)
+	}
+
+	if len(onuMap) == 0 {
+		return nil, nil
+	}
+
+	// Serial number, type and status are walked per column to keep the number of requests per PON constant
+	columns := []struct {
+		oid   string
+		apply func(onu *model.ONUConfigBackup, value interface{})
+	}{
+		{u.cfg.BaseOID() + ponCfg.OnuSerialNumberOID, func(onu *model.ONUConfigBackup, value interface{}) {
+			onu.SerialNumber = utils.ExtractSerialNumber(value)
+		}},
+		{u.cfg.OltCfg.BaseOID2 + ponCfg.OnuTypeOID, func(onu *model.ONUConfigBackup, value interface{}) {
+			onu.Type = utils.ExtractName(value)
+		}},
+		{u.cfg.BaseOID() + ponCfg.OnuStatusOID, func(onu *model.ONUConfigBackup, value interface{}) {
+			onu.OperState = strings.ToLower(utils.ExtractAndGetStatus(value))
+		}},
+	}
+
+	for _, column := range columns {
+		err := u.snmpRepository.Walk(column.oid, func(pdu gosnmp.SnmpPDU) error {
+			if onu, ok := onuMap[utils.ExtractIDOnuID(pdu.Name)]; ok {
+				column.apply(onu, pdu.Value)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("oid", column.oid).Str("pon_port", ponPort).Msg("SNMP walk failed, continuing with partial data")
+		}
+	}
+
+	onus := make([]model.ONUConfigBackup, 0, len(onuMap))
+	for _, onu := range onuMap {
+		onus = append(onus, *onu)
+	}
+	sort.Slice(onus, func(i, j int) bool {
+		return onus[i].ONUID < onus[j].ONUID
+	})
+
+	return onus, nil
+}
+
+// firmwareVersion returns the name of the OID profile the OLT is addressed with
+func (u *configBackupUsecase) firmwareVersion() string {
+	if u.cfg == nil {
+		return string(config.GetCurrentFirmwareVersion())
+	}
+	if firmware, _ := u.cfg.FirmwareProfile(); firmware != "" {
+		return string(firmware)
+	}
+	if u.cfg != nil && u.cfg.BaseOID() != "" {
+		for version, profile := range config.OIDProfiles {
+			if profile.BaseOID == u.cfg.BaseOID() {
+				return string(version)
+			}
+		}
+	}
+	return string(config.GetCurrentFirmwareVersion())
+}
+
+// applyONUDeclaration merges the gpon-olt declaration of an ONU into its backup.
+// The declared type is preferred over the SNMP equipment ID because it is what "onu N type" expects.
+func applyONUDeclaration(onu *model.ONUConfigBackup, decl model.ONUDeclaration) {
+	if decl.Type != "" {
+		if onu.Type != "" && onu.Type != decl.Type {
+			onu.CustomConfig["equipment_id"] = onu.Type
+		}
+		onu.Type = decl.Type
+	}
+	if decl.SerialNumber != "" {
+		onu.SerialNumber = decl.SerialNumber
+	}
+	if decl.Name != "" {
+		onu.Name = decl.Name
+	}
+	if decl.Description != "" {
+		onu.CustomConfig["description"] = decl.Description
+	}
+	if decl.AdminState != "" {
+		onu.AdminState = decl.AdminState
+	}
+}
+
+// applyONURunningConfig merges the gpon-onu running-config of an ONU into its backup
+func applyONURunningConfig(onu *model.ONUConfigBackup, runningConfig *model.ONURunningConfig) {
+	onu.TCONTs = runningConfig.TCONTs
+	onu.GEMPorts = runningConfig.GEMPorts
+	onu.ServicePorts = runningConfig.ServicePorts
+
+	onu.VLANs = make([]model.ONUVLANConfig, 0, len(runningConfig.ServicePorts))
+	for _, sp := range runningConfig.ServicePorts {
+		onu.VLANs = append(onu.VLANs, model.ONUVLANConfig{
+			UserVLAN:    sp.UserVLAN,
+			ServiceVLAN: sp.ServiceVLAN,
+			Mode:        vlanMode(sp.UserVLAN, sp.ServiceVLAN),
+		})
+	}
+}
+
+// newONUBackup creates a backup object for an ONU configuration, created by the caller of ctx
+func (u *configBackupUsecase) newONUBackup(ctx context.Context, onuConfig *model.ONUConfigBackup, description string, tags []string) *model.ConfigBackup {
+	return &model.ConfigBackup{
+		ID:          uuid.New().String(),
+		Type:        "onu",
+		Timestamp:   time.Now(),
+		Description: description,
+		Metadata: model.BackupMetadata{
+			CreatedBy: backupCreator(ctx),
+			Source:    u.cfg.OltCfg.Host,
+			Version:   u.firmwareVersion(),
+			Tags:      tags,
+		},
+		Config: onuConfig,
+	}
+}
+
+// backupSystemCreator is the creator of backups without an authenticated caller, e.g. scheduled backups
+const backupSystemCreator = "system"
+
+// backupCreator returns the identity of the authenticated caller of ctx for BackupMetadata.CreatedBy
+func backupCreator(ctx context.Context) string {
+	if principal, ok := model.PrincipalFromContext(ctx); ok {
+		return principal.Identity()
+	}
+	return backupSystemCreator
+}
+
+// formatPONPort formats a board/PON pair as a C320 PON port ("1/<board>/<pon>")
+func formatPONPort(boardID, ponID int) string {
+	return fmt.Sprintf("1/%d/%d", boardID, ponID)
+}
+
+// parseBoardPon parses a PON port ("1/<board>/<pon>") into its board and PON IDs
+func parseBoardPon(ponPort string) (int, int, error) {
+	parts := strings.Split(ponPort, "/")
+	if len(parts) != 3 {
+		return 0, 0, apperrors.NewValidationError("invalid PON port format", map[string]interface{}{
+			"pon_port": ponPort,
+			"expected": "rack/board/pon (e.g. 1/1/1)",
+		})
+	}
+
+	boardID, errBoard := strconv.Atoi(parts[1])
+	ponID, errPon := strconv.Atoi(parts[2])
+	if errBoard != nil || errPon != nil {
+		return 0, 0, apperrors.NewValidationError("invalid PON port format", map[string]interface{}{
+			"pon_port": ponPort,
+		})
+	}
+
+	return boardID, ponID, nil
+}
+
+// saveBackup stores a backup in the current schema version, recording the checksum of its configuration in the metadata
+func (u *configBackupUsecase) saveBackup(backup *model.ConfigBackup) error {
+	backup.SchemaVersion = model.BackupSchemaVersion
+
+	configData, err := json.Marshal(backup.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backup config: %w", err)
+	}
+	if backup.Metadata.Checksum, err = configChecksum(configData); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(backup)
 	if err != nil {
 		return fmt.Errorf("failed to marshal backup: %w", err)
 	}
 
-	if err := os.WriteFile(filePath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write backup file: %w", err)
+	if err := u.backupStore.Put(context.Background(), backup.ID, data); err != nil {
+		return fmt.Errorf("failed to store backup: %w", err)
 	}
 
+	u.updateIndex(func(index map[string]*model.BackupListItem) {
+		index[backup.ID] = backupListItem(backup)
+	})
 	return nil
 }
 
-// loadBackupFromFile loads a backup from a JSON file
-func (u *configBackupUsecase) loadBackupFromFile(filePath string) (*model.ConfigBackup, error) {
-	data, err := os.ReadFile(filePath)
+// backupListItem returns the list entry of a backup; its size is the one of the stored object.
+// OLT configurations are pointers in new backups and values in loaded ones.
+func backupListItem(backup *model.ConfigBackup) *model.BackupListItem {
+	onuCount := 0
+	switch oltBackup := backup.Config.(type) {
+	case model.OLTConfigBackup:
+		onuCount = len(oltBackup.ONUs)
+	case *model.OLTConfigBackup:
+		onuCount = len(oltBackup.ONUs)
+	default:
+		if backup.Type == "onu" {
+			onuCount = 1
+		}
+	}
+
+	return &model.BackupListItem{
+		ID:          backup.ID,
+		Type:        backup.Type,
+		Timestamp:   backup.Timestamp,
+		Description: backup.Description,
+		ONUCount:    onuCount,
+		Source:      backup.Metadata.Source,
+		Tags:        backup.Metadata.Tags,
+		Checksum:    backup.Metadata.Checksum,
+	}
+}
+
+// updateIndex applies change to the backup index. Failures are only logged: ListBackups adds the
+// backups missing from the index and drops the deleted ones.
+func (u *configBackupUsecase) updateIndex(change func(index map[string]*model.BackupListItem)) {
+	ctx := context.Background()
+	u.indexMu.Lock()
+	defer u.indexMu.Unlock()
+
+	index := u.loadIndex(ctx)
+	change(index)
+	u.saveIndex(ctx, index)
+}
+
+// loadIndex returns the list entries of the backup index by backup ID. A missing or unreadable
+// index is empty and rebuilt by ListBackups. indexMu must be held.
+func (u *configBackupUsecase) loadIndex(ctx context.Context) map[string]*model.BackupListItem {
+	index := make(map[string]*model.BackupListItem)
+
+	data, err := u.backupStore.Get(ctx, backupIndexID)
+	if errors.Is(err, repository.ErrBackupNotFound) {
+		return index
+	}
+	var items []*model.BackupListItem
+	if err == nil {
+		err = json.Unmarshal(data, &items)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read backup file: %w", err)
+		log.Warn().Err(err).Msg("Failed to read the backup index, rebuilding it")
+		return index
+	}
+
+	for _, item := range items {
+		index[item.ID] = item
+	}
+	return index
+}
+
+// saveIndex stores the backup index. indexMu must be held.
+func (u *configBackupUsecase) saveIndex(ctx context.Context, index map[string]*model.BackupListItem) {
+	items := make([]*model.BackupListItem, 0, len(index))
+	for _, item := range index {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+
+	data, err := json.Marshal(items)
+	if err == nil {
+		err = u.backupStore.Put(ctx, backupIndexID, data)
+	}
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to store the backup index")
+	}
+}
+
+// loadBackup loads a backup from the store
+func (u *configBackupUsecase) loadBackup(backupID string) (*model.ConfigBackup, error) {
+	if backupID == backupIndexID {
+		return nil, repository.ErrBackupNotFound
+	}
+	data, err := u.backupStore.Get(context.Background(), backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeBackup(data)
+}
+
+// decodeBackup parses a serialized backup, verifies the checksum of its configuration and
+// migrates it to the current schema version. Backups written before checksums were introduced
+// have none and are accepted as-is.
+func decodeBackup(data []byte) (*model.ConfigBackup, error) {
+	var raw struct {
+		ID       string `json:"id"`
+		Metadata struct {
+			Checksum string `json:"checksum"`
+		} `json:"metadata"`
+		Config json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backup: %w", err)
+	}
+
+	// The checksum covers the configuration as it was written, before any migration
+	if raw.Metadata.Checksum != "" {
+		checksum, err := configChecksum(raw.Config)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != raw.Metadata.Checksum {
+			return nil, fmt.Errorf("backup %s checksum mismatch: stored %s, computed %s", raw.ID, raw.Metadata.Checksum, checksum)
+		}
+	}
+
+	data, err := migrateBackupDocument(data)
+	if err != nil {
+		return nil, err
 	}
 
 	var backup model.ConfigBackup
@@ -426,5 +1194,46 @@ func (u *configBackupUsecase) loadBackupFromFile(filePath string) (*model.Config
 		return nil, fmt.Errorf("failed to unmarshal backup: %w", err)
 	}
 
+	if err := decodeBackupConfig(&backup); err != nil {
+		return nil, err
+	}
+
 	return &backup, nil
 }
+
+// configChecksum returns the SHA-256 checksum ("sha256:<hex>") of a backup configuration in compact JSON form
+func configChecksum(configData []byte) (string, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, configData); err != nil {
+		return "", fmt.Errorf("invalid backup config: %w", err)
+	}
+
+	sum := sha256.Sum256(compact.Bytes())
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// decodeBackupConfig converts the generic JSON config of a loaded backup into its typed form
+// (model.ONUConfigBackup or model.OLTConfigBackup) so callers can type-assert on it.
+func decodeBackupConfig(backup *model.ConfigBackup) error {
+	raw, err := json.Marshal(backup.Config)
+	if err != nil {
+		return fmt.Errorf("failed to re-encode backup config: %w", err)
+	}
+
+	switch backup.Type {
+	case "onu":
+		var onuConfig model.ONUConfigBackup
+		if err := json.Unmarshal(raw, &onuConfig); err != nil {
+			return fmt.Errorf("invalid ONU backup config: %w", err)
+		}
+		backup.Config = onuConfig
+	case "olt":
+		var oltConfig model.OLTConfigBackup
+		if err := json.Unmarshal(raw, &oltConfig); err != nil {
+			return fmt.Errorf("invalid OLT backup config: %w", err)
+		}
+		backup.Config = oltConfig
+	}
+
+	return nil
+}
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// newBackupTestConfig builds a config with a single board/PON and a temporary backup directory
+func newBackupTestConfig(t *testing.T) *config.Config {
+	t.Helper()
+	return &config.Config{
+		OltCfg: config.OltConfig{
+			BaseOID1:  "1.3.6.1.4.1.3902.1012",
+			BaseOID2:  "1.3.6.1.4.1.3902.1082",
+			BackupDir: t.TempDir(),
+		},
+		BoardPonMap: map[config.BoardPonKey]*config.BoardPonConfig{
+			{BoardID: 1, PonID: 1}: {
+				OnuIDNameOID:       ".name",
+				OnuSerialNumberOID: ".serial",
+				OnuTypeOID:         ".type",
+				OnuStatusOID:       ".status",
+			},
+		},
+	}
+}
+
+// newBackupTestSnmpRepository simulates two ONUs (1 online, 2 offline) on the test PON
+func newBackupTestSnmpRepository(cfg *config.Config) *mockSnmpRepository {
+	columns := map[string][]gosnmp.SnmpPDU{
+		cfg.OltCfg.BaseOID1 + ".name": {
+			{Name: ".name.1", Value: []byte("customer-a")},
+			{Name: ".name.2", Value: []byte("customer-b")},
+		},
+		cfg.OltCfg.BaseOID1 + ".serial": {
+			{Name: ".serial.1", Value: "1,ZTEGC0000001"},
+			{Name: ".serial.2", Value: "1,ZTEGC0000002"},
+		},
+		cfg.OltCfg.BaseOID2 + ".type": {
+			{Name: ".type.1", Value: []byte("F670L")},
+			{Name: ".type.2", Value: []byte("F609")},
+		},
+		cfg.OltCfg.BaseOID1 + ".status": {
+			{Name: ".status.1", Value: 4},
+			{Name: ".status.2", Value: 7},
+		},
+	}
+
+	return &mockSnmpRepository{
+		WalkFunc: func(oid string, walkFunc func(pdu gosnmp.SnmpPDU) error) error {
+			for _, pdu := range columns[oid] {
+				if err := walkFunc(pdu); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestBackupOLT_CollectsONUsFromSNMP(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil)
+
+	backup, err := uc.BackupOLT(context.Background(), "nightly", []string{"test"})
+	if err != nil {
+		t.Fatalf("BackupOLT() error = %v", err)
+	}
+
+	oltConfig, ok := backup.Config.(*model.OLTConfigBackup)
+	if !ok {
+		t.Fatalf("Config type = %T, want *model.OLTConfigBackup", backup.Config)
+	}
+	if len(oltConfig.ONUs) != 2 {
+		t.Fatalf("ONUs = %d, want 2", len(oltConfig.ONUs))
+	}
+	if backup.Metadata.TotalONUs != 2 {
+		t.Errorf("TotalONUs = %d, want 2", backup.Metadata.TotalONUs)
+	}
+	if backup.Metadata.CreatedBy != "system" {
+		t.Errorf("CreatedBy = %q, want system without an authenticated caller", backup.Metadata.CreatedBy)
+	}
+
+	first := oltConfig.ONUs[0]
+	if first.PONPort != "1/1/1" || first.ONUID != 1 {
+		t.Errorf("first ONU = %s:%d, want 1/1/1:1", first.PONPort, first.ONUID)
+	}
+	if first.SerialNumber != "ZTEGC0000001" {
+		t.Errorf("SerialNumber = %q, want ZTEGC0000001", first.SerialNumber)
+	}
+	if first.Type != "F670L" {
+		t.Errorf("Type = %q, want F670L", first.Type)
+	}
+	if first.Name != "customer-a" {
+		t.Errorf("Name = %q, want customer-a", first.Name)
+	}
+	if first.OperState != "online" || oltConfig.ONUs[1].OperState != "offline" {
+		t.Errorf("OperState = %q/%q, want online/offline", first.OperState, oltConfig.ONUs[1].OperState)
+	}
+
+	if len(oltConfig.PONPorts) != 1 || oltConfig.PONPorts[0].ActiveONUs != 1 {
+		t.Errorf("PONPorts = %+v, want one port with 1 active ONU", oltConfig.PONPorts)
+	}
+
+	// The saved file must round-trip into the typed config
+	loaded, err := uc.GetBackup(backup.ID)
+	if err != nil {
+		t.Fatalf("GetBackup() error = %v", err)
+	}
+	loadedConfig, ok := loaded.Config.(model.OLTConfigBackup)
+	if !ok {
+		t.Fatalf("loaded Config type = %T, want model.OLTConfigBackup", loaded.Config)
+	}
+	if len(loadedConfig.ONUs) != 2 || loadedConfig.ONUs[1].SerialNumber != "ZTEGC0000002" {
+		t.Errorf("loaded ONUs = %+v", loadedConfig.ONUs)
+	}
+}
+
+func TestBackupStorage_ChecksumAndEncryption(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	local, _ := repository.NewLocalBackupStore(cfg.OltCfg.BackupDir)
+	store, err := repository.NewEncodedBackupStore(local, bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatalf("NewEncodedBackupStore() error = %v", err)
+	}
+	uc := NewConfigBackupUsecase(cfg, store, newBackupTestSnmpRepository(cfg), nil)
+
+	backup, err := uc.BackupOLT(context.Background(), "", nil)
+	if err != nil {
+		t.Fatalf("BackupOLT() error = %v", err)
+	}
+	if !strings.HasPrefix(backup.Metadata.Checksum, "sha256:") {
+		t.Errorf("Checksum = %q, want sha256:<hex>", backup.Metadata.Checksum)
+	}
+
+	raw, err := local.Get(context.Background(), backup.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if bytes.Contains(raw, []byte("ZTEGC0000001")) {
+		t.Error("stored backup is not encrypted")
+	}
+
+	backups, err := uc.ListBackups("", 0)
+	if err != nil || len(backups) != 1 || backups[0].Checksum != backup.Metadata.Checksum {
+		t.Fatalf("ListBackups() = %+v, %v", backups, err)
+	}
+
+	// A modified configuration no longer matches its checksum
+	data, _ := store.Get(context.Background(), backup.ID)
+	tampered := bytes.Replace(data, []byte("ZTEGC0000001"), []byte("ZTEGC9999999"), 1)
+	if err := store.Put(context.Background(), backup.ID, tampered); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := uc.GetBackup(backup.ID); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("GetBackup() error = %v, want checksum mismatch", err)
+	}
+}
+
+func TestBackupStorage_LegacyJSONBackup(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, nil, nil)
+
+	// Backups written by earlier versions: indented JSON without checksum
+	legacy := `{
+  "id": "legacy-1",
+  "type": "onu",
+  "timestamp": "2025-01-01T00:00:00Z",
+  "metadata": {"source": "10.0.0.1", "version": "V2.1.0"},
+  "config": {"pon_port": "1/1/1", "onu_id": 5, "serial_number": "ZTEGC0000005", "type": "F670L"}
+}`
+	if err := os.WriteFile(filepath.Join(cfg.OltCfg.BackupDir, "legacy-1.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := uc.GetBackup("legacy-1")
+	if err != nil {
+		t.Fatalf("GetBackup() error = %v", err)
+	}
+	onu, ok := backup.Config.(model.ONUConfigBackup)
+	if !ok || onu.SerialNumber != "ZTEGC0000005" {
+		t.Errorf("Config = %+v", backup.Config)
+	}
+
+	if _, err := uc.GetBackup("missing"); err == nil {
+		t.Error("expected error for unknown backup")
+	}
+	if err := uc.DeleteBackup("legacy-1"); err != nil {
+		t.Errorf("DeleteBackup() error = %v", err)
+	}
+}
+
+// countingBackupStore records the backups read from a store
+type countingBackupStore struct {
+	repository.BackupStore
+	gets []string
+}
+
+func (s *countingBackupStore) Get(ctx context.Context, id string) ([]byte, error) {
+	s.gets = append(s.gets, id)
+	return s.BackupStore.Get(ctx, id)
+}
+
+func TestBackupStorage_ListFromIndex(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	local, _ := repository.NewLocalBackupStore(cfg.OltCfg.BackupDir)
+	encoded, _ := repository.NewEncodedBackupStore(local, nil)
+	store := &countingBackupStore{BackupStore: encoded}
+	uc := NewConfigBackupUsecase(cfg, store, newBackupTestSnmpRepository(cfg), nil)
+
+	backup, err := uc.BackupOLT(context.Background(), "nightly", []string{"test"})
+	if err != nil {
+		t.Fatalf("BackupOLT() error = %v", err)
+	}
+	// A backup written before the index existed
+	legacy := `{"id": "legacy-1", "type": "onu", "timestamp": "2025-01-01T00:00:00Z", "metadata": {"source": "10.0.0.1"},
+  "config": {"pon_port": "1/1/1", "onu_id": 5, "serial_number": "ZTEGC0000005"}}`
+	if err := os.WriteFile(filepath.Join(cfg.OltCfg.BackupDir, "legacy-1.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The legacy backup is loaded once and added to the index, the new one is listed from the index
+	store.gets = nil
+	backups, err := uc.ListBackups("", 0)
+	if err != nil || len(backups) != 2 {
+		t.Fatalf("ListBackups() = %+v, %v, want 2 backups", backups, err)
+	}
+	if got := strings.Join(store.gets, ","); got != "index,legacy-1" {
+		t.Errorf("read %s, want the index and the legacy backup", got)
+	}
+	olt := backups[0]
+	if olt.ID != backup.ID || olt.ONUCount != 2 || olt.Description != "nightly" || olt.Checksum != backup.Metadata.Checksum || olt.Size == 0 {
+		t.Errorf("OLT backup = %+v", olt)
+	}
+	if backups[1].ID != "legacy-1" || backups[1].ONUCount != 1 || backups[1].Source != "10.0.0.1" {
+		t.Errorf("legacy backup = %+v", backups[1])
+	}
+
+	store.gets = nil
+	if backups, err := uc.ListBackups("onu", 0); err != nil || len(backups) != 1 || backups[0].ID != "legacy-1" {
+		t.Errorf("ListBackups(onu) = %+v, %v", backups, err)
+	}
+	if got := strings.Join(store.gets, ","); got != "index" {
+		t.Errorf("read %s, want only the index", got)
+	}
+
+	// Deleted backups leave the index, also when removed from the store directly
+	if err := uc.DeleteBackup("legacy-1"); err != nil {
+		t.Fatalf("DeleteBackup() error = %v", err)
+	}
+	if err := local.Delete(context.Background(), backup.ID); err != nil {
+		t.Fatal(err)
+	}
+	if backups, err := uc.ListBackups("", 0); err != nil || len(backups) != 0 {
+		t.Errorf("ListBackups() = %+v, %v, want none", backups, err)
+	}
+	if _, err := uc.GetBackup(backupIndexID); err == nil {
+		t.Error("GetBackup(index) error = nil, want not found")
+	}
+}
+
+func TestBackupONU_NotFound(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil)
+
+	if _, err := uc.BackupONU(context.Background(), "1/1/1", 99, "", nil); err == nil {
+		t.Error("expected error for unknown ONU")
+	}
+	if _, err := uc.BackupONU(context.Background(), "invalid", 1, "", nil); err == nil {
+		t.Error("expected error for invalid PON port")
+	}
+}
+
+func TestApplyONURunningConfig_DerivesVLANModes(t *testing.T) {
+	onu := &model.ONUConfigBackup{}
+	applyONURunningConfig(onu, &model.ONURunningConfig{
+		ServicePorts: []model.ONUServicePortConfig{
+			{PortID: 1, UserVLAN: 0, ServiceVLAN: 100},
+			{PortID: 2, UserVLAN: 200, ServiceVLAN: 200},
+			{PortID: 3, UserVLAN: 10, ServiceVLAN: 300},
+		},
+	})
+
+	want := []string{"untag", "tag", "translation"}
+	if len(onu.VLANs) != len(want) {
+		t.Fatalf("VLANs = %d, want %d", len(onu.VLANs), len(want))
+	}
+	for i, mode := range want {
+		if onu.VLANs[i].Mode != mode {
+			t.Errorf("VLANs[%d].Mode = %q, want %q", i, onu.VLANs[i].Mode, mode)
+		}
+	}
+}
+
+func TestMergeRunningConfig(t *testing.T) {
+	runningConfig := &model.RunningConfig{
+		PONPorts: []model.PONRunningConfig{
+			{PONPort: "1/1/1", ONUs: []model.ONUDeclaration{
+				{ONUID: 1, Type: "ZTE-F670L", SerialNumber: "ZTEGC0000001", Name: "customer-a", AdminState: "enabled"},
+				{ONUID: 3, Type: "ZTE-F609", SerialNumber: "ZTEGC0000003", AdminState: "disabled"},
+			}},
+		},
+		ONUs: []model.ONURunningConfig{
+			{PONPort: "1/1/1", ONUID: 1,
+				TCONTs:       []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "UP-10M"}},
+				ServicePorts: []model.ONUServicePortConfig{{PortID: 1, VPort: 1, ServiceVLAN: 100}},
+			},
+		},
+	}
+	onus := []model.ONUConfigBackup{
+		{PONPort: "1/1/1", ONUID: 1, Type: "F670L", OperState: "online", CustomConfig: map[string]interface{}{}},
+	}
+
+	merged := mergeRunningConfig(runningConfig, "1/1/1", onus)
+	if len(merged) != 2 {
+		t.Fatalf("ONUs = %d, want 2 (SNMP ONU plus declared-only ONU)", len(merged))
+	}
+
+	first := merged[0]
+	if first.Type != "ZTE-F670L" || first.CustomConfig["equipment_id"] != "F670L" {
+		t.Errorf("Type = %q (equipment_id %v), want declared type with SNMP type preserved", first.Type, first.CustomConfig["equipment_id"])
+	}
+	if len(first.TCONTs) != 1 || len(first.VLANs) != 1 || first.VLANs[0].Mode != "untag" {
+		t.Errorf("running-config not applied: %+v", first)
+	}
+
+	declaredOnly := merged[1]
+	if declaredOnly.ONUID != 3 || declaredOnly.SerialNumber != "ZTEGC0000003" || declaredOnly.AdminState != "disabled" || declaredOnly.OperState != "unknown" {
+		t.Errorf("declared-only ONU = %+v", declaredOnly)
+	}
+}
@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// onuKey identifies an ONU across configurations
+type onuKey struct {
+	PONPort string
+	ONUID   int
+}
+
+// diffONUConfigs compares two ONU lists (old → new) and returns the ONUs that differ, ordered by PON and ONU ID
+func diffONUConfigs(oldONUs, newONUs []model.ONUConfigBackup) ([]model.ONUDiff, model.DiffSummary) {
+	oldByKey := make(map[onuKey]*model.ONUConfigBackup, len(oldONUs))
+	for i := range oldONUs {
+		oldByKey[onuKey{oldONUs[i].PONPort, oldONUs[i].ONUID}] = &oldONUs[i]
+	}
+	newByKey := make(map[onuKey]*model.ONUConfigBackup, len(newONUs))
+	for i := range newONUs {
+		newByKey[onuKey{newONUs[i].PONPort, newONUs[i].ONUID}] = &newONUs[i]
+	}
+
+	keys := make([]onuKey, 0, len(oldByKey)+len(newByKey))
+	for key := range oldByKey {
+		keys = append(keys, key)
+	}
+	for key := range newByKey {
+		if _, ok := oldByKey[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].PONPort != keys[j].PONPort {
+			return comparePONPorts(keys[i].PONPort, keys[j].PONPort)
+		}
+		return keys[i].ONUID < keys[j].ONUID
+	})
+
+	diffs := []model.ONUDiff{}
+	var summary model.DiffSummary
+	for _, key := range keys {
+		oldONU, inOld := oldByKey[key]
+		newONU, inNew := newByKey[key]
+
+		switch {
+		case !inOld:
+			summary.Added++
+			diffs = append(diffs, model.ONUDiff{PONPort: key.PONPort, ONUID: key.ONUID, Change: model.ChangeAdded})
+		case !inNew:
+			summary.Removed++
+			diffs = append(diffs, model.ONUDiff{PONPort: key.PONPort, ONUID: key.ONUID, Change: model.ChangeRemoved})
+		default:
+			changes := diffONU(oldONU, newONU)
+			if len(changes) == 0 {
+				summary.Unchanged++
+				continue
+			}
+			summary.Changed++
+			diffs = append(diffs, model.ONUDiff{PONPort: key.PONPort, ONUID: key.ONUID, Change: model.ChangeChanged, Changes: changes})
+		}
+	}
+
+	return diffs, summary
+}
+
+// diffONU compares the configuration of one ONU. Operational state is ignored since it is not configuration.
+func diffONU(oldONU, newONU *model.ONUConfigBackup) []model.FieldChange {
+	var changes []model.FieldChange
+
+	scalars := []struct {
+		field    string
+		old, new string
+	}{
+		{"serial_number", oldONU.SerialNumber, newONU.SerialNumber},
+		{"type", oldONU.Type, newONU.Type},
+		{"name", oldONU.Name, newONU.Name},
+		{"admin_state", oldONU.AdminState, newONU.AdminState},
+	}
+	for _, s := range scalars {
+		if s.old != s.new {
+			changes = append(changes, model.FieldChange{Field: s.field, Change: model.ChangeChanged, Old: s.old, New: s.new})
+		}
+	}
+
+	changes = append(changes, diffKeyed("vlan", oldONU.VLANs, newONU.VLANs, func(v model.ONUVLANConfig) string {
+		return fmt.Sprintf("%d/%d", v.UserVLAN, v.ServiceVLAN)
+	})...)
+	changes = append(changes, diffKeyed("tcont", oldONU.TCONTs, newONU.TCONTs, func(t model.ONUTCONTConfig) string {
+		return fmt.Sprintf("%d", t.TCONTID)
+	})...)
+	changes = append(changes, diffKeyed("gemport", oldONU.GEMPorts, newONU.GEMPorts, func(g model.ONUGEMPortConfig) string {
+		return fmt.Sprintf("%d", g.GEMPortID)
+	})...)
+	changes = append(changes, diffKeyed("service_port", oldONU.ServicePorts, newONU.ServicePorts, func(sp model.ONUServicePortConfig) string {
+		return fmt.Sprintf("%d", sp.PortID)
+	})...)
+
+	return changes
+}
+
+// diffKeyed compares two lists of configuration entries matched by key, in the order of first appearance
+func diffKeyed[T any](field string, oldItems, newItems []T, key func(T) string) []model.FieldChange {
+	var changes []model.FieldChange
+
+	newByKey := make(map[string]T, len(newItems))
+	for _, item := range newItems {
+		newByKey[key(item)] = item
+	}
+	seen := make(map[string]bool, len(oldItems))
+
+	for _, oldItem := range oldItems {
+		k := key(oldItem)
+		seen[k] = true
+		newItem, ok := newByKey[k]
+		switch {
+		case !ok:
+			changes = append(changes, model.FieldChange{Field: field + " " + k, Change: model.ChangeRemoved, Old: oldItem})
+		case !reflect.DeepEqual(oldItem, newItem):
+			changes = append(changes, model.FieldChange{Field: field + " " + k, Change: model.ChangeChanged, Old: oldItem, New: newItem})
+		}
+	}
+	for _, newItem := range newItems {
+		if k := key(newItem); !seen[k] {
+			changes = append(changes, model.FieldChange{Field: field + " " + k, Change: model.ChangeAdded, New: newItem})
+		}
+	}
+
+	return changes
+}
+
+// comparePONPorts orders "1/<board>/<pon>" numerically, falling back to string order
+func comparePONPorts(a, b string) bool {
+	boardA, ponA, errA := parseBoardPon(a)
+	boardB, ponB, errB := parseBoardPon(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	if boardA != boardB {
+		return boardA < boardB
+	}
+	return ponA < ponB
+}
+
+// backupONUs returns the ONU configurations stored in a backup
+func backupONUs(backup *model.ConfigBackup) ([]model.ONUConfigBackup, error) {
+	switch cfg := backup.Config.(type) {
+	case model.ONUConfigBackup:
+		return []model.ONUConfigBackup{cfg}, nil
+	case *model.ONUConfigBackup:
+		return []model.ONUConfigBackup{*cfg}, nil
+	case model.OLTConfigBackup:
+		return cfg.ONUs, nil
+	case *model.OLTConfigBackup:
+		return cfg.ONUs, nil
+	default:
+		return nil, fmt.Errorf("unsupported backup config %T", backup.Config)
+	}
+}
+
+// backupRef summarizes a backup for diff output
+func backupRef(backup *model.ConfigBackup) model.BackupRef {
+	return model.BackupRef{
+		ID:          backup.ID,
+		Type:        backup.Type,
+		Timestamp:   backup.Timestamp,
+		Description: backup.Description,
+	}
+}
+
+// diffGlobalConfig compares the DBA profiles and VLAN definitions of two OLT backups
+func diffGlobalConfig(fromBackup, toBackup *model.ConfigBackup) []model.FieldChange {
+	fromOLT, okFrom := fromBackup.Config.(model.OLTConfigBackup)
+	toOLT, okTo := toBackup.Config.(model.OLTConfigBackup)
+	if !okFrom || !okTo {
+		return nil
+	}
+
+	var changes []model.FieldChange
+	changes = append(changes, diffKeyed("dba_profile", fromOLT.DBAProfiles, toOLT.DBAProfiles, func(p model.DBAProfileConfig) string {
+		return p.Name
+	})...)
+	changes = append(changes, diffKeyed("vlan", fromOLT.GlobalVLANs, toOLT.GlobalVLANs, func(v model.GlobalVLANConfig) string {
+		return fmt.Sprintf("%d", v.VLANID)
+	})...)
+	return changes
+}
+
+// renderUnifiedDiff renders a backup diff in unified-diff style.
+// Configuration entries are shown as the CLI lines that would recreate them.
+func renderUnifiedDiff(diff *model.ConfigDiff, fromONUs, toONUs []model.ONUConfigBackup) string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "--- backup %s (%s)\n", diff.From.ID, diff.From.Timestamp.Format("2006-01-02T15:04:05Z07:00"))
+	fmt.Fprintf(&b, "+++ backup %s (%s)\n", diff.To.ID, diff.To.Timestamp.Format("2006-01-02T15:04:05Z07:00"))
+
+	if len(diff.GlobalChanges) > 0 {
+		b.WriteString("@@ global @@\n")
+		writeFieldChanges(&b, diff.GlobalChanges)
+	}
+
+	lookup := func(onus []model.ONUConfigBackup, ponPort string, onuID int) *model.ONUConfigBackup {
+		for i := range onus {
+			if onus[i].PONPort == ponPort && onus[i].ONUID == onuID {
+				return &onus[i]
+			}
+		}
+		return nil
+	}
+
+	for _, onuDiff := range diff.ONUs {
+		fmt.Fprintf(&b, "@@ gpon-onu_%s:%d (%s) @@\n", onuDiff.PONPort, onuDiff.ONUID, onuDiff.Change)
+
+		switch onuDiff.Change {
+		case model.ChangeAdded:
+			if onu := lookup(toONUs, onuDiff.PONPort, onuDiff.ONUID); onu != nil {
+				for _, line := range onuConfigLines(onu) {
+					b.WriteString("+ " + line + "\n")
+				}
+			}
+		case model.ChangeRemoved:
+			if onu := lookup(fromONUs, onuDiff.PONPort, onuDiff.ONUID); onu != nil {
+				for _, line := range onuConfigLines(onu) {
+					b.WriteString("- " + line + "\n")
+				}
+			}
+		default:
+			writeFieldChanges(&b, onuDiff.Changes)
+		}
+	}
+
+	return b.String()
+}
+
+// writeFieldChanges writes "-" / "+" lines for field changes
+func writeFieldChanges(b *strings.Builder, changes []model.FieldChange) {
+	for _, change := range changes {
+		if change.Old != nil {
+			b.WriteString("- " + renderDiffValue(change.Field, change.Old) + "\n")
+		}
+		if change.New != nil {
+			b.WriteString("+ " + renderDiffValue(change.Field, change.New) + "\n")
+		}
+	}
+}
+
+// onuConfigLines renders an ONU configuration as CLI-like lines
+func onuConfigLines(onu *model.ONUConfigBackup) []string {
+	lines := onuDeclarationCommands(onu, onu.ONUID)
+	for _, tcont := range onu.TCONTs {
+		lines = append(lines, tcontCommand(tcont))
+	}
+	for _, gem := range onu.GEMPorts {
+		lines = append(lines, gemportCommand(gem))
+	}
+	for _, sp := range onu.ServicePorts {
+		lines = append(lines, servicePortCommand(sp))
+	}
+	return lines
+}
+
+// renderDiffValue renders one side of a field change as a single line
+func renderDiffValue(field string, value interface{}) string {
+	switch v := value.(type) {
+	case model.ONUTCONTConfig:
+		return tcontCommand(v)
+	case model.ONUGEMPortConfig:
+		return gemportCommand(v)
+	case model.ONUServicePortConfig:
+		return servicePortCommand(v)
+	case model.ONUVLANConfig:
+		return fmt.Sprintf("vlan user-vlan %d service-vlan %d mode %s", v.UserVLAN, v.ServiceVLAN, v.Mode)
+	case model.DBAProfileConfig:
+		commands, err := dbaProfileCommands(v)
+		if err != nil {
+			return fmt.Sprintf("dba-profile %s type %s", v.Name, v.Type)
+		}
+		return commands[0] + " " + commands[1]
+	case model.GlobalVLANConfig:
+		line := fmt.Sprintf("vlan %d", v.VLANID)
+		if v.Name != "" {
+			line += " name " + v.Name
+		}
+		return line
+	default:
+		return fmt.Sprintf("%s: %v", field, v)
+	}
+}
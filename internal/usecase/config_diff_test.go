@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+func TestDiffONUConfigs(t *testing.T) {
+	oldONUs := []model.ONUConfigBackup{
+		{
+			PONPort: "1/1/1", ONUID: 1, SerialNumber: "ZTEGC0000001", Type: "F670L", Name: "customer-a", AdminState: "enabled",
+			TCONTs:       []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "UP-10M"}},
+			ServicePorts: []model.ONUServicePortConfig{{PortID: 1, VPort: 1, UserVLAN: 100, ServiceVLAN: 100}},
+			VLANs:        []model.ONUVLANConfig{{UserVLAN: 100, ServiceVLAN: 100, Mode: "tag"}},
+		},
+		{PONPort: "1/1/1", ONUID: 2, SerialNumber: "ZTEGC0000002", Type: "F609", OperState: "online"},
+		{PONPort: "1/1/10", ONUID: 1, SerialNumber: "ZTEGC0000010", Type: "F609"},
+	}
+	newONUs := []model.ONUConfigBackup{
+		{
+			PONPort: "1/1/1", ONUID: 1, SerialNumber: "ZTEGC0000001", Type: "F670L", Name: "customer-b", AdminState: "disabled",
+			TCONTs:       []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "UP-20M"}, {TCONTID: 2, ProfileName: "UP-VOIP"}},
+			ServicePorts: nil,
+			VLANs:        []model.ONUVLANConfig{{UserVLAN: 100, ServiceVLAN: 100, Mode: "tag"}},
+		},
+		// Operational state is not configuration
+		{PONPort: "1/1/1", ONUID: 2, SerialNumber: "ZTEGC0000002", Type: "F609", OperState: "offline"},
+		{PONPort: "1/1/2", ONUID: 7, SerialNumber: "ZTEGC0000007", Type: "F609"},
+	}
+
+	diffs, summary := diffONUConfigs(oldONUs, newONUs)
+
+	if summary != (model.DiffSummary{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}) {
+		t.Errorf("summary = %+v", summary)
+	}
+
+	// Ordered by PON numerically (1/1/2 before 1/1/10), then ONU ID
+	wantOrder := []struct {
+		pon    string
+		change string
+	}{
+		{"1/1/1", model.ChangeChanged},
+		{"1/1/2", model.ChangeAdded},
+		{"1/1/10", model.ChangeRemoved},
+	}
+	if len(diffs) != len(wantOrder) {
+		t.Fatalf("diffs = %+v", diffs)
+	}
+	for i, want := range wantOrder {
+		if diffs[i].PONPort != want.pon || diffs[i].Change != want.change {
+			t.Errorf("diffs[%d] = %s %s, want %s %s", i, diffs[i].PONPort, diffs[i].Change, want.pon, want.change)
+		}
+	}
+
+	fields := make(map[string]string)
+	for _, change := range diffs[0].Changes {
+		fields[change.Field] = change.Change
+	}
+	wantFields := map[string]string{
+		"name":           model.ChangeChanged,
+		"admin_state":    model.ChangeChanged,
+		"tcont 1":        model.ChangeChanged,
+		"tcont 2":        model.ChangeAdded,
+		"service_port 1": model.ChangeRemoved,
+	}
+	if len(fields) != len(wantFields) {
+		t.Errorf("changes = %+v, want %v", diffs[0].Changes, wantFields)
+	}
+	for field, change := range wantFields {
+		if fields[field] != change {
+			t.Errorf("field %q change = %q, want %q", field, fields[field], change)
+		}
+	}
+}
+
+func TestDetectDrift_RequiresTelnet(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil).(*configBackupUsecase)
+
+	backup := &model.ConfigBackup{
+		ID:        "drift-test",
+		Type:      "onu",
+		Timestamp: time.Now(),
+		Config:    newRestoreTestONU(),
+	}
+	if err := uc.saveBackup(backup); err != nil {
+		t.Fatalf("saveBackup() error = %v", err)
+	}
+
+	_, err := uc.DetectDrift("drift-test")
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeConfig {
+		t.Errorf("error = %v, want config error", err)
+	}
+
+	_, err = uc.DetectDrift("missing")
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("error = %v, want not found error", err)
+	}
+}
+
+func TestDiffBackups(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil).(*configBackupUsecase)
+
+	changedONU := *newRestoreTestONU()
+	changedONU.Name = "renamed"
+	addedONU := model.ONUConfigBackup{PONPort: "1/1/2", ONUID: 3, SerialNumber: "ZTEGC0000003", Type: "F609"}
+
+	backups := []*model.ConfigBackup{
+		{
+			ID: "diff-from", Type: "olt", Timestamp: time.Now(),
+			Config: &model.OLTConfigBackup{
+				ONUs:        []model.ONUConfigBackup{*newRestoreTestONU()},
+				DBAProfiles: []model.DBAProfileConfig{{Name: "UP-10M", Type: "4", Maximum: 10240}},
+			},
+		},
+		{
+			ID: "diff-to", Type: "olt", Timestamp: time.Now(),
+			Config: &model.OLTConfigBackup{
+				ONUs:        []model.ONUConfigBackup{changedONU, addedONU},
+				DBAProfiles: []model.DBAProfileConfig{{Name: "UP-10M", Type: "4", Maximum: 20480}},
+			},
+		},
+	}
+	for _, backup := range backups {
+		if err := uc.saveBackup(backup); err != nil {
+			t.Fatalf("saveBackup() error = %v", err)
+		}
+	}
+
+	diff, err := uc.DiffBackups("diff-from", "diff-to")
+	if err != nil {
+		t.Fatalf("DiffBackups() error = %v", err)
+	}
+	if diff.Identical {
+		t.Error("Identical = true, want false")
+	}
+	if diff.Summary != (model.DiffSummary{Added: 1, Changed: 1}) {
+		t.Errorf("summary = %+v", diff.Summary)
+	}
+	if len(diff.GlobalChanges) != 1 || diff.GlobalChanges[0].Field != "dba_profile UP-10M" {
+		t.Errorf("global changes = %+v", diff.GlobalChanges)
+	}
+
+	for _, want := range []string{
+		"--- backup diff-from",
+		"+++ backup diff-to",
+		"- gpon-onu-profile dba-profile UP-10M type 4 max 10240",
+		"+ gpon-onu-profile dba-profile UP-10M type 4 max 20480",
+		"@@ gpon-onu_1/1/1:5 (changed) @@",
+		"+ name: renamed",
+		"@@ gpon-onu_1/1/2:3 (added) @@",
+		"+ onu 3 type F609 sn ZTEGC0000003",
+	} {
+		if !strings.Contains(diff.Unified, want) {
+			t.Errorf("unified diff missing %q:\n%s", want, diff.Unified)
+		}
+	}
+
+	same, err := uc.DiffBackups("diff-from", "diff-from")
+	if err != nil {
+		t.Fatalf("DiffBackups() error = %v", err)
+	}
+	if !same.Identical || len(same.ONUs) != 0 {
+		t.Errorf("self diff = %+v, want identical", same)
+	}
+
+	var appErr *apperrors.AppError
+	if _, err := uc.DiffBackups("diff-from", ""); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeValidation {
+		t.Errorf("error = %v, want validation error", err)
+	}
+	if _, err := uc.DiffBackups("diff-from", "missing"); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("error = %v, want not found error", err)
+	}
+}
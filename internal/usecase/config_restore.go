@@ -0,0 +1,507 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// Restore item types accepted in RestoreRequest.RestoreItems and reported in RestoreItemResult.ItemType
+const (
+	restoreItemONU         = "onu"
+	restoreItemDBAProfile  = "dba_profile"
+	restoreItemCleanup     = "cleanup"
+	restoreItemTCONT       = "tcont"
+	restoreItemGEMPort     = "gemport"
+	restoreItemServicePort = "service_port"
+	restoreItemVLAN        = "vlan" // alias of service_port: ONU VLANs are carried by service-ports
+)
+
+// restoreStep is a group of CLI commands executed in a single config-mode session
+type restoreStep struct {
+	ItemType string
+	Commands []string
+	Skipped  string // reason the step needs no commands (target already matches the backup)
+}
+
+// restoreTarget identifies the ONU a backup is restored onto
+type restoreTarget struct {
+	PONPort string
+	ONUID   int
+}
+
+// onuTargetState is the configuration currently present on the restore target.
+// A nil state means the target could not be inspected and is assumed to be empty.
+type onuTargetState struct {
+	Declaration *model.ONUDeclaration
+	Running     *model.ONURunningConfig
+}
+
+// restorePlanError reports a conflict between the backup and the target that prevents planning
+type restorePlanError struct {
+	ItemType string
+	Message  string
+}
+
+func (e *restorePlanError) Error() string {
+	return e.Message
+}
+
+// parseRestoreItems converts RestoreRequest.RestoreItems into a set; an empty list selects every item
+func parseRestoreItems(items []string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	if len(items) == 0 {
+		for _, item := range []string{restoreItemONU, restoreItemDBAProfile, restoreItemTCONT, restoreItemGEMPort, restoreItemServicePort} {
+			selected[item] = true
+		}
+		return selected, nil
+	}
+
+	for _, item := range items {
+		switch item {
+		case restoreItemONU, restoreItemDBAProfile, restoreItemTCONT, restoreItemGEMPort, restoreItemServicePort:
+			selected[item] = true
+		case restoreItemVLAN:
+			selected[restoreItemServicePort] = true
+		default:
+			return nil, apperrors.NewValidationError("invalid restore item", map[string]interface{}{
+				"item":    item,
+				"allowed": []string{restoreItemONU, restoreItemDBAProfile, restoreItemTCONT, restoreItemGEMPort, restoreItemServicePort, restoreItemVLAN},
+			})
+		}
+	}
+
+	return selected, nil
+}
+
+// buildONURestorePlan turns an ONU backup into the ordered CLI sequence needed to recreate it on the target:
+// ONU declaration, cleanup of conflicting entries (overwrite only), TCONTs, GEM ports and service-ports.
+func buildONURestorePlan(onu *model.ONUConfigBackup, target restoreTarget, state *onuTargetState, items map[string]bool, overwrite bool) ([]restoreStep, error) {
+	var steps []restoreStep
+	var running *model.ONURunningConfig
+	if state != nil {
+		running = state.Running
+	}
+
+	if items[restoreItemONU] {
+		if onu.SerialNumber == "" || onu.Type == "" {
+			return nil, &restorePlanError{
+				ItemType: restoreItemONU,
+				Message:  "backup has no serial number or ONU type, cannot declare ONU",
+			}
+		}
+
+		declare := onuDeclarationCommands(onu, target.ONUID)
+		switch {
+		case state == nil || state.Declaration == nil:
+			steps = append(steps, restoreStep{ItemType: restoreItemONU, Commands: wrapInterface("gpon-olt_"+target.PONPort, declare)})
+		case strings.EqualFold(state.Declaration.SerialNumber, onu.SerialNumber) && state.Declaration.Type == onu.Type:
+			steps = append(steps, restoreStep{ItemType: restoreItemONU, Skipped: "ONU already registered with the same serial number and type"})
+		case !overwrite:
+			return nil, &restorePlanError{
+				ItemType: restoreItemONU,
+				Message: fmt.Sprintf("ONU ID %d on %s is already registered as %s (%s); set overwrite to replace it",
+					target.ONUID, target.PONPort, state.Declaration.SerialNumber, state.Declaration.Type),
+			}
+		default:
+			// Removing the ONU also removes its TCONT, GEM port and service-port configuration
+			commands := append([]string{fmt.Sprintf("no onu %d", target.ONUID)}, declare...)
+			steps = append(steps, restoreStep{ItemType: restoreItemONU, Commands: wrapInterface("gpon-olt_"+target.PONPort, commands)})
+			running = nil
+		}
+		if onu.AdminState == "disabled" {
+			steps = append(steps, restoreStep{ItemType: restoreItemONU, Skipped: "the ONU is disabled in the backup; its admin state is not restored"})
+		}
+	} else if state != nil && state.Declaration == nil {
+		return nil, &restorePlanError{
+			ItemType: restoreItemONU,
+			Message:  fmt.Sprintf("ONU ID %d is not registered on %s; include \"onu\" in restore_items", target.ONUID, target.PONPort),
+		}
+	}
+
+	if running == nil {
+		running = &model.ONURunningConfig{}
+	}
+	onuInterface := fmt.Sprintf("gpon-onu_%s:%d", target.PONPort, target.ONUID)
+
+	var tconts, gemports, servicePorts []string
+	var servicePortCleanup, gemportCleanup, tcontCleanup []string
+
+	if items[restoreItemServicePort] {
+		existing := make(map[int]model.ONUServicePortConfig, len(running.ServicePorts))
+		for _, sp := range running.ServicePorts {
+			existing[sp.PortID] = sp
+		}
+		for _, sp := range onu.ServicePorts {
+			if current, ok := existing[sp.PortID]; ok {
+				if current.VPort == sp.VPort && current.UserVLAN == sp.UserVLAN && current.ServiceVLAN == sp.ServiceVLAN {
+					continue
+				}
+				if !overwrite {
+					return nil, &restorePlanError{
+						ItemType: restoreItemServicePort,
+						Message:  fmt.Sprintf("service-port %d already exists on %s with different VLANs; set overwrite to replace it", sp.PortID, onuInterface),
+					}
+				}
+				servicePortCleanup = append(servicePortCleanup, fmt.Sprintf("no service-port %d", sp.PortID))
+			}
+			servicePorts = append(servicePorts, servicePortCommand(sp))
+		}
+	}
+
+	// T-CONTs are planned first: the GEM ports bound to a replaced T-CONT have to be replaced as well
+	replacedTCONTs := make(map[int]bool)
+	if items[restoreItemTCONT] {
+		existing := make(map[int]model.ONUTCONTConfig, len(running.TCONTs))
+		for _, tcont := range running.TCONTs {
+			existing[tcont.TCONTID] = tcont
+		}
+		for _, tcont := range onu.TCONTs {
+			if current, ok := existing[tcont.TCONTID]; ok {
+				if current.ProfileName == tcont.ProfileName && current.Name == tcont.Name {
+					continue
+				}
+				if !overwrite {
+					return nil, &restorePlanError{
+						ItemType: restoreItemTCONT,
+						Message:  fmt.Sprintf("tcont %d already exists on %s with profile %s; set overwrite to replace it", tcont.TCONTID, onuInterface, current.ProfileName),
+					}
+				}
+				tcontCleanup = append(tcontCleanup, fmt.Sprintf("no tcont %d", tcont.TCONTID))
+				replacedTCONTs[tcont.TCONTID] = true
+			}
+			tconts = append(tconts, tcontCommand(tcont))
+		}
+	}
+
+	removedGEMPorts := make(map[int]bool)
+	if items[restoreItemGEMPort] {
+		existing := make(map[int]model.ONUGEMPortConfig, len(running.GEMPorts))
+		for _, gem := range running.GEMPorts {
+			existing[gem.GEMPortID] = gem
+		}
+		for _, gem := range onu.GEMPorts {
+			if current, ok := existing[gem.GEMPortID]; ok {
+				matches := current.TCONTID == gem.TCONTID && current.Name == gem.Name
+				if matches && !replacedTCONTs[current.TCONTID] {
+					continue
+				}
+				if !matches && !overwrite {
+					return nil, &restorePlanError{
+						ItemType: restoreItemGEMPort,
+						Message:  fmt.Sprintf("gemport %d already exists on %s with a different T-CONT; set overwrite to replace it", gem.GEMPortID, onuInterface),
+					}
+				}
+				gemportCleanup = append(gemportCleanup, fmt.Sprintf("no gemport %d", gem.GEMPortID))
+				removedGEMPorts[gem.GEMPortID] = true
+			}
+			gemports = append(gemports, gemportCommand(gem))
+		}
+	}
+
+	// A T-CONT cannot be removed while GEM ports are bound to it; the GEM ports that are not in the
+	// backup are removed with it and created again as they were
+	for _, gem := range running.GEMPorts {
+		if replacedTCONTs[gem.TCONTID] && !removedGEMPorts[gem.GEMPortID] {
+			gemportCleanup = append(gemportCleanup, fmt.Sprintf("no gemport %d", gem.GEMPortID))
+			gemports = append(gemports, gemportCommand(gem))
+		}
+	}
+
+	// Conflicting entries are removed in dependency order: service-port, gemport, tcont
+	cleanup := append(append(servicePortCleanup, gemportCleanup...), tcontCleanup...)
+	if len(cleanup) > 0 {
+		steps = append(steps, restoreStep{ItemType: restoreItemCleanup, Commands: wrapInterface(onuInterface, cleanup)})
+	}
+
+	for _, item := range []struct {
+		itemType string
+		commands []string
+	}{
+		{restoreItemTCONT, tconts},
+		{restoreItemGEMPort, gemports},
+		{restoreItemServicePort, servicePorts},
+	} {
+		if len(item.commands) > 0 {
+			// GEM ports removed with a T-CONT are created again even if they are not restored
+			steps = append(steps, restoreStep{ItemType: item.itemType, Commands: wrapInterface(onuInterface, item.commands)})
+		} else if items[item.itemType] {
+			steps = append(steps, restoreStep{ItemType: item.itemType, Skipped: "nothing to restore"})
+		}
+	}
+
+	return steps, nil
+}
+
+// onuDeclarationCommands builds the gpon-olt commands declaring an ONU and its attributes. The admin
+// state is not restored: the C320 has no command to disable an ONU under interface gpon-olt.
+func onuDeclarationCommands(onu *model.ONUConfigBackup, onuID int) []string {
+	commands := []string{fmt.Sprintf("onu %d type %s sn %s", onuID, onu.Type, onu.SerialNumber)}
+
+	if onu.Name != "" {
+		commands = append(commands, fmt.Sprintf("onu %d name %s", onuID, quoteCLIValue(onu.Name)))
+	}
+	if description, ok := onu.CustomConfig["description"].(string); ok && description != "" {
+		commands = append(commands, fmt.Sprintf("onu %d description %s", onuID, quoteCLIValue(description)))
+	}
+
+	return commands
+}
+
+// quoteCLIValue quotes a name or description for the CLI, escaping backslashes and double quotes
+func quoteCLIValue(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// tcontCommand builds the gpon-onu command for a T-CONT
+func tcontCommand(tcont model.ONUTCONTConfig) string {
+	if tcont.Name != "" {
+		return fmt.Sprintf("tcont %d name %s profile %s", tcont.TCONTID, tcont.Name, tcont.ProfileName)
+	}
+	return fmt.Sprintf("tcont %d profile %s", tcont.TCONTID, tcont.ProfileName)
+}
+
+// gemportCommand builds the gpon-onu command for a GEM port
+func gemportCommand(gem model.ONUGEMPortConfig) string {
+	if gem.Name != "" {
+		return fmt.Sprintf("gemport %d name %s tcont %d", gem.GEMPortID, gem.Name, gem.TCONTID)
+	}
+	return fmt.Sprintf("gemport %d tcont %d", gem.GEMPortID, gem.TCONTID)
+}
+
+// servicePortCommand builds the gpon-onu command for a service-port; user VLAN 0 means untagged
+func servicePortCommand(sp model.ONUServicePortConfig) string {
+	userVLAN := "untagged"
+	if sp.UserVLAN > 0 {
+		userVLAN = strconv.Itoa(sp.UserVLAN)
+	}
+	return fmt.Sprintf("service-port %d vport %d user-vlan %s vlan %d", sp.PortID, sp.VPort, userVLAN, sp.ServiceVLAN)
+}
+
+// dbaProfileCommands builds the commands creating (or modifying) a DBA profile
+func dbaProfileCommands(profile model.DBAProfileConfig) ([]string, error) {
+	profileType, err := strconv.Atoi(profile.Type)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DBA profile type %q", profile.Type)
+	}
+
+	var typeCmd string
+	switch profileType {
+	case 1:
+		typeCmd = fmt.Sprintf("type 1 fix %d", profile.Fixed)
+	case 2:
+		typeCmd = fmt.Sprintf("type 2 assure %d", profile.Assured)
+	case 3, 5:
+		typeCmd = fmt.Sprintf("type %d assure %d max %d", profileType, profile.Assured, profile.Maximum)
+	case 4:
+		typeCmd = fmt.Sprintf("type 4 max %d", profile.Maximum)
+	default:
+		return nil, fmt.Errorf("invalid DBA profile type %d", profileType)
+	}
+
+	return []string{
+		fmt.Sprintf("gpon-onu-profile dba-profile %s", profile.Name),
+		typeCmd,
+		"exit",
+	}, nil
+}
+
+// wrapInterface enters an interface, runs the commands and leaves it again
+func wrapInterface(iface string, commands []string) []string {
+	wrapped := make([]string, 0, len(commands)+2)
+	wrapped = append(wrapped, "interface "+iface)
+	wrapped = append(wrapped, commands...)
+	return append(wrapped, "exit")
+}
+
+// restoreONU restores a single ONU configuration onto the target and reports one result per step
+func (u *configBackupUsecase) restoreONU(ctx context.Context, onuConfig *model.ONUConfigBackup, target restoreTarget, items map[string]bool, req *model.RestoreRequest) ([]model.RestoreItemResult, bool) {
+	newResult := func(itemType string) model.RestoreItemResult {
+		return model.RestoreItemResult{PONPort: target.PONPort, ONUID: target.ONUID, ItemType: itemType}
+	}
+
+	if u.telnetSessionManager == nil && !req.DryRun {
+		result := newResult(restoreItemONU)
+		result.Error = "Telnet is not configured"
+		return []model.RestoreItemResult{result}, false
+	}
+
+	// Inspect the target so only missing or conflicting entries are touched
+	var state *onuTargetState
+	if u.telnetSessionManager != nil {
+		inspected, err := u.inspectRestoreTarget(ctx, target)
+		if err != nil {
+			result := newResult(restoreItemONU)
+			result.Error = fmt.Sprintf("failed to read target configuration: %v", err)
+			return []model.RestoreItemResult{result}, false
+		}
+		state = inspected
+	}
+
+	steps, err := buildONURestorePlan(onuConfig, target, state, items, req.Overwrite)
+	if err != nil {
+		itemType := restoreItemONU
+		if planErr, ok := err.(*restorePlanError); ok {
+			itemType = planErr.ItemType
+		}
+		result := newResult(itemType)
+		result.Error = err.Error()
+		return []model.RestoreItemResult{result}, false
+	}
+
+	results := make([]model.RestoreItemResult, 0, len(steps))
+	failed := false
+	for _, step := range steps {
+		result := newResult(step.ItemType)
+		result.Commands = step.Commands
+
+		switch {
+		case step.Skipped != "":
+			result.Success = true
+			result.Message = "Skipped: " + step.Skipped
+		case failed:
+			result.Message = "Skipped: previous step failed"
+		case req.DryRun:
+			result.Success = true
+			result.Message = fmt.Sprintf("Dry run: %d commands would be executed", len(step.Commands))
+		default:
+			if err := u.executeRestoreStep(ctx, step); err != nil {
+				log.Error().
+					Err(err).
+					Str("pon_port", target.PONPort).
+					Int("onu_id", target.ONUID).
+					Str("item", step.ItemType).
+					Msg("Restore step failed")
+				result.Error = err.Error()
+				failed = true
+			} else {
+				result.Success = true
+				result.Message = "Restored"
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, !failed
+}
+
+// inspectRestoreTarget reads the declaration and running-config currently present on the target ONU ID
+func (u *configBackupUsecase) inspectRestoreTarget(ctx context.Context, target restoreTarget) (*onuTargetState, error) {
+	declarations, err := u.telnetSessionManager.GetPONRunningConfig(ctx, target.PONPort)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &onuTargetState{}
+	for i := range declarations {
+		if declarations[i].ONUID == target.ONUID {
+			state.Declaration = &declarations[i]
+			break
+		}
+	}
+	if state.Declaration == nil {
+		return state, nil
+	}
+
+	state.Running, err = u.telnetSessionManager.GetONURunningConfig(ctx, target.PONPort, target.ONUID)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// executeRestoreStep runs a step in config mode and fails on the first command the OLT rejects
+func (u *configBackupUsecase) executeRestoreStep(ctx context.Context, step restoreStep) error {
+	result, err := u.telnetSessionManager.ExecuteInConfigMode(ctx, step.Commands)
+	if err != nil {
+		return err
+	}
+
+	// ExecuteInConfigMode marks the commands the OLT rejected with a CLI error as failed
+	for _, resp := range result.Responses {
+		if !resp.Success {
+			return fmt.Errorf("command %q failed: %s", resp.Command, resp.Error)
+		}
+	}
+
+	return nil
+}
+
+// restoreDBAProfiles recreates the DBA profiles of an OLT backup before any T-CONT references them
+func (u *configBackupUsecase) restoreDBAProfiles(ctx context.Context, profiles []model.DBAProfileConfig, req *model.RestoreRequest) []model.RestoreItemResult {
+	existing := make(map[string]model.DBAProfileConfig)
+	if u.telnetSessionManager != nil {
+		current, err := u.telnetSessionManager.GetAllDBAProfiles(ctx)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to list DBA profiles, assuming none exist")
+		}
+		for _, profile := range current {
+			existing[profile.Name] = model.DBAProfileConfig{
+				Name:    profile.Name,
+				Type:    strconv.Itoa(profile.Type),
+				Fixed:   profile.FixedBandwidth,
+				Assured: profile.AssuredBandwidth,
+				Maximum: profile.MaxBandwidth,
+			}
+		}
+	}
+
+	sorted := append([]model.DBAProfileConfig(nil), profiles...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	results := make([]model.RestoreItemResult, 0, len(sorted))
+	for _, profile := range sorted {
+		result := model.RestoreItemResult{ItemType: restoreItemDBAProfile, Message: profile.Name}
+
+		if current, ok := existing[profile.Name]; ok {
+			if current == profile {
+				result.Success = true
+				result.Message = fmt.Sprintf("Skipped: DBA profile %s already matches", profile.Name)
+				results = append(results, result)
+				continue
+			}
+			if !req.Overwrite {
+				result.Error = fmt.Sprintf("DBA profile %s already exists with different bandwidth; set overwrite to replace it", profile.Name)
+				results = append(results, result)
+				continue
+			}
+		}
+
+		commands, err := dbaProfileCommands(profile)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Commands = commands
+
+		switch {
+		case req.DryRun:
+			result.Success = true
+			result.Message = fmt.Sprintf("Dry run: DBA profile %s would be restored", profile.Name)
+		case u.telnetSessionManager == nil:
+			result.Error = "Telnet is not configured"
+		default:
+			if err := u.executeRestoreStep(ctx, restoreStep{ItemType: restoreItemDBAProfile, Commands: commands}); err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Success = true
+				result.Message = fmt.Sprintf("DBA profile %s restored", profile.Name)
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
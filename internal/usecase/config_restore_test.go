@@ -0,0 +1,424 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func newRestoreTestONU() *model.ONUConfigBackup {
+	return &model.ONUConfigBackup{
+		PONPort:      "1/1/1",
+		ONUID:        5,
+		SerialNumber: "ZTEGC0000005",
+		Type:         "ZTE-F660",
+		Name:         "customer-5",
+		TCONTs: []model.ONUTCONTConfig{
+			{TCONTID: 1, Name: "TCONT_DATA", ProfileName: "UP-10M"},
+		},
+		GEMPorts: []model.ONUGEMPortConfig{
+			{GEMPortID: 1, Name: "GEM_DATA", TCONTID: 1},
+		},
+		ServicePorts: []model.ONUServicePortConfig{
+			{PortID: 1, VPort: 1, UserVLAN: 100, ServiceVLAN: 100},
+			{PortID: 2, VPort: 1, UserVLAN: 0, ServiceVLAN: 200},
+		},
+	}
+}
+
+func TestBuildONURestorePlan_EmptyTarget(t *testing.T) {
+	items, _ := parseRestoreItems(nil)
+
+	steps, err := buildONURestorePlan(newRestoreTestONU(), restoreTarget{PONPort: "1/2/3", ONUID: 9}, nil, items, false)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+
+	want := []restoreStep{
+		{ItemType: restoreItemONU, Commands: []string{
+			"interface gpon-olt_1/2/3",
+			"onu 9 type ZTE-F660 sn ZTEGC0000005",
+			`onu 9 name "customer-5"`,
+			"exit",
+		}},
+		{ItemType: restoreItemTCONT, Commands: []string{
+			"interface gpon-onu_1/2/3:9",
+			"tcont 1 name TCONT_DATA profile UP-10M",
+			"exit",
+		}},
+		{ItemType: restoreItemGEMPort, Commands: []string{
+			"interface gpon-onu_1/2/3:9",
+			"gemport 1 name GEM_DATA tcont 1",
+			"exit",
+		}},
+		{ItemType: restoreItemServicePort, Commands: []string{
+			"interface gpon-onu_1/2/3:9",
+			"service-port 1 vport 1 user-vlan 100 vlan 100",
+			"service-port 2 vport 1 user-vlan untagged vlan 200",
+			"exit",
+		}},
+	}
+
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("plan mismatch\ngot:  %+v\nwant: %+v", steps, want)
+	}
+}
+
+func TestBuildONURestorePlan_RestoreItems(t *testing.T) {
+	items, err := parseRestoreItems([]string{"vlan"})
+	if err != nil {
+		t.Fatalf("parseRestoreItems() error = %v", err)
+	}
+
+	state := &onuTargetState{
+		Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGC0000005"},
+		Running:     &model.ONURunningConfig{},
+	}
+	steps, err := buildONURestorePlan(newRestoreTestONU(), restoreTarget{PONPort: "1/1/1", ONUID: 5}, state, items, false)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+
+	if len(steps) != 1 || steps[0].ItemType != restoreItemServicePort {
+		t.Fatalf("steps = %+v, want a single service_port step", steps)
+	}
+}
+
+func TestBuildONURestorePlan_Conflicts(t *testing.T) {
+	items, _ := parseRestoreItems(nil)
+	target := restoreTarget{PONPort: "1/1/1", ONUID: 5}
+
+	tests := []struct {
+		name      string
+		state     *onuTargetState
+		overwrite bool
+		wantErr   string
+		wantSteps []string
+	}{
+		{
+			name: "different ONU without overwrite",
+			state: &onuTargetState{
+				Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGCAAAAAAA"},
+			},
+			wantErr: restoreItemONU,
+		},
+		{
+			name: "different ONU with overwrite",
+			state: &onuTargetState{
+				Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGCAAAAAAA"},
+				Running: &model.ONURunningConfig{
+					TCONTs: []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "OTHER"}},
+				},
+			},
+			overwrite: true,
+			wantSteps: []string{restoreItemONU, restoreItemTCONT, restoreItemGEMPort, restoreItemServicePort},
+		},
+		{
+			name: "conflicting tcont without overwrite",
+			state: &onuTargetState{
+				Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGC0000005"},
+				Running: &model.ONURunningConfig{
+					TCONTs: []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "OTHER"}},
+				},
+			},
+			wantErr: restoreItemTCONT,
+		},
+		{
+			name: "conflicting tcont with overwrite",
+			state: &onuTargetState{
+				Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGC0000005"},
+				Running: &model.ONURunningConfig{
+					TCONTs: []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "OTHER"}},
+				},
+			},
+			overwrite: true,
+			wantSteps: []string{restoreItemONU, restoreItemCleanup, restoreItemTCONT, restoreItemGEMPort, restoreItemServicePort},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := buildONURestorePlan(newRestoreTestONU(), target, tt.state, items, tt.overwrite)
+			if tt.wantErr != "" {
+				planErr, ok := err.(*restorePlanError)
+				if !ok {
+					t.Fatalf("error = %v, want *restorePlanError", err)
+				}
+				if planErr.ItemType != tt.wantErr {
+					t.Errorf("ItemType = %q, want %q", planErr.ItemType, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("buildONURestorePlan() error = %v", err)
+			}
+
+			var got []string
+			for _, step := range steps {
+				got = append(got, step.ItemType)
+			}
+			if !reflect.DeepEqual(got, tt.wantSteps) {
+				t.Errorf("steps = %v, want %v", got, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestBuildONURestorePlan_CleanupOrder(t *testing.T) {
+	items, _ := parseRestoreItems(nil)
+	state := &onuTargetState{
+		Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGC0000005"},
+		Running: &model.ONURunningConfig{
+			TCONTs:       []model.ONUTCONTConfig{{TCONTID: 1, ProfileName: "OTHER"}},
+			GEMPorts:     []model.ONUGEMPortConfig{{GEMPortID: 1, TCONTID: 2}},
+			ServicePorts: []model.ONUServicePortConfig{{PortID: 1, VPort: 1, UserVLAN: 300, ServiceVLAN: 300}},
+		},
+	}
+
+	steps, err := buildONURestorePlan(newRestoreTestONU(), restoreTarget{PONPort: "1/1/1", ONUID: 5}, state, items, true)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+
+	want := []string{"interface gpon-onu_1/1/1:5", "no service-port 1", "no gemport 1", "no tcont 1", "exit"}
+	if steps[1].ItemType != restoreItemCleanup || !reflect.DeepEqual(steps[1].Commands, want) {
+		t.Errorf("cleanup step = %+v, want commands %v", steps[1], want)
+	}
+}
+
+func TestBuildONURestorePlan_ReplacedTCONT(t *testing.T) {
+	state := &onuTargetState{
+		Declaration: &model.ONUDeclaration{ONUID: 5, Type: "ZTE-F660", SerialNumber: "ZTEGC0000005"},
+		Running: &model.ONURunningConfig{
+			TCONTs:       []model.ONUTCONTConfig{{TCONTID: 1, Name: "TCONT_DATA", ProfileName: "OTHER"}},
+			GEMPorts:     []model.ONUGEMPortConfig{{GEMPortID: 1, Name: "GEM_DATA", TCONTID: 1}, {GEMPortID: 2, TCONTID: 1}},
+			ServicePorts: newRestoreTestONU().ServicePorts,
+		},
+	}
+	target := restoreTarget{PONPort: "1/1/1", ONUID: 5}
+
+	// The GEM ports bound to the T-CONT are removed first and created again, also those not in the backup
+	items, _ := parseRestoreItems(nil)
+	steps, err := buildONURestorePlan(newRestoreTestONU(), target, state, items, true)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+	want := []restoreStep{
+		{ItemType: restoreItemONU, Skipped: "ONU already registered with the same serial number and type"},
+		{ItemType: restoreItemCleanup, Commands: []string{"interface gpon-onu_1/1/1:5", "no gemport 1", "no gemport 2", "no tcont 1", "exit"}},
+		{ItemType: restoreItemTCONT, Commands: []string{"interface gpon-onu_1/1/1:5", "tcont 1 name TCONT_DATA profile UP-10M", "exit"}},
+		{ItemType: restoreItemGEMPort, Commands: []string{"interface gpon-onu_1/1/1:5", "gemport 1 name GEM_DATA tcont 1", "gemport 2 tcont 1", "exit"}},
+		{ItemType: restoreItemServicePort, Skipped: "nothing to restore"},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("plan mismatch\ngot:  %+v\nwant: %+v", steps, want)
+	}
+
+	// Restoring only the T-CONT still creates its GEM ports again
+	items, _ = parseRestoreItems([]string{"tcont"})
+	steps, err = buildONURestorePlan(newRestoreTestONU(), target, state, items, true)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+	if len(steps) != 3 || steps[2].ItemType != restoreItemGEMPort || len(steps[2].Commands) != 4 {
+		t.Errorf("steps = %+v, want cleanup, tcont and the GEM ports created again", steps)
+	}
+}
+
+func TestBuildONURestorePlan_DisabledONU(t *testing.T) {
+	onu := newRestoreTestONU()
+	onu.AdminState = "disabled"
+	items, _ := parseRestoreItems([]string{"onu"})
+
+	steps, err := buildONURestorePlan(onu, restoreTarget{PONPort: "1/1/1", ONUID: 5}, nil, items, false)
+	if err != nil {
+		t.Fatalf("buildONURestorePlan() error = %v", err)
+	}
+	if len(steps) != 2 || steps[1].Skipped == "" {
+		t.Fatalf("steps = %+v, want the declaration and a note on the admin state", steps)
+	}
+	for _, command := range steps[0].Commands {
+		if strings.Contains(command, "state") {
+			t.Errorf("declaration has command %q, want no admin state command", command)
+		}
+	}
+}
+
+func TestParseRestoreItems_Invalid(t *testing.T) {
+	if _, err := parseRestoreItems([]string{"tcont", "firmware"}); err == nil {
+		t.Error("expected error for unknown restore item")
+	}
+}
+
+func TestDBAProfileCommands(t *testing.T) {
+	commands, err := dbaProfileCommands(model.DBAProfileConfig{Name: "UP-10M", Type: "3", Assured: 5120, Maximum: 10240})
+	if err != nil {
+		t.Fatalf("dbaProfileCommands() error = %v", err)
+	}
+	want := []string{"gpon-onu-profile dba-profile UP-10M", "type 3 assure 5120 max 10240", "exit"}
+	if !reflect.DeepEqual(commands, want) {
+		t.Errorf("commands = %v, want %v", commands, want)
+	}
+
+	if _, err := dbaProfileCommands(model.DBAProfileConfig{Name: "BAD", Type: "9"}); err == nil {
+		t.Error("expected error for invalid profile type")
+	}
+}
+
+func TestRestoreFromBackup_DryRun(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, nil, nil).(*configBackupUsecase)
+
+	backup := &model.ConfigBackup{
+		ID:        "restore-test",
+		Type:      "olt",
+		Timestamp: time.Now(),
+		Config: &model.OLTConfigBackup{
+			ONUs: []model.ONUConfigBackup{*newRestoreTestONU(), {PONPort: "1/1/2", ONUID: 1, SerialNumber: "ZTEGC0000001", Type: "F609"}},
+			DBAProfiles: []model.DBAProfileConfig{
+				{Name: "UP-10M", Type: "4", Maximum: 10240},
+			},
+		},
+	}
+	if err := uc.saveBackup(backup); err != nil {
+		t.Fatalf("saveBackup() error = %v", err)
+	}
+
+	result, err := uc.RestoreFromBackup(context.Background(), &model.RestoreRequest{
+		BackupID:  "restore-test",
+		TargetPON: "1/1/1",
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatalf("RestoreFromBackup() error = %v", err)
+	}
+
+	if !result.Success || result.RestoredONUs != 1 || result.FailedONUs != 0 {
+		t.Errorf("result = %+v, want 1 restored ONU", result)
+	}
+	// DBA profile + onu, tcont, gemport, service_port
+	if len(result.Details) != 5 {
+		t.Fatalf("details = %d, want 5", len(result.Details))
+	}
+	if result.Details[0].ItemType != restoreItemDBAProfile || len(result.Details[0].Commands) != 3 {
+		t.Errorf("first detail = %+v, want DBA profile with commands", result.Details[0])
+	}
+	for _, item := range result.Details[1:] {
+		if item.PONPort != "1/1/1" || item.ONUID != 5 || len(item.Commands) == 0 {
+			t.Errorf("detail = %+v, want commands for 1/1/1:5", item)
+		}
+	}
+
+	// Applying without Telnet must fail per ONU rather than report success
+	result, err = uc.RestoreFromBackup(context.Background(), &model.RestoreRequest{BackupID: "restore-test", TargetPON: "1/1/1"})
+	if err != nil {
+		t.Fatalf("RestoreFromBackup() error = %v", err)
+	}
+	if result.Success || result.FailedONUs != 1 {
+		t.Errorf("result = %+v, want failure without Telnet", result)
+	}
+}
+
+func TestRestoreFromBackup_Simulator(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	state := simulator.NewState()
+	state.AddDBAProfile(simulator.DBAProfile{Name: "UP-10M", Type: 4, Maximum: 10240})
+	if err := state.AddONU("1/1/1", simulator.ONU{
+		ID:           1,
+		Type:         "F670L",
+		SerialNumber: "ZTEGC0000001",
+		Name:         `customer "a" \ b`,
+		TCONTs:       []simulator.TCONT{{ID: 1, Name: "TCONT_DATA", Profile: "UP-10M"}},
+		GEMPorts:     []simulator.GEMPort{{ID: 1, Name: "GEM_DATA", TCONT: 1}},
+		ServicePorts: []simulator.ONUServicePort{{ID: 1, VPort: 1, UserVLAN: "100", VLAN: 100}},
+	}); err != nil {
+		t.Fatalf("AddONU() error = %v", err)
+	}
+	server, manager := newSimulatedOLT(t, state)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), manager)
+
+	backup, err := uc.BackupONU(context.Background(), "1/1/1", 1, "before maintenance", nil)
+	if err != nil {
+		t.Fatalf("BackupONU() error = %v", err)
+	}
+	onuBackup, ok := backup.Config.(*model.ONUConfigBackup)
+	if !ok {
+		t.Fatalf("Config type = %T, want *model.ONUConfigBackup", backup.Config)
+	}
+	if onuBackup.Name != `customer "a" \ b` {
+		t.Errorf("backup name = %q", onuBackup.Name)
+	}
+	if len(onuBackup.TCONTs) != 1 || len(onuBackup.GEMPorts) != 1 || len(onuBackup.ServicePorts) != 1 {
+		t.Fatalf("backup has %d T-CONTs, %d GEM ports and %d service ports, want 1 each",
+			len(onuBackup.TCONTs), len(onuBackup.GEMPorts), len(onuBackup.ServicePorts))
+	}
+
+	if _, err := NewProvisionUsecase(manager, cfg, nil).DeleteONU(context.Background(), "1/1/1", 1); err != nil {
+		t.Fatalf("DeleteONU() error = %v", err)
+	}
+
+	result, err := uc.RestoreFromBackup(context.Background(), &model.RestoreRequest{BackupID: backup.ID})
+	if err != nil {
+		t.Fatalf("RestoreFromBackup() error = %v", err)
+	}
+	if !result.Success || result.RestoredONUs != 1 {
+		t.Fatalf("RestoreFromBackup() = %+v", result)
+	}
+
+	restored, ok := server.State().ONU("1/1/1", 1)
+	if !ok {
+		t.Fatal("ONU 1/1/1:1 was not restored")
+	}
+	if restored.SerialNumber != "ZTEGC0000001" || restored.Name != `customer "a" \ b` {
+		t.Errorf("restored ONU = %+v", restored)
+	}
+	if len(restored.TCONTs) != 1 || restored.TCONTs[0].Profile != "UP-10M" || len(restored.GEMPorts) != 1 {
+		t.Errorf("restored T-CONTs = %+v, GEM ports = %+v", restored.TCONTs, restored.GEMPorts)
+	}
+	if len(restored.ServicePorts) != 1 || restored.ServicePorts[0].VLAN != 100 {
+		t.Errorf("restored service ports = %+v", restored.ServicePorts)
+	}
+}
+
+func TestRestoreFromBackup_SimulatorReplacesTCONT(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	state := simulator.NewState()
+	state.AddDBAProfile(simulator.DBAProfile{Name: "UP-10M", Type: 4, Maximum: 10240})
+	state.AddDBAProfile(simulator.DBAProfile{Name: "UP-20M", Type: 4, Maximum: 20480})
+	if err := state.AddONU("1/1/1", simulator.ONU{
+		ID:           5,
+		Type:         "ZTE-F660",
+		SerialNumber: "ZTEGC0000005",
+		TCONTs:       []simulator.TCONT{{ID: 1, Name: "TCONT_DATA", Profile: "UP-20M"}},
+		GEMPorts:     []simulator.GEMPort{{ID: 1, Name: "GEM_DATA", TCONT: 1}, {ID: 2, Name: "GEM_IPTV", TCONT: 1}},
+	}); err != nil {
+		t.Fatalf("AddONU() error = %v", err)
+	}
+	server, manager := newSimulatedOLT(t, state)
+	uc := NewConfigBackupUsecase(cfg, nil, nil, manager).(*configBackupUsecase)
+
+	if err := uc.saveBackup(&model.ConfigBackup{ID: "tcont-test", Type: "onu", Timestamp: time.Now(), Config: newRestoreTestONU()}); err != nil {
+		t.Fatalf("saveBackup() error = %v", err)
+	}
+	result, err := uc.RestoreFromBackup(context.Background(), &model.RestoreRequest{BackupID: "tcont-test", Overwrite: true})
+	if err != nil {
+		t.Fatalf("RestoreFromBackup() error = %v", err)
+	}
+	if !result.Success || result.RestoredONUs != 1 {
+		t.Fatalf("RestoreFromBackup() = %+v", result)
+	}
+
+	restored, _ := server.State().ONU("1/1/1", 5)
+	if len(restored.TCONTs) != 1 || restored.TCONTs[0].Profile != "UP-10M" {
+		t.Errorf("restored T-CONTs = %+v, want profile UP-10M", restored.TCONTs)
+	}
+	if len(restored.GEMPorts) != 2 || len(restored.ServicePorts) != 2 {
+		t.Errorf("restored GEM ports = %+v, service ports = %+v, want both GEM ports and the backup's service ports",
+			restored.GEMPorts, restored.ServicePorts)
+	}
+}
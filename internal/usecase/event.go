@@ -0,0 +1,388 @@
+package usecase
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosnmp/gosnmp"
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+)
+
+// Standard varbinds of SNMPv2 notifications
+const (
+	oidSysUpTime          = ".1.3.6.1.2.1.1.3.0"
+	oidSnmpTrapOID        = ".1.3.6.1.6.3.1.1.4.1.0"
+	oidSnmpTrapEnterprise = ".1.3.6.1.6.3.1.1.4.3.0"
+)
+
+// Maximum number of events returned by ListEvents
+const maxEventLimit = 1000
+
+// TrapSource identifies an OLT by the addresses its notifications come from
+type TrapSource struct {
+	OLTID  string         // OLT the notifications belong to
+	Hosts  []string       // Addresses of the OLT (SNMP and Telnet host)
+	Config *config.Config // Configuration of the OLT; notifications are decoded with its current OID profile
+}
+
+// EventUsecase decodes SNMP traps and informs into typed OLT events
+type EventUsecase interface {
+	// HandleTrap decodes and stores a received notification; it is the trap listener callback
+	HandleTrap(packet *gosnmp.SnmpPacket, remote *net.UDPAddr)
+
+	// ListEvents returns the stored events matching the query, newest first
+	ListEvents(query model.EventQuery) ([]model.OLTEvent, error)
+}
+
+// EventPublisher receives every decoded event besides the event store (implemented by MonitoringStreamUsecase)
+type EventPublisher interface {
+	PublishEvent(event model.OLTEvent)
+}
+
+type eventUsecase struct {
+	store     repository.EventStore
+	publisher EventPublisher        // nil if events are only stored
+	community string                // Required of v1/v2c notifications; empty drops them
+	sources   map[string]TrapSource // By host
+	now       func() time.Time
+}
+
+// NewEventUsecase creates the event usecase for the given OLTs. Notifications from other addresses
+// are dropped. Decoded events are also passed to publisher, unless it is nil.
+func NewEventUsecase(cfg *config.Config, store repository.EventStore, sources []TrapSource, publisher EventPublisher) EventUsecase {
+	u := &eventUsecase{
+		store:     store,
+		publisher: publisher,
+		sources:   make(map[string]TrapSource),
+		now:       time.Now,
+	}
+	if cfg != nil {
+		u.community = cfg.Trap.Community
+	}
+
+	for _, source := range sources {
+		for _, host := range source.Hosts {
+			if host != "" {
+				u.sources[host] = source
+			}
+		}
+	}
+
+	return u
+}
+
+// HandleTrap decodes and stores a received notification
+func (u *eventUsecase) HandleTrap(packet *gosnmp.SnmpPacket, remote *net.UDPAddr) {
+	if packet == nil || remote == nil {
+		return
+	}
+	address := remote.IP.String()
+
+	if packet.Version != gosnmp.Version3 && (u.community == "" || packet.Community != u.community) {
+		log.Warn().Str("source", address).Msg("Dropped SNMP trap with wrong community")
+		return
+	}
+
+	source, ok := u.sources[address]
+	if !ok {
+		log.Warn().Str("source", address).Msg("Dropped SNMP trap from unknown OLT")
+		return
+	}
+	if packet.Version == gosnmp.Version3 && !trapUserAllowed(packet, source.Config.SnmpCfg) {
+		log.Warn().Str("source", address).Str("olt_id", source.OLTID).Msg("Dropped SNMPv3 trap with another user or a lower security level than the OLT's")
+		return
+	}
+
+	_, profile := source.Config.FirmwareProfile()
+	event := decodeTrap(packet, profile)
+	event.ID = uuid.New().String()
+	event.OLTID = source.OLTID
+	event.Source = address
+	event.ReceivedAt = u.now()
+
+	u.store.Add(event)
+	if u.publisher != nil {
+		u.publisher.PublishEvent(event)
+	}
+
+	log.Info().
+		Str("olt_id", event.OLTID).
+		Str("type", event.Type).
+		Str("severity", event.Severity).
+		Str("trap_oid", event.TrapOID).
+		Msg(event.Message)
+}
+
+// trapUserAllowed reports whether an SNMPv3 notification was sent with the user of the OLT and at
+// least its security level. The listener checked the credentials of the user, but knows every OLT's.
+func trapUserAllowed(packet *gosnmp.SnmpPacket, snmpCfg config.SnmpConfig) bool {
+	usm, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || !snmpCfg.IsV3() || usm.UserName != snmpCfg.User {
+		return false
+	}
+
+	required := gosnmp.NoAuthNoPriv
+	switch snmpCfg.SecurityLevel() {
+	case "authPriv":
+		required = gosnmp.AuthPriv
+	case "authNoPriv":
+		required = gosnmp.AuthNoPriv
+	}
+	return packet.MsgFlags&required == required
+}
+
+// ListEvents validates the query and returns the matching events
+func (u *eventUsecase) ListEvents(query model.EventQuery) ([]model.OLTEvent, error) {
+	if query.Type != "" && !isEventType(query.Type) {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("unknown event type %q (valid: %s)", query.Type, strings.Join(model.EventTypes, ", ")), map[string]interface{}{
+			"type": query.Type,
+		})
+	}
+	if query.Limit < 0 || query.Limit > maxEventLimit {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxEventLimit), map[string]interface{}{
+			"limit": query.Limit,
+		})
+	}
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	if !query.Since.IsZero() && !query.Until.IsZero() && !query.Since.Before(query.Until) {
+		return nil, apperrors.NewValidationError("since must be before until", nil)
+	}
+
+	return u.store.List(query), nil
+}
+
+// isEventType reports whether t is a known event type
+func isEventType(t string) bool {
+	for _, eventType := range model.EventTypes {
+		if eventType == t {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeTrap normalizes a notification using the OID profile of the OLT that sent it
+func decodeTrap(packet *gosnmp.SnmpPacket, profile *config.OIDProfile) model.OLTEvent {
+	event := model.OLTEvent{
+		Inform:    packet.PDUType == gosnmp.InformRequest,
+		Variables: make(map[string]string),
+	}
+
+	var payload []gosnmp.SnmpPDU
+	for _, pdu := range packet.Variables {
+		name := normalizeOID(pdu.Name)
+		switch name {
+		case oidSysUpTime, oidSnmpTrapEnterprise:
+		case oidSnmpTrapOID:
+			if value, ok := pdu.Value.(string); ok {
+				event.TrapOID = normalizeOID(value)
+			}
+		default:
+			event.Variables[name] = formatTrapValue(pdu.Value)
+			payload = append(payload, pdu)
+		}
+	}
+
+	// SNMPv1 traps carry the notification as enterprise and specific trap (RFC 3584)
+	if event.TrapOID == "" && packet.PDUType == gosnmp.Trap {
+		event.TrapOID = fmt.Sprintf("%s.0.%d", normalizeOID(packet.Enterprise), packet.SpecificTrap)
+	}
+
+	event.Type = trapEventType(event.TrapOID, profile)
+	switch event.Type {
+	case model.EventOnuStateChange, model.EventOnuDyingGasp, model.EventOnuLOS:
+		decodeONUEvent(&event, payload, profile)
+	case model.EventPonPortDown, model.EventPonPortUp:
+		decodePONEvent(&event, payload, profile)
+	case model.EventCardOffline, model.EventCardOnline:
+		decodeCardEvent(&event, payload)
+	default:
+		event.Message = fmt.Sprintf("Unrecognized notification %s", event.TrapOID)
+	}
+	event.Severity = eventSeverity(event.Type)
+
+	return event
+}
+
+// trapEventType maps a notification OID to an event type
+func trapEventType(trapOID string, profile *config.OIDProfile) string {
+	if profile == nil || trapOID == "" {
+		return model.EventUnknown
+	}
+
+	types := map[string]string{
+		profile.TrapOnuStatusChangeOID: model.EventOnuStateChange,
+		profile.TrapOnuDyingGaspOID:    model.EventOnuDyingGasp,
+		profile.TrapOnuLOSOID:          model.EventOnuLOS,
+		profile.TrapPonPortDownOID:     model.EventPonPortDown,
+		profile.TrapPonPortUpOID:       model.EventPonPortUp,
+		profile.TrapCardOfflineOID:     model.EventCardOffline,
+		profile.TrapCardOnlineOID:      model.EventCardOnline,
+	}
+	for oid, eventType := range types {
+		if oid != "" && normalizeOID(oid) == trapOID {
+			return eventType
+		}
+	}
+
+	return model.EventUnknown
+}
+
+// decodeONUEvent locates the ONU from the {pon_index}.{onu_id} varbind index and resolves
+// state changes into online/offline/dying gasp/LOS from the reported ONU state
+func decodeONUEvent(event *model.OLTEvent, payload []gosnmp.SnmpPDU, profile *config.OIDProfile) {
+	statusPrefix := normalizeOID(profile.BaseOID+profile.OnuStatusIDPrefix) + "."
+
+	for _, pdu := range payload {
+		index := oidIndex(pdu.Name, 2)
+		if index == nil {
+			continue
+		}
+		if event.ONUID == 0 {
+			if board, pon, ok := profile.PonFromIndex(index[0]); ok && index[1] >= 1 && index[1] <= 128 {
+				event.Board, event.PON, event.ONUID = board, pon, index[1]
+			}
+		}
+		if strings.HasPrefix(normalizeOID(pdu.Name), statusPrefix) {
+			event.Status = utils.ExtractAndGetStatus(pdu.Value)
+		}
+	}
+
+	if event.Type == model.EventOnuStateChange {
+		switch event.Status {
+		case "Online":
+			event.Type = model.EventOnuOnline
+		case "Offline", "Auth Failed":
+			event.Type = model.EventOnuOffline
+		case "Dying Gasp":
+			event.Type = model.EventOnuDyingGasp
+		case "LOS":
+			event.Type = model.EventOnuLOS
+		}
+	}
+
+	onu := "unknown ONU"
+	if event.ONUID != 0 {
+		onu = fmt.Sprintf("ONU gpon-onu_1/%d/%d:%d", event.Board, event.PON, event.ONUID)
+	}
+	switch event.Type {
+	case model.EventOnuOnline:
+		event.Message = onu + " is online"
+	case model.EventOnuOffline:
+		event.Message = fmt.Sprintf("%s went offline (%s)", onu, event.Status)
+	case model.EventOnuDyingGasp:
+		event.Message = onu + " sent a dying gasp (power failure)"
+	case model.EventOnuLOS:
+		event.Message = onu + " lost the optical signal (LOS)"
+	default:
+		event.Message = fmt.Sprintf("%s changed state to %s", onu, valueOrUnknown(event.Status))
+	}
+}
+
+// decodePONEvent locates the PON port from the {pon_index} varbind index
+func decodePONEvent(event *model.OLTEvent, payload []gosnmp.SnmpPDU, profile *config.OIDProfile) {
+	for _, pdu := range payload {
+		if index := oidIndex(pdu.Name, 1); index != nil {
+			if board, pon, ok := profile.PonFromIndex(index[0]); ok {
+				event.Board, event.PON = board, pon
+				break
+			}
+		}
+	}
+
+	port := "unknown PON port"
+	if event.PON != 0 {
+		port = fmt.Sprintf("PON port gpon-olt_1/%d/%d", event.Board, event.PON)
+	}
+	if event.Type == model.EventPonPortDown {
+		event.Message = port + " is down"
+	} else {
+		event.Message = port + " is up"
+	}
+}
+
+// decodeCardEvent locates the card from the {rack}.{shelf}.{slot} varbind index
+func decodeCardEvent(event *model.OLTEvent, payload []gosnmp.SnmpPDU) {
+	for _, pdu := range payload {
+		if index := oidIndex(pdu.Name, 3); index != nil {
+			event.Rack, event.Shelf, event.Slot = index[0], index[1], index[2]
+			break
+		}
+	}
+
+	card := "Unknown card"
+	if event.Slot != 0 {
+		card = fmt.Sprintf("Card %d/%d/%d", event.Rack, event.Shelf, event.Slot)
+	}
+	if event.Type == model.EventCardOffline {
+		event.Message = card + " is offline"
+	} else {
+		event.Message = card + " is online"
+	}
+}
+
+// eventSeverity rates an event type
+func eventSeverity(eventType string) string {
+	switch eventType {
+	case model.EventOnuLOS, model.EventPonPortDown, model.EventCardOffline:
+		return model.SeverityCritical
+	case model.EventOnuOffline, model.EventOnuDyingGasp:
+		return model.SeverityWarning
+	default:
+		return model.SeverityInfo
+	}
+}
+
+// oidIndex returns the last n sub-identifiers of an OID, or nil if there are fewer or they are not numeric
+func oidIndex(oid string, n int) []int {
+	parts := strings.Split(strings.Trim(oid, "."), ".")
+	if len(parts) < n {
+		return nil
+	}
+
+	index := make([]int, n)
+	for i, part := range parts[len(parts)-n:] {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return nil
+		}
+		index[i] = value
+	}
+	return index
+}
+
+// normalizeOID returns an OID with a single leading dot
+func normalizeOID(oid string) string {
+	return "." + strings.TrimLeft(oid, ".")
+}
+
+// formatTrapValue renders a varbind value for the event
+func formatTrapValue(value interface{}) string {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// valueOrUnknown returns s, or "unknown" if it is empty
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,258 @@
+package usecase
+
+import (
+	"net"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// newTrapPacket builds an SNMPv2c notification as sent by the OLT
+func newTrapPacket(community, trapOID string, variables ...gosnmp.SnmpPDU) *gosnmp.SnmpPacket {
+	return &gosnmp.SnmpPacket{
+		Version:   gosnmp.Version2c,
+		Community: community,
+		PDUType:   gosnmp.SNMPv2Trap,
+		Variables: append([]gosnmp.SnmpPDU{
+			{Name: oidSysUpTime, Type: gosnmp.TimeTicks, Value: uint32(100)},
+			{Name: oidSnmpTrapOID, Type: gosnmp.ObjectIdentifier, Value: trapOID},
+		}, variables...),
+	}
+}
+
+// testTrapProfile returns the firmware profile with notification OIDs configured as for an OLT of the
+// registry file. The OIDs are under the example enterprise number of RFC 5612, not ZTE's.
+func testTrapProfile(firmware config.FirmwareVersion) *config.OIDProfile {
+	device := config.OLTDeviceConfig{ID: "olt-1", Firmware: firmware, Traps: config.OLTTrapConfig{
+		OnuStatusChange: ".1.3.6.1.4.1.32473.1.0.1",
+		OnuDyingGasp:    ".1.3.6.1.4.1.32473.1.0.2",
+		OnuLOS:          ".1.3.6.1.4.1.32473.1.0.3",
+		PonPortDown:     ".1.3.6.1.4.1.32473.2.0.1",
+		PonPortUp:       ".1.3.6.1.4.1.32473.2.0.2",
+		CardOffline:     ".1.3.6.1.4.1.32473.3.0.1",
+		CardOnline:      ".1.3.6.1.4.1.32473.3.0.2",
+	}}
+	return device.OIDProfile()
+}
+
+func newTestEventUsecase(community string, sources ...TrapSource) (EventUsecase, repository.EventStore) {
+	store := repository.NewMemoryEventStore(100)
+	cfg := &config.Config{Trap: config.TrapConfig{Community: community}}
+	return NewEventUsecase(cfg, store, sources, nil), store
+}
+
+func TestEventUsecase_HandleTrap(t *testing.T) {
+	profile := testTrapProfile(config.FirmwareV22)
+	statusOID := profile.BaseOID + profile.OnuStatusIDPrefix
+
+	tests := []struct {
+		name   string
+		packet *gosnmp.SnmpPacket
+		want   model.OLTEvent
+	}{
+		{
+			name: "ONU offline from status change",
+			packet: newTrapPacket("public", ".1.3.6.1.4.1.32473.1.0.1",
+				gosnmp.SnmpPDU{Name: statusOID + ".285278467.12", Type: gosnmp.Integer, Value: 7}),
+			want: model.OLTEvent{Type: model.EventOnuOffline, Severity: model.SeverityWarning, Board: 1, PON: 3, ONUID: 12, Status: "Offline"},
+		},
+		{
+			name: "ONU online from status change",
+			packet: newTrapPacket("public", ".1.3.6.1.4.1.32473.1.0.1",
+				gosnmp.SnmpPDU{Name: statusOID + ".285278721.1", Type: gosnmp.Integer, Value: 4}),
+			want: model.OLTEvent{Type: model.EventOnuOnline, Severity: model.SeverityInfo, Board: 2, PON: 1, ONUID: 1, Status: "Online"},
+		},
+		{
+			name: "Dying gasp",
+			packet: newTrapPacket("public", ".1.3.6.1.4.1.32473.1.0.2",
+				gosnmp.SnmpPDU{Name: statusOID + ".285278465.5", Type: gosnmp.Integer, Value: 5}),
+			want: model.OLTEvent{Type: model.EventOnuDyingGasp, Severity: model.SeverityWarning, Board: 1, PON: 1, ONUID: 5, Status: "Dying Gasp"},
+		},
+		{
+			name: "PON port down",
+			packet: newTrapPacket("public", ".1.3.6.1.4.1.32473.2.0.1",
+				gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.8.285278466", Type: gosnmp.Integer, Value: 2}),
+			want: model.OLTEvent{Type: model.EventPonPortDown, Severity: model.SeverityCritical, Board: 1, PON: 2},
+		},
+		{
+			name: "Card offline",
+			packet: newTrapPacket("public", ".1.3.6.1.4.1.32473.3.0.1",
+				gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.3902.1015.2.1.1.3.1.4.1.1.3", Type: gosnmp.Integer, Value: 2}),
+			want: model.OLTEvent{Type: model.EventCardOffline, Severity: model.SeverityCritical, Rack: 1, Shelf: 1, Slot: 3},
+		},
+		{
+			name:   "Unknown notification",
+			packet: newTrapPacket("public", ".1.3.6.1.6.3.1.1.5.3"),
+			want:   model.OLTEvent{Type: model.EventUnknown, Severity: model.SeverityInfo},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, store := newTestEventUsecase("public", TrapSource{OLTID: "olt-1", Hosts: []string{"10.0.0.1"}, Config: &config.Config{OIDProfile: profile}})
+
+			u.HandleTrap(tt.packet, &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 162})
+
+			events := store.List(model.EventQuery{})
+			if len(events) != 1 {
+				t.Fatalf("stored %d events, want 1", len(events))
+			}
+			got := events[0]
+			if got.Type != tt.want.Type || got.Severity != tt.want.Severity {
+				t.Errorf("type/severity = %s/%s, want %s/%s", got.Type, got.Severity, tt.want.Type, tt.want.Severity)
+			}
+			if got.Board != tt.want.Board || got.PON != tt.want.PON || got.ONUID != tt.want.ONUID {
+				t.Errorf("location = %d/%d:%d, want %d/%d:%d", got.Board, got.PON, got.ONUID, tt.want.Board, tt.want.PON, tt.want.ONUID)
+			}
+			if got.Rack != tt.want.Rack || got.Shelf != tt.want.Shelf || got.Slot != tt.want.Slot {
+				t.Errorf("card = %d/%d/%d, want %d/%d/%d", got.Rack, got.Shelf, got.Slot, tt.want.Rack, tt.want.Shelf, tt.want.Slot)
+			}
+			if got.Status != tt.want.Status {
+				t.Errorf("status = %q, want %q", got.Status, tt.want.Status)
+			}
+			if got.ID == "" || got.OLTID != "olt-1" || got.Source != "10.0.0.1" || got.Message == "" {
+				t.Errorf("event metadata not set: %+v", got)
+			}
+			if _, ok := got.Variables[oidSnmpTrapOID]; ok {
+				t.Errorf("snmpTrapOID.0 must not be listed as variable")
+			}
+		})
+	}
+}
+
+func TestEventUsecase_HandleTrap_SNMPv1(t *testing.T) {
+	profile := testTrapProfile(config.FirmwareV21)
+	u, store := newTestEventUsecase("public", TrapSource{OLTID: "olt-1", Hosts: []string{"192.0.2.1"}, Config: &config.Config{OIDProfile: profile}})
+
+	// .1.3.6.1.4.1.32473.2.0.1 as enterprise + specific trap
+	u.HandleTrap(&gosnmp.SnmpPacket{
+		Version:   gosnmp.Version1,
+		Community: "public",
+		PDUType:   gosnmp.Trap,
+		SnmpTrap:  gosnmp.SnmpTrap{Enterprise: ".1.3.6.1.4.1.32473.2", GenericTrap: 6, SpecificTrap: 1},
+		Variables: []gosnmp.SnmpPDU{{Name: ".1.3.6.1.2.1.2.2.1.8.268501248", Type: gosnmp.Integer, Value: 2}},
+	}, &net.UDPAddr{IP: net.ParseIP("192.0.2.1")})
+
+	events := store.List(model.EventQuery{})
+	if len(events) != 1 {
+		t.Fatalf("stored %d events, want 1", len(events))
+	}
+	if events[0].Type != model.EventPonPortDown || events[0].Board != 1 || events[0].PON != 1 {
+		t.Errorf("event = %s on %d/%d, want pon_port_down on 1/1", events[0].Type, events[0].Board, events[0].PON)
+	}
+}
+
+func TestEventUsecase_HandleTrap_Dropped(t *testing.T) {
+	profile := testTrapProfile(config.FirmwareV22)
+	sources := []TrapSource{
+		{OLTID: "olt-1", Hosts: []string{"10.0.0.1"}, Config: &config.Config{OIDProfile: profile}},
+		{OLTID: "olt-2", Hosts: []string{"10.0.0.2"}, Config: &config.Config{OIDProfile: profile}},
+	}
+	u, store := newTestEventUsecase("secret", sources...)
+
+	// Wrong community
+	u.HandleTrap(newTrapPacket("public", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.1")})
+	// Unknown source
+	u.HandleTrap(newTrapPacket("secret", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.9")})
+
+	// Without a community no v1/v2c notification is accepted
+	withoutCommunity, withoutCommunityStore := newTestEventUsecase("", sources[0])
+	withoutCommunity.HandleTrap(newTrapPacket("", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.1")})
+	withoutCommunity.HandleTrap(newTrapPacket("public", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.1")})
+	// An unknown source is not taken for the only registered OLT
+	singleOLT, singleOLTStore := newTestEventUsecase("secret", sources[0])
+	singleOLT.HandleTrap(newTrapPacket("secret", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.9")})
+
+	for _, s := range []repository.EventStore{store, withoutCommunityStore, singleOLTStore} {
+		if events := s.List(model.EventQuery{}); len(events) != 0 {
+			t.Fatalf("stored %d events, want 0", len(events))
+		}
+	}
+
+	u.HandleTrap(newTrapPacket("secret", ".1.3.6.1.4.1.32473.2.0.1"), &net.UDPAddr{IP: net.ParseIP("10.0.0.2")})
+	events := store.List(model.EventQuery{})
+	if len(events) != 1 || events[0].OLTID != "olt-2" {
+		t.Fatalf("events = %+v, want one event of olt-2", events)
+	}
+}
+
+func TestEventUsecase_HandleTrap_SNMPv3(t *testing.T) {
+	profile := testTrapProfile(config.FirmwareV22)
+	snmpCfg := config.SnmpConfig{Version: config.SnmpVersion3, User: "nms", AuthProtocol: "SHA", AuthPassphrase: "auth-secret", PrivProtocol: "AES", PrivPassphrase: "priv-secret"}
+	sources := []TrapSource{
+		{OLTID: "olt-1", Hosts: []string{"10.0.0.1"}, Config: &config.Config{OIDProfile: profile, SnmpCfg: snmpCfg}},
+		{OLTID: "olt-2", Hosts: []string{"10.0.0.2"}, Config: &config.Config{OIDProfile: profile, SnmpCfg: config.SnmpConfig{Community: "public"}}},
+	}
+	u, store := newTestEventUsecase("secret", sources...)
+
+	inform := func(user string, flags gosnmp.SnmpV3MsgFlags) *gosnmp.SnmpPacket {
+		packet := newTrapPacket("", ".1.3.6.1.4.1.32473.2.0.1")
+		packet.Version, packet.PDUType, packet.MsgFlags = gosnmp.Version3, gosnmp.InformRequest, flags
+		packet.SecurityParameters = &gosnmp.UsmSecurityParameters{UserName: user}
+		return packet
+	}
+	olt1 := &net.UDPAddr{IP: net.ParseIP("10.0.0.1")}
+
+	// Another OLT's user, a lower security level and an OLT without SNMPv3 are dropped
+	u.HandleTrap(inform("noc", gosnmp.AuthPriv), olt1)
+	u.HandleTrap(inform("nms", gosnmp.AuthNoPriv), olt1)
+	u.HandleTrap(inform("nms", gosnmp.AuthPriv), &net.UDPAddr{IP: net.ParseIP("10.0.0.2")})
+	if events := store.List(model.EventQuery{}); len(events) != 0 {
+		t.Fatalf("stored %d events, want 0", len(events))
+	}
+
+	u.HandleTrap(inform("nms", gosnmp.AuthPriv|gosnmp.Reportable), olt1)
+	events := store.List(model.EventQuery{})
+	if len(events) != 1 || events[0].OLTID != "olt-1" || !events[0].Inform {
+		t.Fatalf("events = %+v, want one inform of olt-1", events)
+	}
+}
+
+func TestEventUsecase_HandleTrap_NotConfigured(t *testing.T) {
+	// Without configured notification OIDs every trap is kept as unknown event with its OID and variables
+	for _, firmware := range []config.FirmwareVersion{config.FirmwareV21, config.FirmwareV22} {
+		device := config.OLTDeviceConfig{ID: "olt-1", Firmware: firmware}
+		u, store := newTestEventUsecase("public", TrapSource{OLTID: "olt-1", Hosts: []string{"10.0.0.1"}, Config: &config.Config{OIDProfile: device.OIDProfile()}})
+
+		u.HandleTrap(newTrapPacket("public", ".1.3.6.1.4.1.32473.1.0.1",
+			gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.32473.1.1.285278467.12", Type: gosnmp.Integer, Value: 7}),
+			&net.UDPAddr{IP: net.ParseIP("10.0.0.1")})
+
+		events := store.List(model.EventQuery{})
+		if len(events) != 1 {
+			t.Fatalf("%s: stored %d events, want 1", firmware, len(events))
+		}
+		got := events[0]
+		if got.Type != model.EventUnknown || got.TrapOID != ".1.3.6.1.4.1.32473.1.0.1" {
+			t.Errorf("%s: event = %s %s, want unknown with the trap OID", firmware, got.Type, got.TrapOID)
+		}
+		if got.Variables[".1.3.6.1.4.1.32473.1.1.285278467.12"] != "7" {
+			t.Errorf("%s: variables = %v, want the status varbind", firmware, got.Variables)
+		}
+	}
+}
+
+func TestEventUsecase_ListEvents_Validation(t *testing.T) {
+	u, _ := newTestEventUsecase("")
+
+	invalid := []model.EventQuery{
+		{Type: "onu_exploded"},
+		{Limit: maxEventLimit + 1},
+	}
+	for _, query := range invalid {
+		if _, err := u.ListEvents(query); !isValidationError(err) {
+			t.Errorf("ListEvents(%+v) error = %v, want validation error", query, err)
+		}
+	}
+
+	events, err := u.ListEvents(model.EventQuery{Type: model.EventOnuLOS})
+	if err != nil {
+		t.Fatalf("ListEvents() error = %v", err)
+	}
+	if events == nil || len(events) != 0 {
+		t.Errorf("ListEvents() = %v, want empty list", events)
+	}
+}
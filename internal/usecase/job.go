@@ -0,0 +1,830 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// maxJobLimit is the largest number of jobs returned by ListJobs
+const maxJobLimit = 1000
+
+// JobExecutor carries out the jobs of one OLT
+type JobExecutor interface {
+	// ExecuteTarget applies the operation of a batch job to one ONU and returns the result message
+	ExecuteTarget(ctx context.Context, job *model.Job, target *model.JobTarget) (string, error)
+
+	// Execute runs a job without targets and returns its result; a failed job may still have a result
+	Execute(ctx context.Context, job *model.Job) (interface{}, error)
+}
+
+// PrincipalResolver looks up the current rights of the caller a job runs as before each target
+type PrincipalResolver interface {
+	// ResolvePrincipal returns the caller of a job owner, or an error if its credential is no longer valid
+	ResolvePrincipal(ctx context.Context, owner *model.JobOwner) (*model.Principal, error)
+}
+
+// JobSubmitter queues jobs on one OLT
+type JobSubmitter interface {
+	// SubmitJob stores a job with the parameters request and queues it. The caller of ctx is recorded as its creator.
+	SubmitJob(ctx context.Context, jobType string, request interface{}, targets []model.JobTarget) (*model.Job, error)
+}
+
+// JobUsecase runs batch and long-running operations as background jobs. The jobs that change an OLT run
+// one after another; read-only jobs (backups, PON monitoring) run on a queue of their own beside them,
+// so they never wait behind a batch. Jobs of different OLTs run in parallel. Jobs are kept in the job
+// store, so they can be followed after a restart and unfinished jobs continue.
+type JobUsecase interface {
+	// RegisterOLT sets the executor of an OLT's jobs and returns the submitter of its jobs; OLTs are registered before Run
+	RegisterOLT(oltID string, executor JobExecutor) JobSubmitter
+
+	// GetJob returns a job the caller of ctx may see
+	GetJob(ctx context.Context, id string) (*model.Job, error)
+
+	// ListJobs returns the matching jobs the caller of ctx may see, newest first
+	ListJobs(ctx context.Context, query model.JobQuery) ([]*model.Job, error)
+
+	// CancelJob cancels a queued job, or stops a running job after its current target
+	CancelJob(ctx context.Context, id string) (*model.Job, error)
+
+	// ResumeJob queues a failed or canceled job again to run its failed and remaining targets
+	ResumeJob(ctx context.Context, id string) (*model.Job, error)
+
+	// Run continues the unfinished jobs of the store and runs the queued jobs until ctx is canceled
+	Run(ctx context.Context)
+}
+
+type jobUsecase struct {
+	store      repository.JobStore
+	audit      AuditUsecase      // Records the runs of jobs that change an OLT; nil disables recording
+	principals PrincipalResolver // Resolves the callers jobs run as; nil if authentication is disabled
+	retention  time.Duration     // How long finished jobs are kept, reported as ExpiresAt
+	now        func() time.Time
+
+	mu       sync.Mutex
+	queues   map[string]*oltQueues  // By OLT ID
+	running  map[string]*runningJob // By job ID
+	jobLocks map[string]*jobLock    // By job ID, while held or waited for
+}
+
+// jobLock serializes the changes of one job: a job is loaded, changed and saved with its lock held,
+// so a save never overwrites a newer state. The store is never accessed with jobUsecase.mu held.
+type jobLock struct {
+	mu   sync.Mutex
+	refs int // Holders and waiters; guarded by jobUsecase.mu
+}
+
+// oltQueues holds the job queues of one OLT
+type oltQueues struct {
+	changes  *jobQueue // Jobs that change the OLT, run one after another
+	readOnly *jobQueue // Jobs that only read the OLT
+}
+
+// queue returns the queue of a job type
+func (q *oltQueues) queue(jobType string) *jobQueue {
+	if model.JobTypes[jobType].ChangesOLT {
+		return q.changes
+	}
+	return q.readOnly
+}
+
+// jobQueue holds the queued jobs of one OLT that run one after another
+type jobQueue struct {
+	oltID    string
+	executor JobExecutor
+	pending  []string      // IDs of the queued jobs, oldest first; guarded by jobUsecase.mu
+	wake     chan struct{} // Signals the worker that a job was queued
+}
+
+// runningJob is a job being executed by a worker
+type runningJob struct {
+	job    *model.Job // Guarded by the lock of the job
+	cancel context.CancelFunc
+}
+
+// jobSubmitter submits the jobs of one OLT
+type jobSubmitter struct {
+	usecase *jobUsecase
+	oltID   string
+	queues  *oltQueues
+}
+
+// NewJobUsecase creates the job runner on a job store. Runs of jobs that change an OLT are recorded in audit.
+// The rights of the caller a job runs as are looked up with principals before each target.
+func NewJobUsecase(store repository.JobStore, audit AuditUsecase, principals PrincipalResolver, retention time.Duration) JobUsecase {
+	return &jobUsecase{
+		store:      store,
+		audit:      audit,
+		principals: principals,
+		retention:  retention,
+		now:        time.Now,
+		queues:     make(map[string]*oltQueues),
+		running:    make(map[string]*runningJob),
+		jobLocks:   make(map[string]*jobLock),
+	}
+}
+
+// RegisterOLT sets the executor of an OLT's jobs and returns the submitter of its jobs
+func (u *jobUsecase) RegisterOLT(oltID string, executor JobExecutor) JobSubmitter {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	queues := &oltQueues{
+		changes:  &jobQueue{oltID: oltID, executor: executor, wake: make(chan struct{}, 1)},
+		readOnly: &jobQueue{oltID: oltID, executor: executor, wake: make(chan struct{}, 1)},
+	}
+	u.queues[oltID] = queues
+	return &jobSubmitter{usecase: u, oltID: oltID, queues: queues}
+}
+
+// SubmitJob stores a queued job and wakes the worker of the OLT
+func (s *jobSubmitter) SubmitJob(ctx context.Context, jobType string, request interface{}, targets []model.JobTarget) (*model.Job, error) {
+	if _, ok := model.JobTypes[jobType]; !ok {
+		return nil, apperrors.NewInternalError("unknown job type "+jobType, nil)
+	}
+	params, err := json.Marshal(request)
+	if err != nil {
+		return nil, apperrors.NewInternalError("failed to marshal job request", err)
+	}
+
+	now := s.usecase.now().UTC()
+	job := &model.Job{
+		ID:        uuid.New().String(),
+		Type:      jobType,
+		OLTID:     s.oltID,
+		Status:    model.JobQueued,
+		RequestID: model.RequestIDFromContext(ctx),
+		Request:   params,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if principal, ok := model.PrincipalFromContext(ctx); ok {
+		job.CreatedBy = principal.Identity()
+		job.RunAs = model.NewJobOwner(principal)
+	}
+	for _, target := range targets {
+		target.Status = model.JobTargetPending
+		job.Targets = append(job.Targets, target)
+	}
+	job.UpdateProgress()
+
+	if err := s.usecase.store.Save(ctx, job); err != nil {
+		return nil, err
+	}
+	s.usecase.enqueue(s.queues.queue(job.Type), job.ID)
+
+	log.Info().
+		Str("job_id", job.ID).
+		Str("type", job.Type).
+		Str("olt_id", job.OLTID).
+		Int("targets", len(job.Targets)).
+		Msg("Job queued")
+
+	return job, nil
+}
+
+// GetJob returns a job the caller of ctx may see
+func (u *jobUsecase) GetJob(ctx context.Context, id string) (*model.Job, error) {
+	job, err := u.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ListJobs returns the matching jobs the caller of ctx may see, newest first
+func (u *jobUsecase) ListJobs(ctx context.Context, query model.JobQuery) ([]*model.Job, error) {
+	if query.Limit == 0 {
+		query.Limit = 100
+	}
+	if query.Limit < 0 || query.Limit > maxJobLimit {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxJobLimit), map[string]interface{}{
+			"limit": query.Limit,
+		})
+	}
+
+	jobs, err := u.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*model.Job, 0, len(jobs))
+	for _, job := range jobs {
+		if len(matches) == query.Limit {
+			break
+		}
+		if matchesJobQuery(job, query) && authorizeJob(ctx, job) == nil {
+			matches = append(matches, job)
+		}
+	}
+	return matches, nil
+}
+
+// CancelJob cancels a queued job, or stops a running job after its current target
+func (u *jobUsecase) CancelJob(ctx context.Context, id string) (*model.Job, error) {
+	defer u.lockJob(id)()
+
+	u.mu.Lock()
+	running, ok := u.running[id]
+	u.mu.Unlock()
+	if ok {
+		if err := authorizeJob(ctx, running.job); err != nil {
+			return nil, err
+		}
+		running.job.CancelRequested = true
+		running.cancel()
+		if err := u.saveLocked(ctx, running.job); err != nil {
+			return nil, err
+		}
+		return copyJob(running.job), nil
+	}
+
+	job, err := u.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobQueued {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("job is %s and cannot be canceled", job.Status), map[string]interface{}{
+			"job_id": id,
+			"status": job.Status,
+		})
+	}
+
+	// The worker skips the job when it comes up in the queue
+	job.Status = model.JobCanceled
+	u.finishLocked(job)
+	if err := u.saveLocked(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// ResumeJob queues a failed or canceled job again to run its failed and remaining targets
+func (u *jobUsecase) ResumeJob(ctx context.Context, id string) (*model.Job, error) {
+	defer u.lockJob(id)()
+
+	u.mu.Lock()
+	_, running := u.running[id]
+	u.mu.Unlock()
+	if running {
+		return nil, apperrors.NewValidationError("job is running and cannot be resumed", map[string]interface{}{
+			"job_id": id,
+		})
+	}
+	job, err := u.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if job.Status != model.JobFailed && job.Status != model.JobCanceled {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("job is %s; only failed and canceled jobs can be resumed", job.Status), map[string]interface{}{
+			"job_id": id,
+			"status": job.Status,
+		})
+	}
+	u.mu.Lock()
+	queues, ok := u.queues[job.OLTID]
+	u.mu.Unlock()
+	if !ok {
+		return nil, apperrors.NewValidationError("the OLT of the job is not registered", map[string]interface{}{
+			"job_id": id,
+			"olt_id": job.OLTID,
+		})
+	}
+
+	for i := range job.Targets {
+		if job.Targets[i].Status == model.JobTargetFailed {
+			job.Targets[i].Status = model.JobTargetPending
+		}
+	}
+	job.Status = model.JobQueued
+	job.ResumedBy, job.RunAs = "", nil
+	if principal, ok := model.PrincipalFromContext(ctx); ok {
+		job.ResumedBy = principal.Identity()
+		job.RunAs = model.NewJobOwner(principal)
+	}
+	job.Error = ""
+	job.CancelRequested = false
+	job.FinishedAt, job.ExpiresAt = nil, nil
+	if err := u.saveLocked(ctx, job); err != nil {
+		return nil, err
+	}
+	u.enqueue(queues.queue(job.Type), job.ID)
+
+	log.Info().Str("job_id", job.ID).Str("type", job.Type).Int("pending", job.Progress.Pending).Msg("Job resumed")
+	return job, nil
+}
+
+// Run continues the unfinished jobs of the store and runs the queued jobs until ctx is canceled
+func (u *jobUsecase) Run(ctx context.Context) {
+	u.requeueUnfinished(ctx)
+
+	u.mu.Lock()
+	queues := make([]*jobQueue, 0, 2*len(u.queues))
+	for _, olt := range u.queues {
+		queues = append(queues, olt.changes, olt.readOnly)
+	}
+	u.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, queue := range queues {
+		wg.Add(1)
+		go func(queue *jobQueue) {
+			defer wg.Done()
+			u.work(ctx, queue)
+		}(queue)
+	}
+	wg.Wait()
+}
+
+// requeueUnfinished queues the jobs that were queued or running when the API stopped. Targets that were
+// running are marked failed: they may or may not have been applied, so they only run again on resume.
+// For the same reason, running jobs without targets, e.g. restores, fail instead of starting over.
+func (u *jobUsecase) requeueUnfinished(ctx context.Context) {
+	jobs, err := u.store.List(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to load unfinished jobs")
+		return
+	}
+
+	for i := len(jobs) - 1; i >= 0; i-- { // Oldest first
+		if !jobs[i].Finished() {
+			u.requeueJob(ctx, jobs[i].ID)
+		}
+	}
+}
+
+// requeueJob queues an unfinished job loaded at startup, or fails it if it cannot continue
+func (u *jobUsecase) requeueJob(ctx context.Context, id string) {
+	defer u.lockJob(id)()
+
+	// Reloaded with the lock held, as the job may have been canceled meanwhile
+	job, err := u.store.Get(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("job_id", id).Msg("Failed to load unfinished job")
+		return
+	}
+	if job.Finished() {
+		return
+	}
+
+	u.mu.Lock()
+	queues, ok := u.queues[job.OLTID]
+	u.mu.Unlock()
+
+	switch {
+	case !ok:
+		job.Status = model.JobFailed
+		job.Error = fmt.Sprintf("OLT %s is not registered", job.OLTID)
+		u.finishLocked(job)
+	case job.Status == model.JobRunning && len(job.Targets) == 0:
+		job.Status = model.JobFailed
+		job.Error = "interrupted by a restart of the API; resume the job to run it again"
+		u.finishLocked(job)
+	case job.Status == model.JobRunning:
+		for i := range job.Targets {
+			if job.Targets[i].Status == model.JobTargetRunning {
+				job.Targets[i].Status = model.JobTargetFailed
+				job.Targets[i].Error = "interrupted by a restart of the API; resume the job to retry"
+			}
+		}
+	}
+	if err := u.saveLocked(ctx, job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to save unfinished job")
+		return
+	}
+	if job.Finished() {
+		log.Warn().Str("job_id", job.ID).Str("type", job.Type).Str("error", job.Error).Msg("Unfinished job failed")
+		return
+	}
+	u.enqueue(queues.queue(job.Type), job.ID)
+	log.Info().Str("job_id", job.ID).Str("type", job.Type).Str("olt_id", job.OLTID).Msg("Continuing unfinished job")
+}
+
+// work runs the jobs of a queue one after another until ctx is canceled
+func (u *jobUsecase) work(ctx context.Context, queue *jobQueue) {
+	for {
+		u.mu.Lock()
+		var id string
+		if len(queue.pending) > 0 {
+			id, queue.pending = queue.pending[0], queue.pending[1:]
+		}
+		u.mu.Unlock()
+
+		if id == "" {
+			select {
+			case <-ctx.Done():
+				return
+			case <-queue.wake:
+				continue
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		u.runJob(ctx, queue, id)
+	}
+}
+
+// runJob executes the pending targets of a job, or the job itself if it has no targets
+func (u *jobUsecase) runJob(ctx context.Context, queue *jobQueue, id string) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	unlock := u.lockJob(id)
+	job, err := u.store.Get(ctx, id)
+	if err != nil {
+		unlock()
+		log.Error().Err(err).Str("job_id", id).Msg("Failed to load queued job")
+		return
+	}
+	if job.Status != model.JobQueued && job.Status != model.JobRunning {
+		unlock()
+		return // Canceled while queued
+	}
+	start := u.now().UTC()
+	job.Status = model.JobRunning
+	job.Attempts++
+	job.StartedAt, job.FinishedAt, job.DurationMs = &start, nil, 0
+	u.mu.Lock()
+	u.running[id] = &runningJob{job: job, cancel: cancel}
+	u.mu.Unlock()
+	u.saveJob(ctx, job)
+	unlock()
+
+	log.Info().Str("job_id", id).Str("type", job.Type).Str("olt_id", job.OLTID).Int("attempt", job.Attempts).Msg("Job started")
+
+	// CLI commands are recorded for the audit log, backups name the caller the job runs as
+	transcript := &model.CLITranscript{}
+	runCtx := model.ContextWithCLITranscript(jobCtx, transcript)
+	runCtx = context.WithValue(runCtx, model.RequestIDKey, job.RequestID)
+
+	var ran []model.JobTarget
+	if len(job.Targets) == 0 {
+		var result interface{}
+		callerCtx, err := u.runAs(ctx, runCtx, job, nil)
+		if err == nil {
+			result, err = queue.executor.Execute(callerCtx, job)
+		}
+		unlock = u.lockJob(id)
+		job.Result = nil
+		if result != nil {
+			if data, marshalErr := json.Marshal(result); marshalErr == nil {
+				job.Result = data
+			}
+		}
+		if err != nil {
+			job.Error = err.Error()
+		}
+		unlock()
+	} else {
+		ran = u.runTargets(ctx, jobCtx, runCtx, queue.executor, job)
+	}
+
+	unlock = u.lockJob(id)
+	job.UpdateProgress()
+	unfinished := job.Error != "" // A job without targets ended early if it failed
+	if len(job.Targets) > 0 {
+		unfinished = job.Progress.Pending > 0
+	}
+	switch {
+	case ctx.Err() != nil && unfinished:
+		// Shutting down: the job stays running and is continued, or failed if it has no targets, after the restart
+		job.Error = ""
+	case jobCtx.Err() != nil && unfinished:
+		job.Status = model.JobCanceled
+		job.Error = ""
+	case job.Error != "":
+		job.Status = model.JobFailed
+	case job.Progress.Failed > 0:
+		job.Status = model.JobFailed
+		job.Error = fmt.Sprintf("%d of %d targets failed", job.Progress.Failed, job.Progress.Total)
+	default:
+		job.Status = model.JobCompleted
+	}
+	job.CancelRequested = false
+	if job.Finished() {
+		u.finishLocked(job)
+	}
+	job.DurationMs = u.now().Sub(start).Milliseconds()
+	u.mu.Lock()
+	delete(u.running, id)
+	u.mu.Unlock()
+	u.saveJob(ctx, job)
+	finished := copyJob(job)
+	unlock()
+
+	log.Info().
+		Str("job_id", id).
+		Str("type", finished.Type).
+		Str("status", finished.Status).
+		Int("succeeded", finished.Progress.Succeeded).
+		Int("failed", finished.Progress.Failed).
+		Int("pending", finished.Progress.Pending).
+		Int64("duration_ms", finished.DurationMs).
+		Msg("Job run ended")
+
+	u.recordRun(ctx, finished, start, ran, transcript.Commands())
+}
+
+// runTargets executes the pending targets of a job until all ran, the job is canceled or its caller may no
+// longer run it, and returns the targets that ran. A target that has started is finished even if the job
+// is canceled meanwhile, so an ONU is never left half-configured.
+func (u *jobUsecase) runTargets(ctx, jobCtx, runCtx context.Context, executor JobExecutor, job *model.Job) []model.JobTarget {
+	var ran []model.JobTarget
+	for i := range job.Targets {
+		unlock := u.lockJob(job.ID)
+		if job.Targets[i].Status != model.JobTargetPending || jobCtx.Err() != nil {
+			unlock()
+			continue
+		}
+		target := job.Targets[i]
+		unlock()
+
+		// The caller is resolved without the lock: the lookup may wait for the key store
+		callerCtx, err := u.runAs(ctx, runCtx, job, &target)
+		unlock = u.lockJob(job.ID)
+		if err != nil {
+			job.Error = err.Error()
+			unlock()
+			log.Warn().Str("job_id", job.ID).Err(err).Msg("Job stopped: its caller may no longer run it")
+			break
+		}
+		if jobCtx.Err() != nil {
+			unlock()
+			continue
+		}
+		job.Targets[i].Status = model.JobTargetRunning
+		job.Targets[i].Message, job.Targets[i].Error = "", ""
+		u.saveJob(ctx, job)
+		target = job.Targets[i]
+		unlock()
+
+		message, err := executor.ExecuteTarget(context.WithoutCancel(callerCtx), job, &target)
+
+		unlock = u.lockJob(job.ID)
+		finishedAt := u.now().UTC()
+		target.Status, target.Message, target.FinishedAt = model.JobTargetSucceeded, message, &finishedAt
+		if err != nil {
+			target.Status, target.Error = model.JobTargetFailed, err.Error()
+			log.Warn().
+				Str("job_id", job.ID).
+				Str("pon_port", target.PONPort).
+				Int("onu_id", target.ONUID).
+				Err(err).
+				Msg("Job target failed")
+		}
+		job.Targets[i] = target
+		u.saveJob(ctx, job)
+		unlock()
+		ran = append(ran, target)
+	}
+	return ran
+}
+
+// runAs looks up the caller a job runs as and checks its scope and board/PON restrictions for a target,
+// or for the whole job if target is nil. It returns the context to run the target with, or an error
+// if the caller may no longer run it. Without authentication the job runs without a caller.
+func (u *jobUsecase) runAs(ctx, runCtx context.Context, job *model.Job, target *model.JobTarget) (context.Context, error) {
+	if job.RunAs == nil || u.principals == nil {
+		return runCtx, nil
+	}
+
+	principal, err := u.principals.ResolvePrincipal(ctx, job.RunAs)
+	if err == nil {
+		runCtx = model.ContextWithPrincipal(runCtx, principal)
+		if err = authorizeJob(runCtx, job); err == nil {
+			err = checkJobRestrictions(principal, job, target)
+		}
+	}
+	if err != nil {
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) {
+			err = errors.New(appErr.Message)
+		}
+		return nil, fmt.Errorf("%s may no longer run the job: %w", job.RunAs.Identity(), err)
+	}
+	return runCtx, nil
+}
+
+// checkJobRestrictions rejects a target, or a job without targets, outside the boards/PONs of a restricted
+// caller. Of the jobs without targets only PON monitoring addresses a single PON port.
+func checkJobRestrictions(principal *model.Principal, job *model.Job, target *model.JobTarget) error {
+	if !principal.Restricted() {
+		return nil
+	}
+
+	var boardID, ponID int
+	switch {
+	case target != nil:
+		var err error
+		if boardID, ponID, err = parseBoardPon(target.PONPort); err != nil {
+			return err
+		}
+	case job.Type == model.JobTypePONMonitoring:
+		var req model.PONMonitoringJobRequest
+		if err := json.Unmarshal(job.Request, &req); err != nil {
+			return apperrors.NewInternalError("failed to decode the job request", err)
+		}
+		boardID, ponID = req.Board, req.PON
+	default:
+		return apperrors.NewForbiddenError("API key is restricted to boards/PONs and the job does not address a PON port", map[string]interface{}{
+			"key_id": principal.ID,
+		})
+	}
+
+	if !principal.AllowsPON(boardID, ponID) {
+		return apperrors.NewForbiddenError("API key is not allowed on this board/PON", map[string]interface{}{
+			"key_id": principal.ID,
+			"board":  boardID,
+			"pon":    ponID,
+		})
+	}
+	return nil
+}
+
+// recordRun writes a run of a job that changes an OLT to the audit log
+func (u *jobUsecase) recordRun(ctx context.Context, job *model.Job, start time.Time, ran []model.JobTarget, commands []model.CLICommand) {
+	if u.audit == nil || !model.JobTypes[job.Type].ChangesOLT {
+		return
+	}
+
+	entry := &model.AuditEntry{
+		Timestamp:  start,
+		User:       model.AuditAnonymous,
+		RequestID:  job.RequestID,
+		OLTID:      job.OLTID,
+		Operation:  job.Type,
+		JobID:      job.ID,
+		Request:    job.Request,
+		Commands:   commands,
+		Result:     model.AuditResultSuccess,
+		DurationMs: job.DurationMs,
+	}
+	switch {
+	case job.ResumedBy != "":
+		entry.User = job.ResumedBy
+	case job.CreatedBy != "":
+		entry.User = job.CreatedBy
+	}
+	if job.Status != model.JobCompleted {
+		entry.Result = model.AuditResultFailure
+		switch {
+		case job.Error != "":
+			entry.Error = job.Error
+		case job.Status == model.JobRunning && len(job.Targets) == 0:
+			entry.Error = "interrupted by a shutdown; resume the job after the restart"
+		case job.Status == model.JobRunning:
+			entry.Error = "interrupted by a shutdown; the job continues after the restart"
+		default:
+			entry.Error = "job " + job.Status
+		}
+	}
+
+	seen := make(map[model.AuditTarget]bool)
+	addTarget := func(target model.AuditTarget) {
+		if !seen[target] {
+			seen[target] = true
+			entry.Targets = append(entry.Targets, target)
+		}
+	}
+	for _, target := range ran {
+		if boardID, ponID, err := parseBoardPon(target.PONPort); err == nil {
+			addTarget(model.AuditTarget{Board: boardID, PON: ponID, ONUID: target.ONUID})
+		}
+	}
+	for _, target := range model.CommandTargets(commands) {
+		addTarget(target)
+	}
+
+	if err := u.audit.Record(context.WithoutCancel(ctx), entry); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Str("operation", job.Type).Msg("Failed to write audit log entry")
+	}
+}
+
+// enqueue queues a job on a queue of its OLT
+func (u *jobUsecase) enqueue(queue *jobQueue, id string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.enqueueLocked(queue, id)
+}
+
+// enqueueLocked queues a job unless it is queued already; u.mu must be held
+func (u *jobUsecase) enqueueLocked(queue *jobQueue, id string) {
+	for _, pending := range queue.pending {
+		if pending == id {
+			return
+		}
+	}
+	queue.pending = append(queue.pending, id)
+	select {
+	case queue.wake <- struct{}{}:
+	default:
+	}
+}
+
+// lockJob acquires the lock of a job and returns its release function; u.mu must not be held
+func (u *jobUsecase) lockJob(id string) func() {
+	u.mu.Lock()
+	lock, ok := u.jobLocks[id]
+	if !ok {
+		lock = &jobLock{}
+		u.jobLocks[id] = lock
+	}
+	lock.refs++
+	u.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		if lock.refs--; lock.refs == 0 {
+			delete(u.jobLocks, id)
+		}
+	}
+}
+
+// finishLocked sets the end of a finished job; the lock of the job must be held
+func (u *jobUsecase) finishLocked(job *model.Job) {
+	now := u.now().UTC()
+	expires := now.Add(u.retention)
+	job.FinishedAt, job.ExpiresAt = &now, &expires
+}
+
+// saveLocked recounts the progress of a job and saves it; the lock of the job must be held
+func (u *jobUsecase) saveLocked(ctx context.Context, job *model.Job) error {
+	job.UpdateProgress()
+	job.UpdatedAt = u.now().UTC()
+	return u.store.Save(context.WithoutCancel(ctx), job)
+}
+
+// saveJob saves a job of a worker, which carries on if the store is unavailable; the lock of the job must be held
+func (u *jobUsecase) saveJob(ctx context.Context, job *model.Job) {
+	if err := u.saveLocked(ctx, job); err != nil {
+		log.Error().Err(err).Str("job_id", job.ID).Msg("Failed to save job")
+	}
+}
+
+// authorizeJob allows the caller of ctx to see and change a job if it has the scope of the job type.
+// Callers restricted to boards or PON ports only reach the jobs they submitted. Without authentication
+// every job is allowed.
+func authorizeJob(ctx context.Context, job *model.Job) error {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	scope := model.JobTypes[job.Type].Scope
+	if scope == "" || !principal.HasScope(scope) {
+		return apperrors.NewForbiddenError("caller lacks the required scope", map[string]interface{}{
+			"principal":      principal.Identity(),
+			"required_scope": scope,
+		})
+	}
+	if principal.Restricted() && job.CreatedBy != principal.Identity() {
+		return apperrors.NewForbiddenError("callers restricted to boards or PON ports only reach their own jobs", map[string]interface{}{
+			"principal": principal.Identity(),
+		})
+	}
+	return nil
+}
+
+// matchesJobQuery reports whether a job passes all filters of the query
+func matchesJobQuery(job *model.Job, query model.JobQuery) bool {
+	if query.OLTID != "" && job.OLTID != query.OLTID {
+		return false
+	}
+	if query.Status != "" && job.Status != query.Status {
+		return false
+	}
+	if query.Type != "" && job.Type != query.Type && !strings.HasPrefix(job.Type, query.Type+".") {
+		return false
+	}
+	return true
+}
+
+// copyJob returns a copy of a job that does not share its targets
+func copyJob(job *model.Job) *model.Job {
+	c := *job
+	c.Targets = append([]model.JobTarget(nil), job.Targets...)
+	return &c
+}
@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// jobExecutor executes the jobs of one OLT with the OLT's usecases
+type jobExecutor struct {
+	onuMgmtUsecase      ONUManagementUsecaseInterface
+	configBackupUsecase ConfigBackupUsecase
+	monitoringUsecase   *MonitoringUsecase
+}
+
+// NewJobExecutor creates the executor of an OLT's jobs
+func NewJobExecutor(onuMgmtUsecase ONUManagementUsecaseInterface, configBackupUsecase ConfigBackupUsecase, monitoringUsecase *MonitoringUsecase) JobExecutor {
+	return &jobExecutor{
+		onuMgmtUsecase:      onuMgmtUsecase,
+		configBackupUsecase: configBackupUsecase,
+		monitoringUsecase:   monitoringUsecase,
+	}
+}
+
+// ExecuteTarget applies the operation of a batch job to one ONU
+func (e *jobExecutor) ExecuteTarget(ctx context.Context, job *model.Job, target *model.JobTarget) (string, error) {
+	var success bool
+	var message string
+	var err error
+
+	switch job.Type {
+	case model.JobTypeBatchReboot:
+		var resp *model.ONURebootResponse
+		resp, err = e.onuMgmtUsecase.RebootONU(ctx, &model.ONURebootRequest{PONPort: target.PONPort, ONUID: target.ONUID})
+		if resp != nil {
+			success, message = resp.Success, resp.Message
+		}
+	case model.JobTypeBatchBlock:
+		var resp *model.ONUBlockResponse
+		resp, err = e.onuMgmtUsecase.BlockONU(ctx, &model.ONUBlockRequest{PONPort: target.PONPort, ONUID: target.ONUID, Block: true})
+		if resp != nil {
+			success, message = resp.Success, resp.Message
+		}
+	case model.JobTypeBatchUnblock:
+		var resp *model.ONUBlockResponse
+		resp, err = e.onuMgmtUsecase.UnblockONU(ctx, &model.ONUBlockRequest{PONPort: target.PONPort, ONUID: target.ONUID})
+		if resp != nil {
+			success, message = resp.Success, resp.Message
+		}
+	case model.JobTypeBatchDelete:
+		var resp *model.ONUDeleteResponse
+		resp, err = e.onuMgmtUsecase.DeleteONU(ctx, &model.ONUDeleteRequest{PONPort: target.PONPort, ONUID: target.ONUID})
+		if resp != nil {
+			success, message = resp.Success, resp.Message
+		}
+	case model.JobTypeBatchDescriptions:
+		var resp *model.ONUDescriptionResponse
+		resp, err = e.onuMgmtUsecase.UpdateDescription(ctx, &model.ONUDescriptionRequest{
+			PONPort:     target.PONPort,
+			ONUID:       target.ONUID,
+			Description: target.Description,
+		})
+		if resp != nil {
+			success, message = resp.Success, resp.Message
+		}
+	default:
+		return "", apperrors.NewInternalError("job type "+job.Type+" has no targets", nil)
+	}
+
+	if err != nil {
+		return "", err
+	}
+	if !success {
+		return message, errors.New(message)
+	}
+	return message, nil
+}
+
+// Execute runs a backup, restore or monitoring job
+func (e *jobExecutor) Execute(ctx context.Context, job *model.Job) (interface{}, error) {
+	switch job.Type {
+	case model.JobTypeBackupOLT:
+		var req model.BackupCreateRequest
+		if err := decodeJobRequest(job, &req); err != nil {
+			return nil, err
+		}
+		backup, err := e.configBackupUsecase.BackupOLT(ctx, req.Description, req.Tags)
+		if err != nil {
+			return nil, err
+		}
+		return backup, nil
+
+	case model.JobTypeRestore:
+		var req model.RestoreRequest
+		if err := decodeJobRequest(job, &req); err != nil {
+			return nil, err
+		}
+		result, err := e.configBackupUsecase.RestoreFromBackup(ctx, &req)
+		if err != nil {
+			return nil, err
+		}
+		if !result.Success {
+			return result, errors.New(result.Message)
+		}
+		return result, nil
+
+	case model.JobTypePONMonitoring:
+		var req model.PONMonitoringJobRequest
+		if err := decodeJobRequest(job, &req); err != nil {
+			return nil, err
+		}
+		info, err := e.monitoringUsecase.GetPONMonitoring(ctx, req.Board, req.PON)
+		if err != nil {
+			return nil, err
+		}
+		return info, nil
+	}
+
+	return nil, apperrors.NewInternalError("unknown job type "+job.Type, nil)
+}
+
+// decodeJobRequest reads the parameters of a job
+func decodeJobRequest(job *model.Job, req interface{}) error {
+	if err := json.Unmarshal(job.Request, req); err != nil {
+		return apperrors.NewInternalError("failed to unmarshal job request", err)
+	}
+	return nil
+}
@@ -0,0 +1,537 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// memoryJobStore is an in-memory repository.JobStore
+type memoryJobStore struct {
+	mu   sync.Mutex
+	jobs map[string][]byte
+}
+
+func newMemoryJobStore() *memoryJobStore {
+	return &memoryJobStore{jobs: make(map[string][]byte)}
+}
+
+func (s *memoryJobStore) Save(_ context.Context, job *model.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = data
+	return nil
+}
+
+func (s *memoryJobStore) Get(_ context.Context, id string) (*model.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.jobs[id]
+	if !ok {
+		return nil, apperrors.NewNotFoundError("job", id)
+	}
+	var job model.Job
+	err := json.Unmarshal(data, &job)
+	return &job, err
+}
+
+func (s *memoryJobStore) List(ctx context.Context) ([]*model.Job, error) {
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+
+	jobs := make([]*model.Job, 0, len(ids))
+	for _, id := range ids {
+		job, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs, nil
+}
+
+// fakeJobExecutor fails the targets of failing and blocks each target until release receives, if set
+type fakeJobExecutor struct {
+	mu        sync.Mutex
+	failing   map[int]bool     // By ONU ID
+	runs      []int            // ONU IDs in the order they ran
+	principal *model.Principal // Caller of the last target
+	started   chan int
+	release   chan struct{}
+}
+
+func (e *fakeJobExecutor) ExecuteTarget(ctx context.Context, _ *model.Job, target *model.JobTarget) (string, error) {
+	e.mu.Lock()
+	e.principal, _ = model.PrincipalFromContext(ctx)
+	e.mu.Unlock()
+	if e.started != nil {
+		e.started <- target.ONUID
+	}
+	if e.release != nil {
+		<-e.release
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.runs = append(e.runs, target.ONUID)
+	if e.failing[target.ONUID] {
+		return "", errors.New("ONU is offline")
+	}
+	return fmt.Sprintf("ONU %d done", target.ONUID), nil
+}
+
+func (e *fakeJobExecutor) Execute(_ context.Context, job *model.Job) (interface{}, error) {
+	return map[string]string{"type": job.Type}, nil
+}
+
+func (e *fakeJobExecutor) ranTargets() []int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]int(nil), e.runs...)
+}
+
+// fakePrincipalResolver resolves job owners by ID to the principals in principals; missing owners are revoked
+type fakePrincipalResolver struct {
+	mu         sync.Mutex
+	principals map[string]*model.Principal
+}
+
+func (r *fakePrincipalResolver) ResolvePrincipal(_ context.Context, owner *model.JobOwner) (*model.Principal, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	principal, ok := r.principals[owner.ID]
+	if !ok {
+		return nil, apperrors.NewUnauthorizedError("API key has been revoked")
+	}
+	return principal, nil
+}
+
+func (r *fakePrincipalResolver) set(principal *model.Principal) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.principals[principal.ID] = principal
+}
+
+func (r *fakePrincipalResolver) revoke(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.principals, id)
+}
+
+// newFakePrincipalResolver returns a resolver that knows principals
+func newFakePrincipalResolver(principals ...*model.Principal) *fakePrincipalResolver {
+	r := &fakePrincipalResolver{principals: map[string]*model.Principal{}}
+	for _, principal := range principals {
+		r.set(principal)
+	}
+	return r
+}
+
+// startJobUsecase runs a job usecase with one OLT "olt-1" until the test ends
+func startJobUsecase(t *testing.T, store repository.JobStore, audit AuditUsecase, executor JobExecutor) (JobUsecase, JobSubmitter) {
+	t.Helper()
+	return startJobUsecaseWithPrincipals(t, store, audit, nil, executor)
+}
+
+// startJobUsecaseWithPrincipals runs a job usecase that resolves the callers of jobs with principals
+func startJobUsecaseWithPrincipals(t *testing.T, store repository.JobStore, audit AuditUsecase, principals PrincipalResolver, executor JobExecutor) (JobUsecase, JobSubmitter) {
+	t.Helper()
+	u := NewJobUsecase(store, audit, principals, time.Hour)
+	submitter := u.RegisterOLT("olt-1", executor)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		u.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return u, submitter
+}
+
+// waitForJob waits until a job is finished and returns it
+func waitForJob(t *testing.T, u JobUsecase, id string) *model.Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := u.GetJob(context.Background(), id)
+		if err != nil {
+			t.Fatalf("GetJob() error = %v", err)
+		}
+		if job.Finished() {
+			return job
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return nil
+}
+
+func onuTargets(ids ...int) []model.JobTarget {
+	targets := make([]model.JobTarget, len(ids))
+	for i, id := range ids {
+		targets[i] = model.JobTarget{PONPort: "1/1/1", ONUID: id}
+	}
+	return targets
+}
+
+func TestJobUsecase_FailedTargetsAreResumed(t *testing.T) {
+	audit, _ := newTestAuditUsecase(t)
+	executor := &fakeJobExecutor{failing: map[int]bool{2: true}}
+	noc := &model.Principal{ID: "k1", Name: "noc", Method: model.AuthMethodAPIKey, Scopes: []string{model.ScopeBatch}}
+	u, submitter := startJobUsecaseWithPrincipals(t, newMemoryJobStore(), audit, newFakePrincipalResolver(noc), executor)
+	ctx := model.ContextWithPrincipal(context.Background(), noc)
+
+	job, err := submitter.SubmitJob(ctx, model.JobTypeBatchReboot, &model.BatchONURebootRequest{}, onuTargets(1, 2, 3))
+	if err != nil {
+		t.Fatalf("SubmitJob() error = %v", err)
+	}
+	if job.Status != model.JobQueued || job.CreatedBy != "api_key:noc" || job.Progress.Pending != 3 {
+		t.Errorf("SubmitJob() = %+v, want 3 pending targets queued by api_key:noc", job)
+	}
+
+	job = waitForJob(t, u, job.ID)
+	if job.Status != model.JobFailed || job.Progress.Succeeded != 2 || job.Progress.Failed != 1 || job.Error != "1 of 3 targets failed" {
+		t.Fatalf("job = %+v, want failed with ONU 2 failed", job)
+	}
+	if target := job.Targets[1]; target.Status != model.JobTargetFailed || target.Error != "ONU is offline" || target.FinishedAt == nil {
+		t.Errorf("target 2 = %+v", target)
+	}
+	if job.ExpiresAt == nil || job.Attempts != 1 {
+		t.Errorf("ExpiresAt = %v, Attempts = %d", job.ExpiresAt, job.Attempts)
+	}
+	executor.mu.Lock()
+	if principal := executor.principal; principal == nil || principal.Identity() != "api_key:noc" || !principal.HasScope(model.ScopeBatch) {
+		t.Errorf("targets ran as %+v, want the submitting principal", principal)
+	}
+	executor.mu.Unlock()
+
+	executor.mu.Lock()
+	executor.failing = nil
+	executor.mu.Unlock()
+	if _, err := u.ResumeJob(ctx, job.ID); err != nil {
+		t.Fatalf("ResumeJob() error = %v", err)
+	}
+	job = waitForJob(t, u, job.ID)
+	if job.Status != model.JobCompleted || job.Progress.Succeeded != 3 || job.Attempts != 2 || job.Error != "" {
+		t.Errorf("resumed job = %+v, want completed", job)
+	}
+	if runs := executor.ranTargets(); fmt.Sprint(runs) != "[1 2 3 2]" {
+		t.Errorf("runs = %v, want only ONU 2 to run again", runs)
+	}
+	if _, err := u.ResumeJob(ctx, job.ID); err == nil {
+		t.Error("ResumeJob(completed) error = nil")
+	}
+
+	// Each run is recorded in the audit log with the ONUs it ran on
+	entries, err := audit.ListEntries(context.Background(), model.AuditQuery{})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ListEntries() = %d entries, %v", len(entries), err)
+	}
+	if entry := entries[1]; entry.JobID != job.ID || entry.Operation != model.JobTypeBatchReboot || entry.User != "api_key:noc" ||
+		entry.Result != model.AuditResultFailure || len(entry.Targets) != 3 {
+		t.Errorf("first run = %+v", entry)
+	}
+	if entry := entries[0]; entry.Result != model.AuditResultSuccess || len(entry.Targets) != 1 || entry.Targets[0].ONUID != 2 {
+		t.Errorf("resumed run = %+v", entry)
+	}
+}
+
+func TestJobUsecase_Cancel(t *testing.T) {
+	executor := &fakeJobExecutor{started: make(chan int), release: make(chan struct{})}
+	u, submitter := startJobUsecase(t, newMemoryJobStore(), nil, executor)
+	ctx := context.Background()
+
+	running, _ := submitter.SubmitJob(ctx, model.JobTypeBatchBlock, &model.BatchONUBlockRequest{}, onuTargets(1, 2, 3))
+	queued, _ := submitter.SubmitJob(ctx, model.JobTypeBatchDelete, &model.BatchONUDeleteRequest{}, onuTargets(4))
+	<-executor.started
+
+	// A queued job is canceled right away and never runs
+	job, err := u.CancelJob(ctx, queued.ID)
+	if err != nil || job.Status != model.JobCanceled {
+		t.Fatalf("CancelJob(queued) = %+v, %v", job, err)
+	}
+
+	// A running job finishes its current target first
+	job, err = u.CancelJob(ctx, running.ID)
+	if err != nil || !job.CancelRequested || job.Status != model.JobRunning {
+		t.Fatalf("CancelJob(running) = %+v, %v", job, err)
+	}
+	executor.release <- struct{}{}
+
+	job = waitForJob(t, u, running.ID)
+	if job.Status != model.JobCanceled || job.Progress.Succeeded != 1 || job.Progress.Pending != 2 || job.CancelRequested {
+		t.Errorf("canceled job = %+v, want ONU 1 done and 2 pending", job)
+	}
+	if _, err := u.CancelJob(ctx, running.ID); err == nil {
+		t.Error("CancelJob(canceled) error = nil")
+	}
+
+	// Resuming runs the remaining targets
+	if _, err := u.ResumeJob(ctx, running.ID); err != nil {
+		t.Fatalf("ResumeJob() error = %v", err)
+	}
+	for _, id := range []int{2, 3} {
+		if started := <-executor.started; started != id {
+			t.Errorf("started ONU %d, want %d", started, id)
+		}
+		executor.release <- struct{}{}
+	}
+	if job = waitForJob(t, u, running.ID); job.Status != model.JobCompleted {
+		t.Errorf("resumed job = %+v, want completed", job)
+	}
+	if runs := executor.ranTargets(); fmt.Sprint(runs) != "[1 2 3]" {
+		t.Errorf("runs = %v, want the queued job never to run", runs)
+	}
+}
+
+func TestJobUsecase_CallerRightsAreCheckedBeforeEachTarget(t *testing.T) {
+	noc := &model.Principal{ID: "k1", Name: "noc", Method: model.AuthMethodAPIKey, Scopes: []string{model.ScopeBatch}}
+	tests := []struct {
+		name    string
+		change  func(principals *fakePrincipalResolver)
+		wantErr string
+	}{
+		{"revoked", func(principals *fakePrincipalResolver) { principals.revoke("k1") }, "api_key:noc may no longer run the job: API key has been revoked"},
+		{"scope removed", func(principals *fakePrincipalResolver) {
+			principals.set(&model.Principal{ID: "k1", Name: "noc", Method: model.AuthMethodAPIKey, Scopes: []string{model.ScopeReadONU}})
+		}, "api_key:noc may no longer run the job: caller lacks the required scope"},
+		{"restricted to another PON", func(principals *fakePrincipalResolver) {
+			principals.set(&model.Principal{ID: "k1", Name: "noc", Method: model.AuthMethodAPIKey, Scopes: []string{model.ScopeBatch},
+				PONs: []model.BoardPON{{Board: 1, PON: 2}}})
+		}, "api_key:noc may no longer run the job: API key is not allowed on this board/PON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principals := newFakePrincipalResolver(noc)
+			executor := &fakeJobExecutor{started: make(chan int), release: make(chan struct{})}
+			u, submitter := startJobUsecaseWithPrincipals(t, newMemoryJobStore(), nil, principals, executor)
+
+			job, err := submitter.SubmitJob(model.ContextWithPrincipal(context.Background(), noc), model.JobTypeBatchReboot, &model.BatchONURebootRequest{}, onuTargets(1, 2, 3))
+			if err != nil {
+				t.Fatalf("SubmitJob() error = %v", err)
+			}
+			if job.RunAs == nil || job.RunAs.ID != "k1" {
+				t.Errorf("RunAs = %+v, want the identity of the submitting key", job.RunAs)
+			}
+			<-executor.started
+			tt.change(principals)
+			executor.release <- struct{}{}
+
+			job = waitForJob(t, u, job.ID)
+			if job.Status != model.JobFailed || job.Error != tt.wantErr || job.Progress.Succeeded != 1 || job.Progress.Pending != 2 {
+				t.Errorf("job = %+v, want failed with %q and ONUs 2 and 3 pending", job, tt.wantErr)
+			}
+			if runs := executor.ranTargets(); fmt.Sprint(runs) != "[1]" {
+				t.Errorf("runs = %v, want only ONU 1", runs)
+			}
+		})
+	}
+}
+
+func TestJobUsecase_ReadOnlyJobsDoNotWaitForBatches(t *testing.T) {
+	executor := &fakeJobExecutor{started: make(chan int), release: make(chan struct{})}
+	u, submitter := startJobUsecase(t, newMemoryJobStore(), nil, executor)
+	ctx := context.Background()
+
+	batch, _ := submitter.SubmitJob(ctx, model.JobTypeBatchReboot, &model.BatchONURebootRequest{}, onuTargets(1, 2))
+	<-executor.started
+
+	// The batch is blocked on its first ONU; backups and monitoring run on the read-only queue meanwhile
+	backup, _ := submitter.SubmitJob(ctx, model.JobTypeBackupOLT, nil, nil)
+	monitoring, _ := submitter.SubmitJob(ctx, model.JobTypePONMonitoring, &model.PONMonitoringJobRequest{Board: 1, PON: 1}, nil)
+	for _, job := range []*model.Job{backup, monitoring} {
+		if job = waitForJob(t, u, job.ID); job.Status != model.JobCompleted {
+			t.Errorf("read-only job = %+v, want completed while the batch runs", job)
+		}
+	}
+
+	// Jobs that change the OLT still wait for each other
+	second, _ := submitter.SubmitJob(ctx, model.JobTypeBatchDelete, &model.BatchONUDeleteRequest{}, onuTargets(3))
+	executor.release <- struct{}{}
+	for _, id := range []int{2, 3} {
+		if started := <-executor.started; started != id {
+			t.Errorf("started ONU %d, want %d", started, id)
+		}
+		executor.release <- struct{}{}
+	}
+	if job := waitForJob(t, u, batch.ID); job.Status != model.JobCompleted {
+		t.Errorf("batch = %+v, want completed", job)
+	}
+	if job := waitForJob(t, u, second.ID); job.Status != model.JobCompleted {
+		t.Errorf("second batch = %+v, want completed", job)
+	}
+}
+
+func TestJobUsecase_ContinuesUnfinishedJobs(t *testing.T) {
+	store := newMemoryJobStore()
+	created := time.Now().Add(-time.Minute)
+	interrupted := &model.Job{
+		ID: "interrupted", Type: model.JobTypeBatchReboot, OLTID: "olt-1", Status: model.JobRunning, CreatedAt: created,
+		Targets: []model.JobTarget{
+			{PONPort: "1/1/1", ONUID: 1, Status: model.JobTargetSucceeded},
+			{PONPort: "1/1/1", ONUID: 2, Status: model.JobTargetRunning},
+			{PONPort: "1/1/1", ONUID: 3, Status: model.JobTargetPending},
+		},
+	}
+	backup := &model.Job{ID: "backup", Type: model.JobTypeBackupOLT, OLTID: "olt-1", Status: model.JobQueued, CreatedAt: created.Add(time.Second)}
+	restore := &model.Job{ID: "restore", Type: model.JobTypeRestore, OLTID: "olt-1", Status: model.JobRunning, CreatedAt: created}
+	orphan := &model.Job{ID: "orphan", Type: model.JobTypeBatchDelete, OLTID: "removed", Status: model.JobQueued, CreatedAt: created,
+		Targets: []model.JobTarget{{PONPort: "1/1/1", ONUID: 9, Status: model.JobTargetPending}}}
+	for _, job := range []*model.Job{interrupted, backup, restore, orphan} {
+		_ = store.Save(context.Background(), job)
+	}
+
+	executor := &fakeJobExecutor{}
+	u, _ := startJobUsecase(t, store, nil, executor)
+
+	// The target that was running may or may not have been applied, so it is only retried on resume
+	job := waitForJob(t, u, "interrupted")
+	if job.Status != model.JobFailed || job.Targets[1].Status != model.JobTargetFailed || job.Targets[2].Status != model.JobTargetSucceeded {
+		t.Errorf("interrupted job = %+v", job)
+	}
+	if runs := executor.ranTargets(); fmt.Sprint(runs) != "[3]" {
+		t.Errorf("runs = %v, want only ONU 3", runs)
+	}
+
+	job = waitForJob(t, u, "backup")
+	if job.Status != model.JobCompleted || string(job.Result) != `{"type":"config.backup_olt"}` || job.Progress.Succeeded != 1 {
+		t.Errorf("backup job = %+v", job)
+	}
+
+	// A job without targets may have been partly applied, so it is not started over
+	job = waitForJob(t, u, "restore")
+	if job.Status != model.JobFailed || job.Result != nil || job.Error != "interrupted by a restart of the API; resume the job to run it again" {
+		t.Errorf("restore job = %+v", job)
+	}
+
+	job = waitForJob(t, u, "orphan")
+	if job.Status != model.JobFailed || job.Error != "OLT removed is not registered" {
+		t.Errorf("orphan job = %+v", job)
+	}
+}
+
+// blockingJobStore blocks the saves of one job until release is closed
+type blockingJobStore struct {
+	*memoryJobStore
+	blockID string
+	blocked chan struct{} // Closed when a save of blockID is blocked
+	release chan struct{}
+	once    sync.Once
+}
+
+func (s *blockingJobStore) Save(ctx context.Context, job *model.Job) error {
+	if job.ID == s.blockID {
+		s.once.Do(func() { close(s.blocked) })
+		<-s.release
+	}
+	return s.memoryJobStore.Save(ctx, job)
+}
+
+func TestJobUsecase_SlowStoreDoesNotBlockOtherJobs(t *testing.T) {
+	store := &blockingJobStore{memoryJobStore: newMemoryJobStore(), blockID: "slow", blocked: make(chan struct{}), release: make(chan struct{})}
+	created := time.Now()
+	for _, job := range []*model.Job{
+		{ID: "slow", Type: model.JobTypeBackupOLT, OLTID: "olt-1", Status: model.JobQueued, CreatedAt: created},
+		{ID: "queued", Type: model.JobTypeBackupOLT, OLTID: "olt-1", Status: model.JobQueued, CreatedAt: created.Add(time.Second)},
+	} {
+		_ = store.memoryJobStore.Save(context.Background(), job)
+	}
+	u, _ := startJobUsecase(t, store, nil, &fakeJobExecutor{})
+	<-store.blocked
+	defer close(store.release)
+
+	// The worker waits for the store while saving "slow"; other jobs can still be changed
+	done := make(chan error)
+	go func() {
+		_, err := u.CancelJob(context.Background(), "queued")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("CancelJob() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("CancelJob() blocked by the save of another job")
+	}
+}
+
+func TestJobUsecase_Access(t *testing.T) {
+	u, submitter := startJobUsecase(t, newMemoryJobStore(), nil, &fakeJobExecutor{})
+	batch := &model.Principal{ID: "batch", Method: model.AuthMethodAPIKey, Name: "batch", Scopes: []string{model.ScopeBatch}}
+	restricted := &model.Principal{ID: "pon", Method: model.AuthMethodAPIKey, Name: "pon", Scopes: []string{model.ScopeBatch, model.ScopeReadONU},
+		PONs: []model.BoardPON{{Board: 1, PON: 1}}}
+	reader := &model.Principal{ID: "reader", Method: model.AuthMethodAPIKey, Name: "reader", Scopes: []string{model.ScopeReadONU}}
+
+	byBatch, _ := submitter.SubmitJob(model.ContextWithPrincipal(context.Background(), batch), model.JobTypeBatchReboot, nil, onuTargets(1))
+	byRestricted, _ := submitter.SubmitJob(model.ContextWithPrincipal(context.Background(), restricted), model.JobTypeBatchBlock, nil, onuTargets(2))
+	monitoring, _ := submitter.SubmitJob(context.Background(), model.JobTypePONMonitoring, &model.PONMonitoringJobRequest{Board: 1, PON: 1}, nil)
+	for _, job := range []*model.Job{byBatch, byRestricted, monitoring} {
+		waitForJob(t, u, job.ID)
+	}
+
+	tests := []struct {
+		name      string
+		principal *model.Principal
+		query     model.JobQuery
+		want      []string
+	}{
+		{"without authentication", nil, model.JobQuery{}, []string{monitoring.ID, byRestricted.ID, byBatch.ID}},
+		{"type group", nil, model.JobQuery{Type: "batch"}, []string{byRestricted.ID, byBatch.ID}},
+		{"scope", batch, model.JobQuery{}, []string{byRestricted.ID, byBatch.ID}},
+		{"restricted caller sees own jobs", restricted, model.JobQuery{}, []string{byRestricted.ID}},
+		{"reader", reader, model.JobQuery{Status: model.JobCompleted}, []string{monitoring.ID}},
+		{"limit", nil, model.JobQuery{Limit: 1}, []string{monitoring.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = model.ContextWithPrincipal(ctx, tt.principal)
+			}
+			jobs, err := u.ListJobs(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("ListJobs() error = %v", err)
+			}
+			var ids []string
+			for _, job := range jobs {
+				ids = append(ids, job.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.want) {
+				t.Errorf("ListJobs() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	var appErr *apperrors.AppError
+	if _, err := u.GetJob(model.ContextWithPrincipal(context.Background(), reader), byBatch.ID); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeForbidden {
+		t.Errorf("GetJob() without scope error = %v, want forbidden", err)
+	}
+	if _, err := u.ResumeJob(model.ContextWithPrincipal(context.Background(), restricted), byBatch.ID); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeForbidden {
+		t.Errorf("ResumeJob() of another caller's job error = %v, want forbidden", err)
+	}
+	if _, err := u.ListJobs(context.Background(), model.JobQuery{Limit: 5000}); err == nil {
+		t.Error("ListJobs(limit 5000) error = nil")
+	}
+}
@@ -4,10 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
-	"github.com/gosnmp/gosnmp"
 	"github.com/rs/zerolog/log"
 	"github.com/s4lfanet/go-api-c320/config"
 	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
@@ -18,14 +16,15 @@ import (
 
 // MonitoringUsecase handles real-time ONU monitoring operations
 type MonitoringUsecase struct {
-	snmp      *gosnmp.GoSNMP
+	snmp      repository.SnmpRepositoryInterface
 	cfg       *config.Config
 	onuRepo   *repository.OnuRepository
 	telnetMgr *repository.TelnetSessionManager
 }
 
-// NewMonitoringUsecase creates a new MonitoringUsecase instance
-func NewMonitoringUsecase(snmp *gosnmp.GoSNMP, cfg *config.Config, onuRepo *repository.OnuRepository, telnetMgr *repository.TelnetSessionManager) *MonitoringUsecase {
+// NewMonitoringUsecase creates a new MonitoringUsecase instance.
+// OIDs are resolved through the Board/PON mappings and the OID profile of cfg.
+func NewMonitoringUsecase(snmp repository.SnmpRepositoryInterface, cfg *config.Config, onuRepo *repository.OnuRepository, telnetMgr *repository.TelnetSessionManager) *MonitoringUsecase {
 	return &MonitoringUsecase{
 		snmp:      snmp,
 		cfg:       cfg,
@@ -35,32 +34,34 @@ func NewMonitoringUsecase(snmp *gosnmp.GoSNMP, cfg *config.Config, onuRepo *repo
 }
 
 // GetONUMonitoring fetches real-time monitoring data for a single ONU
-func (uc *MonitoringUsecase) GetONUMonitoring(ctx context.Context, ponPort string, onuID int) (*model.ONUMonitoringInfo, error) {
-	log.Info().Str("pon_port", ponPort).Int("onu_id", onuID).Msg("Getting ONU monitoring data")
+func (uc *MonitoringUsecase) GetONUMonitoring(ctx context.Context, boardID, ponID, onuID int) (*model.ONUMonitoringInfo, error) {
+	log.Info().Int("board", boardID).Int("pon", ponID).Int("onu_id", onuID).Msg("Getting ONU monitoring data")
 
-	// Get PON index from config
-	boardPonKey := config.BoardPonKey{BoardID: 1, PonID: utils.ConvertStringToInt(ponPort)}
-	_, exists := uc.cfg.BoardPonMap[boardPonKey]
-	if !exists {
-		return nil, apperrors.NewNotFoundError("PON port", ponPort)
+	// Get OIDs of the board/PON from config
+	ponCfg, err := uc.cfg.GetBoardPonConfig(boardID, ponID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("PON port", fmt.Sprintf("%d/%d", boardID, ponID))
 	}
 
-	ponIndex := repository.CalculatePonIndex(1, utils.ConvertStringToInt(ponPort))
-	onuIndexStr := fmt.Sprintf("%d.%d", ponIndex, onuID)
-
 	monitoring := &model.ONUMonitoringInfo{
-		PonPort:    ponPort,
+		Board:      boardID,
+		PonPort:    strconv.Itoa(ponID),
 		OnuID:      onuID,
 		LastUpdate: time.Now(),
 	}
 
-	// Get ONU basic info (serial, model, firmware)
-	serialOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.13.3.1.5.%s", onuIndexStr)    // Device SN
-	modelOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.13.3.1.10.%s", onuIndexStr)    // Model
-	firmwareOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.13.3.1.11.%s", onuIndexStr) // Firmware
-	statusOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.31.4.1.100.%s", onuIndexStr)  // Online status
-
-	oids := []string{serialOID, modelOID, firmwareOID, statusOID}
+	// Get ONU basic info (serial, model, firmware, status)
+	baseOID := uc.cfg.BaseOID()
+	_, profile := uc.cfg.FirmwareProfile()
+	serialOID := onuColumnOID(baseOID, ponCfg.OnuSerialNumberOID, onuID)
+	modelOID := onuColumnOID(uc.cfg.OltCfg.BaseOID2, ponCfg.OnuTypeOID, onuID)
+	statusOID := onuColumnOID(baseOID, ponCfg.OnuStatusOID, onuID)
+	firmwareOID := onuColumnOID(baseOID, ponCfg.OnuFirmwareOID, onuID)
+
+	oids := []string{serialOID, modelOID, statusOID}
+	if firmwareOID != "" {
+		oids = append(oids, firmwareOID)
+	}
 	result, err := uc.snmp.Get(oids)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to get ONU basic info")
@@ -69,49 +70,51 @@ func (uc *MonitoringUsecase) GetONUMonitoring(ctx context.Context, ponPort strin
 
 	// Parse basic info
 	for _, variable := range result.Variables {
-		oidStr := variable.Name
-		switch {
-		case oidStr == serialOID:
-			monitoring.SerialNumber = utils.ExtractStringValue(variable)
-		case oidStr == modelOID:
-			monitoring.Model = utils.ExtractStringValue(variable)
-		case oidStr == firmwareOID:
-			monitoring.FirmwareVer = utils.ExtractStringValue(variable)
-		case oidStr == statusOID:
-			monitoring.OnlineStatus = utils.ExtractIntValue(variable)
+		switch variable.Name {
+		case serialOID:
+			monitoring.SerialNumber = utils.ExtractSerialNumber(variable.Value)
+		case modelOID:
+			monitoring.Model = utils.ExtractStringValue(variable.Value)
+		case firmwareOID:
+			monitoring.FirmwareVer = utils.ExtractStringValue(variable.Value)
+		case statusOID:
+			// The online value differs per firmware (V2.1: 1, V2.2: 4)
+			if utils.ExtractIntValue(variable.Value) == profile.OnuStatusOnline {
+				monitoring.OnlineStatus = 1
+			}
 		}
 	}
 
-	// Get ONU statistics
-	rxPacketsOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.31.4.1.3.%s", onuIndexStr) // RX packets
-	rxBytesOID := fmt.Sprintf("1.3.6.1.4.1.3902.1012.3.31.4.1.6.%s", onuIndexStr)   // RX bytes
+	// Get ONU statistics, if the firmware has the counters
+	rxPacketsOID := onuColumnOID(baseOID, ponCfg.OnuRxPacketsOID, onuID)
+	rxBytesOID := onuColumnOID(baseOID, ponCfg.OnuRxBytesOID, onuID)
 
-	statOIDs := []string{rxPacketsOID, rxBytesOID}
-	statResult, err := uc.snmp.Get(statOIDs)
-	if err != nil {
-		log.Warn().Err(err).Msg("Failed to get ONU statistics, continuing without stats")
-	} else {
-		stats := &model.ONUStatistics{}
-		for _, variable := range statResult.Variables {
-			oidStr := variable.Name
-			switch {
-			case oidStr == rxPacketsOID:
-				stats.RxPackets = utils.ExtractUint64Value(variable)
-			case oidStr == rxBytesOID:
-				stats.RxBytes = utils.ExtractUint64Value(variable)
+	if rxPacketsOID != "" && rxBytesOID != "" {
+		statResult, err := uc.snmp.Get([]string{rxPacketsOID, rxBytesOID})
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to get ONU statistics, continuing without stats")
+		} else {
+			stats := &model.ONUStatistics{}
+			for _, variable := range statResult.Variables {
+				switch variable.Name {
+				case rxPacketsOID:
+					stats.RxPackets = utils.ExtractUint64Value(variable.Value)
+				case rxBytesOID:
+					stats.RxBytes = utils.ExtractUint64Value(variable.Value)
+				}
 			}
-		}
 
-		// Calculate rate (simplified - would need time-based calculation for accurate rate)
-		if stats.RxBytes > 0 {
-			stats.RxRate = utils.FormatBytesRate(stats.RxBytes)
+			// Calculate rate (simplified - would need time-based calculation for accurate rate)
+			if stats.RxBytes > 0 {
+				stats.RxRate = utils.FormatBytesRate(stats.RxBytes)
+			}
+			monitoring.Statistics = stats
 		}
-		monitoring.Statistics = stats
 	}
 
 	// Get optical info via Telnet (V2.1.0 doesn't have SNMP OIDs for optical power)
 	if uc.telnetMgr != nil {
-		opticalInfo, err := uc.telnetMgr.GetONUOpticalInfo(ctx, 1, utils.ConvertStringToInt(ponPort), onuID)
+		opticalInfo, err := uc.telnetMgr.GetONUOpticalInfo(ctx, boardID, ponID, onuID)
 		if err != nil {
 			log.Warn().Err(err).Msg("Failed to get ONU optical info via Telnet, continuing without optical data")
 		} else if opticalInfo != nil {
@@ -139,27 +142,28 @@ func (uc *MonitoringUsecase) GetONUMonitoring(ctx context.Context, ponPort strin
 }
 
 // GetPONMonitoring fetches aggregated monitoring data for a PON port
-func (uc *MonitoringUsecase) GetPONMonitoring(ctx context.Context, ponPort string) (*model.PONMonitoringInfo, error) {
-	log.Info().Str("pon_port", ponPort).Msg("Getting PON monitoring data")
+func (uc *MonitoringUsecase) GetPONMonitoring(ctx context.Context, boardID, ponID int) (*model.PONMonitoringInfo, error) {
+	log.Info().Int("board", boardID).Int("pon", ponID).Msg("Getting PON monitoring data")
 
 	// Get PON config
-	boardPonKey := config.BoardPonKey{BoardID: 1, PonID: utils.ConvertStringToInt(ponPort)}
-	_, exists := uc.cfg.BoardPonMap[boardPonKey]
-	if !exists {
-		return nil, apperrors.NewNotFoundError("PON port", ponPort)
+	ponCfg, err := uc.cfg.GetBoardPonConfig(boardID, ponID)
+	if err != nil {
+		return nil, apperrors.NewNotFoundError("PON port", fmt.Sprintf("%d/%d", boardID, ponID))
 	}
 
-	ponIndex := repository.CalculatePonIndex(1, utils.ConvertStringToInt(ponPort))
+	_, profile := uc.cfg.FirmwareProfile()
+	_, ponIndex, _ := profile.PonIndex(boardID, ponID)
 
 	monitoring := &model.PONMonitoringInfo{
-		PonPort:    ponPort,
+		Board:      boardID,
+		PonPort:    strconv.Itoa(ponID),
 		PonIndex:   ponIndex,
 		LastUpdate: time.Now(),
 		ONUs:       []model.ONUMonitoringInfo{},
 	}
 
 	// Get all ONUs for this PON
-	onus, err := uc.onuRepo.GetByBoardIDAndPonID(ctx, 1, utils.ConvertStringToInt(ponPort))
+	onus, err := uc.onuRepo.GetByBoardIDAndPonID(ctx, boardID, ponID)
 	if err != nil {
 		log.Warn().Err(err).Msg("Failed to get ONUs for PON, continuing with empty list")
 	}
@@ -167,7 +171,7 @@ func (uc *MonitoringUsecase) GetPONMonitoring(ctx context.Context, ponPort strin
 	// Fetch monitoring for each ONU
 	monitoring.OnuCount = len(onus)
 	for _, onu := range onus {
-		onuMon, err := uc.GetONUMonitoring(ctx, ponPort, onu.ID)
+		onuMon, err := uc.GetONUMonitoring(ctx, boardID, ponID, onu.ID)
 		if err != nil {
 			log.Warn().Err(err).Int("onu_id", onu.ID).Msg("Failed to get ONU monitoring")
 			continue
@@ -181,29 +185,29 @@ func (uc *MonitoringUsecase) GetPONMonitoring(ctx context.Context, ponPort strin
 		}
 	}
 
-	// Get PON port statistics
-	ponIndexStr := fmt.Sprintf("%d.1", ponIndex)
-	rxPacketsOID := fmt.Sprintf(".1.3.6.1.4.1.3902.1012.3.31.5.1.3.%s", ponIndexStr)
-	rxBytesOID := fmt.Sprintf(".1.3.6.1.4.1.3902.1012.3.31.5.1.6.%s", ponIndexStr)
-
-	statOIDs := []string{rxPacketsOID, rxBytesOID}
-	statResult, err := uc.snmp.Get(statOIDs)
-	if err == nil && len(statResult.Variables) > 0 {
-		stats := &model.PONStatistics{}
-		for _, variable := range statResult.Variables {
-			oidStr := variable.Name
-			switch {
-			case strings.Contains(oidStr, ".3.31.5.1.3."):
-				stats.RxPackets = utils.ExtractUint64Value(variable)
-			case strings.Contains(oidStr, ".3.31.5.1.6."):
-				stats.RxBytes = utils.ExtractUint64Value(variable)
+	// Get PON port statistics, if the firmware has the counters
+	if ponCfg.PonRxPacketsOID != "" && ponCfg.PonRxBytesOID != "" {
+		baseOID := uc.cfg.BaseOID()
+		rxPacketsOID := onuColumnOID(baseOID, ponCfg.PonRxPacketsOID, 1)
+		rxBytesOID := onuColumnOID(baseOID, ponCfg.PonRxBytesOID, 1)
+
+		statResult, err := uc.snmp.Get([]string{rxPacketsOID, rxBytesOID})
+		if err == nil && len(statResult.Variables) > 0 {
+			stats := &model.PONStatistics{}
+			for _, variable := range statResult.Variables {
+				switch variable.Name {
+				case rxPacketsOID:
+					stats.RxPackets = utils.ExtractUint64Value(variable.Value)
+				case rxBytesOID:
+					stats.RxBytes = utils.ExtractUint64Value(variable.Value)
+				}
 			}
-		}
 
-		if stats.RxBytes > 0 {
-			stats.RxRate = utils.FormatBytesRate(stats.RxBytes)
+			if stats.RxBytes > 0 {
+				stats.RxRate = utils.FormatBytesRate(stats.RxBytes)
+			}
+			monitoring.Statistics = stats
 		}
-		monitoring.Statistics = stats
 	}
 
 	log.Info().
@@ -215,21 +219,23 @@ func (uc *MonitoringUsecase) GetPONMonitoring(ctx context.Context, ponPort strin
 	return monitoring, nil
 }
 
-// GetOLTMonitoring fetches overall OLT monitoring summary
+// GetOLTMonitoring fetches overall OLT monitoring summary, with ONU counts per board
 func (uc *MonitoringUsecase) GetOLTMonitoring(ctx context.Context) (*model.OLTMonitoringSummary, error) {
 	log.Info().Msg("Getting OLT monitoring summary")
 
 	summary := &model.OLTMonitoringSummary{
+		Boards:     []model.BoardMonitoringSummary{},
 		PONPorts:   []model.PONMonitoringInfo{},
 		LastUpdate: time.Now(),
 	}
 
-	// Get all configured PON ports
-	for key := range uc.cfg.BoardPonMap {
-		ponPort := strconv.Itoa(key.PonID)
-		ponMon, err := uc.GetPONMonitoring(ctx, ponPort)
+	// Get all configured PON ports, board by board
+	keys := uc.cfg.BoardPonKeys()
+
+	for _, key := range keys {
+		ponMon, err := uc.GetPONMonitoring(ctx, key.BoardID, key.PonID)
 		if err != nil {
-			log.Warn().Err(err).Str("pon", ponPort).Msg("Failed to get PON monitoring")
+			log.Warn().Err(err).Int("board", key.BoardID).Int("pon", key.PonID).Msg("Failed to get PON monitoring")
 			continue
 		}
 
@@ -237,14 +243,34 @@ func (uc *MonitoringUsecase) GetOLTMonitoring(ctx context.Context) (*model.OLTMo
 		summary.TotalONUs += ponMon.OnuCount
 		summary.OnlineONUs += ponMon.OnlineCount
 		summary.OfflineONUs += ponMon.OfflineCount
+
+		// Keys are sorted, so a new board always starts a new aggregate
+		if n := len(summary.Boards); n == 0 || summary.Boards[n-1].Board != key.BoardID {
+			summary.Boards = append(summary.Boards, model.BoardMonitoringSummary{Board: key.BoardID})
+		}
+		board := &summary.Boards[len(summary.Boards)-1]
+		board.PONPorts++
+		board.TotalONUs += ponMon.OnuCount
+		board.OnlineONUs += ponMon.OnlineCount
+		board.OfflineONUs += ponMon.OfflineCount
 	}
 
 	log.Info().
 		Int("total_onus", summary.TotalONUs).
 		Int("online", summary.OnlineONUs).
 		Int("offline", summary.OfflineONUs).
+		Int("boards", len(summary.Boards)).
 		Int("pon_ports", len(summary.PONPorts)).
 		Msg("Successfully retrieved OLT monitoring summary")
 
 	return summary, nil
 }
+
+// onuColumnOID returns the OID of a row in a Board/PON column: base + column OID + "." + id.
+// It is empty if the firmware has no such column.
+func onuColumnOID(base, column string, id int) string {
+	if column == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%s.%d", base, column, id)
+}
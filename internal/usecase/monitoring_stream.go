@@ -0,0 +1,377 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// streamBuffer is the number of updates buffered per subscriber. A subscriber that falls further
+// behind is disconnected instead of slowing down the others; it gets a fresh snapshot when it reconnects.
+const streamBuffer = 256
+
+// MonitoringStreamUsecase streams the ONU status changes, optical readings and PON counters of the OLTs
+// to live subscribers. The PON ports are read by the state collector of each OLT (OLTMetricsCollector),
+// which also feeds /metrics, so the cost on the OLT does not grow with the number of clients. Events of
+// SNMP traps are streamed as they arrive.
+type MonitoringStreamUsecase interface {
+	// RegisterOLT adds an OLT with its configured PON ports; OLTs are registered before Run
+	RegisterOLT(oltID string, cfg *config.Config)
+
+	// Subscribe returns the last known state of the OLT matching the filter and the channel of the following updates.
+	// The channel is closed when ctx is done, the stream stops or the subscriber falls behind.
+	Subscribe(ctx context.Context, oltID string, filter model.MonitoringStreamFilter) ([]model.MonitoringUpdate, <-chan model.MonitoringUpdate, error)
+
+	// PublishEvent streams an event received as SNMP trap; ONU online and offline events also update the ONU status
+	PublishEvent(event model.OLTEvent)
+
+	// PublishPON streams the state of a PON port read by the collector of the OLT at the given time.
+	// ONUs missing from it are dropped from the state with an onu_removed update.
+	PublishPON(oltID string, key config.BoardPonKey, info *model.PONMonitoringInfo, at time.Time)
+
+	// Watched reports whether the OLT has subscribers, and returns the channel signaled when a client subscribes
+	Watched(oltID string) (bool, <-chan struct{})
+
+	// Run waits until ctx is canceled, then closes all subscriptions
+	Run(ctx context.Context)
+}
+
+type monitoringStream struct {
+	mu      sync.Mutex
+	olts    map[string]*streamOLT // By OLT ID
+	stopped bool
+}
+
+// streamOLT holds the subscribers and the last known state of one OLT
+type streamOLT struct {
+	id          string
+	pons        []config.BoardPonKey                      // Configured PON ports, sorted
+	wake        chan struct{}                             // Signals the collector that a client subscribed
+	subscribers map[*streamSubscriber]bool                // Guarded by monitoringStream.mu
+	state       map[streamStateKey]model.MonitoringUpdate // Last status, optical and counter update; guarded by monitoringStream.mu
+}
+
+// streamSubscriber is a client of the stream
+type streamSubscriber struct {
+	filter  model.MonitoringStreamFilter
+	updates chan model.MonitoringUpdate
+}
+
+// streamStateKey identifies the last update of a type for an ONU or PON port
+type streamStateKey struct {
+	updateType string
+	board      int
+	pon        int
+	onuID      int
+}
+
+// NewMonitoringStreamUsecase creates the live monitoring stream
+func NewMonitoringStreamUsecase() MonitoringStreamUsecase {
+	return &monitoringStream{olts: make(map[string]*streamOLT)}
+}
+
+// RegisterOLT adds an OLT to the stream
+func (s *monitoringStream) RegisterOLT(oltID string, cfg *config.Config) {
+	pons := cfg.BoardPonKeys()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.olts[oltID] = &streamOLT{
+		id:          oltID,
+		pons:        pons,
+		wake:        make(chan struct{}, 1),
+		subscribers: make(map[*streamSubscriber]bool),
+		state:       make(map[streamStateKey]model.MonitoringUpdate),
+	}
+}
+
+// Subscribe validates the filter and registers a subscriber
+func (s *monitoringStream) Subscribe(ctx context.Context, oltID string, filter model.MonitoringStreamFilter) ([]model.MonitoringUpdate, <-chan model.MonitoringUpdate, error) {
+	if filter.PON != 0 && filter.Board == 0 {
+		return nil, nil, apperrors.NewValidationError("pon requires board", map[string]interface{}{"pon": filter.PON})
+	}
+	if filter.ONUID != 0 && filter.PON == 0 {
+		return nil, nil, apperrors.NewValidationError("onu_id requires board and pon", map[string]interface{}{"onu_id": filter.ONUID})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	olt, ok := s.olts[oltID]
+	if !ok {
+		return nil, nil, apperrors.NewNotFoundError("OLT", oltID)
+	}
+	if filter.Board != 0 && len(olt.subscribedPONs([]model.MonitoringStreamFilter{filter})) == 0 {
+		if filter.PON == 0 {
+			return nil, nil, apperrors.NewNotFoundError("board", strconv.Itoa(filter.Board))
+		}
+		return nil, nil, apperrors.NewNotFoundError("PON port", fmt.Sprintf("%d/%d", filter.Board, filter.PON))
+	}
+	if s.stopped {
+		return nil, nil, apperrors.NewInternalError("monitoring stream is stopped", nil)
+	}
+
+	snapshot := make([]model.MonitoringUpdate, 0)
+	for _, update := range olt.state {
+		if filter.Matches(update) {
+			snapshot = append(snapshot, update)
+		}
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		a, b := snapshot[i], snapshot[j]
+		if a.Board != b.Board {
+			return a.Board < b.Board
+		}
+		if a.PON != b.PON {
+			return a.PON < b.PON
+		}
+		if a.ONUID != b.ONUID {
+			return a.ONUID < b.ONUID
+		}
+		return a.Type < b.Type
+	})
+
+	sub := &streamSubscriber{filter: filter, updates: make(chan model.MonitoringUpdate, streamBuffer)}
+	olt.subscribers[sub] = true
+	select {
+	case olt.wake <- struct{}{}:
+	default:
+	}
+
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		olt.unsubscribe(sub)
+	}()
+
+	return snapshot, sub.updates, nil
+}
+
+// PublishEvent streams an event of a registered OLT
+func (s *monitoringStream) PublishEvent(event model.OLTEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	olt, ok := s.olts[event.OLTID]
+	if !ok {
+		return
+	}
+
+	olt.publish(model.MonitoringUpdate{
+		Type:   model.StreamEvent,
+		Source: model.StreamSourceTrap,
+		OLTID:  olt.id,
+		Board:  event.Board,
+		PON:    event.PON,
+		ONUID:  event.ONUID,
+		Time:   event.ReceivedAt,
+		Event:  &event,
+	})
+
+	if event.ONUID == 0 {
+		return
+	}
+	var online bool
+	switch event.Type {
+	case model.EventOnuOnline:
+		online = true
+	case model.EventOnuOffline, model.EventOnuDyingGasp, model.EventOnuLOS:
+		online = false
+	default:
+		return
+	}
+	olt.publishStatus(event.Board, event.PON, event.ONUID, online, model.MonitoringUpdate{
+		Source: model.StreamSourceTrap,
+		Time:   event.ReceivedAt,
+		Status: &model.ONUStatusChange{Reason: event.Status},
+	})
+}
+
+// PublishPON streams the state of a PON port of a registered OLT
+func (s *monitoringStream) PublishPON(oltID string, key config.BoardPonKey, info *model.PONMonitoringInfo, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if olt, ok := s.olts[oltID]; ok {
+		olt.publishPON(key, info, at)
+	}
+}
+
+// Watched reports whether a registered OLT has subscribers; the channel of an unknown OLT is nil
+func (s *monitoringStream) Watched(oltID string) (bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	olt, ok := s.olts[oltID]
+	if !ok {
+		return false, nil
+	}
+	return len(olt.subscribers) > 0, olt.wake
+}
+
+// Run closes all subscriptions when ctx is canceled
+func (s *monitoringStream) Run(ctx context.Context) {
+	<-ctx.Done()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopped = true
+	for _, olt := range s.olts {
+		for sub := range olt.subscribers {
+			olt.unsubscribe(sub)
+		}
+	}
+}
+
+// subscribedPONs returns the configured PON ports selected by any of the filters, sorted
+func (o *streamOLT) subscribedPONs(filters []model.MonitoringStreamFilter) []config.BoardPonKey {
+	var pons []config.BoardPonKey
+	for _, key := range o.pons {
+		for _, filter := range filters {
+			if selectsPON(filter, key) {
+				pons = append(pons, key)
+				break
+			}
+		}
+	}
+	return pons
+}
+
+// selectsPON reports whether a filter selects (some updates of) a PON port
+func selectsPON(filter model.MonitoringStreamFilter, key config.BoardPonKey) bool {
+	return (filter.Board == 0 || filter.Board == key.BoardID) && (filter.PON == 0 || filter.PON == key.PonID)
+}
+
+// publishPON streams the polled state of a PON port: ONU status changes, optical readings and the PON counters.
+// ONUs that are no longer on the PON port (deleted or moved) are dropped with an onu_removed update.
+func (o *streamOLT) publishPON(key config.BoardPonKey, info *model.PONMonitoringInfo, now time.Time) {
+	present := make(map[int]bool, len(info.ONUs))
+	for _, onu := range info.ONUs {
+		present[onu.OnuID] = true
+	}
+	gone := make(map[int]bool)
+	for stateKey := range o.state {
+		if stateKey.board == key.BoardID && stateKey.pon == key.PonID && stateKey.onuID != 0 && !present[stateKey.onuID] {
+			gone[stateKey.onuID] = true
+			delete(o.state, stateKey)
+		}
+	}
+	removed := make([]int, 0, len(gone))
+	for onuID := range gone {
+		removed = append(removed, onuID)
+	}
+	sort.Ints(removed)
+	for _, onuID := range removed {
+		o.publish(model.MonitoringUpdate{
+			Type:   model.StreamONURemoved,
+			Source: model.StreamSourcePoll,
+			OLTID:  o.id,
+			Board:  key.BoardID,
+			PON:    key.PonID,
+			ONUID:  onuID,
+			Time:   now,
+		})
+	}
+
+	for _, onu := range info.ONUs {
+		o.publishStatus(key.BoardID, key.PonID, onu.OnuID, onu.OnlineStatus == 1, model.MonitoringUpdate{
+			Source: model.StreamSourcePoll,
+			Time:   now,
+			Status: &model.ONUStatusChange{SerialNumber: onu.SerialNumber},
+		})
+	}
+
+	for _, onu := range info.ONUs {
+		if onu.Optical == nil {
+			continue
+		}
+		optical := *onu.Optical
+		o.publish(model.MonitoringUpdate{
+			Type:    model.StreamONUOptical,
+			Source:  model.StreamSourcePoll,
+			OLTID:   o.id,
+			Board:   key.BoardID,
+			PON:     key.PonID,
+			ONUID:   onu.OnuID,
+			Time:    now,
+			Optical: &optical,
+		})
+	}
+
+	o.publish(model.MonitoringUpdate{
+		Type:   model.StreamPONCounters,
+		Source: model.StreamSourcePoll,
+		OLTID:  o.id,
+		Board:  key.BoardID,
+		PON:    key.PonID,
+		Time:   now,
+		Counters: &model.PONCounters{
+			OnuCount:     info.OnuCount,
+			OnlineCount:  info.OnlineCount,
+			OfflineCount: info.OfflineCount,
+			Statistics:   info.Statistics,
+		},
+	})
+}
+
+// publishStatus streams the state of an ONU if it changed or was not known.
+// update carries the source, time and the details of the status.
+func (o *streamOLT) publishStatus(board, pon, onuID int, online bool, update model.MonitoringUpdate) {
+	status := *update.Status
+	status.Online = online
+
+	if previous, ok := o.state[streamStateKey{model.StreamONUStatus, board, pon, onuID}]; ok {
+		if previous.Status.Online == online {
+			return
+		}
+		wasOnline := previous.Status.Online
+		status.WasOnline = &wasOnline
+		if status.SerialNumber == "" {
+			status.SerialNumber = previous.Status.SerialNumber
+		}
+	}
+
+	update.Type = model.StreamONUStatus
+	update.OLTID = o.id
+	update.Board, update.PON, update.ONUID = board, pon, onuID
+	update.Status = &status
+	o.publish(update)
+}
+
+// publish records the update as last known state and sends it to the matching subscribers
+func (o *streamOLT) publish(update model.MonitoringUpdate) {
+	if update.Type != model.StreamEvent && update.Type != model.StreamONURemoved {
+		o.state[streamStateKey{update.Type, update.Board, update.PON, update.ONUID}] = update
+	}
+
+	for sub := range o.subscribers {
+		if !sub.filter.Matches(update) {
+			continue
+		}
+		select {
+		case sub.updates <- update:
+		default:
+			log.Warn().Str("olt_id", o.id).Msg("Monitoring stream subscriber fell behind, disconnecting")
+			o.unsubscribe(sub)
+		}
+	}
+}
+
+// unsubscribe removes a subscriber and closes its channel; it does nothing if the subscriber was removed before
+func (o *streamOLT) unsubscribe(sub *streamSubscriber) {
+	if !o.subscribers[sub] {
+		return
+	}
+	delete(o.subscribers, sub)
+	close(sub.updates)
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// newTestStream returns a stream with OLT "olt-1" of PON ports 1/1 to 1/3 and the config of the OLT
+func newTestStream() (*monitoringStream, *config.Config) {
+	cfg := &config.Config{BoardPonMap: map[config.BoardPonKey]*config.BoardPonConfig{}}
+	for pon := 1; pon <= 3; pon++ {
+		cfg.BoardPonMap[config.BoardPonKey{BoardID: 1, PonID: pon}] = &config.BoardPonConfig{}
+	}
+
+	s := NewMonitoringStreamUsecase().(*monitoringStream)
+	s.RegisterOLT("olt-1", cfg)
+	return s, cfg
+}
+
+// streamPON returns the monitoring data of a PON port on board 1 with ONUs of the given online states
+func streamPON(pon int, statuses ...int) *model.PONMonitoringInfo {
+	info := &model.PONMonitoringInfo{Board: 1, PonPort: strconv.Itoa(pon), OnuCount: len(statuses), Statistics: &model.PONStatistics{RxBytes: 1000}}
+	for i, status := range statuses {
+		info.ONUs = append(info.ONUs, model.ONUMonitoringInfo{
+			Board:        1,
+			PonPort:      strconv.Itoa(pon),
+			OnuID:        i + 1,
+			SerialNumber: "ZTEG0000000" + strconv.Itoa(i+1),
+			OnlineStatus: status,
+			Optical:      &model.OpticalInfo{RxPower: -20.5},
+		})
+		if status == 1 {
+			info.OnlineCount++
+		} else {
+			info.OfflineCount++
+		}
+	}
+	return info
+}
+
+// receive returns the next n updates of a subscription
+func receive(t *testing.T, updates <-chan model.MonitoringUpdate, n int) []model.MonitoringUpdate {
+	t.Helper()
+	var received []model.MonitoringUpdate
+	for len(received) < n {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				t.Fatalf("subscription closed after %d of %d updates", len(received), n)
+			}
+			received = append(received, update)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of %d updates", len(received), n)
+		}
+	}
+	return received
+}
+
+// publishTestPON publishes the polled state of a PON port of OLT "olt-1" like the collector
+func (s *monitoringStream) publishTestPON(info *model.PONMonitoringInfo) {
+	pon, _ := strconv.Atoi(info.PonPort)
+	s.PublishPON("olt-1", config.BoardPonKey{BoardID: info.Board, PonID: pon}, info, time.Now())
+}
+
+func TestMonitoringStream_Subscribe_Validation(t *testing.T) {
+	s, _ := newTestStream()
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		oltID   string
+		filter  model.MonitoringStreamFilter
+		errType apperrors.ErrorType
+	}{
+		{"pon without board", "olt-1", model.MonitoringStreamFilter{PON: 1}, apperrors.ErrorTypeValidation},
+		{"onu without pon", "olt-1", model.MonitoringStreamFilter{Board: 1, ONUID: 3}, apperrors.ErrorTypeValidation},
+		{"unknown OLT", "olt-9", model.MonitoringStreamFilter{}, apperrors.ErrorTypeNotFound},
+		{"unknown board", "olt-1", model.MonitoringStreamFilter{Board: 2}, apperrors.ErrorTypeNotFound},
+		{"unknown PON", "olt-1", model.MonitoringStreamFilter{Board: 1, PON: 9}, apperrors.ErrorTypeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := s.Subscribe(ctx, tt.oltID, tt.filter)
+			var appErr *apperrors.AppError
+			if !errors.As(err, &appErr) || appErr.Type != tt.errType {
+				t.Errorf("Subscribe() error = %v, want %s", err, tt.errType)
+			}
+		})
+	}
+}
+
+func TestMonitoringStream_Changes(t *testing.T) {
+	s, _ := newTestStream()
+	ctx := context.Background()
+
+	s.publishTestPON(streamPON(1, 1, 1))
+
+	// New subscribers start with the last known state of their filter
+	snapshot, updates, err := s.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1, PON: 1, ONUID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot) != 2 || snapshot[0].Type != model.StreamONUOptical || snapshot[1].Type != model.StreamONUStatus {
+		t.Fatalf("snapshot = %+v, want optical reading and status of ONU 2", snapshot)
+	}
+
+	// Only the ONU that went offline is reported as status change
+	s.publishTestPON(streamPON(1, 1, 0))
+	changed := receive(t, updates, 2)
+	status := changed[0]
+	if status.Type != model.StreamONUStatus || status.Status.Online || status.Status.WasOnline == nil || !*status.Status.WasOnline {
+		t.Errorf("status = %+v, want online -> offline", status)
+	}
+	if status.Status.SerialNumber != "ZTEG00000002" || status.Source != model.StreamSourcePoll {
+		t.Errorf("status = %+v", status.Status)
+	}
+	if changed[1].Type != model.StreamONUOptical {
+		t.Errorf("update = %+v, want optical reading", changed[1])
+	}
+
+	// Traps are streamed as events and update the status, which the next poll confirms without a change
+	s.PublishEvent(model.OLTEvent{OLTID: "olt-1", Type: model.EventOnuOnline, Board: 1, PON: 1, ONUID: 2, Status: "Online", ReceivedAt: time.Now()})
+	s.PublishEvent(model.OLTEvent{OLTID: "olt-2", Type: model.EventOnuOffline, Board: 1, PON: 1, ONUID: 2})
+	trapped := receive(t, updates, 2)
+	if trapped[0].Type != model.StreamEvent || trapped[0].Event.Type != model.EventOnuOnline {
+		t.Errorf("update = %+v, want trap event", trapped[0])
+	}
+	if trapped[1].Type != model.StreamONUStatus || !trapped[1].Status.Online || trapped[1].Source != model.StreamSourceTrap || trapped[1].Status.Reason != "Online" {
+		t.Errorf("update = %+v, want status from trap", trapped[1])
+	}
+
+	s.publishTestPON(streamPON(1, 1, 1))
+	if update := receive(t, updates, 1)[0]; update.Type != model.StreamONUOptical {
+		t.Errorf("update = %+v, want only the optical reading", update)
+	}
+}
+
+func TestMonitoringStream_SlowSubscriber(t *testing.T) {
+	s, _ := newTestStream()
+
+	_, updates, err := s.Subscribe(context.Background(), "olt-1", model.MonitoringStreamFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < streamBuffer; i++ {
+		s.publishTestPON(streamPON(1, 1))
+	}
+
+	count := 0
+	for range updates {
+		count++
+	}
+	if count != streamBuffer {
+		t.Errorf("received %d updates before the disconnect, want %d", count, streamBuffer)
+	}
+}
+
+func TestMonitoringStream_RemovedONU(t *testing.T) {
+	s, _ := newTestStream()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s.publishTestPON(streamPON(1, 1, 1))
+	s.publishTestPON(streamPON(2, 1))
+	_, updates, err := s.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ONU 1/1:2 was deleted: its removal is streamed before the state of the remaining ONU
+	s.publishTestPON(streamPON(1, 1))
+	received := receive(t, updates, 3)
+	if removed := received[0]; removed.Type != model.StreamONURemoved || removed.PON != 1 || removed.ONUID != 2 || removed.Source != model.StreamSourcePoll {
+		t.Errorf("first update = %+v, want removal of ONU 1/1:2", removed)
+	}
+	if received[1].Type != model.StreamONUOptical || received[2].Type != model.StreamPONCounters {
+		t.Errorf("updates = %+v, want optical reading and counters", received[1:])
+	}
+
+	// Its state is dropped; the ONU of the other PON port is kept
+	snapshot, _, _ := s.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1})
+	onus := make(map[string]bool)
+	for _, update := range snapshot {
+		if update.ONUID != 0 {
+			onus[strconv.Itoa(update.PON)+":"+strconv.Itoa(update.ONUID)] = true
+		}
+	}
+	if len(onus) != 2 || !onus["1:1"] || !onus["2:1"] {
+		t.Errorf("snapshot has ONUs %v, want 1:1 and 2:1", onus)
+	}
+
+	// A returning ONU is reported as new
+	s.publishTestPON(streamPON(1, 1, 0))
+	if status := receive(t, updates, 1)[0]; status.Type != model.StreamONUStatus || status.ONUID != 2 || status.Status.WasOnline != nil {
+		t.Errorf("update = %+v, want initial status of ONU 1/1:2", status)
+	}
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+// newSimulatedMonitoring returns a MonitoringUsecase connected to an SNMP simulator running the given firmware
+func newSimulatedMonitoring(t *testing.T, version config.FirmwareVersion) (*MonitoringUsecase, *simulator.SNMPAgent) {
+	t.Helper()
+
+	agent, cfg := newSimulatedSNMP(t, version)
+	snmpRepo := repository.NewSnmpRepository(cfg.SnmpCfg)
+	return NewMonitoringUsecase(snmpRepo, cfg, repository.NewOnuRepository(snmpRepo, cfg), nil), agent
+}
+
+// monitoringOID returns an OID of the V2.1 ONU and PON tables read by MonitoringUsecase for a board/PON
+func monitoringOID(column string, boardID, ponID, onuID int) string {
+	ponIndex, _, _ := config.OIDProfiles[config.FirmwareV21].PonIndex(boardID, ponID)
+	return fmt.Sprintf(".1.3.6.1.4.1.3902.1012.%s.%d.%d", column, ponIndex, onuID)
+}
+
+func TestMonitoringUsecase_GetONUMonitoring(t *testing.T) {
+	uc, agent := newSimulatedMonitoring(t, config.FirmwareV21)
+	records := agent.Records()
+
+	tests := []struct {
+		name     string
+		boardID  int
+		serial   string
+		model    string
+		firmware string
+	}{
+		{"board 1", 1, "ZTEGC8F10001", "F660V6.0", "V6.0.10P2N12"},
+		{"board 2", 2, "ZTEGDA5918AC", "F660V6.0", "V6.0.10P2N12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			packets, _ := records.Get(monitoringOID("3.31.4.1.3", tt.boardID, 1, 1))
+			bytes, _ := records.Get(monitoringOID("3.31.4.1.6", tt.boardID, 1, 1))
+
+			info, err := uc.GetONUMonitoring(context.Background(), tt.boardID, 1, 1)
+			if err != nil {
+				t.Fatalf("GetONUMonitoring() error = %v", err)
+			}
+			if info.Board != tt.boardID || info.SerialNumber != tt.serial || info.Model != tt.model || info.FirmwareVer != tt.firmware {
+				t.Errorf("GetONUMonitoring() = board %d, serial %q, model %q, firmware %q, want %d, %q, %q, %q",
+					info.Board, info.SerialNumber, info.Model, info.FirmwareVer, tt.boardID, tt.serial, tt.model, tt.firmware)
+			}
+			if info.OnlineStatus != 1 {
+				t.Errorf("OnlineStatus = %d, want 1", info.OnlineStatus)
+			}
+			if info.Statistics == nil || info.Statistics.RxPackets != packets.Value.(uint64) || info.Statistics.RxBytes != bytes.Value.(uint64) {
+				t.Errorf("Statistics = %+v, want %v packets and %v bytes", info.Statistics, packets.Value, bytes.Value)
+			}
+			if info.Optical != nil {
+				t.Errorf("Optical = %+v without Telnet, want nil", info.Optical)
+			}
+		})
+	}
+
+	if _, err := uc.GetONUMonitoring(context.Background(), 1, 17, 1); err == nil {
+		t.Error("GetONUMonitoring(PON 17) error = nil, want not found")
+	}
+	if _, err := uc.GetONUMonitoring(context.Background(), 3, 1, 1); err == nil {
+		t.Error("GetONUMonitoring(board 3) error = nil, want not found")
+	}
+}
+
+func TestMonitoringUsecase_GetONUMonitoringV22(t *testing.T) {
+	uc, _ := newSimulatedMonitoring(t, config.FirmwareV22)
+
+	info, err := uc.GetONUMonitoring(context.Background(), 2, 1, 2)
+	if err != nil {
+		t.Fatalf("GetONUMonitoring() error = %v", err)
+	}
+	if info.SerialNumber != "ZTEGC8F1A0B7" || info.Model != "F660V6.0" || info.OnlineStatus != 1 {
+		t.Errorf("GetONUMonitoring() = serial %q, model %q, status %d, want ZTEGC8F1A0B7, F660V6.0, 1",
+			info.SerialNumber, info.Model, info.OnlineStatus)
+	}
+	if info.FirmwareVer != "" || info.Statistics != nil {
+		t.Errorf("GetONUMonitoring() = firmware %q, statistics %+v, want none on V2.2", info.FirmwareVer, info.Statistics)
+	}
+
+	// Status 7 is offline on V2.2
+	offline, err := uc.GetONUMonitoring(context.Background(), 1, 1, 3)
+	if err != nil {
+		t.Fatalf("GetONUMonitoring() error = %v", err)
+	}
+	if offline.OnlineStatus != 0 {
+		t.Errorf("OnlineStatus = %d, want 0", offline.OnlineStatus)
+	}
+}
+
+func TestMonitoringUsecase_GetPONMonitoring(t *testing.T) {
+	uc, agent := newSimulatedMonitoring(t, config.FirmwareV21)
+	ctx := context.Background()
+
+	board2, err := uc.GetPONMonitoring(ctx, 2, 1)
+	if err != nil {
+		t.Fatalf("GetPONMonitoring(board 2) error = %v", err)
+	}
+	if board2.Board != 2 || board2.PonIndex != 268509440 || board2.OnuCount != 2 || board2.OnlineCount != 2 {
+		t.Errorf("GetPONMonitoring(board 2) = board %d, index %d, %d ONUs, %d online, want 2, 268509440, 2, 2",
+			board2.Board, board2.PonIndex, board2.OnuCount, board2.OnlineCount)
+	}
+
+	before, err := uc.GetPONMonitoring(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetPONMonitoring() error = %v", err)
+	}
+	if before.OnuCount != 3 || before.OnlineCount != 2 || before.OfflineCount != 1 || len(before.ONUs) != 3 {
+		t.Fatalf("GetPONMonitoring() = %d ONUs, %d online, %d offline, want 3, 2, 1",
+			before.OnuCount, before.OnlineCount, before.OfflineCount)
+	}
+	if before.Statistics == nil || before.Statistics.RxPackets == 0 {
+		t.Fatalf("Statistics = %+v, want PON counters", before.Statistics)
+	}
+
+	// ONU 2 goes offline while traffic keeps flowing
+	simulator.SetValue(monitoringOID("3.31.4.1.100", 1, 1, 2), gosnmp.Integer, 2)(agent.Records())
+	simulator.IncrementCounters(".1.3.6.1.4.1.3902.1012.3.31.5.1.3", 500)(agent.Records())
+
+	after, err := uc.GetPONMonitoring(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("GetPONMonitoring() error = %v", err)
+	}
+	if after.OnlineCount != 1 || after.OfflineCount != 2 {
+		t.Errorf("after ONU 2 went offline: %d online, %d offline, want 1, 2", after.OnlineCount, after.OfflineCount)
+	}
+	if after.Statistics.RxPackets != before.Statistics.RxPackets+500 {
+		t.Errorf("RxPackets = %d, want %d", after.Statistics.RxPackets, before.Statistics.RxPackets+500)
+	}
+}
+
+func TestMonitoringUsecase_GetOLTMonitoring(t *testing.T) {
+	// Both fixtures hold the same ONUs: 4 on board 1 (one offline) and 2 on board 2
+	for _, version := range []config.FirmwareVersion{config.FirmwareV21, config.FirmwareV22} {
+		t.Run(string(version), func(t *testing.T) {
+			uc, _ := newSimulatedMonitoring(t, version)
+
+			summary, err := uc.GetOLTMonitoring(context.Background())
+			if err != nil {
+				t.Fatalf("GetOLTMonitoring() error = %v", err)
+			}
+			if summary.TotalONUs != 6 || summary.OnlineONUs != 5 || summary.OfflineONUs != 1 {
+				t.Errorf("GetOLTMonitoring() = %d ONUs, %d online, %d offline, want 6, 5, 1",
+					summary.TotalONUs, summary.OnlineONUs, summary.OfflineONUs)
+			}
+			if len(summary.PONPorts) != 32 || summary.PONPorts[0].Board != 1 || summary.PONPorts[16].Board != 2 {
+				t.Errorf("GetOLTMonitoring() = %d PON ports, want 32 sorted by board", len(summary.PONPorts))
+			}
+
+			if len(summary.Boards) != 2 {
+				t.Fatalf("Boards = %+v, want 2 boards", summary.Boards)
+			}
+			board1, board2 := summary.Boards[0], summary.Boards[1]
+			if board1.Board != 1 || board1.PONPorts != 16 || board1.TotalONUs != 4 || board1.OnlineONUs != 3 || board1.OfflineONUs != 1 {
+				t.Errorf("Boards[0] = %+v, want board 1 with 4 ONUs, 3 online", board1)
+			}
+			if board2.Board != 2 || board2.PONPorts != 16 || board2.TotalONUs != 2 || board2.OnlineONUs != 2 {
+				t.Errorf("Boards[1] = %+v, want board 2 with 2 ONUs online", board2)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/jwt"
+)
+
+// TokenUsecase authenticates the bearer tokens (JWT) of an OpenID Connect provider
+type TokenUsecase interface {
+	// Authenticate returns the user of a token with the scopes of its roles, an unauthorized error
+	// for invalid tokens, or a forbidden error if the token grants no role
+	Authenticate(ctx context.Context, token string) (*model.Principal, error)
+
+	// ResolvePrincipal returns the current scopes of the roles an OIDC user's job was submitted with
+	ResolvePrincipal(ctx context.Context, owner *model.JobOwner) (*model.Principal, error)
+}
+
+type tokenUsecase struct {
+	validator  *jwt.Validator
+	userClaim  string
+	rolesClaim string
+	roleMap    map[string]string // Claim value => role; nil if the claim holds the role names
+}
+
+// tokenErrors are the validation errors that make a token invalid; other errors mean the key set is unavailable
+var tokenErrors = []error{
+	jwt.ErrMalformed, jwt.ErrAlgorithm, jwt.ErrUnknownKey, jwt.ErrSignature, jwt.ErrExpired,
+	jwt.ErrNotYetValid, jwt.ErrIssuer, jwt.ErrAudience, jwt.ErrMissingClaim,
+}
+
+// NewTokenUsecase creates the bearer token usecase for the OIDC settings, verifying tokens with keys.
+// It fails if the role mapping names unknown roles.
+func NewTokenUsecase(cfg config.OIDCConfig, keys jwt.KeyProvider) (TokenUsecase, error) {
+	roleMap, err := cfg.RoleMap()
+	if err != nil {
+		return nil, err
+	}
+	for value, role := range roleMap {
+		if _, ok := model.RoleScopes[role]; !ok {
+			return nil, fmt.Errorf("AUTH_OIDC_ROLE_MAPPING maps %q to unknown role %q (valid: %s, %s, %s)",
+				value, role, model.RoleViewer, model.RoleTechnician, model.RoleAdmin)
+		}
+	}
+
+	return &tokenUsecase{
+		validator:  jwt.NewValidator(keys, cfg.Issuer, cfg.Audience, cfg.Leeway),
+		userClaim:  cfg.UserClaim,
+		rolesClaim: cfg.RolesClaim,
+		roleMap:    roleMap,
+	}, nil
+}
+
+// Authenticate validates a token and maps its roles claim to roles and scopes
+func (u *tokenUsecase) Authenticate(ctx context.Context, token string) (*model.Principal, error) {
+	claims, err := u.validator.Validate(ctx, token)
+	if err != nil {
+		for _, tokenErr := range tokenErrors {
+			if errors.Is(err, tokenErr) {
+				log.Debug().Err(err).Msg("Bearer token rejected")
+				if tokenErr == jwt.ErrExpired {
+					return nil, apperrors.NewUnauthorizedError("bearer token has expired")
+				}
+				return nil, apperrors.NewUnauthorizedError("invalid bearer token")
+			}
+		}
+		log.Error().Err(err).Msg("Failed to verify bearer token")
+		return nil, apperrors.NewInternalError("failed to load the OIDC key set", err)
+	}
+
+	subject := claims.String("sub")
+	if subject == "" {
+		return nil, apperrors.NewUnauthorizedError("bearer token has no subject")
+	}
+	user := claims.String(u.userClaim)
+	if user == "" {
+		user = subject
+	}
+
+	roles := u.roles(claims.Strings(u.rolesClaim))
+	if len(roles) == 0 {
+		return nil, apperrors.NewForbiddenError("bearer token grants no role", map[string]interface{}{
+			"user":        user,
+			"roles_claim": u.rolesClaim,
+			"valid_roles": []string{model.RoleViewer, model.RoleTechnician, model.RoleAdmin},
+		})
+	}
+
+	return &model.Principal{
+		ID:     subject,
+		Name:   user,
+		Method: model.AuthMethodOIDC,
+		Roles:  roles,
+		Scopes: roleScopes(roles),
+	}, nil
+}
+
+// ResolvePrincipal maps the roles of the token a job was submitted with to their current scopes. The API
+// has no record of OIDC users between requests: a user removed at the provider keeps these roles for
+// the jobs submitted before, until they are canceled.
+func (u *tokenUsecase) ResolvePrincipal(_ context.Context, owner *model.JobOwner) (*model.Principal, error) {
+	var roles []string
+	for _, role := range owner.Roles {
+		if _, ok := model.RoleScopes[role]; ok {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return nil, apperrors.NewForbiddenError("the roles of the job's user grant no scope", map[string]interface{}{
+			"user": owner.Identity(),
+		})
+	}
+
+	return &model.Principal{
+		ID:     owner.ID,
+		Name:   owner.Name,
+		Method: model.AuthMethodOIDC,
+		Roles:  roles,
+		Scopes: roleScopes(roles),
+	}, nil
+}
+
+// roles maps the values of the roles claim to roles; unknown values are ignored
+func (u *tokenUsecase) roles(values []string) []string {
+	var roles []string
+	for _, value := range values {
+		role := value
+		if u.roleMap != nil {
+			role = u.roleMap[value]
+		}
+		if _, ok := model.RoleScopes[role]; ok && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// roleScopes returns the scopes granted by any of the roles, in the order of model.Scopes
+func roleScopes(roles []string) []string {
+	var scopes []string
+	for _, scope := range model.Scopes {
+		for _, role := range roles {
+			if slices.Contains(model.RoleScopes[role], scope) {
+				scopes = append(scopes, scope)
+				break
+			}
+		}
+	}
+	return scopes
+}
+
+// Authenticator resolves the caller of an API key or bearer token, and the callers jobs run as
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*model.Principal, error)
+	PrincipalResolver
+}
+
+type authenticator struct {
+	apiKeys APIKeyUsecase
+	tokens  TokenUsecase
+}
+
+// NewAuthenticator returns an authenticator for API keys and, unless tokens is nil, OIDC bearer tokens.
+// Credentials shaped like a JWT go to tokens, all others to apiKeys.
+func NewAuthenticator(apiKeys APIKeyUsecase, tokens TokenUsecase) Authenticator {
+	return &authenticator{apiKeys: apiKeys, tokens: tokens}
+}
+
+// Authenticate dispatches the credential by its shape
+func (a *authenticator) Authenticate(ctx context.Context, credential string) (*model.Principal, error) {
+	if a.tokens != nil && jwt.IsToken(credential) {
+		return a.tokens.Authenticate(ctx, credential)
+	}
+	return a.apiKeys.Authenticate(ctx, credential)
+}
+
+// ResolvePrincipal dispatches the owner of a job by its authentication method
+func (a *authenticator) ResolvePrincipal(ctx context.Context, owner *model.JobOwner) (*model.Principal, error) {
+	if owner.Method != model.AuthMethodOIDC {
+		return a.apiKeys.ResolvePrincipal(ctx, owner)
+	}
+	if a.tokens == nil {
+		return nil, apperrors.NewUnauthorizedError("bearer tokens are no longer accepted")
+	}
+	return a.tokens.ResolvePrincipal(ctx, owner)
+}
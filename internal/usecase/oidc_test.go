@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/jwt"
+)
+
+const (
+	testIssuer   = "https://sso.example.net/realms/noc"
+	testAudience = "go-api-c320"
+)
+
+// newTestTokenUsecase returns a token usecase trusting a generated EC key, and a function signing claims with it
+func newTestTokenUsecase(t *testing.T, cfg config.OIDCConfig) (TokenUsecase, func(claims map[string]interface{}) string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Issuer, cfg.Audience = testIssuer, testAudience
+	u, err := NewTokenUsecase(cfg, &jwt.KeySet{Keys: []jwt.Key{{ID: "noc-1", Public: &key.PublicKey}}})
+	if err != nil {
+		t.Fatalf("NewTokenUsecase() error = %v", err)
+	}
+
+	sign := func(claims map[string]interface{}) string {
+		c := map[string]interface{}{
+			"iss": testIssuer,
+			"aud": testAudience,
+			"sub": "2b7c",
+			"exp": time.Now().Add(5 * time.Minute).Unix(),
+		}
+		for name, value := range claims {
+			c[name] = value
+		}
+		token, err := jwt.Sign("ES256", "noc-1", key, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	return u, sign
+}
+
+func TestTokenUsecase_RoleMapping(t *testing.T) {
+	u, sign := newTestTokenUsecase(t, config.OIDCConfig{
+		UserClaim:   "preferred_username",
+		RolesClaim:  "realm_access.roles",
+		RoleMapping: "noc-viewers=viewer,noc-field=technician,noc-admins=admin",
+	})
+
+	tests := []struct {
+		name       string
+		groups     []string
+		wantRoles  []string
+		wantScope  string
+		denyScope  string
+		wantStatus apperrors.ErrorType
+	}{
+		{name: "viewer", groups: []string{"offline_access", "noc-viewers"}, wantRoles: []string{model.RoleViewer}, wantScope: model.ScopeReadONU, denyScope: model.ScopeWriteONU},
+		{name: "technician", groups: []string{"noc-field"}, wantRoles: []string{model.RoleTechnician}, wantScope: model.ScopeWriteVLAN, denyScope: model.ScopeAdminConfig},
+		{name: "admin", groups: []string{"noc-admins", "noc-viewers"}, wantRoles: []string{model.RoleAdmin, model.RoleViewer}, wantScope: model.ScopeAdminKeys},
+		{name: "role name without mapping", groups: []string{"admin"}, wantStatus: apperrors.ErrorTypeForbidden},
+		{name: "no roles", groups: nil, wantStatus: apperrors.ErrorTypeForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := sign(map[string]interface{}{
+				"preferred_username": "alice",
+				"realm_access":       map[string]interface{}{"roles": tt.groups},
+			})
+			principal, err := u.Authenticate(context.Background(), token)
+
+			if tt.wantStatus != "" {
+				var appErr *apperrors.AppError
+				if !errors.As(err, &appErr) || appErr.Type != tt.wantStatus {
+					t.Errorf("Authenticate() error = %v, want %s", err, tt.wantStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.ID != "2b7c" || principal.Name != "alice" || principal.Method != model.AuthMethodOIDC || principal.Identity() != "alice" {
+				t.Errorf("Authenticate() = %+v, want OIDC user alice", principal)
+			}
+			if strings.Join(principal.Roles, ",") != strings.Join(tt.wantRoles, ",") {
+				t.Errorf("Roles = %v, want %v", principal.Roles, tt.wantRoles)
+			}
+			if !principal.HasScope(tt.wantScope) || (tt.denyScope != "" && principal.HasScope(tt.denyScope)) {
+				t.Errorf("Scopes = %v, want %s without %s", principal.Scopes, tt.wantScope, tt.denyScope)
+			}
+		})
+	}
+}
+
+func TestTokenUsecase_RoleNamesAndUserFallback(t *testing.T) {
+	u, sign := newTestTokenUsecase(t, config.OIDCConfig{UserClaim: "preferred_username", RolesClaim: "roles"})
+
+	principal, err := u.Authenticate(context.Background(), sign(map[string]interface{}{"roles": []string{"technician", "unknown"}}))
+	if err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+	if principal.Name != "2b7c" || len(principal.Roles) != 1 || !principal.HasScope(model.ScopeBatch) {
+		t.Errorf("Authenticate() = %+v, want technician named after sub", principal)
+	}
+}
+
+func TestTokenUsecase_InvalidTokens(t *testing.T) {
+	u, sign := newTestTokenUsecase(t, config.OIDCConfig{RolesClaim: "roles"})
+	ctx := context.Background()
+
+	expired := sign(map[string]interface{}{"roles": []string{"viewer"}, "exp": time.Now().Add(-time.Hour).Unix()})
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, expired)), "expired")
+
+	otherAudience := sign(map[string]interface{}{"roles": []string{"viewer"}, "aud": "grafana"})
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, otherAudience)), "invalid bearer token")
+
+	noSubject := sign(map[string]interface{}{"roles": []string{"viewer"}, "sub": ""})
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, noSubject)), "no subject")
+
+	assertUnauthorized(t, errorOf(u.Authenticate(ctx, "eyJhbGciOiJub25lIn0.e30.")), "invalid bearer token")
+}
+
+func TestNewTokenUsecase_UnknownRole(t *testing.T) {
+	_, err := NewTokenUsecase(config.OIDCConfig{RoleMapping: "noc-admins=superuser"}, &jwt.KeySet{})
+	if err == nil || !strings.Contains(err.Error(), "superuser") {
+		t.Errorf("NewTokenUsecase() error = %v, want unknown role error", err)
+	}
+}
+
+func TestAuthenticator_Dispatch(t *testing.T) {
+	apiKeys, _ := newTestAPIKeyUsecase()
+	tokens, sign := newTestTokenUsecase(t, config.OIDCConfig{RolesClaim: "roles"})
+	ctx := context.Background()
+
+	auth := NewAuthenticator(apiKeys, tokens)
+	if principal, err := auth.Authenticate(ctx, testAdminKey); err != nil || principal.Method != model.AuthMethodAdmin {
+		t.Errorf("Authenticate(admin key) = %+v, %v", principal, err)
+	}
+	if principal, err := auth.Authenticate(ctx, sign(map[string]interface{}{"roles": []string{"viewer"}})); err != nil || principal.Method != model.AuthMethodOIDC {
+		t.Errorf("Authenticate(token) = %+v, %v", principal, err)
+	}
+
+	// Without OIDC, tokens are checked as API keys and rejected
+	withoutOIDC := NewAuthenticator(apiKeys, nil)
+	assertUnauthorized(t, errorOf(withoutOIDC.Authenticate(ctx, sign(nil))), "invalid API key")
+
+	// The users of jobs are resolved by their roles, and rejected once OIDC is turned off
+	user := &model.JobOwner{Method: model.AuthMethodOIDC, ID: "u1", Name: "alice", Roles: []string{"viewer"}}
+	if principal, err := auth.ResolvePrincipal(ctx, user); err != nil || principal.Identity() != "alice" || !principal.HasScope(model.ScopeReadONU) {
+		t.Errorf("ResolvePrincipal(user) = %+v, %v", principal, err)
+	}
+	if principal, err := auth.ResolvePrincipal(ctx, &model.JobOwner{Method: model.AuthMethodAdmin, ID: adminPrincipalID}); err != nil || principal.Method != model.AuthMethodAdmin {
+		t.Errorf("ResolvePrincipal(admin) = %+v, %v", principal, err)
+	}
+	var appErr *apperrors.AppError
+	if _, err := auth.ResolvePrincipal(ctx, &model.JobOwner{Method: model.AuthMethodOIDC, ID: "u2", Roles: []string{"removed"}}); !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeForbidden {
+		t.Errorf("ResolvePrincipal(unknown role) error = %v, want forbidden", err)
+	}
+	assertUnauthorized(t, errorOf(withoutOIDC.ResolvePrincipal(ctx, user)), "no longer accepted")
+}
@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+// OLTUsecaseInterface defines the interface for OLT registry operations
+type OLTUsecaseInterface interface {
+	ListOLTs(ctx context.Context) []model.OLTInfo
+	GetOLT(ctx context.Context, oltID string) (*model.OLTInfo, error)
+}
+
+// oltUsecase implements OLTUsecaseInterface
+type oltUsecase struct {
+	registry *repository.OLTRegistry
+}
+
+// NewOLTUsecase creates a new OLT usecase instance
+func NewOLTUsecase(registry *repository.OLTRegistry) OLTUsecaseInterface {
+	return &oltUsecase{registry: registry}
+}
+
+// ListOLTs returns all registered OLTs
+func (u *oltUsecase) ListOLTs(ctx context.Context) []model.OLTInfo {
+	defaultID := u.registry.DefaultID()
+	conns := u.registry.List()
+
+	result := make([]model.OLTInfo, 0, len(conns))
+	for _, conn := range conns {
+		result = append(result, toOLTInfo(conn, defaultID, false))
+	}
+	return result
+}
+
+// GetOLT returns a single OLT including its Telnet session status
+func (u *oltUsecase) GetOLT(ctx context.Context, oltID string) (*model.OLTInfo, error) {
+	conn, ok := u.registry.Get(oltID)
+	if !ok {
+		return nil, apperrors.NewNotFoundError("OLT", oltID)
+	}
+
+	info := toOLTInfo(conn, u.registry.DefaultID(), true)
+	return &info, nil
+}
+
+// toOLTInfo converts a registry entry into its API representation (credentials are never exposed)
+func toOLTInfo(conn *repository.OLTConnection, defaultID string, withStatus bool) model.OLTInfo {
+	info := model.OLTInfo{
+		ID:         conn.Device.ID,
+		Name:       conn.Device.Name,
+		Firmware:   string(conn.Device.Firmware),
+		SnmpHost:   conn.Device.Snmp.Host,
+		SnmpPort:   conn.Device.Snmp.Port,
+		TelnetHost: conn.TelnetConfig.Host,
+		TelnetPort: conn.TelnetConfig.Port,
+		Default:    conn.Device.ID == defaultID,
+	}
+
+	if conn.Config != nil {
+		if firmware, _ := conn.Config.FirmwareProfile(); firmware != "" {
+			info.Firmware = string(firmware) // Switched when the OLT firmware changes
+		}
+	}
+
+	if withStatus && conn.TelnetSessionManager != nil {
+		info.Telnet = conn.TelnetSessionManager.GetConnectionStatus()
+	}
+
+	return info
+}
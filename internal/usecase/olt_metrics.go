@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/metrics"
+)
+
+// OLTMonitoringSource reads the monitoring data of a PON port (implemented by MonitoringUsecase)
+type OLTMonitoringSource interface {
+	GetPONMonitoring(ctx context.Context, boardID, ponID int) (*model.PONMonitoringInfo, error)
+}
+
+// OLTMetricsCollector collects the ONU, PON and card state of one OLT, exports it on /metrics and feeds
+// every PON port it reads to the monitoring stream, so both are served by one walk of the OLT.
+// Collecting walks every ONU (SNMP and Telnet) and takes minutes on a full OLT, so it runs in the
+// background every METRICS_COLLECT_INTERVAL, or every STREAM_POLL_INTERVAL while stream clients are
+// subscribed, and scrapes are served from the last complete snapshot.
+type OLTMetricsCollector struct {
+	oltID          string
+	cfg            *config.Config
+	interval       time.Duration // Pause between collections for /metrics; 0 disables the metrics
+	streamInterval time.Duration // Pause between collections while the stream has subscribers; 0 streams only traps
+	monitoring     OLTMonitoringSource
+	cards          CardUseCaseInterface
+	stream         MonitoringStreamUsecase // Receives every PON port read; nil if the OLT is not streamed
+	now            func() time.Time
+
+	mu       sync.RWMutex
+	snapshot []metrics.Family // OLT state of the last collection
+	success  bool             // Last collection succeeded
+	duration time.Duration    // Duration of the last collection
+	lastRun  time.Time        // End of the last collection
+}
+
+// NewOLTMetricsCollector creates the collector of an OLT and registers it with the default metrics registry.
+// Nothing is exported for the OLT if the metrics are disabled (METRICS_COLLECT_INTERVAL 0).
+func NewOLTMetricsCollector(oltID string, cfg *config.Config, monitoring OLTMonitoringSource, cards CardUseCaseInterface, stream MonitoringStreamUsecase) *OLTMetricsCollector {
+	c := &OLTMetricsCollector{
+		oltID:          oltID,
+		cfg:            cfg,
+		interval:       cfg.Metrics.CollectInterval,
+		streamInterval: cfg.Stream.PollInterval,
+		monitoring:     monitoring,
+		cards:          cards,
+		stream:         stream,
+		now:            time.Now,
+	}
+	metrics.Default.Register(c)
+	return c
+}
+
+// Run collects the OLT state until ctx is canceled: right away and then every interval if the metrics
+// are enabled, and every stream interval while the stream has subscribers. It returns immediately if
+// both are disabled.
+func (c *OLTMetricsCollector) Run(ctx context.Context) {
+	if c.interval <= 0 && (c.stream == nil || c.streamInterval <= 0) {
+		log.Info().Str("olt_id", c.oltID).Msg("OLT state collection disabled")
+		return
+	}
+	if c.interval <= 0 {
+		log.Info().Str("olt_id", c.oltID).Msg("OLT metrics collection disabled, collecting for stream clients only")
+	}
+
+	for {
+		// A client subscribing to the stream may shorten the pause, which is measured from the last collection
+		pause, ok, subscribed := c.pause()
+		var timer *time.Timer
+		var due <-chan time.Time
+		if ok {
+			c.mu.RLock()
+			lastRun := c.lastRun
+			c.mu.RUnlock()
+			wait := pause - c.now().Sub(lastRun)
+			if lastRun.IsZero() || wait <= 0 {
+				c.Refresh(ctx)
+				if ctx.Err() != nil {
+					return
+				}
+				continue
+			}
+			timer = time.NewTimer(wait)
+			due = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-due:
+		case <-subscribed:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// pause returns the pause after a collection: the shorter of the enabled intervals, the stream
+// interval only while the stream has subscribers. ok is false if nothing is to be collected. The
+// channel is signaled when a stream client subscribes.
+func (c *OLTMetricsCollector) pause() (pause time.Duration, ok bool, subscribed <-chan struct{}) {
+	pause, ok = c.interval, c.interval > 0
+	if c.stream == nil || c.streamInterval <= 0 {
+		return pause, ok, nil
+	}
+	watched, subscribed := c.stream.Watched(c.oltID)
+	if watched && (!ok || c.streamInterval < pause) {
+		pause, ok = c.streamInterval, true
+	}
+	return pause, ok, subscribed
+}
+
+// Refresh collects the OLT state once and replaces the snapshot.
+// The snapshot is kept if no PON port can be read, so a failed run does not blank the dashboards.
+func (c *OLTMetricsCollector) Refresh(ctx context.Context) {
+	start := c.now()
+	families, err := c.gather(ctx)
+	duration := c.now().Sub(start)
+	if err != nil && ctx.Err() == nil {
+		log.Warn().Err(err).Str("olt_id", c.oltID).Msg("Failed to collect OLT metrics")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.success = err == nil
+	c.duration = duration
+	c.lastRun = c.now()
+	if err == nil {
+		c.snapshot = families
+	}
+}
+
+// Collect implements metrics.Collector
+func (c *OLTMetricsCollector) Collect() []metrics.Family {
+	if c.interval <= 0 {
+		return nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	olt := metrics.Labels{"olt": c.oltID}
+	success := 0.0
+	if c.success {
+		success = 1
+	}
+
+	families := append([]metrics.Family(nil), c.snapshot...)
+	families = append(families,
+		gauge("c320_olt_collect_success", "Whether the last collection of the OLT state succeeded", metrics.Sample{Labels: olt, Value: success}),
+		gauge("c320_olt_collect_duration_seconds", "Duration of the last collection of the OLT state", metrics.Sample{Labels: olt, Value: c.duration.Seconds()}),
+	)
+	if !c.lastRun.IsZero() {
+		families = append(families, gauge("c320_olt_collect_timestamp_seconds", "Unix time of the last collection of the OLT state",
+			metrics.Sample{Labels: olt, Value: float64(c.lastRun.Unix())}))
+	}
+	return families
+}
+
+// gather reads the configured PON ports one after another, streams each of them and builds the
+// metric families of the OLT state
+func (c *OLTMetricsCollector) gather(ctx context.Context) ([]metrics.Family, error) {
+	keys := c.cfg.BoardPonKeys()
+	pons := make([]model.PONMonitoringInfo, 0, len(keys))
+	for _, key := range keys {
+		info, err := c.monitoring.GetPONMonitoring(ctx, key.BoardID, key.PonID)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("olt_id", c.oltID).Int("board", key.BoardID).Int("pon", key.PonID).Msg("Failed to get PON monitoring")
+			continue
+		}
+		if c.stream != nil {
+			c.stream.PublishPON(c.oltID, key, info, c.now())
+		}
+		pons = append(pons, *info)
+	}
+	if len(keys) > 0 && len(pons) == 0 {
+		return nil, fmt.Errorf("none of the %d PON ports could be read", len(keys))
+	}
+
+	ponONUs := newFamily("c320_pon_onus", "Number of ONUs on a PON port by state", metrics.TypeGauge)
+	ponRxBytes := newFamily("c320_pon_rx_bytes_total", "Bytes received on a PON port", metrics.TypeCounter)
+	ponRxPackets := newFamily("c320_pon_rx_packets_total", "Packets received on a PON port", metrics.TypeCounter)
+	onuOnline := newFamily("c320_onu_online", "Whether the ONU is online", metrics.TypeGauge)
+	onuRxBytes := newFamily("c320_onu_rx_bytes_total", "Bytes received from the ONU", metrics.TypeCounter)
+	onuRxPackets := newFamily("c320_onu_rx_packets_total", "Packets received from the ONU", metrics.TypeCounter)
+	onuRxPower := newFamily("c320_onu_rx_power_dbm", "Optical power received by the ONU", metrics.TypeGauge)
+	onuTxPower := newFamily("c320_onu_tx_power_dbm", "Optical power transmitted by the ONU", metrics.TypeGauge)
+	oltRxPower := newFamily("c320_onu_olt_rx_power_dbm", "Optical power of the ONU received by the OLT", metrics.TypeGauge)
+	onuTemperature := newFamily("c320_onu_temperature_celsius", "Temperature of the ONU optical module", metrics.TypeGauge)
+	onuVoltage := newFamily("c320_onu_voltage_volts", "Supply voltage of the ONU optical module", metrics.TypeGauge)
+	onuBias := newFamily("c320_onu_bias_current_milliamperes", "Laser bias current of the ONU optical module", metrics.TypeGauge)
+
+	for _, pon := range pons {
+		board := strconv.Itoa(pon.Board)
+		ponLabels := metrics.Labels{"olt": c.oltID, "board": board, "pon": pon.PonPort}
+		ponONUs.Samples = append(ponONUs.Samples,
+			metrics.Sample{Labels: withState(ponLabels, "online"), Value: float64(pon.OnlineCount)},
+			metrics.Sample{Labels: withState(ponLabels, "offline"), Value: float64(pon.OfflineCount)},
+		)
+		if pon.Statistics != nil {
+			ponRxBytes.Samples = append(ponRxBytes.Samples, metrics.Sample{Labels: ponLabels, Value: float64(pon.Statistics.RxBytes)})
+			ponRxPackets.Samples = append(ponRxPackets.Samples, metrics.Sample{Labels: ponLabels, Value: float64(pon.Statistics.RxPackets)})
+		}
+
+		// ONUs are identified by their location only: serial numbers are customer data and
+		// would add a series per replaced ONU
+		for _, onu := range pon.ONUs {
+			onuLabels := metrics.Labels{
+				"olt":    c.oltID,
+				"board":  board,
+				"pon":    pon.PonPort,
+				"onu_id": strconv.Itoa(onu.OnuID),
+			}
+			online := 0.0
+			if onu.OnlineStatus == 1 {
+				online = 1
+			}
+			onuOnline.Samples = append(onuOnline.Samples, metrics.Sample{Labels: onuLabels, Value: online})
+
+			if onu.Statistics != nil {
+				onuRxBytes.Samples = append(onuRxBytes.Samples, metrics.Sample{Labels: onuLabels, Value: float64(onu.Statistics.RxBytes)})
+				onuRxPackets.Samples = append(onuRxPackets.Samples, metrics.Sample{Labels: onuLabels, Value: float64(onu.Statistics.RxPackets)})
+			}
+			if optical := onu.Optical; optical != nil {
+				onuRxPower.Samples = append(onuRxPower.Samples, metrics.Sample{Labels: onuLabels, Value: optical.RxPower})
+				onuTxPower.Samples = append(onuTxPower.Samples, metrics.Sample{Labels: onuLabels, Value: optical.TxPower})
+				oltRxPower.Samples = append(oltRxPower.Samples, metrics.Sample{Labels: onuLabels, Value: optical.OLTRxPower})
+				onuTemperature.Samples = append(onuTemperature.Samples, metrics.Sample{Labels: onuLabels, Value: optical.Temperature})
+				onuVoltage.Samples = append(onuVoltage.Samples, metrics.Sample{Labels: onuLabels, Value: optical.Voltage})
+				onuBias.Samples = append(onuBias.Samples, metrics.Sample{Labels: onuLabels, Value: optical.BiasCurrent})
+			}
+		}
+	}
+
+	families := []metrics.Family{
+		ponONUs, ponRxBytes, ponRxPackets,
+		onuOnline, onuRxBytes, onuRxPackets,
+		onuRxPower, onuTxPower, oltRxPower, onuTemperature, onuVoltage, onuBias,
+	}
+
+	// Card state is optional: a failed card walk only drops the card metrics
+	cards, err := c.cards.GetAllCards(ctx)
+	if err != nil {
+		log.Warn().Err(err).Str("olt_id", c.oltID).Msg("Failed to collect card metrics")
+		return families, nil
+	}
+	cardUp := newFamily("c320_card_up", "Whether the card is operational (status active or online)", metrics.TypeGauge)
+	for _, card := range cards {
+		up := 0.0
+		if card.Status == "active" || card.Status == "online" {
+			up = 1
+		}
+		cardUp.Samples = append(cardUp.Samples, metrics.Sample{Labels: metrics.Labels{
+			"olt":    c.oltID,
+			"rack":   strconv.Itoa(card.Rack),
+			"shelf":  strconv.Itoa(card.Shelf),
+			"slot":   strconv.Itoa(card.Slot),
+			"type":   card.CardType,
+			"status": card.Status,
+		}, Value: up})
+	}
+
+	return append(families, cardUp), nil
+}
+
+// newFamily creates an empty metric family
+func newFamily(name, help, metricType string) metrics.Family {
+	return metrics.Family{Name: name, Help: help, Type: metricType}
+}
+
+// gauge creates a gauge family with the given samples
+func gauge(name, help string, samples ...metrics.Sample) metrics.Family {
+	return metrics.Family{Name: name, Help: help, Type: metrics.TypeGauge, Samples: samples}
+}
+
+// withState returns a copy of labels with the state label set
+func withState(labels metrics.Labels, state string) metrics.Labels {
+	result := metrics.Labels{"state": state}
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,266 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/pkg/metrics"
+)
+
+// fakePONSource returns fixed PON monitoring data and counts the reads per PON port
+type fakePONSource struct {
+	mu    sync.Mutex
+	pons  map[config.BoardPonKey]*model.PONMonitoringInfo
+	err   error // Returned for every PON port if set
+	polls map[config.BoardPonKey]int
+}
+
+func (f *fakePONSource) GetPONMonitoring(_ context.Context, boardID, ponID int) (*model.PONMonitoringInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := config.BoardPonKey{BoardID: boardID, PonID: ponID}
+	f.polls[key]++
+	if info, ok := f.pons[key]; ok && f.err == nil {
+		return info, nil
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return nil, errors.New("PON not reachable")
+}
+
+func (f *fakePONSource) pollCount(boardID, ponID int) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.polls[config.BoardPonKey{BoardID: boardID, PonID: ponID}]
+}
+
+type fakeCardUsecase struct {
+	cards []*model.CardInfo
+	err   error
+}
+
+func (f *fakeCardUsecase) GetAllCards(context.Context) ([]*model.CardInfo, error) {
+	return f.cards, f.err
+}
+
+func (f *fakeCardUsecase) GetCard(context.Context, int, int, int) (*model.CardInfo, error) {
+	return nil, errors.New("not implemented")
+}
+
+// findSample returns the value of the sample of a family with the given labels
+func findSample(families []metrics.Family, name string, labels metrics.Labels) (float64, bool) {
+	for _, family := range families {
+		if family.Name != name {
+			continue
+		}
+	samples:
+		for _, sample := range family.Samples {
+			for k, v := range labels {
+				if sample.Labels[k] != v {
+					continue samples
+				}
+			}
+			return sample.Value, true
+		}
+	}
+	return 0, false
+}
+
+func TestOLTMetricsCollector(t *testing.T) {
+	pon := model.PONMonitoringInfo{
+		Board:        1,
+		PonPort:      "3",
+		OnlineCount:  1,
+		OfflineCount: 1,
+		Statistics:   &model.PONStatistics{RxBytes: 1000, RxPackets: 10},
+		ONUs: []model.ONUMonitoringInfo{
+			{
+				OnuID:        5,
+				SerialNumber: "ZTEGC0000001",
+				OnlineStatus: 1,
+				Statistics:   &model.ONUStatistics{RxBytes: 400},
+				Optical:      &model.OpticalInfo{RxPower: -21.5, TxPower: 2.1, Temperature: 41, Voltage: 3.3},
+			},
+			{OnuID: 6, SerialNumber: "ZTEGC0000002"},
+		},
+	}
+	source := &fakePONSource{
+		pons:  map[config.BoardPonKey]*model.PONMonitoringInfo{{BoardID: 1, PonID: 3}: &pon},
+		polls: make(map[config.BoardPonKey]int),
+	}
+	cards := &fakeCardUsecase{cards: []*model.CardInfo{
+		{Rack: 1, Shelf: 1, Slot: 3, CardType: "GTGO", Status: "online"},
+		{Rack: 1, Shelf: 1, Slot: 4, CardType: "GTGO", Status: "inactive"},
+	}}
+
+	cfg := &config.Config{
+		Metrics:     config.MetricsConfig{CollectInterval: time.Minute},
+		BoardPonMap: map[config.BoardPonKey]*config.BoardPonConfig{{BoardID: 1, PonID: 3}: {}},
+	}
+	collector := NewOLTMetricsCollector("olt-1", cfg, source, cards, nil)
+
+	collector.Refresh(context.Background())
+	families := collector.Collect()
+
+	onu := metrics.Labels{"olt": "olt-1", "board": "1", "pon": "3", "onu_id": "5"}
+	checks := []struct {
+		name   string
+		labels metrics.Labels
+		want   float64
+	}{
+		{"c320_pon_onus", metrics.Labels{"pon": "3", "state": "online"}, 1},
+		{"c320_pon_onus", metrics.Labels{"pon": "3", "state": "offline"}, 1},
+		{"c320_pon_rx_bytes_total", metrics.Labels{"pon": "3"}, 1000},
+		{"c320_onu_online", onu, 1},
+		{"c320_onu_online", metrics.Labels{"onu_id": "6"}, 0},
+		{"c320_onu_rx_bytes_total", onu, 400},
+		{"c320_onu_rx_power_dbm", onu, -21.5},
+		{"c320_onu_temperature_celsius", onu, 41},
+		{"c320_onu_voltage_volts", onu, 3.3},
+		{"c320_card_up", metrics.Labels{"slot": "3"}, 1},
+		{"c320_card_up", metrics.Labels{"slot": "4"}, 0},
+		{"c320_olt_collect_success", metrics.Labels{"olt": "olt-1"}, 1},
+	}
+	for _, check := range checks {
+		got, ok := findSample(families, check.name, check.labels)
+		if !ok {
+			t.Errorf("%s%v not exported", check.name, check.labels)
+		} else if got != check.want {
+			t.Errorf("%s%v = %v, want %v", check.name, check.labels, got, check.want)
+		}
+	}
+	for _, family := range families {
+		if family.Name == "c320_pon_onus" && len(family.Samples) != 2 {
+			t.Errorf("c320_pon_onus has %d samples, want 2", len(family.Samples))
+		}
+		for _, sample := range family.Samples {
+			if _, ok := sample.Labels["serial_number"]; ok {
+				t.Errorf("%s%v exports the ONU serial number", family.Name, sample.Labels)
+			}
+		}
+	}
+
+	// A failed collection keeps the last snapshot and reports the failure
+	source.err = errors.New("timeout")
+	collector.Refresh(context.Background())
+	families = collector.Collect()
+	if _, ok := findSample(families, "c320_onu_online", onu); !ok {
+		t.Error("snapshot dropped after a failed collection")
+	}
+	if got, _ := findSample(families, "c320_olt_collect_success", nil); got != 0 {
+		t.Errorf("c320_olt_collect_success = %v, want 0", got)
+	}
+}
+
+func TestOLTMetricsCollector_Disabled(t *testing.T) {
+	collector := NewOLTMetricsCollector("olt-1", &config.Config{}, &fakePONSource{}, &fakeCardUsecase{}, NewMonitoringStreamUsecase())
+
+	collector.Run(context.Background()) // Returns immediately
+	if families := collector.Collect(); families != nil {
+		t.Errorf("Collect() = %+v, want nothing when disabled", families)
+	}
+}
+
+func TestOLTMetricsCollector_FeedsStream(t *testing.T) {
+	stream, cfg := newTestStream()
+	cfg.Metrics.CollectInterval = time.Hour
+	cfg.Stream.PollInterval = time.Hour
+	source := &fakePONSource{
+		pons: map[config.BoardPonKey]*model.PONMonitoringInfo{
+			{BoardID: 1, PonID: 1}: streamPON(1, 1, 1),
+			{BoardID: 1, PonID: 2}: streamPON(2, 1, 0),
+		},
+		polls: make(map[config.BoardPonKey]int),
+	}
+	collector := NewOLTMetricsCollector("olt-1", cfg, source, &fakeCardUsecase{}, stream)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Two dashboards watch PON 1/1, one of them the whole board, and one ONU view PON 1/2
+	_, first, err := stream.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1, PON: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, _ := stream.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1})
+	_, onu, _ := stream.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1, PON: 2, ONUID: 1})
+
+	done := make(chan struct{})
+	go func() {
+		collector.Run(ctx)
+		close(done)
+	}()
+
+	// PON 1/1: status and optical reading of 2 ONUs and the counters
+	updates := receive(t, first, 5)
+	if updates[0].Type != model.StreamONUStatus || !updates[0].Status.Online || updates[0].Status.WasOnline != nil {
+		t.Errorf("first update = %+v, want initial online status", updates[0])
+	}
+	if updates[2].Type != model.StreamONUOptical || updates[2].Optical.RxPower != -20.5 {
+		t.Errorf("third update = %+v, want optical reading", updates[2])
+	}
+	if counters := updates[4]; counters.Type != model.StreamPONCounters || counters.Counters.OnlineCount != 2 || counters.Counters.Statistics.RxBytes != 1000 {
+		t.Errorf("last update = %+v, want PON counters", counters)
+	}
+	// The board subscriber also gets PON 1/2; the failed read of PON 1/3 is skipped
+	receive(t, second, 5+5)
+	// The ONU subscriber gets the status and optical reading of ONU 1/2:1, not the PON counters
+	for _, update := range receive(t, onu, 2) {
+		if update.PON != 2 || update.ONUID != 1 {
+			t.Errorf("ONU subscriber received %+v", update)
+		}
+	}
+
+	// The same walk serves /metrics
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, ok := findSample(collector.Collect(), "c320_onu_online", metrics.Labels{"pon": "2", "onu_id": "1"}); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("walk was not exported on /metrics")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	for pon := 1; pon <= 3; pon++ {
+		if got := source.pollCount(1, pon); got != 1 {
+			t.Errorf("PON 1/%d read %d times, want once for the stream and the metrics", pon, got)
+		}
+	}
+
+	cancel()
+	<-done
+}
+
+func TestOLTMetricsCollector_StreamOnly(t *testing.T) {
+	stream, cfg := newTestStream()
+	cfg.Stream.PollInterval = time.Hour // Metrics disabled
+	source := &fakePONSource{
+		pons:  map[config.BoardPonKey]*model.PONMonitoringInfo{{BoardID: 1, PonID: 1}: streamPON(1, 1)},
+		polls: make(map[config.BoardPonKey]int),
+	}
+	collector := NewOLTMetricsCollector("olt-1", cfg, source, &fakeCardUsecase{}, stream)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go collector.Run(ctx)
+
+	// Nothing is read until a client subscribes
+	time.Sleep(50 * time.Millisecond)
+	if got := source.pollCount(1, 1); got != 0 {
+		t.Fatalf("PON 1/1 read %d times without subscribers", got)
+	}
+	_, updates, err := stream.Subscribe(ctx, "olt-1", model.MonitoringStreamFilter{Board: 1, PON: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receive(t, updates, 3)
+	if families := collector.Collect(); families != nil {
+		t.Errorf("Collect() = %+v, want nothing with the metrics disabled", families)
+	}
+}
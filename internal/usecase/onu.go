@@ -69,7 +69,7 @@ func (u *onuUsecase) getBoardConfig(boardID, ponID int) (*model.OltConfig, error
 	}
 
 	// Determine base OID based on boardID
-	baseOID := u.cfg.OltCfg.BaseOID1 // Retrieve base OID from global config
+	baseOID := u.cfg.BaseOID() // Retrieve base OID from global config
 
 	// Build OltConfig from dynamic config
 	return &model.OltConfig{
@@ -662,7 +662,7 @@ func (u *onuUsecase) GetByBoardIDAndPonIDWithPagination(
 }
 
 func (u *onuUsecase) getName(OnuIDNameOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuIDNameOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuIDNameOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)         // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -680,7 +680,7 @@ func (u *onuUsecase) getONUType(OnuTypeOID, onuID string) (string, error) {
 }
 
 func (u *onuUsecase) getSerialNumber(OnuSerialNumberOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuSerialNumberOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuSerialNumberOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)               // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -699,7 +699,7 @@ func (u *onuUsecase) getTxPower(OnuTxPowerOID, onuID string) (string, error) {
 }
 
 func (u *onuUsecase) getRxPower(OnuRxPowerOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuRxPowerOID + "." + onuID + ".1" // Construct OID
+	oid := u.cfg.BaseOID() + OnuRxPowerOID + "." + onuID + ".1" // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)                 // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -709,7 +709,7 @@ func (u *onuUsecase) getRxPower(OnuRxPowerOID, onuID string) (string, error) {
 }
 
 func (u *onuUsecase) getStatus(OnuStatusOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuStatusOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuStatusOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)         // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -727,7 +727,7 @@ func (u *onuUsecase) getIPAddress(OnuIPAddressOID, onuID string) (string, error)
 }
 
 func (u *onuUsecase) getDescription(OnuDescriptionOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuDescriptionOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuDescriptionOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)              // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -736,7 +736,7 @@ func (u *onuUsecase) getDescription(OnuDescriptionOID, onuID string) (string, er
 }
 
 func (u *onuUsecase) getLastOnline(OnuLastOnlineOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuLastOnlineOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuLastOnlineOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)             // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -747,7 +747,7 @@ func (u *onuUsecase) getLastOnline(OnuLastOnlineOID, onuID string) (string, erro
 }
 
 func (u *onuUsecase) getLastOffline(OnuLastOfflineOID, onuID string) (string, error) {
-	baseOID := u.cfg.OltCfg.BaseOID1                 // Get base OID
+	baseOID := u.cfg.BaseOID()                 // Get base OID
 	oid := baseOID + OnuLastOfflineOID + "." + onuID // Construct full OID
 	oids := []string{oid}                            // Create slice of OIDs
 
@@ -770,7 +770,7 @@ func (u *onuUsecase) getLastOffline(OnuLastOfflineOID, onuID string) (string, er
 }
 
 func (u *onuUsecase) getLastOfflineReason(OnuLastOfflineReasonOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuLastOfflineReasonOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuLastOfflineReasonOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)                    // Fetch from SNMP
 	if err != nil {
 		return "", err
@@ -780,7 +780,7 @@ func (u *onuUsecase) getLastOfflineReason(OnuLastOfflineReasonOID, onuID string)
 }
 
 func (u *onuUsecase) getOnuGponOpticalDistance(OnuGponOpticalDistanceOID, onuID string) (string, error) {
-	oid := u.cfg.OltCfg.BaseOID1 + OnuGponOpticalDistanceOID + "." + onuID // Construct OID
+	oid := u.cfg.BaseOID() + OnuGponOpticalDistanceOID + "." + onuID // Construct OID
 	result, err := u.getFromSNMPWithSingleflight(oid)                      // Fetch from SNMP
 	if err != nil {
 		return "", err
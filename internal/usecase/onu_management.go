@@ -25,16 +25,20 @@ type ONUManagementUsecaseInterface interface {
 type ONUManagementUsecase struct {
 	telnetSessionManager *repository.TelnetSessionManager
 	config               *config.Config
+	snapshots            PreChangeSnapshotter
 }
 
-// NewONUManagementUsecase creates a new ONU management usecase
+// NewONUManagementUsecase creates a new ONU management usecase.
+// If snapshots is set, ONUs are backed up before they are deleted.
 func NewONUManagementUsecase(
 	telnetSessionManager *repository.TelnetSessionManager,
 	cfg *config.Config,
+	snapshots PreChangeSnapshotter,
 ) ONUManagementUsecaseInterface {
 	return &ONUManagementUsecase{
 		telnetSessionManager: telnetSessionManager,
 		config:               cfg,
+		snapshots:            snapshots,
 	}
 }
 
@@ -235,14 +239,26 @@ func (u *ONUManagementUsecase) DeleteONU(ctx context.Context, req *model.ONUDele
 		}, err
 	}
 
-	// Execute deletion
-	if err := u.telnetSessionManager.DeleteONU(ctx, req); err != nil {
-		log.Error().Err(err).Msg("Failed to delete ONU")
+	// Snapshot the ONU so the deletion can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, req.PONPort, req.ONUID, OperationDeleteONU)
+	if err != nil {
 		return &model.ONUDeleteResponse{
 			PONPort: req.PONPort,
 			ONUID:   req.ONUID,
 			Success: false,
-			Message: fmt.Sprintf("Failed to delete ONU: %v", err),
+			Message: err.Error(),
+		}, err
+	}
+
+	// Execute deletion
+	if err := u.telnetSessionManager.DeleteONU(ctx, req); err != nil {
+		log.Error().Err(err).Msg("Failed to delete ONU")
+		return &model.ONUDeleteResponse{
+			PONPort:  req.PONPort,
+			ONUID:    req.ONUID,
+			Success:  false,
+			Message:  fmt.Sprintf("Failed to delete ONU: %v", err),
+			Snapshot: snapshot,
 		}, err
 	}
 
@@ -252,10 +268,11 @@ func (u *ONUManagementUsecase) DeleteONU(ctx context.Context, req *model.ONUDele
 		Msg("ONU deleted successfully")
 
 	return &model.ONUDeleteResponse{
-		PONPort: req.PONPort,
-		ONUID:   req.ONUID,
-		Success: true,
-		Message: "ONU configuration deleted successfully",
+		PONPort:  req.PONPort,
+		ONUID:    req.ONUID,
+		Success:  true,
+		Message:  "ONU configuration deleted successfully",
+		Snapshot: snapshot,
 	}, nil
 }
 
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestONUManagementUsecase_Operations(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewONUManagementUsecase(manager, nil, nil)
+	ctx := context.Background()
+	state := server.State()
+
+	if resp, err := uc.RebootONU(ctx, &model.ONURebootRequest{PONPort: "1/1/1", ONUID: 1}); err != nil || !resp.Success {
+		t.Fatalf("RebootONU() = %+v, %v", resp, err)
+	}
+	if resp, err := uc.BlockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2, Block: true}); err != nil || !resp.Blocked {
+		t.Fatalf("BlockONU() = %+v, %v", resp, err)
+	}
+	if resp, err := uc.UpdateDescription(ctx, &model.ONUDescriptionRequest{PONPort: "1/1/1", ONUID: 3, Description: "Warehouse"}); err != nil || !resp.Success {
+		t.Fatalf("UpdateDescription() = %+v, %v", resp, err)
+	}
+
+	if onu, _ := state.ONU("1/1/1", 1); onu.Reboots != 1 {
+		t.Errorf("Reboots = %d, want 1", onu.Reboots)
+	}
+	if onu, _ := state.ONU("1/1/1", 2); !onu.Disabled {
+		t.Error("ONU 2 is not disabled")
+	}
+	if onu, _ := state.ONU("1/1/1", 3); onu.Name != "Warehouse" {
+		t.Errorf("Name = %q, want Warehouse", onu.Name)
+	}
+
+	if resp, err := uc.UnblockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/1", ONUID: 2}); err != nil || resp.Blocked {
+		t.Fatalf("UnblockONU() = %+v, %v", resp, err)
+	}
+	if onu, _ := state.ONU("1/1/1", 2); onu.Disabled {
+		t.Error("ONU 2 is still disabled")
+	}
+
+	if resp, err := uc.DeleteONU(ctx, &model.ONUDeleteRequest{PONPort: "1/1/1", ONUID: 3}); err != nil || !resp.Success {
+		t.Fatalf("DeleteONU() = %+v, %v", resp, err)
+	}
+	if _, ok := state.ONU("1/1/1", 3); ok {
+		t.Error("ONU 3 still exists")
+	}
+}
+
+func TestONUManagementUsecase_MissingONU(t *testing.T) {
+	_, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewONUManagementUsecase(manager, nil, nil)
+	ctx := context.Background()
+
+	if resp, err := uc.RebootONU(ctx, &model.ONURebootRequest{PONPort: "1/1/1", ONUID: 64}); err == nil || resp.Success {
+		t.Errorf("RebootONU() = %+v, %v, want an error", resp, err)
+	}
+	if resp, err := uc.BlockONU(ctx, &model.ONUBlockRequest{PONPort: "1/1/2", ONUID: 1, Block: true}); err == nil || resp.Success {
+		t.Errorf("BlockONU() = %+v, %v, want an error", resp, err)
+	}
+	if resp, err := uc.DeleteONU(ctx, &model.ONUDeleteRequest{PONPort: "1/1/1", ONUID: 64}); err == nil || resp.Success {
+		t.Errorf("DeleteONU() = %+v, %v, want an error", resp, err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/s4lfanet/go-api-c320/config"
 	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
 	"github.com/gosnmp/gosnmp"
 )
 
@@ -982,3 +983,100 @@ func TestGetLastOffline_NoVariables(t *testing.T) {
 		t.Error("Expected error for no variables")
 	}
 }
+
+func TestOnuUsecase_Simulator(t *testing.T) {
+	tests := []struct {
+		version config.FirmwareVersion
+		want    []model.ONUInfoPerBoard // Board 1 PON 1
+	}{
+		{
+			// V2.1 has no RX power column and the name column holds the device serial number
+			version: config.FirmwareV21,
+			want: []model.ONUInfoPerBoard{
+				{Board: 1, PON: 1, ID: 1, Name: "ZTEGC8F10001", OnuType: "F660V6.0", SerialNumber: "ZTEGC8F10001", Status: "Logging"},
+				{Board: 1, PON: 1, ID: 2, Name: "ZTEGC8F10002", OnuType: "F660V6.0", SerialNumber: "ZTEGC8F10002", Status: "Logging"},
+				{Board: 1, PON: 1, ID: 3, Name: "HWTC1F14CAAD", OnuType: "F609V5.3", SerialNumber: "HWTC1F14CAAD", Status: "LOS"},
+			},
+		},
+		{
+			version: config.FirmwareV22,
+			want: []model.ONUInfoPerBoard{
+				{Board: 1, PON: 1, ID: 1, Name: "customer-1", OnuType: "F660V6.0", SerialNumber: "ZTEGC8F10001", RXPower: "-21.80", Status: "Online"},
+				{Board: 1, PON: 1, ID: 2, Name: "customer-2", OnuType: "F660V6.0", SerialNumber: "ZTEGC8F10002", RXPower: "-22.70", Status: "Online"},
+				{Board: 1, PON: 1, ID: 3, Name: "customer-3", OnuType: "F609V5.3", SerialNumber: "HWTC1F14CAAD", Status: "Offline"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.version), func(t *testing.T) {
+			_, cfg := newSimulatedSNMP(t, tt.version)
+			u := NewOnuUsecase(repository.NewSnmpRepository(cfg.SnmpCfg), &mockRedisRepository{}, cfg)
+			ctx := context.Background()
+
+			onus, err := u.GetByBoardIDAndPonID(ctx, 1, 1)
+			if err != nil {
+				t.Fatalf("GetByBoardIDAndPonID() error = %v", err)
+			}
+			if len(onus) != len(tt.want) {
+				t.Fatalf("GetByBoardIDAndPonID() returned %d ONUs, want %d", len(onus), len(tt.want))
+			}
+			for i := range tt.want {
+				if onus[i] != tt.want[i] {
+					t.Errorf("GetByBoardIDAndPonID()[%d] = %+v, want %+v", i, onus[i], tt.want[i])
+				}
+			}
+
+			// Board 2 uses its own index base
+			onus, err = u.GetByBoardIDAndPonID(ctx, 2, 1)
+			if err != nil || len(onus) != 2 {
+				t.Errorf("GetByBoardIDAndPonID(2, 1) = %d ONUs, %v, want 2", len(onus), err)
+			}
+
+			serials, err := u.GetOnuIDAndSerialNumber(1, 2)
+			if err != nil {
+				t.Fatalf("GetOnuIDAndSerialNumber() error = %v", err)
+			}
+			if len(serials) != 1 || serials[0].SerialNumber != "ZTEGD824CDF3" {
+				t.Errorf("GetOnuIDAndSerialNumber(1, 2) = %+v, want ONU 1 ZTEGD824CDF3", serials)
+			}
+
+			empty, err := u.GetEmptyOnuID(ctx, 1, 1)
+			if err != nil {
+				t.Fatalf("GetEmptyOnuID() error = %v", err)
+			}
+			if len(empty) != 125 || empty[0].ID != 4 {
+				t.Errorf("GetEmptyOnuID() = %d IDs starting at %d, want 125 starting at 4", len(empty), empty[0].ID)
+			}
+		})
+	}
+}
+
+func TestGetByBoardIDPonIDAndOnuID_Simulator(t *testing.T) {
+	_, cfg := newSimulatedSNMP(t, config.FirmwareV22)
+	u := NewOnuUsecase(repository.NewSnmpRepository(cfg.SnmpCfg), &mockRedisRepository{}, cfg)
+
+	onu, err := u.GetByBoardIDPonIDAndOnuID(1, 1, 1)
+	if err != nil {
+		t.Fatalf("GetByBoardIDPonIDAndOnuID() error = %v", err)
+	}
+	want := model.ONUCustomerInfo{
+		Board: 1, PON: 1, ID: 1,
+		Name:                "customer-1",
+		Description:         "customer-1 V6.0.10P2N12",
+		OnuType:             "F660V6.0",
+		SerialNumber:        "ZTEGC8F10001",
+		RXPower:             "-21.80",
+		TXPower:             "2.40",
+		Status:              "Online",
+		IPAddress:           "10.10.1.11",
+		LastOnline:          "2024-10-17 08:30:00",
+		LastOffline:         "2024-10-16 23:05:12",
+		LastOfflineReason:   "Reboot",
+		GponOpticalDistance: "1250",
+	}
+	// Durations depend on the current time
+	onu.Uptime, onu.LastDownTimeDuration = "", ""
+	if onu != want {
+		t.Errorf("GetByBoardIDPonIDAndOnuID() = %+v, want %+v", onu, want)
+	}
+}
@@ -62,7 +62,7 @@ func (u *ponUsecase) GetPonPortInfo(ctx context.Context, boardID, ponID int) (*m
 	}
 
 	// Get admin status from .3.11.3.1.1.{pon_index}
-	adminStatusOID := fmt.Sprintf("%s.3.11.3.1.1.%d", u.cfg.OltCfg.BaseOID1, ponIndex)
+	adminStatusOID := fmt.Sprintf("%s.3.11.3.1.1.%d", u.cfg.BaseOID(), ponIndex)
 	if result, err := u.snmpRepository.Get([]string{adminStatusOID}); err == nil && len(result.Variables) > 0 {
 		if status, ok := result.Variables[0].Value.(int); ok {
 			if status == 1 {
@@ -74,7 +74,7 @@ func (u *ponUsecase) GetPonPortInfo(ctx context.Context, boardID, ponID int) (*m
 	}
 
 	// Get operational status and distance from .3.11.5.1.{col}.{pon_index}
-	distanceOID := fmt.Sprintf("%s.3.11.5.1.3.%d", u.cfg.OltCfg.BaseOID1, ponIndex)
+	distanceOID := fmt.Sprintf("%s.3.11.5.1.3.%d", u.cfg.BaseOID(), ponIndex)
 	if result, err := u.snmpRepository.Get([]string{distanceOID}); err == nil && len(result.Variables) > 0 {
 		if distance, ok := result.Variables[0].Value.(int); ok {
 			ponInfo.Distance = distance
@@ -82,7 +82,7 @@ func (u *ponUsecase) GetPonPortInfo(ctx context.Context, boardID, ponID int) (*m
 	}
 
 	// Get oper status from .3.11.5.1.4.{pon_index}
-	operStatusOID := fmt.Sprintf("%s.3.11.5.1.4.%d", u.cfg.OltCfg.BaseOID1, ponIndex)
+	operStatusOID := fmt.Sprintf("%s.3.11.5.1.4.%d", u.cfg.BaseOID(), ponIndex)
 	if result, err := u.snmpRepository.Get([]string{operStatusOID}); err == nil && len(result.Variables) > 0 {
 		if status, ok := result.Variables[0].Value.(int); ok {
 			if status == 2 {
@@ -94,7 +94,7 @@ func (u *ponUsecase) GetPonPortInfo(ctx context.Context, boardID, ponID int) (*m
 	}
 
 	// Count ONUs by doing SNMP walk on ONU table
-	onuCountOID := fmt.Sprintf("%s.3.13.3.1.5.%d", u.cfg.OltCfg.BaseOID1, ponIndex)
+	onuCountOID := fmt.Sprintf("%s.3.13.3.1.5.%d", u.cfg.BaseOID(), ponIndex)
 	onuCount := 0
 	err := u.snmpRepository.Walk(onuCountOID, func(pdu gosnmp.SnmpPDU) error {
 		onuCount++
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// Operations that take a pre-change snapshot; the name is stored as a tag on the snapshot backup
+const (
+	OperationDeleteONU          = "onu_management.delete_onu"
+	OperationProvisionDeleteONU = "provision.delete_onu"
+	OperationModifyVLAN         = "vlan.modify_vlan"
+	OperationDeleteVLAN         = "vlan.delete_vlan"
+	OperationDeleteTCONT        = "traffic.delete_tcont"
+	OperationDeleteGEMPort      = "traffic.delete_gemport"
+)
+
+// PreChangeSnapshotter backs up an ONU before a destructive operation changes it
+type PreChangeSnapshotter interface {
+	SnapshotONU(ctx context.Context, ponPort string, onuID int, operation string) (*model.PreChangeSnapshot, error)
+}
+
+// takePreChangeSnapshot snapshots an ONU before operation runs, on behalf of the caller of ctx; a nil snapshotter disables snapshots.
+// If the snapshot fails the operation must not run, so the error says so.
+func takePreChangeSnapshot(ctx context.Context, snapshots PreChangeSnapshotter, ponPort string, onuID int, operation string) (*model.PreChangeSnapshot, error) {
+	if snapshots == nil {
+		return nil, nil
+	}
+
+	snapshot, err := snapshots.SnapshotONU(ctx, ponPort, onuID, operation)
+	if err != nil {
+		log.Error().
+			Err(err).
+			Str("pon_port", ponPort).
+			Int("onu_id", onuID).
+			Str("operation", operation).
+			Msg("Pre-change snapshot failed, operation not executed")
+
+		// Unknown ONUs and invalid input are reported as such
+		var appErr *apperrors.AppError
+		if errors.As(err, &appErr) && (appErr.Type == apperrors.ErrorTypeNotFound || appErr.Type == apperrors.ErrorTypeValidation) {
+			return nil, err
+		}
+		return nil, apperrors.NewInternalError(fmt.Sprintf("pre-change snapshot failed, %s was not executed", operation), err)
+	}
+
+	log.Info().
+		Str("backup_id", snapshot.BackupID).
+		Str("pon_port", ponPort).
+		Int("onu_id", onuID).
+		Str("operation", operation).
+		Str("user", backupCreator(ctx)).
+		Msg("Pre-change snapshot created")
+
+	return snapshot, nil
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	apperrors "github.com/s4lfanet/go-api-c320/internal/errors"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+)
+
+// failingSnapshotter fails every snapshot with err
+type failingSnapshotter struct {
+	err   error
+	calls int
+}
+
+func (s *failingSnapshotter) SnapshotONU(_ context.Context, ponPort string, onuID int, operation string) (*model.PreChangeSnapshot, error) {
+	s.calls++
+	return nil, s.err
+}
+
+func TestSnapshotONU_StoresTaggedBackup(t *testing.T) {
+	cfg := newBackupTestConfig(t)
+	uc := NewConfigBackupUsecase(cfg, nil, newBackupTestSnmpRepository(cfg), nil)
+
+	ctx := model.ContextWithPrincipal(context.Background(), &model.Principal{ID: "2b7c", Name: "alice", Method: model.AuthMethodOIDC})
+	snapshot, err := uc.SnapshotONU(ctx, "1/1/1", 1, OperationDeleteTCONT)
+	if err != nil {
+		t.Fatalf("SnapshotONU() error = %v", err)
+	}
+	if snapshot.Operation != OperationDeleteTCONT || snapshot.RestoreEndpoint != "POST /config/restore/"+snapshot.BackupID {
+		t.Errorf("snapshot = %+v", snapshot)
+	}
+
+	backup, err := uc.GetBackup(snapshot.BackupID)
+	if err != nil {
+		t.Fatalf("GetBackup() error = %v", err)
+	}
+	if backup.Type != "onu" || len(backup.Metadata.Tags) != 2 ||
+		backup.Metadata.Tags[0] != model.BackupTagPreChange || backup.Metadata.Tags[1] != OperationDeleteTCONT {
+		t.Errorf("backup type %q, tags %v", backup.Type, backup.Metadata.Tags)
+	}
+	if backup.Metadata.CreatedBy != "alice" {
+		t.Errorf("CreatedBy = %q, want the user of the deleting request", backup.Metadata.CreatedBy)
+	}
+	onu, ok := backup.Config.(model.ONUConfigBackup)
+	if !ok || onu.SerialNumber != "ZTEGC0000001" {
+		t.Errorf("Config = %+v, want ONU 1/1/1:1", backup.Config)
+	}
+
+	// Unknown ONUs are not snapshotted
+	_, err = uc.SnapshotONU(context.Background(), "1/1/1", 9, OperationDeleteTCONT)
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("SnapshotONU(unknown ONU) error = %v, want not found", err)
+	}
+}
+
+func TestPreChangeSnapshot_FailureAbortsOperation(t *testing.T) {
+	snapshots := &failingSnapshotter{err: errors.New("backup directory is read-only")}
+
+	// The telnet session manager is nil: reaching the OLT would panic
+	traffic := NewTrafficUsecase(nil, nil, snapshots)
+	if _, err := traffic.DeleteTCONT(context.Background(), "1/1/1", 1, 1); err == nil || !strings.Contains(err.Error(), OperationDeleteTCONT+" was not executed") {
+		t.Errorf("DeleteTCONT() error = %v, want aborted operation", err)
+	}
+	if _, err := traffic.DeleteGEMPort(context.Background(), "1/1/1", 1, 1); err == nil {
+		t.Error("DeleteGEMPort() succeeded without a snapshot")
+	}
+
+	vlan := NewVLANUsecase(nil, nil, snapshots)
+	if _, err := vlan.DeleteVLAN(context.Background(), "1/1/1", 1); err == nil {
+		t.Error("DeleteVLAN() succeeded without a snapshot")
+	}
+
+	provision := NewProvisionUsecase(nil, nil, snapshots)
+	if _, err := provision.DeleteONU(context.Background(), "1/1/1", 1); err == nil {
+		t.Error("ProvisionUsecase.DeleteONU() succeeded without a snapshot")
+	}
+
+	if snapshots.calls != 4 {
+		t.Errorf("snapshot attempts = %d, want 4", snapshots.calls)
+	}
+
+	// Not found errors keep their type so the API answers 404
+	snapshots.err = apperrors.NewNotFoundError("ONU", "1/1/1:1")
+	_, err := traffic.DeleteTCONT(context.Background(), "1/1/1", 1, 1)
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) || appErr.Type != apperrors.ErrorTypeNotFound {
+		t.Errorf("DeleteTCONT() error = %v, want not found", err)
+	}
+}
@@ -51,7 +51,7 @@ func (u *profileUsecase) GetAllTrafficProfiles(ctx context.Context) ([]*model.Tr
 
 	// Walk the profile name OID to get all profile IDs
 	// OID: .3.26.1.1.2.{profile_id}
-	nameOID := fmt.Sprintf("%s.3.26.1.1.2", u.cfg.OltCfg.BaseOID1)
+	nameOID := fmt.Sprintf("%s.3.26.1.1.2", u.cfg.BaseOID())
 
 	err := u.snmpRepository.Walk(nameOID, func(pdu gosnmp.SnmpPDU) error {
 		// Extract profile ID from OID
@@ -95,7 +95,7 @@ func (u *profileUsecase) GetAllTrafficProfiles(ctx context.Context) ([]*model.Tr
 	// For each profile, get CIR, PIR, and MaxBW
 	for profileID, profile := range profiles {
 		// Get CIR (.3.26.1.1.3)
-		cirOID := fmt.Sprintf("%s.3.26.1.1.3.%d", u.cfg.OltCfg.BaseOID1, profileID)
+		cirOID := fmt.Sprintf("%s.3.26.1.1.3.%d", u.cfg.BaseOID(), profileID)
 		if result, err := u.snmpRepository.Get([]string{cirOID}); err == nil && len(result.Variables) > 0 {
 			if cir, ok := result.Variables[0].Value.(int); ok {
 				profile.CIR = cir
@@ -103,7 +103,7 @@ func (u *profileUsecase) GetAllTrafficProfiles(ctx context.Context) ([]*model.Tr
 		}
 
 		// Get PIR (.3.26.1.1.4)
-		pirOID := fmt.Sprintf("%s.3.26.1.1.4.%d", u.cfg.OltCfg.BaseOID1, profileID)
+		pirOID := fmt.Sprintf("%s.3.26.1.1.4.%d", u.cfg.BaseOID(), profileID)
 		if result, err := u.snmpRepository.Get([]string{pirOID}); err == nil && len(result.Variables) > 0 {
 			if pir, ok := result.Variables[0].Value.(int); ok {
 				profile.PIR = pir
@@ -111,7 +111,7 @@ func (u *profileUsecase) GetAllTrafficProfiles(ctx context.Context) ([]*model.Tr
 		}
 
 		// Get MaxBW (.3.26.1.1.5)
-		maxBWOID := fmt.Sprintf("%s.3.26.1.1.5.%d", u.cfg.OltCfg.BaseOID1, profileID)
+		maxBWOID := fmt.Sprintf("%s.3.26.1.1.5.%d", u.cfg.BaseOID(), profileID)
 		if result, err := u.snmpRepository.Get([]string{maxBWOID}); err == nil && len(result.Variables) > 0 {
 			if maxBW, ok := result.Variables[0].Value.(int); ok {
 				profile.MaxBW = maxBW
@@ -138,7 +138,7 @@ func (u *profileUsecase) GetTrafficProfile(ctx context.Context, profileID int) (
 	}
 
 	// Get profile name (.3.26.1.1.2)
-	nameOID := fmt.Sprintf("%s.3.26.1.1.2.%d", u.cfg.OltCfg.BaseOID1, profileID)
+	nameOID := fmt.Sprintf("%s.3.26.1.1.2.%d", u.cfg.BaseOID(), profileID)
 	result, err := u.snmpRepository.Get([]string{nameOID})
 	if err != nil {
 		return nil, apperrors.NewNotFoundError("traffic profile", map[string]int{"profile_id": profileID})
@@ -156,7 +156,7 @@ func (u *profileUsecase) GetTrafficProfile(ctx context.Context, profileID int) (
 	}
 
 	// Get CIR (.3.26.1.1.3)
-	cirOID := fmt.Sprintf("%s.3.26.1.1.3.%d", u.cfg.OltCfg.BaseOID1, profileID)
+	cirOID := fmt.Sprintf("%s.3.26.1.1.3.%d", u.cfg.BaseOID(), profileID)
 	if result, err := u.snmpRepository.Get([]string{cirOID}); err == nil && len(result.Variables) > 0 {
 		if cir, ok := result.Variables[0].Value.(int); ok {
 			profile.CIR = cir
@@ -164,7 +164,7 @@ func (u *profileUsecase) GetTrafficProfile(ctx context.Context, profileID int) (
 	}
 
 	// Get PIR (.3.26.1.1.4)
-	pirOID := fmt.Sprintf("%s.3.26.1.1.4.%d", u.cfg.OltCfg.BaseOID1, profileID)
+	pirOID := fmt.Sprintf("%s.3.26.1.1.4.%d", u.cfg.BaseOID(), profileID)
 	if result, err := u.snmpRepository.Get([]string{pirOID}); err == nil && len(result.Variables) > 0 {
 		if pir, ok := result.Variables[0].Value.(int); ok {
 			profile.PIR = pir
@@ -172,7 +172,7 @@ func (u *profileUsecase) GetTrafficProfile(ctx context.Context, profileID int) (
 	}
 
 	// Get MaxBW (.3.26.1.1.5)
-	maxBWOID := fmt.Sprintf("%s.3.26.1.1.5.%d", u.cfg.OltCfg.BaseOID1, profileID)
+	maxBWOID := fmt.Sprintf("%s.3.26.1.1.5.%d", u.cfg.BaseOID(), profileID)
 	if result, err := u.snmpRepository.Get([]string{maxBWOID}); err == nil && len(result.Variables) > 0 {
 		if maxBW, ok := result.Variables[0].Value.(int); ok {
 			profile.MaxBW = maxBW
@@ -192,7 +192,7 @@ func (u *profileUsecase) GetAllVlanProfiles(ctx context.Context) ([]*model.VlanP
 	// Walk the VLAN profile OID to get all VLAN names
 	// OID: .3.50.20.15.1.{col}.{vlan_name_ascii}
 	// The VLAN name is encoded as ASCII decimal values in the OID
-	baseOID := fmt.Sprintf("%s.3.50.20.15.1", u.cfg.OltCfg.BaseOID1)
+	baseOID := fmt.Sprintf("%s.3.50.20.15.1", u.cfg.BaseOID())
 
 	err := u.snmpRepository.Walk(baseOID, func(pdu gosnmp.SnmpPDU) error {
 		// Extract VLAN name from OID
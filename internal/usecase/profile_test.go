@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+)
+
+func TestProfileUsecase_Simulator(t *testing.T) {
+	for _, version := range []config.FirmwareVersion{config.FirmwareV21, config.FirmwareV22} {
+		t.Run(string(version), func(t *testing.T) {
+			_, cfg := newSimulatedSNMP(t, version)
+			u := NewProfileUsecase(repository.NewSnmpRepository(cfg.SnmpCfg), &mockRedisRepository{}, cfg)
+			ctx := context.Background()
+
+			profiles, err := u.GetAllTrafficProfiles(ctx)
+			if err != nil {
+				t.Fatalf("GetAllTrafficProfiles() error = %v", err)
+			}
+			sort.Slice(profiles, func(i, j int) bool { return profiles[i].ProfileID < profiles[j].ProfileID })
+			want := []model.TrafficProfile{
+				{ProfileID: 1, Name: "UP-10M", CIR: 5120, PIR: 10240, MaxBW: 10240},
+				{ProfileID: 2, Name: "UP-50M", CIR: 10240, PIR: 51200, MaxBW: 51200},
+				{ProfileID: 3, Name: "UP-100M", CIR: 20480, PIR: 102400, MaxBW: 102400},
+			}
+			if len(profiles) != len(want) {
+				t.Fatalf("GetAllTrafficProfiles() returned %d profiles, want %d", len(profiles), len(want))
+			}
+			for i := range want {
+				if *profiles[i] != want[i] {
+					t.Errorf("GetAllTrafficProfiles()[%d] = %+v, want %+v", i, *profiles[i], want[i])
+				}
+			}
+
+			profile, err := u.GetTrafficProfile(ctx, 2)
+			if err != nil {
+				t.Fatalf("GetTrafficProfile() error = %v", err)
+			}
+			if *profile != want[1] {
+				t.Errorf("GetTrafficProfile(2) = %+v, want %+v", *profile, want[1])
+			}
+
+			vlans, err := u.GetAllVlanProfiles(ctx)
+			if err != nil {
+				t.Fatalf("GetAllVlanProfiles() error = %v", err)
+			}
+			sort.Slice(vlans, func(i, j int) bool { return vlans[i].VlanID < vlans[j].VlanID })
+			wantVlans := []model.VlanProfile{
+				{Name: "INTERNET", VlanID: 100, Priority: 0, Mode: "tag", Description: "Residential internet"},
+				{Name: "IPTV", VlanID: 200, Priority: 4, Mode: "untag", Description: "Multicast TV"},
+			}
+			if len(vlans) != len(wantVlans) {
+				t.Fatalf("GetAllVlanProfiles() returned %d profiles, want %d", len(vlans), len(wantVlans))
+			}
+			for i := range wantVlans {
+				if *vlans[i] != wantVlans[i] {
+					t.Errorf("GetAllVlanProfiles()[%d] = %+v, want %+v", i, *vlans[i], wantVlans[i])
+				}
+			}
+		})
+	}
+}
@@ -21,7 +21,7 @@ type ProvisionUseCaseInterface interface {
 
 	// ONU Registration
 	RegisterONU(ctx context.Context, req model.ONURegistrationRequest) (*model.ONURegistrationResponse, error)
-	DeleteONU(ctx context.Context, ponPort string, onuID int) error
+	DeleteONU(ctx context.Context, ponPort string, onuID int) (*model.PreChangeSnapshot, error)
 
 	// ONU Configuration
 	ConfigureTCONT(ctx context.Context, ponPort string, onuID int, tcontID int, profileName string) error
@@ -33,13 +33,16 @@ type ProvisionUseCaseInterface interface {
 type ProvisionUsecase struct {
 	sessionManager *repository.TelnetSessionManager
 	config         *config.Config
+	snapshots      PreChangeSnapshotter
 }
 
-// NewProvisionUsecase creates a new provision usecase instance
-func NewProvisionUsecase(sessionManager *repository.TelnetSessionManager, cfg *config.Config) ProvisionUseCaseInterface {
+// NewProvisionUsecase creates a new provision usecase instance.
+// If snapshots is set, ONUs are backed up before they are deleted.
+func NewProvisionUsecase(sessionManager *repository.TelnetSessionManager, cfg *config.Config, snapshots PreChangeSnapshotter) ProvisionUseCaseInterface {
 	return &ProvisionUsecase{
 		sessionManager: sessionManager,
 		config:         cfg,
+		snapshots:      snapshots,
 	}
 }
 
@@ -266,13 +269,19 @@ func (u *ProvisionUsecase) RegisterONU(ctx context.Context, req model.ONURegistr
 	}, nil
 }
 
-// DeleteONU deletes an ONU from the OLT
-func (u *ProvisionUsecase) DeleteONU(ctx context.Context, ponPort string, onuID int) error {
+// DeleteONU deletes an ONU from the OLT and returns the snapshot taken before (nil if snapshots are disabled)
+func (u *ProvisionUsecase) DeleteONU(ctx context.Context, ponPort string, onuID int) (*model.PreChangeSnapshot, error) {
 	log.Info().
 		Str("pon_port", ponPort).
 		Int("onu_id", onuID).
 		Msg("Deleting ONU")
 
+	// Snapshot the ONU so the deletion can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, ponPort, onuID, OperationProvisionDeleteONU)
+	if err != nil {
+		return nil, err
+	}
+
 	commands := []string{
 		fmt.Sprintf("interface gpon-olt_%s", ponPort),
 		fmt.Sprintf("no onu %d", onuID),
@@ -282,24 +291,24 @@ func (u *ProvisionUsecase) DeleteONU(ctx context.Context, ponPort string, onuID
 	result, err := u.sessionManager.ExecuteInConfigMode(ctx, commands)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to delete ONU")
-		return err
+		return snapshot, err
 	}
 
 	// Check for errors
 	for _, resp := range result.Responses {
-		if !resp.Success {
+		if !resp.Success || strings.Contains(strings.ToLower(resp.Output), "error") {
 			log.Error().
 				Str("command", resp.Command).
 				Str("output", resp.Output).
 				Msg("Delete command failed")
-			return fmt.Errorf("delete failed: %s", resp.Output)
+			return snapshot, fmt.Errorf("delete failed: %s", resp.Output)
 		}
 	}
 
 	// Save configuration
 	if err := u.sessionManager.SaveConfiguration(ctx); err != nil {
 		log.Error().Err(err).Msg("Failed to save configuration")
-		return err
+		return snapshot, err
 	}
 
 	log.Info().
@@ -307,7 +316,7 @@ func (u *ProvisionUsecase) DeleteONU(ctx context.Context, ponPort string, onuID
 		Int("onu_id", onuID).
 		Msg("ONU deleted successfully")
 
-	return nil
+	return snapshot, nil
 }
 
 // ConfigureTCONT configures TCONT for an ONU
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestProvisionUsecase_UnconfiguredONUs(t *testing.T) {
+	_, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewProvisionUsecase(manager, nil, nil)
+	ctx := context.Background()
+
+	all, err := uc.GetAllUnconfiguredONUs(ctx)
+	if err != nil {
+		t.Fatalf("GetAllUnconfiguredONUs() error = %v", err)
+	}
+	if len(all) != 2 || all[0].SerialNumber != "ZTEGD824CDF3" || all[1].PONPort != "1/1/2" {
+		t.Errorf("GetAllUnconfiguredONUs() = %+v", all)
+	}
+
+	onus, err := uc.GetUnconfiguredONUs(ctx, "1/1/2")
+	if err != nil {
+		t.Fatalf("GetUnconfiguredONUs() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].SerialNumber != "ZTEGDA5918AC" || onus[0].Type != "ZTE (Auto-detect)" {
+		t.Errorf("GetUnconfiguredONUs() = %+v", onus)
+	}
+}
+
+func TestProvisionUsecase_RegisterAndDelete(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewProvisionUsecase(manager, nil, nil)
+	ctx := context.Background()
+	state := server.State()
+
+	req := model.ONURegistrationRequest{
+		PONPort:      "1/1/1",
+		ONUID:        4,
+		ONUType:      "ZTE-F609",
+		SerialNumber: "ZTEGD824CDF3",
+		Name:         "new customer",
+	}
+	req.Profile.DBAProfile = "UP-50M"
+	req.Profile.VLAN = 300
+	resp, err := uc.RegisterONU(ctx, req)
+	if err != nil || !resp.Success {
+		t.Fatalf("RegisterONU() = %+v, %v", resp, err)
+	}
+
+	onu, ok := state.ONU("1/1/1", 4)
+	if !ok {
+		t.Fatal("ONU 1/1/1:4 was not registered")
+	}
+	if onu.Name != "new customer" || onu.SerialNumber != "ZTEGD824CDF3" {
+		t.Errorf("ONU = %+v", onu)
+	}
+	if len(onu.TCONTs) != 1 || onu.TCONTs[0].Profile != "UP-50M" || len(onu.GEMPorts) != 1 {
+		t.Errorf("TCONTs = %+v, GEMPorts = %+v", onu.TCONTs, onu.GEMPorts)
+	}
+	if len(onu.ServicePorts) != 1 || onu.ServicePorts[0].VLAN != 300 || onu.ServicePorts[0].UserVLAN != "untagged" {
+		t.Errorf("ServicePorts = %+v", onu.ServicePorts)
+	}
+	if state.Saves() != 1 {
+		t.Errorf("Saves() = %d, want 1", state.Saves())
+	}
+
+	// The serial number is in use now
+	_, err = uc.RegisterONU(ctx, model.ONURegistrationRequest{PONPort: "1/1/1", ONUID: 5, ONUType: "ZTE-F609", SerialNumber: "ZTEGD824CDF3"})
+	if err == nil {
+		t.Error("RegisterONU() with a registered serial number succeeded")
+	}
+
+	if _, err := uc.DeleteONU(ctx, "1/1/1", 4); err != nil {
+		t.Fatalf("DeleteONU() error = %v", err)
+	}
+	if _, ok := state.ONU("1/1/1", 4); ok {
+		t.Error("ONU 1/1/1:4 still exists")
+	}
+	if _, err := uc.DeleteONU(ctx, "1/1/1", 4); err == nil {
+		t.Error("DeleteONU() of a missing ONU succeeded")
+	}
+
+	// A deleted ONU shows up as unconfigured again
+	onus, err := uc.GetUnconfiguredONUs(ctx, "1/1/1")
+	if err != nil {
+		t.Fatalf("GetUnconfiguredONUs() error = %v", err)
+	}
+	if len(onus) != 1 || onus[0].SerialNumber != "ZTEGD824CDF3" {
+		t.Errorf("GetUnconfiguredONUs() = %+v", onus)
+	}
+}
+
+func TestProvisionUsecase_ConfigureErrors(t *testing.T) {
+	_, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewProvisionUsecase(manager, nil, nil)
+	ctx := context.Background()
+
+	if err := uc.ConfigureTCONT(ctx, "1/1/1", 1, 2, "MISSING"); err == nil {
+		t.Error("ConfigureTCONT() with an unknown DBA profile succeeded")
+	}
+	if err := uc.ConfigureGEMPort(ctx, "1/1/1", 1, 2, 7); err == nil {
+		t.Error("ConfigureGEMPort() on a missing T-CONT succeeded")
+	}
+	if err := uc.ConfigureServicePort(ctx, "1/1/1", 99, 1, 100, "100"); err == nil {
+		t.Error("ConfigureServicePort() on a missing ONU succeeded")
+	}
+}
@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+// newSimulatedOLT starts a CLI simulator for state and returns a session manager connected to it
+func newSimulatedOLT(t *testing.T, state *simulator.State) (*simulator.CLIServer, *repository.TelnetSessionManager) {
+	t.Helper()
+
+	server := simulator.NewCLIServer(simulator.CLIConfig{EnablePassword: "zxr10"}, state)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	manager := repository.NewTelnetSessionManager(server.TelnetConfig())
+	t.Cleanup(func() {
+		manager.Close()
+		server.Close()
+	})
+	return server, manager
+}
+
+// newSimulatedSNMP starts an SNMP simulator serving the fixture of version and returns it with a
+// configuration pointing at it, with the OIDs LoadConfig selects for that firmware
+func newSimulatedSNMP(t *testing.T, version config.FirmwareVersion) (*simulator.SNMPAgent, *config.Config) {
+	t.Helper()
+
+	records, err := simulator.Fixture(version)
+	if err != nil {
+		t.Fatalf("Fixture() error = %v", err)
+	}
+	agent := simulator.NewSNMPAgent(simulator.SNMPConfig{}, records)
+	if err := agent.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { agent.Close() })
+
+	profile := config.OIDProfiles[version]
+	boardPonMap, err := config.InitializeBoardPonMapForProfile(profile)
+	if err != nil {
+		t.Fatalf("InitializeBoardPonMapForProfile() error = %v", err)
+	}
+	return agent, &config.Config{
+		SnmpCfg: agent.SnmpConfig(),
+		OltCfg: config.OltConfig{
+			BaseOID1: profile.BaseOID,
+			BaseOID2: config.BaseOID2,
+		},
+		Firmware:    version,
+		OIDProfile:  profile,
+		BoardPonMap: boardPonMap,
+	}
+}
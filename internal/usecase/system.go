@@ -0,0 +1,382 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/parser"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/utils"
+	"github.com/s4lfanet/go-api-c320/pkg/metrics"
+)
+
+// SNMPv2-MIB system group
+const (
+	sysDescrOID    = ".1.3.6.1.2.1.1.1.0"
+	sysObjectIDOID = ".1.3.6.1.2.1.1.2.0"
+	sysUpTimeOID   = ".1.3.6.1.2.1.1.3.0"
+	sysNameOID     = ".1.3.6.1.2.1.1.5.0"
+
+	zteEnterpriseOID = ".1.3.6.1.4.1.3902." // sysObjectID prefix of ZTE devices
+)
+
+// firmwareCheckInterval is how often the OLT is polled for reconnects and reboots, after which the firmware is detected again
+const firmwareCheckInterval = time.Minute
+
+// VersionSource provides the "show version" output of an OLT (implemented by TelnetSessionManager)
+type VersionSource interface {
+	GetVersion(ctx context.Context) (*model.OLTVersion, error)
+}
+
+// SystemUsecaseInterface defines the interface for OLT system information
+type SystemUsecaseInterface interface {
+	GetSystemInfo(ctx context.Context) (*model.SystemInfo, error)
+}
+
+// SystemUsecase detects the model and software version of an OLT and selects the matching OID profile.
+// The version is read from sysDescr over SNMP, or from "show version" over the CLI if SNMP does not report it.
+// The profile is selected by the first detection. When a later detection finds another firmware, e.g. after
+// an upgrade, the profile of an OLT that is not pinned is switched with the callback of OnFirmwareChange.
+type SystemUsecase struct {
+	oltID      string
+	configured config.FirmwareVersion // Pinned firmware, or FirmwareAuto
+	snmp       repository.SnmpRepositoryInterface
+	cli        VersionSource
+	interval   time.Duration
+	now        func() time.Time
+
+	mu            sync.RWMutex
+	info          *model.SystemInfo                  // Result of the last detection
+	active        config.FirmwareVersion             // Firmware profile the OLT is read with
+	switchProfile func(config.FirmwareVersion) error // Regenerates the OID mappings for another firmware; nil if not set
+	uptime        uint32                             // sysUpTime of the last poll
+	reachable     bool                               // Last poll succeeded
+}
+
+// NewSystemUsecase creates the firmware detection of an OLT and registers its state with the default metrics registry.
+// configured is the firmware pinned by ZTE_FIRMWARE_VERSION or the registry entry, or FirmwareAuto; cli may be nil.
+func NewSystemUsecase(oltID string, configured config.FirmwareVersion, snmp repository.SnmpRepositoryInterface, cli VersionSource) *SystemUsecase {
+	u := &SystemUsecase{
+		oltID:      oltID,
+		configured: configured,
+		snmp:       snmp,
+		cli:        cli,
+		interval:   firmwareCheckInterval,
+		now:        time.Now,
+	}
+	metrics.Default.Register(u)
+	return u
+}
+
+// OnFirmwareChange sets the function that switches the OLT to the profile of another firmware.
+// It is called when a detection after the first one finds a new firmware on an OLT that is not pinned;
+// the profile stays active if it fails.
+func (u *SystemUsecase) OnFirmwareChange(switchProfile func(firmware config.FirmwareVersion) error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.switchProfile = switchProfile
+}
+
+// Firmware returns the firmware profile the OLT is read with, detecting it first if necessary
+func (u *SystemUsecase) Firmware(ctx context.Context) config.FirmwareVersion {
+	u.mu.RLock()
+	active := u.active
+	u.mu.RUnlock()
+
+	if active == "" {
+		return config.FirmwareVersion(u.Detect(ctx).Firmware)
+	}
+	return active
+}
+
+// GetSystemInfo returns the result of the last firmware detection
+func (u *SystemUsecase) GetSystemInfo(ctx context.Context) (*model.SystemInfo, error) {
+	u.mu.RLock()
+	info := u.info
+	u.mu.RUnlock()
+
+	if info == nil {
+		info = u.Detect(ctx)
+	}
+	result := *info
+	return &result, nil
+}
+
+// Run polls sysUpTime every interval until ctx is canceled and detects the firmware again
+// when the OLT is reachable after a failed poll or has rebooted, e.g. for a firmware upgrade.
+func (u *SystemUsecase) Run(ctx context.Context) {
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			u.Check(ctx)
+		}
+	}
+}
+
+// Check polls sysUpTime once and detects the firmware again after a reconnect or reboot
+func (u *SystemUsecase) Check(ctx context.Context) {
+	result, err := u.snmp.Get([]string{sysUpTimeOID})
+	var uptime uint32
+	if err == nil {
+		uptime, err = sysUpTime(result)
+	}
+
+	u.mu.Lock()
+	wasReachable, lastUptime := u.reachable, u.uptime
+	u.reachable = err == nil
+	if err == nil {
+		u.uptime = uptime
+	}
+	u.mu.Unlock()
+
+	switch {
+	case err != nil:
+		if wasReachable {
+			log.Warn().Err(err).Str("olt_id", u.oltID).Msg("OLT is not reachable over SNMP")
+		}
+	case !wasReachable:
+		log.Info().Str("olt_id", u.oltID).Msg("OLT is reachable over SNMP again, detecting firmware")
+		u.Detect(ctx)
+	case uptime < lastUptime:
+		log.Warn().Str("olt_id", u.oltID).Msg("OLT has rebooted, detecting firmware")
+		u.Detect(ctx)
+	}
+}
+
+// Detect reads the model and software version from the OLT, selects the firmware profile on the
+// first call, switches it on later calls if the firmware changed, and returns the result. It never
+// fails: if nothing can be detected, the pinned or process-wide firmware is used and the reason is
+// reported in the warning.
+func (u *SystemUsecase) Detect(ctx context.Context) *model.SystemInfo {
+	info := &model.SystemInfo{
+		OLTID:      u.oltID,
+		Source:     model.FirmwareSourceConfigured,
+		Pinned:     u.configured != config.FirmwareAuto,
+		DetectedAt: u.now(),
+	}
+
+	var warnings []string
+	snmpErr := u.detectSNMP(info, &warnings)
+	var cliErr error
+	if info.SoftwareVersion == "" && u.cli != nil {
+		cliErr = u.detectCLI(ctx, info)
+	}
+
+	var detected config.FirmwareVersion
+	if info.SoftwareVersion != "" {
+		detected, info.KnownBuild = config.FirmwareForSoftwareVersion(info.SoftwareVersion)
+		info.DetectedFirmware = string(detected)
+	}
+
+	u.mu.Lock()
+	first := u.active == ""
+	if first {
+		u.active = u.selectFirmware(detected)
+	}
+	active, switchProfile := u.active, u.switchProfile
+	u.mu.Unlock()
+
+	// The firmware of an OLT that is not pinned changed since the profile was selected
+	var switchErr error
+	if !first && detected != "" && detected != active && u.configured == config.FirmwareAuto {
+		switchErr = fmt.Errorf("profile switching is not available")
+		if switchProfile != nil {
+			switchErr = switchProfile(detected)
+		}
+		if switchErr == nil {
+			log.Info().Str("olt_id", u.oltID).Str("from", string(active)).Str("to", string(detected)).
+				Msg("Switched OID profile to the new firmware")
+			active = detected
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.active = active
+	info.Firmware = string(active)
+	info.ProfileName = config.GetOIDProfileForVersion(active).Name
+
+	level := zerolog.InfoLevel
+	switch {
+	case info.SoftwareVersion == "":
+		level = zerolog.WarnLevel
+		warnings = append(warnings, fmt.Sprintf("firmware could not be detected (snmp: %s, cli: %s); using the %s profile",
+			errorOrNotTried(snmpErr), errorOrNotTried(cliErr), u.active))
+	case !info.KnownBuild:
+		level = zerolog.WarnLevel
+		warnings = append(warnings, fmt.Sprintf("unknown firmware build %s; using the %s profile, ONU data may be missing or wrong",
+			info.SoftwareVersion, detected))
+	}
+	if detected != "" && detected != active {
+		level = zerolog.ErrorLevel
+		info.ProfileMismatch = true
+		if switchErr == nil {
+			warnings = append(warnings, fmt.Sprintf("firmware is pinned to %s but the OLT runs %s", active, info.SoftwareVersion))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("OLT now runs %s but is read with the %s profile: %v",
+				info.SoftwareVersion, active, switchErr))
+		}
+	}
+	info.Warning = strings.Join(warnings, "; ")
+	u.info = info
+
+	log.WithLevel(level).Str("olt_id", u.oltID).
+		Str("model", info.Model).
+		Str("software_version", info.SoftwareVersion).
+		Str("source", info.Source).
+		Str("firmware", info.Firmware).
+		Bool("pinned", info.Pinned).
+		Str("warning", info.Warning).
+		Msg("OLT firmware detected")
+
+	return info
+}
+
+// selectFirmware chooses the profile of the first detection: a pinned firmware always wins,
+// otherwise the detected one, falling back to the process-wide firmware
+func (u *SystemUsecase) selectFirmware(detected config.FirmwareVersion) config.FirmwareVersion {
+	switch {
+	case u.configured != config.FirmwareAuto:
+		return u.configured
+	case detected != "":
+		return detected
+	default:
+		return config.GetCurrentFirmwareVersion()
+	}
+}
+
+// detectSNMP reads the system group of the OLT into info
+func (u *SystemUsecase) detectSNMP(info *model.SystemInfo, warnings *[]string) error {
+	result, err := u.snmp.Get([]string{sysDescrOID, sysObjectIDOID, sysUpTimeOID, sysNameOID})
+	if err != nil {
+		return err
+	}
+
+	for _, pdu := range result.Variables {
+		switch strings.TrimPrefix(pdu.Name, ".") {
+		case sysDescrOID[1:]:
+			info.SysDescr = octetString(pdu)
+		case sysObjectIDOID[1:]:
+			if oid, ok := pdu.Value.(string); ok && pdu.Type == gosnmp.ObjectIdentifier {
+				info.SysObjectID = "." + strings.TrimPrefix(oid, ".")
+			}
+		case sysUpTimeOID[1:]:
+			if ticks, ok := pdu.Value.(uint32); ok {
+				info.Uptime = utils.ConvertDurationToString(time.Duration(ticks) * 10 * time.Millisecond)
+				u.mu.Lock()
+				u.uptime, u.reachable = ticks, true
+				u.mu.Unlock()
+			}
+		case sysNameOID[1:]:
+			info.SysName = octetString(pdu)
+		}
+	}
+
+	if info.SysObjectID != "" && !strings.HasPrefix(info.SysObjectID, zteEnterpriseOID) {
+		*warnings = append(*warnings, fmt.Sprintf("sysObjectID %s is not a ZTE device", info.SysObjectID))
+	}
+
+	version := parser.ParseVersion(info.SysDescr)
+	info.Model = version.Model
+	if version.Version != "" {
+		info.SoftwareVersion = version.Version
+		info.Source = model.FirmwareSourceSNMP
+		return nil
+	}
+	return fmt.Errorf("no software version in sysDescr %q", info.SysDescr)
+}
+
+// detectCLI reads "show version" into info
+func (u *SystemUsecase) detectCLI(ctx context.Context, info *model.SystemInfo) error {
+	version, err := u.cli.GetVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version.Version == "" {
+		return fmt.Errorf("no software version in \"show version\"")
+	}
+
+	info.SoftwareVersion = version.Version
+	info.Source = model.FirmwareSourceCLI
+	if info.Model == "" {
+		info.Model = version.Model
+	}
+	if info.Uptime == "" {
+		info.Uptime = version.Uptime
+	}
+	return nil
+}
+
+// Collect implements metrics.Collector
+func (u *SystemUsecase) Collect() []metrics.Family {
+	u.mu.RLock()
+	defer u.mu.RUnlock()
+
+	if u.info == nil {
+		return nil
+	}
+	known, mismatch := 0.0, 0.0
+	if u.info.KnownBuild {
+		known = 1
+	}
+	if u.info.ProfileMismatch {
+		mismatch = 1
+	}
+	olt := metrics.Labels{"olt": u.oltID}
+	return []metrics.Family{
+		gauge("c320_olt_firmware_info", "Software version of the OLT and the OID profile it is read with",
+			metrics.Sample{Labels: metrics.Labels{
+				"olt":              u.oltID,
+				"model":            u.info.Model,
+				"software_version": u.info.SoftwareVersion,
+				"firmware":         u.info.Firmware,
+				"source":           u.info.Source,
+			}, Value: 1}),
+		gauge("c320_olt_firmware_known_build", "Whether the software version of the OLT matches a verified OID profile",
+			metrics.Sample{Labels: olt, Value: known}),
+		gauge("c320_olt_firmware_profile_mismatch", "Whether the OLT reports a different firmware than the active OID profile",
+			metrics.Sample{Labels: olt, Value: mismatch}),
+	}
+}
+
+// sysUpTime returns the sysUpTime value of a GET response
+func sysUpTime(result *gosnmp.SnmpPacket) (uint32, error) {
+	if len(result.Variables) == 0 {
+		return 0, fmt.Errorf("empty sysUpTime response")
+	}
+	ticks, ok := result.Variables[0].Value.(uint32)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sysUpTime %s", result.Variables[0].Type)
+	}
+	return ticks, nil
+}
+
+// octetString returns the value of an OctetString variable, or "" for other types
+func octetString(pdu gosnmp.SnmpPDU) string {
+	if value, ok := pdu.Value.([]byte); ok {
+		return strings.TrimSpace(string(value))
+	}
+	return ""
+}
+
+// errorOrNotTried returns the message of err, or "not tried" for nil
+func errorOrNotTried(err error) string {
+	if err == nil {
+		return "not tried"
+	}
+	return err.Error()
+}
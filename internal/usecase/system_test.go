@@ -0,0 +1,184 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/repository"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestSystemUsecase_DetectSNMP(t *testing.T) {
+	tests := []struct {
+		fixture config.FirmwareVersion
+		version string
+	}{
+		{config.FirmwareV21, "V2.1.0"},
+		{config.FirmwareV22, "V2.2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.fixture), func(t *testing.T) {
+			agent, _ := newSimulatedSNMP(t, tt.fixture)
+			u := NewSystemUsecase("olt-1", config.FirmwareAuto, repository.NewSnmpRepository(agent.SnmpConfig()), nil)
+
+			if got := u.Firmware(context.Background()); got != tt.fixture {
+				t.Errorf("Firmware() = %s, want %s", got, tt.fixture)
+			}
+			info, err := u.GetSystemInfo(context.Background())
+			if err != nil {
+				t.Fatalf("GetSystemInfo() error = %v", err)
+			}
+			if info.Model != "ZXA10 C320" || info.SoftwareVersion != tt.version || info.Source != model.FirmwareSourceSNMP {
+				t.Errorf("GetSystemInfo() = %+v, want ZXA10 C320 %s from snmp", info, tt.version)
+			}
+			if !info.KnownBuild || info.Pinned || info.Warning != "" || info.ProfileName != config.OIDProfiles[tt.fixture].Name {
+				t.Errorf("GetSystemInfo() = %+v, want a known build without warning", info)
+			}
+			if !strings.HasPrefix(info.SysObjectID, ".1.3.6.1.4.1.3902.") || info.SysName == "" || info.Uptime == "" {
+				t.Errorf("GetSystemInfo() = %+v, want sysObjectID, sysName and uptime", info)
+			}
+		})
+	}
+}
+
+func TestSystemUsecase_DetectCLIFallback(t *testing.T) {
+	agent, _ := newSimulatedSNMP(t, config.FirmwareV21)
+	agent.Records().Set(sysDescrOID, gosnmp.OctetString, []byte("ZXA10 C320"))
+
+	server := simulator.NewCLIServer(simulator.CLIConfig{Version: "V2.2.0"}, simulator.NewState())
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	manager := repository.NewTelnetSessionManager(server.TelnetConfig())
+	t.Cleanup(func() {
+		manager.Close()
+		server.Close()
+	})
+
+	u := NewSystemUsecase("olt-1", config.FirmwareAuto, repository.NewSnmpRepository(agent.SnmpConfig()), manager)
+	info := u.Detect(context.Background())
+	if info.Source != model.FirmwareSourceCLI || info.SoftwareVersion != "V2.2.0" || info.Firmware != string(config.FirmwareV22) {
+		t.Errorf("Detect() = %+v, want V2.2.0 from the CLI", info)
+	}
+	if info.Model != "ZXA10 C320" || info.Warning != "" {
+		t.Errorf("Detect() = %+v, want model without warning", info)
+	}
+}
+
+func TestSystemUsecase_DetectWarnings(t *testing.T) {
+	tests := []struct {
+		name         string
+		configured   config.FirmwareVersion
+		sysDescr     string
+		sysObjectID  string
+		wantFirmware config.FirmwareVersion
+		wantKnown    bool
+		wantWarning  string
+	}{
+		{
+			name:         "unknown newer build",
+			configured:   config.FirmwareAuto,
+			sysDescr:     "ZXA10 C320, ZTE ZXA10 C320 Software, Version: V2.3.1",
+			wantFirmware: config.FirmwareV22,
+			wantWarning:  "unknown firmware build V2.3.1",
+		},
+		{
+			name:         "pinned firmware differs",
+			configured:   config.FirmwareV21,
+			sysDescr:     "ZXA10 C320, ZTE ZXA10 C320 Software, Version: V2.2.0",
+			wantFirmware: config.FirmwareV21,
+			wantKnown:    true,
+			wantWarning:  "firmware is pinned to v2.1 but the OLT runs V2.2.0",
+		},
+		{
+			name:         "not detected",
+			configured:   config.FirmwareAuto,
+			sysDescr:     "Linux router 5.10",
+			sysObjectID:  ".1.3.6.1.4.1.8072.3.2.10",
+			wantFirmware: config.GetCurrentFirmwareVersion(),
+			wantWarning:  "sysObjectID .1.3.6.1.4.1.8072.3.2.10 is not a ZTE device; firmware could not be detected",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agent, _ := newSimulatedSNMP(t, config.FirmwareV21)
+			agent.Records().Set(sysDescrOID, gosnmp.OctetString, []byte(tt.sysDescr))
+			if tt.sysObjectID != "" {
+				agent.Records().Set(sysObjectIDOID, gosnmp.ObjectIdentifier, tt.sysObjectID)
+			}
+
+			u := NewSystemUsecase("olt-1", tt.configured, repository.NewSnmpRepository(agent.SnmpConfig()), nil)
+			info := u.Detect(context.Background())
+			if info.Firmware != string(tt.wantFirmware) || info.KnownBuild != tt.wantKnown {
+				t.Errorf("Detect() = %+v, want firmware %s, known build %v", info, tt.wantFirmware, tt.wantKnown)
+			}
+			if !strings.Contains(info.Warning, tt.wantWarning) {
+				t.Errorf("Detect() warning = %q, want %q", info.Warning, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestSystemUsecase_CheckAfterReboot(t *testing.T) {
+	agent, _ := newSimulatedSNMP(t, config.FirmwareV21)
+	u := NewSystemUsecase("olt-1", config.FirmwareAuto, repository.NewSnmpRepository(agent.SnmpConfig()), nil)
+	var switched []config.FirmwareVersion
+	u.OnFirmwareChange(func(firmware config.FirmwareVersion) error {
+		switched = append(switched, firmware)
+		return nil
+	})
+	ctx := context.Background()
+
+	if got := u.Firmware(ctx); got != config.FirmwareV21 || len(switched) != 0 {
+		t.Fatalf("Firmware() = %s, switched %v, want v2.1 without a switch", got, switched)
+	}
+
+	// Uptime still increasing: nothing is detected again
+	agent.Records().Set(sysDescrOID, gosnmp.OctetString, []byte("ZXA10 C320, ZTE ZXA10 C320 Software, Version: V2.2.0"))
+	agent.Records().Set(sysUpTimeOID, gosnmp.TimeTicks, uint32(223456789))
+	u.Check(ctx)
+	if info, _ := u.GetSystemInfo(ctx); info.SoftwareVersion != "V2.1.0" {
+		t.Errorf("GetSystemInfo() after a poll = %+v, want the first detection", info)
+	}
+
+	// The OLT was upgraded and rebooted: the profile is switched to the new firmware
+	agent.Records().Set(sysUpTimeOID, gosnmp.TimeTicks, uint32(1000))
+	u.Check(ctx)
+	info, _ := u.GetSystemInfo(ctx)
+	if info.SoftwareVersion != "V2.2.0" || info.DetectedFirmware != string(config.FirmwareV22) {
+		t.Errorf("GetSystemInfo() after a reboot = %+v, want V2.2.0", info)
+	}
+	if info.Firmware != string(config.FirmwareV22) || info.ProfileMismatch || info.Warning != "" {
+		t.Errorf("GetSystemInfo() after a reboot = %+v, want the v2.2 profile without a mismatch", info)
+	}
+	if got := u.Firmware(ctx); got != config.FirmwareV22 || len(switched) != 1 || switched[0] != config.FirmwareV22 {
+		t.Errorf("Firmware() = %s, switched %v, want a single switch to v2.2", got, switched)
+	}
+}
+
+func TestSystemUsecase_CheckAfterRebootSwitchFails(t *testing.T) {
+	agent, _ := newSimulatedSNMP(t, config.FirmwareV21)
+	u := NewSystemUsecase("olt-1", config.FirmwareAuto, repository.NewSnmpRepository(agent.SnmpConfig()), nil)
+	u.OnFirmwareChange(func(config.FirmwareVersion) error { return errors.New("config rejected") })
+	ctx := context.Background()
+	u.Firmware(ctx)
+
+	agent.Records().Set(sysDescrOID, gosnmp.OctetString, []byte("ZXA10 C320, ZTE ZXA10 C320 Software, Version: V2.2.0"))
+	agent.Records().Set(sysUpTimeOID, gosnmp.TimeTicks, uint32(1000))
+	u.Check(ctx)
+
+	info, _ := u.GetSystemInfo(ctx)
+	if info.Firmware != string(config.FirmwareV21) || !info.ProfileMismatch || !strings.Contains(info.Warning, "config rejected") {
+		t.Errorf("GetSystemInfo() after a failed switch = %+v, want the v2.1 profile with a mismatch", info)
+	}
+	if got := u.Firmware(ctx); got != config.FirmwareV21 {
+		t.Errorf("Firmware() = %s, want v2.1 after a failed switch", got)
+	}
+}
@@ -22,24 +22,27 @@ type TrafficUsecaseInterface interface {
 	// TCONT operations
 	GetONUTCONT(ctx context.Context, ponPort string, onuID int, tcontID int) (*model.TCONTInfo, error)
 	ConfigureTCONT(ctx context.Context, req model.TCONTConfigRequest) (*model.TCONTConfigResponse, error)
-	DeleteTCONT(ctx context.Context, ponPort string, onuID int, tcontID int) error
+	DeleteTCONT(ctx context.Context, ponPort string, onuID int, tcontID int) (*model.PreChangeSnapshot, error)
 
 	// GEMPort operations
 	ConfigureGEMPort(ctx context.Context, req model.GEMPortConfigRequest) (*model.GEMPortConfigResponse, error)
-	DeleteGEMPort(ctx context.Context, ponPort string, onuID int, gemportID int) error
+	DeleteGEMPort(ctx context.Context, ponPort string, onuID int, gemportID int) (*model.PreChangeSnapshot, error)
 }
 
 // TrafficUsecase implements the traffic profile usecase interface
 type TrafficUsecase struct {
 	telnetSessionManager *repository.TelnetSessionManager
 	config               *config.Config
+	snapshots            PreChangeSnapshotter
 }
 
-// NewTrafficUsecase creates a new traffic usecase instance
-func NewTrafficUsecase(telnetSessionManager *repository.TelnetSessionManager, cfg *config.Config) TrafficUsecaseInterface {
+// NewTrafficUsecase creates a new traffic usecase instance.
+// If snapshots is set, ONUs are backed up before a T-CONT or GEM port is deleted.
+func NewTrafficUsecase(telnetSessionManager *repository.TelnetSessionManager, cfg *config.Config, snapshots PreChangeSnapshotter) TrafficUsecaseInterface {
 	return &TrafficUsecase{
 		telnetSessionManager: telnetSessionManager,
 		config:               cfg,
+		snapshots:            snapshots,
 	}
 }
 
@@ -258,8 +261,8 @@ func (u *TrafficUsecase) ConfigureTCONT(ctx context.Context, req model.TCONTConf
 	return response, nil
 }
 
-// DeleteTCONT deletes T-CONT from an ONU
-func (u *TrafficUsecase) DeleteTCONT(ctx context.Context, ponPort string, onuID int, tcontID int) error {
+// DeleteTCONT deletes T-CONT from an ONU and returns the snapshot taken before (nil if snapshots are disabled)
+func (u *TrafficUsecase) DeleteTCONT(ctx context.Context, ponPort string, onuID int, tcontID int) (*model.PreChangeSnapshot, error) {
 	log.Info().
 		Str("pon_port", ponPort).
 		Int("onu_id", onuID).
@@ -268,18 +271,24 @@ func (u *TrafficUsecase) DeleteTCONT(ctx context.Context, ponPort string, onuID
 
 	// Validate inputs
 	if err := validatePONPort(ponPort); err != nil {
-		return fmt.Errorf("invalid PON port: %w", err)
+		return nil, fmt.Errorf("invalid PON port: %w", err)
 	}
 
 	if err := validateONUID(onuID); err != nil {
-		return fmt.Errorf("invalid ONU ID: %w", err)
+		return nil, fmt.Errorf("invalid ONU ID: %w", err)
 	}
 
 	if err := validateTCONTID(tcontID); err != nil {
-		return fmt.Errorf("invalid TCONT ID: %w", err)
+		return nil, fmt.Errorf("invalid TCONT ID: %w", err)
 	}
 
-	err := u.telnetSessionManager.DeleteTCONT(ctx, ponPort, onuID, tcontID)
+	// Snapshot the ONU so the deletion can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, ponPort, onuID, OperationDeleteTCONT)
+	if err != nil {
+		return nil, err
+	}
+
+	err = u.telnetSessionManager.DeleteTCONT(ctx, ponPort, onuID, tcontID)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -287,7 +296,7 @@ func (u *TrafficUsecase) DeleteTCONT(ctx context.Context, ponPort string, onuID
 			Int("onu_id", onuID).
 			Int("tcont_id", tcontID).
 			Msg("Failed to delete TCONT")
-		return err
+		return snapshot, err
 	}
 
 	log.Info().
@@ -296,7 +305,7 @@ func (u *TrafficUsecase) DeleteTCONT(ctx context.Context, ponPort string, onuID
 		Int("tcont_id", tcontID).
 		Msg("TCONT deleted successfully")
 
-	return nil
+	return snapshot, nil
 }
 
 // ConfigureGEMPort configures GEM port for an ONU
@@ -334,8 +343,8 @@ func (u *TrafficUsecase) ConfigureGEMPort(ctx context.Context, req model.GEMPort
 	return response, nil
 }
 
-// DeleteGEMPort deletes GEM port from an ONU
-func (u *TrafficUsecase) DeleteGEMPort(ctx context.Context, ponPort string, onuID int, gemportID int) error {
+// DeleteGEMPort deletes GEM port from an ONU and returns the snapshot taken before (nil if snapshots are disabled)
+func (u *TrafficUsecase) DeleteGEMPort(ctx context.Context, ponPort string, onuID int, gemportID int) (*model.PreChangeSnapshot, error) {
 	log.Info().
 		Str("pon_port", ponPort).
 		Int("onu_id", onuID).
@@ -344,18 +353,24 @@ func (u *TrafficUsecase) DeleteGEMPort(ctx context.Context, ponPort string, onuI
 
 	// Validate inputs
 	if err := validatePONPort(ponPort); err != nil {
-		return fmt.Errorf("invalid PON port: %w", err)
+		return nil, fmt.Errorf("invalid PON port: %w", err)
 	}
 
 	if err := validateONUID(onuID); err != nil {
-		return fmt.Errorf("invalid ONU ID: %w", err)
+		return nil, fmt.Errorf("invalid ONU ID: %w", err)
 	}
 
 	if err := validateGEMPortID(gemportID); err != nil {
-		return fmt.Errorf("invalid GEM port ID: %w", err)
+		return nil, fmt.Errorf("invalid GEM port ID: %w", err)
+	}
+
+	// Snapshot the ONU so the deletion can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, ponPort, onuID, OperationDeleteGEMPort)
+	if err != nil {
+		return nil, err
 	}
 
-	err := u.telnetSessionManager.DeleteGEMPort(ctx, ponPort, onuID, gemportID)
+	err = u.telnetSessionManager.DeleteGEMPort(ctx, ponPort, onuID, gemportID)
 	if err != nil {
 		log.Error().
 			Err(err).
@@ -363,7 +378,7 @@ func (u *TrafficUsecase) DeleteGEMPort(ctx context.Context, ponPort string, onuI
 			Int("onu_id", onuID).
 			Int("gemport_id", gemportID).
 			Msg("Failed to delete GEM port")
-		return err
+		return snapshot, err
 	}
 
 	log.Info().
@@ -372,7 +387,7 @@ func (u *TrafficUsecase) DeleteGEMPort(ctx context.Context, ponPort string, onuI
 		Int("gemport_id", gemportID).
 		Msg("GEM port deleted successfully")
 
-	return nil
+	return snapshot, nil
 }
 
 // Validation functions
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestTrafficUsecase_DBAProfiles(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewTrafficUsecase(manager, nil, nil)
+	ctx := context.Background()
+
+	resp, err := uc.CreateDBAProfile(ctx, model.DBAProfileRequest{Name: "UP-100M", Type: 3, AssuredBandwidth: 20480, MaxBandwidth: 102400})
+	if err != nil || !resp.Success {
+		t.Fatalf("CreateDBAProfile() = %+v, %v", resp, err)
+	}
+
+	profile, err := uc.GetDBAProfile(ctx, "UP-100M")
+	if err != nil {
+		t.Fatalf("GetDBAProfile() error = %v", err)
+	}
+	if profile.Type != 3 || profile.AssuredBandwidth != 20480 || profile.MaxBandwidth != 102400 {
+		t.Errorf("GetDBAProfile() = %+v", profile)
+	}
+
+	resp, err = uc.ModifyDBAProfile(ctx, model.DBAProfileRequest{Name: "UP-100M", Type: 1, FixedBandwidth: 51200})
+	if err != nil || !resp.Success {
+		t.Fatalf("ModifyDBAProfile() = %+v, %v", resp, err)
+	}
+	if p, _ := server.State().DBAProfile("UP-100M"); p.Type != 1 || p.Fixed != 51200 {
+		t.Errorf("DBA profile after modify = %+v", p)
+	}
+
+	profiles, err := uc.GetAllDBAProfiles(ctx)
+	if err != nil {
+		t.Fatalf("GetAllDBAProfiles() error = %v", err)
+	}
+	if len(profiles) != 3 {
+		t.Errorf("GetAllDBAProfiles() = %+v, want 3 profiles", profiles)
+	}
+
+	// UP-10M is used by the T-CONTs of the demo ONUs
+	if err := uc.DeleteDBAProfile(ctx, "UP-10M"); err == nil {
+		t.Error("DeleteDBAProfile() of a profile in use succeeded")
+	}
+	if err := uc.DeleteDBAProfile(ctx, "UP-100M"); err != nil {
+		t.Fatalf("DeleteDBAProfile() error = %v", err)
+	}
+	if _, err := uc.GetDBAProfile(ctx, "UP-100M"); err == nil {
+		t.Error("GetDBAProfile() of a deleted profile succeeded")
+	}
+}
+
+func TestTrafficUsecase_TCONTAndGEMPort(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewTrafficUsecase(manager, nil, nil)
+	ctx := context.Background()
+
+	resp, err := uc.ConfigureTCONT(ctx, model.TCONTConfigRequest{PONPort: "1/1/1", ONUID: 1, TCONTID: 2, Name: "TCONT_VOIP", Profile: "UP-50M"})
+	if err != nil || !resp.Success {
+		t.Fatalf("ConfigureTCONT() = %+v, %v", resp, err)
+	}
+	gem, err := uc.ConfigureGEMPort(ctx, model.GEMPortConfigRequest{PONPort: "1/1/1", ONUID: 1, GEMPortID: 2, Name: "GEM_VOIP", TCONTID: 2, Queue: 2})
+	if err != nil || !gem.Success {
+		t.Fatalf("ConfigureGEMPort() = %+v, %v", gem, err)
+	}
+
+	tcont, err := uc.GetONUTCONT(ctx, "1/1/1", 1, 2)
+	if err != nil {
+		t.Fatalf("GetONUTCONT() error = %v", err)
+	}
+	if tcont.Name != "TCONT_VOIP" || tcont.Profile != "UP-50M" {
+		t.Errorf("GetONUTCONT() = %+v", tcont)
+	}
+
+	// A T-CONT cannot be deleted while a GEM port uses it
+	if _, err := uc.DeleteTCONT(ctx, "1/1/1", 1, 2); err == nil {
+		t.Error("DeleteTCONT() of a T-CONT in use succeeded")
+	}
+	if _, err := uc.DeleteGEMPort(ctx, "1/1/1", 1, 2); err != nil {
+		t.Fatalf("DeleteGEMPort() error = %v", err)
+	}
+	if _, err := uc.DeleteTCONT(ctx, "1/1/1", 1, 2); err != nil {
+		t.Fatalf("DeleteTCONT() error = %v", err)
+	}
+
+	onu, _ := server.State().ONU("1/1/1", 1)
+	if len(onu.TCONTs) != 1 || len(onu.GEMPorts) != 1 {
+		t.Errorf("ONU after deletes = %+v", onu)
+	}
+	if _, err := uc.GetONUTCONT(ctx, "1/1/1", 1, 2); err == nil {
+		t.Error("GetONUTCONT() of a deleted T-CONT succeeded")
+	}
+
+	resp, err = uc.ConfigureTCONT(ctx, model.TCONTConfigRequest{PONPort: "1/1/1", ONUID: 1, TCONTID: 3, Profile: "MISSING"})
+	if err == nil && resp.Success {
+		t.Error("ConfigureTCONT() with an unknown DBA profile succeeded")
+	}
+}
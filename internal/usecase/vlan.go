@@ -17,20 +17,23 @@ type VLANUsecaseInterface interface {
 	GetAllServicePorts(ctx context.Context) ([]model.ONUVLANInfo, error)
 	ConfigureVLAN(ctx context.Context, req model.VLANConfigRequest) (*model.VLANConfigResponse, error)
 	ModifyVLAN(ctx context.Context, req model.VLANConfigRequest) (*model.VLANConfigResponse, error)
-	DeleteVLAN(ctx context.Context, ponPort string, onuID int) error
+	DeleteVLAN(ctx context.Context, ponPort string, onuID int) (*model.PreChangeSnapshot, error)
 }
 
 // VLANUsecase implements the VLAN usecase interface
 type VLANUsecase struct {
 	telnetSessionManager *repository.TelnetSessionManager
 	config               *config.Config
+	snapshots            PreChangeSnapshotter
 }
 
-// NewVLANUsecase creates a new VLAN usecase instance
-func NewVLANUsecase(telnetSessionManager *repository.TelnetSessionManager, cfg *config.Config) VLANUsecaseInterface {
+// NewVLANUsecase creates a new VLAN usecase instance.
+// If snapshots is set, ONUs are backed up before their VLAN is modified or deleted.
+func NewVLANUsecase(telnetSessionManager *repository.TelnetSessionManager, cfg *config.Config, snapshots PreChangeSnapshotter) VLANUsecaseInterface {
 	return &VLANUsecase{
 		telnetSessionManager: telnetSessionManager,
 		config:               cfg,
+		snapshots:            snapshots,
 	}
 }
 
@@ -157,6 +160,12 @@ func (u *VLANUsecase) ModifyVLAN(ctx context.Context, req model.VLANConfigReques
 	// Update service port ID in request
 	req.ServicePortID = existingVLAN.ServicePortID
 
+	// Snapshot the ONU so the modification can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, req.PONPort, req.ONUID, OperationModifyVLAN)
+	if err != nil {
+		return nil, err
+	}
+
 	// Configure (update) VLAN via telnet
 	response, err := u.telnetSessionManager.ConfigureONUVLAN(ctx, req)
 	if err != nil {
@@ -167,6 +176,7 @@ func (u *VLANUsecase) ModifyVLAN(ctx context.Context, req model.VLANConfigReques
 			Msg("Failed to modify VLAN")
 		return nil, err
 	}
+	response.Snapshot = snapshot
 
 	log.Info().
 		Str("pon_port", req.PONPort).
@@ -177,8 +187,8 @@ func (u *VLANUsecase) ModifyVLAN(ctx context.Context, req model.VLANConfigReques
 	return response, nil
 }
 
-// DeleteVLAN removes VLAN configuration for an ONU
-func (u *VLANUsecase) DeleteVLAN(ctx context.Context, ponPort string, onuID int) error {
+// DeleteVLAN removes VLAN configuration for an ONU and returns the snapshot taken before (nil if snapshots are disabled)
+func (u *VLANUsecase) DeleteVLAN(ctx context.Context, ponPort string, onuID int) (*model.PreChangeSnapshot, error) {
 	log.Info().
 		Str("pon_port", ponPort).
 		Int("onu_id", onuID).
@@ -186,22 +196,28 @@ func (u *VLANUsecase) DeleteVLAN(ctx context.Context, ponPort string, onuID int)
 
 	// Validate inputs
 	if err := validatePONPort(ponPort); err != nil {
-		return fmt.Errorf("invalid PON port: %w", err)
+		return nil, fmt.Errorf("invalid PON port: %w", err)
 	}
 
 	if err := validateONUID(onuID); err != nil {
-		return fmt.Errorf("invalid ONU ID: %w", err)
+		return nil, fmt.Errorf("invalid ONU ID: %w", err)
+	}
+
+	// Snapshot the ONU so the deletion can be undone
+	snapshot, err := takePreChangeSnapshot(ctx, u.snapshots, ponPort, onuID, OperationDeleteVLAN)
+	if err != nil {
+		return nil, err
 	}
 
 	// Delete VLAN via telnet
-	err := u.telnetSessionManager.DeleteONUVLAN(ctx, ponPort, onuID)
+	err = u.telnetSessionManager.DeleteONUVLAN(ctx, ponPort, onuID)
 	if err != nil {
 		log.Error().
 			Err(err).
 			Str("pon_port", ponPort).
 			Int("onu_id", onuID).
 			Msg("Failed to delete VLAN")
-		return err
+		return snapshot, err
 	}
 
 	log.Info().
@@ -209,7 +225,7 @@ func (u *VLANUsecase) DeleteVLAN(ctx context.Context, ponPort string, onuID int)
 		Int("onu_id", onuID).
 		Msg("VLAN deleted successfully")
 
-	return nil
+	return snapshot, nil
 }
 
 // validateVLANRequest validates VLAN configuration request
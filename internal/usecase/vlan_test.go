@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/s4lfanet/go-api-c320/internal/model"
+	"github.com/s4lfanet/go-api-c320/internal/simulator"
+)
+
+func TestVLANUsecase_Lifecycle(t *testing.T) {
+	server, manager := newSimulatedOLT(t, simulator.NewDemoState())
+	uc := NewVLANUsecase(manager, nil, nil)
+	ctx := context.Background()
+	state := server.State()
+
+	if _, err := uc.ConfigureVLAN(ctx, model.VLANConfigRequest{PONPort: "1/1/1", ONUID: 2, SVLAN: 200, CVLAN: 20, VLANMode: "translation"}); err != nil {
+		t.Fatalf("ConfigureVLAN() error = %v", err)
+	}
+	if _, err := uc.ConfigureVLAN(ctx, model.VLANConfigRequest{PONPort: "1/1/1", ONUID: 3, SVLAN: 300, VLANMode: "tag"}); err != nil {
+		t.Fatalf("ConfigureVLAN() error = %v", err)
+	}
+
+	info, err := uc.GetONUVLAN(ctx, "1/1/1", 2)
+	if err != nil {
+		t.Fatalf("GetONUVLAN() error = %v", err)
+	}
+	if info.ServicePortID != 1 || info.SVLAN != 200 || info.CVLAN != 20 || info.VLANMode != "vlan-translation" {
+		t.Errorf("GetONUVLAN() = %+v", info)
+	}
+
+	all, err := uc.GetAllServicePorts(ctx)
+	if err != nil {
+		t.Fatalf("GetAllServicePorts() error = %v", err)
+	}
+	if len(all) != 2 || all[1].PONPort != "1/1/1" || all[1].ONUID != 3 || all[1].SVLAN != 300 || all[1].VLANMode != "tag-transform" {
+		t.Errorf("GetAllServicePorts() = %+v", all)
+	}
+
+	resp, err := uc.ModifyVLAN(ctx, model.VLANConfigRequest{PONPort: "1/1/1", ONUID: 2, SVLAN: 250, CVLAN: 25, VLANMode: "translation"})
+	if err != nil {
+		t.Fatalf("ModifyVLAN() error = %v", err)
+	}
+	if resp.ServicePortID != 1 {
+		t.Errorf("ModifyVLAN() service port = %d, want 1", resp.ServicePortID)
+	}
+	if ports := state.ServicePorts(); ports[0].VLAN != 250 || ports[0].UserVLAN != 25 {
+		t.Errorf("service port 1 = %+v", ports[0])
+	}
+
+	if _, err := uc.DeleteVLAN(ctx, "1/1/1", 3); err != nil {
+		t.Fatalf("DeleteVLAN() error = %v", err)
+	}
+	if ports := state.ServicePorts(); len(ports) != 1 || ports[0].ONUID != 2 {
+		t.Errorf("ServicePorts() = %+v, want only ONU 2", ports)
+	}
+
+	if _, err := uc.ModifyVLAN(ctx, model.VLANConfigRequest{PONPort: "1/1/1", ONUID: 3, SVLAN: 300, VLANMode: "tag"}); err == nil {
+		t.Error("ModifyVLAN() without a service port succeeded")
+	}
+	if _, err := uc.ConfigureVLAN(ctx, model.VLANConfigRequest{PONPort: "1/1/1", ONUID: 42, SVLAN: 100, VLANMode: "tag"}); err == nil {
+		t.Error("ConfigureVLAN() on a missing ONU succeeded")
+	}
+}
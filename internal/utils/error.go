@@ -47,6 +47,23 @@ func HandleError(w http.ResponseWriter, err error) {
 				Msg("Resource not found")
 			ErrorNotFound(w, appErr)
 
+		case apperrors.ErrorTypeUnauthorized: // Unauthorized error -> 401 Unauthorized
+			// Log as WARN - missing or rejected credentials
+			log.Warn().
+				Str("error_type", string(appErr.Type)).
+				Str("message", appErr.Message).
+				Msg("Unauthorized request")
+			ErrorUnauthorized(w, appErr)
+
+		case apperrors.ErrorTypeForbidden: // Forbidden error -> 403 Forbidden
+			// Log as WARN - valid credentials without the required scope or board/PON
+			log.Warn().
+				Str("error_type", string(appErr.Type)).
+				Str("message", appErr.Message).
+				Interface("details", appErr.Details).
+				Msg("Forbidden request")
+			ErrorForbidden(w, appErr)
+
 		case apperrors.ErrorTypeSNMP, apperrors.ErrorTypeRedis, apperrors.ErrorTypeInternal: // Systems errors -> 500 Internal Error
 			// Log as ERROR - real system error (already logged upstream, but log here for completeness)
 			log.Error().
@@ -113,3 +130,23 @@ func ErrorNotFound(w http.ResponseWriter, err error) {
 	}
 	SendJSONResponse(w, http.StatusNotFound, webResponse)
 }
+
+// ErrorUnauthorized is a helper function to send a 401 Unauthorized response
+func ErrorUnauthorized(w http.ResponseWriter, err error) {
+	webResponse := ErrorResponse{
+		Code:    http.StatusUnauthorized,
+		Status:  "Unauthorized",
+		Message: err.Error(),
+	}
+	SendJSONResponse(w, http.StatusUnauthorized, webResponse)
+}
+
+// ErrorForbidden is a helper function to send a 403 Forbidden response
+func ErrorForbidden(w http.ResponseWriter, err error) {
+	webResponse := ErrorResponse{
+		Code:    http.StatusForbidden,
+		Status:  "Forbidden",
+		Message: err.Error(),
+	}
+	SendJSONResponse(w, http.StatusForbidden, webResponse)
+}
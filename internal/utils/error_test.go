@@ -258,6 +258,36 @@ func TestHandleError_ConfigError(t *testing.T) {
 	}
 }
 
+func TestHandleError_AuthErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"unauthorized", apperrors.NewUnauthorizedError("API key is required"), http.StatusUnauthorized},
+		{"forbidden", apperrors.NewForbiddenError("missing scope", nil), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			HandleError(rr, tt.err)
+
+			if status := rr.Code; status != tt.want {
+				t.Errorf("Status code tidak sesuai: got %v want %v", status, tt.want)
+			}
+
+			var response ErrorResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Errorf("Gagal mendecode respons JSON: %v", err)
+			}
+			if int(response.Code) != tt.want {
+				t.Errorf("Response code tidak sesuai: got %v want %v", response.Code, tt.want)
+			}
+		})
+	}
+}
+
 func TestHandleError_UnknownErrorType(t *testing.T) {
 	rr := httptest.NewRecorder()
 	// Create AppError with unknown type
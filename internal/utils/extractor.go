@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 	"strconv"
@@ -77,6 +78,10 @@ func ExtractSerialNumber(oidValue interface{}) string {
 		}
 		data = []byte(v)
 	case []byte:
+		// V2.2 reports "1,<serial>" as an OCTET STRING, which gosnmp decodes to bytes
+		if bytes.HasPrefix(v, []byte("1,")) {
+			return ExtractSerialNumber(string(v))
+		}
 		data = v
 	default:
 		return ""
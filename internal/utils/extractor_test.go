@@ -85,6 +85,7 @@ func TestExtractSerialNumber(t *testing.T) {
 		// 8+ bytes: first 4 = vendor ID, bytes 4-8 = hex encoded serial
 		{[]byte{0x5A, 0x54, 0x45, 0x47, 0xD8, 0x24, 0xCD, 0xF3}, "ZTEGD824CDF3"}, // Real GPON format
 		{[]byte("TEST1234"), "TEST31323334"},                                     // 8 ASCII bytes: "TEST" + hex("1234")
+		{[]byte("1,ZTEGC8F10001"), "ZTEGC8F10001"},                               // V2.2 "1," prefixed OCTET STRING
 
 		// Short byte slices (< 8 bytes) - return as string if printable
 		{[]byte("ABCD"), "ABCD"}, // 4 bytes, printable ASCII
@@ -0,0 +1,79 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"@often",
+	}
+
+	for _, expr := range tests {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) expected error", expr)
+		}
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	base := time.Date(2026, 1, 14, 10, 30, 15, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 1, 14, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 1, 14, 10, 45, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2026, 1, 15, 2, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 1, 14, 11, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2026, 1, 18, 0, 0, 0, 0, time.UTC)},
+		{"30 3 * * 7", time.Date(2026, 1, 18, 3, 30, 0, 0, time.UTC)},
+		{"0 1 * * 1-5", time.Date(2026, 1, 15, 1, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 10,20 * *", time.Date(2026, 1, 20, 12, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: either matches (15th, or the next Friday)
+		{"0 0 15 * 5", time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := s.Next(base); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchedule_NextIsStrictlyAfter(t *testing.T) {
+	s, err := Parse("0 2 * * *")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	at := time.Date(2026, 1, 14, 2, 0, 0, 0, time.UTC)
+	if got, want := s.Next(at), at.AddDate(0, 0, 1); !got.Equal(want) {
+		t.Errorf("Next() = %v, want %v", got, want)
+	}
+	if s.String() != "0 2 * * *" {
+		t.Errorf("String() = %q", s.String())
+	}
+}
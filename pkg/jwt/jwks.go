@@ -0,0 +1,276 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Key is a public key of a JSON Web Key Set
+type Key struct {
+	ID        string           // "kid", may be empty
+	Algorithm string           // "alg", empty if the key does not restrict the algorithm
+	Public    crypto.PublicKey // *rsa.PublicKey, *ecdsa.PublicKey or ed25519.PublicKey
+}
+
+// KeySet is a parsed JSON Web Key Set (RFC 7517)
+type KeySet struct {
+	Keys []Key
+}
+
+// jwk holds the members of a JSON Web Key used for RSA, EC and OKP (Ed25519) public keys
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseKeySet parses a JSON Web Key Set. Encryption keys and key types other than RSA, EC and
+// Ed25519 are skipped, as identity providers publish them alongside the signing keys.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	set := &KeySet{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		public, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %d (kid %q): %w", i, k.Kid, err)
+		}
+		if public == nil {
+			continue
+		}
+		set.Keys = append(set.Keys, Key{ID: k.Kid, Algorithm: k.Alg, Public: public})
+	}
+
+	if len(set.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS contains no RSA, EC or Ed25519 signing keys")
+	}
+	return set, nil
+}
+
+// publicKey decodes the key material; unsupported key types yield nil
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("n: %w", err)
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("e: %w", err)
+		}
+		if n.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must have at least 2048 bits, got %d", n.BitLen())
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("unsupported RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curve, ok := curves[k.Crv]
+		if !ok {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("x must be a %d byte Ed25519 key", ed25519.PublicKeySize)
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, nil
+}
+
+// curves maps the JWK "crv" names to the curves of ES256, ES384 and ES512
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+// decodeBigInt decodes a base64url encoded big-endian integer
+func decodeBigInt(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, fmt.Errorf("missing")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+// Lookup returns the keys that may have signed a token with the given key ID and algorithm.
+// Tokens without a key ID are checked against every key of the algorithm.
+// A KeySet is a KeyProvider with fixed keys.
+func (s *KeySet) Lookup(_ context.Context, kid, alg string) ([]Key, error) {
+	var keys []Key
+	for _, key := range s.Keys {
+		if kid != "" && key.ID != kid {
+			continue
+		}
+		if key.Algorithm != "" && key.Algorithm != alg {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeyProvider returns the keys for verifying a token
+type KeyProvider interface {
+	Lookup(ctx context.Context, kid, alg string) ([]Key, error)
+}
+
+// Default reload intervals of a JWKS
+const (
+	DefaultJWKSMaxAge      = time.Hour        // Reload the key set after this time
+	DefaultJWKSMinInterval = 30 * time.Second // Do not reload more often for unknown key IDs
+)
+
+// maxJWKSSize limits the size of a key set document
+const maxJWKSSize = 1 << 20
+
+// JWKS is a KeyProvider that loads a key set from a file or an http(s) URL.
+// The set is reloaded after MaxAge, and when a token names an unknown key ID (the
+// provider rotated its keys), at most once per MinInterval.
+type JWKS struct {
+	Location    string        // File path or http(s) URL
+	Client      *http.Client  // Client for URLs; nil uses a client with a 10s timeout
+	MaxAge      time.Duration // Reload interval
+	MinInterval time.Duration // Minimum time between reloads
+
+	mu       sync.Mutex
+	set      *KeySet
+	loadedAt time.Time
+	now      func() time.Time // Clock, replaced in tests
+}
+
+// NewJWKS creates a key provider for a file path or an http(s) URL with the default reload intervals
+func NewJWKS(location string) *JWKS {
+	return &JWKS{
+		Location:    location,
+		MaxAge:      DefaultJWKSMaxAge,
+		MinInterval: DefaultJWKSMinInterval,
+		now:         time.Now,
+	}
+}
+
+// Load loads the key set now, e.g. to fail at startup if it is unreachable
+func (j *JWKS) Load(ctx context.Context) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.reload(ctx)
+}
+
+// Lookup returns the keys matching kid and alg, loading or reloading the key set as needed
+func (j *JWKS) Lookup(ctx context.Context, kid, alg string) ([]Key, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := j.now()
+	if j.set == nil || now.Sub(j.loadedAt) >= j.MaxAge {
+		if err := j.reload(ctx); err != nil && j.set == nil {
+			return nil, err // Keep using a stale set if the provider is unreachable
+		}
+	}
+
+	keys, _ := j.set.Lookup(ctx, kid, alg)
+	if len(keys) == 0 && kid != "" && now.Sub(j.loadedAt) >= j.MinInterval {
+		if err := j.reload(ctx); err != nil {
+			return nil, err
+		}
+		keys, _ = j.set.Lookup(ctx, kid, alg)
+	}
+	return keys, nil
+}
+
+// reload reads and parses the key set; the caller holds mu
+func (j *JWKS) reload(ctx context.Context) error {
+	data, err := j.read(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS from %s: %w", j.Location, err)
+	}
+	set, err := ParseKeySet(data)
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS from %s: %w", j.Location, err)
+	}
+
+	j.set = set
+	j.loadedAt = j.now()
+	return nil
+}
+
+// read returns the key set document of the file or URL
+func (j *JWKS) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.Location, "http://") && !strings.HasPrefix(j.Location, "https://") {
+		return os.ReadFile(j.Location)
+	}
+
+	client := j.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.Location, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
@@ -0,0 +1,324 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validation errors; the errors returned by Validator.Validate wrap one of them
+var (
+	ErrMalformed    = errors.New("malformed token")
+	ErrAlgorithm    = errors.New("unsupported signing algorithm")
+	ErrUnknownKey   = errors.New("no key found for token")
+	ErrSignature    = errors.New("invalid token signature")
+	ErrExpired      = errors.New("token has expired")
+	ErrNotYetValid  = errors.New("token is not valid yet")
+	ErrIssuer       = errors.New("invalid token issuer")
+	ErrAudience     = errors.New("invalid token audience")
+	ErrMissingClaim = errors.New("missing token claim")
+)
+
+// Algorithms lists the supported JWS algorithms. HMAC and "none" are rejected:
+// tokens are only accepted if signed with a key of the key set.
+var Algorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// hashes maps the algorithms to their digest; EdDSA signs the message itself
+var hashes = map[string]crypto.Hash{
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	"PS256": crypto.SHA256, "PS384": crypto.SHA384, "PS512": crypto.SHA512,
+	"ES256": crypto.SHA256, "ES384": crypto.SHA384, "ES512": crypto.SHA512,
+}
+
+// curveBits maps the ECDSA algorithms to the size of their curve (P-256, P-384 and P-521)
+var curveBits = map[string]int{"ES256": 256, "ES384": 384, "ES512": 521}
+
+// header is the JOSE header of a token
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Claims are the claims of a validated token
+type Claims map[string]interface{}
+
+// IsToken reports whether s has the shape of a compact JWS ("<header>.<payload>.<signature>", header starting with '{')
+func IsToken(s string) bool {
+	return strings.Count(s, ".") == 2 && strings.HasPrefix(s, "eyJ")
+}
+
+// Validator validates signed tokens against a key set and the expected issuer and audience
+type Validator struct {
+	Keys     KeyProvider
+	Issuer   string        // Required "iss"; empty accepts any issuer
+	Audience string        // Required entry of "aud"; empty accepts any audience
+	Leeway   time.Duration // Allowed clock skew for "exp" and "nbf"
+
+	now func() time.Time // Clock, replaced in tests
+}
+
+// NewValidator creates a validator for tokens of issuer for audience
+func NewValidator(keys KeyProvider, issuer, audience string, leeway time.Duration) *Validator {
+	return &Validator{Keys: keys, Issuer: issuer, Audience: audience, Leeway: leeway, now: time.Now}
+}
+
+// Validate checks the signature and the "exp", "nbf", "iss" and "aud" claims of a token and returns its claims.
+// Tokens without "exp" are rejected.
+func (v *Validator) Validate(ctx context.Context, token string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var hdr header
+	if err := decodeSegment(parts[0], &hdr); err != nil {
+		return nil, fmt.Errorf("%w: header: %v", ErrMalformed, err)
+	}
+	if !slices.Contains(Algorithms, hdr.Alg) {
+		return nil, fmt.Errorf("%w: %q", ErrAlgorithm, hdr.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %v", ErrMalformed, err)
+	}
+
+	keys, err := v.Keys.Lookup(ctx, hdr.Kid, hdr.Alg)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%w: kid %q, alg %s", ErrUnknownKey, hdr.Kid, hdr.Alg)
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if verify(hdr.Alg, key.Public, signed, signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, ErrSignature
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: payload: %v", ErrMalformed, err)
+	}
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validateClaims checks the registered claims of a token with a verified signature
+func (v *Validator) validateClaims(claims Claims) error {
+	now := v.now()
+
+	exp, ok := claims.Time("exp")
+	if !ok {
+		return fmt.Errorf("%w: exp", ErrMissingClaim)
+	}
+	if !now.Before(exp.Add(v.Leeway)) {
+		return ErrExpired
+	}
+	if nbf, ok := claims.Time("nbf"); ok && now.Add(v.Leeway).Before(nbf) {
+		return ErrNotYetValid
+	}
+
+	if v.Issuer != "" && claims.String("iss") != v.Issuer {
+		return fmt.Errorf("%w: %q", ErrIssuer, claims.String("iss"))
+	}
+	if v.Audience != "" {
+		found := false
+		for _, aud := range claims.Strings("aud") {
+			if aud == v.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %v", ErrAudience, claims["aud"])
+		}
+	}
+	return nil
+}
+
+// verify checks a signature with a public key; the key type must match the algorithm
+func verify(alg string, public crypto.PublicKey, signed, signature []byte) bool {
+	if alg == "EdDSA" {
+		key, ok := public.(ed25519.PublicKey)
+		return ok && ed25519.Verify(key, signed, signature)
+	}
+
+	hash := hashes[alg]
+	h := hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		key, ok := public.(*rsa.PublicKey)
+		return ok && rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil
+	case "PS":
+		key, ok := public.(*rsa.PublicKey)
+		return ok && rsa.VerifyPSS(key, hash, digest, signature, nil) == nil
+	case "ES":
+		key, ok := public.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		// JWS ECDSA signatures are R || S, each padded to the size of the curve
+		bits := key.Curve.Params().BitSize
+		size := (bits + 7) / 8
+		if bits != curveBits[alg] || len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
+
+// decodeSegment decodes a base64url encoded JSON segment of a token
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Lookup returns a claim by name. Dotted names address nested objects, e.g. "realm_access.roles".
+func (c Claims) Lookup(name string) (interface{}, bool) {
+	if value, ok := c[name]; ok {
+		return value, true
+	}
+
+	var value interface{} = map[string]interface{}(c)
+	for _, part := range strings.Split(name, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[part]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// String returns a string claim, or "" if it is missing or not a string
+func (c Claims) String(name string) string {
+	value, _ := c.Lookup(name)
+	s, _ := value.(string)
+	return s
+}
+
+// Strings returns a claim that is a list of strings; a single string is returned as a list of one.
+// Items that are not strings are skipped.
+func (c Claims) Strings(name string) []string {
+	value, _ := c.Lookup(name)
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// Time returns a NumericDate claim such as "exp"
+func (c Claims) Time(name string) (time.Time, bool) {
+	value, ok := c.Lookup(name)
+	if !ok {
+		return time.Time{}, false
+	}
+	seconds, ok := value.(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second))), true
+}
+
+// Sign creates a compact JWS of claims with an *rsa.PrivateKey, *ecdsa.PrivateKey or ed25519.PrivateKey,
+// e.g. to test the API against a local key set
+func Sign(alg, kid string, key crypto.PrivateKey, claims interface{}) (string, error) {
+	if !slices.Contains(Algorithms, alg) {
+		return "", fmt.Errorf("%w: %q", ErrAlgorithm, alg)
+	}
+
+	hdr, err := json.Marshal(header{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed := base64.RawURLEncoding.EncodeToString(hdr) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	var signature []byte
+	if alg == "EdDSA" {
+		k, ok := key.(ed25519.PrivateKey)
+		if !ok {
+			return "", fmt.Errorf("%s requires an Ed25519 key", alg)
+		}
+		signature = ed25519.Sign(k, []byte(signed))
+	} else {
+		hash := hashes[alg]
+		h := hash.New()
+		h.Write([]byte(signed))
+		digest := h.Sum(nil)
+
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			if alg[:2] == "PS" {
+				signature, err = rsa.SignPSS(rand.Reader, k, hash, digest, nil)
+			} else if alg[:2] == "RS" {
+				signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+			} else {
+				err = fmt.Errorf("%s requires an EC key", alg)
+			}
+		case *ecdsa.PrivateKey:
+			if alg[:2] != "ES" {
+				return "", fmt.Errorf("%s requires an RSA key", alg)
+			}
+			var r, s *big.Int
+			r, s, err = ecdsa.Sign(rand.Reader, k, digest)
+			if err == nil {
+				size := (k.Curve.Params().BitSize + 7) / 8
+				signature = make([]byte, 2*size)
+				r.FillBytes(signature[:size])
+				s.FillBytes(signature[size:])
+			}
+		default:
+			err = fmt.Errorf("unsupported key type %T", key)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
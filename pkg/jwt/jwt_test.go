@@ -0,0 +1,299 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testKeys holds one key of each supported type
+type testKeys struct {
+	rsa *rsa.PrivateKey
+	ec  *ecdsa.PrivateKey
+	ed  ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKeys{rsa: rsaKey, ec: ecKey, ed: edKey}
+}
+
+// jwks returns the JSON Web Key Set of the public keys, with kids "rsa", "ec" and "ed"
+func (k testKeys) jwks() []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	doc := map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "use": "sig", "n": b64(k.rsa.N.Bytes()), "e": b64(big.NewInt(int64(k.rsa.E)).Bytes())},
+		{"kty": "EC", "kid": "ec", "alg": "ES256", "crv": "P-256", "x": b64(k.ec.X.Bytes()), "y": b64(k.ec.Y.Bytes())},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "AQAB", "e": "AQAB"},
+		{"kty": "oct", "kid": "hmac", "k": "c2VjcmV0"},
+	}}
+	data, _ := json.Marshal(doc)
+	return data
+}
+
+func sign(t *testing.T, alg, kid string, key crypto.PrivateKey, claims map[string]interface{}) string {
+	t.Helper()
+	token, err := Sign(alg, kid, key, claims)
+	if err != nil {
+		t.Fatalf("Sign(%s) error = %v", alg, err)
+	}
+	return token
+}
+
+func TestParseKeySet(t *testing.T) {
+	keys := newTestKeys(t)
+
+	set, err := ParseKeySet(keys.jwks())
+	if err != nil {
+		t.Fatalf("ParseKeySet() error = %v", err)
+	}
+	if len(set.Keys) != 3 {
+		t.Fatalf("ParseKeySet() = %d keys, want the 3 signing keys", len(set.Keys))
+	}
+
+	tests := []struct {
+		kid, alg string
+		want     int
+	}{
+		{"rsa", "RS256", 1},
+		{"ec", "ES256", 1},
+		{"ec", "ES384", 0}, // The key is restricted to ES256
+		{"", "EdDSA", 2},   // Keys without "alg" match any algorithm
+		{"missing", "RS256", 0},
+	}
+	for _, tt := range tests {
+		if got, _ := set.Lookup(context.Background(), tt.kid, tt.alg); len(got) != tt.want {
+			t.Errorf("Lookup(%q, %s) = %d keys, want %d", tt.kid, tt.alg, len(got), tt.want)
+		}
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"keys":[]}`,
+		`{"keys":[{"kty":"oct","k":"c2VjcmV0"}]}`,
+		`{"keys":[{"kty":"RSA","n":"AQAB","e":"AQAB"}]}`,
+		`{"keys":[{"kty":"EC","crv":"P-256","x":"AQ","y":"AQ"}]}`,
+		`{"keys":[{"kty":"EC","crv":"secp256k1","x":"AQ","y":"AQ"}]}`,
+	}
+	for _, data := range invalid {
+		if _, err := ParseKeySet([]byte(data)); err == nil {
+			t.Errorf("ParseKeySet(%s) error = nil", data)
+		}
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	keys := newTestKeys(t)
+	set, err := ParseKeySet(keys.jwks())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	v := NewValidator(set, "https://sso.example.net/realms/noc", "go-api-c320", time.Minute)
+	v.now = func() time.Time { return now }
+
+	claims := func(modify func(map[string]interface{})) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":                "https://sso.example.net/realms/noc",
+			"aud":                []string{"account", "go-api-c320"},
+			"sub":                "2b7c",
+			"preferred_username": "alice",
+			"exp":                now.Add(5 * time.Minute).Unix(),
+			"realm_access":       map[string]interface{}{"roles": []string{"noc-admins"}},
+		}
+		if modify != nil {
+			modify(c)
+		}
+		return c
+	}
+
+	valid := sign(t, "RS256", "rsa", keys.rsa, claims(nil))
+	tampered := strings.Split(valid, ".")
+	tampered[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"mallory","exp":9999999999}`))
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"RS256", valid, nil},
+		{"PS384", sign(t, "PS384", "rsa", keys.rsa, claims(nil)), nil},
+		{"ES256", sign(t, "ES256", "ec", keys.ec, claims(nil)), nil},
+		{"EdDSA", sign(t, "EdDSA", "ed", keys.ed, claims(nil)), nil},
+		{"no kid", sign(t, "EdDSA", "", keys.ed, claims(nil)), nil},
+		{"audience string", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["aud"] = "go-api-c320" })), nil},
+		{"expired within leeway", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["exp"] = now.Add(-30 * time.Second).Unix() })), nil},
+		{"expired", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["exp"] = now.Add(-2 * time.Minute).Unix() })), ErrExpired},
+		{"no exp", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { delete(c, "exp") })), ErrMissingClaim},
+		{"not yet valid", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["nbf"] = now.Add(2 * time.Minute).Unix() })), ErrNotYetValid},
+		{"other issuer", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["iss"] = "https://evil.example.net" })), ErrIssuer},
+		{"other audience", sign(t, "ES256", "ec", keys.ec, claims(func(c map[string]interface{}) { c["aud"] = "grafana" })), ErrAudience},
+		{"tampered payload", strings.Join(tampered, "."), ErrSignature},
+		{"wrong key", sign(t, "RS256", "rsa", mustRSAKey(t), claims(nil)), ErrSignature},
+		{"key of other type", swapKid(t, sign(t, "ES256", "ec", keys.ec, claims(nil)), "ed"), ErrSignature},
+		{"unknown kid", sign(t, "RS256", "rotated", keys.rsa, claims(nil)), ErrUnknownKey},
+		{"alg none", unsigned(`{"alg":"none"}`, claims(nil)), ErrAlgorithm},
+		{"alg HS256", unsigned(`{"alg":"HS256","kid":"hmac"}`, claims(nil)), ErrAlgorithm},
+		{"not a token", "c320_0123456789abcdef_secret", ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := v.Validate(context.Background(), tt.token)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				if got.String("preferred_username") != "alice" {
+					t.Errorf("Validate() claims = %v", got)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaims(t *testing.T) {
+	claims := Claims{
+		"sub":          "2b7c",
+		"groups":       []interface{}{"noc", 42, "field"},
+		"realm_access": map[string]interface{}{"roles": []interface{}{"admin"}},
+		"exp":          float64(1772366400),
+		"dotted.name":  "top-level",
+	}
+
+	if got := claims.Strings("groups"); strings.Join(got, ",") != "noc,field" {
+		t.Errorf("Strings(groups) = %v", got)
+	}
+	if got := claims.Strings("realm_access.roles"); len(got) != 1 || got[0] != "admin" {
+		t.Errorf("Strings(realm_access.roles) = %v", got)
+	}
+	if got := claims.Strings("sub"); len(got) != 1 || got[0] != "2b7c" {
+		t.Errorf("Strings(sub) = %v", got)
+	}
+	if got := claims.String("dotted.name"); got != "top-level" {
+		t.Errorf("String(dotted.name) = %q", got)
+	}
+	if got := claims.String("realm_access.missing"); got != "" {
+		t.Errorf("String(missing) = %q", got)
+	}
+	if got, ok := claims.Time("exp"); !ok || !got.Equal(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time(exp) = %v, %v", got, ok)
+	}
+}
+
+func TestJWKS_File(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, keys.jwks(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	j := NewJWKS(path)
+	if err := j.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got, err := j.Lookup(context.Background(), "ec", "ES256")
+	if err != nil || len(got) != 1 {
+		t.Errorf("Lookup(ec) = %d keys, %v, want 1", len(got), err)
+	}
+
+	if err := NewJWKS(filepath.Join(t.TempDir(), "missing.json")).Load(context.Background()); err == nil {
+		t.Error("Load(missing file) error = nil")
+	}
+}
+
+func TestJWKS_URLReloadsForUnknownKid(t *testing.T) {
+	keys := newTestKeys(t)
+	rotated := newTestKeys(t)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			_, _ = w.Write(keys.jwks())
+			return
+		}
+		// After rotation the provider publishes the new EC key as "ec-2"
+		_, _ = w.Write([]byte(strings.Replace(string(rotated.jwks()), `"kid":"ec"`, `"kid":"ec-2"`, 1)))
+	}))
+	defer server.Close()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	j := NewJWKS(server.URL)
+	j.now = func() time.Time { return now }
+
+	if got, err := j.Lookup(context.Background(), "ec", "ES256"); err != nil || len(got) != 1 {
+		t.Fatalf("Lookup(ec) = %d keys, %v, want 1", len(got), err)
+	}
+
+	// Unknown kids do not reload before MinInterval
+	if got, _ := j.Lookup(context.Background(), "ec-2", "ES256"); len(got) != 0 || requests.Load() != 1 {
+		t.Fatalf("Lookup(ec-2) = %d keys after %d requests, want no reload yet", len(got), requests.Load())
+	}
+
+	now = now.Add(DefaultJWKSMinInterval)
+	got, err := j.Lookup(context.Background(), "ec-2", "ES256")
+	if err != nil || len(got) != 1 || requests.Load() != 2 {
+		t.Fatalf("Lookup(ec-2) = %d keys, %v after %d requests, want the reloaded key", len(got), err, requests.Load())
+	}
+	if !got[0].Public.(*ecdsa.PublicKey).Equal(&rotated.ec.PublicKey) {
+		t.Error("Lookup(ec-2) returned a key of the old set")
+	}
+}
+
+func mustRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// swapKid replaces the key ID in the header of a token, keeping its signature
+func swapKid(t *testing.T, token, kid string) string {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	hdr, _ := json.Marshal(header{Alg: "ES256", Kid: kid, Typ: "JWT"})
+	parts[0] = base64.RawURLEncoding.EncodeToString(hdr)
+	return strings.Join(parts, ".")
+}
+
+// unsigned returns a token with the given header and an empty signature
+func unsigned(hdr string, claims map[string]interface{}) string {
+	payload, _ := json.Marshal(claims)
+	return base64.RawURLEncoding.EncodeToString([]byte(hdr)) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+}
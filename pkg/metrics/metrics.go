@@ -0,0 +1,379 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Metric types of the Prometheus text exposition format
+const (
+	TypeCounter   = "counter"
+	TypeGauge     = "gauge"
+	TypeHistogram = "histogram"
+)
+
+// DefBuckets are the default histogram buckets in seconds, suited for request latencies
+var DefBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Labels maps label names to values
+type Labels map[string]string
+
+// Sample is a single value of a metric family
+type Sample struct {
+	Suffix string // Appended to the family name, e.g. "_bucket" for histograms
+	Labels Labels
+	Value  float64
+}
+
+// Family is a metric with all its samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Collector provides metric families when the metrics are scraped
+type Collector interface {
+	Collect() []Family
+}
+
+// Registry holds the collectors exported on /metrics
+type Registry struct {
+	mu         sync.RWMutex
+	collectors []Collector
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Default is the registry the instruments of this application are registered with
+var Default = NewRegistry()
+
+// Register adds a collector to the registry
+func (r *Registry) Register(c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.collectors = append(r.collectors, c)
+}
+
+// Gather collects all families, sorted by name. Families with the same name are merged.
+func (r *Registry) Gather() []Family {
+	r.mu.RLock()
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.RUnlock()
+
+	byName := make(map[string]*Family)
+	var names []string
+	for _, c := range collectors {
+		for _, f := range c.Collect() {
+			if existing, ok := byName[f.Name]; ok {
+				existing.Samples = append(existing.Samples, f.Samples...)
+				continue
+			}
+			family := f
+			byName[f.Name] = &family
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(names)
+
+	families := make([]Family, 0, len(names))
+	for _, name := range names {
+		families = append(families, *byName[name])
+	}
+	return families
+}
+
+// WriteText writes all metrics in the Prometheus text exposition format (version 0.0.4)
+func (r *Registry) WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range r.Gather() {
+		if f.Help != "" {
+			fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help))
+		}
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		for _, s := range f.Samples {
+			bw.WriteString(f.Name + s.Suffix)
+			writeLabels(bw, s.Labels)
+			bw.WriteByte(' ')
+			bw.WriteString(formatValue(s.Value))
+			bw.WriteByte('\n')
+		}
+	}
+	return bw.Flush()
+}
+
+// Handler serves the metrics of the registry
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// writeLabels writes {name="value",...} with the names sorted; nothing for no labels
+func writeLabels(bw *bufio.Writer, labels Labels) {
+	if len(labels) == 0 {
+		return
+	}
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bw.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			bw.WriteByte(',')
+		}
+		bw.WriteString(name)
+		bw.WriteString(`="`)
+		bw.WriteString(escapeLabelValue(labels[name]))
+		bw.WriteByte('"')
+	}
+	bw.WriteByte('}')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
+
+// formatValue formats a sample value, including the special values +Inf, -Inf and NaN
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// vec keeps one value per combination of label values
+type vec struct {
+	name       string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+}
+
+// key joins label values into a map key
+func (v *vec) key(values []string) string {
+	if len(values) != len(v.labelNames) {
+		panic(fmt.Sprintf("metric %s: expected %d label values, got %d", v.name, len(v.labelNames), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// labels pairs the label names with the values of a key
+func (v *vec) labels(key string) Labels {
+	if len(v.labelNames) == 0 {
+		return nil
+	}
+	values := strings.Split(key, "\xff")
+	labels := make(Labels, len(values))
+	for i, name := range v.labelNames {
+		labels[name] = values[i]
+	}
+	return labels
+}
+
+// sortedKeys returns the keys of m in a stable order
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	vec
+	values map[string]float64
+}
+
+// NewCounterVec creates a counter and registers it with the default registry
+func NewCounterVec(name, help string, labelNames ...string) *CounterVec {
+	c := &CounterVec{vec: vec{name: name, help: help, labelNames: labelNames}, values: make(map[string]float64)}
+	Default.Register(c)
+	return c
+}
+
+// Add increases the counter of the label values by delta, which must not be negative
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metric %s: counter cannot decrease", c.name))
+	}
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.values[key] += delta
+}
+
+// Inc increases the counter of the label values by one
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Collect implements Collector
+func (c *CounterVec) Collect() []Family {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	family := Family{Name: c.name, Help: c.help, Type: TypeCounter}
+	for _, key := range sortedKeys(c.values) {
+		family.Samples = append(family.Samples, Sample{Labels: c.labels(key), Value: c.values[key]})
+	}
+	return []Family{family}
+}
+
+// GaugeVec is a gauge partitioned by labels
+type GaugeVec struct {
+	vec
+	values map[string]float64
+}
+
+// NewGaugeVec creates a gauge and registers it with the default registry
+func NewGaugeVec(name, help string, labelNames ...string) *GaugeVec {
+	g := &GaugeVec{vec: vec{name: name, help: help, labelNames: labelNames}, values: make(map[string]float64)}
+	Default.Register(g)
+	return g
+}
+
+// Set sets the gauge of the label values
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	key := g.key(labelValues)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] = value
+}
+
+// Add changes the gauge of the label values by delta (which may be negative)
+func (g *GaugeVec) Add(delta float64, labelValues ...string) {
+	key := g.key(labelValues)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.values[key] += delta
+}
+
+// Collect implements Collector
+func (g *GaugeVec) Collect() []Family {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	family := Family{Name: g.name, Help: g.help, Type: TypeGauge}
+	for _, key := range sortedKeys(g.values) {
+		family.Samples = append(family.Samples, Sample{Labels: g.labels(key), Value: g.values[key]})
+	}
+	return []Family{family}
+}
+
+// histogram holds the observations of one label combination
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	vec
+	buckets []float64
+	values  map[string]*histogram
+}
+
+// NewHistogramVec creates a histogram with the given upper bounds (DefBuckets if nil)
+// and registers it with the default registry
+func NewHistogramVec(name, help string, buckets []float64, labelNames ...string) *HistogramVec {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	h := &HistogramVec{
+		vec:     vec{name: name, help: help, labelNames: labelNames},
+		buckets: buckets,
+		values:  make(map[string]*histogram),
+	}
+	Default.Register(h)
+	return h
+}
+
+// Observe records a value for the label values
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+	}
+	if i := sort.SearchFloat64s(h.buckets, value); i < len(h.buckets) {
+		hist.counts[i]++
+	}
+	hist.count++
+	hist.sum += value
+}
+
+// Collect implements Collector
+func (h *HistogramVec) Collect() []Family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	family := Family{Name: h.name, Help: h.help, Type: TypeHistogram}
+	for _, key := range sortedKeys(h.values) {
+		hist := h.values[key]
+		labels := h.labels(key)
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += hist.counts[i]
+			family.Samples = append(family.Samples, Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", formatValue(upper)), Value: float64(cumulative)})
+		}
+		family.Samples = append(family.Samples,
+			Sample{Suffix: "_bucket", Labels: withLabel(labels, "le", "+Inf"), Value: float64(hist.count)},
+			Sample{Suffix: "_sum", Labels: labels, Value: hist.sum},
+			Sample{Suffix: "_count", Labels: labels, Value: float64(hist.count)},
+		)
+	}
+	return []Family{family}
+}
+
+// withLabel returns a copy of labels with an additional label
+func withLabel(labels Labels, name, value string) Labels {
+	result := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WriteText(t *testing.T) {
+	counter := NewCounterVec("test_requests_total", "Requests", "method")
+	counter.Inc("GET")
+	counter.Add(2, "GET")
+	counter.Inc(`PO"ST`)
+
+	gauge := NewGaugeVec("test_queue_depth", "Queue depth\nof the pool")
+	gauge.Set(3)
+	gauge.Add(-1)
+
+	histogram := NewHistogramVec("test_duration_seconds", "Duration", []float64{1, 0.1}, "route")
+	histogram.Observe(0.05, "/a")
+	histogram.Observe(0.5, "/a")
+	histogram.Observe(5, "/a")
+
+	registry := NewRegistry()
+	registry.Register(counter)
+	registry.Register(gauge)
+	registry.Register(histogram)
+
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP test_duration_seconds Duration
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{le="0.1",route="/a"} 1
+test_duration_seconds_bucket{le="1",route="/a"} 2
+test_duration_seconds_bucket{le="+Inf",route="/a"} 3
+test_duration_seconds_sum{route="/a"} 5.55
+test_duration_seconds_count{route="/a"} 3
+# HELP test_queue_depth Queue depth\nof the pool
+# TYPE test_queue_depth gauge
+test_queue_depth 2
+# HELP test_requests_total Requests
+# TYPE test_requests_total counter
+test_requests_total{method="GET"} 3
+test_requests_total{method="PO\"ST"} 1
+`
+	if out.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRegistry_MergesFamilies(t *testing.T) {
+	registry := NewRegistry()
+	for _, olt := range []string{"b", "a"} {
+		registry.Register(staticCollector{Family{Name: "test_up", Type: TypeGauge, Samples: []Sample{{Labels: Labels{"olt": olt}, Value: 1}}}})
+	}
+
+	families := registry.Gather()
+	if len(families) != 1 || len(families[0].Samples) != 2 {
+		t.Fatalf("Gather() = %+v, want one family with two samples", families)
+	}
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if strings.Count(rec.Body.String(), "# TYPE test_up gauge") != 1 {
+		t.Errorf("family header not written exactly once:\n%s", rec.Body.String())
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := map[float64]string{1: "1", 0.25: "0.25", math.Inf(1): "+Inf", math.Inf(-1): "-Inf", 1e21: "1e+21"}
+	for value, want := range tests {
+		if got := formatValue(value); got != want {
+			t.Errorf("formatValue(%v) = %q, want %q", value, got, want)
+		}
+	}
+	if got := formatValue(math.NaN()); got != "NaN" {
+		t.Errorf("formatValue(NaN) = %q, want NaN", got)
+	}
+}
+
+type staticCollector []Family
+
+func (c staticCollector) Collect() []Family { return c }
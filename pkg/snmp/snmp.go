@@ -1,6 +1,7 @@
 package snmp
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
@@ -19,43 +20,63 @@ var (
 
 // SetupSnmpConnection is a function to set up snmp connection
 // It helps in initializing the SNMP parameters based on environment or configuration.
-func SetupSnmpConnection(config *config.Config) (*gosnmp.GoSNMP, error) {
+func SetupSnmpConnection(cfg *config.Config) (*gosnmp.GoSNMP, error) {
+	snmpCfg := cfg.SnmpCfg
+
 	// Check if the application is running in a development or production environment
 	if os.Getenv("APP_ENV") == "development" || os.Getenv("APP_ENV") == "production" {
 		// Load from environment variables
 		snmpHost = os.Getenv("SNMP_HOST")
 		snmpPort = utils.ConvertStringToUint16(os.Getenv("SNMP_PORT"))
 		snmpCommunity = os.Getenv("SNMP_COMMUNITY")
+
+		snmpCfg.Version = os.Getenv("SNMP_VERSION")
+		snmpCfg.User = os.Getenv("SNMP_V3_USER")
+		snmpCfg.AuthProtocol = os.Getenv("SNMP_V3_AUTH_PROTOCOL")
+		snmpCfg.AuthPassphrase = os.Getenv("SNMP_V3_AUTH_PASSPHRASE")
+		snmpCfg.PrivProtocol = os.Getenv("SNMP_V3_PRIV_PROTOCOL")
+		snmpCfg.PrivPassphrase = os.Getenv("SNMP_V3_PRIV_PASSPHRASE")
 	} else {
 		// Load from config object
-		snmpHost = config.SnmpCfg.IP
-		snmpPort = config.SnmpCfg.Port
-		snmpCommunity = config.SnmpCfg.Community
+		snmpHost = cfg.SnmpCfg.IP
+		snmpPort = cfg.SnmpCfg.Port
+		snmpCommunity = cfg.SnmpCfg.Community
 	}
 
+	snmpCfg.IP, snmpCfg.Port, snmpCfg.Community = snmpHost, snmpPort, snmpCommunity
+	return ConnectSnmp(snmpCfg)
+}
+
+// ConnectSnmp sets up an SNMP connection from explicit connection parameters.
+// It is used directly when several OLTs are managed, each with its own credentials.
+func ConnectSnmp(cfg config.SnmpConfig) (*gosnmp.GoSNMP, error) {
 	// Check if SNMP configuration is valid (non-empty)
-	if snmpHost == "" || snmpPort == 0 || snmpCommunity == "" {
+	if cfg.IP == "" || cfg.Port == 0 {
 		log.Error().Msg("SNMP configuration is invalid")       // Log error
 		return nil, fmt.Errorf("konfigurasi SNMP tidak valid") // Return error (Note: Error string is in Indonesian, keeping it as is or should I translate it? Request said English comments, didn't explicitly strict logic strings, but I will leave logic string as is to avoid breaking changes if any)
 	}
+	if err := cfg.Validate(); err != nil {
+		log.Error().Err(err).Msg("SNMP configuration is invalid")
+		return nil, fmt.Errorf("invalid SNMP configuration: %w", err)
+	}
 
 	log.Info().
-		Str("host", snmpHost).
-		Uint16("port", snmpPort).
+		Str("host", cfg.IP).
+		Uint16("port", cfg.Port).
+		Bool("snmpv3", cfg.IsV3()).
 		Msg("Setting up SNMP connection") // Log setup information
 
 	// Create a new SNMP target instance
 	// Note: SNMP library logging is disabled, we use zerolog for application logging instead
 	target := &gosnmp.GoSNMP{
-		Target:    snmpHost,                       // Target IP
-		Port:      snmpPort,                       // Target Port
-		Community: snmpCommunity,                  // Community String
-		Version:   gosnmp.Version2c,               // SNMP Version 2c
-		Timeout:   time.Duration(5) * time.Second, // Timeout: 5 s (reduced from the 30s for better responsiveness)
-		Retries:   2,                              // Retry count: 2 (reduced from 3, max time = 5 s × 2 = 10s)
-		MaxOids:   60,                             // Maximum OIDs per request (batch size for better performance)
-		Logger:    gosnmp.Logger{},                // Disable SNMP library logging (empty struct)
+		Target:  cfg.IP,                         // Target IP
+		Port:    cfg.Port,                       // Target Port
+		Timeout: time.Duration(5) * time.Second, // Timeout: 5 s (reduced from the 30s for better responsiveness)
+		Retries: 2,                              // Retry count: 2 (reduced from 3, max time = 5 s × 2 = 10s)
+		MaxOids: 60,                             // Maximum OIDs per request (batch size for better performance)
+		Logger:  gosnmp.Logger{},                // Disable SNMP library logging (empty struct)
 	}
+	ApplySecurity(target, cfg) // Community (v2c) or USM credentials (v3)
 
 	// Connect to the SNMP target
 	err := target.Connect()
@@ -67,3 +88,89 @@ func SetupSnmpConnection(config *config.Config) (*gosnmp.GoSNMP, error) {
 	log.Info().Msg("Successfully connected to SNMP") // Log success
 	return target, nil                               // Return SNMP target object
 }
+
+// ApplySecurity sets the SNMP version and credentials of target: the community for v2c,
+// the USM user, authentication and privacy settings for v3. cfg must be valid (see config.SnmpConfig.Validate).
+func ApplySecurity(target *gosnmp.GoSNMP, cfg config.SnmpConfig) {
+	if !cfg.IsV3() {
+		target.Version = gosnmp.Version2c
+		target.Community = cfg.Community
+		return
+	}
+
+	usm := &gosnmp.UsmSecurityParameters{
+		UserName:                 cfg.User,
+		AuthenticationProtocol:   gosnmp.NoAuth,
+		AuthenticationPassphrase: cfg.AuthPassphrase,
+		PrivacyProtocol:          gosnmp.NoPriv,
+		PrivacyPassphrase:        cfg.PrivPassphrase,
+	}
+	switch config.NormalizeSnmpProtocol(cfg.AuthProtocol) {
+	case "MD5":
+		usm.AuthenticationProtocol = gosnmp.MD5
+	case "SHA":
+		usm.AuthenticationProtocol = gosnmp.SHA
+	case "SHA256":
+		usm.AuthenticationProtocol = gosnmp.SHA256
+	}
+	switch config.NormalizeSnmpProtocol(cfg.PrivProtocol) {
+	case "DES":
+		usm.PrivacyProtocol = gosnmp.DES
+	case "AES":
+		usm.PrivacyProtocol = gosnmp.AES
+	}
+
+	target.Version = gosnmp.Version3
+	target.SecurityModel = gosnmp.UserSecurityModel
+	target.SecurityParameters = usm
+	switch cfg.SecurityLevel() {
+	case "authPriv":
+		target.MsgFlags = gosnmp.AuthPriv
+	case "authNoPriv":
+		target.MsgFlags = gosnmp.AuthNoPriv
+	default:
+		target.MsgFlags = gosnmp.NoAuthNoPriv
+	}
+}
+
+// probeOID is sysDescr.0, which every agent answers
+const probeOID = ".1.3.6.1.2.1.1.1.0"
+
+// Probe queries the agent once to verify reachability and credentials.
+// UDP gives no feedback on Connect, so this is the first point where wrong credentials show up.
+func Probe(target *gosnmp.GoSNMP) error {
+	result, err := target.Get([]string{probeOID})
+	if err != nil {
+		return probeError(target, err)
+	}
+	if result.Error != gosnmp.NoError {
+		return fmt.Errorf("SNMP agent %s:%d rejected the probe request: %s", target.Target, target.Port, result.Error)
+	}
+	return nil
+}
+
+// probeError turns the error of a failed probe into an explanation of the likely cause
+func probeError(target *gosnmp.GoSNMP, err error) error {
+	agent := fmt.Sprintf("%s:%d", target.Target, target.Port)
+
+	var user string
+	if usm, ok := target.SecurityParameters.(*gosnmp.UsmSecurityParameters); ok {
+		user = usm.UserName
+	}
+
+	switch {
+	case errors.Is(err, gosnmp.ErrUnknownUsername):
+		return fmt.Errorf("SNMPv3 user %q is unknown to agent %s: %w", user, agent, err)
+	case errors.Is(err, gosnmp.ErrWrongDigest):
+		return fmt.Errorf("SNMPv3 authentication failed on agent %s: wrong auth protocol or auth passphrase for user %q: %w", agent, user, err)
+	case errors.Is(err, gosnmp.ErrDecryption):
+		return fmt.Errorf("SNMPv3 decryption failed on agent %s: wrong priv protocol or priv passphrase for user %q: %w", agent, user, err)
+	case errors.Is(err, gosnmp.ErrUnknownSecurityLevel):
+		return fmt.Errorf("SNMPv3 security level is not allowed for user %q on agent %s: %w", user, agent, err)
+	case target.Version == gosnmp.Version3:
+		return fmt.Errorf("no SNMPv3 response from agent %s (check host, port and that the agent accepts SNMPv3): %w", agent, err)
+	default:
+		// v2c agents silently drop requests with a wrong community
+		return fmt.Errorf("no SNMP response from agent %s (check host, port and community): %w", agent, err)
+	}
+}
@@ -1,9 +1,14 @@
 package snmp
 
 import (
+	"errors"
+	"net"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/gosnmp/gosnmp"
 	"github.com/s4lfanet/go-api-c320/config"
 )
 
@@ -243,3 +248,93 @@ func TestSetupSnmpConnection_Development(t *testing.T) {
 		}
 	}
 }
+
+func TestApplySecurity(t *testing.T) {
+	v2c := &gosnmp.GoSNMP{}
+	ApplySecurity(v2c, config.SnmpConfig{Community: "public"})
+	if v2c.Version != gosnmp.Version2c || v2c.Community != "public" {
+		t.Errorf("v2c: version %v, community %q", v2c.Version, v2c.Community)
+	}
+
+	v3 := &gosnmp.GoSNMP{}
+	ApplySecurity(v3, config.SnmpConfig{
+		Version:        "3",
+		User:           "nms",
+		AuthProtocol:   "sha-256",
+		AuthPassphrase: "auth-secret",
+		PrivProtocol:   "AES",
+		PrivPassphrase: "priv-secret",
+	})
+	if v3.Version != gosnmp.Version3 || v3.SecurityModel != gosnmp.UserSecurityModel || v3.MsgFlags != gosnmp.AuthPriv {
+		t.Errorf("v3: version %v, model %v, flags %v", v3.Version, v3.SecurityModel, v3.MsgFlags)
+	}
+	usm, ok := v3.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		t.Fatalf("SecurityParameters = %T", v3.SecurityParameters)
+	}
+	if usm.UserName != "nms" || usm.AuthenticationProtocol != gosnmp.SHA256 || usm.PrivacyProtocol != gosnmp.AES {
+		t.Errorf("USM parameters = %+v", usm)
+	}
+	if v3.Community != "" {
+		t.Errorf("Expected no community for SNMPv3, got %q", v3.Community)
+	}
+}
+
+func TestConnectSnmp_InvalidV3Config(t *testing.T) {
+	conn, err := ConnectSnmp(config.SnmpConfig{IP: "127.0.0.1", Port: 161, Version: "3"})
+	if err == nil || !strings.Contains(err.Error(), "user is required") {
+		t.Errorf("Expected SNMPv3 validation error, got %v", err)
+	}
+	if conn != nil {
+		t.Error("Expected nil connection for invalid config")
+	}
+}
+
+func TestProbe_NoResponse(t *testing.T) {
+	// An agent that drops every request behaves like one with a wrong community
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer listener.Close()
+
+	target := &gosnmp.GoSNMP{
+		Target:  "127.0.0.1",
+		Port:    uint16(listener.LocalAddr().(*net.UDPAddr).Port),
+		Timeout: 50 * time.Millisecond,
+		Retries: 0,
+	}
+	ApplySecurity(target, config.SnmpConfig{Community: "wrong"})
+	if err := target.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer target.Conn.Close()
+
+	err = Probe(target)
+	if err == nil || !strings.Contains(err.Error(), "check host, port and community") {
+		t.Errorf("Probe() error = %v, want a hint at the community", err)
+	}
+}
+
+func TestProbeError(t *testing.T) {
+	target := &gosnmp.GoSNMP{Target: "10.0.0.1", Port: 161}
+	ApplySecurity(target, config.SnmpConfig{Version: "3", User: "nms", AuthProtocol: "SHA", AuthPassphrase: "auth-secret"})
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{gosnmp.ErrUnknownUsername, `SNMPv3 user "nms" is unknown`},
+		{gosnmp.ErrWrongDigest, "wrong auth protocol or auth passphrase"},
+		{gosnmp.ErrDecryption, "wrong priv protocol or priv passphrase"},
+		{gosnmp.ErrUnknownSecurityLevel, "security level is not allowed"},
+		{errors.New("request timeout (after 2 retries)"), "accepts SNMPv3"},
+	}
+
+	for _, tt := range tests {
+		err := probeError(target, tt.err)
+		if !strings.Contains(err.Error(), tt.want) || !errors.Is(err, tt.err) {
+			t.Errorf("probeError(%v) = %v, want %q", tt.err, err, tt.want)
+		}
+	}
+}
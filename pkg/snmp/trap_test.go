@@ -0,0 +1,149 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/s4lfanet/go-api-c320/config"
+)
+
+// freeUDPAddr reserves a free UDP port on the loopback address
+func freeUDPAddr(t *testing.T) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+func TestStartTrapListener(t *testing.T) {
+	addr := freeUDPAddr(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *gosnmp.SnmpPacket, 1)
+	err := StartTrapListener(ctx, addr.String(), TrapSecurity{}, func(packet *gosnmp.SnmpPacket, _ *net.UDPAddr) {
+		received <- packet
+	})
+	if err != nil {
+		t.Fatalf("StartTrapListener() error = %v", err)
+	}
+
+	// A second listener on the same address fails to bind
+	if err := StartTrapListener(ctx, addr.String(), TrapSecurity{}, func(*gosnmp.SnmpPacket, *net.UDPAddr) {}); err == nil {
+		t.Error("StartTrapListener() on a bound address succeeded, want error")
+	}
+
+	sender := &gosnmp.GoSNMP{
+		Target:    "127.0.0.1",
+		Port:      uint16(addr.Port),
+		Community: "public",
+		Version:   gosnmp.Version2c,
+		Timeout:   time.Second,
+	}
+	if err := sender.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer sender.Conn.Close()
+
+	_, err = sender.SendTrap(gosnmp.SnmpTrap{Variables: []gosnmp.SnmpPDU{
+		{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.3902.1082.500.10.2.2.0.1"},
+	}})
+	if err != nil {
+		t.Fatalf("SendTrap() error = %v", err)
+	}
+
+	select {
+	case packet := <-received:
+		if packet.Community != "public" {
+			t.Errorf("community = %q, want public", packet.Community)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("trap not received")
+	}
+}
+
+func TestStartTrapListener_SNMPv3Inform(t *testing.T) {
+	addr := freeUDPAddr(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	olt := config.SnmpConfig{
+		Version:        config.SnmpVersion3,
+		User:           "nms",
+		AuthProtocol:   "SHA",
+		AuthPassphrase: "auth-secret",
+		PrivProtocol:   "AES",
+		PrivPassphrase: "priv-secret",
+	}
+	other := config.SnmpConfig{Version: config.SnmpVersion3, User: "noc", AuthProtocol: "MD5", AuthPassphrase: "noc-secret"}
+	security := TrapSecurity{
+		EngineID: []byte{0x80, 0x00, 0x7e, 0x59, 0x04, 't', 'r', 'a', 'p', 's'},
+		Users:    []config.SnmpConfig{olt, other, olt},
+	}
+
+	received := make(chan *gosnmp.SnmpPacket, 2)
+	err := StartTrapListener(ctx, addr.String(), security, func(packet *gosnmp.SnmpPacket, _ *net.UDPAddr) {
+		received <- packet
+	})
+	if err != nil {
+		t.Fatalf("StartTrapListener() error = %v", err)
+	}
+
+	// sendInform sends an inform with credentials and returns the error of the acknowledgement
+	sendInform := func(credentials config.SnmpConfig) error {
+		sender := &gosnmp.GoSNMP{
+			Target:  "127.0.0.1",
+			Port:    uint16(addr.Port),
+			Timeout: 500 * time.Millisecond,
+			Retries: 1,
+		}
+		ApplySecurity(sender, credentials)
+		if err := sender.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer sender.Conn.Close()
+
+		_, err := sender.SendTrap(gosnmp.SnmpTrap{IsInform: true, Variables: []gosnmp.SnmpPDU{
+			{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.4.1.32473.1.0.1"},
+		}})
+		return err
+	}
+
+	if err := sendInform(olt); err != nil {
+		t.Fatalf("SendTrap(inform) error = %v, want the inform acknowledged", err)
+	}
+	select {
+	case packet := <-received:
+		usm, _ := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+		if packet.Version != gosnmp.Version3 || usm == nil || usm.UserName != "nms" {
+			t.Errorf("packet = %+v, want an SNMPv3 notification of nms", packet)
+		}
+		if packet.MsgFlags&gosnmp.AuthPriv != gosnmp.AuthPriv {
+			t.Errorf("MsgFlags = %v, want authPriv", packet.MsgFlags)
+		}
+		if len(packet.Variables) != 2 || packet.Variables[1].Value != ".1.3.6.1.4.1.32473.1.0.1" {
+			t.Errorf("variables = %+v, want the decrypted notification", packet.Variables)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("inform not received")
+	}
+
+	// Wrong credentials are neither passed on nor acknowledged
+	wrong := olt
+	wrong.AuthPassphrase = "guessed-secret"
+	if err := sendInform(wrong); err == nil {
+		t.Error("SendTrap(inform with a wrong passphrase) error = nil, want no acknowledgement")
+	}
+	select {
+	case packet := <-received:
+		t.Errorf("received %+v with a wrong passphrase", packet)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
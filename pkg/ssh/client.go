@@ -0,0 +1,346 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrAuthFailed is returned when the server rejects all offered authentication methods
+var ErrAuthFailed = errors.New("ssh: authentication failed")
+
+// Algorithms offered by default: those x/crypto considers secure, without HMAC-SHA1
+var (
+	defaultKeyExchanges = gossh.SupportedAlgorithms().KeyExchanges
+	defaultCiphers      = gossh.SupportedAlgorithms().Ciphers
+	defaultMACs         = slices.DeleteFunc(gossh.SupportedAlgorithms().MACs, func(mac string) bool {
+		return mac == gossh.HMACSHA1
+	})
+)
+
+// Algorithms offered after the defaults with Config.LegacyAlgorithms, for older OLT
+// firmware whose SSH server supports nothing else
+var (
+	legacyKeyExchanges = []string{gossh.InsecureKeyExchangeDH14SHA1, gossh.InsecureKeyExchangeDH1SHA1}
+	legacyCiphers      = []string{gossh.InsecureCipherAES128CBC, gossh.InsecureCipherTripleDESCBC}
+	legacyMACs         = []string{gossh.HMACSHA1}
+)
+
+// Config holds the client settings of an SSH connection
+type Config struct {
+	User     string       // User name
+	Password string       // Password for "password" and "keyboard-interactive" authentication (optional)
+	Signer   gossh.Signer // Private key for "publickey" authentication (optional)
+
+	// HostKeyFingerprints are the accepted SHA256 fingerprints of the server host key
+	// (as printed by ssh-keygen -l). The connection is refused if the key is not pinned.
+	HostKeyFingerprints []string
+
+	// LegacyAlgorithms additionally offers diffie-hellman-group1/group14-sha1, aes128-cbc,
+	// 3des-cbc and hmac-sha1. Only for OLTs that support nothing newer.
+	LegacyAlgorithms bool
+
+	Timeout time.Duration // Limit for connecting, key exchange, authentication and starting the shell
+	Term    string        // Terminal type of the pseudo terminal (default vt100)
+	Columns int           // Terminal width in characters (default 200)
+}
+
+// clientConfig converts the settings to the configuration of x/crypto/ssh
+func (cfg *Config) clientConfig(timeout time.Duration) *gossh.ClientConfig {
+	var auth []gossh.AuthMethod
+	if cfg.Signer != nil {
+		auth = append(auth, gossh.PublicKeys(cfg.Signer))
+	}
+	if cfg.Password != "" {
+		password := cfg.Password
+		auth = append(auth,
+			gossh.Password(password),
+			// Keyboard-interactive prompts are answered with the password
+			gossh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+				answers := make([]string, len(questions))
+				for i := range answers {
+					answers[i] = password
+				}
+				return answers, nil
+			}))
+	}
+
+	algorithms := gossh.Config{
+		KeyExchanges: slices.Clone(defaultKeyExchanges),
+		Ciphers:      slices.Clone(defaultCiphers),
+		MACs:         slices.Clone(defaultMACs),
+	}
+	if cfg.LegacyAlgorithms {
+		algorithms.KeyExchanges = append(algorithms.KeyExchanges, legacyKeyExchanges...)
+		algorithms.Ciphers = append(algorithms.Ciphers, legacyCiphers...)
+		algorithms.MACs = append(algorithms.MACs, legacyMACs...)
+	}
+
+	return &gossh.ClientConfig{
+		Config:          algorithms,
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: pinnedHostKeys(cfg.HostKeyFingerprints),
+		ClientVersion:   clientVersion,
+		Timeout:         timeout,
+	}
+}
+
+// clientVersion is the identification string sent to the server
+const clientVersion = "SSH-2.0-go-api-c320"
+
+// pinnedHostKeys accepts the server host key only if its fingerprint is pinned
+func pinnedHostKeys(fingerprints []string) gossh.HostKeyCallback {
+	return func(_ string, _ net.Addr, key gossh.PublicKey) error {
+		fingerprint := gossh.FingerprintSHA256(key)
+		for _, pinned := range fingerprints {
+			if fingerprintsEqual(pinned, fingerprint) {
+				return nil
+			}
+		}
+		return &HostKeyError{Fingerprint: fingerprint}
+	}
+}
+
+// HostKeyError is returned when the server's host key is not one of the pinned fingerprints
+type HostKeyError struct {
+	Fingerprint string // SHA256 fingerprint of the presented host key
+}
+
+func (e *HostKeyError) Error() string {
+	return fmt.Sprintf("ssh: host key %s is not pinned", e.Fingerprint)
+}
+
+// Conn is an interactive shell session over SSH. It implements net.Conn; reads
+// return the output of the shell (stdout and stderr) and writes go to its input.
+type Conn struct {
+	conn    net.Conn
+	client  *gossh.Client
+	session *gossh.Session
+	stdin   io.WriteCloser
+
+	mu           sync.Mutex
+	buf          []byte
+	readErr      error         // Set when the session or connection ends
+	dataReady    chan struct{} // Signaled when data or readErr arrives
+	readDeadline time.Time
+}
+
+// Dial connects to an SSH server, authenticates and starts an interactive shell with a pseudo terminal
+func Dial(network, addr string, cfg *Config) (*Conn, error) {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	nc, err := net.DialTimeout(network, addr, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newClientConn(nc, addr, cfg, time.Now().Add(timeout))
+	if err != nil {
+		nc.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// newClientConn runs the handshake on an established connection and starts the shell
+func newClientConn(nc net.Conn, addr string, cfg *Config, deadline time.Time) (*Conn, error) {
+	if err := nc.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := gossh.NewClientConn(nc, addr, cfg.clientConfig(time.Until(deadline)))
+	if err != nil {
+		// x/crypto/ssh has no error type for rejected credentials
+		if strings.Contains(err.Error(), "unable to authenticate") {
+			return nil, fmt.Errorf("%w: %v", ErrAuthFailed, err)
+		}
+		return nil, err
+	}
+	client := gossh.NewClient(sshConn, chans, reqs)
+
+	c := &Conn{conn: nc, client: client, dataReady: make(chan struct{}, 1)}
+	if err := c.startShell(cfg); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if err := nc.SetDeadline(time.Time{}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// startShell opens a session, requests a pseudo terminal and starts the shell
+func (c *Conn) startShell(cfg *Config) error {
+	session, err := c.client.NewSession()
+	if err != nil {
+		return err
+	}
+	c.session = session
+
+	if c.stdin, err = session.StdinPipe(); err != nil {
+		return err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	session.Stderr = outputWriter{c}
+
+	term := cfg.Term
+	if term == "" {
+		term = "vt100"
+	}
+	columns := cfg.Columns
+	if columns <= 0 {
+		columns = 200
+	}
+	if err := session.RequestPty(term, 0, columns, gossh.TerminalModes{}); err != nil {
+		return err
+	}
+	if err := session.Shell(); err != nil {
+		return err
+	}
+
+	go c.readLoop(stdout)
+	return nil
+}
+
+// readLoop buffers the shell output until the session ends
+func (c *Conn) readLoop(stdout io.Reader) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := stdout.Read(buf)
+		if n > 0 {
+			c.append(buf[:n])
+		}
+		if err != nil {
+			c.fail(err)
+			return
+		}
+	}
+}
+
+// outputWriter appends the stderr output of the shell to the read buffer
+type outputWriter struct{ c *Conn }
+
+func (w outputWriter) Write(p []byte) (int, error) {
+	w.c.append(p)
+	return len(p), nil
+}
+
+// append adds output to the read buffer and wakes up a waiting Read
+func (c *Conn) append(data []byte) {
+	c.mu.Lock()
+	c.buf = append(c.buf, data...)
+	c.mu.Unlock()
+	c.signal()
+}
+
+// signal wakes up a waiting Read
+func (c *Conn) signal() {
+	select {
+	case c.dataReady <- struct{}{}:
+	default:
+	}
+}
+
+// fail ends the session with err; pending and later reads return it once the buffer is drained
+func (c *Conn) fail(err error) {
+	c.mu.Lock()
+	if c.readErr == nil {
+		c.readErr = err
+	}
+	c.mu.Unlock()
+	c.signal()
+}
+
+// Read reads shell output. It returns os.ErrDeadlineExceeded when the read deadline passes.
+func (c *Conn) Read(b []byte) (int, error) {
+	for {
+		c.mu.Lock()
+		if len(c.buf) > 0 {
+			n := copy(b, c.buf)
+			c.buf = c.buf[n:]
+			c.mu.Unlock()
+			return n, nil
+		}
+		if c.readErr != nil {
+			err := c.readErr
+			c.mu.Unlock()
+			return 0, err
+		}
+		deadline := c.readDeadline
+		c.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-c.dataReady
+			continue
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-c.dataReady:
+			timer.Stop()
+		case <-timer.C:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+// Write sends input to the shell, waiting for the server's channel window when it is exhausted
+func (c *Conn) Write(b []byte) (int, error) {
+	return c.stdin.Write(b)
+}
+
+// Close closes the session and the connection
+func (c *Conn) Close() error {
+	c.fail(net.ErrClosed)
+	if c.session != nil {
+		_ = c.session.Close()
+	}
+	return c.client.Close()
+}
+
+// LocalAddr returns the local network address
+func (c *Conn) LocalAddr() net.Addr { return c.conn.LocalAddr() }
+
+// RemoteAddr returns the address of the server
+func (c *Conn) RemoteAddr() net.Addr { return c.conn.RemoteAddr() }
+
+// SetDeadline sets the read and write deadlines
+func (c *Conn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// SetReadDeadline sets the deadline for Read; a zero value disables it
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	c.signal() // A waiting Read picks up the new deadline
+	return nil
+}
+
+// SetWriteDeadline sets the deadline for sending on the underlying connection
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,33 @@
+package ssh
+
+import (
+	"encoding/pem"
+	"errors"
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// fingerprintsEqual compares fingerprints, accepting them with or without the "SHA256:" prefix and base64 padding
+func fingerprintsEqual(pinned, actual string) bool {
+	pinned = strings.TrimRight(strings.TrimPrefix(strings.TrimSpace(pinned), "SHA256:"), "=")
+	return pinned == strings.TrimPrefix(actual, "SHA256:")
+}
+
+// ErrEncryptedKey is returned for private keys protected by a passphrase
+var ErrEncryptedKey = errors.New("ssh: encrypted private keys are not supported, remove the passphrase (ssh-keygen -p -N '')")
+
+// ParsePrivateKey parses a PEM encoded private key: OpenSSH ("OPENSSH PRIVATE KEY"),
+// PKCS#1 ("RSA PRIVATE KEY"), SEC 1 ("EC PRIVATE KEY") or PKCS#8 ("PRIVATE KEY")
+func ParsePrivateKey(pemBytes []byte) (gossh.Signer, error) {
+	if block, _ := pem.Decode(pemBytes); block != nil && block.Type == "ENCRYPTED PRIVATE KEY" {
+		return nil, ErrEncryptedKey
+	}
+
+	signer, err := gossh.ParsePrivateKey(pemBytes)
+	var passphraseErr *gossh.PassphraseMissingError
+	if errors.As(err, &passphraseErr) {
+		return nil, ErrEncryptedKey
+	}
+	return signer, err
+}
@@ -0,0 +1,115 @@
+package ssh
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// testServer is an SSH server with a line-based shell that mimics the OLT prompt, for testing the client
+type testServer struct {
+	config *gossh.ServerConfig
+	errs   chan error
+}
+
+// startTestServer listens on a loopback port and serves one connection with the host key.
+// configure sets the algorithms and authentication callbacks of the server.
+func startTestServer(t *testing.T, hostKey gossh.Signer, configure func(*gossh.ServerConfig)) (*testServer, string) {
+	t.Helper()
+	s := &testServer{config: &gossh.ServerConfig{}, errs: make(chan error, 1)}
+	s.config.AddHostKey(hostKey)
+	configure(s.config)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			s.errs <- err
+			return
+		}
+		defer conn.Close()
+		s.errs <- s.serve(conn)
+	}()
+	return s, listener.Addr().String()
+}
+
+// serve runs the handshake and the shell of one session
+func (s *testServer) serve(conn net.Conn) error {
+	sshConn, chans, reqs, err := gossh.NewServerConn(conn, s.config)
+	if err != nil {
+		return err
+	}
+	defer sshConn.Close()
+	go gossh.DiscardRequests(reqs)
+
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(gossh.UnknownChannelType, "only sessions")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			return err
+		}
+		return shell(channel, requests)
+	}
+	return nil
+}
+
+// shell answers every line with its echo, "ok" and the prompt until the client closes the channel
+func shell(channel gossh.Channel, requests <-chan *gossh.Request) error {
+	defer channel.Close()
+
+	started := make(chan error, 1)
+	go func() {
+		var pty bool
+		for req := range requests {
+			switch req.Type {
+			case "pty-req":
+				pty = true
+				_ = req.Reply(true, nil)
+			case "shell":
+				_ = req.Reply(pty, nil)
+				if !pty {
+					started <- fmt.Errorf("shell requested without a pseudo terminal")
+					return
+				}
+				started <- nil
+			default:
+				_ = req.Reply(false, nil)
+			}
+		}
+	}()
+	if err := <-started; err != nil {
+		return err
+	}
+
+	if _, err := channel.Write([]byte("\r\nZXAN#")); err != nil {
+		return err
+	}
+	lines := bufio.NewReader(channel)
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil {
+			return nil // Client closed the session
+		}
+		answer := strings.TrimSpace(line) + "\r\nok\r\nZXAN#"
+		if _, err := channel.Write([]byte(answer)); err != nil {
+			return err
+		}
+	}
+}
+
+// wait returns the result of the served connection
+func (s *testServer) wait() error {
+	return <-s.errs
+}
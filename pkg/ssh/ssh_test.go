@@ -0,0 +1,331 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+var (
+	rsaKeyOnce sync.Once
+	rsaKey     *rsa.PrivateKey
+)
+
+// testRSAKey returns a 2048-bit RSA key, generated once per test run
+func testRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	rsaKeyOnce.Do(func() {
+		var err error
+		if rsaKey, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			t.Fatalf("GenerateKey() error = %v", err)
+		}
+	})
+	return rsaKey
+}
+
+// testSigner wraps a private key as host or client key
+func testSigner(t *testing.T, key any) gossh.Signer {
+	t.Helper()
+	signer, err := gossh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatalf("NewSignerFromKey() error = %v", err)
+	}
+	return signer
+}
+
+func testEd25519Signer(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	return testSigner(t, key)
+}
+
+// acceptPassword configures password authentication with "admin"/"secret" on a test server
+func acceptPassword(config *gossh.ServerConfig) {
+	config.PasswordCallback = func(meta gossh.ConnMetadata, password []byte) (*gossh.Permissions, error) {
+		if meta.User() == "admin" && string(password) == "secret" {
+			return nil, nil
+		}
+		return nil, errors.New("wrong password")
+	}
+}
+
+// readUntil reads from the connection until the output contains want
+func readUntil(t *testing.T, conn net.Conn, want string) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var out []byte
+	buf := make([]byte, 1024)
+	for !strings.Contains(string(out), want) {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read() error = %v after %q", err, out)
+		}
+		out = append(out, buf[:n]...)
+	}
+	return string(out)
+}
+
+// runCommand sends a line and checks the shell's answer
+func runCommand(t *testing.T, conn net.Conn, line string) {
+	t.Helper()
+	if _, err := conn.Write([]byte(line + "\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if out := readUntil(t, conn, "ok\r\nZXAN#"); !strings.Contains(out, line) {
+		t.Errorf("output %q does not echo %q", out, line)
+	}
+}
+
+func TestDial_Algorithms(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	ecdsaSigner := testSigner(t, ecdsaKey)
+	rsaSigner := testSigner(t, testRSAKey(t))
+	ed25519Signer := testEd25519Signer(t)
+
+	tests := []struct {
+		name    string
+		hostKey gossh.Signer
+		kex     string
+		cipher  string
+		mac     string
+		legacy  bool // Client offers the legacy algorithms
+		wantErr bool
+	}{
+		{"curve25519 gcm", ed25519Signer, gossh.KeyExchangeCurve25519, gossh.CipherAES128GCM, gossh.HMACSHA256, false, false},
+		{"nistp256 chacha20", ecdsaSigner, gossh.KeyExchangeECDHP256, gossh.CipherChaCha20Poly1305, gossh.HMACSHA256, false, false},
+		{"group14-sha256 ctr", rsaSigner, gossh.KeyExchangeDH14SHA256, gossh.CipherAES256CTR, gossh.HMACSHA512, false, false},
+		{"group14-sha1 not offered", rsaSigner, gossh.InsecureKeyExchangeDH14SHA1, gossh.CipherAES128CTR, gossh.HMACSHA256, false, true},
+		{"group1-sha1 not offered", rsaSigner, gossh.InsecureKeyExchangeDH1SHA1, gossh.CipherAES128CTR, gossh.HMACSHA256, false, true},
+		{"3des-cbc not offered", rsaSigner, gossh.KeyExchangeCurve25519, gossh.InsecureCipherTripleDESCBC, gossh.HMACSHA256, false, true},
+		{"hmac-sha1 not offered", rsaSigner, gossh.KeyExchangeCurve25519, gossh.CipherAES128CTR, gossh.HMACSHA1, false, true},
+		{"legacy group14-sha1 cbc", rsaSigner, gossh.InsecureKeyExchangeDH14SHA1, gossh.InsecureCipherAES128CBC, gossh.HMACSHA1, true, false},
+		{"legacy group1 3des", rsaSigner, gossh.InsecureKeyExchangeDH1SHA1, gossh.InsecureCipherTripleDESCBC, gossh.HMACSHA1, true, false},
+		{"legacy prefers modern", ed25519Signer, gossh.KeyExchangeCurve25519, gossh.CipherAES128GCM, gossh.HMACSHA256, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, addr := startTestServer(t, tt.hostKey, func(config *gossh.ServerConfig) {
+				config.KeyExchanges = []string{tt.kex}
+				config.Ciphers = []string{tt.cipher}
+				config.MACs = []string{tt.mac}
+				acceptPassword(config)
+			})
+
+			conn, err := Dial("tcp", addr, &Config{
+				User:                "admin",
+				Password:            "secret",
+				HostKeyFingerprints: []string{gossh.FingerprintSHA256(tt.hostKey.PublicKey())},
+				LegacyAlgorithms:    tt.legacy,
+				Timeout:             5 * time.Second,
+			})
+			if tt.wantErr {
+				if err == nil {
+					conn.Close()
+					t.Fatal("Dial() succeeded, want no common algorithm")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			readUntil(t, conn, "ZXAN#")
+			runCommand(t, conn, "show gpon onu state gpon-olt_1/1/1")
+			conn.Close()
+
+			if err := server.wait(); err != nil {
+				t.Errorf("server error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDial_PublicKeyAuthentication(t *testing.T) {
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	keys := map[string]any{"ed25519": ed25519Key, "rsa": testRSAKey(t), "ecdsa": ecdsaKey}
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				t.Fatalf("MarshalPKCS8PrivateKey() error = %v", err)
+			}
+			signer, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error = %v", err)
+			}
+
+			hostKey := testEd25519Signer(t)
+			server, addr := startTestServer(t, hostKey, func(config *gossh.ServerConfig) {
+				config.PublicKeyCallback = func(_ gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+					if bytes.Equal(key.Marshal(), signer.PublicKey().Marshal()) {
+						return nil, nil
+					}
+					return nil, errors.New("unknown key")
+				}
+			})
+
+			conn, err := Dial("tcp", addr, &Config{
+				User:                "admin",
+				Signer:              signer,
+				HostKeyFingerprints: []string{gossh.FingerprintSHA256(hostKey.PublicKey())},
+			})
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			runCommand(t, conn, "show card")
+			conn.Close()
+			if err := server.wait(); err != nil {
+				t.Errorf("server error = %v", err)
+			}
+		})
+	}
+}
+
+func TestDial_KeyboardInteractive(t *testing.T) {
+	hostKey := testEd25519Signer(t)
+	_, addr := startTestServer(t, hostKey, func(config *gossh.ServerConfig) {
+		config.KeyboardInteractiveCallback = func(_ gossh.ConnMetadata, challenge gossh.KeyboardInteractiveChallenge) (*gossh.Permissions, error) {
+			answers, err := challenge("", "", []string{"Password: "}, []bool{false})
+			if err != nil {
+				return nil, err
+			}
+			if len(answers) != 1 || answers[0] != "secret" {
+				return nil, fmt.Errorf("wrong answers %q", answers)
+			}
+			return nil, nil
+		}
+	})
+
+	conn, err := Dial("tcp", addr, &Config{
+		User:                "admin",
+		Password:            "secret",
+		HostKeyFingerprints: []string{gossh.FingerprintSHA256(hostKey.PublicKey())},
+	})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	runCommand(t, conn, "show version")
+	conn.Close()
+}
+
+func TestDial_Rejected(t *testing.T) {
+	hostKey := testEd25519Signer(t)
+	fingerprint := gossh.FingerprintSHA256(hostKey.PublicKey())
+
+	t.Run("host key not pinned", func(t *testing.T) {
+		_, addr := startTestServer(t, hostKey, acceptPassword)
+
+		_, err := Dial("tcp", addr, &Config{User: "admin", Password: "secret", HostKeyFingerprints: []string{"SHA256:AAAA"}})
+		var hostKeyErr *HostKeyError
+		if !errors.As(err, &hostKeyErr) {
+			t.Fatalf("Dial() error = %v, want HostKeyError", err)
+		}
+		if hostKeyErr.Fingerprint != fingerprint {
+			t.Errorf("Fingerprint = %s, want %s", hostKeyErr.Fingerprint, fingerprint)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, addr := startTestServer(t, hostKey, acceptPassword)
+
+		_, err := Dial("tcp", addr, &Config{User: "admin", Password: "wrong", HostKeyFingerprints: []string{fingerprint}})
+		if !errors.Is(err, ErrAuthFailed) {
+			t.Fatalf("Dial() error = %v, want ErrAuthFailed", err)
+		}
+	})
+}
+
+func TestConn_ReadDeadlineAndRekey(t *testing.T) {
+	hostKey := testEd25519Signer(t)
+	_, addr := startTestServer(t, hostKey, func(config *gossh.ServerConfig) {
+		acceptPassword(config)
+		config.RekeyThreshold = 256 // Re-exchange keys after a few packets
+	})
+
+	conn, err := Dial("tcp", addr, &Config{User: "admin", Password: "secret", HostKeyFingerprints: []string{gossh.FingerprintSHA256(hostKey.PublicKey())}})
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+	readUntil(t, conn, "ZXAN#")
+
+	// Nothing to read: the deadline passes with a timeout error
+	_ = conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err = conn.Read(make([]byte, 16))
+	var netErr net.Error
+	if !errors.Is(err, os.ErrDeadlineExceeded) || !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Fatalf("Read() error = %v, want a timeout", err)
+	}
+
+	// The session continues across key re-exchanges
+	for i := 0; i < 5; i++ {
+		runCommand(t, conn, fmt.Sprintf("show gpon onu detail-info gpon-onu_1/1/1:%d", i+1))
+	}
+}
+
+func TestParsePrivateKey_OpenSSH(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	block, err := gossh.MarshalPrivateKey(priv, "test@c320")
+	if err != nil {
+		t.Fatalf("MarshalPrivateKey() error = %v", err)
+	}
+	signer, err := ParsePrivateKey(pem.EncodeToMemory(block))
+	if err != nil {
+		t.Fatalf("ParsePrivateKey() error = %v", err)
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), testSigner(t, priv).PublicKey().Marshal()) {
+		t.Error("PublicKey() does not match the key file")
+	}
+
+	encrypted, err := gossh.MarshalPrivateKeyWithPassphrase(priv, "test@c320", []byte("passphrase"))
+	if err != nil {
+		t.Fatalf("MarshalPrivateKeyWithPassphrase() error = %v", err)
+	}
+	if _, err := ParsePrivateKey(pem.EncodeToMemory(encrypted)); !errors.Is(err, ErrEncryptedKey) {
+		t.Errorf("ParsePrivateKey(encrypted) error = %v, want ErrEncryptedKey", err)
+	}
+}
+
+func TestFingerprintsEqual(t *testing.T) {
+	actual := gossh.FingerprintSHA256(testEd25519Signer(t).PublicKey())
+	for _, pinned := range []string{actual, strings.TrimPrefix(actual, "SHA256:"), actual + "=", " " + actual + " "} {
+		if !fingerprintsEqual(pinned, actual) {
+			t.Errorf("fingerprintsEqual(%q) = false", pinned)
+		}
+	}
+	if fingerprintsEqual(gossh.FingerprintSHA256(testEd25519Signer(t).PublicKey()), actual) {
+		t.Error("different fingerprints are equal")
+	}
+}